@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Categories of the default observability agents
+const (
+	AgentLogging = "Logging"
+	AgentMetrics = "Metrics"
+	AgentAPM     = "APM / Tracing"
+)
+
+// agentSpec is a platform agent as written in the config file. Match entries
+// containing a "/" are matched against the image repository path, others against
+// the image name and the pod's workload name (exact, "<match>-..." or "...-<match>").
+type agentSpec struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Match    []string `json:"match"`
+}
+
+// defaultAgents is used when the config file defines no agents
+var defaultAgents = []agentSpec{
+	{Name: "Fluent Bit", Category: AgentLogging, Match: []string{"fluent-bit", "fluentbit"}},
+	{Name: "Fluentd", Category: AgentLogging, Match: []string{"fluentd"}},
+	{Name: "Promtail", Category: AgentLogging, Match: []string{"promtail"}},
+	{Name: "Vector", Category: AgentLogging, Match: []string{"timberio/vector"}},
+	{Name: "Filebeat", Category: AgentLogging, Match: []string{"filebeat"}},
+	{Name: "Node Exporter", Category: AgentMetrics, Match: []string{"node-exporter"}},
+	{Name: "kube-state-metrics", Category: AgentMetrics, Match: []string{"kube-state-metrics"}},
+	{Name: "Prometheus", Category: AgentMetrics, Match: []string{"prometheus"}},
+	{Name: "Grafana Agent", Category: AgentMetrics, Match: []string{"grafana-agent", "grafana/alloy"}},
+	{Name: "Datadog Agent", Category: AgentAPM, Match: []string{"datadog-agent", "datadoghq/agent", "datadoghq/cluster-agent"}},
+	{Name: "OpenTelemetry Collector", Category: AgentAPM, Match: []string{"opentelemetry-collector", "otel-collector"}},
+}
+
+// parseAgents validates agent specs from the config file
+func parseAgents(specs []agentSpec) ([]agentSpec, error) {
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("agent without name")
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("duplicate agent '%s'", spec.Name)
+		}
+		seen[spec.Name] = true
+		if spec.Category == "" {
+			return nil, fmt.Errorf("agent '%s' without category", spec.Name)
+		}
+		if len(spec.Match) == 0 {
+			return nil, fmt.Errorf("agent '%s' without match patterns", spec.Name)
+		}
+	}
+	return specs, nil
+}
+
+// imageRepository strips the tag and digest from a container image reference
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// matchesName reports whether name is pattern or starts or ends with it as a
+// dash-separated component
+func matchesName(name, pattern string) bool {
+	return name == pattern || strings.HasPrefix(name, pattern+"-") || strings.HasSuffix(name, "-"+pattern)
+}
+
+// matches reports whether a container image or workload name belongs to the agent
+func (a agentSpec) matches(image, workload string) bool {
+	repo := imageRepository(image)
+	name := repo[strings.LastIndex(repo, "/")+1:]
+	for _, pattern := range a.Match {
+		if strings.Contains(pattern, "/") {
+			if strings.Contains(repo, pattern) {
+				return true
+			}
+			continue
+		}
+		if matchesName(name, pattern) || (workload != "" && matchesName(workload, pattern)) {
+			return true
+		}
+	}
+	return false
+}
+
+// agentOverhead sums the requests of one agent's containers in a namespace
+type agentOverhead struct {
+	agent, category, namespace string
+	containers                 int
+	reqCPU, reqMem             int64
+}
+
+// agentOverheads attributes containers to the first matching platform agent. A
+// pod whose workload matches an agent counts entirely; otherwise containers are
+// matched by image, so agent sidecars in application pods are found too. Returns
+// the rows sorted by CPU and the cluster-wide requests of all containers.
+func agentOverheads(pods []corev1.Pod, agents []agentSpec) (rows []agentOverhead, clusterCPU, clusterMem int64) {
+	type key struct{ agent, namespace string }
+	overheads := make(map[key]*agentOverhead)
+
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+
+		var owner *agentSpec
+		workload := workloadOf(pod).name
+		for j := range agents {
+			if agents[j].matches("", workload) {
+				owner = &agents[j]
+				break
+			}
+		}
+
+		for _, c := range podContainers(pod) {
+			cpu := quantityMilli(c.Resources.Requests.Cpu())
+			mem := quantityBytes(c.Resources.Requests.Memory())
+			clusterCPU += cpu
+			clusterMem += mem
+
+			agent := owner
+			for j := 0; agent == nil && j < len(agents); j++ {
+				if agents[j].matches(c.Image, "") {
+					agent = &agents[j]
+				}
+			}
+			if agent == nil {
+				continue
+			}
+
+			k := key{agent.Name, pod.Namespace}
+			o := overheads[k]
+			if o == nil {
+				o = &agentOverhead{agent: agent.Name, category: agent.Category, namespace: pod.Namespace}
+				overheads[k] = o
+			}
+			o.containers++
+			o.reqCPU += cpu
+			o.reqMem += mem
+		}
+	}
+
+	rows = make([]agentOverhead, 0, len(overheads))
+	for _, o := range overheads {
+		rows = append(rows, *o)
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].reqCPU != rows[j].reqCPU {
+			return rows[i].reqCPU > rows[j].reqCPU
+		}
+		if rows[i].agent != rows[j].agent {
+			return rows[i].agent < rows[j].agent
+		}
+		return rows[i].namespace < rows[j].namespace
+	})
+	return rows, clusterCPU, clusterMem
+}
+
+// createPlatformSheet lists observability agent requests per namespace and
+// summarizes the platform share of cluster requests per category. Applications
+// are the requests of the workbook's pods beyond the agents; the shares divide
+// by the cluster requests, which differ in split group workbooks.
+func createPlatformSheet(f *excelize.File, rows []agentOverhead, requests, cluster namespaceTotal, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create platform overhead sheet: %w", err)
+	}
+
+	headers := []string{
+		"Agent", "Category", "Namespace", "Containers",
+		"Request CPU (cores)", "Request Memory (Gi)", "CPU % of Cluster", "Memory % of Cluster",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	type total struct {
+		containers     int
+		reqCPU, reqMem int64
+	}
+	categories := make(map[string]*total)
+	var categoryOrder []string
+	platform := &total{}
+
+	row := 2
+	for _, o := range rows {
+		data := []interface{}{
+			o.agent,
+			o.category,
+			o.namespace,
+			o.containers,
+			milliToCores(o.reqCPU),
+			bytesToGi(o.reqMem),
+			ratio(o.reqCPU, cluster.reqCPU),
+			ratio(o.reqMem, cluster.reqMem),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("agent '%s' namespace '%s'", o.agent, o.namespace)); err != nil {
+			return err
+		}
+		row++
+
+		c := categories[o.category]
+		if c == nil {
+			c = &total{}
+			categories[o.category] = c
+			categoryOrder = append(categoryOrder, o.category)
+		}
+		for _, t := range []*total{c, platform} {
+			t.containers += o.containers
+			t.reqCPU += o.reqCPU
+			t.reqMem += o.reqMem
+		}
+	}
+	if row > 2 {
+		f.SetCellStyle(sheetName, "E2", fmt.Sprintf("F%d", row-1), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "G2", fmt.Sprintf("H%d", row-1), export.PercentStyle(f, "0.0%"))
+	}
+
+	// Platform vs application summary
+	row++
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Platform vs Applications")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
+	row++
+	sort.Strings(categoryOrder)
+	summaryStart := row
+	// Container counts are only tracked for agents
+	summary := func(label string, containers interface{}, cpu, mem int64) error {
+		data := []interface{}{
+			label, "", "", containers,
+			milliToCores(cpu), bytesToGi(mem),
+			ratio(cpu, cluster.reqCPU), ratio(mem, cluster.reqMem),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("summary '%s'", label)); err != nil {
+			return err
+		}
+		row++
+		return nil
+	}
+	for _, category := range categoryOrder {
+		c := categories[category]
+		if err := summary(category, c.containers, c.reqCPU, c.reqMem); err != nil {
+			return err
+		}
+	}
+	platformRow := row
+	if err := summary("Platform agents", platform.containers, platform.reqCPU, platform.reqMem); err != nil {
+		return err
+	}
+	if err := summary("Applications", nil, requests.reqCPU-platform.reqCPU, requests.reqMem-platform.reqMem); err != nil {
+		return err
+	}
+
+	f.SetCellStyle(sheetName, fmt.Sprintf("E%d", summaryStart), fmt.Sprintf("F%d", row-1), export.DecimalStyle(f, false))
+	f.SetCellStyle(sheetName, fmt.Sprintf("G%d", summaryStart), fmt.Sprintf("H%d", row-1), export.PercentStyle(f, "0.0%"))
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", platformRow), fmt.Sprintf("A%d", platformRow), export.BoldStyle(f))
+
+	f.SetColWidth(sheetName, "A", "A", 26)
+	f.SetColWidth(sheetName, "B", "B", 16)
+	f.SetColWidth(sheetName, "C", "C", 30)
+	f.SetColWidth(sheetName, "D", "D", 12)
+	f.SetColWidth(sheetName, "E", "H", 20)
+
+	return nil
+}
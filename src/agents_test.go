@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestImageRepository(t *testing.T) {
+	tests := []struct{ image, want string }{
+		{"nginx", "nginx"},
+		{"nginx:1.25", "nginx"},
+		{"localhost:5000/team/app:v1", "localhost:5000/team/app"},
+		{"localhost:5000/team/app", "localhost:5000/team/app"},
+		{"quay.io/prometheus/node-exporter@sha256:abc", "quay.io/prometheus/node-exporter"},
+		{"gcr.io/datadoghq/agent:7@sha256:abc", "gcr.io/datadoghq/agent"},
+	}
+	for _, tt := range tests {
+		if got := imageRepository(tt.image); got != tt.want {
+			t.Errorf("imageRepository(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
+
+func TestAgentSpecMatches(t *testing.T) {
+	tests := []struct {
+		agent    string
+		image    string
+		workload string
+		want     bool
+	}{
+		{"Fluent Bit", "cr.fluentbit.io/fluent/fluent-bit:3.0", "", true},
+		{"Node Exporter", "", "prometheus-node-exporter", true},
+		{"Node Exporter", "quay.io/prometheus/node-exporter:v1.8.0", "", true},
+		{"Datadog Agent", "gcr.io/datadoghq/agent:7", "", true},
+		{"Datadog Agent", "example.com/shop/agent:1", "", false},
+		{"Vector", "timberio/vector:0.38.0-debian", "", true},
+		{"Vector", "example.com/vector-search:1", "", false},
+		{"Prometheus", "example.com/prometheusish:1", "", false},
+	}
+	agents := make(map[string]agentSpec)
+	for _, a := range defaultAgents {
+		agents[a.Name] = a
+	}
+	for _, tt := range tests {
+		if got := agents[tt.agent].matches(tt.image, tt.workload); got != tt.want {
+			t.Errorf("%s.matches(%q, %q) = %v, want %v", tt.agent, tt.image, tt.workload, got, tt.want)
+		}
+	}
+}
+
+func TestParseAgents(t *testing.T) {
+	if _, err := parseAgents(defaultAgents); err != nil {
+		t.Errorf("parseAgents(defaults) error = %v", err)
+	}
+	invalid := [][]agentSpec{
+		{{Category: AgentLogging, Match: []string{"x"}}},
+		{{Name: "x", Match: []string{"x"}}},
+		{{Name: "x", Category: AgentLogging}},
+		{{Name: "x", Category: AgentLogging, Match: []string{"x"}}, {Name: "x", Category: AgentMetrics, Match: []string{"y"}}},
+	}
+	for _, specs := range invalid {
+		if _, err := parseAgents(specs); err == nil {
+			t.Errorf("parseAgents(%+v) expected error", specs)
+		}
+	}
+}
+
+func TestAgentOverheads(t *testing.T) {
+	controller := true
+	daemonPod := func(namespace, name, image string) corev1.Pod {
+		c := testSidecarContainer("main", "100m", "128Mi")
+		c.Image = image
+		reloader := testSidecarContainer("config-reloader", "10m", "16Mi")
+		reloader.Image = "example.com/reloader:1"
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       namespace,
+				OwnerReferences: []metav1.OwnerReference{{Kind: "DaemonSet", Name: name, Controller: &controller}},
+			},
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{c, reloader}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	app := testSidecarContainer("app", "1", "1Gi")
+	app.Image = "example.com/shop/web:1"
+	logs := testSidecarContainer("logs", "50m", "64Mi")
+	logs.Image = "fluent/fluent-bit:3.0"
+	appPod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{app, logs}},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	pods := []corev1.Pod{
+		daemonPod("monitoring", "prometheus-node-exporter", "example.com/mirror/exporter:1"),
+		daemonPod("monitoring", "prometheus-node-exporter", "example.com/mirror/exporter:1"),
+		appPod,
+	}
+
+	rows, clusterCPU, clusterMem := agentOverheads(pods, defaultAgents)
+	if clusterCPU != 2*110+1050 || clusterMem != 2*144*BytesPerMi+1088*BytesPerMi {
+		t.Errorf("cluster totals = %dm, %d bytes", clusterCPU, clusterMem)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("agentOverheads() returned %d rows, want 2: %+v", len(rows), rows)
+	}
+	if rows[0].agent != "Node Exporter" || rows[0].containers != 4 || rows[0].reqCPU != 220 {
+		t.Errorf("rows[0] = %+v, want whole Node Exporter pods by workload name", rows[0])
+	}
+	if rows[1].agent != "Fluent Bit" || rows[1].namespace != "shop" || rows[1].reqCPU != 50 {
+		t.Errorf("rows[1] = %+v, want the Fluent Bit sidecar only", rows[1])
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultAlertAfterFailures is the number of consecutive failed generations
+// that trigger an alert
+const DefaultAlertAfterFailures = 1
+
+// SMTPPasswordEnv holds the password of the alerts email username
+const SMTPPasswordEnv = "SMTP_PASSWORD"
+
+// alertSpec is the alerts section of the config file
+type alertSpec struct {
+	Webhooks           []string        `json:"webhooks,omitempty"`           // URLs receiving a JSON POST {"text": "..."}, e.g. Slack or Mattermost incoming webhooks
+	Email              *alertEmailSpec `json:"email,omitempty"`              // SMTP delivery next to or instead of the webhooks
+	AfterFailures      int             `json:"afterFailures,omitempty"`      // Consecutive failures before alerting, default DefaultAlertAfterFailures
+	DurationSLOSeconds int             `json:"durationSLOSeconds,omitempty"` // Alert when a generation takes longer, 0 = off
+	Conditions         []string        `json:"conditions,omitempty"`         // Validation rules whose findings are sent, see defaultValidationRules
+	StateFile          string          `json:"stateFile,omitempty"`          // Keeps the failure count and sent conditions between one-shot runs, e.g. on a CronJob volume
+}
+
+// alertEmailSpec is the SMTP delivery of alerts; the password is read from
+// SMTPPasswordEnv
+type alertEmailSpec struct {
+	Server   string   `json:"server"` // host:port, STARTTLS when offered
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	Username string   `json:"username,omitempty"` // PLAIN auth, only over TLS or to localhost
+}
+
+// alertState is the failure count and the triggered conditions kept in the state file
+type alertState struct {
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastFailure         time.Time `json:"lastFailure"`
+	Conditions          []string  `json:"conditions,omitempty"` // "<rule> <subject>" of the last sent conditions
+}
+
+// alerter notifies the webhooks and email recipients when report generation
+// fails repeatedly, recovers or exceeds the duration SLO, and when condition
+// rules trigger or clear. A nil alerter does nothing.
+type alerter struct {
+	webhooks      []string
+	email         *alertEmailSpec
+	afterFailures int
+	slo           time.Duration
+	conditions    map[string]bool // Validation rules sent as conditions
+	statePath     string
+	cluster       string
+	client        *http.Client
+
+	mu    sync.Mutex // Server mode observes concurrent regenerations
+	state alertState
+}
+
+// parseAlerts validates the alerts section and loads the state file; a nil
+// spec disables alerts
+func parseAlerts(spec *alertSpec, cluster string) (*alerter, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	if len(spec.Webhooks) == 0 && spec.Email == nil {
+		return nil, fmt.Errorf("alerts need at least one webhook or an email")
+	}
+	for i, hook := range spec.Webhooks {
+		u, err := url.Parse(hook)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return nil, fmt.Errorf("alert webhook %d is not an http(s) URL", i+1)
+		}
+	}
+	if err := spec.Email.validate(); err != nil {
+		return nil, fmt.Errorf("alert email: %w", err)
+	}
+	if spec.AfterFailures < 0 || spec.DurationSLOSeconds < 0 {
+		return nil, fmt.Errorf("alerts afterFailures and durationSLOSeconds must not be negative")
+	}
+	conditions := make(map[string]bool, len(spec.Conditions))
+	for _, rule := range spec.Conditions {
+		if _, ok := defaultValidationRules[rule]; !ok {
+			return nil, fmt.Errorf("unknown alert condition '%s', conditions are validation rules", rule)
+		}
+		conditions[rule] = true
+	}
+
+	a := &alerter{
+		webhooks:      spec.Webhooks,
+		email:         spec.Email,
+		afterFailures: spec.AfterFailures,
+		slo:           time.Duration(spec.DurationSLOSeconds) * time.Second,
+		conditions:    conditions,
+		statePath:     spec.StateFile,
+		cluster:       cluster,
+		client:        &http.Client{Timeout: DefaultAPITimeout},
+	}
+	if a.afterFailures == 0 {
+		a.afterFailures = DefaultAlertAfterFailures
+	}
+	if a.statePath != "" {
+		if err := validatePath(a.statePath); err != nil {
+			return nil, fmt.Errorf("invalid alert state file: %w", err)
+		}
+		data, err := os.ReadFile(a.statePath)
+		switch {
+		case os.IsNotExist(err):
+			// First run
+		case err != nil:
+			return nil, fmt.Errorf("failed to read alert state %s: %w", a.statePath, err)
+		default:
+			if err := json.Unmarshal(data, &a.state); err != nil {
+				logrus.Warnf("Ignoring alert state %s: %v", a.statePath, err)
+			}
+		}
+	}
+	return a, nil
+}
+
+// observe records the outcome of one report generation. It alerts when the
+// failure count reaches afterFailures, once more on recovery, and whenever a
+// generation exceeds the SLO. Findings are a result, not a failure.
+func (a *alerter) observe(err error, duration time.Duration) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	subject := a.subject()
+	if err != nil {
+		a.state.ConsecutiveFailures++
+		a.state.LastFailure = time.Now()
+		if a.state.ConsecutiveFailures == a.afterFailures {
+			a.send(fmt.Sprintf("%s failed %s in a row: %v", subject, pluralize(a.state.ConsecutiveFailures, "time"), err))
+		}
+	} else {
+		if a.state.ConsecutiveFailures >= a.afterFailures {
+			a.send(fmt.Sprintf("%s recovered after %s", subject, pluralize(a.state.ConsecutiveFailures, "failure")))
+		}
+		a.state.ConsecutiveFailures = 0
+	}
+	if a.slo > 0 && duration > a.slo {
+		a.send(fmt.Sprintf("%s took %s, above the SLO of %s", subject, duration.Round(time.Second), a.slo))
+	}
+	a.saveState()
+}
+
+// subject names the report in alert messages
+func (a *alerter) subject() string {
+	if a.cluster != "" {
+		return fmt.Sprintf("Resource report of cluster '%s'", a.cluster)
+	}
+	return "Resource report"
+}
+
+// saveState writes the state file, when configured
+func (a *alerter) saveState() {
+	if a.statePath == "" {
+		return
+	}
+	data, err := json.Marshal(a.state)
+	if err == nil {
+		err = os.WriteFile(a.statePath, data, 0o644)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to write alert state %s: %v", a.statePath, err)
+	}
+}
+
+// watchesConditions reports whether findings are sent as conditions. Nil-safe.
+func (a *alerter) watchesConditions() bool {
+	return a != nil && len(a.conditions) > 0
+}
+
+// conditionRules returns the validation rules with the condition rules enabled:
+// a condition disabled in the validation section is evaluated at info
+func (a *alerter) conditionRules(r validationRules) validationRules {
+	rules := make(map[string]validationRule, len(r.rules))
+	for name, rule := range r.rules {
+		if a.conditions[name] && rule.severity == severityOff {
+			rule.severity = severityInfo
+		}
+		rules[name] = rule
+	}
+	r.rules = rules
+	return r
+}
+
+// notifyConditions sends the open findings of the condition rules when one
+// triggered since the last message, and a message when all cleared. Findings
+// acknowledged in a review are not sent. The sent conditions are kept in the
+// state file, so scheduled runs do not repeat an unchanged message.
+func (a *alerter) notifyConditions(findings []validationFinding) {
+	if !a.watchesConditions() {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var lines []string
+	open := make(map[string]bool)
+	for _, finding := range findings {
+		if !a.conditions[finding.rule] || finding.acknowledged() {
+			continue
+		}
+		message := finding.message
+		if message == "" {
+			message = finding.subject
+		}
+		lines = append(lines, fmt.Sprintf("- [%s] %s: %s", finding.rule, finding.severity, message))
+		open[finding.rule+" "+finding.subject] = true
+	}
+	keys := make([]string, 0, len(open))
+	for key := range open {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	sent := make(map[string]bool, len(a.state.Conditions))
+	for _, key := range a.state.Conditions {
+		sent[key] = true
+	}
+	triggered := false
+	for _, key := range keys {
+		triggered = triggered || !sent[key]
+	}
+	switch {
+	case triggered:
+		a.send(fmt.Sprintf("%s: %s\n%s", a.subject(), pluralize(len(lines), "condition finding"), strings.Join(lines, "\n")))
+	case len(keys) == 0 && len(a.state.Conditions) > 0:
+		a.send(fmt.Sprintf("%s: all conditions cleared", a.subject()))
+	}
+	a.state.Conditions = keys
+	a.saveState()
+}
+
+// send delivers text to every webhook and the email recipients. Failed
+// deliveries are logged by host only, as webhook URLs often contain a secret;
+// they never fail the report.
+func (a *alerter) send(text string) {
+	if a.email != nil {
+		if err := a.email.send(text); err != nil {
+			logrus.Warnf("Failed to send alert email via %s: %v", a.email.Server, err)
+		}
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		logrus.Warnf("Failed to encode alert: %v", err)
+		return
+	}
+	logrus.Warnf("Alert: %s", text)
+	for _, hook := range a.webhooks {
+		host := hook
+		if u, err := url.Parse(hook); err == nil {
+			host = u.Host
+		}
+		resp, err := a.client.Post(hook, "application/json", bytes.NewReader(body))
+		if err != nil {
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				err = urlErr.Err
+			}
+			logrus.Warnf("Failed to send alert to %s: %v", host, err)
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			logrus.Warnf("Failed to send alert to %s: webhook returned %s", host, resp.Status)
+		}
+	}
+}
+
+// validate checks an email spec. Nil-safe.
+func (e *alertEmailSpec) validate() error {
+	if e == nil {
+		return nil
+	}
+	if _, _, err := net.SplitHostPort(e.Server); err != nil {
+		return fmt.Errorf("server must be host:port: %w", err)
+	}
+	if len(e.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	for _, addr := range append([]string{e.From}, e.To...) {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("invalid address '%s': %w", addr, err)
+		}
+	}
+	return nil
+}
+
+// send mails text to the recipients; its first line is the subject
+func (e *alertEmailSpec) send(text string) error {
+	subject, _, _ := strings.Cut(text, "\n")
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", e.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(text, "\n", "\r\n") + "\r\n")
+
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, _ := net.SplitHostPort(e.Server)
+		auth = smtp.PlainAuth("", e.Username, os.Getenv(SMTPPasswordEnv), host)
+	}
+	return smtp.SendMail(e.Server, auth, e.From, e.To, []byte(msg.String()))
+}
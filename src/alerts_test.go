@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAlerterObserve(t *testing.T) {
+	var mu sync.Mutex
+	var alerts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Text string }
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("invalid alert body: %v", err)
+		}
+		mu.Lock()
+		alerts = append(alerts, body.Text)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	state := filepath.Join(t.TempDir(), "alerts.json")
+	spec := &alertSpec{Webhooks: []string{server.URL}, AfterFailures: 2, DurationSLOSeconds: 60, StateFile: state}
+	a, err := parseAlerts(spec, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := errors.New("failed to list pods")
+	a.observe(failed, time.Second)
+	if len(alerts) != 0 {
+		t.Fatalf("alerts after one failure = %v, want none", alerts)
+	}
+
+	// The next one-shot run continues the count from the state file
+	if a, err = parseAlerts(spec, "prod"); err != nil {
+		t.Fatal(err)
+	}
+	a.observe(failed, time.Second)
+	a.observe(failed, time.Second)
+	a.observe(nil, 2*time.Minute)
+	want := []string{
+		"Resource report of cluster 'prod' failed 2 times in a row: failed to list pods",
+		"Resource report of cluster 'prod' recovered after 3 failures",
+		"Resource report of cluster 'prod' took 2m0s, above the SLO of 1m0s",
+	}
+	if strings.Join(alerts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("alerts = %q, want %q", alerts, want)
+	}
+
+	var nilAlerter *alerter
+	nilAlerter.observe(failed, time.Hour) // Must not panic
+
+	for _, spec := range []*alertSpec{
+		{},
+		{Webhooks: []string{"ftp://example.com"}},
+		{Webhooks: []string{server.URL}, AfterFailures: -1},
+		{Webhooks: []string{server.URL}, Conditions: []string{"disk-full"}},
+		{Email: &alertEmailSpec{Server: "smtp.example.com", From: "report@example.com", To: []string{"ops@example.com"}}},
+		{Email: &alertEmailSpec{Server: "smtp.example.com:25", From: "report@example.com"}},
+		{Email: &alertEmailSpec{Server: "smtp.example.com:25", From: "report", To: []string{"ops@example.com"}}},
+	} {
+		if _, err := parseAlerts(spec, ""); err == nil {
+			t.Errorf("parseAlerts(%+v) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestAlerterNotifyConditions(t *testing.T) {
+	var alerts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Text string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		alerts = append(alerts, body.Text)
+	}))
+	defer server.Close()
+
+	state := filepath.Join(t.TempDir(), "alerts.json")
+	spec := &alertSpec{Webhooks: []string{server.URL}, Conditions: []string{RuleCapacitySaturation, RuleSavingsOpportunity}, StateFile: state}
+	a, err := parseAlerts(spec, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Conditions are evaluated even when disabled in the validation section
+	rules := a.conditionRules(validationRules{rules: defaultValidationRules})
+	if rules.rules[RuleCapacitySaturation].severity != severityInfo || rules.rules[RuleNewNamespaceNoLimits].severity != severityOff {
+		t.Errorf("conditionRules() = %+v", rules.rules)
+	}
+	if defaultValidationRules[RuleCapacitySaturation].severity != severityOff {
+		t.Error("conditionRules() changed the defaults")
+	}
+
+	saturated := []validationFinding{
+		{rule: RuleCapacitySaturation, severity: severityInfo, subject: "cluster", message: "CPU requests at 85% of allocatable in cluster (threshold 80%)"},
+		{rule: RuleCapacitySaturation, severity: severityInfo, subject: "cluster", message: "Memory requests at 90% of allocatable in cluster (threshold 80%)"},
+		{rule: RuleNamespaceWithoutLimits, severity: severityWarn, subject: "namespace/shop"}, // Not a condition
+	}
+	a.notifyConditions(saturated)
+	a.notifyConditions(saturated) // Unchanged: not sent again
+
+	// The next one-shot run remembers the sent conditions
+	if a, err = parseAlerts(spec, "prod"); err != nil {
+		t.Fatal(err)
+	}
+	a.notifyConditions(saturated[:1])
+	savings := validationFinding{rule: RuleSavingsOpportunity, severity: severityInfo, subject: "cluster", message: "CPU requests exceed the usage based recommendations by 6.5 cores (threshold 4)"}
+	a.notifyConditions([]validationFinding{saturated[0], savings})
+	a.notifyConditions(nil)
+	a.notifyConditions(nil)
+
+	want := []string{
+		"Resource report of cluster 'prod': 2 condition findings\n" +
+			"- [capacity-saturation] info: CPU requests at 85% of allocatable in cluster (threshold 80%)\n" +
+			"- [capacity-saturation] info: Memory requests at 90% of allocatable in cluster (threshold 80%)",
+		"Resource report of cluster 'prod': 2 condition findings\n" +
+			"- [capacity-saturation] info: CPU requests at 85% of allocatable in cluster (threshold 80%)\n" +
+			"- [savings-opportunity] info: CPU requests exceed the usage based recommendations by 6.5 cores (threshold 4)",
+		"Resource report of cluster 'prod': all conditions cleared",
+	}
+	if strings.Join(alerts, "\n---\n") != strings.Join(want, "\n---\n") {
+		t.Errorf("alerts = %q, want %q", alerts, want)
+	}
+
+	var nilAlerter *alerter
+	if nilAlerter.watchesConditions() {
+		t.Error("nil alerter watches conditions")
+	}
+	nilAlerter.notifyConditions(saturated) // Must not panic
+}
+
+// testSMTPServer accepts one SMTP session on localhost and returns the
+// received message on the channel
+func testSMTPServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case inData && line == ".\r\n":
+				inData = false
+				received <- data.String()
+				reply("250 OK")
+			case inData:
+				data.WriteString(line)
+			case strings.HasPrefix(line, "EHLO"), strings.HasPrefix(line, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				reply("354 End data with <CR><LF>.<CR><LF>")
+			case strings.HasPrefix(line, "QUIT"):
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestAlerterEmail(t *testing.T) {
+	addr, received := testSMTPServer(t)
+	a, err := parseAlerts(&alertSpec{Email: &alertEmailSpec{Server: addr, From: "report@example.com", To: []string{"ops@example.com"}}}, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.notifyConditions([]validationFinding{}) // No conditions configured: nothing sent
+	a.observe(errors.New("failed to list pods"), time.Second)
+
+	select {
+	case msg := <-received:
+		for _, want := range []string{
+			"To: ops@example.com\r\n",
+			"Subject: Resource report of cluster 'prod' failed 1 time in a row: failed to list pods\r\n",
+			"\r\n\r\nResource report of cluster 'prod' failed",
+		} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("message lacks %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no email received")
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+)
+
+// Server mode REST API endpoints
+const (
+	PathAPINamespaces      = "/api/v1/namespaces"
+	PathAPINodes           = "/api/v1/nodes"
+	PathAPIRecommendations = "/api/v1/recommendations"
+	PathAPIOpenAPI         = "/api/v1/openapi.json"
+)
+
+// Pagination of list endpoints
+const (
+	APIDefaultLimit = 100
+	APIMaxLimit     = 1000
+)
+
+// APIVersion is the version of the REST API in the OpenAPI document
+const APIVersion = "1.0.0"
+
+// apiNamespace is a namespace item of the REST API
+type apiNamespace = report.Namespace
+
+// apiNode is a node item of the REST API
+type apiNode = report.Node
+
+// apiPage is the envelope of list responses
+type apiPage struct {
+	Generated  time.Time   `json:"generated"` // Data timestamp of the snapshot
+	Total      int         `json:"total"`     // Items after filtering
+	Offset     int         `json:"offset"`
+	Limit      int         `json:"limit"`
+	NextOffset *int        `json:"nextOffset,omitempty"` // Omitted on the last page
+	Items      interface{} `json:"items"`
+}
+
+// apiError is the body of error responses
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// apiViews are the REST API items of one snapshot, built once per regeneration
+type apiViews struct {
+	generated       time.Time
+	namespaces      []apiNamespace
+	nodes           []apiNode
+	recommendations []findingsRecord
+}
+
+// buildAPIViews aggregates a snapshot into the REST API items, sorted by name.
+// Recommendations are the validation findings, most severe first.
+func buildAPIViews(snap *clusterSnapshot, rules validationRules, meta reportMetadata) *apiViews {
+	namespaceTotals, nodeTotals := aggregateTotals(snap.pods, snap.nodes)
+	views := &apiViews{generated: snap.collected}
+	views.namespaces, views.nodes = report.Summarize(snap.pods, snap.namespaces, snap.nodes)
+
+	findings := validateResources(validationInput{
+		namespaceTotals:  namespaceTotals,
+		nodeTotals:       nodeTotals,
+		saturation:       saturationByPool(snap.nodes, nodeTotals),
+		namespaceCreated: namespaceCreation(snap.namespaces),
+		specDrift:        workloadSpecDrift(snap.pods),
+		now:              snap.collected,
+	}, rules)
+	meta.generated = snap.collected
+	views.recommendations = buildFindingsReport(findings, meta).Findings
+	return views
+}
+
+// apiParam is a query parameter of a list endpoint
+type apiParam struct {
+	name, description string
+}
+
+// apiRoute is a list endpoint; the OpenAPI document is generated from these
+type apiRoute struct {
+	path, summary string
+	params        []apiParam  // Filters, limit and offset are added to every route
+	schema        string      // Component schema name of the items
+	item          interface{} // Zero item, for the response schema
+	list          func(v *apiViews, q url.Values) ([]interface{}, error)
+}
+
+// apiRoutes are the REST API list endpoints
+var apiRoutes = []apiRoute{
+	{
+		path:    PathAPINamespaces,
+		summary: "Requests and limits per namespace",
+		params: []apiParam{
+			{"name", "Only namespaces whose name contains this text"},
+			{"phase", "Only namespaces in this phase (Active, Terminating)"},
+		},
+		schema: "Namespace",
+		item:   apiNamespace{},
+		list: func(v *apiViews, q url.Values) ([]interface{}, error) {
+			var items []interface{}
+			for _, ns := range v.namespaces {
+				if !strings.Contains(ns.Name, q.Get("name")) || (q.Get("phase") != "" && !strings.EqualFold(ns.Phase, q.Get("phase"))) {
+					continue
+				}
+				items = append(items, ns)
+			}
+			return items, nil
+		},
+	},
+	{
+		path:    PathAPINodes,
+		summary: "Pods, requests, limits and allocatable capacity per node",
+		params: []apiParam{
+			{"name", "Only nodes whose name or IP contains this text"},
+			{"pool", "Only nodes of this node pool"},
+		},
+		schema: "Node",
+		item:   apiNode{},
+		list: func(v *apiViews, q url.Values) ([]interface{}, error) {
+			var items []interface{}
+			for _, node := range v.nodes {
+				name := q.Get("name")
+				if !strings.Contains(node.Name, name) && !strings.Contains(node.IP, name) {
+					continue
+				}
+				if q.Get("pool") != "" && node.Pool != q.Get("pool") {
+					continue
+				}
+				items = append(items, node)
+			}
+			return items, nil
+		},
+	},
+	{
+		path:    PathAPIRecommendations,
+		summary: "Validation findings, most severe first",
+		params: []apiParam{
+			{"severity", "Minimum severity: info, warn or error"},
+			{"rule", "Only findings of this rule"},
+			{"subject", "Only findings whose subject contains this text, e.g. namespace/shop"},
+		},
+		schema: "Recommendation",
+		item:   findingsRecord{},
+		list: func(v *apiViews, q url.Values) ([]interface{}, error) {
+			minSeverity := severityInfo
+			if value := q.Get("severity"); value != "" {
+				s, err := parseSeverity(value)
+				if err != nil {
+					return nil, err
+				}
+				minSeverity = s
+			}
+			var items []interface{}
+			for _, r := range v.recommendations {
+				s, _ := parseSeverity(r.Severity)
+				if s < minSeverity || (q.Get("rule") != "" && r.Rule != q.Get("rule")) || !strings.Contains(r.Subject, q.Get("subject")) {
+					continue
+				}
+				items = append(items, r)
+			}
+			return items, nil
+		},
+	},
+}
+
+// parsePage reads limit and offset query parameters
+func parsePage(q url.Values) (limit, offset int, err error) {
+	limit = APIDefaultLimit
+	if value := q.Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil || limit < 1 || limit > APIMaxLimit {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", APIMaxLimit)
+		}
+	}
+	if value := q.Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
+
+// paginate returns one page of filtered items
+func paginate(items []interface{}, limit, offset int, generated time.Time) apiPage {
+	page := apiPage{Generated: generated, Total: len(items), Offset: offset, Limit: limit, Items: []interface{}{}}
+	if offset < len(items) {
+		end := offset + limit
+		if end > len(items) {
+			end = len(items)
+		}
+		page.Items = items[offset:end]
+		if end < len(items) {
+			page.NextOffset = &end
+		}
+	}
+	return page
+}
+
+// query returns one page of the items of route matching the filters and page
+// parameters of q
+func (v *apiViews) query(route apiRoute, q url.Values) (apiPage, error) {
+	limit, offset, err := parsePage(q)
+	if err != nil {
+		return apiPage{}, err
+	}
+	items, err := route.list(v, q)
+	if err != nil {
+		return apiPage{}, err
+	}
+	return paginate(items, limit, offset, v.generated), nil
+}
+
+// handleAPI registers the REST API routes and the OpenAPI document on mux
+func (s *reportServer) handleAPI(mux *http.ServeMux) {
+	for _, route := range apiRoutes {
+		mux.HandleFunc(route.path, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", http.MethodGet)
+				writeJSON(w, http.StatusMethodNotAllowed, apiError{"method not allowed"})
+				return
+			}
+			views := s.apiViews()
+			if views == nil {
+				writeJSON(w, http.StatusServiceUnavailable, apiError{"no snapshot collected yet"})
+				return
+			}
+			page, err := views.query(route, r.URL.Query())
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, apiError{err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, page)
+		})
+	}
+	mux.HandleFunc(PathAPIOpenAPI, func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, openAPIDocument())
+	})
+}
+
+// openAPIDocument generates the OpenAPI 3.0 description of the list endpoints
+// from apiRoutes and the JSON tags of the item types
+func openAPIDocument() map[string]interface{} {
+	schemas := map[string]interface{}{
+		"Error": jsonSchema(reflect.TypeOf(apiError{})),
+	}
+	paths := make(map[string]interface{})
+	for _, route := range apiRoutes {
+		schemas[route.schema] = jsonSchema(reflect.TypeOf(route.item))
+
+		page := jsonSchema(reflect.TypeOf(apiPage{}))
+		page["properties"].(map[string]interface{})["items"] = map[string]interface{}{
+			"type":  "array",
+			"items": map[string]interface{}{"$ref": "#/components/schemas/" + route.schema},
+		}
+
+		params := append([]apiParam{}, route.params...)
+		params = append(params,
+			apiParam{"limit", fmt.Sprintf("Page size, 1 to %d (default %d)", APIMaxLimit, APIDefaultLimit)},
+			apiParam{"offset", "Index of the first item (default 0)"},
+		)
+		var parameters []interface{}
+		for _, p := range params {
+			schemaType := "string"
+			if p.name == "limit" || p.name == "offset" {
+				schemaType = "integer"
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name": p.name, "in": "query", "description": p.description,
+				"schema": map[string]interface{}{"type": schemaType},
+			})
+		}
+
+		errorResponse := func(description string) map[string]interface{} {
+			return map[string]interface{}{
+				"description": description,
+				"content": map[string]interface{}{"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+				}},
+			}
+		}
+		paths[route.path] = map[string]interface{}{
+			"get": map[string]interface{}{
+				"summary":    route.summary,
+				"parameters": parameters,
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "One page of items",
+						"content":     map[string]interface{}{"application/json": map[string]interface{}{"schema": page}},
+					},
+					"400": errorResponse("Invalid filter or pagination parameter"),
+					"503": errorResponse("No snapshot collected yet"),
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi":    "3.0.3",
+		"info":       map[string]interface{}{"title": ToolName + " API", "version": APIVersion},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": schemas},
+	}
+}
+
+// jsonSchema derives an OpenAPI schema from a Go type and its JSON tags. Fields
+// without omitempty are required.
+func jsonSchema(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		return jsonSchema(t.Elem())
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.Slice:
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem())}
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			name, options, _ := strings.Cut(tag, ",")
+			properties[name] = jsonSchema(field.Type)
+			if !strings.Contains(options, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		schema := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testAPISnapshot(now time.Time) *clusterSnapshot {
+	pod := func(namespace, name, node, ip, cpu string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: corev1.PodSpec{NodeName: node, Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, HostIP: ip},
+		}
+	}
+	node := func(name, ip, pool string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{report.NodePoolLabels[0]: pool}},
+			Status: corev1.NodeStatus{
+				Addresses:   []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: ip}},
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("16Gi")},
+			},
+		}
+	}
+	return &clusterSnapshot{
+		collected: now,
+		pods: []corev1.Pod{
+			pod("shop", "web-1", "n1", "10.0.0.1", "500m"),
+			pod("shop", "web-2", "n2", "10.0.0.2", "500m"),
+			pod("search", "idx-1", "n2", "10.0.0.2", "2"),
+		},
+		namespaces: &corev1.NamespaceList{Items: []corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "shop", CreationTimestamp: metav1.NewTime(now.AddDate(0, -1, 0))}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "search", CreationTimestamp: metav1.NewTime(now.AddDate(0, -1, 0))}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating}},
+		}},
+		nodes: &corev1.NodeList{Items: []corev1.Node{node("n1", "10.0.0.1", "general"), node("n2", "10.0.0.2", "batch")}},
+	}
+}
+
+func TestBuildAPIViews(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	rules, _ := parseValidationRules(validationSpec{})
+	views := buildAPIViews(testAPISnapshot(now), rules, reportMetadata{})
+
+	wantNamespaces := []apiNamespace{
+		{Name: "search", Phase: "Terminating", RequestCPUMillicores: 2000, RequestMemoryBytes: BytesPerGi},
+		{Name: "shop", Phase: "Active", RequestCPUMillicores: 1000, RequestMemoryBytes: 2 * BytesPerGi},
+	}
+	if !reflect.DeepEqual(views.namespaces, wantNamespaces) {
+		t.Errorf("namespaces = %+v, want %+v", views.namespaces, wantNamespaces)
+	}
+
+	if len(views.nodes) != 2 {
+		t.Fatalf("nodes = %+v, want 2", views.nodes)
+	}
+	n2 := views.nodes[1]
+	if n2.Name != "n2" || n2.Pool != "batch" || n2.Pods != 2 || n2.AllocatableCPUMillicores != 4000 || n2.CPURequestRatio == nil || *n2.CPURequestRatio != 0.625 {
+		t.Errorf("n2 = %+v", n2)
+	}
+
+	// Both namespaces have no limits
+	if len(views.recommendations) != 2 || views.recommendations[0].Subject != "namespace/search" {
+		t.Errorf("recommendations = %+v", views.recommendations)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	tests := []struct {
+		query         string
+		limit, offset int
+		wantErr       bool
+	}{
+		{"", APIDefaultLimit, 0, false},
+		{"limit=10&offset=20", 10, 20, false},
+		{"limit=0", 0, 0, true},
+		{"limit=5000", 0, 0, true},
+		{"offset=-1", 0, 0, true},
+		{"limit=ten", 0, 0, true},
+	}
+	for _, tt := range tests {
+		q, _ := url.ParseQuery(tt.query)
+		limit, offset, err := parsePage(q)
+		if (err != nil) != tt.wantErr || limit != tt.limit || offset != tt.offset {
+			t.Errorf("parsePage(%q) = %d, %d, %v", tt.query, limit, offset, err)
+		}
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	items := []interface{}{1, 2, 3, 4, 5}
+	page := paginate(items, 2, 2, time.Time{})
+	if !reflect.DeepEqual(page.Items, []interface{}{3, 4}) || page.NextOffset == nil || *page.NextOffset != 4 || page.Total != 5 {
+		t.Errorf("paginate(offset 2) = %+v", page)
+	}
+	if page := paginate(items, 2, 4, time.Time{}); !reflect.DeepEqual(page.Items, []interface{}{5}) || page.NextOffset != nil {
+		t.Errorf("paginate(last page) = %+v", page)
+	}
+	if page := paginate(items, 2, 10, time.Time{}); !reflect.DeepEqual(page.Items, []interface{}{}) {
+		t.Errorf("paginate(beyond end) = %+v, want empty items", page)
+	}
+}
+
+func TestAPIEndpoints(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	rules, _ := parseValidationRules(validationSpec{})
+	s := &reportServer{}
+	handler := s.handler()
+
+	get := func(target string) (int, map[string]interface{}) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		var body map[string]interface{}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatalf("GET %s: %v", target, err)
+		}
+		return rec.Code, body
+	}
+
+	if code, _ := get(PathAPINamespaces); code != http.StatusServiceUnavailable {
+		t.Errorf("GET %s before the first build = %d, want 503", PathAPINamespaces, code)
+	}
+
+	s.views = buildAPIViews(testAPISnapshot(now), rules, reportMetadata{})
+	tests := []struct {
+		target string
+		code   int
+		total  float64
+	}{
+		{PathAPINamespaces, http.StatusOK, 2},
+		{PathAPINamespaces + "?name=sh", http.StatusOK, 1},
+		{PathAPINamespaces + "?phase=terminating", http.StatusOK, 1},
+		{PathAPINodes + "?pool=batch", http.StatusOK, 1},
+		{PathAPINodes + "?name=10.0.0", http.StatusOK, 2},
+		{PathAPIRecommendations + "?severity=warn", http.StatusOK, 2},
+		{PathAPIRecommendations + "?subject=namespace/shop", http.StatusOK, 1},
+		{PathAPIRecommendations + "?severity=error", http.StatusOK, 0},
+		{PathAPIRecommendations + "?severity=fatal", http.StatusBadRequest, 0},
+		{PathAPINodes + "?limit=0", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		code, body := get(tt.target)
+		if code != tt.code {
+			t.Errorf("GET %s = %d, want %d", tt.target, code, tt.code)
+			continue
+		}
+		if code == http.StatusOK && body["total"] != tt.total {
+			t.Errorf("GET %s total = %v, want %v", tt.target, body["total"], tt.total)
+		}
+		if code != http.StatusOK && body["error"] == "" {
+			t.Errorf("GET %s returned no error message", tt.target)
+		}
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc := openAPIDocument()
+	if _, err := json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+	paths := doc["paths"].(map[string]interface{})
+	for _, path := range []string{PathAPINamespaces, PathAPINodes, PathAPIRecommendations} {
+		if paths[path] == nil {
+			t.Errorf("OpenAPI document lacks %s", path)
+		}
+	}
+
+	schemas := doc["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	node, ok := schemas["Node"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("schemas = %v, want a Node schema", schemas)
+	}
+	properties := node["properties"].(map[string]interface{})
+	if properties["cpuRequestRatio"].(map[string]interface{})["type"] != "number" {
+		t.Errorf("cpuRequestRatio = %v, want number", properties["cpuRequestRatio"])
+	}
+	required := node["required"].([]string)
+	if !reflect.DeepEqual(required[:2], []string{"ip", "pods"}) {
+		t.Errorf("required = %v, want ip and pods first, optional name and pool omitted", required)
+	}
+	if schemas["Recommendation"] == nil {
+		t.Errorf("schemas = %v, want a Recommendation schema", schemas)
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ArchLabel is the well-known node label holding the CPU architecture
+const ArchLabel = "kubernetes.io/arch"
+
+// Placeholders for pods without a (known) node architecture
+const (
+	ArchUnknown     = "unknown"
+	ArchUnscheduled = "unscheduled"
+)
+
+// How a workload is pinned to one architecture
+const (
+	ArchByNodeSelector = "nodeSelector"
+	ArchByNodeAffinity = "nodeAffinity"
+)
+
+// nodeArch returns the CPU architecture of a node from its label or node info
+func nodeArch(node *corev1.Node) string {
+	if arch := node.Labels[ArchLabel]; arch != "" {
+		return arch
+	}
+	if arch := node.Status.NodeInfo.Architecture; arch != "" {
+		return arch
+	}
+	return ArchUnknown
+}
+
+// podArchRestriction returns the single architecture a pod is pinned to by
+// nodeSelector or required node affinity, or "" when it may run on several
+func podArchRestriction(pod *corev1.Pod) (arch, by string) {
+	if arch := pod.Spec.NodeSelector[ArchLabel]; arch != "" {
+		return arch, ArchByNodeSelector
+	}
+
+	affinity := pod.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return "", ""
+	}
+	// Terms are ORed: every term has to allow exactly the same single architecture
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	for _, term := range terms {
+		termArch := ""
+		for _, expr := range term.MatchExpressions {
+			if expr.Key == ArchLabel && expr.Operator == corev1.NodeSelectorOpIn && len(expr.Values) == 1 {
+				termArch = expr.Values[0]
+			}
+		}
+		if termArch == "" || (arch != "" && termArch != arch) {
+			return "", ""
+		}
+		arch = termArch
+	}
+	if arch == "" {
+		return "", ""
+	}
+	return arch, ArchByNodeAffinity
+}
+
+// archTotal sums pods, requests and allocatable capacity of one architecture
+type archTotal struct {
+	arch             string
+	nodes, pods      int
+	reqCPU, allocCPU int64 // millicores
+	reqMem, allocMem int64 // bytes
+}
+
+// archNamespace sums the requests of a namespace's pods on one architecture
+type archNamespace struct {
+	namespace, arch string
+	pods            int
+	reqCPU, reqMem  int64
+}
+
+// archRestricted is a workload pinned to a single architecture
+type archRestricted struct {
+	workload       workloadKey
+	arch, by       string
+	pods           int
+	reqCPU, reqMem int64
+}
+
+// archSegments aggregates nodes and namespace requests by the architecture of the
+// node each pod runs on, and lists workloads pinned to one architecture
+func archSegments(pods []corev1.Pod, nodes *corev1.NodeList) ([]archTotal, []archNamespace, []archRestricted) {
+	archByNode := make(map[string]string)
+	totals := make(map[string]*archTotal)
+	total := func(arch string) *archTotal {
+		if totals[arch] == nil {
+			totals[arch] = &archTotal{arch: arch}
+		}
+		return totals[arch]
+	}
+	if nodes != nil {
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			arch := nodeArch(node)
+			archByNode[node.Name] = arch
+			t := total(arch)
+			t.nodes++
+			t.allocCPU += node.Status.Allocatable.Cpu().MilliValue()
+			t.allocMem += node.Status.Allocatable.Memory().Value()
+		}
+	}
+
+	type nsKey struct{ namespace, arch string }
+	namespaces := make(map[nsKey]*archNamespace)
+	restricted := make(map[workloadKey]*archRestricted)
+
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		var cpu, mem int64
+		for _, c := range pod.Spec.Containers {
+			cpu += quantityMilli(c.Resources.Requests.Cpu())
+			mem += quantityBytes(c.Resources.Requests.Memory())
+		}
+
+		arch := ArchUnscheduled
+		if pod.Spec.NodeName != "" {
+			arch = ArchUnknown
+			if a, ok := archByNode[pod.Spec.NodeName]; ok {
+				arch = a
+			}
+		}
+		t := total(arch)
+		t.pods++
+		t.reqCPU += cpu
+		t.reqMem += mem
+
+		ns := namespaces[nsKey{pod.Namespace, arch}]
+		if ns == nil {
+			ns = &archNamespace{namespace: pod.Namespace, arch: arch}
+			namespaces[nsKey{pod.Namespace, arch}] = ns
+		}
+		ns.pods++
+		ns.reqCPU += cpu
+		ns.reqMem += mem
+
+		if pinned, by := podArchRestriction(pod); pinned != "" {
+			w := workloadOf(pod)
+			r := restricted[w]
+			if r == nil {
+				r = &archRestricted{workload: w, arch: pinned, by: by}
+				restricted[w] = r
+			}
+			r.pods++
+			r.reqCPU += cpu
+			r.reqMem += mem
+		}
+	}
+
+	archs := make([]archTotal, 0, len(totals))
+	for _, t := range totals {
+		archs = append(archs, *t)
+	}
+	sort.Slice(archs, func(i, j int) bool { return archs[i].arch < archs[j].arch })
+
+	nsRows := make([]archNamespace, 0, len(namespaces))
+	for _, ns := range namespaces {
+		nsRows = append(nsRows, *ns)
+	}
+	sort.Slice(nsRows, func(i, j int) bool {
+		if nsRows[i].namespace != nsRows[j].namespace {
+			return nsRows[i].namespace < nsRows[j].namespace
+		}
+		return nsRows[i].arch < nsRows[j].arch
+	})
+
+	pinned := make([]archRestricted, 0, len(restricted))
+	for _, r := range restricted {
+		pinned = append(pinned, *r)
+	}
+	sort.Slice(pinned, func(i, j int) bool {
+		if pinned[i].reqCPU != pinned[j].reqCPU {
+			return pinned[i].reqCPU > pinned[j].reqCPU
+		}
+		return pinned[i].workload.String() < pinned[j].workload.String()
+	})
+
+	return archs, nsRows, pinned
+}
+
+// createArchSheet writes capacity per architecture, namespace requests per
+// architecture and the workloads pinned to one architecture
+func createArchSheet(f *excelize.File, archs []archTotal, namespaces []archNamespace, pinned []archRestricted, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create architecture sheet: %w", err)
+	}
+
+	section := func(row int, title string, headers []string) error {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), title)
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
+		if err := f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row+1), &headers); err != nil {
+			return fmt.Errorf("failed to set headers: %w", err)
+		}
+		return nil
+	}
+
+	// Capacity and requests per architecture
+	if err := section(1, "Nodes by Architecture", []string{
+		"Architecture", "Nodes", "Pods", "Request CPU (cores)", "Allocatable CPU (cores)", "CPU %",
+		"Request Memory (Gi)", "Allocatable Memory (Gi)", "Memory %",
+	}); err != nil {
+		return err
+	}
+	row := 3
+	for _, a := range archs {
+		data := []interface{}{
+			a.arch, a.nodes, a.pods,
+			milliToCores(a.reqCPU), milliToCores(a.allocCPU), ratio(a.reqCPU, a.allocCPU),
+			bytesToGi(a.reqMem), bytesToGi(a.allocMem), ratio(a.reqMem, a.allocMem),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("architecture '%s'", a.arch)); err != nil {
+			return err
+		}
+		row++
+	}
+	if row > 3 {
+		f.SetCellStyle(sheetName, "D3", fmt.Sprintf("E%d", row-1), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "G3", fmt.Sprintf("H%d", row-1), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "F3", fmt.Sprintf("F%d", row-1), export.PercentStyle(f, "0.0%"))
+		f.SetCellStyle(sheetName, "I3", fmt.Sprintf("I%d", row-1), export.PercentStyle(f, "0.0%"))
+	}
+
+	// Namespace requests per architecture
+	row += 2
+	if err := section(row, "Namespace Requests by Architecture", []string{
+		"Namespace", "Architecture", "Pods", "Request CPU (cores)", "Request Memory (Gi)",
+	}); err != nil {
+		return err
+	}
+	row += 2
+	start := row
+	for _, ns := range namespaces {
+		data := []interface{}{ns.namespace, ns.arch, ns.pods, milliToCores(ns.reqCPU), bytesToGi(ns.reqMem)}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("namespace '%s' architecture '%s'", ns.namespace, ns.arch)); err != nil {
+			return err
+		}
+		row++
+	}
+	if row > start {
+		f.SetCellStyle(sheetName, fmt.Sprintf("D%d", start), fmt.Sprintf("E%d", row-1), export.DecimalStyle(f, false))
+	}
+
+	// Workloads that cannot move to another architecture as is
+	row += 2
+	if err := section(row, "Workloads Pinned to One Architecture", []string{
+		"Workload", "Architecture", "Pinned By", "Pods", "Request CPU (cores)", "Request Memory (Gi)",
+	}); err != nil {
+		return err
+	}
+	row += 2
+	start = row
+	for _, p := range pinned {
+		data := []interface{}{p.workload.String(), p.arch, p.by, p.pods, milliToCores(p.reqCPU), bytesToGi(p.reqMem)}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("workload '%s'", p.workload)); err != nil {
+			return err
+		}
+		row++
+	}
+	if row > start {
+		f.SetCellStyle(sheetName, fmt.Sprintf("E%d", start), fmt.Sprintf("F%d", row-1), export.DecimalStyle(f, false))
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", "C", 14)
+	f.SetColWidth(sheetName, "D", "I", 22)
+
+	return nil
+}
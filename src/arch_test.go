@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func archAffinity(terms ...[]string) *corev1.Affinity {
+	selector := &corev1.NodeSelector{}
+	for _, values := range terms {
+		selector.NodeSelectorTerms = append(selector.NodeSelectorTerms, corev1.NodeSelectorTerm{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: ArchLabel, Operator: corev1.NodeSelectorOpIn, Values: values}},
+		})
+	}
+	return &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{RequiredDuringSchedulingIgnoredDuringExecution: selector}}
+}
+
+func TestPodArchRestriction(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     corev1.PodSpec
+		arch, by string
+	}{
+		{"none", corev1.PodSpec{}, "", ""},
+		{"nodeSelector", corev1.PodSpec{NodeSelector: map[string]string{ArchLabel: "amd64"}}, "amd64", ArchByNodeSelector},
+		{"affinity", corev1.PodSpec{Affinity: archAffinity([]string{"arm64"})}, "arm64", ArchByNodeAffinity},
+		{"affinity both archs", corev1.PodSpec{Affinity: archAffinity([]string{"amd64", "arm64"})}, "", ""},
+		{"terms agree", corev1.PodSpec{Affinity: archAffinity([]string{"arm64"}, []string{"arm64"})}, "arm64", ArchByNodeAffinity},
+		{"terms differ", corev1.PodSpec{Affinity: archAffinity([]string{"amd64"}, []string{"arm64"})}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := corev1.Pod{Spec: tt.spec}
+			arch, by := podArchRestriction(&pod)
+			if arch != tt.arch || by != tt.by {
+				t.Errorf("podArchRestriction() = %q, %q, want %q, %q", arch, by, tt.arch, tt.by)
+			}
+		})
+	}
+}
+
+func TestArchSegments(t *testing.T) {
+	node := func(name, arch string) corev1.Node {
+		n := corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if arch != "" {
+			n.Labels = map[string]string{ArchLabel: arch}
+		}
+		n.Status.NodeInfo.Architecture = "amd64"
+		n.Status.Allocatable = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("16Gi"),
+		}
+		return n
+	}
+	nodes := &corev1.NodeList{Items: []corev1.Node{node("x86-1", "amd64"), node("arm-1", "arm64"), node("legacy", "")}}
+
+	pod := func(namespace, name, nodeName string, selector map[string]string) corev1.Pod {
+		p := testDeliveryPod(name, name[:len(name)-2], nil, "1")
+		p.Namespace = namespace
+		p.Spec.NodeName = nodeName
+		p.Spec.NodeSelector = selector
+		return p
+	}
+	pods := []corev1.Pod{
+		pod("shop", "web-1", "x86-1", map[string]string{ArchLabel: "amd64"}),
+		pod("shop", "web-2", "legacy", map[string]string{ArchLabel: "amd64"}),
+		pod("shop", "api-1", "arm-1", nil),
+		pod("search", "idx-1", "", nil),
+	}
+
+	archs, namespaces, pinned := archSegments(pods, nodes)
+
+	want := map[string]struct{ nodes, pods int }{"amd64": {2, 2}, "arm64": {1, 1}, ArchUnscheduled: {0, 1}}
+	if len(archs) != len(want) {
+		t.Fatalf("archSegments() returned %d architectures, want %d: %+v", len(archs), len(want), archs)
+	}
+	for _, a := range archs {
+		if w := want[a.arch]; a.nodes != w.nodes || a.pods != w.pods {
+			t.Errorf("%s = %d nodes, %d pods, want %d, %d", a.arch, a.nodes, a.pods, w.nodes, w.pods)
+		}
+	}
+	if archs[0].arch != "amd64" || archs[0].allocCPU != 8000 || archs[0].reqCPU != 2000 {
+		t.Errorf("archs[0] = %+v", archs[0])
+	}
+
+	if len(namespaces) != 3 || namespaces[1] != (archNamespace{namespace: "shop", arch: "amd64", pods: 2, reqCPU: 2000, reqMem: 512 * BytesPerMi}) {
+		t.Errorf("namespaces = %+v", namespaces)
+	}
+
+	if len(pinned) != 1 || pinned[0].arch != "amd64" || pinned[0].by != ArchByNodeSelector || pinned[0].pods != 2 {
+		t.Errorf("pinned = %+v, want web with 2 amd64 pods", pinned)
+	}
+}
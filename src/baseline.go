@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultBaselineThreshold is the request change against the baseline, in
+// percent, from which namespaces and workloads are marked
+const DefaultBaselineThreshold = 20
+
+// Baseline states of a namespace or workload
+const (
+	BaselineDeviates = "deviates"
+	BaselineNew      = "new"
+	BaselineRemoved  = "removed"
+)
+
+// baselineRequests are the summed requests of a namespace or workload
+type baselineRequests struct {
+	CPU    int64 `json:"cpu"`    // millicores
+	Memory int64 `json:"memory"` // bytes
+}
+
+// baselineSnapshot is the stored request baseline written with -save-baseline
+// and compared against with -baseline. Workloads are keyed by
+// namespace/kind/name.
+type baselineSnapshot struct {
+	Collected  time.Time                   `json:"collected"`
+	Namespaces map[string]baselineRequests `json:"namespaces"`
+	Workloads  map[string]baselineRequests `json:"workloads"`
+}
+
+// newBaseline sums the requests of the active pods per namespace and workload
+func newBaseline(pods []corev1.Pod, collected time.Time) *baselineSnapshot {
+	b := &baselineSnapshot{
+		Collected:  collected,
+		Namespaces: make(map[string]baselineRequests),
+		Workloads:  make(map[string]baselineRequests),
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		cpu, mem := podRequests(pod)
+		ns := b.Namespaces[pod.Namespace]
+		ns.CPU += cpu
+		ns.Memory += mem
+		b.Namespaces[pod.Namespace] = ns
+		key := workloadOf(pod).String()
+		w := b.Workloads[key]
+		w.CPU += cpu
+		w.Memory += mem
+		b.Workloads[key] = w
+	}
+	return b
+}
+
+// loadBaseline reads a baseline file
+func loadBaseline(path string) (*baselineSnapshot, error) {
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid baseline path: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline %s: %w", path, err)
+	}
+	var b baselineSnapshot
+	if err := yaml.UnmarshalStrict(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	if b.Collected.IsZero() {
+		return nil, fmt.Errorf("baseline %s has no collection time", path)
+	}
+	return &b, nil
+}
+
+// writeBaseline writes the baseline of a run to path
+func writeBaseline(path string, b *baselineSnapshot) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write baseline %s: %w", path, err)
+	}
+	return nil
+}
+
+// baselineDeviation is a namespace or workload compared against the baseline
+type baselineDeviation struct {
+	name              string
+	baseline, current baselineRequests
+	state             string // BaselineDeviates, BaselineNew, BaselineRemoved or empty within the threshold
+}
+
+// cpuChange returns the CPU request change as a share of the baseline, nil
+// without baseline requests
+func (d baselineDeviation) cpuChange() interface{} {
+	return ratio(d.current.CPU-d.baseline.CPU, d.baseline.CPU)
+}
+
+// memChange returns the memory request change as a share of the baseline, nil
+// without baseline requests
+func (d baselineDeviation) memChange() interface{} {
+	return ratio(d.current.Memory-d.baseline.Memory, d.baseline.Memory)
+}
+
+// compareBaseline compares the current requests with the baseline by name. A
+// change of more than threshold percent of CPU or memory deviates; names of
+// only one side are new or removed.
+func compareBaseline(baseline, current map[string]baselineRequests, threshold int) []baselineDeviation {
+	names := make(map[string]bool, len(baseline)+len(current))
+	for name := range baseline {
+		names[name] = true
+	}
+	for name := range current {
+		names[name] = true
+	}
+
+	limit := float64(threshold) / 100
+	exceeds := func(before, after int64) bool {
+		if before == 0 {
+			return after != 0
+		}
+		return math.Abs(float64(after-before))/float64(before) > limit
+	}
+	result := make([]baselineDeviation, 0, len(names))
+	for name := range names {
+		b, inBaseline := baseline[name]
+		c, inCurrent := current[name]
+		d := baselineDeviation{name: name, baseline: b, current: c}
+		switch {
+		case !inBaseline:
+			d.state = BaselineNew
+		case !inCurrent:
+			d.state = BaselineRemoved
+		case exceeds(b.CPU, c.CPU) || exceeds(b.Memory, c.Memory):
+			d.state = BaselineDeviates
+		}
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+// createBaselineSheet lists every namespace and the workloads outside the
+// threshold with their requests in the baseline and now, marking the rows that
+// left the threshold
+func createBaselineSheet(f *excelize.File, baseline, current *baselineSnapshot, threshold int, sheetName string, t theme) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create baseline sheet: %w", err)
+	}
+
+	f.SetCellValue(sheetName, "A1", fmt.Sprintf("Requests against the baseline of %s; changes above %d%% are marked", baseline.Collected.Format("2006-01-02 15:04"), threshold))
+
+	colors := t.efficiency[0]
+	markStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: colors.font},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{colors.fill}, Pattern: 1},
+	})
+	decimalStyle := export.DecimalStyle(f, false)
+	percentStyle := export.PercentStyle(f, "+0.0%;-0.0%;0.0%")
+
+	headers := func(row int, first string) error {
+		data := []interface{}{
+			first, "Baseline CPU (cores)", "Current CPU (cores)", "CPU Change",
+			"Baseline Memory (Gi)", "Current Memory (Gi)", "Memory Change", "State",
+		}
+		if err := export.SetRow(f, sheetName, row, data, "baseline headers"); err != nil {
+			return err
+		}
+		return f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("H%d", row), export.BoldStyle(f))
+	}
+	writeRows := func(row int, deviations []baselineDeviation, kind string) (int, error) {
+		for _, d := range deviations {
+			data := []interface{}{
+				d.name,
+				milliToCores(d.baseline.CPU),
+				milliToCores(d.current.CPU),
+				d.cpuChange(),
+				bytesToGi(d.baseline.Memory),
+				bytesToGi(d.current.Memory),
+				d.memChange(),
+				d.state,
+			}
+			if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("%s '%s'", kind, d.name)); err != nil {
+				return row, err
+			}
+			f.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("C%d", row), decimalStyle)
+			f.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("D%d", row), percentStyle)
+			f.SetCellStyle(sheetName, fmt.Sprintf("E%d", row), fmt.Sprintf("F%d", row), decimalStyle)
+			f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("G%d", row), percentStyle)
+			if d.state != "" {
+				f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), markStyle)
+				f.SetCellStyle(sheetName, fmt.Sprintf("H%d", row), fmt.Sprintf("H%d", row), markStyle)
+			}
+			row++
+		}
+		return row, nil
+	}
+
+	if err := headers(3, "Namespace"); err != nil {
+		return err
+	}
+	row, err := writeRows(4, compareBaseline(baseline.Namespaces, current.Namespaces, threshold), "namespace")
+	if err != nil {
+		return err
+	}
+
+	// Only the workloads outside the threshold, the list of all would repeat the Resources sheet
+	var workloads []baselineDeviation
+	for _, d := range compareBaseline(baseline.Workloads, current.Workloads, threshold) {
+		if d.state != "" {
+			workloads = append(workloads, d)
+		}
+	}
+	row++
+	if err := headers(row, "Workload"); err != nil {
+		return err
+	}
+	if row, err = writeRows(row+1, workloads, "workload"); err != nil {
+		return err
+	}
+	if len(workloads) == 0 {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "No workload changed by more than the threshold")
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 48)
+	f.SetColWidth(sheetName, "B", "G", 20)
+	f.SetColWidth(sheetName, "H", "H", 12)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestCompareBaseline(t *testing.T) {
+	baseline := map[string]baselineRequests{
+		"shop":    {CPU: 1000, Memory: 1 << 30},
+		"search":  {CPU: 2000, Memory: 4 << 30},
+		"batch":   {CPU: 500, Memory: 1 << 30},
+		"retired": {CPU: 100, Memory: 128 << 20},
+		"idle":    {},
+	}
+	current := map[string]baselineRequests{
+		"shop":   {CPU: 1100, Memory: 1 << 30}, // +10%
+		"search": {CPU: 2000, Memory: 6 << 30}, // Memory +50%
+		"batch":  {CPU: 300, Memory: 1 << 30},  // CPU -40%
+		"idle":   {},
+		"new":    {CPU: 100},
+	}
+	want := map[string]string{
+		"batch":   BaselineDeviates,
+		"idle":    "",
+		"new":     BaselineNew,
+		"retired": BaselineRemoved,
+		"search":  BaselineDeviates,
+		"shop":    "",
+	}
+
+	got := compareBaseline(baseline, current, DefaultBaselineThreshold)
+	if len(got) != len(want) || got[0].name != "batch" {
+		t.Fatalf("compareBaseline() = %+v", got)
+	}
+	for _, d := range got {
+		if d.state != want[d.name] {
+			t.Errorf("%s state = %q, want %q", d.name, d.state, want[d.name])
+		}
+	}
+	if got[0].cpuChange() != -0.4 || got[2].cpuChange() != nil {
+		t.Errorf("cpuChange() = %v and %v, want -0.4 and none without baseline", got[0].cpuChange(), got[2].cpuChange())
+	}
+
+	// A lower threshold marks the 10% change too
+	for _, d := range compareBaseline(baseline, current, 5) {
+		if d.name == "shop" && d.state != BaselineDeviates {
+			t.Errorf("shop state = %q at 5%%, want %q", d.state, BaselineDeviates)
+		}
+	}
+}
+
+func TestBaselineRoundTrip(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(now)
+	b := newBaseline(snap.pods, now)
+	if ns := b.Namespaces["shop"]; ns.CPU != 1000 || ns.Memory != 2<<30 || len(b.Workloads) != 3 {
+		t.Fatalf("newBaseline() = %+v", b)
+	}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	if err := writeBaseline(path, b); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Collected.Equal(now) || loaded.Namespaces["search"] != b.Namespaces["search"] {
+		t.Errorf("loadBaseline() = %+v, want %+v", loaded, b)
+	}
+
+	// The search pod is gone a week later
+	current := newBaseline(snap.pods[:2], now.AddDate(0, 0, 7))
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createBaselineSheet(f, loaded, current, DefaultBaselineThreshold, "Baseline", themes[DefaultTheme]); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A4": "search", "H4": BaselineRemoved, "A5": "shop", "H5": "", "A7": "Workload", "A8": "search/Pod/idx-1"} {
+		if got, _ := f.GetCellValue("Baseline", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
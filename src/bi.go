@@ -0,0 +1,274 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Report formats of -format
+const (
+	FormatXLSX     = "xlsx"     // Human-oriented workbook
+	FormatBI       = "bi"       // Flat table for BI tools: CSV, or a workbook with one Excel table
+	FormatJSON     = "json"     // Full dataset for scripts: containers, totals and insights
+	FormatMarkdown = "markdown" // Totals, insights and findings as tables for chat, wikis and pull requests
+)
+
+// BI export table and sheet names of the workbook variant
+const (
+	BITableName = "PodResources"
+	BISheetName = "Data"
+)
+
+// biColumns are the columns of the BI export, one row per container
+var biColumns = []string{
+	"report_date", "cluster", "namespace", "team", "workload_kind", "workload_name",
+	"pod", "container", "node", "node_ip", "node_pool", "phase", "qos_class",
+	"pod_created", "restart_count",
+	"request_cpu_millicores", "limit_cpu_millicores", "request_memory_bytes", "limit_memory_bytes",
+	"request_ephemeral_storage_bytes", "limit_ephemeral_storage_bytes", "request_gpu", "limit_gpu",
+}
+
+// parseFormat validates the -format flag
+func parseFormat(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "", FormatXLSX:
+		return FormatXLSX, nil
+	case FormatBI:
+		return FormatBI, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatMarkdown:
+		return FormatMarkdown, nil
+	}
+	return "", fmt.Errorf("unknown format '%s' (want %s, %s, %s or %s)", value, FormatXLSX, FormatBI, FormatJSON, FormatMarkdown)
+}
+
+// csvDialect is the locale of CSV exports, e.g. ';' and decimal commas for
+// Excel in European locales
+type csvDialect struct {
+	delimiter    rune // Field separator
+	decimalComma bool // Write decimals with ',' instead of '.'
+}
+
+// defaultCSVDialect is RFC 4180 with '.' decimals
+var defaultCSVDialect = csvDialect{delimiter: ','}
+
+// parseCSVDialect validates the -csv-delimiter and -decimal-comma flags. The
+// delimiter is a single character or "tab"; empty keeps ','.
+func parseCSVDialect(delimiter string, decimalComma bool) (csvDialect, error) {
+	dialect := defaultCSVDialect
+	dialect.decimalComma = decimalComma
+	switch {
+	case delimiter == "":
+	case strings.EqualFold(delimiter, "tab") || delimiter == `\t`:
+		dialect.delimiter = '\t'
+	case utf8.RuneCountInString(delimiter) == 1:
+		dialect.delimiter, _ = utf8.DecodeRuneInString(delimiter)
+	default:
+		return dialect, fmt.Errorf("csv delimiter '%s' must be a single character or 'tab'", delimiter)
+	}
+	switch dialect.delimiter {
+	case '"', '\r', '\n', '.', utf8.RuneError:
+		return dialect, fmt.Errorf("invalid csv delimiter %q", dialect.delimiter)
+	}
+	if decimalComma && dialect.delimiter == ',' {
+		return dialect, fmt.Errorf("decimal comma requires a csv delimiter other than ','")
+	}
+	return dialect, nil
+}
+
+// biFilename turns a default report filename into the default BI export name
+func biFilename(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ".csv"
+}
+
+// biQuantity returns a quantity in base units (millicores when milli is set),
+// nil for unset quantities so BI tools load them as blank instead of zero
+func biQuantity(list corev1.ResourceList, name corev1.ResourceName, milli bool) interface{} {
+	q, ok := list[name]
+	if !ok {
+		return nil
+	}
+	if milli {
+		return quantityMilli(&q)
+	}
+	return quantityBytes(&q)
+}
+
+// biRows returns the BI table rows of the containers of active pods, the same
+// containers the Resources sheet lists. Timestamps are RFC 3339.
+func biRows(pods []corev1.Pod, nodes *corev1.NodeList, namespaces *corev1.NamespaceList, meta reportMetadata, teams *teamMapping) [][]interface{} {
+	pools := make(map[string]string)
+	if nodes != nil {
+		for i := range nodes.Items {
+			pools[nodes.Items[i].Name] = nodePool(&nodes.Items[i])
+		}
+	}
+	nsLabels := namespaceLabelIndex(namespaces)
+	reportDate := meta.generated.Format(time.RFC3339)
+
+	var rows [][]interface{}
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		workload := workloadOf(pod)
+		var team string
+		if info, ok := teams.resolve(pod.Labels, nsLabels[pod.Namespace], pod.Namespace); ok {
+			team = info.Name
+		}
+		var restarts int64
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts += int64(cs.RestartCount)
+		}
+		var created interface{}
+		if !pod.CreationTimestamp.IsZero() {
+			created = pod.CreationTimestamp.In(meta.generated.Location()).Format(time.RFC3339)
+		}
+
+		for _, c := range pod.Spec.Containers {
+			rows = append(rows, []interface{}{
+				reportDate, meta.cluster.name, pod.Namespace, team, workload.kind, workload.name,
+				pod.Name, c.Name, pod.Spec.NodeName, pod.Status.HostIP, pools[pod.Spec.NodeName],
+				string(pod.Status.Phase), string(pod.Status.QOSClass),
+				created, restarts,
+				biQuantity(c.Resources.Requests, corev1.ResourceCPU, true),
+				biQuantity(c.Resources.Limits, corev1.ResourceCPU, true),
+				biQuantity(c.Resources.Requests, corev1.ResourceMemory, false),
+				biQuantity(c.Resources.Limits, corev1.ResourceMemory, false),
+				biQuantity(c.Resources.Requests, corev1.ResourceEphemeralStorage, false),
+				biQuantity(c.Resources.Limits, corev1.ResourceEphemeralStorage, false),
+				biQuantity(c.Resources.Requests, "nvidia.com/gpu", false),
+				biQuantity(c.Resources.Limits, "nvidia.com/gpu", false),
+			})
+		}
+	}
+	return rows
+}
+
+// isCSV reports whether a BI export filename is written as CSV; stdout is
+// always CSV
+func isCSV(filename string) bool {
+	return filename == StdoutPath || strings.EqualFold(filepath.Ext(filename), ".csv")
+}
+
+// writeBIExport writes the BI table to filename: CSV in the given dialect for
+// *.csv, otherwise a workbook with the rows as an Excel table without formatting
+func writeBIExport(filename string, rows [][]interface{}, dialect csvDialect) error {
+	if isCSV(filename) {
+		return writeBICSV(filename, rows, dialect)
+	}
+
+	f, err := export.New(BISheetName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logrus.Warnf("Failed to close Excel file: %v", err)
+		}
+	}()
+	if err := f.SetSheetRow(BISheetName, "A1", &biColumns); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+	for i, row := range rows {
+		if err := export.SetRow(f, BISheetName, i+2, row, fmt.Sprintf("BI row %d", i+1)); err != nil {
+			return err
+		}
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(biColumns))
+	// An Excel table needs at least one data row
+	lastRow := len(rows) + 1
+	if lastRow < 2 {
+		lastRow = 2
+	}
+	if err := f.AddTable(BISheetName, &excelize.Table{
+		Range:     fmt.Sprintf("A1:%s%d", lastCol, lastRow),
+		Name:      BITableName,
+		StyleName: "TableStyleLight1",
+	}); err != nil {
+		return fmt.Errorf("failed to add BI table: %w", err)
+	}
+	if err := f.SaveAs(filename); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	return nil
+}
+
+// writeBICSV writes the BI table as CSV with a header row to filename or
+// stdout; the default dialect is RFC 4180
+func writeBICSV(filename string, rows [][]interface{}, dialect csvDialect) (err error) {
+	if filename == StdoutPath {
+		if err := encodeBICSV(stdout, rows, dialect); err != nil {
+			return fmt.Errorf("failed to write to stdout: %w", err)
+		}
+		return nil
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filename, err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to write %s: %w", filename, cerr)
+		}
+	}()
+	if err := encodeBICSV(file, rows, dialect); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return nil
+}
+
+// encodeBICSV writes the header row and the BI rows as CSV
+func encodeBICSV(out io.Writer, rows [][]interface{}, dialect csvDialect) error {
+	w := csv.NewWriter(out)
+	w.Comma = dialect.delimiter
+	if err := w.Write(biColumns); err != nil {
+		return err
+	}
+	record := make([]string, len(biColumns))
+	for _, row := range rows {
+		for i, v := range row {
+			record[i] = biValue(v, dialect.decimalComma)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// biValue formats a BI cell for CSV; nil becomes an empty field. Decimals
+// use a comma with decimalComma and are never written with exponents.
+func biValue(v interface{}, decimalComma bool) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if decimalComma {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	case string:
+		return v
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"", FormatXLSX, false},
+		{"xlsx", FormatXLSX, false},
+		{"BI", FormatBI, false},
+		{"json", FormatJSON, false},
+		{"markdown", FormatMarkdown, false},
+		{"csv", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseFormat(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseFormat(%q) = %q, %v", tt.value, got, err)
+		}
+	}
+}
+
+func TestBIRows(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(now)
+	teams, _ := parseTeamMapping([]byte("teams:\n  payments:\n    namespaces: [shop]\n"))
+	rows := biRows(snap.pods, snap.nodes, snap.namespaces, reportMetadata{cluster: clusterIdentity{name: "prod"}, generated: now}, teams)
+	if len(rows) != 3 {
+		t.Fatalf("rows = %d, want one per container", len(rows))
+	}
+	for _, row := range rows {
+		if len(row) != len(biColumns) {
+			t.Fatalf("row has %d values, want %d", len(row), len(biColumns))
+		}
+	}
+
+	column := func(row []interface{}, name string) interface{} {
+		for i, c := range biColumns {
+			if c == name {
+				return row[i]
+			}
+		}
+		t.Fatalf("no column %s", name)
+		return nil
+	}
+	web := rows[0]
+	want := map[string]interface{}{
+		"report_date":            "2024-05-10T12:00:00Z",
+		"cluster":                "prod",
+		"namespace":              "shop",
+		"team":                   "payments",
+		"node_pool":              "general",
+		"request_cpu_millicores": int64(500),
+		"request_memory_bytes":   int64(BytesPerGi),
+		"limit_cpu_millicores":   nil,
+		"restart_count":          int64(0),
+	}
+	for name, value := range want {
+		if got := column(web, name); !reflect.DeepEqual(got, value) {
+			t.Errorf("%s = %#v, want %#v", name, got, value)
+		}
+	}
+	if team := column(rows[2], "team"); team != "" {
+		t.Errorf("team of an unmapped namespace = %q, want empty", team)
+	}
+}
+
+func TestWriteBIExport(t *testing.T) {
+	dir := t.TempDir()
+	rows := [][]interface{}{make([]interface{}, len(biColumns))}
+	rows[0][0], rows[0][15] = "2024-05-10T12:00:00Z", int64(250)
+
+	csvPath := filepath.Join(dir, "bi.csv")
+	if err := writeBIExport(csvPath, rows, defaultCSVDialect); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(csvPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0][15] != "request_cpu_millicores" || records[1][15] != "250" || records[1][16] != "" {
+		t.Errorf("csv records = %v", records)
+	}
+
+	xlsxPath := filepath.Join(dir, "bi.xlsx")
+	if err := writeBIExport(xlsxPath, rows, defaultCSVDialect); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenFile(xlsxPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tables, err := f.GetTables(BISheetName)
+	if err != nil || len(tables) != 1 || tables[0].Name != BITableName || tables[0].Range != "A1:W2" {
+		t.Errorf("tables = %+v, %v", tables, err)
+	}
+	if v, _ := f.GetCellValue(BISheetName, "P2"); v != "250" {
+		t.Errorf("P2 = %q, want 250", v)
+	}
+}
+
+func TestBIFilename(t *testing.T) {
+	if got := biFilename("resource_prod_2024-05-10.xlsx"); got != "resource_prod_2024-05-10.csv" {
+		t.Errorf("biFilename() = %q", got)
+	}
+}
+
+func TestParseCSVDialect(t *testing.T) {
+	tests := []struct {
+		delimiter    string
+		decimalComma bool
+		want         csvDialect
+		wantErr      bool
+	}{
+		{"", false, csvDialect{delimiter: ','}, false},
+		{";", true, csvDialect{delimiter: ';', decimalComma: true}, false},
+		{"tab", false, csvDialect{delimiter: '\t'}, false},
+		{`\t`, true, csvDialect{delimiter: '\t', decimalComma: true}, false},
+		{"|", false, csvDialect{delimiter: '|'}, false},
+		{"", true, csvDialect{}, true},
+		{",", true, csvDialect{}, true},
+		{";;", false, csvDialect{}, true},
+		{`"`, false, csvDialect{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCSVDialect(tt.delimiter, tt.decimalComma)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("parseCSVDialect(%q, %v) = %+v, %v", tt.delimiter, tt.decimalComma, got, err)
+		}
+	}
+}
+
+func TestRenderSettingsCSVDialect(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings renderSettings
+		filename string
+		want     csvDialect
+		wantErr  bool
+	}{
+		{"defaults", renderSettings{}, "out.xlsx", defaultCSVDialect, false},
+		{"bi csv", renderSettings{Format: FormatBI, CSVDelimiter: ";", DecimalComma: true}, "out.csv", csvDialect{';', true}, false},
+		{"bi workbook", renderSettings{Format: FormatBI, CSVDelimiter: ";"}, "out.xlsx", csvDialect{}, true},
+		{"xlsx format", renderSettings{Format: FormatXLSX, DecimalComma: true}, "out.csv", csvDialect{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.settings.csvDialect(tt.filename)
+			if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+				t.Errorf("csvDialect(%q) = %+v, %v", tt.filename, got, err)
+			}
+		})
+	}
+}
+
+func TestWriteBICSVDialect(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bi.csv")
+	rows := [][]interface{}{make([]interface{}, len(biColumns))}
+	rows[0][1], rows[0][15], rows[0][16] = "prod;eu", int64(250), 0.5
+	if err := writeBIExport(path, rows, csvDialect{delimiter: ';', decimalComma: true}); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	r := csv.NewReader(file)
+	r.Comma = ';'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1][1] != "prod;eu" || records[1][15] != "250" || records[1][16] != "0,5" {
+		t.Errorf("csv records = %v", records)
+	}
+}
+
+func TestBIValue(t *testing.T) {
+	tests := []struct {
+		value        interface{}
+		decimalComma bool
+		want         string
+	}{
+		{nil, false, ""},
+		{int64(1024), true, "1024"},
+		{1.25, false, "1.25"},
+		{1.25, true, "1,25"},
+		{1e21, false, "1000000000000000000000"},
+		{"a.b", true, "a.b"},
+	}
+	for _, tt := range tests {
+		if got := biValue(tt.value, tt.decimalComma); got != tt.want {
+			t.Errorf("biValue(%v, %v) = %q, want %q", tt.value, tt.decimalComma, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,555 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// BundleVersion is the format version of offline bundles; render rejects others
+const BundleVersion = 1
+
+// offlineBundle is a cluster snapshot with its render settings, written in the
+// cluster with -bundle and rendered into workbooks by the render subcommand
+type offlineBundle struct {
+	Version         int                    `json:"version"`
+	Collected       time.Time              `json:"collected"`
+	Settings        renderSettings         `json:"settings"`
+	Config          config                 `json:"config"` // Config file with flag overrides applied
+	Pods            []corev1.Pod           `json:"pods"`
+	Namespaces      *corev1.NamespaceList  `json:"namespaces,omitempty"`
+	Nodes           *corev1.NodeList       `json:"nodes,omitempty"`
+	Evictions       []bundleEviction       `json:"evictions,omitempty"`
+	Metrics         []bundleMetric         `json:"metrics,omitempty"`
+	UsageQuantiles  *bundleQuantiles       `json:"usageQuantiles,omitempty"`
+	Events          []bundleEvents         `json:"events,omitempty"`
+	ResourceChanges []bundleResourceChange `json:"resourceChanges,omitempty"`
+	HPAScaling      []bundleHPAScaling     `json:"hpaScaling,omitempty"`
+	StatefulSets    []bundleStatefulSet    `json:"statefulSets,omitempty"`
+	FinishedJobs    []bundleWorkload       `json:"finishedJobs,omitempty"`
+	JobRuns         []bundleJobRun         `json:"jobRuns,omitempty"`
+	CronJobs        []bundleCronJob        `json:"cronJobs,omitempty"`
+	GitOps          *bundleGitOps          `json:"gitops,omitempty"`
+}
+
+// bundleWorkload is a workloadKey in a bundle
+type bundleWorkload struct {
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+}
+
+// bundleEviction is the kubeletEviction of a node in a bundle
+type bundleEviction struct {
+	Node       string `json:"node"`
+	HardMemory int64  `json:"hardMemory"`
+	SoftMemory int64  `json:"softMemory"`
+}
+
+// bundleEvents are the warningEvents of a namespace and reason in a bundle
+type bundleEvents struct {
+	Namespace string    `json:"namespace,omitempty"`
+	Reason    string    `json:"reason"`
+	Count     int32     `json:"count"`
+	Objects   int       `json:"objects"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Latest    string    `json:"latest"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// bundleMetric is the metrics-server usage of a container in a bundle
+type bundleMetric struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	CPU       int64  `json:"cpu"`    // Millicores
+	Memory    int64  `json:"memory"` // Bytes
+}
+
+// bundleQuantiles are the usageQuantiles in a bundle
+type bundleQuantiles struct {
+	Quantiles  []float64             `json:"quantiles"`
+	Containers []bundleQuantileUsage `json:"containers"`
+}
+
+// bundleQuantileUsage is the usage of a container, one value per quantile
+type bundleQuantileUsage struct {
+	Namespace string  `json:"namespace"`
+	Pod       string  `json:"pod"`
+	Container string  `json:"container"`
+	CPU       []int64 `json:"cpu"`    // Millicores
+	Memory    []int64 `json:"memory"` // Bytes
+}
+
+// bundleResourceChange is a resourceChange in a bundle
+type bundleResourceChange struct {
+	Workload  bundleWorkload              `json:"workload"`
+	Container string                      `json:"container"`
+	Revision  int64                       `json:"revision"`
+	ChangedAt time.Time                   `json:"changedAt"`
+	Before    corev1.ResourceRequirements `json:"before"`
+	After     corev1.ResourceRequirements `json:"after"`
+}
+
+// bundleHPAScaling is an hpaScaling in a bundle
+type bundleHPAScaling struct {
+	Workload bundleWorkload `json:"workload"`
+	HPA      string         `json:"hpa"`
+	Min      int32          `json:"min"`
+	Max      int32          `json:"max"`
+	Current  int32          `json:"current"`
+	Desired  int32          `json:"desired"`
+	State    string         `json:"state"`
+}
+
+// bundleStatefulSet is a statefulSetFootprint in a bundle
+type bundleStatefulSet struct {
+	Workload      bundleWorkload        `json:"workload"`
+	Replicas      int32                 `json:"replicas"`
+	RequestCPU    int64                 `json:"requestCPU"`
+	RequestMemory int64                 `json:"requestMemory"`
+	Claims        []bundleClaimTemplate `json:"claims,omitempty"`
+}
+
+// bundleClaimTemplate is a claimTemplate in a bundle
+type bundleClaimTemplate struct {
+	Name         string `json:"name"`
+	StorageClass string `json:"storageClass"`
+	Bytes        int64  `json:"bytes"`
+}
+
+// bundleJobRun is a jobRun in a bundle
+type bundleJobRun struct {
+	Job           bundleWorkload `json:"job"`
+	CronJob       string         `json:"cronJob,omitempty"`
+	State         string         `json:"state"`
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	Succeeded     int32          `json:"succeeded"`
+	Failed        int32          `json:"failed"`
+	Parallelism   int32          `json:"parallelism"`
+	RequestCPU    int64          `json:"requestCPU"`
+	RequestMemory int64          `json:"requestMemory"`
+}
+
+// bundleCronJob is a cronJobSpec in a bundle
+type bundleCronJob struct {
+	CronJob           bundleWorkload `json:"cronJob"`
+	Schedule          string         `json:"schedule"`
+	TimeZone          string         `json:"timeZone,omitempty"`
+	Suspended         bool           `json:"suspended,omitempty"`
+	ConcurrencyPolicy string         `json:"concurrencyPolicy"`
+	RequestCPU        int64          `json:"requestCPU"`
+	RequestMemory     int64          `json:"requestMemory"`
+	DurationSeconds   float64        `json:"durationSeconds"`
+	DurationSource    string         `json:"durationSource"`
+}
+
+// bundleGitOpsOwner is a gitopsOwner of a workload in a bundle
+type bundleGitOpsOwner struct {
+	Workload bundleWorkload `json:"workload"`
+	Tool     string         `json:"tool"`
+	App      string         `json:"app"`
+	Repo     string         `json:"repo,omitempty"`
+}
+
+// bundleGitOps is a gitopsIndex in a bundle
+type bundleGitOps struct {
+	Workloads []bundleGitOpsOwner `json:"workloads,omitempty"`
+	Repos     map[string]string   `json:"repos,omitempty"`
+}
+
+func toBundleWorkload(k workloadKey) bundleWorkload {
+	return bundleWorkload{Namespace: k.namespace, Kind: k.kind, Name: k.name}
+}
+
+func (w bundleWorkload) key() workloadKey {
+	return workloadKey{namespace: w.Namespace, kind: w.Kind, name: w.Name}
+}
+
+// newOfflineBundle stores snap with the settings and config it is rendered with
+func newOfflineBundle(snap *clusterSnapshot, settings renderSettings, cfg *config) *offlineBundle {
+	b := &offlineBundle{
+		Version:    BundleVersion,
+		Collected:  snap.collected,
+		Settings:   settings,
+		Config:     *cfg,
+		Pods:       append(append([]corev1.Pod(nil), snap.pods...), snap.pending...), // Rendering separates them again
+		Namespaces: snap.namespaces,
+		Nodes:      snap.nodes,
+	}
+	for _, c := range snap.resourceChanges {
+		b.ResourceChanges = append(b.ResourceChanges, bundleResourceChange{
+			Workload: toBundleWorkload(c.workload), Container: c.container, Revision: c.revision,
+			ChangedAt: c.changedAt, Before: c.before, After: c.after,
+		})
+	}
+	for node, e := range snap.evictions {
+		b.Evictions = append(b.Evictions, bundleEviction{Node: node, HardMemory: e.hardMem, SoftMemory: e.softMem})
+	}
+	sort.Slice(b.Evictions, func(i, j int) bool { return b.Evictions[i].Node < b.Evictions[j].Node })
+	for key, usage := range snap.metrics {
+		parts := strings.SplitN(key, "/", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		b.Metrics = append(b.Metrics, bundleMetric{Namespace: parts[0], Pod: parts[1], Container: parts[2], CPU: usage.cpu, Memory: usage.mem})
+	}
+	sort.Slice(b.Metrics, func(i, j int) bool {
+		mi, mj := b.Metrics[i], b.Metrics[j]
+		return mi.Namespace+"/"+mi.Pod+"/"+mi.Container < mj.Namespace+"/"+mj.Pod+"/"+mj.Container
+	})
+	if snap.quantiles != nil {
+		b.UsageQuantiles = &bundleQuantiles{Quantiles: snap.quantiles.quantiles}
+		for key, samples := range snap.quantiles.containers {
+			parts := strings.SplitN(key, "/", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			usage := bundleQuantileUsage{Namespace: parts[0], Pod: parts[1], Container: parts[2]}
+			for _, sample := range samples {
+				usage.CPU = append(usage.CPU, sample.cpu)
+				usage.Memory = append(usage.Memory, sample.mem)
+			}
+			b.UsageQuantiles.Containers = append(b.UsageQuantiles.Containers, usage)
+		}
+		sort.Slice(b.UsageQuantiles.Containers, func(i, j int) bool {
+			ci, cj := b.UsageQuantiles.Containers[i], b.UsageQuantiles.Containers[j]
+			return ci.Namespace+"/"+ci.Pod+"/"+ci.Container < cj.Namespace+"/"+cj.Pod+"/"+cj.Container
+		})
+	}
+	for _, e := range snap.events {
+		b.Events = append(b.Events, bundleEvents{
+			Namespace: e.namespace, Reason: e.reason, Count: e.count, Objects: e.objects,
+			FirstSeen: e.firstSeen, LastSeen: e.lastSeen, Latest: e.latest, Message: e.message,
+		})
+	}
+	for _, h := range snap.hpaScaling {
+		b.HPAScaling = append(b.HPAScaling, bundleHPAScaling{
+			Workload: toBundleWorkload(h.workload), HPA: h.hpa,
+			Min: h.min, Max: h.max, Current: h.current, Desired: h.desired, State: h.state,
+		})
+	}
+	for _, s := range snap.statefulSets {
+		sts := bundleStatefulSet{Workload: toBundleWorkload(s.workload), Replicas: s.replicas, RequestCPU: s.reqCPU, RequestMemory: s.reqMem}
+		for _, c := range s.claims {
+			sts.Claims = append(sts.Claims, bundleClaimTemplate{Name: c.name, StorageClass: c.storageClass, Bytes: c.bytes})
+		}
+		b.StatefulSets = append(b.StatefulSets, sts)
+	}
+	for key, finished := range snap.finishedJobs {
+		if finished {
+			b.FinishedJobs = append(b.FinishedJobs, toBundleWorkload(key))
+		}
+	}
+	sort.Slice(b.FinishedJobs, func(i, j int) bool {
+		return b.FinishedJobs[i].key().String() < b.FinishedJobs[j].key().String()
+	})
+	for _, r := range snap.jobRuns {
+		b.JobRuns = append(b.JobRuns, bundleJobRun{
+			Job: toBundleWorkload(r.job), CronJob: r.cronJob, State: r.state, Start: r.start, End: r.end,
+			Succeeded: r.succeeded, Failed: r.failed, Parallelism: r.parallelism, RequestCPU: r.reqCPU, RequestMemory: r.reqMem,
+		})
+	}
+	for _, c := range snap.cronJobs {
+		b.CronJobs = append(b.CronJobs, bundleCronJob{
+			CronJob: toBundleWorkload(c.cronJob), Schedule: c.schedule, TimeZone: c.timeZone, Suspended: c.suspended,
+			ConcurrencyPolicy: c.concurrency, RequestCPU: c.reqCPU, RequestMemory: c.reqMem,
+			DurationSeconds: c.duration.Seconds(), DurationSource: c.durationSource,
+		})
+	}
+	if snap.gitops != nil {
+		b.GitOps = &bundleGitOps{Repos: snap.gitops.repos}
+		for key, owner := range snap.gitops.workloads {
+			b.GitOps.Workloads = append(b.GitOps.Workloads, bundleGitOpsOwner{
+				Workload: toBundleWorkload(key), Tool: owner.tool, App: owner.app, Repo: owner.repo,
+			})
+		}
+		sort.Slice(b.GitOps.Workloads, func(i, j int) bool {
+			return b.GitOps.Workloads[i].Workload.key().String() < b.GitOps.Workloads[j].Workload.key().String()
+		})
+	}
+	return b
+}
+
+// snapshot restores the cluster snapshot of the bundle with timestamps in location
+func (b *offlineBundle) snapshot(location *time.Location) *clusterSnapshot {
+	snap := &clusterSnapshot{
+		collected:  b.Collected.In(location),
+		pods:       b.Pods,
+		namespaces: b.Namespaces,
+		nodes:      b.Nodes,
+	}
+	for _, c := range b.ResourceChanges {
+		snap.resourceChanges = append(snap.resourceChanges, resourceChange{
+			workload: c.Workload.key(), container: c.Container, revision: c.Revision,
+			changedAt: c.ChangedAt.In(location), before: c.Before, after: c.After,
+		})
+	}
+	if len(b.Evictions) > 0 {
+		snap.evictions = make(kubeletEvictions, len(b.Evictions))
+		for _, e := range b.Evictions {
+			snap.evictions[e.Node] = kubeletEviction{hardMem: e.HardMemory, softMem: e.SoftMemory}
+		}
+	}
+	if len(b.Metrics) > 0 {
+		snap.metrics = make(containerMetrics, len(b.Metrics))
+		for _, m := range b.Metrics {
+			snap.metrics[m.Namespace+"/"+m.Pod+"/"+m.Container] = metricsSample{cpu: m.CPU, mem: m.Memory}
+		}
+	}
+	if q := b.UsageQuantiles; q != nil {
+		snap.quantiles = &usageQuantiles{quantiles: q.Quantiles, containers: make(map[string][]metricsSample, len(q.Containers))}
+		for _, c := range q.Containers {
+			if len(c.CPU) != len(q.Quantiles) || len(c.Memory) != len(q.Quantiles) {
+				continue // Edited bundle, the columns would shift
+			}
+			samples := make([]metricsSample, len(q.Quantiles))
+			for i := range samples {
+				samples[i] = metricsSample{cpu: c.CPU[i], mem: c.Memory[i]}
+			}
+			snap.quantiles.containers[c.Namespace+"/"+c.Pod+"/"+c.Container] = samples
+		}
+	}
+	if len(b.Events) > 0 {
+		snap.events = make([]warningEvents, 0, len(b.Events))
+		for _, e := range b.Events {
+			snap.events = append(snap.events, warningEvents{
+				namespace: e.Namespace, reason: e.Reason, count: e.Count, objects: e.Objects,
+				firstSeen: e.FirstSeen.In(location), lastSeen: e.LastSeen.In(location), latest: e.Latest, message: e.Message,
+			})
+		}
+	}
+	for _, h := range b.HPAScaling {
+		snap.hpaScaling = append(snap.hpaScaling, hpaScaling{
+			workload: h.Workload.key(), hpa: h.HPA,
+			min: h.Min, max: h.Max, current: h.Current, desired: h.Desired, state: h.State,
+		})
+	}
+	for _, s := range b.StatefulSets {
+		fp := statefulSetFootprint{workload: s.Workload.key(), replicas: s.Replicas, reqCPU: s.RequestCPU, reqMem: s.RequestMemory}
+		for _, c := range s.Claims {
+			fp.claims = append(fp.claims, claimTemplate{name: c.Name, storageClass: c.StorageClass, bytes: c.Bytes})
+		}
+		snap.statefulSets = append(snap.statefulSets, fp)
+	}
+	if len(b.FinishedJobs) > 0 {
+		snap.finishedJobs = make(map[workloadKey]bool, len(b.FinishedJobs))
+		for _, w := range b.FinishedJobs {
+			snap.finishedJobs[w.key()] = true
+		}
+	}
+	for _, r := range b.JobRuns {
+		run := jobRun{
+			job: r.Job.key(), cronJob: r.CronJob, state: r.State, start: r.Start.In(location),
+			succeeded: r.Succeeded, failed: r.Failed, parallelism: r.Parallelism, reqCPU: r.RequestCPU, reqMem: r.RequestMemory,
+		}
+		if !r.End.IsZero() {
+			run.end = r.End.In(location)
+		}
+		snap.jobRuns = append(snap.jobRuns, run)
+	}
+	for _, c := range b.CronJobs {
+		snap.cronJobs = append(snap.cronJobs, cronJobSpec{
+			cronJob: c.CronJob.key(), schedule: c.Schedule, timeZone: c.TimeZone, suspended: c.Suspended,
+			concurrency: c.ConcurrencyPolicy, reqCPU: c.RequestCPU, reqMem: c.RequestMemory,
+			duration: time.Duration(c.DurationSeconds * float64(time.Second)), durationSource: c.DurationSource,
+		})
+	}
+	if b.GitOps != nil {
+		snap.gitops = &gitopsIndex{workloads: make(map[workloadKey]gitopsOwner, len(b.GitOps.Workloads)), repos: b.GitOps.Repos}
+		if snap.gitops.repos == nil {
+			snap.gitops.repos = make(map[string]string)
+		}
+		for _, o := range b.GitOps.Workloads {
+			snap.gitops.workloads[o.Workload.key()] = gitopsOwner{tool: o.Tool, app: o.App, repo: o.Repo}
+		}
+	}
+	return snap
+}
+
+// writeBundle writes b as JSON, gzip compressed when path ends in .gz
+func writeBundle(path string, b *offlineBundle) (err error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle %s: %w", path, err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to write bundle %s: %w", path, cerr)
+		}
+	}()
+
+	var w io.Writer = file
+	if strings.HasSuffix(path, ".gz") {
+		gz := gzip.NewWriter(file)
+		defer func() {
+			if cerr := gz.Close(); cerr != nil && err == nil {
+				err = fmt.Errorf("failed to compress bundle %s: %w", path, cerr)
+			}
+		}()
+		w = gz
+	}
+	if err := json.NewEncoder(w).Encode(b); err != nil {
+		return fmt.Errorf("failed to write bundle %s: %w", path, err)
+	}
+	return nil
+}
+
+// readBundle reads a bundle written by writeBundle
+func readBundle(path string) (*offlineBundle, error) {
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid bundle path: %w", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle %s: %w", path, err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress bundle %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	b := &offlineBundle{}
+	if err := json.NewDecoder(r).Decode(b); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle %s: %w", path, err)
+	}
+	if b.Version != BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d in %s, want %d", b.Version, path, BundleVersion)
+	}
+	if b.Settings.Teams != nil {
+		// Rebuild the namespace index of the team mapping
+		data, err := json.Marshal(b.Settings.Teams)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore team mapping: %w", err)
+		}
+		if b.Settings.Teams, err = parseTeamMapping(data); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// renderArgs are the flags of the render subcommand
+type renderArgs struct {
+	bundle, output, findings, findingsFormat, appendTo *string
+	verbose, quiet                                     *bool
+}
+
+// renderFlags defines the flags of the render subcommand
+func renderFlags() (*flag.FlagSet, *renderArgs) {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	return fs, &renderArgs{
+		bundle:         fs.String("bundle", "", "Path to the offline bundle written with -bundle"),
+		output:         fs.String("output", "", "Output filename, - for stdout with -format bi (default: resource_YYYY-MM-DD.xlsx of the collection date)"),
+		findings:       fs.String("findings", "", "Also write validation findings to this file, - for stdout (JSON, or SARIF for *.sarif)"),
+		findingsFormat: fs.String("findings-format", "", "Findings file format: json or sarif (default: from file extension)"),
+		appendTo:       fs.String("append", "", "Also add the bundle's namespace summary as a dated sheet to this multi-run workbook"),
+		verbose:        fs.Bool("verbose", false, "Enable verbose logging"),
+		quiet:          fs.Bool("quiet", false, "Only log errors (logs always go to stderr)"),
+	}
+}
+
+// runRender is the render subcommand: it writes the workbooks of an offline
+// bundle without cluster access
+func runRender(args []string) error {
+	fs, a := renderFlags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := configureLogging(*a.verbose, *a.quiet); err != nil {
+		return err
+	}
+	if *a.bundle == "" {
+		return fmt.Errorf("-bundle is required")
+	}
+
+	b, err := readBundle(*a.bundle)
+	if err != nil {
+		return err
+	}
+	location, err := loadTimezone(b.Config.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	snap := b.snapshot(location)
+
+	opts, err := b.Settings.reportOptions(&b.Config, snap.collected)
+	if err != nil {
+		return fmt.Errorf("invalid bundle settings: %w", err)
+	}
+	separatePending(snap, opts.pendingPods)
+	if *a.findings != "" {
+		if err := validatePath(*a.findings); err != nil {
+			return fmt.Errorf("invalid findings path: %w", err)
+		}
+		opts.findingsPath = *a.findings
+		if opts.findingsFormat, err = findingsFormat(*a.findings, *a.findingsFormat); err != nil {
+			return fmt.Errorf("invalid findings format: %w", err)
+		}
+	}
+	split, err := parseSplitBy(b.Settings.SplitBy)
+	if err != nil {
+		return fmt.Errorf("invalid split-by: %w", err)
+	}
+	if split != nil && split.kind == "team" && opts.teams == nil {
+		return fmt.Errorf("invalid split-by: 'team' requires a team mapping in the bundle")
+	}
+
+	reportFormat, err := parseFormat(b.Settings.Format)
+	if err != nil {
+		return fmt.Errorf("invalid bundle settings: %w", err)
+	}
+	if reportFormat != FormatXLSX && *a.findings != "" {
+		return fmt.Errorf("-findings requires the xlsx format")
+	}
+	filename := getOutputFilename(*a.output, filenameClusterName(b.Settings.Cluster), snap.collected)
+	if reportFormat == FormatBI && *a.output == "" {
+		filename = biFilename(filename)
+	}
+	if reportFormat == FormatJSON && *a.output == "" {
+		filename = jsonFilename(filename)
+	}
+	if reportFormat == FormatMarkdown && *a.output == "" {
+		filename = markdownFilename(filename)
+	}
+	if err := validatePath(filename); err != nil {
+		return fmt.Errorf("invalid output filename: %w", err)
+	}
+
+	csvFormat, err := b.Settings.csvDialect(filename)
+	if err != nil {
+		return fmt.Errorf("invalid bundle settings: %w", err)
+	}
+	if err := validateStdout(filename, reportFormat, opts.findingsPath, split != nil, false); err != nil {
+		return err
+	}
+
+	logrus.Infof("Rendering bundle of %s collected %s with %d pods", getNamespaceDisplay(b.Settings.Namespace), snap.collected.Format(time.RFC3339), len(snap.pods))
+	job := reportJob{namespace: b.Settings.Namespace, split: split, filename: filename, format: reportFormat, csv: csvFormat, opts: opts}
+	if *a.appendTo != "" {
+		if err := validatePath(*a.appendTo); err != nil {
+			return fmt.Errorf("invalid append path: %w", err)
+		}
+		job.appendPath = *a.appendTo
+	}
+	return job.render(context.Background(), snap)
+}
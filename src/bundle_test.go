@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestOfflineBundleRoundTrip(t *testing.T) {
+	collected := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(collected)
+	deploy := workloadKey{namespace: "shop", kind: "Deployment", name: "web"}
+	job := workloadKey{namespace: "search", kind: "Job", name: "reindex"}
+	snap.resourceChanges = []resourceChange{{
+		workload: deploy, container: "app", revision: 4, changedAt: collected.Add(-time.Hour),
+		after: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}},
+	}}
+	snap.hpaScaling = []hpaScaling{{workload: deploy, hpa: "web", min: 2, max: 4, current: 4, desired: 6, state: ScalingAtMax}}
+	snap.statefulSets = []statefulSetFootprint{{
+		workload: workloadKey{namespace: "shop", kind: "StatefulSet", name: "db"}, replicas: 3, reqCPU: 2000, reqMem: 8 << 30,
+		claims: []claimTemplate{{name: "data", storageClass: "fast", bytes: 100 << 30}},
+	}}
+	snap.evictions = kubeletEvictions{"node-1": {hardMem: 100 << 20, softMem: 1 << 30}}
+	snap.metrics = containerMetrics{"shop/web-1/app": {cpu: 120, mem: 300 << 20}}
+	snap.quantiles = &usageQuantiles{quantiles: []float64{0.95, 0.99}, containers: map[string][]metricsSample{
+		"shop/web-1/app": {{cpu: 180, mem: 400 << 20}, {cpu: 260, mem: 450 << 20}},
+	}}
+	snap.events = []warningEvents{{
+		namespace: "shop", reason: "BackOff", count: 12, objects: 2, firstSeen: collected.Add(-3 * time.Hour), lastSeen: collected.Add(-time.Minute),
+		latest: "Pod/web-1", message: "Back-off restarting failed container app",
+	}}
+	snap.finishedJobs = map[workloadKey]bool{job: true}
+	snap.jobRuns = []jobRun{
+		{job: job, state: JobRunComplete, start: collected.Add(-2 * time.Hour), end: collected.Add(-time.Hour), succeeded: 1, parallelism: 1, reqCPU: 500},
+		{job: workloadKey{namespace: "search", kind: "Job", name: "nightly-1"}, cronJob: "nightly", state: JobRunRunning, start: collected.Add(-time.Minute), parallelism: 2},
+	}
+	snap.cronJobs = []cronJobSpec{{
+		cronJob: workloadKey{namespace: "search", kind: "CronJob", name: "nightly"}, schedule: "0 2 * * *", timeZone: "Europe/Berlin",
+		concurrency: "Forbid", reqCPU: 1000, reqMem: 1 << 30, duration: 90 * time.Second, durationSource: DurationHistory,
+	}}
+	snap.gitops = &gitopsIndex{
+		workloads: map[workloadKey]gitopsOwner{deploy: {tool: GitOpsToolArgoCD, app: "shop", repo: "https://git.example.com/shop"}},
+		repos:     map[string]string{GitOpsToolArgoCD + "|shop": "https://git.example.com/shop"},
+	}
+	teams, err := parseTeamMapping([]byte("teams:\n  payments:\n    namespaces: [shop]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := renderSettings{Cluster: "prod", SortBy: "namespace", IdleDays: 14, Teams: teams}
+	cfg, _ := loadConfig("")
+	cfg.Theme, cfg.Sheets = "dark", []string{SheetNamespaces}
+
+	for _, name := range []string{"bundle.json", "bundle.json.gz"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := writeBundle(path, newOfflineBundle(snap, settings, cfg)); err != nil {
+				t.Fatal(err)
+			}
+			b, err := readBundle(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			restored := b.snapshot(time.UTC)
+
+			if !restored.collected.Equal(collected) || len(restored.pods) != 3 || len(restored.nodes.Items) != 2 || len(restored.namespaces.Items) != 2 {
+				t.Errorf("restored snapshot = %+v", restored)
+			}
+			if got := restored.resourceChanges[0]; got.workload != deploy || got.revision != 4 || got.after.Requests.Cpu().MilliValue() != 500 {
+				t.Errorf("resourceChanges = %+v", restored.resourceChanges)
+			}
+			if !reflect.DeepEqual(restored.hpaScaling, snap.hpaScaling) {
+				t.Errorf("hpaScaling = %+v, want %+v", restored.hpaScaling, snap.hpaScaling)
+			}
+			if !reflect.DeepEqual(restored.events, snap.events) {
+				t.Errorf("events = %+v, want %+v", restored.events, snap.events)
+			}
+			if !reflect.DeepEqual(restored.evictions, snap.evictions) {
+				t.Errorf("evictions = %+v, want %+v", restored.evictions, snap.evictions)
+			}
+			if !reflect.DeepEqual(restored.quantiles, snap.quantiles) {
+				t.Errorf("quantiles = %+v, want %+v", restored.quantiles, snap.quantiles)
+			}
+			if !reflect.DeepEqual(restored.metrics, snap.metrics) {
+				t.Errorf("metrics = %+v, want %+v", restored.metrics, snap.metrics)
+			}
+			if !reflect.DeepEqual(restored.statefulSets, snap.statefulSets) {
+				t.Errorf("statefulSets = %+v, want %+v", restored.statefulSets, snap.statefulSets)
+			}
+			if !reflect.DeepEqual(restored.finishedJobs, snap.finishedJobs) {
+				t.Errorf("finishedJobs = %v, want %v", restored.finishedJobs, snap.finishedJobs)
+			}
+			if !reflect.DeepEqual(restored.jobRuns, snap.jobRuns) {
+				t.Errorf("jobRuns = %+v, want %+v", restored.jobRuns, snap.jobRuns)
+			}
+			if !reflect.DeepEqual(restored.cronJobs, snap.cronJobs) {
+				t.Errorf("cronJobs = %+v, want %+v", restored.cronJobs, snap.cronJobs)
+			}
+			if !reflect.DeepEqual(restored.gitops, snap.gitops) {
+				t.Errorf("gitops = %+v, want %+v", restored.gitops, snap.gitops)
+			}
+			if b.Settings.Teams.namespaceTeams["shop"] != "payments" {
+				t.Errorf("team mapping index not rebuilt: %+v", b.Settings.Teams)
+			}
+
+			opts, err := b.Settings.reportOptions(&b.Config, restored.collected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if opts.metadata.cluster.name != "prod" || !opts.sheets.enabled(SheetNamespaces) || opts.sheets.enabled(SheetNodes) || opts.idleAfter != 14*24*time.Hour || len(opts.sortKeys) != 1 {
+				t.Errorf("reportOptions = %+v", opts)
+			}
+		})
+	}
+}
+
+func TestReadBundleVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "pods": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBundle(path); err == nil || !strings.Contains(err.Error(), "unsupported bundle version 99") {
+		t.Errorf("readBundle() error = %v, want unsupported version", err)
+	}
+}
+
+func TestRenderSettingsInvalid(t *testing.T) {
+	cfg, _ := loadConfig("")
+	if _, err := (renderSettings{SortBy: "bogus"}).reportOptions(cfg, time.Now()); err == nil || !strings.Contains(err.Error(), "invalid sort-by") {
+		t.Errorf("reportOptions() error = %v, want invalid sort-by", err)
+	}
+	cfg.Sheets = []string{"bogus"}
+	if _, err := (renderSettings{}).reportOptions(cfg, time.Now()); err == nil || !strings.Contains(err.Error(), "invalid sheet selection") {
+		t.Errorf("reportOptions() error = %v, want invalid sheet selection", err)
+	}
+}
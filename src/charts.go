@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+)
+
+// Heatmap color scale anchors (allocation as fraction of allocatable)
+const (
+	HeatmapMidValue = "0.6" // Middle color (yellow) at 60% allocation
+	HeatmapMaxValue = "1"   // Top color (red) at 100% allocation and above
+)
+
+// createNodeHeatmapSheet writes a node x resource matrix of allocation
+// percentages with the theme's three-color scale (green-yellow-red by default)
+func createNodeHeatmapSheet(f *excelize.File, nodeTotals map[string]nodeTotal, sheetName string, t theme) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create heatmap sheet: %w", err)
+	}
+
+	headers := []string{"Node", "CPU Requests %", "CPU Limits %", "Memory Requests %", "Memory Limits %"}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	var sortedNodes []string
+	for node := range nodeTotals {
+		sortedNodes = append(sortedNodes, node)
+	}
+	sort.Strings(sortedNodes)
+
+	row := 2
+	for _, node := range sortedNodes {
+		totals := nodeTotals[node]
+		label := node
+		if totals.nodeName != "" {
+			label = totals.nodeName
+		}
+
+		// Nodes without known allocatable get empty cells so the scale ignores them
+		data := []interface{}{label, nil, nil, nil, nil}
+		if totals.allocCPU > 0 {
+			data[1] = float64(totals.reqCPU) / float64(totals.allocCPU)
+			data[2] = float64(totals.limCPU) / float64(totals.allocCPU)
+		}
+		if totals.allocMem > 0 {
+			data[3] = float64(totals.reqMem) / float64(totals.allocMem)
+			data[4] = float64(totals.limMem) / float64(totals.allocMem)
+		}
+
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("node '%s'", label)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	if row == 2 {
+		return nil
+	}
+
+	lastCell, _ := excelize.CoordinatesToCellName(5, row-1)
+	rangeRef := "B2:" + lastCell
+
+	percentStyle, err := f.NewStyle(&excelize.Style{
+		NumFmt:    10, // 0.00%
+		Alignment: &excelize.Alignment{Horizontal: "center"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create heatmap style: %w", err)
+	}
+	if err := f.SetCellStyle(sheetName, "B2", lastCell, percentStyle); err != nil {
+		return fmt.Errorf("failed to set heatmap style: %w", err)
+	}
+
+	if err := f.SetConditionalFormat(sheetName, rangeRef, []excelize.ConditionalFormatOptions{{
+		Type:     "3_color_scale",
+		Criteria: "=",
+		MinType:  "num",
+		MidType:  "num",
+		MaxType:  "num",
+		MinValue: "0",
+		MidValue: HeatmapMidValue,
+		MaxValue: HeatmapMaxValue,
+		MinColor: t.heatmap[0],
+		MidColor: t.heatmap[1],
+		MaxColor: t.heatmap[2],
+	}}); err != nil {
+		return fmt.Errorf("failed to set heatmap color scale: %w", err)
+	}
+
+	if err := f.SetColWidth(sheetName, "A", "A", 25); err != nil {
+		return fmt.Errorf("failed to set column width: %w", err)
+	}
+	if err := f.SetColWidth(sheetName, "B", "E", 18); err != nil {
+		return fmt.Errorf("failed to set column width: %w", err)
+	}
+
+	return nil
+}
+
+// createRequestLimitSheet writes per-workload request/limit values (per pod)
+// and scatter charts that separate tight, generous and missing limits
+func createRequestLimitSheet(f *excelize.File, workloadTotals map[workloadKey]workloadTotal, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create request vs limit sheet: %w", err)
+	}
+
+	// Limits are split into one column per class so every class becomes its own chart series
+	headers := []string{
+		"Workload", "Pods",
+		"Request CPU (m)", "Limit CPU (m) Tight", "Limit CPU (m) Generous", "Limit CPU (m) Missing",
+		"Request Memory (Mi)", "Limit Memory (Mi) Tight", "Limit Memory (Mi) Generous", "Limit Memory (Mi) Missing",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	classColumn := map[string]int{LimitClassTight: 0, LimitClassGenerous: 1, LimitClassMissing: 2}
+
+	row := 2
+	for _, key := range sortedWorkloads(workloadTotals) {
+		totals := workloadTotals[key]
+		if totals.pods == 0 {
+			continue
+		}
+		pods := float64(totals.pods)
+		reqCPU := float64(totals.reqCPU) / pods
+		limCPU := float64(totals.limCPU) / pods
+		reqMem := bytesToMi(totals.reqMem) / pods
+		limMem := bytesToMi(totals.limMem) / pods
+
+		data := make([]interface{}, len(headers))
+		data[0] = key.String()
+		data[1] = totals.pods
+		data[2] = reqCPU
+		data[3+classColumn[limitClass(totals.reqCPU, totals.limCPU, totals.missingCPULimit)]] = limCPU
+		data[6] = reqMem
+		data[7+classColumn[limitClass(totals.reqMem, totals.limMem, totals.missingMemLimit)]] = limMem
+
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("workload '%s'", key)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	if row == 2 {
+		return nil
+	}
+	lastRow := row - 1
+
+	integerStyle := export.IntegerStyle(f)
+	lastCell, _ := excelize.CoordinatesToCellName(len(headers), lastRow)
+	f.SetCellStyle(sheetName, "C2", lastCell, integerStyle)
+
+	scatterSeries := func(xCol string, yCols []string) []excelize.ChartSeries {
+		var series []excelize.ChartSeries
+		for _, yCol := range yCols {
+			series = append(series, excelize.ChartSeries{
+				Name:       fmt.Sprintf("'%s'!$%s$1", sheetName, yCol),
+				Categories: fmt.Sprintf("'%s'!$%s$2:$%s$%d", sheetName, xCol, xCol, lastRow),
+				Values:     fmt.Sprintf("'%s'!$%s$2:$%s$%d", sheetName, yCol, yCol, lastRow),
+				Marker:     excelize.ChartMarker{Symbol: "circle", Size: 6},
+				Line:       excelize.ChartLine{Type: excelize.ChartLineNone},
+			})
+		}
+		return series
+	}
+
+	charts := []struct {
+		cell, title, xTitle, yTitle string
+		series                      []excelize.ChartSeries
+	}{
+		{"L1", "CPU Request vs Limit per Pod", "Request CPU (m)", "Limit CPU (m)", scatterSeries("C", []string{"D", "E", "F"})},
+		{"L32", "Memory Request vs Limit per Pod", "Request Memory (Mi)", "Limit Memory (Mi)", scatterSeries("G", []string{"H", "I", "J"})},
+	}
+	for _, chart := range charts {
+		if err := f.AddChart(sheetName, chart.cell, &excelize.Chart{
+			Type:         excelize.Scatter,
+			Series:       chart.series,
+			Title:        []excelize.RichTextRun{{Text: chart.title}},
+			Legend:       excelize.ChartLegend{Position: "top"},
+			ShowBlanksAs: "gap",
+			XAxis:        excelize.ChartAxis{Title: []excelize.RichTextRun{{Text: chart.xTitle}}, MajorGridLines: true},
+			YAxis:        excelize.ChartAxis{Title: []excelize.RichTextRun{{Text: chart.yTitle}}, MajorGridLines: true},
+			Dimension:    excelize.ChartDimension{Width: ChartBaseWidth, Height: ChartBaseHeight},
+		}); err != nil {
+			return fmt.Errorf("failed to add %s chart: %w", chart.title, err)
+		}
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", "B", 8)
+	f.SetColWidth(sheetName, "C", "J", 16)
+
+	return nil
+}
+
+// Histogram bin upper bounds for container request sizes
+var (
+	CPURequestBins    = []int64{50, 100, 250, 500, 1000, 2000, 4000} // millicores
+	MemoryRequestBins = []int64{64, 128, 256, 512, 1024, 2048, 4096} // Mi
+)
+
+// histogram counts values per bin; bin 0 holds unset (zero) values, bin i
+// holds values up to bounds[i-1] and the last bin everything above
+func histogram(values []int64, bounds []int64) []int {
+	counts := make([]int, len(bounds)+2)
+	for _, v := range values {
+		if v <= 0 {
+			counts[0]++
+			continue
+		}
+		idx := sort.Search(len(bounds), func(i int) bool { return v <= bounds[i] })
+		counts[idx+1]++
+	}
+	return counts
+}
+
+// histogramLabels returns display labels matching the bins of histogram
+func histogramLabels(bounds []int64, unit string) []string {
+	labels := []string{"Not set"}
+	lower := int64(0)
+	for _, upper := range bounds {
+		labels = append(labels, fmt.Sprintf("%d-%d%s", lower, upper, unit))
+		lower = upper
+	}
+	return append(labels, fmt.Sprintf(">%d%s", lower, unit))
+}
+
+// createRequestDistributionSheet writes binned request size tables with column charts
+func createRequestDistributionSheet(f *excelize.File, cpuRequests, memRequests []int64, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create distribution sheet: %w", err)
+	}
+
+	memRequestsMi := make([]int64, len(memRequests))
+	for i, v := range memRequests {
+		memRequestsMi[i] = bytesToWholeMi(v)
+	}
+
+	tables := []struct {
+		col, title string
+		labels     []string
+		counts     []int
+	}{
+		{"A", "CPU Request", histogramLabels(CPURequestBins, "m"), histogram(cpuRequests, CPURequestBins)},
+		{"D", "Memory Request", histogramLabels(MemoryRequestBins, "Mi"), histogram(memRequestsMi, MemoryRequestBins)},
+	}
+
+	for i, table := range tables {
+		colNum, _ := excelize.ColumnNameToNumber(table.col)
+		countCol, _ := excelize.ColumnNumberToName(colNum + 1)
+
+		header := []interface{}{table.title, "Containers"}
+		if err := f.SetSheetRow(sheetName, table.col+"1", &header); err != nil {
+			return fmt.Errorf("failed to set headers: %w", err)
+		}
+		for j, label := range table.labels {
+			data := []interface{}{label, table.counts[j]}
+			if err := f.SetSheetRow(sheetName, fmt.Sprintf("%s%d", table.col, j+2), &data); err != nil {
+				return fmt.Errorf("failed to set %s bin '%s': %w", table.title, label, err)
+			}
+		}
+		f.SetCellStyle(sheetName, table.col+"1", countCol+"1", export.BoldStyle(f))
+		f.SetColWidth(sheetName, table.col, countCol, 16)
+
+		lastRow := len(table.labels) + 1
+		if err := f.AddChart(sheetName, fmt.Sprintf("H%d", 1+i*22), &excelize.Chart{
+			Type: excelize.Col,
+			Series: []excelize.ChartSeries{{
+				Name:       fmt.Sprintf("'%s'!$%s$1", sheetName, countCol),
+				Categories: fmt.Sprintf("'%s'!$%s$2:$%s$%d", sheetName, table.col, table.col, lastRow),
+				Values:     fmt.Sprintf("'%s'!$%s$2:$%s$%d", sheetName, countCol, countCol, lastRow),
+			}},
+			Title:     []excelize.RichTextRun{{Text: table.title + " Size Distribution"}},
+			Legend:    excelize.ChartLegend{Position: "none"},
+			Dimension: excelize.ChartDimension{Width: ChartBaseWidth, Height: 400},
+		}); err != nil {
+			return fmt.Errorf("failed to add %s histogram: %w", table.title, err)
+		}
+	}
+
+	return nil
+}
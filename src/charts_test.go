@@ -0,0 +1,90 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestHistogram(t *testing.T) {
+	bounds := []int64{100, 500}
+
+	tests := []struct {
+		name   string
+		values []int64
+		want   []int
+	}{
+		{"empty", nil, []int{0, 0, 0, 0}},
+		{"unset values", []int64{0, 0}, []int{2, 0, 0, 0}},
+		{"bin edges inclusive", []int64{1, 100, 101, 500}, []int{0, 2, 2, 0}},
+		{"overflow bin", []int64{501, 10000}, []int{0, 0, 0, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := histogram(tt.values, bounds); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("histogram() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistogramLabels(t *testing.T) {
+	want := []string{"Not set", "0-100m", "100-500m", ">500m"}
+	if got := histogramLabels([]int64{100, 500}, "m"); !reflect.DeepEqual(got, want) {
+		t.Errorf("histogramLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestCreateNodeHeatmapSheet(t *testing.T) {
+	const sheet = "Node Heatmap"
+	nodeTotals := map[string]nodeTotal{
+		"10.0.0.1": {nodeName: "node-a", reqCPU: 1000, limCPU: 3000, reqMem: 1 << 30, limMem: 2 << 30, allocCPU: 2000, allocMem: 4 << 30},
+		"Unknown":  {reqCPU: 500, reqMem: 1 << 20}, // Unscheduled, no allocatable
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createNodeHeatmapSheet(f, nodeTotals, sheet, themes[DefaultTheme]); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		cell string
+		want string
+	}{
+		{"A2", "node-a"}, // Sorted by IP, labeled by node name when known
+		{"B2", "0.5"},
+		{"C2", "1.5"},
+		{"D2", "0.25"},
+		{"E2", "0.5"},
+		{"A3", "Unknown"},
+		{"B3", ""}, // Without allocatable the cells stay empty for the scale
+		{"E3", ""},
+	}
+	for _, tt := range tests {
+		if got, err := f.GetCellValue(sheet, tt.cell, excelize.Options{RawCellValue: true}); err != nil || got != tt.want {
+			t.Errorf("%s = %q, %v; want %q", tt.cell, got, err, tt.want)
+		}
+	}
+
+	formats, err := f.GetConditionalFormats(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scale, ok := formats["B2:E3"]
+	if len(formats) != 1 || !ok || len(scale) != 1 || scale[0].Type != "3_color_scale" || scale[0].MidValue != HeatmapMidValue {
+		t.Errorf("conditional formats = %+v, want one color scale over B2:E3", formats)
+	}
+
+	// Without nodes the sheet has headers only and no color scale
+	empty := excelize.NewFile()
+	defer empty.Close()
+	if err := createNodeHeatmapSheet(empty, nil, sheet, themes[DefaultTheme]); err != nil {
+		t.Fatal(err)
+	}
+	if formats, _ := empty.GetConditionalFormats(sheet); len(formats) != 0 {
+		t.Errorf("empty sheet has conditional formats %+v", formats)
+	}
+}
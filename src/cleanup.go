@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultFailedPodDays is the age after which failed pods are listed for cleanup
+const DefaultFailedPodDays = 7
+
+// MirrorPodAnnotation marks static pods mirrored from a node's manifest directory
+const MirrorPodAnnotation = "kubernetes.io/config.mirror"
+
+// Cleanup categories in sheet order
+const (
+	CleanupUnownedPod     = "Unowned pod"
+	CleanupFinishedJobPod = "Finished Job pod"
+	CleanupFailedPod      = "Failed pod"
+)
+
+var cleanupCategories = []string{CleanupUnownedPod, CleanupFinishedJobPod, CleanupFailedPod}
+
+// cleanupCandidate is a pod that is likely safe to delete
+type cleanupCandidate struct {
+	category       string
+	namespace, pod string
+	owner          string // "<Kind>/<name>", empty for unowned pods
+	phase          corev1.PodPhase
+	age            time.Duration
+	reqCPU, reqMem int64 // Container requests in millicores and bytes
+}
+
+// holdsRequests reports whether the pod still reserves its requests on a node;
+// the scheduler ignores pods in a terminal phase
+func (c cleanupCandidate) holdsRequests() bool {
+	return c.phase != corev1.PodSucceeded && c.phase != corev1.PodFailed
+}
+
+// finishedJobs returns the Jobs with a Complete or Failed condition
+func finishedJobs(jobs []batchv1.Job) map[workloadKey]bool {
+	finished := make(map[workloadKey]bool)
+	for _, job := range jobs {
+		for _, cond := range job.Status.Conditions {
+			if (cond.Type == batchv1.JobComplete || cond.Type == batchv1.JobFailed) && cond.Status == corev1.ConditionTrue {
+				finished[workloadKey{namespace: job.Namespace, kind: "Job", name: job.Name}] = true
+				break
+			}
+		}
+	}
+	return finished
+}
+
+// cleanupCandidates finds running or pending pods without a controller, pods of
+// finished Jobs that still hold their requests and failed pods older than failedAfter
+func cleanupCandidates(pods []corev1.Pod, finished map[workloadKey]bool, now time.Time, failedAfter time.Duration) []cleanupCandidate {
+	var candidates []cleanupCandidate
+	for i := range pods {
+		pod := &pods[i]
+		owner := workloadOf(pod)
+		age := now.Sub(pod.CreationTimestamp.Time)
+
+		var category string
+		switch {
+		case isActivePod(pod) && owner.kind == "Pod" && pod.Annotations[MirrorPodAnnotation] == "":
+			category = CleanupUnownedPod
+		case isActivePod(pod) && owner.kind == "Job" && finished[owner]:
+			category = CleanupFinishedJobPod
+		case pod.Status.Phase == corev1.PodFailed && age >= failedAfter:
+			category = CleanupFailedPod
+		default:
+			continue
+		}
+
+		c := cleanupCandidate{
+			category:  category,
+			namespace: pod.Namespace,
+			pod:       pod.Name,
+			phase:     pod.Status.Phase,
+			age:       age,
+		}
+		if owner.kind != "Pod" {
+			c.owner = owner.kind + "/" + owner.name
+		}
+		for _, container := range pod.Spec.Containers {
+			c.reqCPU += quantityMilli(container.Resources.Requests.Cpu())
+			c.reqMem += quantityBytes(container.Resources.Requests.Memory())
+		}
+		candidates = append(candidates, c)
+	}
+
+	order := make(map[string]int, len(cleanupCategories))
+	for i, category := range cleanupCategories {
+		order[category] = i
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.category != b.category {
+			return order[a.category] < order[b.category]
+		}
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		return a.pod < b.pod
+	})
+	return candidates
+}
+
+// createCleanupSheet lists cleanup candidates followed by per-category totals.
+// Only pods still holding their requests count as reclaimable capacity.
+func createCleanupSheet(f *excelize.File, candidates []cleanupCandidate, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create cleanup sheet: %w", err)
+	}
+
+	headers := []string{
+		"Category", "Namespace", "Pod", "Owner", "Phase", "Age (days)",
+		"Request CPU (cores)", "Request Memory (Gi)", "Holds Requests",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, c := range candidates {
+		holds := "No"
+		if c.holdsRequests() {
+			holds = "Yes"
+		}
+		data := []interface{}{
+			c.category,
+			c.namespace,
+			c.pod,
+			valueOrDash(c.owner),
+			string(c.phase),
+			int(c.age.Hours() / 24),
+			milliToCores(c.reqCPU),
+			bytesToGi(c.reqMem),
+			holds,
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("pod '%s'", c.pod)); err != nil {
+			return err
+		}
+		row++
+	}
+	last := row - 1
+	if last >= 2 {
+		f.SetCellStyle(sheetName, "G2", fmt.Sprintf("H%d", last), export.DecimalStyle(f, false))
+	}
+
+	// Per-category totals; the reclaimable columns only sum pods holding requests
+	row++
+	summaryHeaders := []interface{}{"Reclaimable", "Pods", "", "", "", "", "Request CPU (cores)", "Request Memory (Gi)"}
+	if err := export.SetRow(f, sheetName, row, summaryHeaders, "cleanup summary headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("H%d", row), export.BoldStyle(f))
+	row++
+
+	// Ranges cover at least row 2 so the formulas stay valid on an empty list
+	dataEnd := last
+	if dataEnd < 2 {
+		dataEnd = 2
+	}
+	categoryRange := fmt.Sprintf("$A$2:$A$%d", dataEnd)
+	holdsRange := fmt.Sprintf("$I$2:$I$%d", dataEnd)
+
+	first := row
+	for _, category := range cleanupCategories {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), category)
+		f.SetCellFormula(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("COUNTIF(%s,A%d)", categoryRange, row))
+		for _, col := range []string{"G", "H"} {
+			valueRange := fmt.Sprintf("$%s$2:$%s$%d", col, col, dataEnd)
+			f.SetCellFormula(sheetName, fmt.Sprintf("%s%d", col, row), fmt.Sprintf(`SUMIFS(%s,%s,A%d,%s,"Yes")`, valueRange, categoryRange, row, holdsRange))
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("H%d", row), export.DecimalStyle(f, false))
+		row++
+	}
+
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Total")
+	for _, col := range []string{"B", "G", "H"} {
+		f.SetCellFormula(sheetName, fmt.Sprintf("%s%d", col, row), fmt.Sprintf("SUM(%s%d:%s%d)", col, first, col, row-1))
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), export.BoldStyle(f))
+	f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("H%d", row), export.DecimalStyle(f, true))
+
+	f.SetColWidth(sheetName, "A", "A", 20)
+	f.SetColWidth(sheetName, "B", "D", 30)
+	f.SetColWidth(sheetName, "E", "F", 12)
+	f.SetColWidth(sheetName, "G", "I", 20)
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testCleanupPod(name string, phase corev1.PodPhase, created time.Time, ownerKind, ownerName string) corev1.Pod {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "batch", CreationTimestamp: metav1.NewTime(created)},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "main",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("250m"),
+				corev1.ResourceMemory: resource.MustParse("512Mi"),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+	if ownerKind != "" {
+		controller := true
+		pod.OwnerReferences = []metav1.OwnerReference{{Kind: ownerKind, Name: ownerName, Controller: &controller}}
+	}
+	return pod
+}
+
+func TestFinishedJobs(t *testing.T) {
+	job := func(name string, condType batchv1.JobConditionType, status corev1.ConditionStatus) batchv1.Job {
+		return batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "batch"},
+			Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: condType, Status: status}}},
+		}
+	}
+	finished := finishedJobs([]batchv1.Job{
+		job("done", batchv1.JobComplete, corev1.ConditionTrue),
+		job("broken", batchv1.JobFailed, corev1.ConditionTrue),
+		job("running", batchv1.JobSuspended, corev1.ConditionTrue),
+		job("pending", batchv1.JobComplete, corev1.ConditionFalse),
+	})
+
+	for name, want := range map[string]bool{"done": true, "broken": true, "running": false, "pending": false} {
+		if got := finished[workloadKey{namespace: "batch", kind: "Job", name: name}]; got != want {
+			t.Errorf("finishedJobs()[%s] = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestCleanupCandidates(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	mirror := testCleanupPod("etcd-node-1", corev1.PodRunning, now.Add(-30*day), "", "")
+	mirror.Annotations = map[string]string{MirrorPodAnnotation: "abc"}
+
+	pods := []corev1.Pod{
+		testCleanupPod("debug", corev1.PodRunning, now.Add(-3*day), "", ""),
+		testCleanupPod("web-7d9f-x1", corev1.PodRunning, now.Add(-3*day), "ReplicaSet", "web-7d9f"),
+		testCleanupPod("export-abc", corev1.PodRunning, now.Add(-2*day), "Job", "export"),
+		testCleanupPod("import-abc", corev1.PodRunning, now.Add(-2*day), "Job", "import"),
+		testCleanupPod("export-old", corev1.PodSucceeded, now.Add(-9*day), "Job", "export"),
+		testCleanupPod("crash-old", corev1.PodFailed, now.Add(-10*day), "Job", "crash"),
+		testCleanupPod("crash-new", corev1.PodFailed, now.Add(-1*day), "Job", "crash"),
+		mirror,
+	}
+	finished := map[workloadKey]bool{{namespace: "batch", kind: "Job", name: "export"}: true}
+
+	got := cleanupCandidates(pods, finished, now, 7*day)
+
+	want := []struct {
+		pod, category, owner string
+		holds                bool
+	}{
+		{"debug", CleanupUnownedPod, "", true},
+		{"export-abc", CleanupFinishedJobPod, "Job/export", true},
+		{"crash-old", CleanupFailedPod, "Job/crash", false},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("cleanupCandidates() returned %d candidates, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		c := got[i]
+		if c.pod != w.pod || c.category != w.category || c.owner != w.owner || c.holdsRequests() != w.holds {
+			t.Errorf("cleanupCandidates()[%d] = %+v (holds %v), want %+v", i, c, c.holdsRequests(), w)
+		}
+		if c.reqCPU != 250 || c.reqMem != 512*BytesPerMi {
+			t.Errorf("cleanupCandidates()[%d] requests = %dm/%d bytes", i, c.reqCPU, c.reqMem)
+		}
+	}
+	if days := int(got[2].age.Hours() / 24); days != 10 {
+		t.Errorf("crash-old age = %d days, want 10", days)
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// InClusterName is the cluster name used inside a pod when CLUSTER_NAME is not set
+const InClusterName = "in-cluster"
+
+// clusterIdentity names the cluster (and kubeconfig context) a report was generated from
+type clusterIdentity struct {
+	name    string
+	context string
+}
+
+// cacheKey identifies the cluster of a metadata cache: context and name
+func (c clusterIdentity) cacheKey() string {
+	return c.context + "/" + c.name
+}
+
+// resolveClusterIdentity determines the cluster name: the -cluster-name override,
+// CLUSTER_NAME when running in a pod, or the cluster of the current kubeconfig context
+func resolveClusterIdentity(kubeconfigPath, override string) clusterIdentity {
+	if override != "" {
+		return clusterIdentity{name: override}
+	}
+
+	if _, inCluster := os.LookupEnv("KUBERNETES_SERVICE_HOST"); inCluster {
+		if name := os.Getenv("CLUSTER_NAME"); name != "" {
+			return clusterIdentity{name: name}
+		}
+		return clusterIdentity{name: InClusterName}
+	}
+
+	config, err := kubeconfigRules(kubeconfigPath).Load()
+	if err != nil {
+		logrus.Debugf("Could not read kubeconfig for cluster name: %v", err)
+		return clusterIdentity{}
+	}
+	return clusterFromConfig(config)
+}
+
+// clusterFromConfig returns the cluster of the current context in a kubeconfig
+func clusterFromConfig(config *clientcmdapi.Config) clusterIdentity {
+	identity := clusterIdentity{context: config.CurrentContext}
+	if ctx, ok := config.Contexts[config.CurrentContext]; ok && ctx.Cluster != "" {
+		identity.name = ctx.Cluster
+	} else {
+		identity.name = config.CurrentContext
+	}
+	return identity
+}
+
+// ServiceAccountNamespaceFile holds the namespace of the pod's service account
+const ServiceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// contextNamespace returns the namespace kubectl would use without -n: the
+// namespace of the current kubeconfig context, or of the service account when
+// running in a pod
+func contextNamespace(kubeconfigPath string) (string, error) {
+	if _, inCluster := os.LookupEnv("KUBERNETES_SERVICE_HOST"); inCluster {
+		data, err := os.ReadFile(ServiceAccountNamespaceFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read service account namespace: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	config, err := kubeconfigRules(kubeconfigPath).Load()
+	if err != nil {
+		return "", fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+	return namespaceFromConfig(config), nil
+}
+
+// namespaceFromConfig returns the namespace of the current context in a
+// kubeconfig, default when the context sets none
+func namespaceFromConfig(config *clientcmdapi.Config) string {
+	if ctx, ok := config.Contexts[config.CurrentContext]; ok && ctx.Namespace != "" {
+		return ctx.Namespace
+	}
+	return metav1.NamespaceDefault
+}
+
+// kubeconfigPaths are the files of a repeatable -kubeconfig flag; each value may
+// also list several files separated like PATH
+type kubeconfigPaths []string
+
+// kubeconfigFlag defines the -kubeconfig flag on fs
+func kubeconfigFlag(fs *flag.FlagSet) *kubeconfigPaths {
+	paths := &kubeconfigPaths{}
+	fs.Var(paths, "kubeconfig", "Path to kubeconfig file; repeat the flag or separate paths with ':' to merge several (default: KUBECONFIG or ~/.kube/config)")
+	return paths
+}
+
+// String returns the paths as a list separated like PATH, the form the client
+// functions take
+func (p *kubeconfigPaths) String() string {
+	if p == nil {
+		return ""
+	}
+	return strings.Join(*p, string(os.PathListSeparator))
+}
+
+// Set adds the paths of one -kubeconfig value
+func (p *kubeconfigPaths) Set(value string) error {
+	added := false
+	for _, path := range filepath.SplitList(value) {
+		if path != "" {
+			*p = append(*p, path)
+			added = true
+		}
+	}
+	if !added {
+		return fmt.Errorf("empty kubeconfig path")
+	}
+	return nil
+}
+
+// validate checks the paths. Unlike files listed in KUBECONFIG, which kubectl
+// skips when missing, each given file must exist.
+func (p kubeconfigPaths) validate() error {
+	for _, path := range p {
+		if err := validatePath(path); err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// kubeconfigRules returns the loading rules of kubectl for a kubeconfig path or
+// a list of paths separated like PATH; empty selects the files of KUBECONFIG,
+// else ~/.kube/config. Several files are merged: the first file to set a
+// value, like the current context, wins.
+func kubeconfigRules(kubeconfigPath string) *clientcmd.ClientConfigLoadingRules {
+	if paths := filepath.SplitList(kubeconfigPath); len(paths) > 1 {
+		return &clientcmd.ClientConfigLoadingRules{Precedence: paths}
+	} else if len(paths) == 1 {
+		return &clientcmd.ClientConfigLoadingRules{ExplicitPath: paths[0]}
+	}
+	if paths := filepath.SplitList(os.Getenv(clientcmd.RecommendedConfigPathEnvVar)); len(paths) > 0 {
+		return &clientcmd.ClientConfigLoadingRules{Precedence: paths}
+	}
+	return &clientcmd.ClientConfigLoadingRules{ExplicitPath: defaultKubeconfigPath("")}
+}
+
+// defaultKubeconfigPath returns path or, when empty, ~/.kube/config
+func defaultKubeconfigPath(path string) string {
+	if path == "" {
+		if home := homeDir(); home != "" {
+			path = filepath.Join(home, ".kube", "config")
+		}
+	}
+	return path
+}
+
+// filenameClusterName shortens a cluster name for filenames; cloud provider names
+// like EKS ARNs ("arn:aws:eks:...:cluster/prod") are reduced to their last segment
+func filenameClusterName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return safeFilenamePart(name)
+}
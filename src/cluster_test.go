@@ -0,0 +1,168 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestClusterFromConfig(t *testing.T) {
+	config := &clientcmdapi.Config{
+		Contexts: map[string]*clientcmdapi.Context{
+			"admin@prod":  {Cluster: "prod-eu"},
+			"no-cluster":  {},
+			"eks-staging": {Cluster: "arn:aws:eks:eu-west-1:123456789012:cluster/staging"},
+		},
+	}
+
+	tests := []struct {
+		current string
+		want    clusterIdentity
+	}{
+		{"admin@prod", clusterIdentity{name: "prod-eu", context: "admin@prod"}},
+		{"no-cluster", clusterIdentity{name: "no-cluster", context: "no-cluster"}},
+		{"missing", clusterIdentity{name: "missing", context: "missing"}},
+		{"eks-staging", clusterIdentity{name: "arn:aws:eks:eu-west-1:123456789012:cluster/staging", context: "eks-staging"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.current, func(t *testing.T) {
+			config.CurrentContext = tt.current
+			if got := clusterFromConfig(config); got != tt.want {
+				t.Errorf("clusterFromConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceFromConfig(t *testing.T) {
+	config := &clientcmdapi.Config{
+		Contexts: map[string]*clientcmdapi.Context{
+			"shop-dev": {Cluster: "dev", Namespace: "shop"},
+			"admin":    {Cluster: "dev"},
+		},
+	}
+
+	tests := []struct {
+		current string
+		want    string
+	}{
+		{"shop-dev", "shop"},
+		{"admin", "default"},
+		{"missing", "default"},
+		{"", "default"},
+	}
+
+	for _, tt := range tests {
+		config.CurrentContext = tt.current
+		if got := namespaceFromConfig(config); got != tt.want {
+			t.Errorf("namespaceFromConfig() with context %q = %q, want %q", tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestKubeconfigPathsSet(t *testing.T) {
+	var paths kubeconfigPaths
+	for _, value := range []string{"a.yaml", strings.Join([]string{"b.yaml", "", "c.yaml"}, string(os.PathListSeparator))} {
+		if err := paths.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := (kubeconfigPaths{"a.yaml", "b.yaml", "c.yaml"}); !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+	if got := filepath.SplitList(paths.String()); !reflect.DeepEqual(got, []string(paths)) {
+		t.Errorf("String() = %q does not split back into the paths", paths.String())
+	}
+	if err := paths.Set(""); err == nil {
+		t.Error("expected an error for an empty path")
+	}
+	if err := (kubeconfigPaths{filepath.Join(t.TempDir(), "missing")}).validate(); err == nil {
+		t.Error("expected an error for a missing kubeconfig file")
+	}
+}
+
+func TestKubeconfigRulesMerge(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	prod := write("prod.yaml", `apiVersion: v1
+kind: Config
+current-context: prod
+clusters: [{name: prod, cluster: {server: "https://prod.example.com"}}]
+contexts: [{name: prod, context: {cluster: prod, namespace: shop}}]
+`)
+	staging := write("staging.yaml", `apiVersion: v1
+kind: Config
+current-context: staging
+clusters: [{name: staging, cluster: {server: "https://staging.example.com"}}]
+contexts: [{name: staging, context: {cluster: staging}}]
+`)
+	missing := filepath.Join(dir, "missing.yaml")
+	list := strings.Join([]string{prod, missing, staging}, string(os.PathListSeparator))
+
+	check := func(t *testing.T, kubeconfigPath string) {
+		config, err := kubeconfigRules(kubeconfigPath).Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(config.Contexts) != 2 || len(config.Clusters) != 2 {
+			t.Errorf("merged %d contexts and %d clusters, want 2 each", len(config.Contexts), len(config.Clusters))
+		}
+		if got := clusterFromConfig(config); got.context != "prod" {
+			t.Errorf("current context = %q, want the one of the first file", got.context)
+		}
+		if got := namespaceFromConfig(config); got != "shop" {
+			t.Errorf("namespace = %q, want shop", got)
+		}
+	}
+
+	t.Run("flag", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", "")
+		check(t, list)
+	})
+	t.Run("KUBECONFIG", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", list)
+		check(t, "")
+	})
+	t.Run("flag wins over KUBECONFIG", func(t *testing.T) {
+		t.Setenv("KUBECONFIG", staging)
+		if rules := kubeconfigRules(prod); rules.ExplicitPath != prod || len(rules.Precedence) != 0 {
+			t.Errorf("rules = %+v, want only %s", rules, prod)
+		}
+	})
+}
+
+func TestResolveClusterIdentityOverride(t *testing.T) {
+	got := resolveClusterIdentity("/nonexistent/kubeconfig", "my-cluster")
+	if want := (clusterIdentity{name: "my-cluster"}); got != want {
+		t.Errorf("resolveClusterIdentity() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFilenameClusterName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"prod-eu", "prod-eu"},
+		{"arn:aws:eks:eu-west-1:123456789012:cluster/staging", "staging"},
+		{"gke_project_europe-west1_main", "gke_project_europe-west1_main"},
+		{"kind kind", "kind-kind"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := filenameClusterName(tt.name); got != tt.want {
+			t.Errorf("filenameClusterName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// customColumnSpec is a user-defined computed column as written in the config file.
+// Exactly one of Formula, Template or Expression must be set.
+//
+//	columns:
+//	  - name: Cost Units
+//	    formula: "{request_cpu_m}/1000*4 + {request_memory_mi}/1024"
+//	  - name: Owner Label
+//	    template: '{{ index .Labels "owner" }}'
+//	  - name: CPU Burst
+//	    expression: "limits.cpu / requests.cpu"
+type customColumnSpec struct {
+	Name       string `json:"name"`
+	Formula    string `json:"formula,omitempty"`    // Excel formula, {field} is replaced by the cell of the same row
+	Template   string `json:"template,omitempty"`   // Go template evaluated over rowFields
+	Expression string `json:"expression,omitempty"` // CEL expression evaluated per row (see expr.go)
+}
+
+// customColumn is a validated custom column ready for rendering
+type customColumn struct {
+	name    string
+	formula string
+	tmpl    *template.Template
+	expr    *exprNode
+}
+
+// rowFields exposes container row values to custom column templates
+type rowFields struct {
+	Namespace       string
+	Pod             string
+	Container       string
+	Node            string
+	Status          string
+	QoSClass        string
+	TShirtSize      string
+	Team            string
+	RequestCPU      int64 // millicores
+	LimitCPU        int64 // millicores
+	RequestMemoryMi float64
+	LimitMemoryMi   float64
+	RestartCount    int32
+	Labels          map[string]string // Pod labels
+	Annotations     map[string]string // Pod annotations
+}
+
+// formulaFields maps formula placeholders to Resources sheet columns
+var formulaFields = map[string]string{
+	"namespace":            "A",
+	"pod":                  "B",
+	"container":            "C",
+	"request_cpu_m":        "D",
+	"request_memory_mi":    "F",
+	"limit_cpu_m":          "H",
+	"limit_memory_mi":      "J",
+	"restart_count":        "M",
+	"request_storage_gi":   "O",
+	"limit_storage_gi":     "Q",
+	"request_gpu":          "S",
+	"limit_gpu":            "U",
+	"status":               "W",
+	"qos_class":            "X",
+	"node":                 "Y",
+	"cpu_efficiency":       "Z",
+	"memory_efficiency":    "AA",
+	"cpu_cluster_share":    "AB",
+	"memory_cluster_share": "AC",
+	"tshirt_size":          "AD",
+}
+
+var formulaPlaceholder = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// parseCustomColumns validates custom column specs from the config file
+func parseCustomColumns(specs []customColumnSpec) ([]customColumn, error) {
+	columns := make([]customColumn, 0, len(specs))
+	for _, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("custom column without name")
+		}
+		kinds := 0
+		for _, source := range []string{spec.Formula, spec.Template, spec.Expression} {
+			if source != "" {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return nil, fmt.Errorf("custom column '%s' needs exactly one of formula, template or expression", spec.Name)
+		}
+
+		column := customColumn{name: spec.Name}
+		switch {
+		case spec.Formula != "":
+			for _, match := range formulaPlaceholder.FindAllStringSubmatch(spec.Formula, -1) {
+				if _, ok := formulaFields[match[1]]; !ok {
+					return nil, fmt.Errorf("custom column '%s' references unknown field '%s'", spec.Name, match[1])
+				}
+			}
+			column.formula = strings.TrimPrefix(spec.Formula, "=")
+		case spec.Expression != "":
+			expr, err := parseExpr(spec.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("invalid expression for custom column '%s': %w", spec.Name, err)
+			}
+			column.expr = &expr
+		default:
+			tmpl, err := template.New(spec.Name).Option("missingkey=zero").Parse(spec.Template)
+			if err != nil {
+				return nil, fmt.Errorf("invalid template for custom column '%s': %w", spec.Name, err)
+			}
+			column.tmpl = tmpl
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// formulaFor returns the column formula with placeholders resolved for a sheet row
+func (c customColumn) formulaFor(row int) string {
+	return formulaPlaceholder.ReplaceAllStringFunc(c.formula, func(placeholder string) string {
+		return fmt.Sprintf("%s%d", formulaFields[strings.Trim(placeholder, "{}")], row)
+	})
+}
+
+// render evaluates a template or expression column; numeric template results
+// are returned as numbers and an undefined expression value as nil
+func (c customColumn) render(fields rowFields) (interface{}, error) {
+	if c.expr != nil {
+		value, _ := c.expr.eval(fields)
+		return value, nil
+	}
+	var sb strings.Builder
+	if err := c.tmpl.Execute(&sb, fields); err != nil {
+		return nil, fmt.Errorf("failed to evaluate custom column '%s': %w", c.name, err)
+	}
+	result := strings.TrimSpace(sb.String())
+	if num, err := strconv.ParseFloat(result, 64); err == nil {
+		return num, nil
+	}
+	return result, nil
+}
+
+// setCustomColumns writes all custom column cells of a row starting at firstCol
+func setCustomColumns(f *excelize.File, sheetName string, row, firstCol int, columns []customColumn, fields rowFields) error {
+	for i, column := range columns {
+		cell, err := excelize.CoordinatesToCellName(firstCol+i, row)
+		if err != nil {
+			return fmt.Errorf("failed to get cell name for custom column '%s': %w", column.name, err)
+		}
+
+		if column.formula != "" {
+			if err := f.SetCellFormula(sheetName, cell, column.formulaFor(row)); err != nil {
+				return fmt.Errorf("failed to set formula for custom column '%s': %w", column.name, err)
+			}
+			continue
+		}
+
+		value, err := column.render(fields)
+		if err != nil {
+			return err
+		}
+		if err := f.SetCellValue(sheetName, cell, value); err != nil {
+			return fmt.Errorf("failed to set value for custom column '%s': %w", column.name, err)
+		}
+	}
+	return nil
+}
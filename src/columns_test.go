@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseCustomColumns(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []customColumnSpec
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"formula", []customColumnSpec{{Name: "CU", Formula: "{request_cpu_m}/1000"}}, false},
+		{"template", []customColumnSpec{{Name: "Owner", Template: "{{ .Namespace }}"}}, false},
+		{"expression", []customColumnSpec{{Name: "Burst", Expression: "limits.cpu / requests.cpu"}}, false},
+		{"missing name", []customColumnSpec{{Formula: "1"}}, true},
+		{"neither formula nor template", []customColumnSpec{{Name: "X"}}, true},
+		{"both formula and template", []customColumnSpec{{Name: "X", Formula: "1", Template: "1"}}, true},
+		{"both template and expression", []customColumnSpec{{Name: "X", Template: "1", Expression: "1"}}, true},
+		{"invalid expression", []customColumnSpec{{Name: "X", Expression: "requests.cpu +"}}, true},
+		{"unknown field", []customColumnSpec{{Name: "X", Formula: "{cpu}*2"}}, true},
+		{"invalid template", []customColumnSpec{{Name: "X", Template: "{{ .Pod "}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseCustomColumns(tt.specs); (err != nil) != tt.wantErr {
+				t.Errorf("parseCustomColumns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomColumnFormulaFor(t *testing.T) {
+	columns, err := parseCustomColumns([]customColumnSpec{
+		{Name: "CU", Formula: "={request_cpu_m}/1000*4+{request_memory_mi}/1024"},
+	})
+	if err != nil {
+		t.Fatalf("parseCustomColumns() error = %v", err)
+	}
+
+	want := "D7/1000*4+F7/1024"
+	if got := columns[0].formulaFor(7); got != want {
+		t.Errorf("formulaFor() = %v, want %v", got, want)
+	}
+}
+
+func TestCustomColumnRender(t *testing.T) {
+	columns, err := parseCustomColumns([]customColumnSpec{
+		{Name: "Label", Template: `{{ index .Labels "app" }}-{{ .TShirtSize }}`},
+		{Name: "Units", Template: `{{ .RequestCPU }}`},
+		{Name: "Burst", Expression: "limits.cpu / requests.cpu"},
+	})
+	if err != nil {
+		t.Fatalf("parseCustomColumns() error = %v", err)
+	}
+
+	fields := rowFields{RequestCPU: 250, LimitCPU: 500, TShirtSize: "S", Labels: map[string]string{"app": "web"}}
+
+	if got, err := columns[0].render(fields); err != nil || got != "web-S" {
+		t.Errorf("render() = %v, %v, want web-S", got, err)
+	}
+	if got, err := columns[1].render(fields); err != nil || got != float64(250) {
+		t.Errorf("render() = %v (%T), %v, want numeric 250", got, got, err)
+	}
+	if got, err := columns[2].render(fields); err != nil || got != 2.0 {
+		t.Errorf("render() = %v, %v, want 2", got, err)
+	}
+	if got, err := columns[2].render(rowFields{LimitCPU: 500}); err != nil || got != nil {
+		t.Errorf("render() = %v, %v, want nil without a request", got, err)
+	}
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Subcommands; flags without a subcommand run the report
+const (
+	CommandReport       = "report"
+	CommandServe        = "serve"
+	CommandCheck        = "check"
+	CommandCompare      = "compare"
+	CommandFleet        = "fleet"
+	CommandParity       = "parity"
+	CommandIngestReview = "ingest-review"
+	CommandRender       = "render"
+	CommandSimulate     = "simulate"
+	CommandWebhook      = "webhook"
+	CommandInit         = "init"
+	CommandSelfUpdate   = "self-update"
+	CommandCompletion   = "completion"
+	CommandHelp         = "help"
+)
+
+// Defaults of the serve and check subcommands
+const (
+	DefaultListenAddr  = ":8080" // serve without -serve
+	DefaultCheckFailOn = "error" // check without -fail-on or failOn in the config
+)
+
+// command is a subcommand with its one-line help
+type command struct {
+	name    string
+	summary string
+}
+
+// commands lists the subcommands in help order
+var commands = []command{
+	{CommandReport, "Write the report workbook or BI export (default without a subcommand)"},
+	{CommandServe, "Serve the report over HTTP and regenerate it when pods change"},
+	{CommandCheck, "Evaluate the validation rules without writing a report (exit code 2 on findings)"},
+	{CommandCompare, "Compare the namespace requests of two offline bundles"},
+	{CommandFleet, "Roll up the offline bundles of several clusters into a fleet overview"},
+	{CommandParity, "Match the workloads of two environments and list resource spec divergence"},
+	{CommandIngestReview, "Read the review decisions of a workbook's Warnings sheet into a review file"},
+	{CommandRender, "Write the report of an offline bundle without cluster access"},
+	{CommandSimulate, "Project node pool allocation for a what-if scenario"},
+	{CommandWebhook, "Serve an admission webhook annotating new pods with their size and efficiency class"},
+	{CommandInit, "Set up a config file interactively and check the cluster permissions"},
+	{CommandSelfUpdate, "Replace this binary with the latest verified release"},
+	{CommandCompletion, "Print a bash, zsh or fish completion script"},
+	{CommandHelp, "Show this help"},
+}
+
+// completionShells are the shells of the completion subcommand
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// splitCommand returns the subcommand and its arguments. Arguments starting
+// with a flag run the report, so existing invocations keep working.
+func splitCommand(args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return CommandReport, args, nil
+	}
+	for _, c := range commands {
+		if args[0] == c.name {
+			return c.name, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("unknown command '%s' (run 'help' for the list of commands)", args[0])
+}
+
+// commandFlags returns the flag set of each subcommand; report, serve and
+// check share the report flags
+func commandFlags(report *flag.FlagSet) map[string]*flag.FlagSet {
+	renderFS, _ := renderFlags()
+	simulateFS, _ := simulateFlags()
+	webhookFS, _ := webhookFlags()
+	compareFS, _ := compareFlags()
+	fleetFS, _ := fleetFlags()
+	parityFS, _ := parityFlags()
+	ingestReviewFS, _ := ingestReviewFlags()
+	initFS, _ := initFlags()
+	selfUpdateFS, _ := selfUpdateFlags()
+	return map[string]*flag.FlagSet{
+		CommandReport:       report,
+		CommandServe:        report,
+		CommandCheck:        report,
+		CommandCompare:      compareFS,
+		CommandFleet:        fleetFS,
+		CommandParity:       parityFS,
+		CommandIngestReview: ingestReviewFS,
+		CommandRender:       renderFS,
+		CommandSimulate:     simulateFS,
+		CommandWebhook:      webhookFS,
+		CommandInit:         initFS,
+		CommandSelfUpdate:   selfUpdateFS,
+	}
+}
+
+// printUsage writes the subcommand overview and the report flags
+func printUsage(w io.Writer, program string, report *flag.FlagSet) {
+	fmt.Fprintf(w, "Usage: %s [command] [flags]\n\nCommands:\n", program)
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-14s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command. Report flags:\n", program)
+	report.SetOutput(w)
+	report.PrintDefaults()
+}
+
+// flagNames returns the flags of a flag set with their leading dash, sorted
+func flagNames(fs *flag.FlagSet) []string {
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		names = append(names, "-"+f.Name)
+	})
+	sort.Strings(names)
+	return names
+}
+
+// completionScript returns the completion script of a shell for program
+func completionScript(shell, program string, flags map[string]*flag.FlagSet) (string, error) {
+	var names []string
+	for _, c := range commands {
+		names = append(names, c.name)
+	}
+	// Commands with their own flags; the others complete the report flags
+	own := []string{CommandCompare, CommandFleet, CommandParity, CommandIngestReview, CommandRender, CommandSimulate, CommandWebhook, CommandInit, CommandSelfUpdate}
+	function := "_" + strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, program)
+
+	var b strings.Builder
+	switch shell {
+	case "bash":
+		fmt.Fprintf(&b, "# bash completion for %s\n", program)
+		fmt.Fprintf(&b, "%s() {\n", function)
+		b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" words\n")
+		b.WriteString("    case \"${COMP_WORDS[1]}\" in\n")
+		fmt.Fprintf(&b, "        %s) [[ ${COMP_CWORD} -eq 2 ]] && COMPREPLY=($(compgen -W \"%s\" -- \"${cur}\")); return ;;\n", CommandCompletion, strings.Join(completionShells, " "))
+		for _, name := range own {
+			fmt.Fprintf(&b, "        %s) words=\"%s\" ;;\n", name, strings.Join(flagNames(flags[name]), " "))
+		}
+		fmt.Fprintf(&b, "        *) words=\"%s\" ;;\n", strings.Join(flagNames(flags[CommandReport]), " "))
+		b.WriteString("    esac\n")
+		fmt.Fprintf(&b, "    [[ ${COMP_CWORD} -eq 1 ]] && words=\"%s ${words}\"\n", strings.Join(names, " "))
+		b.WriteString("    if [[ ${cur} == -* || ${COMP_CWORD} -eq 1 ]]; then\n")
+		b.WriteString("        COMPREPLY=($(compgen -W \"${words}\" -- \"${cur}\"))\n")
+		b.WriteString("    fi\n")
+		b.WriteString("}\n")
+		fmt.Fprintf(&b, "complete -o default -F %s %s\n", function, program)
+	case "zsh":
+		fmt.Fprintf(&b, "#compdef %s\n", program)
+		fmt.Fprintf(&b, "%s() {\n", function)
+		b.WriteString("    local -a opts\n")
+		b.WriteString("    case ${words[2]} in\n")
+		fmt.Fprintf(&b, "        %s) (( CURRENT == 3 )) && compadd -- %s; return ;;\n", CommandCompletion, strings.Join(completionShells, " "))
+		for _, name := range own {
+			fmt.Fprintf(&b, "        %s) opts=(%s) ;;\n", name, strings.Join(flagNames(flags[name]), " "))
+		}
+		fmt.Fprintf(&b, "        *) opts=(%s) ;;\n", strings.Join(flagNames(flags[CommandReport]), " "))
+		b.WriteString("    esac\n")
+		fmt.Fprintf(&b, "    (( CURRENT == 2 )) && opts=(%s $opts)\n", strings.Join(names, " "))
+		b.WriteString("    if [[ $PREFIX == -* ]] || (( CURRENT == 2 )); then\n")
+		b.WriteString("        compadd -- $opts\n")
+		b.WriteString("    else\n")
+		b.WriteString("        _files\n")
+		b.WriteString("    fi\n")
+		b.WriteString("}\n")
+		fmt.Fprintf(&b, "compdef %s %s\n", function, program)
+	case "fish":
+		fmt.Fprintf(&b, "# fish completion for %s\n", program)
+		for _, c := range commands {
+			fmt.Fprintf(&b, "complete -c %s -n '__fish_use_subcommand' -a %s -d %s\n", program, c.name, fishQuote(c.summary))
+		}
+		fmt.Fprintf(&b, "complete -c %s -n '__fish_seen_subcommand_from %s' -f -a '%s'\n", program, CommandCompletion, strings.Join(completionShells, " "))
+		condition := fmt.Sprintf("not __fish_seen_subcommand_from %s %s %s", strings.Join(own, " "), CommandCompletion, CommandHelp)
+		writeFishFlags(&b, program, condition, flags[CommandReport])
+		for _, name := range own {
+			writeFishFlags(&b, program, "__fish_seen_subcommand_from "+name, flags[name])
+		}
+	default:
+		return "", fmt.Errorf("unknown shell '%s' (valid: %s)", shell, strings.Join(completionShells, ", "))
+	}
+	return b.String(), nil
+}
+
+// writeFishFlags writes the fish completions of the flags of fs; Go flags are
+// old-style single dash options
+func writeFishFlags(b *strings.Builder, program, condition string, fs *flag.FlagSet) {
+	fs.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(b, "complete -c %s -n '%s' -o %s -d %s\n", program, condition, f.Name, fishQuote(f.Usage))
+	})
+}
+
+// fishQuote single-quotes s for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
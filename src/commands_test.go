@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		args     []string
+		wantCmd  string
+		wantArgs []string
+		wantErr  bool
+	}{
+		{nil, CommandReport, nil, false},
+		{[]string{"-namespace", "shop"}, CommandReport, []string{"-namespace", "shop"}, false},
+		{[]string{"report", "-verbose"}, CommandReport, []string{"-verbose"}, false},
+		{[]string{"check", "-fail-on", "warn"}, CommandCheck, []string{"-fail-on", "warn"}, false},
+		{[]string{"completion", "zsh"}, CommandCompletion, []string{"zsh"}, false},
+		{[]string{"self-update", "-check"}, CommandSelfUpdate, []string{"-check"}, false},
+		{[]string{"repot"}, "", nil, true},
+	}
+	for _, tt := range tests {
+		cmd, args, err := splitCommand(tt.args)
+		if (err != nil) != tt.wantErr || cmd != tt.wantCmd || !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("splitCommand(%v) = %q, %v, %v", tt.args, cmd, args, err)
+		}
+	}
+}
+
+func TestCompletionScript(t *testing.T) {
+	report := flag.NewFlagSet("report", flag.ContinueOnError)
+	report.String("namespace", "", "Kubernetes namespace")
+	report.Bool("verbose", false, "Enable verbose logging")
+	flags := commandFlags(report)
+
+	tests := []struct {
+		shell string
+		want  []string
+	}{
+		{"bash", []string{
+			"complete -o default -F _PodResourceCalculator PodResourceCalculator",
+			`render) words="-append -bundle -findings -findings-format -output -quiet -verbose"`,
+			`*) words="-namespace -verbose"`,
+		}},
+		{"zsh", []string{
+			"compdef _PodResourceCalculator PodResourceCalculator",
+			"compare) opts=(-after -before -changed-only -quiet -verbose)",
+		}},
+		{"fish", []string{
+			"complete -c PodResourceCalculator -n '__fish_use_subcommand' -a check",
+			"complete -c PodResourceCalculator -n '__fish_seen_subcommand_from simulate' -o scenario",
+			"-o namespace -d 'Kubernetes namespace'",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			script, err := completionScript(tt.shell, "PodResourceCalculator", flags)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(script, want) {
+					t.Errorf("script lacks %q:\n%s", want, script)
+				}
+			}
+		})
+	}
+	if _, err := completionScript("powershell", "PodResourceCalculator", flags); err == nil {
+		t.Error("expected an error for an unknown shell")
+	}
+}
+
+func TestFishQuote(t *testing.T) {
+	if got := fishQuote(`the bundle's path \ file`); got != `'the bundle\'s path \\ file'` {
+		t.Errorf("fishQuote() = %s", got)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// compareArgs are the flags of the compare subcommand
+type compareArgs struct {
+	before, after  *string
+	changedOnly    *bool
+	verbose, quiet *bool
+}
+
+// compareFlags defines the flags of the compare subcommand
+func compareFlags() (*flag.FlagSet, *compareArgs) {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	return fs, &compareArgs{
+		before:      fs.String("before", "", "Offline bundle of the earlier snapshot"),
+		after:       fs.String("after", "", "Offline bundle of the later snapshot"),
+		changedOnly: fs.Bool("changed-only", false, "Only list namespaces whose requests changed"),
+		verbose:     fs.Bool("verbose", false, "Enable verbose logging"),
+		quiet:       fs.Bool("quiet", false, "Only log errors (logs always go to stderr)"),
+	}
+}
+
+// namespaceDelta are the totals of a namespace in two snapshots
+type namespaceDelta struct {
+	namespace     string
+	before, after namespaceTotal
+}
+
+// changed reports whether the requests of the namespace changed
+func (d namespaceDelta) changed() bool {
+	return d.before.reqCPU != d.after.reqCPU || d.before.reqMem != d.after.reqMem
+}
+
+// compareSnapshots returns the namespace totals of both snapshots, sorted by
+// namespace; namespaces of only one snapshot have zero totals in the other
+func compareSnapshots(before, after *clusterSnapshot) []namespaceDelta {
+	beforeTotals, _ := aggregateTotals(before.pods, before.nodes)
+	afterTotals, _ := aggregateTotals(after.pods, after.nodes)
+
+	byNamespace := make(map[string]namespaceDelta)
+	for ns, totals := range beforeTotals {
+		byNamespace[ns] = namespaceDelta{namespace: ns, before: totals}
+	}
+	for ns, totals := range afterTotals {
+		d := byNamespace[ns]
+		d.namespace, d.after = ns, totals
+		byNamespace[ns] = d
+	}
+
+	result := make([]namespaceDelta, 0, len(byNamespace))
+	for _, d := range byNamespace {
+		result = append(result, d)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].namespace < result[j].namespace })
+	return result
+}
+
+// printComparison writes the request changes per namespace and the cluster
+// total as an aligned table
+func printComparison(w io.Writer, deltas []namespaceDelta, collectedBefore, collectedAfter time.Time, changedOnly bool) error {
+	fmt.Fprintf(w, "Before: %s\nAfter:  %s\n\n", collectedBefore.Format(time.RFC3339), collectedAfter.Format(time.RFC3339))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Namespace\tCPU Before\tCPU After\tCPU Δ (cores)\tMemory Before (Gi)\tMemory After (Gi)\tMemory Δ (Gi)\t")
+	row := func(name string, before, after namespaceTotal) {
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t%+.2f\t%.2f\t%.2f\t%+.2f\t\n", name,
+			milliToCores(before.reqCPU), milliToCores(after.reqCPU), milliToCores(after.reqCPU-before.reqCPU),
+			bytesToGi(before.reqMem), bytesToGi(after.reqMem), bytesToGi(after.reqMem-before.reqMem))
+	}
+	var totalBefore, totalAfter namespaceTotal
+	for _, d := range deltas {
+		totalBefore.reqCPU += d.before.reqCPU
+		totalBefore.reqMem += d.before.reqMem
+		totalAfter.reqCPU += d.after.reqCPU
+		totalAfter.reqMem += d.after.reqMem
+		if changedOnly && !d.changed() {
+			continue
+		}
+		row(d.namespace, d.before, d.after)
+	}
+	row("CLUSTER TOTAL", totalBefore, totalAfter)
+	return tw.Flush()
+}
+
+// runCompare implements the compare subcommand: it prints the request changes
+// per namespace between two offline bundles
+func runCompare(args []string) error {
+	fs, a := compareFlags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := configureLogging(*a.verbose, *a.quiet); err != nil {
+		return err
+	}
+	if *a.before == "" || *a.after == "" {
+		return fmt.Errorf("-before and -after are required")
+	}
+
+	var snaps []*clusterSnapshot
+	for _, path := range []string{*a.before, *a.after} {
+		b, err := readBundle(path)
+		if err != nil {
+			return err
+		}
+		location, err := loadTimezone(b.Config.Timezone)
+		if err != nil {
+			return fmt.Errorf("invalid timezone in %s: %w", path, err)
+		}
+		snaps = append(snaps, b.snapshot(location))
+	}
+	before, after := snaps[0], snaps[1]
+	if after.collected.Before(before.collected) {
+		logrus.Warnf("The -after bundle was collected before the -before bundle")
+	}
+
+	return printComparison(stdout, compareSnapshots(before, after), before.collected, after.collected, *a.changedOnly)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareSnapshots(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	before := testAPISnapshot(now)
+	after := testAPISnapshot(now.AddDate(0, 0, 7))
+	after.pods = append(after.pods[:1], after.pods[2]) // web-2 removed
+	after.pods[1].Namespace = "catalog"                // search renamed
+
+	deltas := compareSnapshots(before, after)
+	got := make(map[string]namespaceDelta)
+	var order []string
+	for _, d := range deltas {
+		got[d.namespace] = d
+		order = append(order, d.namespace)
+	}
+	if strings.Join(order, ",") != "catalog,search,shop" {
+		t.Errorf("namespaces = %v, want sorted union", order)
+	}
+	if d := got["shop"]; d.before.reqCPU != 1000 || d.after.reqCPU != 500 {
+		t.Errorf("shop = %+v, want 1000m before and 500m after", d)
+	}
+	if d := got["search"]; d.before.reqCPU != 2000 || d.after.reqCPU != 0 {
+		t.Errorf("search = %+v, want removed", d)
+	}
+	if d := got["catalog"]; d.before.reqCPU != 0 || d.after.reqCPU != 2000 {
+		t.Errorf("catalog = %+v, want added", d)
+	}
+
+	var buf bytes.Buffer
+	if err := printComparison(&buf, deltas, before.collected, after.collected, true); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "-0.50") || !strings.Contains(out, "CLUSTER TOTAL") {
+		t.Errorf("comparison output:\n%s", out)
+	}
+}
+
+func TestPrintComparisonChangedOnly(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(now)
+	deltas := compareSnapshots(snap, snap)
+	for _, changedOnly := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := printComparison(&buf, deltas, now, now, changedOnly); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(buf.String(), "shop"); got == changedOnly {
+			t.Errorf("changedOnly=%v: shop listed = %v", changedOnly, got)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// DefaultComputeUnitName labels compute units when the config names none
+const DefaultComputeUnitName = "CU"
+
+// ComputeUnitChartWorkloads is the number of workloads in the Compute Units chart
+const ComputeUnitChartWorkloads = 15
+
+// computeUnitSpec is the compute unit as written in the config file, e.g. 1 CU
+// = 1 vCPU + 4Gi. Either dimension may be left out to charge by the other only.
+type computeUnitSpec struct {
+	Name   string `json:"name,omitempty"`   // Display name, default CU
+	CPU    string `json:"cpu,omitempty"`    // CPU per unit, e.g. "1"
+	Memory string `json:"memory,omitempty"` // Memory per unit, e.g. "4Gi"
+}
+
+// computeUnit is the parsed compute unit
+type computeUnit struct {
+	name string
+	cpu  int64 // Millicores, 0 when not charged
+	mem  int64 // Bytes, 0 when not charged
+}
+
+// parseComputeUnit validates the compute unit; a nil result disables the
+// Compute Units sheet
+func parseComputeUnit(spec *computeUnitSpec) (*computeUnit, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	u := &computeUnit{name: spec.Name}
+	if u.name == "" {
+		u.name = DefaultComputeUnitName
+	}
+	if spec.CPU != "" {
+		q, err := resource.ParseQuantity(spec.CPU)
+		if err != nil || q.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid computeUnit cpu '%s'", spec.CPU)
+		}
+		u.cpu = q.MilliValue()
+	}
+	if spec.Memory != "" {
+		q, err := resource.ParseQuantity(spec.Memory)
+		if err != nil || q.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid computeUnit memory '%s'", spec.Memory)
+		}
+		u.mem = q.Value()
+	}
+	if u.cpu == 0 && u.mem == 0 {
+		return nil, fmt.Errorf("computeUnit needs cpu or memory")
+	}
+	return u, nil
+}
+
+// units converts requests into CPU and memory units; a pod consumes the larger
+// of the two, as a unit can't be split between dimensions
+func (u *computeUnit) units(reqCPU, reqMem int64) (cpu, mem, units float64) {
+	if u.cpu > 0 {
+		cpu = float64(reqCPU) / float64(u.cpu)
+	}
+	if u.mem > 0 {
+		mem = float64(reqMem) / float64(u.mem)
+	}
+	return cpu, mem, maxFloat(cpu, mem)
+}
+
+// definition describes the unit, e.g. "1 CU = 1 cores + 4 Gi"
+func (u *computeUnit) definition() string {
+	def := "1 " + u.name + " ="
+	if u.cpu > 0 {
+		def += fmt.Sprintf(" %g cores", milliToCores(u.cpu))
+	}
+	if u.cpu > 0 && u.mem > 0 {
+		def += " +"
+	}
+	if u.mem > 0 {
+		def += fmt.Sprintf(" %g Gi", bytesToGi(u.mem))
+	}
+	return def
+}
+
+// computeUnitUsage is the compute unit consumption of a namespace or workload
+type computeUnitUsage struct {
+	name               string // Namespace or workload key
+	pods               int
+	reqCPU, reqMem     int64
+	cpuUnits, memUnits float64
+	units              float64 // Sum of the pods' larger dimension
+}
+
+// computeUnitUsages sums the compute units of active pods per namespace and per
+// workload, most units first
+func computeUnitUsages(pods []corev1.Pod, u *computeUnit) (namespaces, workloads []computeUnitUsage) {
+	byNamespace := make(map[string]*computeUnitUsage)
+	byWorkload := make(map[string]*computeUnitUsage)
+	add := func(m map[string]*computeUnitUsage, name string, reqCPU, reqMem int64) {
+		usage, ok := m[name]
+		if !ok {
+			usage = &computeUnitUsage{name: name}
+			m[name] = usage
+		}
+		cpu, mem, units := u.units(reqCPU, reqMem)
+		usage.pods++
+		usage.reqCPU += reqCPU
+		usage.reqMem += reqMem
+		usage.cpuUnits += cpu
+		usage.memUnits += mem
+		usage.units += units
+	}
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		reqCPU, reqMem := podRequests(pod)
+		add(byNamespace, pod.Namespace, reqCPU, reqMem)
+		add(byWorkload, workloadOf(pod).String(), reqCPU, reqMem)
+	}
+
+	sorted := func(m map[string]*computeUnitUsage) []computeUnitUsage {
+		usages := make([]computeUnitUsage, 0, len(m))
+		for _, usage := range m {
+			usages = append(usages, *usage)
+		}
+		sort.Slice(usages, func(i, j int) bool {
+			if usages[i].units != usages[j].units {
+				return usages[i].units > usages[j].units
+			}
+			return usages[i].name < usages[j].name
+		})
+		return usages
+	}
+	return sorted(byNamespace), sorted(byWorkload)
+}
+
+// createComputeUnitSheet writes the compute units per namespace with a total,
+// then per workload, each with a bar chart for internal chargeback
+func createComputeUnitSheet(f *excelize.File, namespaces, workloads []computeUnitUsage, u *computeUnit, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create compute units sheet: %w", err)
+	}
+
+	f.SetCellValue(sheetName, "A1", u.definition()+"; each pod consumes the larger of its CPU and memory units")
+	decimalStyle := export.DecimalStyle(f, false)
+	percentStyle := export.PercentStyle(f, "0.0%")
+
+	var total computeUnitUsage
+	for _, usage := range namespaces {
+		total.pods += usage.pods
+		total.reqCPU += usage.reqCPU
+		total.reqMem += usage.reqMem
+		total.cpuUnits += usage.cpuUnits
+		total.memUnits += usage.memUnits
+		total.units += usage.units
+	}
+
+	row := 3
+	tables := []struct {
+		title  string
+		usages []computeUnitUsage
+		top    int // Rows in the chart
+	}{
+		{"Namespace", namespaces, len(namespaces)},
+		{"Workload", workloads, ComputeUnitChartWorkloads},
+	}
+	for _, table := range tables {
+		headers := []interface{}{
+			table.title, "Pods", "Request CPU (cores)", "Request Memory (Gi)",
+			"CPU " + u.name, "Memory " + u.name, u.name, "Share of " + u.name,
+		}
+		if err := export.SetRow(f, sheetName, row, headers, table.title+" compute unit headers"); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("H%d", row), export.BoldStyle(f))
+		header := row
+		row++
+		for _, usage := range table.usages {
+			data := []interface{}{
+				usage.name, usage.pods, milliToCores(usage.reqCPU), bytesToGi(usage.reqMem),
+				usage.cpuUnits, usage.memUnits, usage.units, ratioFloat(usage.units, total.units),
+			}
+			if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("%s '%s'", table.title, usage.name)); err != nil {
+				return err
+			}
+			f.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("G%d", row), decimalStyle)
+			f.SetCellStyle(sheetName, fmt.Sprintf("H%d", row), fmt.Sprintf("H%d", row), percentStyle)
+			row++
+		}
+
+		if n := min([]int{len(table.usages), table.top}); n > 0 {
+			if err := f.AddChart(sheetName, fmt.Sprintf("J%d", header), &excelize.Chart{
+				Type: excelize.Bar,
+				Series: []excelize.ChartSeries{{
+					Name:       fmt.Sprintf("'%s'!$G$%d", sheetName, header),
+					Categories: fmt.Sprintf("'%s'!$A$%d:$A$%d", sheetName, header+1, header+n),
+					Values:     fmt.Sprintf("'%s'!$G$%d:$G$%d", sheetName, header+1, header+n),
+				}},
+				Title:     []excelize.RichTextRun{{Text: fmt.Sprintf("%s per %s", u.name, table.title)}},
+				Legend:    excelize.ChartLegend{Position: "none"},
+				XAxis:     excelize.ChartAxis{ReverseOrder: true},
+				Dimension: excelize.ChartDimension{Width: ChartBaseWidth, Height: uint(min([]int{ChartMaxHeight, 200 + 20*n}))},
+			}); err != nil {
+				return fmt.Errorf("failed to add %s compute unit chart: %w", table.title, err)
+			}
+		}
+
+		if table.title == "Namespace" {
+			data := []interface{}{
+				"Total", total.pods, milliToCores(total.reqCPU), bytesToGi(total.reqMem),
+				total.cpuUnits, total.memUnits, total.units, ratioFloat(total.units, total.units),
+			}
+			if err := export.SetRow(f, sheetName, row, data, "compute unit total"); err != nil {
+				return err
+			}
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), export.DecimalStyle(f, true))
+			f.SetCellStyle(sheetName, fmt.Sprintf("H%d", row), fmt.Sprintf("H%d", row), percentStyle)
+			row++
+		}
+		// Leave room for the chart of the table
+		row = max([]int{row, header + 2 + min([]int{ChartMaxHeight, 200 + 20*table.top})/20}) + 2
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", "H", 18)
+
+	return nil
+}
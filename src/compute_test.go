@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseComputeUnit(t *testing.T) {
+	if u, err := parseComputeUnit(nil); u != nil || err != nil {
+		t.Errorf("parseComputeUnit(nil) = %+v, %v; want nil", u, err)
+	}
+	u, err := parseComputeUnit(&computeUnitSpec{CPU: "1", Memory: "4Gi"})
+	if err != nil || u.name != DefaultComputeUnitName || u.cpu != 1000 || u.mem != 4<<30 {
+		t.Errorf("parseComputeUnit() = %+v, %v", u, err)
+	}
+	if got := u.definition(); got != "1 CU = 1 cores + 4 Gi" {
+		t.Errorf("definition() = %q", got)
+	}
+	if u, err := parseComputeUnit(&computeUnitSpec{Name: "Slot", Memory: "8Gi"}); err != nil || u.cpu != 0 || u.definition() != "1 Slot = 8 Gi" {
+		t.Errorf("parseComputeUnit(memory only) = %+v, %v", u, err)
+	}
+	for _, spec := range []computeUnitSpec{{}, {CPU: "-1"}, {Memory: "lots"}} {
+		if _, err := parseComputeUnit(&spec); err == nil {
+			t.Errorf("parseComputeUnit(%+v) expected error", spec)
+		}
+	}
+}
+
+func TestComputeUnitUsages(t *testing.T) {
+	u, _ := parseComputeUnit(&computeUnitSpec{CPU: "1", Memory: "4Gi"})
+	pod := func(namespace, name, cpu, mem string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem),
+				}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("web", "web-1", "2", "2Gi"),    // 2 CPU units
+		pod("web", "web-2", "500m", "1Gi"), // 0.5
+		pod("cache", "redis", "1", "16Gi"), // 4 memory units
+	}
+
+	namespaces, workloads := computeUnitUsages(pods, u)
+	if len(namespaces) != 2 || len(workloads) != 3 {
+		t.Fatalf("computeUnitUsages() = %+v, %+v", namespaces, workloads)
+	}
+	if c := namespaces[0]; c.name != "cache" || c.units != 4 || c.cpuUnits != 1 || c.memUnits != 4 {
+		t.Errorf("cache = %+v, want 4 units", c)
+	}
+	if w := namespaces[1]; w.name != "web" || w.pods != 2 || w.units != 2.5 || w.memUnits != 0.75 {
+		t.Errorf("web = %+v, want 2.5 units", w)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createComputeUnitSheet(f, namespaces, workloads, u, "Compute Units"); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A3": "Namespace", "G3": "CU", "A4": "cache", "G4": "4.00", "A6": "Total", "G6": "6.50"} {
+		if got, _ := f.GetCellValue("Compute Units", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// config holds report settings loaded from the -config file (YAML or JSON)
+type config struct {
+	TShirtSizes       []tshirtSizeSpec       `json:"tshirtSizes,omitempty"`
+	Columns           []customColumnSpec     `json:"columns,omitempty"`
+	Links             []rowLinkSpec          `json:"links,omitempty"`    // Resources sheet hyperlinks
+	Agents            []agentSpec            `json:"agents,omitempty"`   // Replace the default agent list
+	Sheets            []string               `json:"sheets,omitempty"`   // Overridden by -sheets
+	Theme             string                 `json:"theme,omitempty"`    // Overridden by -theme
+	Language          string                 `json:"language,omitempty"` // Overridden by -language
+	Messages          map[string]string      `json:"messages,omitempty"` // Insights translations by English text
+	Timezone          string                 `json:"timezone,omitempty"` // Overridden by -timezone
+	Validation        validationSpec         `json:"validation,omitempty"`
+	Pricing           *pricingSpec           `json:"pricing,omitempty"`     // Enables the Cost sheet
+	Security          *securitySpec          `json:"security,omitempty"`    // Security Anomalies thresholds
+	Scoring           *scoringSpec           `json:"scoring,omitempty"`     // Insights efficiency ratings and recommendations
+	ComputeUnit       *computeUnitSpec       `json:"computeUnit,omitempty"` // Enables the Compute Units sheet
+	ExtendedResources []extendedResourceSpec `json:"extendedResources,omitempty"`
+	Hooks             []hookSpec             `json:"hooks,omitempty"`             // Enrichment commands run on each snapshot
+	ExcludeAnnotation string                 `json:"excludeAnnotation,omitempty"` // Overridden by -exclude-annotation
+	NamespacePattern  string                 `json:"namespacePattern,omitempty"`  // Overridden by -namespace-pattern
+	Alerts            *alertSpec             `json:"alerts,omitempty"`            // Notify on failed or slow report generation
+}
+
+// configOverrides are the flags that take precedence over the config file,
+// applied again to a config reloaded in server mode
+type configOverrides struct {
+	timezone          string
+	failOn            string
+	sheets            string
+	theme             string
+	language          string
+	excludeAnnotation string
+	namespacePattern  string
+	check             bool // The check subcommand fails on DefaultCheckFailOn without a fail-on
+}
+
+// apply sets the config fields of the given flags
+func (o configOverrides) apply(cfg *config) {
+	if o.timezone != "" {
+		cfg.Timezone = o.timezone
+	}
+	if o.failOn != "" {
+		cfg.Validation.FailOn = o.failOn
+	}
+	if o.check && cfg.Validation.FailOn == "" {
+		cfg.Validation.FailOn = DefaultCheckFailOn
+	}
+	if o.sheets != "" {
+		cfg.Sheets = strings.Split(o.sheets, ",")
+	}
+	if o.theme != "" {
+		cfg.Theme = o.theme
+	}
+	if o.language != "" {
+		cfg.Language = o.language
+	}
+	if o.excludeAnnotation != "" {
+		cfg.ExcludeAnnotation = o.excludeAnnotation
+	}
+	if o.namespacePattern != "" {
+		cfg.NamespacePattern = o.namespacePattern
+	}
+}
+
+// loadConfig reads the config file; an empty path yields the defaults
+func loadConfig(path string) (*config, error) {
+	cfg := &config{}
+	if path != "" {
+		if err := validatePath(path); err != nil {
+			return nil, fmt.Errorf("invalid config path: %w", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+		}
+		if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+
+	if len(cfg.TShirtSizes) == 0 {
+		cfg.TShirtSizes = defaultTShirtSizes
+	}
+	if len(cfg.Agents) == 0 {
+		cfg.Agents = defaultAgents
+	}
+
+	return cfg, nil
+}
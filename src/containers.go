@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// containerGroup sums the containers of one name across all pods, like all
+// istio-proxy or all nginx containers
+type containerGroup struct {
+	name                string
+	containers          int
+	namespaces, images  map[string]bool
+	reqCPU, reqMem      int64   // Total requests in millicores and bytes
+	cpuSizes, memSizes  []int64 // Request of each container, sorted
+	configs             map[string]int
+	topConfig           string // Most common requests and limits
+	topConfigContainers int
+}
+
+// containerConfig describes the requests and limits of a container, the unit
+// that can be standardized across pods
+func containerConfig(c corev1.Container) string {
+	q := func(list corev1.ResourceList, name corev1.ResourceName) string {
+		if v, ok := list[name]; ok {
+			return v.String()
+		}
+		return "-"
+	}
+	return fmt.Sprintf("requests %s / %s, limits %s / %s",
+		q(c.Resources.Requests, corev1.ResourceCPU), q(c.Resources.Requests, corev1.ResourceMemory),
+		q(c.Resources.Limits, corev1.ResourceCPU), q(c.Resources.Limits, corev1.ResourceMemory))
+}
+
+// containerGroups groups the containers of active pods by container name, most
+// containers first
+func containerGroups(pods []corev1.Pod) []containerGroup {
+	groups := make(map[string]*containerGroup)
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		for _, c := range podContainers(pod) {
+			g := groups[c.Name]
+			if g == nil {
+				g = &containerGroup{name: c.Name, namespaces: map[string]bool{}, images: map[string]bool{}, configs: map[string]int{}}
+				groups[c.Name] = g
+			}
+			cpu := quantityMilli(c.Resources.Requests.Cpu())
+			mem := quantityBytes(c.Resources.Requests.Memory())
+			g.containers++
+			g.namespaces[pod.Namespace] = true
+			g.images[imageRepository(c.Image)] = true
+			g.reqCPU += cpu
+			g.reqMem += mem
+			g.cpuSizes = append(g.cpuSizes, cpu)
+			g.memSizes = append(g.memSizes, mem)
+			g.configs[containerConfig(c)]++
+		}
+	}
+
+	result := make([]containerGroup, 0, len(groups))
+	for _, g := range groups {
+		sort.Slice(g.cpuSizes, func(i, j int) bool { return g.cpuSizes[i] < g.cpuSizes[j] })
+		sort.Slice(g.memSizes, func(i, j int) bool { return g.memSizes[i] < g.memSizes[j] })
+		for config, n := range g.configs {
+			if n > g.topConfigContainers || n == g.topConfigContainers && config < g.topConfig {
+				g.topConfig, g.topConfigContainers = config, n
+			}
+		}
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].containers != result[j].containers {
+			return result[i].containers > result[j].containers
+		}
+		return result[i].name < result[j].name
+	})
+	return result
+}
+
+// percentileOf returns the nearest-rank percentile p (0-100) of sorted values
+func percentileOf(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// createContainerGroupSheet lists the container groups with their request
+// sizes and how uniform their configurations are
+func createContainerGroupSheet(f *excelize.File, groups []containerGroup, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create container groups sheet: %w", err)
+	}
+
+	headers := []string{
+		"Container", "Containers", "Namespaces", "Images",
+		"Request CPU (cores)", "Request Memory (Gi)",
+		"CPU Request p50 (m)", "CPU Request p95 (m)", "Memory Request p50 (Mi)", "Memory Request p95 (Mi)",
+		"Configurations", "Most Common Configuration", "Most Common %",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, g := range groups {
+		data := []interface{}{
+			g.name,
+			g.containers,
+			len(g.namespaces),
+			len(g.images),
+			milliToCores(g.reqCPU),
+			bytesToGi(g.reqMem),
+			percentileOf(g.cpuSizes, 50),
+			percentileOf(g.cpuSizes, 95),
+			bytesToWholeMi(percentileOf(g.memSizes, 50)),
+			bytesToWholeMi(percentileOf(g.memSizes, 95)),
+			len(g.configs),
+			g.topConfig,
+			ratio(int64(g.topConfigContainers), int64(g.containers)),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("container '%s'", g.name)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	if row > 2 {
+		last := row - 1
+		f.SetCellStyle(sheetName, "E2", fmt.Sprintf("F%d", last), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "G2", fmt.Sprintf("K%d", last), export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, "M2", fmt.Sprintf("M%d", last), export.PercentStyle(f, "0.0%"))
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 28)
+	f.SetColWidth(sheetName, "B", "D", 12)
+	f.SetColWidth(sheetName, "E", "K", 20)
+	f.SetColWidth(sheetName, "L", "L", 56)
+	f.SetColWidth(sheetName, "M", "M", 16)
+
+	return nil
+}
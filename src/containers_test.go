@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPercentileOf(t *testing.T) {
+	values := []int64{10, 20, 30, 40, 50, 60, 70, 80, 90, 100}
+	tests := []struct {
+		p    float64
+		want int64
+	}{
+		{50, 50},
+		{95, 100},
+		{90, 90},
+		{0, 10},
+	}
+	for _, tt := range tests {
+		if got := percentileOf(values, tt.p); got != tt.want {
+			t.Errorf("percentileOf(p%.0f) = %d, want %d", tt.p, got, tt.want)
+		}
+	}
+	if got := percentileOf(nil, 50); got != 0 {
+		t.Errorf("percentileOf(nil) = %d, want 0", got)
+	}
+}
+
+func TestContainerGroups(t *testing.T) {
+	pod := func(namespace string, containers ...corev1.Container) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec:       corev1.PodSpec{Containers: containers},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	proxy := func(cpu string) corev1.Container {
+		c := testSidecarContainer("istio-proxy", cpu, "128Mi")
+		c.Image = "docker.io/istio/proxyv2:1.22"
+		return c
+	}
+	app := testSidecarContainer("app", "1", "1Gi")
+	pods := []corev1.Pod{
+		pod("shop", app, proxy("100m")),
+		pod("shop", proxy("100m")),
+		pod("search", proxy("500m")),
+		{Spec: corev1.PodSpec{Containers: []corev1.Container{proxy("2")}}, Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+	}
+
+	groups := containerGroups(pods)
+	if len(groups) != 2 || groups[0].name != "istio-proxy" || groups[1].name != "app" {
+		t.Fatalf("containerGroups() = %+v, want istio-proxy before app", groups)
+	}
+	g := groups[0]
+	if g.containers != 3 || len(g.namespaces) != 2 || len(g.images) != 1 || g.reqCPU != 700 {
+		t.Errorf("istio-proxy = %+v, want 3 containers in 2 namespaces requesting 700m", g)
+	}
+	if p50, p95 := percentileOf(g.cpuSizes, 50), percentileOf(g.cpuSizes, 95); p50 != 100 || p95 != 500 {
+		t.Errorf("CPU p50/p95 = %d/%d, want 100/500", p50, p95)
+	}
+	if len(g.configs) != 2 || g.topConfigContainers != 2 || g.topConfig != containerConfig(proxy("100m")) {
+		t.Errorf("configs = %v, most common %q (%d)", g.configs, g.topConfig, g.topConfigContainers)
+	}
+}
+
+func TestContainerConfig(t *testing.T) {
+	c := testSidecarContainer("app", "250m", "512Mi")
+	if got, want := containerConfig(c), "requests 250m / 512Mi, limits - / -"; got != want {
+		t.Errorf("containerConfig() = %q, want %q", got, want)
+	}
+	c.Resources.Limits = corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")}
+	if got, want := containerConfig(c), "requests 250m / 512Mi, limits - / 1Gi"; got != want {
+		t.Errorf("containerConfig() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Well-known node labels holding the availability zone, checked in order
+var zoneLabels = []string{"topology.kubernetes.io/zone", "failure-domain.beta.kubernetes.io/zone"}
+
+// ZoneNone is the zone of nodes without a zone label and of unscheduled pods
+const ZoneNone = "-"
+
+// DefaultCurrency labels prices when the config names no currency
+const DefaultCurrency = "USD"
+
+// pricingSpec is the cost model as written in the config file. Prices apply per
+// requested core and GiB per month; pool and zone multipliers weight the rate of
+// pods on those nodes (e.g. 3 for a GPU pool, 1.2 for a pricier zone). Storage
+// prices apply per claimed GiB per month, optionally per storage class.
+type pricingSpec struct {
+	Currency            string             `json:"currency,omitempty"`
+	CPUCoreMonth        float64            `json:"cpuCoreMonth,omitempty"`
+	MemoryGiMonth       float64            `json:"memoryGiMonth,omitempty"`
+	StorageGiMonth      float64            `json:"storageGiMonth,omitempty"`
+	StorageClassGiMonth map[string]float64 `json:"storageClassGiMonth,omitempty"`
+	PoolMultipliers     map[string]float64 `json:"poolMultipliers,omitempty"`
+	ZoneMultipliers     map[string]float64 `json:"zoneMultipliers,omitempty"`
+}
+
+// parsePricing validates the cost model; a nil result disables the Cost sheet
+func parsePricing(spec *pricingSpec) (*pricingSpec, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	if spec.CPUCoreMonth < 0 || spec.MemoryGiMonth < 0 || spec.StorageGiMonth < 0 {
+		return nil, fmt.Errorf("negative price in pricing")
+	}
+	for class, price := range spec.StorageClassGiMonth {
+		if price < 0 {
+			return nil, fmt.Errorf("negative storage price for class '%s'", class)
+		}
+	}
+	if spec.CPUCoreMonth == 0 && spec.MemoryGiMonth == 0 {
+		return nil, fmt.Errorf("pricing needs cpuCoreMonth or memoryGiMonth")
+	}
+	for kind, multipliers := range map[string]map[string]float64{"pool": spec.PoolMultipliers, "zone": spec.ZoneMultipliers} {
+		for name, m := range multipliers {
+			if m <= 0 {
+				return nil, fmt.Errorf("%s multiplier for '%s' must be positive", kind, name)
+			}
+		}
+	}
+	if spec.Currency == "" {
+		spec.Currency = DefaultCurrency
+	}
+	return spec, nil
+}
+
+// multiplier returns the combined price multiplier of a node pool and zone
+func (p *pricingSpec) multiplier(pool, zone string) float64 {
+	m := 1.0
+	if v, ok := p.PoolMultipliers[pool]; ok {
+		m *= v
+	}
+	if v, ok := p.ZoneMultipliers[zone]; ok {
+		m *= v
+	}
+	return m
+}
+
+// storagePrice returns the monthly price per GiB of a storage class
+func (p *pricingSpec) storagePrice(class string) float64 {
+	if price, ok := p.StorageClassGiMonth[class]; ok {
+		return price
+	}
+	return p.StorageGiMonth
+}
+
+// flatCost is the monthly cost of requests at the base rate
+func (p *pricingSpec) flatCost(reqCPU, reqMem int64) float64 {
+	return float64(reqCPU)/1000*p.CPUCoreMonth + float64(reqMem)/float64(BytesPerGi)*p.MemoryGiMonth
+}
+
+// nodeZone returns the availability zone of a node from well-known labels
+func nodeZone(node *corev1.Node) string {
+	for _, label := range zoneLabels {
+		if zone := node.Labels[label]; zone != "" {
+			return zone
+		}
+	}
+	return ZoneNone
+}
+
+// namespaceCost is the monthly cost of a namespace's requests
+type namespaceCost struct {
+	namespace      string
+	reqCPU, reqMem int64
+	flat, weighted float64
+}
+
+// locationCost is the monthly cost of requests in one node pool and zone
+type locationCost struct {
+	pool, zone     string
+	multiplier     float64
+	pods           int
+	reqCPU, reqMem int64
+	weighted       float64
+}
+
+// podCosts attributes the requests of active pods to namespaces and to the node
+// pool and zone they run on, weighted with the pricing multipliers. Pods without
+// a known node are charged at the base rate.
+func podCosts(pods []corev1.Pod, nodes *corev1.NodeList, p *pricingSpec) ([]namespaceCost, []locationCost) {
+	type location struct{ pool, zone string }
+	nodeLocations := make(map[string]location)
+	if nodes != nil {
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			nodeLocations[node.Name] = location{nodePool(node), nodeZone(node)}
+		}
+	}
+
+	namespaces := make(map[string]*namespaceCost)
+	locations := make(map[location]*locationCost)
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		var cpu, mem int64
+		for _, c := range pod.Spec.Containers {
+			cpu += quantityMilli(c.Resources.Requests.Cpu())
+			mem += quantityBytes(c.Resources.Requests.Memory())
+		}
+
+		loc, ok := nodeLocations[pod.Spec.NodeName]
+		if !ok {
+			loc = location{UnscheduledPool, ZoneNone}
+		}
+		l := locations[loc]
+		if l == nil {
+			l = &locationCost{pool: loc.pool, zone: loc.zone, multiplier: p.multiplier(loc.pool, loc.zone)}
+			if !ok {
+				l.multiplier = 1
+			}
+			locations[loc] = l
+		}
+		cost := p.flatCost(cpu, mem)
+		l.pods++
+		l.reqCPU += cpu
+		l.reqMem += mem
+		l.weighted += cost * l.multiplier
+
+		ns := namespaces[pod.Namespace]
+		if ns == nil {
+			ns = &namespaceCost{namespace: pod.Namespace}
+			namespaces[pod.Namespace] = ns
+		}
+		ns.reqCPU += cpu
+		ns.reqMem += mem
+		ns.flat += cost
+		ns.weighted += cost * l.multiplier
+	}
+
+	nsRows := make([]namespaceCost, 0, len(namespaces))
+	for _, ns := range namespaces {
+		nsRows = append(nsRows, *ns)
+	}
+	sort.Slice(nsRows, func(i, j int) bool {
+		if nsRows[i].weighted != nsRows[j].weighted {
+			return nsRows[i].weighted > nsRows[j].weighted
+		}
+		return nsRows[i].namespace < nsRows[j].namespace
+	})
+
+	locRows := make([]locationCost, 0, len(locations))
+	for _, l := range locations {
+		locRows = append(locRows, *l)
+	}
+	sort.Slice(locRows, func(i, j int) bool {
+		if locRows[i].pool != locRows[j].pool {
+			return locRows[i].pool < locRows[j].pool
+		}
+		return locRows[i].zone < locRows[j].zone
+	})
+	return nsRows, locRows
+}
+
+// createCostSheet writes the monthly cost per namespace, flat and weighted by
+// node pool and zone, followed by the cost per pool and zone
+func createCostSheet(f *excelize.File, namespaces []namespaceCost, locations []locationCost, p *pricingSpec, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create cost sheet: %w", err)
+	}
+
+	var total float64
+	for _, ns := range namespaces {
+		total += ns.weighted
+	}
+
+	f.SetCellValue(sheetName, "A1", fmt.Sprintf("Monthly cost of requests (%s): %.2f per core, %.2f per GiB", p.Currency, p.CPUCoreMonth, p.MemoryGiMonth))
+	f.SetCellStyle(sheetName, "A1", "A1", export.HeaderStyle(f))
+	headers := []string{
+		"Namespace", "Request CPU (cores)", "Request Memory (Gi)",
+		fmt.Sprintf("Flat Cost (%s)", p.Currency), fmt.Sprintf("Weighted Cost (%s)", p.Currency), "Share of Cost",
+	}
+	if err := f.SetSheetRow(sheetName, "A3", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 4
+	for _, ns := range namespaces {
+		data := []interface{}{
+			ns.namespace, milliToCores(ns.reqCPU), bytesToGi(ns.reqMem), ns.flat, ns.weighted, ratioFloat(ns.weighted, total),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("namespace '%s'", ns.namespace)); err != nil {
+			return err
+		}
+		row++
+	}
+	if row > 4 {
+		last := row - 1
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Total")
+		for _, col := range []string{"B", "C", "D", "E"} {
+			f.SetCellFormula(sheetName, fmt.Sprintf("%s%d", col, row), fmt.Sprintf("SUM(%s4:%s%d)", col, col, last))
+		}
+		f.SetCellStyle(sheetName, "B4", fmt.Sprintf("E%d", last), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "F4", fmt.Sprintf("F%d", last), export.PercentStyle(f, "0.0%"))
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.BoldStyle(f))
+		f.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("E%d", row), export.DecimalStyle(f, true))
+		row++
+	}
+
+	// Cost per node pool and zone
+	row += 2
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Cost by Node Pool and Zone")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
+	row++
+	locHeaders := []string{
+		"Node Pool", "Zone", "Multiplier", "Pods", "Request CPU (cores)", "Request Memory (Gi)",
+		fmt.Sprintf("Weighted Cost (%s)", p.Currency), "Share of Cost",
+	}
+	if err := f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &locHeaders); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+	row++
+	start := row
+	for _, l := range locations {
+		data := []interface{}{
+			l.pool, l.zone, l.multiplier, l.pods, milliToCores(l.reqCPU), bytesToGi(l.reqMem), l.weighted, ratioFloat(l.weighted, total),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("pool '%s' zone '%s'", l.pool, l.zone)); err != nil {
+			return err
+		}
+		row++
+	}
+	if row > start {
+		f.SetCellStyle(sheetName, fmt.Sprintf("C%d", start), fmt.Sprintf("C%d", row-1), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, fmt.Sprintf("E%d", start), fmt.Sprintf("G%d", row-1), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, fmt.Sprintf("H%d", start), fmt.Sprintf("H%d", row-1), export.PercentStyle(f, "0.0%"))
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 30)
+	f.SetColWidth(sheetName, "B", "H", 20)
+
+	return nil
+}
+
+// ratioFloat returns part/total or nil when total is zero, leaving the cell empty
+func ratioFloat(part, total float64) interface{} {
+	if total == 0 {
+		return nil
+	}
+	return part / total
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParsePricing(t *testing.T) {
+	if p, err := parsePricing(nil); p != nil || err != nil {
+		t.Errorf("parsePricing(nil) = %v, %v, want disabled", p, err)
+	}
+	p, err := parsePricing(&pricingSpec{CPUCoreMonth: 20})
+	if err != nil || p.Currency != DefaultCurrency {
+		t.Errorf("parsePricing() = %+v, %v, want default currency", p, err)
+	}
+
+	invalid := []pricingSpec{
+		{},
+		{CPUCoreMonth: -1, MemoryGiMonth: 2},
+		{CPUCoreMonth: 20, PoolMultipliers: map[string]float64{"gpu": 0}},
+		{CPUCoreMonth: 20, ZoneMultipliers: map[string]float64{"eu-west-1a": -1}},
+	}
+	for _, spec := range invalid {
+		if _, err := parsePricing(&spec); err == nil {
+			t.Errorf("parsePricing(%+v) expected error", spec)
+		}
+	}
+}
+
+func TestPodCosts(t *testing.T) {
+	node := func(name, pool, zone string) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			"karpenter.sh/nodepool": pool, "topology.kubernetes.io/zone": zone,
+		}}}
+	}
+	nodes := &corev1.NodeList{Items: []corev1.Node{node("cpu-a", "general", "a"), node("gpu-b", "gpu", "b")}}
+	pod := func(namespace, nodeName string) corev1.Pod {
+		p := testDeliveryPod(namespace+"-1", namespace+"-rs", nil, "1") // 1 core, 256Mi
+		p.Namespace = namespace
+		p.Spec.NodeName = nodeName
+		return p
+	}
+	pods := []corev1.Pod{pod("web", "cpu-a"), pod("ml", "gpu-b"), pod("ml", "")}
+
+	p := &pricingSpec{
+		CPUCoreMonth: 20, MemoryGiMonth: 4,
+		PoolMultipliers: map[string]float64{"gpu": 3},
+		ZoneMultipliers: map[string]float64{"b": 1.5, "a": 1},
+	}
+	namespaces, locations := podCosts(pods, nodes, p)
+
+	// 1 core * 20 + 0.25 GiB * 4 = 21 per pod
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if len(namespaces) != 2 || namespaces[0].namespace != "ml" || !near(namespaces[0].flat, 42) || !near(namespaces[0].weighted, 21*4.5+21) {
+		t.Errorf("namespaces = %+v", namespaces)
+	}
+	if !near(namespaces[1].weighted, 21) {
+		t.Errorf("web weighted = %g, want 21", namespaces[1].weighted)
+	}
+
+	if len(locations) != 3 {
+		t.Fatalf("podCosts() returned %d locations, want 3: %+v", len(locations), locations)
+	}
+	if l := locations[1]; l.pool != "gpu" || l.zone != "b" || l.multiplier != 4.5 || l.pods != 1 {
+		t.Errorf("locations[1] = %+v, want gpu/b at 4.5", l)
+	}
+	if l := locations[2]; l.pool != UnscheduledPool || l.zone != ZoneNone || l.multiplier != 1 {
+		t.Errorf("locations[2] = %+v, want unscheduled at base rate", l)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronMacros are the schedule shorthands accepted by the CronJob controller
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField is one field of a schedule with its range and names
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // Names of min, min+1, ..., nil when only numbers are valid
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// cronSchedule is a parsed five-field cron schedule as used by CronJobs
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays map[int]bool
+	anyDay, anyWeekday                     bool // Unrestricted day fields, see matches
+	location                               *time.Location
+}
+
+// parseCronSchedule parses a CronJob schedule: five fields with lists, ranges,
+// steps and month and weekday names, the @ macros and a CRON_TZ= or TZ= prefix.
+// Times are evaluated in location unless the schedule names a time zone.
+func parseCronSchedule(expr string, location *time.Location) (*cronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if strings.HasPrefix(expr, prefix) {
+			zone, rest, _ := strings.Cut(strings.TrimPrefix(expr, prefix), " ")
+			loc, err := time.LoadLocation(zone)
+			if err != nil {
+				return nil, fmt.Errorf("unknown time zone '%s'", zone)
+			}
+			location, expr = loc, strings.TrimSpace(rest)
+		}
+	}
+	if macro, ok := cronMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("schedule '%s' has %d fields, want 5", expr, len(fields))
+	}
+
+	sets := make([]map[int]bool, len(fields))
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("schedule '%s': %w", expr, err)
+		}
+		sets[i] = set
+	}
+	if sets[4][7] { // Sunday is 0 or 7
+		sets[4][0] = true
+	}
+	return &cronSchedule{
+		minutes: sets[0], hours: sets[1], days: sets[2], months: sets[3], weekdays: sets[4],
+		anyDay:     strings.HasPrefix(fields[2], "*") || fields[2] == "?",
+		anyWeekday: strings.HasPrefix(fields[4], "*") || fields[4] == "?",
+		location:   location,
+	}, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and steps
+func parseCronField(field string, spec cronField) (map[int]bool, error) {
+	set := make(map[int]bool)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step '%s' in %s", stepPart, spec.name)
+			}
+		}
+
+		first, last := spec.min, spec.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if first, err = spec.value(lo); err != nil {
+				return nil, err
+			}
+			if last, err = spec.value(hi); err != nil {
+				return nil, err
+			}
+			if first > last {
+				return nil, fmt.Errorf("invalid range '%s' in %s", rangePart, spec.name)
+			}
+		default:
+			value, err := spec.value(rangePart)
+			if err != nil {
+				return nil, err
+			}
+			first = value
+			if !hasStep {
+				last = value
+			}
+		}
+		for v := first; v <= last; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+// value parses a number or name of the field
+func (spec cronField) value(s string) (int, error) {
+	for i, name := range spec.names {
+		if strings.EqualFold(s, name) {
+			return spec.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < spec.min || v > spec.max {
+		return 0, fmt.Errorf("invalid %s '%s'", spec.name, s)
+	}
+	return v, nil
+}
+
+// matches reports whether the schedule fires at the minute of t. As in cron,
+// a day matches either day field when both are restricted.
+func (c *cronSchedule) matches(t time.Time) bool {
+	t = t.In(c.location)
+	if !c.minutes[t.Minute()] || !c.hours[t.Hour()] || !c.months[int(t.Month())] {
+		return false
+	}
+	day, weekday := c.days[t.Day()], c.weekdays[int(t.Weekday())]
+	if c.anyDay || c.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// between returns the times the schedule fires in [from, to)
+func (c *cronSchedule) between(from, to time.Time) []time.Time {
+	var times []time.Time
+	t := from.Truncate(time.Minute)
+	if t.Before(from) {
+		t = t.Add(time.Minute)
+	}
+	for ; t.Before(to); t = t.Add(time.Minute) {
+		if c.matches(t) {
+			times = append(times, t)
+		}
+	}
+	return times
+}
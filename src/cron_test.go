@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	at := func(s string) time.Time {
+		ts, _ := time.Parse("2006-01-02 15:04", s)
+		return ts
+	}
+	tests := []struct {
+		schedule string
+		time     string // UTC
+		want     bool
+	}{
+		{"*/15 * * * *", "2024-05-10 12:45", true},
+		{"*/15 * * * *", "2024-05-10 12:50", false},
+		{"0 2 * * *", "2024-05-10 02:00", true},
+		{"@hourly", "2024-05-10 07:00", true},
+		{"@daily", "2024-05-10 07:00", false},
+		{"30 8-18/2 * * mon-fri", "2024-05-10 10:30", true}, // Friday
+		{"30 8-18/2 * * mon-fri", "2024-05-11 10:30", false},
+		{"0 0 1 * 7", "2024-05-12 00:00", true},   // Sunday as 7, either day field matches
+		{"0 0 1 * sun", "2024-05-01 00:00", true}, // The 1st, a Wednesday
+		{"0 0 1 jan,jul *", "2024-07-01 00:00", true},
+		{"CRON_TZ=Europe/Berlin 0 2 * * *", "2024-05-10 00:00", true},
+	}
+	for _, tt := range tests {
+		s, err := parseCronSchedule(tt.schedule, time.UTC)
+		if err != nil {
+			t.Errorf("parseCronSchedule(%q) error: %v", tt.schedule, err)
+			continue
+		}
+		if got := s.matches(at(tt.time)); got != tt.want {
+			t.Errorf("%q matches %s = %v, want %v", tt.schedule, tt.time, got, tt.want)
+		}
+	}
+
+	s, _ := parseCronSchedule("0 2 * * *", berlin)
+	if got := s.between(at("2024-05-10 00:00"), at("2024-05-12 00:00")); len(got) != 2 || !got[0].Equal(at("2024-05-10 00:00")) {
+		t.Errorf("between() = %v, want 00:00 UTC on both days", got)
+	}
+
+	for _, schedule := range []string{"* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "0 0 * foo *", "TZ=Nowhere/City 0 * * * *"} {
+		if _, err := parseCronSchedule(schedule, time.UTC); err == nil {
+			t.Errorf("parseCronSchedule(%q) succeeded, want an error", schedule)
+		}
+	}
+}
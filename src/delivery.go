@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RolloutsHashLabel is set by Argo Rollouts on the pods of each rollout revision
+const RolloutsHashLabel = "rollouts-pod-template-hash"
+
+// Kinds of paired workloads on the progressive delivery sheet
+const (
+	DeliveryArgoRollout = "Argo Rollout"
+	DeliveryNamePair    = "Name suffix"
+)
+
+// deliverySuffixes mark workload copies created for canary or blue/green releases
+var deliverySuffixes = []string{"canary", "preview", "stable", "active", "baseline", "blue", "green"}
+
+// deliveryVariant is one revision or copy of a paired workload
+type deliveryVariant struct {
+	name           string
+	pods           int
+	reqCPU, reqMem int64
+}
+
+// deliveryGroup is a workload running in several variants at once
+type deliveryGroup struct {
+	key      workloadKey // Kind is "Rollout" or the kind of the paired workloads
+	source   string      // DeliveryArgoRollout or DeliveryNamePair
+	variants []deliveryVariant
+}
+
+// totals returns the combined requests of all variants
+func (g deliveryGroup) totals() (pods int, cpu, mem int64) {
+	for _, v := range g.variants {
+		pods += v.pods
+		cpu += v.reqCPU
+		mem += v.reqMem
+	}
+	return pods, cpu, mem
+}
+
+// temporary returns the requests of all but the largest variant, the capacity
+// freed once the release is promoted or aborted
+func (g deliveryGroup) temporary() (cpu, mem int64) {
+	_, cpu, mem = g.totals()
+	largest := 0
+	for i, v := range g.variants {
+		if v.reqCPU > g.variants[largest].reqCPU {
+			largest = i
+		}
+	}
+	return cpu - g.variants[largest].reqCPU, mem - g.variants[largest].reqMem
+}
+
+// deliverySuffix splits a known delivery suffix from a workload name
+func deliverySuffix(name string) (base, suffix string) {
+	for _, s := range deliverySuffixes {
+		if strings.HasSuffix(name, "-"+s) && len(name) > len(s)+1 {
+			return strings.TrimSuffix(name, "-"+s), s
+		}
+	}
+	return name, ""
+}
+
+// rolloutOf returns the Argo Rollout and revision hash of a pod
+func rolloutOf(pod *corev1.Pod) (name, hash string, ok bool) {
+	hash = pod.Labels[RolloutsHashLabel]
+	if hash == "" {
+		return "", "", false
+	}
+	for _, ref := range pod.OwnerReferences {
+		if ref.Kind == "ReplicaSet" && strings.HasSuffix(ref.Name, "-"+hash) {
+			return strings.TrimSuffix(ref.Name, "-"+hash), hash, true
+		}
+	}
+	return "", "", false
+}
+
+// deliveryGroups finds Argo Rollouts with more than one active revision and
+// workloads running next to a -canary/-preview/-blue/-green style copy
+func deliveryGroups(pods []corev1.Pod) []deliveryGroup {
+	type groupKey struct {
+		key    workloadKey
+		source string
+	}
+	variants := make(map[groupKey]map[string]*deliveryVariant)
+
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+
+		var gk groupKey
+		var variant string
+		if rollout, hash, ok := rolloutOf(pod); ok {
+			gk = groupKey{workloadKey{namespace: pod.Namespace, kind: "Rollout", name: rollout}, DeliveryArgoRollout}
+			variant = hash
+		} else {
+			w := workloadOf(pod)
+			base, _ := deliverySuffix(w.name)
+			gk = groupKey{workloadKey{namespace: w.namespace, kind: w.kind, name: base}, DeliveryNamePair}
+			variant = w.name
+		}
+
+		if variants[gk] == nil {
+			variants[gk] = make(map[string]*deliveryVariant)
+		}
+		v := variants[gk][variant]
+		if v == nil {
+			v = &deliveryVariant{name: variant}
+			variants[gk][variant] = v
+		}
+		v.pods++
+		for _, container := range pod.Spec.Containers {
+			v.reqCPU += quantityMilli(container.Resources.Requests.Cpu())
+			v.reqMem += quantityBytes(container.Resources.Requests.Memory())
+		}
+	}
+
+	var groups []deliveryGroup
+	for gk, byName := range variants {
+		if len(byName) < 2 {
+			continue
+		}
+		g := deliveryGroup{key: gk.key, source: gk.source}
+		for _, v := range byName {
+			g.variants = append(g.variants, *v)
+		}
+		sort.Slice(g.variants, func(i, j int) bool { return g.variants[i].name < g.variants[j].name })
+		groups = append(groups, g)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		ci, _ := groups[i].temporary()
+		cj, _ := groups[j].temporary()
+		if ci != cj {
+			return ci > cj
+		}
+		return groups[i].key.String() < groups[j].key.String()
+	})
+	return groups
+}
+
+// createDeliverySheet lists paired canary/blue-green workloads with their combined
+// and temporary (releasable after promotion) requests
+func createDeliverySheet(f *excelize.File, groups []deliveryGroup, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create progressive delivery sheet: %w", err)
+	}
+
+	headers := []string{
+		"Workload", "Detected By", "Variants", "Pods",
+		"Request CPU (cores)", "Request Memory (Gi)", "Temporary CPU (cores)", "Temporary Memory (Gi)",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, g := range groups {
+		names := make([]string, 0, len(g.variants))
+		for _, v := range g.variants {
+			names = append(names, fmt.Sprintf("%s (%s)", v.name, pluralize(v.pods, "pod")))
+		}
+		pods, cpu, mem := g.totals()
+		tempCPU, tempMem := g.temporary()
+
+		data := []interface{}{
+			g.key.String(),
+			g.source,
+			strings.Join(names, ", "),
+			pods,
+			milliToCores(cpu),
+			bytesToGi(mem),
+			milliToCores(tempCPU),
+			bytesToGi(tempMem),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("workload '%s'", g.key)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	if row > 2 {
+		f.SetCellStyle(sheetName, "E2", fmt.Sprintf("H%d", row-1), export.DecimalStyle(f, false))
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Total")
+		for _, col := range []string{"D", "E", "F", "G", "H"} {
+			f.SetCellFormula(sheetName, fmt.Sprintf("%s%d", col, row), fmt.Sprintf("SUM(%s2:%s%d)", col, col, row-1))
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("D%d", row), export.BoldStyle(f))
+		f.SetCellStyle(sheetName, fmt.Sprintf("E%d", row), fmt.Sprintf("H%d", row), export.DecimalStyle(f, true))
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", "B", 14)
+	f.SetColWidth(sheetName, "C", "C", 50)
+	f.SetColWidth(sheetName, "D", "D", 8)
+	f.SetColWidth(sheetName, "E", "H", 20)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testDeliveryPod(name, replicaSet string, labels map[string]string, cpu string) corev1.Pod {
+	controller := true
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "shop", Labels: labels,
+			OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: replicaSet, Controller: &controller}},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name: "app",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse("256Mi"),
+			}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestDeliverySuffix(t *testing.T) {
+	tests := []struct {
+		name, base, suffix string
+	}{
+		{"web-canary", "web", "canary"},
+		{"checkout-green", "checkout", "green"},
+		{"web", "web", ""},
+		{"canary", "canary", ""},
+		{"-canary", "-canary", ""},
+		{"web-canaryx", "web-canaryx", ""},
+	}
+	for _, tt := range tests {
+		base, suffix := deliverySuffix(tt.name)
+		if base != tt.base || suffix != tt.suffix {
+			t.Errorf("deliverySuffix(%q) = %q, %q, want %q, %q", tt.name, base, suffix, tt.base, tt.suffix)
+		}
+	}
+}
+
+func TestDeliveryGroups(t *testing.T) {
+	hash := func(h string) map[string]string { return map[string]string{"pod-template-hash": h} }
+	rollout := func(h string) map[string]string { return map[string]string{RolloutsHashLabel: h} }
+
+	pods := []corev1.Pod{
+		// web and web-canary Deployments
+		testDeliveryPod("web-aaa-1", "web-aaa", hash("aaa"), "1"),
+		testDeliveryPod("web-aaa-2", "web-aaa", hash("aaa"), "1"),
+		testDeliveryPod("web-canary-bbb-1", "web-canary-bbb", hash("bbb"), "1"),
+		// Argo Rollout with stable and canary revisions
+		testDeliveryPod("cart-111-1", "cart-111", rollout("111"), "500m"),
+		testDeliveryPod("cart-111-2", "cart-111", rollout("111"), "500m"),
+		testDeliveryPod("cart-111-3", "cart-111", rollout("111"), "500m"),
+		testDeliveryPod("cart-222-1", "cart-222", rollout("222"), "500m"),
+		// Rollout with a single revision and a Deployment in the middle of a rolling update
+		testDeliveryPod("search-333-1", "search-333", rollout("333"), "1"),
+		testDeliveryPod("api-ccc-1", "api-ccc", hash("ccc"), "1"),
+		testDeliveryPod("api-ddd-1", "api-ddd", hash("ddd"), "1"),
+	}
+
+	groups := deliveryGroups(pods)
+	if len(groups) != 2 {
+		t.Fatalf("deliveryGroups() returned %d groups, want 2: %+v", len(groups), groups)
+	}
+
+	web := groups[0]
+	if web.key != (workloadKey{namespace: "shop", kind: "Deployment", name: "web"}) || web.source != DeliveryNamePair {
+		t.Errorf("groups[0] = %+v, want shop/Deployment/web pair", web)
+	}
+	if pods, cpu, _ := web.totals(); pods != 3 || cpu != 3000 {
+		t.Errorf("web totals = %d pods, %dm", pods, cpu)
+	}
+	if cpu, mem := web.temporary(); cpu != 1000 || mem != 256*BytesPerMi {
+		t.Errorf("web temporary = %dm, %d bytes, want the canary's requests", cpu, mem)
+	}
+
+	cart := groups[1]
+	if cart.key != (workloadKey{namespace: "shop", kind: "Rollout", name: "cart"}) || cart.source != DeliveryArgoRollout || len(cart.variants) != 2 {
+		t.Errorf("groups[1] = %+v, want shop/Rollout/cart with 2 revisions", cart)
+	}
+	if cpu, _ := cart.temporary(); cpu != 500 {
+		t.Errorf("cart temporary = %dm, want 500m", cpu)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// workloadDrift is a workload whose active pods run different resource specs,
+// e.g. a stuck rollout or pods edited by hand
+type workloadDrift struct {
+	workload workloadKey
+	pods     []int // Pods per distinct spec, most common first
+}
+
+// podResourceSpec describes the requests and limits of all containers of a pod
+// that stay running, in container name order
+func podResourceSpec(pod *corev1.Pod) string {
+	containers := podContainers(pod)
+	specs := make([]string, 0, len(containers))
+	for _, c := range containers {
+		specs = append(specs, c.Name+": "+containerConfig(c))
+	}
+	sort.Strings(specs)
+	return strings.Join(specs, "; ")
+}
+
+// workloadSpecDrift returns the workloads whose active pods run more than one
+// resource spec, by namespace, kind and name. Bare pods cannot drift.
+func workloadSpecDrift(pods []corev1.Pod) []workloadDrift {
+	specs := make(map[workloadKey]map[string]int)
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		key := workloadOf(pod)
+		if key.kind == "Pod" {
+			continue
+		}
+		if specs[key] == nil {
+			specs[key] = make(map[string]int)
+		}
+		specs[key][podResourceSpec(pod)]++
+	}
+
+	var drift []workloadDrift
+	for key, counts := range specs {
+		if len(counts) < 2 {
+			continue
+		}
+		d := workloadDrift{workload: key}
+		for _, n := range counts {
+			d.pods = append(d.pods, n)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(d.pods)))
+		drift = append(drift, d)
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].workload.String() < drift[j].workload.String() })
+	return drift
+}
+
+// message describes the drift for the Warnings sheet
+func (d workloadDrift) message() string {
+	counts := make([]string, len(d.pods))
+	total := 0
+	for i, n := range d.pods {
+		counts[i] = fmt.Sprint(n)
+		total += n
+	}
+	return fmt.Sprintf("%s '%s' in '%s' runs %d different resource specs across %s (%s): stuck rollout or edited pods",
+		d.workload.kind, d.workload.name, d.workload.namespace, len(d.pods), pluralize(total, "pod"), strings.Join(counts, "/"))
+}
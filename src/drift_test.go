@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadSpecDrift(t *testing.T) {
+	pod := func(owner, hash string, phase corev1.PodPhase, containers ...corev1.Container) corev1.Pod {
+		p := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: owner + "-" + hash},
+			Spec:       corev1.PodSpec{Containers: containers},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		if hash != "" {
+			p.Labels = map[string]string{"pod-template-hash": hash}
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: "ReplicaSet", Name: owner + "-" + hash}}
+		}
+		return p
+	}
+	small := testSidecarContainer("app", "500m", "512Mi")
+	large := testSidecarContainer("app", "1", "1Gi")
+	proxy := testSidecarContainer("istio-proxy", "100m", "128Mi")
+
+	pods := []corev1.Pod{
+		pod("web", "abc", corev1.PodRunning, small, proxy),
+		pod("web", "abc", corev1.PodRunning, proxy, small), // Container order does not matter
+		pod("web", "abc", corev1.PodRunning, small, proxy),
+		pod("web", "def", corev1.PodRunning, large, proxy),
+		pod("web", "def", corev1.PodSucceeded, testSidecarContainer("app", "2", "2Gi")),
+		pod("api", "abc", corev1.PodRunning, small),
+		pod("api", "abc", corev1.PodRunning, small),
+		pod("tool", "", corev1.PodRunning, small),
+		pod("tool", "", corev1.PodRunning, large),
+	}
+
+	got := workloadSpecDrift(pods)
+	if len(got) != 1 {
+		t.Fatalf("workloadSpecDrift() returned %d workloads, want 1: %+v", len(got), got)
+	}
+	d := got[0]
+	if d.workload != (workloadKey{namespace: "shop", kind: "Deployment", name: "web"}) {
+		t.Errorf("workload = %v, want Deployment shop/web", d.workload)
+	}
+	if len(d.pods) != 2 || d.pods[0] != 3 || d.pods[1] != 1 {
+		t.Errorf("pods = %v, want [3 1]", d.pods)
+	}
+	if msg := d.message(); !strings.Contains(msg, "2 different resource specs across 4 pods (3/1)") {
+		t.Errorf("message() = %q", msg)
+	}
+}
+
+func TestValidateWorkloadSpecDrift(t *testing.T) {
+	drift := []workloadDrift{
+		{workload: workloadKey{namespace: "shop", kind: "Deployment", name: "web"}, pods: []int{3, 1}},
+		{workload: workloadKey{namespace: "shop", kind: "StatefulSet", name: "db"}, pods: []int{1, 1, 1}},
+	}
+	tests := []struct {
+		threshold float64
+		want      int
+	}{
+		{1, 2},
+		{2, 1},
+		{3, 0},
+	}
+	for _, tt := range tests {
+		r := validationRules{rules: map[string]validationRule{RuleWorkloadSpecDrift: {severityWarn, tt.threshold}}}
+		n := 0
+		for _, f := range validateResources(validationInput{specDrift: drift}, r) {
+			if f.rule == RuleWorkloadSpecDrift {
+				n++
+			}
+		}
+		if n != tt.want {
+			t.Errorf("threshold %.0f: got %d findings, want %d", tt.threshold, n, tt.want)
+		}
+	}
+}
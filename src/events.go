@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultEventHours is the window of Warning events on the Events sheet. The
+// API server keeps events for an hour by default, longer windows need a
+// longer --event-ttl.
+const DefaultEventHours = 24
+
+// warningEvents are the Warning events of one namespace and reason, e.g. the
+// FailedScheduling, OOMKilling, Evicted or BackOff events behind pending pods,
+// restarts and missing capacity
+type warningEvents struct {
+	namespace string // Empty for cluster-scoped objects such as nodes
+	reason    string
+	count     int32 // Occurrences, including repeats folded into one event
+	objects   int   // Distinct involved objects
+	firstSeen time.Time
+	lastSeen  time.Time
+	latest    string // Kind/name of the object of the latest event
+	message   string // Message of the latest event
+}
+
+// fetchWarningEvents lists the Warning events of a namespace ("" for all)
+func fetchWarningEvents(ctx context.Context, clientSet kubernetes.Interface, namespace string) ([]corev1.Event, error) {
+	events, err := clientSet.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{FieldSelector: "type=" + corev1.EventTypeWarning})
+	if err != nil {
+		return nil, err
+	}
+	return events.Items, nil
+}
+
+// eventTimes returns when an event was first and last seen, falling back to
+// the event time and series that newer reporters set instead
+func eventTimes(e corev1.Event) (first, last time.Time) {
+	first, last = e.FirstTimestamp.Time, e.LastTimestamp.Time
+	if first.IsZero() {
+		first = e.EventTime.Time
+	}
+	if first.IsZero() {
+		first = e.CreationTimestamp.Time
+	}
+	if last.IsZero() && e.Series != nil {
+		last = e.Series.LastObservedTime.Time
+	}
+	if last.IsZero() {
+		last = first
+	}
+	if first.After(last) {
+		first = last
+	}
+	return first, last
+}
+
+// summarizeWarningEvents aggregates the Warning events last seen since the
+// given time by namespace and reason, most frequent first
+func summarizeWarningEvents(events []corev1.Event, since time.Time) []warningEvents {
+	type key struct{ namespace, reason string }
+	groups := make(map[key]*warningEvents)
+	objects := make(map[key]map[string]bool)
+	for _, e := range events {
+		if e.Type != corev1.EventTypeWarning {
+			continue
+		}
+		first, last := eventTimes(e)
+		if last.Before(since) {
+			continue
+		}
+		count := e.Count
+		if e.Series != nil && e.Series.Count > count {
+			count = e.Series.Count
+		}
+		if count < 1 {
+			count = 1
+		}
+
+		k := key{e.InvolvedObject.Namespace, e.Reason}
+		g, ok := groups[k]
+		if !ok {
+			g = &warningEvents{namespace: k.namespace, reason: k.reason, firstSeen: first}
+			groups[k] = g
+			objects[k] = make(map[string]bool)
+		}
+		object := e.InvolvedObject.Kind + "/" + e.InvolvedObject.Name
+		objects[k][object] = true
+		g.count += count
+		g.objects = len(objects[k])
+		if first.Before(g.firstSeen) {
+			g.firstSeen = first
+		}
+		if !last.Before(g.lastSeen) {
+			g.lastSeen, g.latest, g.message = last, object, strings.TrimSpace(e.Message)
+		}
+	}
+
+	summaries := make([]warningEvents, 0, len(groups)) // Non-nil: no events still creates the sheet
+	for _, g := range groups {
+		summaries = append(summaries, *g)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		return a.reason < b.reason
+	})
+	return summaries
+}
+
+// eventsOfPods keeps the events of the given namespaces and of
+// cluster-scoped objects, for the workbook of a split group
+func eventsOfPods(summaries []warningEvents, pods []corev1.Pod) []warningEvents {
+	namespaces := make(map[string]bool)
+	for i := range pods {
+		namespaces[pods[i].Namespace] = true
+	}
+	retained := make([]warningEvents, 0, len(summaries))
+	for _, s := range summaries {
+		if s.namespace == "" || namespaces[s.namespace] {
+			retained = append(retained, s)
+		}
+	}
+	return retained
+}
+
+// createEventsSheet lists the Warning events per namespace and reason next to
+// the namespace's running pods and requests
+func createEventsSheet(f *excelize.File, summaries []warningEvents, pods []corev1.Pod, namespaceTotals map[string]namespaceTotal, location *time.Location, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create events sheet: %w", err)
+	}
+
+	headers := []string{
+		"Namespace", "Reason", "Events", "Objects", "First Seen", "Last Seen", "Latest Object", "Latest Message",
+		"Pods", "Request CPU (cores)", "Request Memory (Gi)",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	namespacePods := make(map[string]int)
+	for i := range pods {
+		if isActivePod(&pods[i]) {
+			namespacePods[pods[i].Namespace]++
+		}
+	}
+
+	decimalStyle := export.DecimalStyle(f, false)
+	row := 2
+	for _, s := range summaries {
+		totals, active := namespaceTotals[s.namespace]
+
+		namespace := s.namespace
+		if namespace == "" {
+			namespace = "(cluster)"
+		}
+		data := []interface{}{
+			namespace,
+			s.reason,
+			s.count,
+			s.objects,
+			s.firstSeen.In(location).Format("2006-01-02 15:04"),
+			s.lastSeen.In(location).Format("2006-01-02 15:04"),
+			s.latest,
+			s.message,
+		}
+		if active {
+			data = append(data, namespacePods[s.namespace], milliToCores(totals.reqCPU), bytesToGi(totals.reqMem))
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("events '%s' in '%s'", s.reason, namespace)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("J%d", row), fmt.Sprintf("K%d", row), decimalStyle)
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 25)
+	f.SetColWidth(sheetName, "B", "B", 22)
+	f.SetColWidth(sheetName, "C", "D", 10)
+	f.SetColWidth(sheetName, "E", "F", 17)
+	f.SetColWidth(sheetName, "G", "G", 40)
+	f.SetColWidth(sheetName, "H", "H", 80)
+	f.SetColWidth(sheetName, "I", "I", 8)
+	f.SetColWidth(sheetName, "J", "K", 20)
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testWarningEvent(namespace, kind, name, reason string, count int32, last time.Time) corev1.Event {
+	return corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Namespace: namespace, Kind: kind, Name: name},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        reason + " " + name,
+		Count:          count,
+		FirstTimestamp: metav1.NewTime(last.Add(-time.Hour)),
+		LastTimestamp:  metav1.NewTime(last),
+	}
+}
+
+func TestSummarizeWarningEvents(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	series := corev1.Event{
+		InvolvedObject: corev1.ObjectReference{Namespace: "shop", Kind: "Pod", Name: "web-2"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		EventTime:      metav1.NewMicroTime(now.Add(-2 * time.Hour)),
+		Series:         &corev1.EventSeries{Count: 5, LastObservedTime: metav1.NewMicroTime(now.Add(-time.Minute))},
+	}
+	normal := testWarningEvent("shop", "Pod", "web-1", "Pulled", 1, now)
+	normal.Type = corev1.EventTypeNormal
+	events := []corev1.Event{
+		testWarningEvent("shop", "Pod", "web-1", "BackOff", 3, now.Add(-10*time.Minute)),
+		testWarningEvent("shop", "Pod", "web-1", "BackOff", 2, now.Add(-5*time.Minute)),
+		series,
+		testWarningEvent("search", "Pod", "idx-1", "FailedScheduling", 1, now.Add(-time.Hour)),
+		testWarningEvent("", "Node", "n1", "OOMKilling", 1, now.Add(-time.Hour)),
+		testWarningEvent("search", "Pod", "idx-0", "Evicted", 1, now.Add(-48*time.Hour)), // Outside the window
+		normal,
+	}
+
+	got := summarizeWarningEvents(events, now.Add(-24*time.Hour))
+	if len(got) != 3 {
+		t.Fatalf("summaries = %+v, want 3", got)
+	}
+	backOff := got[0]
+	if backOff.namespace != "shop" || backOff.reason != "BackOff" || backOff.count != 10 || backOff.objects != 2 {
+		t.Errorf("BackOff = %+v", backOff)
+	}
+	if !backOff.lastSeen.Equal(now.Add(-time.Minute)) || backOff.latest != "Pod/web-2" || !backOff.firstSeen.Equal(now.Add(-2*time.Hour)) {
+		t.Errorf("BackOff times = %v - %v, latest %q", backOff.firstSeen, backOff.lastSeen, backOff.latest)
+	}
+	// Ties by count sort by namespace, cluster-scoped first
+	if got[1].namespace != "" || got[1].reason != "OOMKilling" || got[2].reason != "FailedScheduling" {
+		t.Errorf("order = %+v", got)
+	}
+
+	if none := summarizeWarningEvents(nil, now); none == nil || len(none) != 0 {
+		t.Errorf("summarizeWarningEvents(nil) = %#v, want empty", none)
+	}
+}
+
+func TestEventsOfPods(t *testing.T) {
+	summaries := []warningEvents{{namespace: "shop", reason: "BackOff"}, {namespace: "search", reason: "Evicted"}, {reason: "OOMKilling"}}
+	pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web-1"}}}
+	got := eventsOfPods(summaries, pods)
+	if len(got) != 2 || got[0].namespace != "shop" || got[1].reason != "OOMKilling" {
+		t.Errorf("eventsOfPods() = %+v", got)
+	}
+}
+
+func TestCreateEventsSheet(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(now)
+	namespaceTotals, _ := aggregateTotals(snap.pods, snap.nodes)
+	summaries := []warningEvents{
+		{namespace: "shop", reason: "BackOff", count: 4, objects: 1, firstSeen: now.Add(-time.Hour), lastSeen: now, latest: "Pod/web-1", message: "Back-off"},
+		{reason: "OOMKilling", count: 1, objects: 1, firstSeen: now, lastSeen: now, latest: "Node/n1"},
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createEventsSheet(f, summaries, snap.pods, namespaceTotals, time.UTC, "Events"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := f.GetRows("Events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("rows = %v", rows)
+	}
+	if shop := rows[1]; shop[0] != "shop" || shop[2] != "4" || shop[5] != "2024-05-10 12:00" || shop[8] != "2" || shop[9] != "1.00" {
+		t.Errorf("shop row = %q", shop)
+	}
+	// No message and no namespace totals
+	if cluster := rows[2]; cluster[0] != "(cluster)" || len(cluster) != 7 {
+		t.Errorf("cluster row = %q", cluster)
+	}
+}
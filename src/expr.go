@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+// Custom column expressions are CEL (https://cel.dev) evaluated per container
+// row:
+//
+//	limits.cpu / requests.cpu
+//	requests.memory > 1024.0 ? "large" : labels.?tier.orValue("")
+//
+// The variables are the row fields of exprVariables; namespace is a reserved
+// word in CEL, so the namespace is ns. Expressions are compiled and type
+// checked when the config is loaded and must produce a number, string or bool.
+// A row whose evaluation fails, such as a missing map key, an integer division
+// by zero or a non-finite number, gets an empty cell.
+
+// exprVariables are the row fields of expressions: CPU in cores and memory in
+// Mi as doubles, as on the Resources sheet
+var exprVariables = map[string]struct {
+	typ *cel.Type
+	get func(r rowFields) interface{}
+}{
+	"ns":              {cel.StringType, func(r rowFields) interface{} { return r.Namespace }},
+	"pod":             {cel.StringType, func(r rowFields) interface{} { return r.Pod }},
+	"container":       {cel.StringType, func(r rowFields) interface{} { return r.Container }},
+	"node":            {cel.StringType, func(r rowFields) interface{} { return r.Node }},
+	"status":          {cel.StringType, func(r rowFields) interface{} { return r.Status }},
+	"qosClass":        {cel.StringType, func(r rowFields) interface{} { return r.QoSClass }},
+	"tshirtSize":      {cel.StringType, func(r rowFields) interface{} { return r.TShirtSize }},
+	"team":            {cel.StringType, func(r rowFields) interface{} { return r.Team }},
+	"restarts":        {cel.IntType, func(r rowFields) interface{} { return int64(r.RestartCount) }},
+	"requests.cpu":    {cel.DoubleType, func(r rowFields) interface{} { return milliToCores(r.RequestCPU) }},
+	"limits.cpu":      {cel.DoubleType, func(r rowFields) interface{} { return milliToCores(r.LimitCPU) }},
+	"requests.memory": {cel.DoubleType, func(r rowFields) interface{} { return r.RequestMemoryMi }},
+	"limits.memory":   {cel.DoubleType, func(r rowFields) interface{} { return r.LimitMemoryMi }},
+	"labels":          {cel.MapType(cel.StringType, cel.StringType), func(r rowFields) interface{} { return stringMap(r.Labels) }},
+	"annotations":     {cel.MapType(cel.StringType, cel.StringType), func(r rowFields) interface{} { return stringMap(r.Annotations) }},
+}
+
+// exprEnv is the CEL environment of custom column expressions
+var exprEnv = func() *cel.Env {
+	opts := []cel.EnvOption{cel.OptionalTypes(), cel.CrossTypeNumericComparisons(true)}
+	for name, v := range exprVariables {
+		opts = append(opts, cel.Variable(name, v.typ))
+	}
+	env, err := cel.NewEnv(opts...)
+	if err != nil {
+		panic(fmt.Sprintf("invalid expression environment: %v", err))
+	}
+	return env
+}()
+
+// exprNode is a compiled, type-checked expression
+type exprNode struct {
+	program cel.Program
+}
+
+// parseExpr compiles an expression and checks that it produces a cell value
+func parseExpr(src string) (exprNode, error) {
+	ast, issues := exprEnv.Compile(src)
+	if issues != nil && issues.Err() != nil {
+		return exprNode{}, issues.Err()
+	}
+	switch ast.OutputType() {
+	case cel.DoubleType, cel.IntType, cel.UintType, cel.StringType, cel.BoolType:
+	default:
+		return exprNode{}, fmt.Errorf("expression yields %s, want a number, string or bool", ast.OutputType())
+	}
+	program, err := exprEnv.Program(ast)
+	if err != nil {
+		return exprNode{}, err
+	}
+	return exprNode{program: program}, nil
+}
+
+// eval evaluates the expression for a row; ok is false when the value is
+// undefined. Numbers are returned as float64.
+func (n exprNode) eval(fields rowFields) (value interface{}, ok bool) {
+	vars := make(map[string]interface{}, len(exprVariables))
+	for name, v := range exprVariables {
+		vars[name] = v.get(fields)
+	}
+	out, _, err := n.program.Eval(vars)
+	if err != nil || types.IsError(out) {
+		return nil, false
+	}
+	switch v := out.Value().(type) {
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, false
+		}
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return v, true
+	}
+}
+
+// stringMap returns m, or an empty map for nil so lookups fail as missing keys
+func stringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
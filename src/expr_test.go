@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseExpr(t *testing.T) {
+	fields := rowFields{
+		Namespace:       "shop",
+		Container:       "app",
+		TShirtSize:      "M",
+		RequestCPU:      250,
+		LimitCPU:        1000,
+		RequestMemoryMi: 512,
+		LimitMemoryMi:   512,
+		RestartCount:    3,
+		Labels:          map[string]string{"tier": "web", "app.kubernetes.io/name": "shop-web"},
+	}
+	tests := []struct {
+		expr string
+		want interface{} // nil for an undefined value
+	}{
+		{"limits.cpu / requests.cpu", 4.0},
+		{"requests.memory * 2.0 - 24.0", 1000.0},
+		{"2 + 3 * 4", 14.0},
+		{"(2 + 3) * 4", 20.0},
+		{"-requests.cpu", -0.25},
+		{"restarts % 2", 1.0},
+		{"limits.cpu / (requests.cpu - 0.25)", nil},
+		{"restarts / 0", nil},
+		{`ns + "/" + container`, "shop/app"},
+		{`labels["tier"]`, "web"},
+		{"labels.tier", "web"},
+		{`labels["app.kubernetes.io/name"]`, "shop-web"},
+		{`labels["missing"]`, nil},
+		{`labels.?missing.orValue("")`, ""},
+		{`"owner" in annotations ? annotations.owner : "-"`, "-"},
+		{`requests.memory >= limits.memory ? "guaranteed" : "burstable"`, "guaranteed"},
+		{`tshirtSize == 'M' && restarts > 2`, true},
+		{`!(restarts > 2) || ns != "shop"`, false},
+		{`requests.cpu == 0.0 || limits.cpu / requests.cpu > 2.0`, true},
+		{`restarts > 2.5`, true},
+		{`"a" < "b"`, true},
+		{`container.startsWith("a") && ns.matches("^sh")`, true},
+		{`string(restarts) + "x"`, "3x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			node, err := parseExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("parseExpr() error = %v", err)
+			}
+			got, ok := node.eval(fields)
+			if tt.want == nil {
+				if ok {
+					t.Errorf("eval() = %v, want undefined", got)
+				}
+				return
+			}
+			if !ok || got != tt.want {
+				t.Errorf("eval() = %v (%v), want %v", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseExprErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"requests.gpu",
+		"limits.cpu /",
+		"(1 + 2",
+		"1 2",
+		`"unterminated`,
+		"namespace",
+		"ns * 2",
+		`ns == 1`,
+		"requests.cpu * 2",
+		"1 ? 2 : 3",
+		`restarts > 1 ? "many" : 0`,
+		"!restarts",
+		"-ns",
+		"labels",
+		"[1, 2]",
+		"requests.cpu # 2",
+	} {
+		if _, err := parseExpr(expr); err == nil {
+			t.Errorf("parseExpr(%q) expected error", expr)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// extendedResourceSpec maps a device plugin resource to a display name, unit and
+// price in the config file
+type extendedResourceSpec struct {
+	Resource string  `json:"resource"`        // e.g. vendor.com/fpga
+	Name     string  `json:"name,omitempty"`  // Display name, default the resource name
+	Unit     string  `json:"unit,omitempty"`  // Display unit label, e.g. "devices" or "MiB"
+	Scale    string  `json:"scale,omitempty"` // Quantity per displayed unit, e.g. "1Mi", default "1"
+	Price    float64 `json:"price,omitempty"` // Per displayed unit and month
+}
+
+// extendedResource is a parsed extended resource mapping
+type extendedResource struct {
+	resource corev1.ResourceName
+	name     string
+	unit     string
+	scale    float64
+	price    float64
+}
+
+// isExtendedResource reports whether a resource name is a domain-qualified
+// extended resource (device plugins, nvidia.com/gpu, ...)
+func isExtendedResource(name corev1.ResourceName) bool {
+	return strings.Contains(string(name), "/") && !strings.HasPrefix(string(name), "kubernetes.io/")
+}
+
+// parseExtendedResources validates the configured mappings
+func parseExtendedResources(specs []extendedResourceSpec) ([]extendedResource, error) {
+	resources := make([]extendedResource, 0, len(specs))
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		if !isExtendedResource(corev1.ResourceName(spec.Resource)) {
+			return nil, fmt.Errorf("'%s' is not an extended resource (expected domain/name)", spec.Resource)
+		}
+		if seen[spec.Resource] {
+			return nil, fmt.Errorf("duplicate extended resource '%s'", spec.Resource)
+		}
+		seen[spec.Resource] = true
+		if spec.Price < 0 {
+			return nil, fmt.Errorf("negative price for extended resource '%s'", spec.Resource)
+		}
+
+		r := extendedResource{resource: corev1.ResourceName(spec.Resource), name: spec.Name, unit: spec.Unit, scale: 1, price: spec.Price}
+		if r.name == "" {
+			r.name = spec.Resource
+		}
+		if spec.Scale != "" {
+			q, err := resource.ParseQuantity(spec.Scale)
+			if err != nil || q.Sign() <= 0 {
+				return nil, fmt.Errorf("invalid scale '%s' for extended resource '%s'", spec.Scale, spec.Resource)
+			}
+			r.scale = q.AsApproximateFloat64()
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
+
+// value converts a quantity into displayed units
+func (r extendedResource) value(list corev1.ResourceList) float64 {
+	q, ok := list[r.resource]
+	if !ok {
+		return 0
+	}
+	return q.AsApproximateFloat64() / r.scale
+}
+
+// label returns the display name with its unit, e.g. "FPGA (devices)"
+func (r extendedResource) label() string {
+	if r.unit == "" {
+		return r.name
+	}
+	return fmt.Sprintf("%s (%s)", r.name, r.unit)
+}
+
+// extendedResourceHeaders are the Resources sheet columns of mapped resources
+func extendedResourceHeaders(resources []extendedResource) []string {
+	headers := make([]string, 0, 2*len(resources))
+	for _, r := range resources {
+		headers = append(headers, "Request "+r.label(), "Limit "+r.label())
+	}
+	return headers
+}
+
+// extendedResourceColumns returns a container's requests and limits of mapped resources
+func extendedResourceColumns(resources []extendedResource, requirements corev1.ResourceRequirements) []interface{} {
+	columns := make([]interface{}, 0, 2*len(resources))
+	for _, r := range resources {
+		columns = append(columns, r.value(requirements.Requests), r.value(requirements.Limits))
+	}
+	return columns
+}
+
+// discoverExtendedResources returns the mapped resources followed by every other
+// extended resource found in node allocatable or container requests and limits
+func discoverExtendedResources(mapped []extendedResource, pods []corev1.Pod, nodes *corev1.NodeList) []extendedResource {
+	known := make(map[corev1.ResourceName]bool, len(mapped))
+	for _, r := range mapped {
+		known[r.resource] = true
+	}
+	found := make(map[corev1.ResourceName]bool)
+	collect := func(list corev1.ResourceList) {
+		for name := range list {
+			if isExtendedResource(name) && !known[name] {
+				found[name] = true
+			}
+		}
+	}
+	if nodes != nil {
+		for _, node := range nodes.Items {
+			collect(node.Status.Allocatable)
+		}
+	}
+	for _, pod := range pods {
+		for _, c := range pod.Spec.Containers {
+			collect(c.Resources.Requests)
+			collect(c.Resources.Limits)
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	resources := append([]extendedResource{}, mapped...)
+	for _, name := range names {
+		resources = append(resources, extendedResource{resource: corev1.ResourceName(name), name: name, scale: 1})
+	}
+	return resources
+}
+
+// extendedUsage sums an extended resource in the cluster and per namespace
+type extendedUsage struct {
+	allocatable       float64
+	request, limit    float64
+	namespaceRequests map[string]float64
+	namespaceLimits   map[string]float64
+}
+
+// extendedUsages aggregates each resource over node allocatable and the
+// containers of active pods
+func extendedUsages(resources []extendedResource, pods []corev1.Pod, nodes *corev1.NodeList) []extendedUsage {
+	usages := make([]extendedUsage, len(resources))
+	for i, r := range resources {
+		u := &usages[i]
+		u.namespaceRequests = make(map[string]float64)
+		u.namespaceLimits = make(map[string]float64)
+		if nodes != nil {
+			for _, node := range nodes.Items {
+				u.allocatable += r.value(node.Status.Allocatable)
+			}
+		}
+		for j := range pods {
+			pod := &pods[j]
+			if !isActivePod(pod) {
+				continue
+			}
+			for _, c := range pod.Spec.Containers {
+				req, lim := r.value(c.Resources.Requests), r.value(c.Resources.Limits)
+				if req == 0 && lim == 0 {
+					continue
+				}
+				u.request += req
+				u.limit += lim
+				u.namespaceRequests[pod.Namespace] += req
+				u.namespaceLimits[pod.Namespace] += lim
+			}
+		}
+	}
+	return usages
+}
+
+// createExtendedResourceSheet writes cluster capacity, requests and cost of each
+// extended resource, followed by the requests per namespace and, when GPUs are
+// found, the GPU Sharing section
+func createExtendedResourceSheet(f *excelize.File, resources []extendedResource, usages []extendedUsage, shares []gpuShare, currency, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create extended resources sheet: %w", err)
+	}
+
+	headers := []string{
+		"Resource", "Resource Name", "Unit", "Allocatable", "Requested", "Limit", "Requested %",
+		fmt.Sprintf("Price per Unit (%s)", currency), fmt.Sprintf("Monthly Cost (%s)", currency),
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for i, r := range resources {
+		u := usages[i]
+		var price, cost interface{}
+		if r.price > 0 {
+			price, cost = r.price, u.request*r.price
+		}
+		var requested interface{}
+		if u.allocatable > 0 {
+			requested = u.request / u.allocatable
+		}
+		data := []interface{}{r.name, string(r.resource), r.unit, u.allocatable, u.request, u.limit, requested, price, cost}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("extended resource '%s'", r.resource)); err != nil {
+			return err
+		}
+		row++
+	}
+	if row > 2 {
+		f.SetCellStyle(sheetName, "D2", fmt.Sprintf("F%d", row-1), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "G2", fmt.Sprintf("G%d", row-1), export.PercentStyle(f, "0.0%"))
+		f.SetCellStyle(sheetName, "H2", fmt.Sprintf("I%d", row-1), export.DecimalStyle(f, false))
+	}
+
+	// Requests per namespace
+	row += 2
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Requests by Namespace")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
+	row++
+	nsHeaders := []string{"Resource", "Namespace", "Unit", "Requested", "Limit", fmt.Sprintf("Monthly Cost (%s)", currency)}
+	if err := f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &nsHeaders); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+	row++
+	start := row
+	for i, r := range resources {
+		u := usages[i]
+		namespaces := make([]string, 0, len(u.namespaceRequests))
+		for ns := range u.namespaceRequests {
+			namespaces = append(namespaces, ns)
+		}
+		sort.Strings(namespaces)
+		for _, ns := range namespaces {
+			var cost interface{}
+			if r.price > 0 {
+				cost = u.namespaceRequests[ns] * r.price
+			}
+			data := []interface{}{r.name, ns, r.unit, u.namespaceRequests[ns], u.namespaceLimits[ns], cost}
+			if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("extended resource '%s' namespace '%s'", r.resource, ns)); err != nil {
+				return err
+			}
+			row++
+		}
+	}
+	if row > start {
+		f.SetCellStyle(sheetName, fmt.Sprintf("D%d", start), fmt.Sprintf("F%d", row-1), export.DecimalStyle(f, false))
+	}
+
+	if len(shares) > 0 {
+		if _, err := writeGPUSharing(f, shares, sheetName, row+2); err != nil {
+			return err
+		}
+	}
+
+	f.SetColWidth(sheetName, "A", "B", 30)
+	f.SetColWidth(sheetName, "C", "C", 12)
+	f.SetColWidth(sheetName, "D", "I", 20)
+
+	return nil
+}
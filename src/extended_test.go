@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIsExtendedResource(t *testing.T) {
+	tests := []struct {
+		name corev1.ResourceName
+		want bool
+	}{
+		{"vendor.com/fpga", true},
+		{"nvidia.com/gpu", true},
+		{"intel.com/sgx_epc", true},
+		{"cpu", false},
+		{"hugepages-2Mi", false},
+		{"ephemeral-storage", false},
+		{"kubernetes.io/batch-cpu", false},
+	}
+	for _, tt := range tests {
+		if got := isExtendedResource(tt.name); got != tt.want {
+			t.Errorf("isExtendedResource(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseExtendedResources(t *testing.T) {
+	resources, err := parseExtendedResources([]extendedResourceSpec{
+		{Resource: "vendor.com/fpga", Name: "FPGA", Unit: "devices", Price: 150},
+		{Resource: "intel.com/sgx_epc", Unit: "MiB", Scale: "1Mi"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resources[0].label() != "FPGA (devices)" || resources[0].scale != 1 {
+		t.Errorf("resources[0] = %+v", resources[0])
+	}
+	if resources[1].name != "intel.com/sgx_epc" || resources[1].scale != 1<<20 {
+		t.Errorf("resources[1] = %+v, want resource name and 1Mi scale", resources[1])
+	}
+
+	invalid := [][]extendedResourceSpec{
+		{{Resource: "cpu"}},
+		{{Resource: "vendor.com/fpga"}, {Resource: "vendor.com/fpga"}},
+		{{Resource: "vendor.com/fpga", Scale: "0"}},
+		{{Resource: "vendor.com/fpga", Scale: "lots"}},
+		{{Resource: "vendor.com/fpga", Price: -1}},
+	}
+	for _, specs := range invalid {
+		if _, err := parseExtendedResources(specs); err == nil {
+			t.Errorf("parseExtendedResources(%+v) expected error", specs)
+		}
+	}
+}
+
+func TestExtendedResourceUsage(t *testing.T) {
+	mapped, _ := parseExtendedResources([]extendedResourceSpec{
+		{Resource: "intel.com/sgx_epc", Name: "SGX EPC", Unit: "MiB", Scale: "1Mi", Price: 0.5},
+	})
+	nodes := &corev1.NodeList{Items: []corev1.Node{{
+		ObjectMeta: metav1.ObjectMeta{Name: "n1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			"intel.com/sgx_epc": resource.MustParse("256Mi"),
+			"vendor.com/fpga":   resource.MustParse("4"),
+		}},
+	}}}
+	pod := func(namespace string, requests corev1.ResourceList) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:      "app",
+				Resources: corev1.ResourceRequirements{Requests: requests, Limits: requests},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("enclave", corev1.ResourceList{"intel.com/sgx_epc": resource.MustParse("64Mi")}),
+		pod("enclave", corev1.ResourceList{"intel.com/sgx_epc": resource.MustParse("32Mi")}),
+		pod("hpc", corev1.ResourceList{"vendor.com/fpga": resource.MustParse("1"), "example.org/dongle": resource.MustParse("1")}),
+	}
+
+	resources := discoverExtendedResources(mapped, pods, nodes)
+	names := []corev1.ResourceName{"intel.com/sgx_epc", "example.org/dongle", "vendor.com/fpga"}
+	if len(resources) != len(names) {
+		t.Fatalf("discoverExtendedResources() = %+v, want %v", resources, names)
+	}
+	for i, name := range names {
+		if resources[i].resource != name {
+			t.Errorf("resources[%d] = %s, want %s", i, resources[i].resource, name)
+		}
+	}
+
+	usages := extendedUsages(resources, pods, nodes)
+	if u := usages[0]; u.allocatable != 256 || u.request != 96 || u.namespaceRequests["enclave"] != 96 {
+		t.Errorf("SGX usage = %+v, want 96 of 256 MiB", u)
+	}
+	if u := usages[2]; u.allocatable != 4 || u.request != 1 || u.namespaceLimits["hpc"] != 1 {
+		t.Errorf("FPGA usage = %+v", u)
+	}
+
+	columns := extendedResourceColumns(mapped, pods[0].Spec.Containers[0].Resources)
+	if len(columns) != 2 || columns[0] != 64.0 || columns[1] != 64.0 {
+		t.Errorf("extendedResourceColumns() = %v, want [64 64]", columns)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Gini coefficient thresholds used to describe how concentrated requests are
+const (
+	GiniEven         = 0.3 // Below: requests spread evenly across tenants
+	GiniConcentrated = 0.6 // At or above: a few tenants hold most requests
+)
+
+// TopTenantFraction is the share of tenants whose combined requests are reported
+const TopTenantFraction = 0.1
+
+// giniCoefficient returns the Gini coefficient of values: 0 when all tenants
+// request the same, approaching 1 when a single tenant requests everything
+func giniCoefficient(values []float64) float64 {
+	n := len(values)
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum, weighted float64
+	for i, v := range sorted {
+		sum += v
+		weighted += float64(i+1) * v
+	}
+	if n < 2 || sum == 0 {
+		return 0
+	}
+	return 2*weighted/(float64(n)*sum) - float64(n+1)/float64(n)
+}
+
+// jainsIndex returns Jain's fairness index of values: 1 when all tenants request
+// the same, 1/n when a single tenant requests everything
+func jainsIndex(values []float64) float64 {
+	var sum, squares float64
+	for _, v := range values {
+		sum += v
+		squares += v * v
+	}
+	if squares == 0 {
+		return 1
+	}
+	return sum * sum / (float64(len(values)) * squares)
+}
+
+// topShare returns the share of the total held by the largest fraction of values
+// (at least one value)
+func topShare(values []float64, fraction float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Sort(sort.Reverse(sort.Float64Slice(sorted)))
+
+	top := int(math.Ceil(float64(len(sorted)) * fraction))
+	var sum, topSum float64
+	for i, v := range sorted {
+		sum += v
+		if i < top {
+			topSum += v
+		}
+	}
+	if sum == 0 {
+		return 0
+	}
+	return topSum / sum
+}
+
+// giniRating describes a Gini coefficient for the Insights sheet
+func giniRating(gini float64) string {
+	switch {
+	case gini < GiniEven:
+		return "✅ Evenly shared"
+	case gini < GiniConcentrated:
+		return "⚡ Moderately concentrated"
+	default:
+		return "⚠️ Few tenants dominate"
+	}
+}
+
+// tenantRequests returns the CPU (millicores) and memory (bytes) requests per tenant
+func tenantRequests(totals map[string]namespaceTotal) (cpu, mem []float64) {
+	for _, t := range totals {
+		cpu = append(cpu, float64(t.reqCPU))
+		mem = append(mem, float64(t.reqMem))
+	}
+	return cpu, mem
+}
+
+// fairnessInsights returns Insights rows (label, value, note, number format) for the
+// request distribution across tenants, e.g. "namespaces" or "teams"
+func fairnessInsights(msgs messageCatalog, tenant string, totals map[string]namespaceTotal) [][]interface{} {
+	cpu, mem := tenantRequests(totals)
+	cpuGini, memGini := giniCoefficient(cpu), giniCoefficient(mem)
+	tenant = msgs.text(tenant)
+	return [][]interface{}{
+		{msgs.sprintf("CPU Request Gini (%s)", tenant), cpuGini, giniRating(cpuGini), "0.00"},
+		{msgs.sprintf("Memory Request Gini (%s)", tenant), memGini, giniRating(memGini), "0.00"},
+		{msgs.sprintf("CPU Jain's Index (%s)", tenant), jainsIndex(cpu), "1 = equal shares", "0.00"},
+		{msgs.sprintf("Memory Jain's Index (%s)", tenant), jainsIndex(mem), "1 = equal shares", "0.00"},
+		{msgs.sprintf("Top %.0f%% %s CPU Share", TopTenantFraction*100, tenant), topShare(cpu, TopTenantFraction), "Share of all CPU requests", "0.0%"},
+		{msgs.sprintf("Top %.0f%% %s Memory Share", TopTenantFraction*100, tenant), topShare(mem, TopTenantFraction), "Share of all memory requests", "0.0%"},
+	}
+}
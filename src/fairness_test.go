@@ -0,0 +1,75 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGiniCoefficient(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"equal", []float64{100, 100, 100, 100}, 0},
+		{"one tenant holds all", []float64{0, 0, 0, 400}, 0.75},
+		{"mixed", []float64{100, 200, 300, 400}, 0.25},
+		{"single tenant", []float64{500}, 0},
+		{"all zero", []float64{0, 0}, 0},
+		{"empty", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := giniCoefficient(tt.values); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("giniCoefficient() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJainsIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"equal", []float64{100, 100, 100, 100}, 1},
+		{"one tenant holds all", []float64{0, 0, 0, 400}, 0.25},
+		{"mixed", []float64{100, 300}, 0.8},
+		{"all zero", []float64{0, 0}, 1},
+		{"empty", nil, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jainsIndex(tt.values); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("jainsIndex() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopShare(t *testing.T) {
+	values := []float64{10, 10, 10, 10, 10, 10, 10, 10, 10, 10, 400}
+	// 10% of 11 tenants rounds up to 2: 400 + 10 of 500
+	if got := topShare(values, 0.1); math.Abs(got-0.82) > 1e-9 {
+		t.Errorf("topShare() = %v, want 0.82", got)
+	}
+	if got := topShare([]float64{5}, 0.1); got != 1 {
+		t.Errorf("topShare(single) = %v, want 1", got)
+	}
+	if got := topShare(nil, 0.1); got != 0 {
+		t.Errorf("topShare(nil) = %v, want 0", got)
+	}
+}
+
+func TestGiniRating(t *testing.T) {
+	for gini, want := range map[float64]string{
+		0.1: "✅ Evenly shared",
+		0.3: "⚡ Moderately concentrated",
+		0.6: "⚠️ Few tenants dominate",
+	} {
+		if got := giniRating(gini); got != want {
+			t.Errorf("giniRating(%v) = %q, want %q", gini, got, want)
+		}
+	}
+}
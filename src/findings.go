@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Findings export formats for -findings-format
+const (
+	FindingsFormatJSON  = "json"
+	FindingsFormatSARIF = "sarif"
+)
+
+// FindingsSchemaVersion is incremented on incompatible changes to the JSON findings schema
+const FindingsSchemaVersion = 1
+
+// Tool identification in SARIF exports
+const (
+	ToolName           = "PodResourceCalculator"
+	ToolInformationURI = "https://github.com/ohauer/PodResourceCalculator"
+	SARIFSchema        = "https://json.schemastore.org/sarif-2.1.0.json"
+	SARIFVersion       = "2.1.0"
+)
+
+// findingsFormat returns the explicit format or derives it from the file
+// extension: .sarif writes SARIF, anything else JSON
+func findingsFormat(path, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case FindingsFormatJSON, FindingsFormatSARIF:
+		return strings.ToLower(format), nil
+	case "":
+		if strings.EqualFold(filepath.Ext(path), ".sarif") {
+			return FindingsFormatSARIF, nil
+		}
+		return FindingsFormatJSON, nil
+	}
+	return "", fmt.Errorf("unknown findings format '%s' (valid: %s, %s)", format, FindingsFormatJSON, FindingsFormatSARIF)
+}
+
+// findingsReport is the documented JSON findings schema
+type findingsReport struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	Generated     time.Time         `json:"generated"`
+	Cluster       string            `json:"cluster,omitempty"`
+	Namespace     string            `json:"namespace,omitempty"` // Empty for all namespaces
+	Findings      []findingsRecord  `json:"findings"`
+	Rules         map[string]string `json:"rules"` // Rule name -> description
+}
+
+// findingsRecord is a single finding in the JSON schema
+type findingsRecord struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"` // info, warn or error
+	Subject  string `json:"subject"`  // e.g. "namespace/shop" or "cluster"
+	Message  string `json:"message"`
+	Action   string `json:"action,omitempty"` // Review action from -review
+	Owner    string `json:"owner,omitempty"`  // Review owner from -review
+}
+
+// SARIF 2.1.0 subset used for findings exports
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+// sarifSuppression marks a result acknowledged in a review
+type sarifSuppression struct {
+	Kind          string `json:"kind"`   // external: outside the analyzed source
+	Status        string `json:"status"` // accepted
+	Justification string `json:"justification,omitempty"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind,omitempty"`
+}
+
+// sarifLevels maps finding severities to SARIF result levels
+var sarifLevels = map[severity]string{severityInfo: "note", severityWarn: "warning", severityError: "error"}
+
+// buildFindingsReport converts findings into the JSON findings schema
+func buildFindingsReport(findings []validationFinding, meta reportMetadata) findingsReport {
+	report := findingsReport{
+		SchemaVersion: FindingsSchemaVersion,
+		Generated:     meta.generated,
+		Cluster:       meta.cluster.name,
+		Namespace:     meta.namespace,
+		Findings:      make([]findingsRecord, 0, len(findings)),
+		Rules:         validationRuleDescriptions,
+	}
+	for _, finding := range findings {
+		report.Findings = append(report.Findings, findingsRecord{
+			Rule: finding.rule, Severity: finding.severity.String(), Subject: finding.subject, Message: finding.message,
+			Action: finding.action, Owner: finding.owner,
+		})
+	}
+	return report
+}
+
+// buildSARIF converts findings into a SARIF log with one run. Subjects become
+// logical locations, prefixed with the cluster name when known.
+func buildSARIF(findings []validationFinding, meta reportMetadata) sarifLog {
+	driver := sarifDriver{Name: ToolName, InformationURI: ToolInformationURI}
+	rules := make([]string, 0, len(validationRuleDescriptions))
+	for rule := range validationRuleDescriptions {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	for _, rule := range rules {
+		driver.Rules = append(driver.Rules, sarifRule{ID: rule, ShortDescription: sarifMessage{Text: validationRuleDescriptions[rule]}})
+	}
+
+	run := sarifRun{Tool: sarifTool{Driver: driver}, Results: make([]sarifResult, 0, len(findings))}
+	for _, finding := range findings {
+		kind, name, found := strings.Cut(finding.subject, "/")
+		if !found {
+			name = finding.subject
+		}
+		qualified := finding.subject
+		if meta.cluster.name != "" {
+			qualified = meta.cluster.name + "/" + finding.subject
+		}
+		result := sarifResult{
+			RuleID:  finding.rule,
+			Level:   sarifLevels[finding.severity],
+			Message: sarifMessage{Text: finding.message},
+			Locations: []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{
+				Name: name, FullyQualifiedName: qualified, Kind: kind,
+			}}}},
+		}
+		if finding.acknowledged() {
+			justification := finding.action + "ed in review"
+			if finding.owner != "" {
+				justification += " by " + finding.owner
+			}
+			result.Suppressions = []sarifSuppression{{Kind: "external", Status: "accepted", Justification: justification}}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	return sarifLog{Schema: SARIFSchema, Version: SARIFVersion, Runs: []sarifRun{run}}
+}
+
+// writeFindings writes the findings as JSON or SARIF
+func writeFindings(path, format string, findings []validationFinding, meta reportMetadata) error {
+	var doc interface{} = buildFindingsReport(findings, meta)
+	if format == FindingsFormatSARIF {
+		doc = buildSARIF(findings, meta)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode findings: %w", err)
+	}
+	if path == StdoutPath {
+		if _, err := stdout.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write findings to stdout: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write findings %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testFindings() []validationFinding {
+	return []validationFinding{
+		{rule: RuleNodePodImbalance, severity: severityError, subject: "cluster", message: "Pod distribution imbalanced: 1-9 pods per node"},
+		{rule: RuleNamespaceWithoutLimits, severity: severityWarn, subject: "namespace/shop", message: "Namespace 'shop' has no resource limits"},
+	}
+}
+
+func TestFindingsFormat(t *testing.T) {
+	tests := []struct {
+		path, format, want string
+		wantErr            bool
+	}{
+		{"findings.json", "", FindingsFormatJSON, false},
+		{"findings.sarif", "", FindingsFormatSARIF, false},
+		{"FINDINGS.SARIF", "", FindingsFormatSARIF, false},
+		{"findings.out", "SARIF", FindingsFormatSARIF, false},
+		{"findings.sarif", "json", FindingsFormatJSON, false},
+		{"findings.json", "xml", "", true},
+	}
+	for _, tt := range tests {
+		got, err := findingsFormat(tt.path, tt.format)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("findingsFormat(%q, %q) = %q, %v, want %q", tt.path, tt.format, got, err, tt.want)
+		}
+	}
+}
+
+func TestBuildSARIF(t *testing.T) {
+	meta := reportMetadata{cluster: clusterIdentity{name: "prod"}}
+	log := buildSARIF(testFindings(), meta)
+
+	if log.Version != SARIFVersion || len(log.Runs) != 1 {
+		t.Fatalf("buildSARIF() = %+v", log)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != len(validationRuleDescriptions) {
+		t.Errorf("driver lists %d rules, want %d", len(run.Tool.Driver.Rules), len(validationRuleDescriptions))
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("buildSARIF() returned %d results, want 2", len(run.Results))
+	}
+	if r := run.Results[0]; r.Level != "error" || r.Locations[0].LogicalLocations[0].FullyQualifiedName != "prod/cluster" {
+		t.Errorf("results[0] = %+v", r)
+	}
+	loc := run.Results[1].Locations[0].LogicalLocations[0]
+	if run.Results[1].Level != "warning" || loc.Name != "shop" || loc.Kind != "namespace" || loc.FullyQualifiedName != "prod/namespace/shop" {
+		t.Errorf("results[1] = %+v, location %+v", run.Results[1], loc)
+	}
+}
+
+func TestWriteFindings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "findings.json")
+	meta := reportMetadata{namespace: "shop", generated: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	if err := writeFindings(path, FindingsFormatJSON, testFindings(), meta); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report findingsReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("findings are not valid JSON: %v", err)
+	}
+	if report.SchemaVersion != FindingsSchemaVersion || report.Namespace != "shop" || !report.Generated.Equal(meta.generated) {
+		t.Errorf("report header = %+v", report)
+	}
+	if len(report.Findings) != 2 || report.Findings[1] != (findingsRecord{
+		Rule: RuleNamespaceWithoutLimits, Severity: "warn", Subject: "namespace/shop", Message: "Namespace 'shop' has no resource limits",
+	}) {
+		t.Errorf("report findings = %+v", report.Findings)
+	}
+
+	if err := writeFindings(path, FindingsFormatJSON, nil, meta); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	if err := json.Unmarshal(data, &report); err != nil || report.Findings == nil {
+		t.Errorf("empty findings should be written as [], got %s", data)
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+)
+
+// FleetSheetName is the sheet of the fleet workbook
+const FleetSheetName = "Fleet Overview"
+
+// fleetArgs are the flags of the fleet subcommand; the bundles are the
+// arguments after the flags
+type fleetArgs struct {
+	output         *string
+	verbose, quiet *bool
+}
+
+// fleetFlags defines the flags of the fleet subcommand
+func fleetFlags() (*flag.FlagSet, *fleetArgs) {
+	fs := flag.NewFlagSet("fleet", flag.ExitOnError)
+	return fs, &fleetArgs{
+		output:  fs.String("output", "", "Output filename (default: fleet_YYYY-MM-DD.xlsx of the latest collection date)"),
+		verbose: fs.Bool("verbose", false, "Enable verbose logging"),
+		quiet:   fs.Bool("quiet", false, "Only log errors (logs always go to stderr)"),
+	}
+}
+
+// clusterSummary are the totals of one cluster of the fleet
+type clusterSummary struct {
+	cluster   string
+	collected time.Time
+	nodes     int
+	totals    runTotals
+	cost      float64 // Weighted monthly cost, with pricing only
+	currency  string  // Empty without pricing
+}
+
+// saturation returns the higher of the CPU and memory requested shares of
+// allocatable, 0 without node capacity
+func (c clusterSummary) saturation() float64 {
+	var s float64
+	if c.totals.allocCPU > 0 {
+		s = float64(c.totals.reqCPU) / float64(c.totals.allocCPU)
+	}
+	if c.totals.allocMem > 0 {
+		s = maxFloat(s, float64(c.totals.reqMem)/float64(c.totals.allocMem))
+	}
+	return s
+}
+
+// efficiency returns the mean requests / limits ratio of CPU and memory like
+// the efficiency columns of the Resources sheet, 0 without limits
+func (c clusterSummary) efficiency() float64 {
+	var sum float64
+	var n int
+	if c.totals.limCPU > 0 {
+		sum += float64(c.totals.reqCPU) / float64(c.totals.limCPU)
+		n++
+	}
+	if c.totals.limMem > 0 {
+		sum += float64(c.totals.reqMem) / float64(c.totals.limMem)
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
+
+// maxFloat returns the larger of a and b
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// summarizeCluster sums the cluster totals of a bundle read from path. The
+// cluster is named by the bundle settings, else by the file name.
+func summarizeCluster(b *offlineBundle, path string) (clusterSummary, error) {
+	s := clusterSummary{cluster: b.Settings.Cluster, collected: b.Collected}
+	if s.cluster == "" {
+		s.cluster = b.Settings.Context
+	}
+	if s.cluster == "" {
+		s.cluster = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".gz"), ".json")
+	}
+
+	active := 0
+	for i := range b.Pods {
+		if isActivePod(&b.Pods[i]) {
+			active++
+		}
+	}
+	namespaceTotals, nodeTotals := aggregateTotals(b.Pods, b.Nodes)
+	s.totals = summarizeRun(active, namespaceTotals, nodeTotals)
+	if b.Nodes != nil {
+		s.nodes = len(b.Nodes.Items)
+		// Nodes without pods still add capacity
+		s.totals.allocCPU, s.totals.allocMem = 0, 0
+		for _, node := range b.Nodes.Items {
+			s.totals.allocCPU += node.Status.Allocatable.Cpu().MilliValue()
+			s.totals.allocMem += node.Status.Allocatable.Memory().Value()
+		}
+	}
+
+	pricing, err := parsePricing(b.Config.Pricing)
+	if err != nil {
+		return s, fmt.Errorf("invalid pricing in %s: %w", path, err)
+	}
+	if pricing != nil {
+		namespaceCosts, _ := podCosts(b.Pods, b.Nodes, pricing)
+		for _, ns := range namespaceCosts {
+			s.cost += ns.weighted
+		}
+		s.currency = pricing.Currency
+	}
+	return s, nil
+}
+
+// fleetRanks returns the rank (1 = first) of each cluster by saturation and
+// cost, highest first, and by efficiency, most efficient first. Clusters
+// without a cost are not ranked by cost (rank 0).
+func fleetRanks(clusters []clusterSummary) (saturation, efficiency, cost []int) {
+	rank := func(less func(a, b clusterSummary) bool, include func(c clusterSummary) bool) []int {
+		order := make([]int, 0, len(clusters))
+		for i, c := range clusters {
+			if include(c) {
+				order = append(order, i)
+			}
+		}
+		sort.SliceStable(order, func(i, j int) bool { return less(clusters[order[i]], clusters[order[j]]) })
+		ranks := make([]int, len(clusters))
+		for r, i := range order {
+			ranks[i] = r + 1
+		}
+		return ranks
+	}
+	all := func(clusterSummary) bool { return true }
+	saturation = rank(func(a, b clusterSummary) bool { return a.saturation() > b.saturation() }, all)
+	efficiency = rank(func(a, b clusterSummary) bool { return a.efficiency() > b.efficiency() }, all)
+	cost = rank(func(a, b clusterSummary) bool { return a.cost > b.cost }, func(c clusterSummary) bool { return c.currency != "" })
+	return saturation, efficiency, cost
+}
+
+// fleetCurrency returns the currency shared by all priced clusters, empty when
+// no cluster is priced or the currencies differ
+func fleetCurrency(clusters []clusterSummary) string {
+	currency := ""
+	for _, c := range clusters {
+		if c.currency == "" {
+			continue
+		}
+		if currency != "" && c.currency != currency {
+			return ""
+		}
+		currency = c.currency
+	}
+	return currency
+}
+
+// createFleetSheet writes one row per cluster, most saturated first, with
+// its ranks and the fleet totals below
+func createFleetSheet(f *excelize.File, clusters []clusterSummary, sheetName string) error {
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].saturation() > clusters[j].saturation() })
+	satRanks, effRanks, costRanks := fleetRanks(clusters)
+	currency := fleetCurrency(clusters)
+
+	f.SetCellValue(sheetName, "A1", fmt.Sprintf("Fleet Overview: %s", pluralize(len(clusters), "cluster")))
+	f.SetCellStyle(sheetName, "A1", "A1", export.HeaderStyle(f))
+
+	headers := []interface{}{
+		"Cluster", "Collected", "Nodes", "Namespaces", "Pods",
+		"Request CPU (cores)", "Allocatable CPU (cores)", "CPU Requested %",
+		"Request Memory (Gi)", "Allocatable Memory (Gi)", "Memory Requested %",
+		"Saturation %", "Efficiency (Requests / Limits)", "Monthly Cost", "Currency",
+		"Saturation Rank", "Efficiency Rank", "Cost Rank",
+	}
+	if err := export.SetRow(f, sheetName, 3, headers, "fleet headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, "A3", "R3", export.BoldStyle(f))
+
+	var fleet clusterSummary
+	fleet.cluster = "Fleet Total"
+	row := 4
+	for i, c := range clusters {
+		var cost, costRank interface{}
+		if c.currency != "" {
+			cost = c.cost
+			costRank = costRanks[i]
+		}
+		data := []interface{}{
+			c.cluster,
+			c.collected.Format("2006-01-02 15:04"),
+			c.nodes,
+			c.totals.namespaces,
+			c.totals.pods,
+			milliToCores(c.totals.reqCPU),
+			milliToCores(c.totals.allocCPU),
+			ratio(c.totals.reqCPU, c.totals.allocCPU),
+			bytesToGi(c.totals.reqMem),
+			bytesToGi(c.totals.allocMem),
+			ratio(c.totals.reqMem, c.totals.allocMem),
+			c.saturation(),
+			c.efficiency(),
+			cost,
+			valueOrDash(c.currency),
+			satRanks[i],
+			effRanks[i],
+			costRank,
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("cluster '%s'", c.cluster)); err != nil {
+			return err
+		}
+		row++
+
+		fleet.nodes += c.nodes
+		fleet.totals.namespaces += c.totals.namespaces
+		fleet.totals.pods += c.totals.pods
+		fleet.totals.reqCPU += c.totals.reqCPU
+		fleet.totals.limCPU += c.totals.limCPU
+		fleet.totals.reqMem += c.totals.reqMem
+		fleet.totals.limMem += c.totals.limMem
+		fleet.totals.allocCPU += c.totals.allocCPU
+		fleet.totals.allocMem += c.totals.allocMem
+		fleet.cost += c.cost
+	}
+
+	// Costs in different currencies cannot be summed
+	var fleetCost interface{}
+	if currency != "" {
+		fleetCost = fleet.cost
+	}
+	total := []interface{}{
+		fleet.cluster, "", fleet.nodes, fleet.totals.namespaces, fleet.totals.pods,
+		milliToCores(fleet.totals.reqCPU), milliToCores(fleet.totals.allocCPU), ratio(fleet.totals.reqCPU, fleet.totals.allocCPU),
+		bytesToGi(fleet.totals.reqMem), bytesToGi(fleet.totals.allocMem), ratio(fleet.totals.reqMem, fleet.totals.allocMem),
+		fleet.saturation(), fleet.efficiency(), fleetCost, valueOrDash(currency),
+	}
+	if err := export.SetRow(f, sheetName, row, total, "fleet total"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("E%d", row), export.BoldStyle(f))
+
+	f.SetCellStyle(sheetName, "F4", fmt.Sprintf("G%d", row), export.DecimalStyle(f, false))
+	f.SetCellStyle(sheetName, "H4", fmt.Sprintf("H%d", row), export.PercentStyle(f, "0.0%"))
+	f.SetCellStyle(sheetName, "I4", fmt.Sprintf("J%d", row), export.DecimalStyle(f, false))
+	f.SetCellStyle(sheetName, "K4", fmt.Sprintf("M%d", row), export.PercentStyle(f, "0.0%"))
+	f.SetCellStyle(sheetName, "N4", fmt.Sprintf("N%d", row), export.DecimalStyle(f, false))
+
+	row += 2
+	notes := []string{
+		"Saturation: the higher of the CPU and memory requests / allocatable. Efficiency: the mean CPU and memory requests / limits.",
+		"Monthly cost: weighted cost of the requests with the pricing config of each bundle; the fleet total needs a single currency.",
+	}
+	for _, note := range notes {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), note)
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 28)
+	f.SetColWidth(sheetName, "B", "B", 18)
+	f.SetColWidth(sheetName, "C", "E", 12)
+	f.SetColWidth(sheetName, "F", "N", 20)
+	f.SetColWidth(sheetName, "O", "R", 14)
+
+	return nil
+}
+
+// writeFleetWorkbook writes the fleet overview of the clusters to filename
+func writeFleetWorkbook(filename string, clusters []clusterSummary) (err error) {
+	f, err := export.New(FleetSheetName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to close workbook %s: %w", filename, cerr)
+		}
+	}()
+	if err := createFleetSheet(f, clusters, FleetSheetName); err != nil {
+		return err
+	}
+	if err := f.SaveAs(filename); err != nil {
+		return fmt.Errorf("failed to save workbook %s: %w", filename, err)
+	}
+	return nil
+}
+
+// runFleet implements the fleet subcommand: it rolls the offline bundles of
+// several clusters up into one fleet overview workbook
+func runFleet(args []string) error {
+	fs, a := fleetFlags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := configureLogging(*a.verbose, *a.quiet); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("at least one offline bundle is required")
+	}
+
+	var clusters []clusterSummary
+	var latest time.Time
+	seen := make(map[string]string)
+	for _, path := range fs.Args() {
+		b, err := readBundle(path)
+		if err != nil {
+			return err
+		}
+		s, err := summarizeCluster(b, path)
+		if err != nil {
+			return err
+		}
+		if other, ok := seen[s.cluster]; ok {
+			logrus.Warnf("Bundles %s and %s are both of cluster '%s'", other, path, s.cluster)
+		}
+		seen[s.cluster] = path
+		if s.collected.After(latest) {
+			latest = s.collected
+		}
+		clusters = append(clusters, s)
+	}
+
+	filename := *a.output
+	if filename == "" {
+		filename = fmt.Sprintf("fleet_%s.xlsx", latest.Format("2006-01-02"))
+	}
+	filename = filepath.Clean(filename)
+	if err := validatePath(filename); err != nil {
+		return fmt.Errorf("invalid output filename: %w", err)
+	}
+	if err := writeFleetWorkbook(filename, clusters); err != nil {
+		return err
+	}
+	logrus.Infof("Fleet overview of %s written: %s", pluralize(len(clusters), "cluster"), filename)
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSummarizeCluster(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(now)
+	b := &offlineBundle{
+		Collected: now,
+		Pods:      snap.pods,
+		Nodes:     snap.nodes,
+		Config:    config{Pricing: &pricingSpec{CPUCoreMonth: 10}},
+	}
+
+	s, err := summarizeCluster(b, "/tmp/prod-eu.json.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.cluster != "prod-eu" || s.nodes != 2 || s.totals.pods != 3 || s.totals.namespaces != 2 {
+		t.Errorf("summary = %+v, want cluster prod-eu from the file name with 2 nodes, 3 pods", s)
+	}
+	// 3 of 8 cores requested, 3Gi of 32Gi
+	if s.totals.reqCPU != 3000 || s.totals.allocCPU != 8000 || s.saturation() != 0.375 {
+		t.Errorf("saturation = %v of %+v, want 0.375", s.saturation(), s.totals)
+	}
+	if s.currency != DefaultCurrency || s.cost != 30 {
+		t.Errorf("cost = %v %s, want 30 %s", s.cost, s.currency, DefaultCurrency)
+	}
+
+	b.Settings.Cluster = "prod"
+	b.Config.Pricing = &pricingSpec{CPUCoreMonth: -1}
+	if _, err := summarizeCluster(b, "/tmp/prod-eu.json.gz"); err == nil {
+		t.Error("expected an error for invalid pricing")
+	}
+}
+
+func TestFleetRanks(t *testing.T) {
+	clusters := []clusterSummary{
+		{cluster: "a", totals: runTotals{reqCPU: 500, limCPU: 1000, allocCPU: 1000}, cost: 50, currency: "EUR"},
+		{cluster: "b", totals: runTotals{reqCPU: 900, limCPU: 1000, allocCPU: 1000}},
+		{cluster: "c", totals: runTotals{reqCPU: 200, limCPU: 2000, allocCPU: 1000}, cost: 80, currency: "EUR"},
+	}
+	saturation, efficiency, cost := fleetRanks(clusters)
+	tests := []struct {
+		name      string
+		got, want []int
+	}{
+		{"saturation", saturation, []int{2, 1, 3}},
+		{"efficiency", efficiency, []int{2, 1, 3}},
+		{"cost", cost, []int{2, 0, 1}},
+	}
+	for _, tt := range tests {
+		for i := range tt.want {
+			if tt.got[i] != tt.want[i] {
+				t.Errorf("%s ranks = %v, want %v", tt.name, tt.got, tt.want)
+				break
+			}
+		}
+	}
+
+	if got := fleetCurrency(clusters); got != "EUR" {
+		t.Errorf("fleetCurrency() = %q, want EUR", got)
+	}
+	clusters[1].currency = "USD"
+	if got := fleetCurrency(clusters); got != "" {
+		t.Errorf("fleetCurrency() = %q, want none for mixed currencies", got)
+	}
+}
+
+func TestWriteFleetWorkbook(t *testing.T) {
+	clusters := []clusterSummary{
+		{cluster: "staging", nodes: 1, totals: runTotals{pods: 2, reqCPU: 100, allocCPU: 1000}},
+		{cluster: "prod", nodes: 3, totals: runTotals{pods: 5, reqCPU: 800, allocCPU: 1000}},
+	}
+	path := filepath.Join(t.TempDir(), "fleet.xlsx")
+	if err := writeFleetWorkbook(path, clusters); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	for cell, want := range map[string]string{"A4": "prod", "A5": "staging", "A6": "Fleet Total", "C6": "4", "E6": "7"} {
+		if got, _ := f.GetCellValue(FleetSheetName, cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
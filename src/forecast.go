@@ -0,0 +1,360 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultCronJobDuration is the assumed run time of CronJobs without finished
+// runs or an active deadline
+const DefaultCronJobDuration = 10 * time.Minute
+
+// MaxForecastPeaks is the number of peak windows on the CronJob Forecast sheet
+const MaxForecastPeaks = 10
+
+// Sources of the estimated run time of a CronJob
+const (
+	DurationHistory  = "history"  // Average of its finished Jobs
+	DurationDeadline = "deadline" // activeDeadlineSeconds of the Job template
+	DurationDefault  = "default"  // DefaultCronJobDuration
+)
+
+// cronJobSpec is a CronJob with the requests and estimated run time of one run
+type cronJobSpec struct {
+	cronJob        workloadKey // namespace/CronJob/name
+	schedule       string
+	timeZone       string // spec.timeZone, empty for the controller's time zone
+	suspended      bool
+	concurrency    string // Allow, Forbid or Replace
+	reqCPU, reqMem int64  // Requests of the parallel pods of one run
+	duration       time.Duration
+	durationSource string // DurationHistory, DurationDeadline or DurationDefault
+}
+
+// cronJobSpecs describes the CronJobs; the run time is the average of the
+// finished runs, else the active deadline, else DefaultCronJobDuration
+func cronJobSpecs(cronJobs []batchv1.CronJob, runs []jobRun) []cronJobSpec {
+	type history struct {
+		total time.Duration
+		count int
+	}
+	finished := make(map[workloadKey]history)
+	for _, run := range runs {
+		if run.cronJob == "" || run.end.IsZero() {
+			continue
+		}
+		h := finished[run.group()]
+		h.total += run.end.Sub(run.start)
+		h.count++
+		finished[run.group()] = h
+	}
+
+	specs := make([]cronJobSpec, 0, len(cronJobs))
+	for i := range cronJobs {
+		cj := &cronJobs[i]
+		jobSpec := cj.Spec.JobTemplate.Spec
+		spec := cronJobSpec{
+			cronJob:        workloadKey{namespace: cj.Namespace, kind: "CronJob", name: cj.Name},
+			schedule:       cj.Spec.Schedule,
+			suspended:      cj.Spec.Suspend != nil && *cj.Spec.Suspend,
+			concurrency:    string(cj.Spec.ConcurrencyPolicy),
+			duration:       DefaultCronJobDuration,
+			durationSource: DurationDefault,
+		}
+		if spec.concurrency == "" {
+			spec.concurrency = string(batchv1.AllowConcurrent)
+		}
+		if cj.Spec.TimeZone != nil {
+			spec.timeZone = *cj.Spec.TimeZone
+		}
+		parallelism := int64(1)
+		if jobSpec.Parallelism != nil {
+			parallelism = int64(*jobSpec.Parallelism)
+		}
+		if jobSpec.Completions != nil && int64(*jobSpec.Completions) < parallelism {
+			parallelism = int64(*jobSpec.Completions)
+		}
+		cpu, mem := podRequests(&corev1.Pod{Spec: jobSpec.Template.Spec})
+		spec.reqCPU, spec.reqMem = cpu*parallelism, mem*parallelism
+		if h := finished[spec.cronJob]; h.count > 0 {
+			spec.duration, spec.durationSource = h.total/time.Duration(h.count), DurationHistory
+		} else if jobSpec.ActiveDeadlineSeconds != nil {
+			spec.duration, spec.durationSource = time.Duration(*jobSpec.ActiveDeadlineSeconds)*time.Second, DurationDeadline
+		}
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].cronJob.String() < specs[j].cronJob.String() })
+	return specs
+}
+
+// forecastRun is one expected run of a CronJob
+type forecastRun struct {
+	spec       *cronJobSpec
+	start, end time.Time
+}
+
+// scheduledRuns returns the runs of the CronJob starting in [from, to); Forbid
+// skips starts while the previous run is active and Replace ends it
+func (s *cronJobSpec) scheduledRuns(from, to time.Time) ([]forecastRun, error) {
+	location := time.UTC // The controller's time zone, UTC in most clusters
+	if s.timeZone != "" {
+		loc, err := time.LoadLocation(s.timeZone)
+		if err != nil {
+			return nil, fmt.Errorf("unknown time zone '%s'", s.timeZone)
+		}
+		location = loc
+	}
+	schedule, err := parseCronSchedule(s.schedule, location)
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []forecastRun
+	for _, start := range schedule.between(from, to) {
+		if n := len(runs); n > 0 && runs[n-1].end.After(start) {
+			switch s.concurrency {
+			case string(batchv1.ForbidConcurrent):
+				continue
+			case string(batchv1.ReplaceConcurrent):
+				runs[n-1].end = start
+			}
+		}
+		runs = append(runs, forecastRun{spec: s, start: start, end: start.Add(s.duration)})
+	}
+	return runs, nil
+}
+
+// burstWindow is a time window with a constant set of running CronJobs
+type burstWindow struct {
+	start, end         time.Time
+	cronJobs           []string // namespace/name of the running CronJobs
+	cpu, mem           int64    // Requests of the running CronJobs
+	steadyCPU          int64    // Requests of the pods not owned by Jobs
+	steadyMem          int64
+	allocCPU, allocMem int64 // Allocatable of the cluster, 0 without node data
+}
+
+// cpuShare returns the steady and CronJob CPU requests as a share of
+// allocatable, nil without node data
+func (w burstWindow) cpuShare() interface{} {
+	return ratio(w.steadyCPU+w.cpu, w.allocCPU)
+}
+
+// memShare returns the steady and CronJob memory requests as a share of
+// allocatable, nil without node data
+func (w burstWindow) memShare() interface{} {
+	return ratio(w.steadyMem+w.mem, w.allocMem)
+}
+
+// cronJobForecast is the expected CronJob load in the horizon after from
+type cronJobForecast struct {
+	from    time.Time
+	horizon time.Duration
+	specs   []cronJobSpec
+	runs    map[workloadKey]int    // Expected runs per CronJob
+	invalid map[workloadKey]string // Schedules that could not be parsed
+	peaks   []burstWindow          // Windows with the most CronJob CPU requests first
+}
+
+// newCronJobForecast expands the schedules of the active CronJobs in the
+// horizon and finds the windows where the requests of overlapping runs peak.
+// Pods not owned by Jobs are the steady load the bursts add to.
+func newCronJobForecast(specs []cronJobSpec, pods []corev1.Pod, nodes *corev1.NodeList, from time.Time, horizon time.Duration) *cronJobForecast {
+	fc := &cronJobForecast{
+		from:    from,
+		horizon: horizon,
+		specs:   specs,
+		runs:    make(map[workloadKey]int),
+		invalid: make(map[workloadKey]string),
+	}
+
+	type event struct {
+		at    time.Time
+		run   int
+		start bool
+	}
+	var runs []forecastRun
+	var events []event
+	for i := range specs {
+		spec := &specs[i]
+		if spec.suspended {
+			continue
+		}
+		scheduled, err := spec.scheduledRuns(from, from.Add(horizon))
+		if err != nil {
+			fc.invalid[spec.cronJob] = err.Error()
+			logrus.Warnf("Skipping CronJob %s in the forecast: %v", spec.cronJob, err)
+			continue
+		}
+		fc.runs[spec.cronJob] = len(scheduled)
+		for _, run := range scheduled {
+			events = append(events, event{run.start, len(runs), true}, event{run.end, len(runs), false})
+			runs = append(runs, run)
+		}
+	}
+	// Ends first, so back-to-back runs do not overlap
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return !events[i].start && events[j].start
+	})
+
+	var steadyCPU, steadyMem int64
+	for i := range pods {
+		if isActivePod(&pods[i]) && workloadOf(&pods[i]).kind != "Job" {
+			cpu, mem := podRequests(&pods[i])
+			steadyCPU += cpu
+			steadyMem += mem
+		}
+	}
+	var allocCPU, allocMem int64
+	if nodes != nil {
+		for _, node := range nodes.Items {
+			allocCPU += node.Status.Allocatable.Cpu().MilliValue()
+			allocMem += node.Status.Allocatable.Memory().Value()
+		}
+	}
+
+	active := make(map[int]bool)
+	var windows []burstWindow
+	for i, e := range events {
+		if e.start {
+			active[e.run] = true
+		} else {
+			delete(active, e.run)
+		}
+		if len(active) == 0 || (i+1 < len(events) && events[i+1].at.Equal(e.at)) {
+			continue
+		}
+		w := burstWindow{start: e.at, steadyCPU: steadyCPU, steadyMem: steadyMem, allocCPU: allocCPU, allocMem: allocMem}
+		if i+1 < len(events) {
+			w.end = events[i+1].at
+		}
+		for run := range active {
+			w.cpu += runs[run].spec.reqCPU
+			w.mem += runs[run].spec.reqMem
+			w.cronJobs = append(w.cronJobs, runs[run].spec.cronJob.namespace+"/"+runs[run].spec.cronJob.name)
+		}
+		sort.Strings(w.cronJobs)
+		windows = append(windows, w)
+	}
+	sort.SliceStable(windows, func(i, j int) bool {
+		if windows[i].cpu != windows[j].cpu {
+			return windows[i].cpu > windows[j].cpu
+		}
+		return windows[i].mem > windows[j].mem
+	})
+	if len(windows) > MaxForecastPeaks {
+		windows = windows[:MaxForecastPeaks]
+	}
+	fc.peaks = windows
+	return fc
+}
+
+// createForecastSheet lists the CronJob peak windows of the forecast, marking
+// those above threshold of allocatable, followed by the CronJobs and their
+// expected runs
+func createForecastSheet(f *excelize.File, fc *cronJobForecast, threshold float64, sheetName string, t theme) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create forecast sheet: %w", err)
+	}
+
+	location := fc.from.Location()
+	f.SetCellValue(sheetName, "A1", fmt.Sprintf("CronJob requests expected from %s for %s on top of the requests of all other pods",
+		fc.from.Format("2006-01-02 15:04"), pluralize(int(fc.horizon.Hours()), "hour")))
+
+	colors := t.efficiency[0]
+	markStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: colors.font},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{colors.fill}, Pattern: 1},
+	})
+	decimalStyle := export.DecimalStyle(f, false)
+	percentStyle := export.PercentStyle(f, "0.0%")
+
+	peakHeaders := []interface{}{
+		"Window Start", "Window End", "CronJobs Running", "CronJob CPU (cores)", "CronJob Memory (Gi)",
+		"CPU % of Allocatable", "Memory % of Allocatable", "CronJobs",
+	}
+	if err := export.SetRow(f, sheetName, 3, peakHeaders, "forecast peak headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, "A3", "H3", export.BoldStyle(f))
+	row := 4
+	for _, w := range fc.peaks {
+		end := "-"
+		if !w.end.IsZero() {
+			end = w.end.In(location).Format("2006-01-02 15:04")
+		}
+		data := []interface{}{
+			w.start.In(location).Format("2006-01-02 15:04"), end, len(w.cronJobs),
+			milliToCores(w.cpu), bytesToGi(w.mem), w.cpuShare(), w.memShare(), strings.Join(w.cronJobs, ", "),
+		}
+		if err := export.SetRow(f, sheetName, row, data, "forecast window "+data[0].(string)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("E%d", row), decimalStyle)
+		f.SetCellStyle(sheetName, fmt.Sprintf("F%d", row), fmt.Sprintf("G%d", row), percentStyle)
+		for col, share := range map[string]interface{}{"F": w.cpuShare(), "G": w.memShare()} {
+			if s, ok := share.(float64); ok && s > threshold {
+				f.SetCellStyle(sheetName, fmt.Sprintf("%s%d", col, row), fmt.Sprintf("%s%d", col, row), markStyle)
+			}
+		}
+		row++
+	}
+	if len(fc.peaks) == 0 {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "No CronJob runs expected in the forecast horizon")
+		row++
+	}
+
+	row++
+	headers := []interface{}{
+		"CronJob", "Schedule", "Time Zone", "Concurrency Policy", "Expected Runs", "Est. Duration (min)",
+		"Duration Source", "Request CPU per Run (cores)", "Request Memory per Run (Gi)", "Note",
+	}
+	if err := export.SetRow(f, sheetName, row, headers, "forecast cronjob headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("J%d", row), export.BoldStyle(f))
+	row++
+	for _, spec := range fc.specs {
+		note := fc.invalid[spec.cronJob]
+		if spec.suspended {
+			note = "Suspended"
+		}
+		data := []interface{}{
+			spec.cronJob.String(), spec.schedule, valueOrDash(spec.timeZone), spec.concurrency, fc.runs[spec.cronJob],
+			spec.duration.Minutes(), spec.durationSource, milliToCores(spec.reqCPU), bytesToGi(spec.reqMem), note,
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("cronjob '%s'", spec.cronJob)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("F%d", row), fmt.Sprintf("F%d", row), decimalStyle)
+		f.SetCellStyle(sheetName, fmt.Sprintf("H%d", row), fmt.Sprintf("I%d", row), decimalStyle)
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "B", 40)
+	f.SetColWidth(sheetName, "C", "G", 20)
+	f.SetColWidth(sheetName, "H", "J", 40)
+
+	return nil
+}
+
+// forecast returns the CronJob forecast of the snapshot over horizon, nil when
+// CronJobs were not collected
+func (snap *clusterSnapshot) forecast(horizon time.Duration) *cronJobForecast {
+	if snap.cronJobs == nil || horizon <= 0 {
+		return nil
+	}
+	return newCronJobForecast(snap.cronJobs, snap.pods, snap.nodes, snap.collected, horizon)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCronJobSpecs(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	int64p := func(v int64) *int64 { return &v }
+	int32p := func(v int32) *int32 { return &v }
+	cronJob := func(name string, deadline *int64) batchv1.CronJob {
+		cj := batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: name}, Spec: batchv1.CronJobSpec{Schedule: "0 * * * *"}}
+		cj.Spec.JobTemplate.Spec = batchv1.JobSpec{Parallelism: int32p(3), ActiveDeadlineSeconds: deadline, Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "run", Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}}}},
+		}}}
+		return cj
+	}
+	runs := []jobRun{
+		{job: workloadKey{namespace: "batch", kind: "Job", name: "etl-1"}, cronJob: "etl", start: now.Add(-2 * time.Hour), end: now.Add(-time.Hour)},
+		{job: workloadKey{namespace: "batch", kind: "Job", name: "etl-2"}, cronJob: "etl", start: now.Add(-time.Hour), end: now.Add(-30 * time.Minute)},
+		{job: workloadKey{namespace: "batch", kind: "Job", name: "etl-3"}, cronJob: "etl", start: now.Add(-time.Minute)}, // Running
+	}
+
+	specs := cronJobSpecs([]batchv1.CronJob{cronJob("etl", nil), cronJob("report", int64p(1200)), cronJob("sync", nil)}, runs)
+	want := []struct {
+		duration time.Duration
+		source   string
+	}{{45 * time.Minute, DurationHistory}, {20 * time.Minute, DurationDeadline}, {DefaultCronJobDuration, DurationDefault}}
+	for i, w := range want {
+		if specs[i].duration != w.duration || specs[i].durationSource != w.source {
+			t.Errorf("%s duration = %v from %s, want %v from %s", specs[i].cronJob, specs[i].duration, specs[i].durationSource, w.duration, w.source)
+		}
+	}
+	if specs[0].reqCPU != 1500 || specs[0].concurrency != string(batchv1.AllowConcurrent) {
+		t.Errorf("etl spec = %+v, want 3 pods of 500m and Allow", specs[0])
+	}
+}
+
+func TestCronJobForecast(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(now) // 3 cores requested of 8 allocatable
+	spec := func(name, schedule, concurrency string, cpu int64, duration time.Duration) cronJobSpec {
+		return cronJobSpec{
+			cronJob: workloadKey{namespace: "batch", kind: "CronJob", name: name}, schedule: schedule,
+			concurrency: concurrency, reqCPU: cpu, duration: duration,
+		}
+	}
+	specs := []cronJobSpec{
+		spec("etl", "0 2 * * *", "Allow", 3000, time.Hour),          // 02:00-03:00
+		spec("report", "30 2 * * *", "Allow", 2000, time.Hour),      // 02:30-03:30, overlaps etl
+		spec("poll", "*/10 * * * *", "Forbid", 100, 25*time.Minute), // Every 30 minutes with Forbid
+		spec("broken", "61 * * * *", "Allow", 100, time.Minute),
+	}
+
+	fc := newCronJobForecast(specs, snap.pods, snap.nodes, now, 24*time.Hour)
+	if fc.runs[specs[0].cronJob] != 1 || fc.runs[specs[2].cronJob] != 48 || fc.invalid[specs[3].cronJob] == "" {
+		t.Fatalf("runs = %v, invalid = %v", fc.runs, fc.invalid)
+	}
+	peak := fc.peaks[0]
+	if !peak.start.Equal(now.Add(14*time.Hour+30*time.Minute)) || peak.cpu != 5100 || strings.Join(peak.cronJobs, ",") != "batch/etl,batch/poll,batch/report" {
+		t.Fatalf("peak = %+v, want etl, poll and report from 02:30", peak)
+	}
+	if share := peak.cpuShare(); share != (3000.0+5100)/8000 {
+		t.Errorf("cpuShare() = %v", share)
+	}
+
+	rules, _ := parseValidationRules(validationSpec{})
+	findings := validateResources(validationInput{forecast: fc}, rules)
+	if len(findings) != 1 || findings[0].rule != RuleCronJobBurst || !strings.Contains(findings[0].message, "101% of allocatable at 2024-05-11 02:30") {
+		t.Errorf("findings = %+v", findings)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createForecastSheet(f, fc, rules.rules[RuleCronJobBurst].threshold, "CronJob Forecast", themes[DefaultTheme]); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A4": "2024-05-11 02:30", "C4": "3", "A15": "CronJob", "A16": "batch/CronJob/etl", "A19": "batch/CronJob/broken"} {
+		if got, _ := f.GetCellValue("CronJob Forecast", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Labels and annotations set by GitOps controllers on the objects they apply
+const (
+	ArgoTrackingIDAnnotation = "argocd.argoproj.io/tracking-id" // "<app>:<group>/<kind>:<namespace>/<name>"
+	ArgoInstanceLabel        = "argocd.argoproj.io/instance"
+	FluxKustomizationName    = "kustomize.toolkit.fluxcd.io/name"
+	FluxKustomizationNS      = "kustomize.toolkit.fluxcd.io/namespace"
+	FluxHelmReleaseName      = "helm.toolkit.fluxcd.io/name"
+	FluxHelmReleaseNS        = "helm.toolkit.fluxcd.io/namespace"
+)
+
+// GitOps tools reported in the GitOps Tool column
+const (
+	GitOpsToolArgoCD = "Argo CD"
+	GitOpsToolFlux   = "Flux"
+)
+
+// GitOps custom resources used to resolve the source repository of an owner
+var (
+	argoApplicationsGVR   = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "applications"}
+	fluxKustomizationsGVR = schema.GroupVersionResource{Group: "kustomize.toolkit.fluxcd.io", Version: "v1", Resource: "kustomizations"}
+	fluxHelmReleasesGVR   = schema.GroupVersionResource{Group: "helm.toolkit.fluxcd.io", Version: "v2", Resource: "helmreleases"}
+	fluxSourceGVRs        = map[string]schema.GroupVersionResource{
+		"GitRepository":  {Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "gitrepositories"},
+		"HelmRepository": {Group: "source.toolkit.fluxcd.io", Version: "v1", Resource: "helmrepositories"},
+		"OCIRepository":  {Group: "source.toolkit.fluxcd.io", Version: "v1beta2", Resource: "ocirepositories"},
+	}
+)
+
+// gitopsOwner is the GitOps application managing a workload
+type gitopsOwner struct {
+	tool string // GitOpsToolArgoCD or GitOpsToolFlux
+	app  string // Argo CD Application name or Flux "<Kind> <namespace>/<name>"
+	repo string // Source repository URL (and path), empty when unknown
+}
+
+// gitopsIndex resolves GitOps owners of workloads
+type gitopsIndex struct {
+	workloads map[workloadKey]gitopsOwner // Owners detected on workload objects
+	repos     map[string]string           // Repository per tool + app
+}
+
+// gitopsOwnerFromMetadata detects a GitOps owner from object labels and annotations
+func gitopsOwnerFromMetadata(labels, annotations map[string]string) (gitopsOwner, bool) {
+	if id := annotations[ArgoTrackingIDAnnotation]; id != "" {
+		app, _, _ := strings.Cut(id, ":")
+		return gitopsOwner{tool: GitOpsToolArgoCD, app: app}, true
+	}
+	if app := labels[ArgoInstanceLabel]; app != "" {
+		return gitopsOwner{tool: GitOpsToolArgoCD, app: app}, true
+	}
+	if name := labels[FluxKustomizationName]; name != "" {
+		return gitopsOwner{tool: GitOpsToolFlux, app: "Kustomization " + labels[FluxKustomizationNS] + "/" + name}, true
+	}
+	if name := labels[FluxHelmReleaseName]; name != "" {
+		return gitopsOwner{tool: GitOpsToolFlux, app: "HelmRelease " + labels[FluxHelmReleaseNS] + "/" + name}, true
+	}
+	return gitopsOwner{}, false
+}
+
+// resolve returns the GitOps owner of a pod: the owner detected on its workload,
+// else labels/annotations on the pod itself. Nil-safe.
+func (g *gitopsIndex) resolve(workload workloadKey, podLabels, podAnnotations map[string]string) (gitopsOwner, bool) {
+	if g == nil {
+		return gitopsOwner{}, false
+	}
+	owner, ok := g.workloads[workload]
+	if !ok {
+		owner, ok = gitopsOwnerFromMetadata(podLabels, podAnnotations)
+	}
+	if ok {
+		owner.repo = g.repos[owner.tool+"|"+owner.app]
+	}
+	return owner, ok
+}
+
+// loadGitOpsIndex detects GitOps owners on Deployments, StatefulSets and DaemonSets and
+// resolves their repositories from Argo CD Applications and Flux sources when readable
+func loadGitOpsIndex(ctx context.Context, clientSet kubernetes.Interface, dyn dynamic.Interface, namespace string) (*gitopsIndex, error) {
+	index := &gitopsIndex{
+		workloads: make(map[workloadKey]gitopsOwner),
+		repos:     make(map[string]string),
+	}
+
+	add := func(kind string, meta metav1.ObjectMeta) {
+		if owner, ok := gitopsOwnerFromMetadata(meta.Labels, meta.Annotations); ok {
+			index.workloads[workloadKey{namespace: meta.Namespace, kind: kind, name: meta.Name}] = owner
+		}
+	}
+
+	apps := clientSet.AppsV1()
+	deployments, err := apps.Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments: %w", err)
+	}
+	for _, d := range deployments.Items {
+		add("Deployment", d.ObjectMeta)
+	}
+	statefulSets, err := apps.StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets: %w", err)
+	}
+	for _, s := range statefulSets.Items {
+		add("StatefulSet", s.ObjectMeta)
+	}
+	daemonSets, err := apps.DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daemonsets: %w", err)
+	}
+	for _, d := range daemonSets.Items {
+		add("DaemonSet", d.ObjectMeta)
+	}
+
+	// Repositories are optional: the CRDs may not be installed or readable
+	if dyn != nil {
+		for key, repo := range gitopsRepos(ctx, dyn) {
+			index.repos[key] = repo
+		}
+	}
+
+	return index, nil
+}
+
+// gitopsRepos lists Argo CD Applications and Flux Kustomizations/HelmReleases
+// and maps tool + app to the source repository
+func gitopsRepos(ctx context.Context, dyn dynamic.Interface) map[string]string {
+	repos := make(map[string]string)
+	list := func(gvr schema.GroupVersionResource) []unstructured.Unstructured {
+		objects, err := dyn.Resource(gvr).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logrus.Debugf("GitOps repository lookup skipped for %s: %v", gvr.Resource, err)
+			return nil
+		}
+		return objects.Items
+	}
+
+	for _, app := range list(argoApplicationsGVR) {
+		if repo := argoApplicationRepo(&app); repo != "" {
+			repos[GitOpsToolArgoCD+"|"+app.GetName()] = repo
+			// Applications outside the control plane namespace are tracked as "<namespace>_<name>"
+			repos[GitOpsToolArgoCD+"|"+app.GetNamespace()+"_"+app.GetName()] = repo
+		}
+	}
+
+	sources := make(map[string]string) // "<Kind> <namespace>/<name>" -> URL
+	for kind, gvr := range fluxSourceGVRs {
+		for _, src := range list(gvr) {
+			if url, _, _ := unstructured.NestedString(src.Object, "spec", "url"); url != "" {
+				sources[kind+" "+src.GetNamespace()+"/"+src.GetName()] = url
+			}
+		}
+	}
+	for _, ks := range list(fluxKustomizationsGVR) {
+		if repo := fluxRepo(&ks, sources, "spec", "sourceRef"); repo != "" {
+			repos[GitOpsToolFlux+"|Kustomization "+ks.GetNamespace()+"/"+ks.GetName()] = repo
+		}
+	}
+	for _, hr := range list(fluxHelmReleasesGVR) {
+		if repo := fluxRepo(&hr, sources, "spec", "chart", "spec", "sourceRef"); repo != "" {
+			repos[GitOpsToolFlux+"|HelmRelease "+hr.GetNamespace()+"/"+hr.GetName()] = repo
+		}
+	}
+
+	return repos
+}
+
+// argoApplicationRepo returns "<repoURL> (<path>)" of an Argo CD Application's (first) source
+func argoApplicationRepo(app *unstructured.Unstructured) string {
+	source, found, _ := unstructured.NestedMap(app.Object, "spec", "source")
+	if !found {
+		sources, _, _ := unstructured.NestedSlice(app.Object, "spec", "sources")
+		if len(sources) == 0 {
+			return ""
+		}
+		source, _ = sources[0].(map[string]interface{})
+	}
+	repo, _, _ := unstructured.NestedString(source, "repoURL")
+	path, _, _ := unstructured.NestedString(source, "path")
+	return repoWithPath(repo, path)
+}
+
+// fluxRepo resolves the source URL referenced by a Flux object; spec.path is appended
+func fluxRepo(obj *unstructured.Unstructured, sources map[string]string, refPath ...string) string {
+	ref, found, _ := unstructured.NestedStringMap(obj.Object, refPath...)
+	if !found {
+		return ""
+	}
+	namespace := ref["namespace"]
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+	path, _, _ := unstructured.NestedString(obj.Object, "spec", "path")
+	return repoWithPath(sources[ref["kind"]+" "+namespace+"/"+ref["name"]], path)
+}
+
+// repoWithPath formats a repository URL with an optional path
+func repoWithPath(repo, path string) string {
+	path = strings.TrimPrefix(path, "./")
+	if repo == "" || path == "" || path == "." {
+		return repo
+	}
+	return fmt.Sprintf("%s (%s)", repo, path)
+}
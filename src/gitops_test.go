@@ -0,0 +1,161 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGitopsOwnerFromMetadata(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        gitopsOwner
+		wantOK      bool
+	}{
+		{
+			name:        "argo tracking id",
+			annotations: map[string]string{ArgoTrackingIDAnnotation: "shop:apps/Deployment:shop/web"},
+			want:        gitopsOwner{tool: GitOpsToolArgoCD, app: "shop"},
+			wantOK:      true,
+		},
+		{
+			name:        "tracking id wins over instance label",
+			labels:      map[string]string{ArgoInstanceLabel: "legacy"},
+			annotations: map[string]string{ArgoTrackingIDAnnotation: "shop:apps/Deployment:shop/web"},
+			want:        gitopsOwner{tool: GitOpsToolArgoCD, app: "shop"},
+			wantOK:      true,
+		},
+		{
+			name:   "argo instance label",
+			labels: map[string]string{ArgoInstanceLabel: "monitoring"},
+			want:   gitopsOwner{tool: GitOpsToolArgoCD, app: "monitoring"},
+			wantOK: true,
+		},
+		{
+			name:   "flux kustomization",
+			labels: map[string]string{FluxKustomizationName: "apps", FluxKustomizationNS: "flux-system"},
+			want:   gitopsOwner{tool: GitOpsToolFlux, app: "Kustomization flux-system/apps"},
+			wantOK: true,
+		},
+		{
+			name:   "flux helm release",
+			labels: map[string]string{FluxHelmReleaseName: "redis", FluxHelmReleaseNS: "cache"},
+			want:   gitopsOwner{tool: GitOpsToolFlux, app: "HelmRelease cache/redis"},
+			wantOK: true,
+		},
+		{
+			name:   "helm instance label is not a gitops owner",
+			labels: map[string]string{"app.kubernetes.io/instance": "redis"},
+		},
+		{name: "no metadata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := gitopsOwnerFromMetadata(tt.labels, tt.annotations)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("gitopsOwnerFromMetadata() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGitopsIndexResolve(t *testing.T) {
+	web := workloadKey{namespace: "shop", kind: "Deployment", name: "web"}
+	index := &gitopsIndex{
+		workloads: map[workloadKey]gitopsOwner{web: {tool: GitOpsToolArgoCD, app: "shop"}},
+		repos:     map[string]string{GitOpsToolArgoCD + "|shop": "https://git.example.com/shop.git (deploy)"},
+	}
+
+	got, ok := index.resolve(web, nil, nil)
+	if !ok || got.app != "shop" || got.repo != "https://git.example.com/shop.git (deploy)" {
+		t.Errorf("resolve(workload) = %+v, %v", got, ok)
+	}
+
+	// Pods of workloads without GitOps metadata fall back to their own labels
+	job := workloadKey{namespace: "shop", kind: "Job", name: "migrate"}
+	got, ok = index.resolve(job, map[string]string{FluxKustomizationName: "jobs", FluxKustomizationNS: "flux-system"}, nil)
+	if !ok || got.app != "Kustomization flux-system/jobs" || got.repo != "" {
+		t.Errorf("resolve(pod labels) = %+v, %v", got, ok)
+	}
+
+	var nilIndex *gitopsIndex
+	if _, ok := nilIndex.resolve(web, nil, nil); ok {
+		t.Error("nil index resolved an owner")
+	}
+}
+
+func TestArgoApplicationRepo(t *testing.T) {
+	tests := []struct {
+		name string
+		spec map[string]interface{}
+		want string
+	}{
+		{
+			name: "single source",
+			spec: map[string]interface{}{"source": map[string]interface{}{"repoURL": "https://git.example.com/shop.git", "path": "deploy/prod"}},
+			want: "https://git.example.com/shop.git (deploy/prod)",
+		},
+		{
+			name: "helm chart without path",
+			spec: map[string]interface{}{"source": map[string]interface{}{"repoURL": "https://charts.example.com", "chart": "redis"}},
+			want: "https://charts.example.com",
+		},
+		{
+			name: "first of multiple sources",
+			spec: map[string]interface{}{"sources": []interface{}{
+				map[string]interface{}{"repoURL": "https://git.example.com/a.git", "path": "."},
+				map[string]interface{}{"repoURL": "https://git.example.com/b.git"},
+			}},
+			want: "https://git.example.com/a.git",
+		},
+		{name: "no source", spec: map[string]interface{}{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &unstructured.Unstructured{Object: map[string]interface{}{"spec": tt.spec}}
+			if got := argoApplicationRepo(app); got != tt.want {
+				t.Errorf("argoApplicationRepo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFluxRepo(t *testing.T) {
+	sources := map[string]string{
+		"GitRepository flux-system/fleet": "ssh://git@git.example.com/fleet.git",
+		"HelmRepository cache/bitnami":    "https://charts.bitnami.com/bitnami",
+	}
+
+	ks := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "apps", "namespace": "flux-system"},
+		"spec": map[string]interface{}{
+			"path":      "./clusters/prod",
+			"sourceRef": map[string]interface{}{"kind": "GitRepository", "name": "fleet"},
+		},
+	}}
+	if got, want := fluxRepo(ks, sources, "spec", "sourceRef"), "ssh://git@git.example.com/fleet.git (clusters/prod)"; got != want {
+		t.Errorf("fluxRepo(kustomization) = %q, want %q", got, want)
+	}
+
+	hr := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "redis", "namespace": "cache"},
+		"spec": map[string]interface{}{"chart": map[string]interface{}{"spec": map[string]interface{}{
+			"chart":     "redis",
+			"sourceRef": map[string]interface{}{"kind": "HelmRepository", "name": "bitnami"},
+		}}},
+	}}
+	if got, want := fluxRepo(hr, sources, "spec", "chart", "spec", "sourceRef"), "https://charts.bitnami.com/bitnami"; got != want {
+		t.Errorf("fluxRepo(helmrelease) = %q, want %q", got, want)
+	}
+
+	missing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"sourceRef": map[string]interface{}{"kind": "GitRepository", "name": "unknown"}},
+	}}
+	if got := fluxRepo(missing, sources, "spec", "sourceRef"); got != "" {
+		t.Errorf("fluxRepo(unknown source) = %q, want empty", got)
+	}
+}
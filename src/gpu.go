@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NVIDIA device plugin resources and GPU feature discovery node labels
+const (
+	GPUResource       = "nvidia.com/gpu"
+	GPUSharedResource = "nvidia.com/gpu.shared"   // Time-sliced replicas with renameByDefault
+	MIGResourcePrefix = "nvidia.com/mig-"         // MIG mixed strategy, e.g. nvidia.com/mig-1g.5gb
+	GPUReplicasLabel  = "nvidia.com/gpu.replicas" // Time-slicing replicas per physical GPU
+	GPUProductLabel   = "nvidia.com/gpu.product"  // Ends in -MIG-<profile> with the MIG single strategy
+	MIGComputeSlices  = 7                         // Compute slices of a full A100/H100
+)
+
+// GPU sharing modes on the GPU Sharing section
+const (
+	GPUExclusive   = "Exclusive"
+	GPUMIG         = "MIG"
+	GPUTimeSlicing = "Time-slicing"
+)
+
+// isGPUResource reports whether a resource is a whole, time-sliced or MIG GPU
+func isGPUResource(name corev1.ResourceName) bool {
+	return name == GPUResource || name == GPUSharedResource || strings.HasPrefix(string(name), MIGResourcePrefix)
+}
+
+// migFraction returns the share of a physical GPU of a MIG profile such as
+// "3g.20gb" or "1g.10gb+me", counted in compute slices
+func migFraction(profile string) (float64, bool) {
+	slices, _, ok := strings.Cut(profile, "g.")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(slices)
+	if err != nil || n < 1 || n > MIGComputeSlices {
+		return 0, false
+	}
+	return float64(n) / MIGComputeSlices, true
+}
+
+// gpuUnit returns the physical GPUs one unit of a GPU resource stands for and
+// how the GPU is shared. node may be nil for pods not scheduled to a known node.
+func gpuUnit(node *corev1.Node, name corev1.ResourceName) (float64, []string) {
+	if profile, ok := strings.CutPrefix(string(name), MIGResourcePrefix); ok {
+		if fraction, ok := migFraction(profile); ok {
+			return fraction, []string{GPUMIG}
+		}
+		return 1, []string{GPUMIG}
+	}
+	if node == nil {
+		return 1, nil
+	}
+	fraction, modes := 1.0, []string(nil)
+	if _, profile, ok := strings.Cut(node.Labels[GPUProductLabel], "-MIG-"); ok {
+		if f, ok := migFraction(profile); ok {
+			fraction, modes = f, append(modes, GPUMIG)
+		}
+	}
+	replicas := node.Labels[GPUReplicasLabel]
+	if replicas == "" {
+		replicas = node.Annotations[GPUReplicasLabel]
+	}
+	if n, err := strconv.Atoi(replicas); err == nil && n > 1 {
+		fraction, modes = fraction/float64(n), append(modes, GPUTimeSlicing)
+	}
+	return fraction, modes
+}
+
+// gpuShare is a GPU resource in device plugin units and in physical GPUs
+type gpuShare struct {
+	resource          corev1.ResourceName
+	sharing           string // Sharing modes of the nodes, e.g. "MIG" or "Time-slicing"
+	allocatable       float64
+	request           float64
+	allocGPUs         float64 // Physical GPU equivalents
+	reqGPUs           float64
+	namespaceRequests map[string]float64 // Physical GPU equivalents
+}
+
+// gpuShares converts the GPU resources of nodes and active pods into physical
+// GPUs, so a MIG slice or time-sliced replica is not counted as a whole GPU.
+// Container limits stand in for requests, as Kubernetes defaults them.
+func gpuShares(pods []corev1.Pod, nodes *corev1.NodeList) []gpuShare {
+	byName := make(map[corev1.ResourceName]*gpuShare)
+	modes := make(map[corev1.ResourceName]map[string]bool)
+	share := func(name corev1.ResourceName) *gpuShare {
+		s, ok := byName[name]
+		if !ok {
+			s = &gpuShare{resource: name, namespaceRequests: make(map[string]float64)}
+			byName[name] = s
+			modes[name] = make(map[string]bool)
+		}
+		return s
+	}
+
+	nodeByName := make(map[string]*corev1.Node)
+	if nodes != nil {
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			nodeByName[node.Name] = node
+			for name, q := range node.Status.Allocatable {
+				if !isGPUResource(name) || q.IsZero() {
+					continue
+				}
+				fraction, sharing := gpuUnit(node, name)
+				s := share(name)
+				s.allocatable += q.AsApproximateFloat64()
+				s.allocGPUs += q.AsApproximateFloat64() * fraction
+				for _, mode := range sharing {
+					modes[name][mode] = true
+				}
+			}
+		}
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			requested := c.Resources.Requests
+			if len(requested) == 0 {
+				requested = c.Resources.Limits
+			}
+			for name, q := range requested {
+				if !isGPUResource(name) || q.IsZero() {
+					continue
+				}
+				fraction, _ := gpuUnit(nodeByName[pod.Spec.NodeName], name)
+				s := share(name)
+				s.request += q.AsApproximateFloat64()
+				s.reqGPUs += q.AsApproximateFloat64() * fraction
+				s.namespaceRequests[pod.Namespace] += q.AsApproximateFloat64() * fraction
+			}
+		}
+	}
+
+	shares := make([]gpuShare, 0, len(byName))
+	for name, s := range byName {
+		sharing := make([]string, 0, len(modes[name]))
+		for mode := range modes[name] {
+			sharing = append(sharing, mode)
+		}
+		if len(sharing) == 0 {
+			sharing = append(sharing, GPUExclusive)
+		}
+		sort.Strings(sharing)
+		s.sharing = strings.Join(sharing, ", ")
+		shares = append(shares, *s)
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].resource < shares[j].resource })
+	return shares
+}
+
+// writeGPUSharing appends the GPU Sharing section at row: units and physical
+// GPUs per GPU resource with a total, then physical GPUs requested per
+// namespace. It returns the row after the section.
+func writeGPUSharing(f *excelize.File, shares []gpuShare, sheetName string, row int) (int, error) {
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "GPU Sharing (physical GPU equivalents)")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
+	row++
+	headers := []interface{}{"Resource", "Sharing", "Allocatable Units", "Physical GPUs", "Requested Units", "Requested GPUs", "Requested %"}
+	if err := export.SetRow(f, sheetName, row, headers, "GPU sharing headers"); err != nil {
+		return row, err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), export.BoldStyle(f))
+	row++
+
+	decimalStyle := export.DecimalStyle(f, false)
+	percentStyle := export.PercentStyle(f, "0.0%")
+	var allocGPUs, reqGPUs float64
+	namespaces := make(map[string]float64)
+	for _, s := range shares {
+		var requested interface{}
+		if s.allocGPUs > 0 {
+			requested = s.reqGPUs / s.allocGPUs
+		}
+		data := []interface{}{string(s.resource), s.sharing, s.allocatable, s.allocGPUs, s.request, s.reqGPUs, requested}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("GPU resource '%s'", s.resource)); err != nil {
+			return row, err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("F%d", row), decimalStyle)
+		f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("G%d", row), percentStyle)
+		allocGPUs += s.allocGPUs
+		reqGPUs += s.reqGPUs
+		for ns, gpus := range s.namespaceRequests {
+			namespaces[ns] += gpus
+		}
+		row++
+	}
+	var requested interface{}
+	if allocGPUs > 0 {
+		requested = reqGPUs / allocGPUs
+	}
+	if err := export.SetRow(f, sheetName, row, []interface{}{"Total", "", nil, allocGPUs, nil, reqGPUs, requested}, "GPU sharing total"); err != nil {
+		return row, err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("F%d", row), export.DecimalStyle(f, true))
+	f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("G%d", row), percentStyle)
+	row += 2
+
+	if err := export.SetRow(f, sheetName, row, []interface{}{"Namespace", "Requested GPUs"}, "GPU namespace headers"); err != nil {
+		return row, err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), export.BoldStyle(f))
+	row++
+	names := make([]string, 0, len(namespaces))
+	for ns := range namespaces {
+		names = append(names, ns)
+	}
+	sort.Strings(names)
+	for _, ns := range names {
+		if err := export.SetRow(f, sheetName, row, []interface{}{ns, namespaces[ns]}, fmt.Sprintf("GPU namespace '%s'", ns)); err != nil {
+			return row, err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("B%d", row), decimalStyle)
+		row++
+	}
+	return row, nil
+}
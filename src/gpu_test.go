@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMIGFraction(t *testing.T) {
+	tests := []struct {
+		profile string
+		want    float64
+		ok      bool
+	}{
+		{"1g.5gb", 1.0 / 7, true},
+		{"3g.20gb", 3.0 / 7, true},
+		{"1g.10gb+me", 1.0 / 7, true},
+		{"7g.80gb", 1, true},
+		{"8g.80gb", 0, false},
+		{"gpu", 0, false},
+	}
+	for _, tt := range tests {
+		if got, ok := migFraction(tt.profile); ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("migFraction(%q) = %v, %v; want %v, %v", tt.profile, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestGPUShares(t *testing.T) {
+	node := func(name string, labels map[string]string, allocatable corev1.ResourceList) corev1.Node {
+		return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}, Status: corev1.NodeStatus{Allocatable: allocatable}}
+	}
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		node("whole", nil, corev1.ResourceList{GPUResource: resource.MustParse("2")}),
+		node("sliced", map[string]string{GPUReplicasLabel: "4"}, corev1.ResourceList{GPUResource: resource.MustParse("8")}), // 2 GPUs
+		node("mig", nil, corev1.ResourceList{"nvidia.com/mig-1g.5gb": resource.MustParse("7")}),                             // 1 GPU
+	}}
+	pod := func(namespace, nodeName string, name corev1.ResourceName, count string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: namespace + "-" + nodeName},
+			Spec: corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{Limits: corev1.ResourceList{name: resource.MustParse(count)}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("train", "whole", GPUResource, "1"),
+		pod("infer", "sliced", GPUResource, "2"),          // Half a GPU
+		pod("infer", "mig", "nvidia.com/mig-1g.5gb", "3"), // 3/7 GPU
+		pod("notebook", "", "nvidia.com/mig-1g.5gb", "1"), // Pending, still a slice
+	}
+
+	shares := gpuShares(pods, nodes)
+	if len(shares) != 2 {
+		t.Fatalf("gpuShares() = %+v, want gpu and mig-1g.5gb", shares)
+	}
+	gpu, mig := shares[0], shares[1]
+	if gpu.resource != GPUResource || gpu.sharing != GPUTimeSlicing || gpu.allocatable != 10 || gpu.allocGPUs != 4 || gpu.request != 3 || gpu.reqGPUs != 1.5 {
+		t.Errorf("gpu = %+v, want 10 units as 4 GPUs, 1.5 requested", gpu)
+	}
+	if mig.sharing != GPUMIG || mig.allocGPUs != 1 || math.Abs(mig.reqGPUs-4.0/7) > 1e-9 || math.Abs(mig.namespaceRequests["infer"]-3.0/7) > 1e-9 {
+		t.Errorf("mig = %+v, want 4 of 7 slices requested", mig)
+	}
+
+	single := node("single", map[string]string{GPUProductLabel: "NVIDIA-A100-SXM4-40GB-MIG-3g.20gb"}, nil)
+	if fraction, modes := gpuUnit(&single, GPUResource); math.Abs(fraction-3.0/7) > 1e-9 || len(modes) != 1 || modes[0] != GPUMIG {
+		t.Errorf("gpuUnit(single strategy) = %v, %v; want 3/7 MIG", fraction, modes)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	next, err := writeGPUSharing(f, shares, "Sheet1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next != 11 {
+		t.Errorf("writeGPUSharing() next row = %d, want 11", next)
+	}
+	for cell, want := range map[string]string{"A3": GPUResource, "B3": GPUTimeSlicing, "D3": "4.00", "A5": "Total", "D5": "5.00", "A7": "Namespace", "A8": "infer", "A9": "notebook", "A10": "train"} {
+		if got, _ := f.GetCellValue("Sheet1", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
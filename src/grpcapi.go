@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+
+	"github.com/ohauer/PodResourceCalculator/proto/snapshotsv1"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcServer implements the Snapshots service of proto/snapshots.proto on the
+// REST API views of the report server
+type grpcServer struct {
+	snapshotsv1.UnimplementedSnapshotsServer
+	views func() *apiViews // Current views, nil before the first snapshot
+}
+
+// GetSnapshot returns every view of the current snapshot
+func (g *grpcServer) GetSnapshot(context.Context, *snapshotsv1.GetSnapshotRequest) (*snapshotsv1.Snapshot, error) {
+	views := g.views()
+	if views == nil {
+		return nil, status.Error(codes.Unavailable, "no snapshot collected yet")
+	}
+	snapshot := &snapshotsv1.Snapshot{Generated: timestamppb.New(views.generated)}
+	for _, ns := range views.namespaces {
+		snapshot.Namespaces = append(snapshot.Namespaces, grpcNamespace(ns))
+	}
+	for _, node := range views.nodes {
+		snapshot.Nodes = append(snapshot.Nodes, grpcNode(node))
+	}
+	for _, r := range views.recommendations {
+		snapshot.Recommendations = append(snapshot.Recommendations, grpcRecommendation(r))
+	}
+	return snapshot, nil
+}
+
+// ListNamespaces returns the page of GET /api/v1/namespaces
+func (g *grpcServer) ListNamespaces(_ context.Context, req *snapshotsv1.ListNamespacesRequest) (*snapshotsv1.ListNamespacesResponse, error) {
+	q := url.Values{"name": {req.GetName()}, "phase": {req.GetPhase()}}
+	page, err := g.query(PathAPINamespaces, q, req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+	resp := &snapshotsv1.ListNamespacesResponse{
+		Generated: timestamppb.New(page.Generated), Total: int32(page.Total), Offset: int32(page.Offset),
+		Limit: int32(page.Limit), NextOffset: grpcNextOffset(page),
+	}
+	for _, item := range page.Items.([]interface{}) {
+		resp.Items = append(resp.Items, grpcNamespace(item.(apiNamespace)))
+	}
+	return resp, nil
+}
+
+// ListNodes returns the page of GET /api/v1/nodes
+func (g *grpcServer) ListNodes(_ context.Context, req *snapshotsv1.ListNodesRequest) (*snapshotsv1.ListNodesResponse, error) {
+	q := url.Values{"name": {req.GetName()}, "pool": {req.GetPool()}}
+	page, err := g.query(PathAPINodes, q, req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+	resp := &snapshotsv1.ListNodesResponse{
+		Generated: timestamppb.New(page.Generated), Total: int32(page.Total), Offset: int32(page.Offset),
+		Limit: int32(page.Limit), NextOffset: grpcNextOffset(page),
+	}
+	for _, item := range page.Items.([]interface{}) {
+		resp.Items = append(resp.Items, grpcNode(item.(apiNode)))
+	}
+	return resp, nil
+}
+
+// ListRecommendations returns the page of GET /api/v1/recommendations
+func (g *grpcServer) ListRecommendations(_ context.Context, req *snapshotsv1.ListRecommendationsRequest) (*snapshotsv1.ListRecommendationsResponse, error) {
+	q := url.Values{"severity": {req.GetSeverity()}, "rule": {req.GetRule()}, "subject": {req.GetSubject()}}
+	page, err := g.query(PathAPIRecommendations, q, req.GetLimit(), req.GetOffset())
+	if err != nil {
+		return nil, err
+	}
+	resp := &snapshotsv1.ListRecommendationsResponse{
+		Generated: timestamppb.New(page.Generated), Total: int32(page.Total), Offset: int32(page.Offset),
+		Limit: int32(page.Limit), NextOffset: grpcNextOffset(page),
+	}
+	for _, item := range page.Items.([]interface{}) {
+		resp.Items = append(resp.Items, grpcRecommendation(item.(findingsRecord)))
+	}
+	return resp, nil
+}
+
+// query returns the page the REST endpoint at path would return for the same
+// filters; a limit of 0 selects the default page size
+func (g *grpcServer) query(path string, q url.Values, limit, offset int32) (apiPage, error) {
+	views := g.views()
+	if views == nil {
+		return apiPage{}, status.Error(codes.Unavailable, "no snapshot collected yet")
+	}
+	if limit != 0 {
+		q.Set("limit", strconv.Itoa(int(limit)))
+	}
+	if offset != 0 {
+		q.Set("offset", strconv.Itoa(int(offset)))
+	}
+	for _, route := range apiRoutes {
+		if route.path != path {
+			continue
+		}
+		page, err := views.query(route, q)
+		if err != nil {
+			return apiPage{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return page, nil
+	}
+	return apiPage{}, status.Errorf(codes.Internal, "no API route %s", path)
+}
+
+// grpcNextOffset returns the offset of the next page, nil on the last page
+func grpcNextOffset(page apiPage) *int32 {
+	if page.NextOffset == nil {
+		return nil
+	}
+	return proto.Int32(int32(*page.NextOffset))
+}
+
+// grpcNamespace converts a namespace item to its message
+func grpcNamespace(ns apiNamespace) *snapshotsv1.NamespaceTotals {
+	return &snapshotsv1.NamespaceTotals{
+		Name:                 ns.Name,
+		Phase:                ns.Phase,
+		RequestCpuMillicores: ns.RequestCPUMillicores,
+		LimitCpuMillicores:   ns.LimitCPUMillicores,
+		RequestMemoryBytes:   ns.RequestMemoryBytes,
+		LimitMemoryBytes:     ns.LimitMemoryBytes,
+	}
+}
+
+// grpcNode converts a node item to its message
+func grpcNode(node apiNode) *snapshotsv1.NodeTotals {
+	return &snapshotsv1.NodeTotals{
+		Name:                     node.Name,
+		Ip:                       node.IP,
+		Pool:                     node.Pool,
+		Pods:                     int32(node.Pods),
+		AllocatableCpuMillicores: node.AllocatableCPUMillicores,
+		RequestCpuMillicores:     node.RequestCPUMillicores,
+		LimitCpuMillicores:       node.LimitCPUMillicores,
+		AllocatableMemoryBytes:   node.AllocatableMemoryBytes,
+		RequestMemoryBytes:       node.RequestMemoryBytes,
+		LimitMemoryBytes:         node.LimitMemoryBytes,
+		CpuRequestRatio:          node.CPURequestRatio,
+		MemoryRequestRatio:       node.MemoryRequestRatio,
+	}
+}
+
+// grpcRecommendation converts a finding to its message
+func grpcRecommendation(r findingsRecord) *snapshotsv1.Recommendation {
+	return &snapshotsv1.Recommendation{
+		Rule:     r.Rule,
+		Severity: r.Severity,
+		Subject:  r.Subject,
+		Message:  r.Message,
+		Action:   r.Action,
+		Owner:    r.Owner,
+	}
+}
+
+// serveGRPC serves the gRPC service of s, with server reflection for tools
+// like grpcurl, on addr until ctx is done
+func serveGRPC(ctx context.Context, addr string, s *reportServer) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC: %w", err)
+	}
+	server := newGRPCServer(s.apiViews)
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			logrus.Errorf("gRPC server failed: %v", err)
+		}
+	}()
+	logrus.Infof("Serving gRPC on %s", listener.Addr())
+	return nil
+}
+
+// newGRPCServer returns a gRPC server with the Snapshots service on views and
+// server reflection registered
+func newGRPCServer(views func() *apiViews) *grpc.Server {
+	server := grpc.NewServer()
+	snapshotsv1.RegisterSnapshotsServer(server, &grpcServer{views: views})
+	reflection.Register(server)
+	return server
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/proto/snapshotsv1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCService(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	rules, _ := parseValidationRules(validationSpec{})
+	s := &reportServer{}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := newGRPCServer(s.apiViews)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := snapshotsv1.NewSnapshotsClient(conn)
+	ctx := context.Background()
+
+	if _, err := client.GetSnapshot(ctx, &snapshotsv1.GetSnapshotRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("GetSnapshot before the first build = %v, want Unavailable", err)
+	}
+	if _, err := client.ListNamespaces(ctx, &snapshotsv1.ListNamespacesRequest{}); status.Code(err) != codes.Unavailable {
+		t.Errorf("ListNamespaces before the first build = %v, want Unavailable", err)
+	}
+
+	s.views = buildAPIViews(testAPISnapshot(now), rules, reportMetadata{})
+	tests := []struct {
+		name  string
+		call  func() (int32, error) // Returns the total
+		code  codes.Code
+		total int32
+	}{
+		{"namespaces", func() (int32, error) {
+			resp, err := client.ListNamespaces(ctx, &snapshotsv1.ListNamespacesRequest{})
+			return resp.GetTotal(), err
+		}, codes.OK, 2},
+		{"namespaces by name", func() (int32, error) {
+			resp, err := client.ListNamespaces(ctx, &snapshotsv1.ListNamespacesRequest{Name: "sh"})
+			return resp.GetTotal(), err
+		}, codes.OK, 1},
+		{"nodes by pool", func() (int32, error) {
+			resp, err := client.ListNodes(ctx, &snapshotsv1.ListNodesRequest{Pool: "batch"})
+			return resp.GetTotal(), err
+		}, codes.OK, 1},
+		{"warnings", func() (int32, error) {
+			resp, err := client.ListRecommendations(ctx, &snapshotsv1.ListRecommendationsRequest{Severity: "warn"})
+			return resp.GetTotal(), err
+		}, codes.OK, 2},
+		{"unknown severity", func() (int32, error) {
+			resp, err := client.ListRecommendations(ctx, &snapshotsv1.ListRecommendationsRequest{Severity: "fatal"})
+			return resp.GetTotal(), err
+		}, codes.InvalidArgument, 0},
+		{"negative limit", func() (int32, error) {
+			resp, err := client.ListNodes(ctx, &snapshotsv1.ListNodesRequest{Limit: -1})
+			return resp.GetTotal(), err
+		}, codes.InvalidArgument, 0},
+		{"negative offset", func() (int32, error) {
+			resp, err := client.ListNodes(ctx, &snapshotsv1.ListNodesRequest{Offset: -1})
+			return resp.GetTotal(), err
+		}, codes.InvalidArgument, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, err := tt.call()
+			if code := status.Code(err); code != tt.code {
+				t.Fatalf("error = %v, want %v", err, tt.code)
+			}
+			if total != tt.total {
+				t.Errorf("total = %d, want %d", total, tt.total)
+			}
+		})
+	}
+
+	// Pages match the REST API
+	nodes, err := client.ListNodes(ctx, &snapshotsv1.ListNodesRequest{Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes.GetItems()) != 1 || nodes.GetLimit() != 1 || nodes.NextOffset == nil || nodes.GetNextOffset() != 1 {
+		t.Errorf("ListNodes(limit=1) = %v", nodes)
+	}
+	if got := nodes.GetGenerated().AsTime(); !got.Equal(now) {
+		t.Errorf("generated = %v, want %v", got, now)
+	}
+	last, err := client.ListNodes(ctx, &snapshotsv1.ListNodesRequest{Offset: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.NextOffset != nil || len(last.GetItems()) != 1 {
+		t.Errorf("ListNodes(offset=1) = %v", last)
+	}
+
+	snapshot, err := client.GetSnapshot(ctx, &snapshotsv1.GetSnapshotRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.GetNamespaces()) != len(s.views.namespaces) || len(snapshot.GetNodes()) != len(s.views.nodes) ||
+		len(snapshot.GetRecommendations()) != len(s.views.recommendations) {
+		t.Errorf("GetSnapshot() = %v", snapshot)
+	}
+	for i, ns := range snapshot.GetNamespaces() {
+		want := s.views.namespaces[i]
+		if ns.GetName() != want.Name || ns.GetRequestCpuMillicores() != want.RequestCPUMillicores || ns.GetLimitMemoryBytes() != want.LimitMemoryBytes {
+			t.Errorf("namespace %d = %v, want %+v", i, ns, want)
+		}
+	}
+	for i, node := range snapshot.GetNodes() {
+		want := s.views.nodes[i]
+		if node.GetIp() != want.IP || (node.CpuRequestRatio == nil) != (want.CPURequestRatio == nil) {
+			t.Errorf("node %d = %v, want %+v", i, node, want)
+		}
+	}
+
+	// Server reflection lists the service for grpcurl
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		found = found || service.GetName() == snapshotsv1.Snapshots_ServiceDesc.ServiceName
+	}
+	if !found {
+		t.Errorf("reflection services = %v", resp.GetListServicesResponse().GetService())
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultHeadroomPercent is the share of a node pool's requests kept free by
+// overprovisioning pause pods
+const DefaultHeadroomPercent = 10
+
+// poolHeadroom is the overprovisioning recommendation of one node pool: pause
+// pods sized like its largest pod, so evicting one frees room for any pod of the
+// pool without waiting for a new node
+type poolHeadroom struct {
+	pool                   string
+	nodes, pods            int
+	nodeCPU, nodeMem       int64 // Allocatable of the smallest node
+	reqCPU, reqMem         int64 // Requests of the scheduled pods
+	largestCPU, largestMem int64 // Largest pod requests
+	pauseCPU, pauseMem     int64 // Recommended pause pod requests
+	replicas               int
+	perNode                int // Pause pods fitting on the smallest empty node
+}
+
+// podRequests sums the requests of the containers that stay running in a pod
+func podRequests(pod *corev1.Pod) (cpu, mem int64) {
+	for _, c := range podContainers(pod) {
+		cpu += quantityMilli(c.Resources.Requests.Cpu())
+		mem += quantityBytes(c.Resources.Requests.Memory())
+	}
+	return cpu, mem
+}
+
+// headroomByPool recommends pause pod sizes and replicas per node pool. Pause
+// pods take the largest CPU and memory request of a scheduled pod in the pool,
+// capped at the smallest node, and cover percent of the pool's requests.
+func headroomByPool(pods []corev1.Pod, nodes *corev1.NodeList, percent int) []poolHeadroom {
+	if nodes == nil || len(nodes.Items) == 0 {
+		return nil
+	}
+
+	pools := make(map[string]*poolHeadroom)
+	nodePools := make(map[string]string, len(nodes.Items))
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		name := nodePool(node)
+		nodePools[node.Name] = name
+		p := pools[name]
+		if p == nil {
+			p = &poolHeadroom{pool: name}
+			pools[name] = p
+		}
+		p.nodes++
+		cpu, mem := node.Status.Allocatable.Cpu().MilliValue(), node.Status.Allocatable.Memory().Value()
+		if p.nodeCPU == 0 || cpu < p.nodeCPU {
+			p.nodeCPU = cpu
+		}
+		if p.nodeMem == 0 || mem < p.nodeMem {
+			p.nodeMem = mem
+		}
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) || pod.Spec.NodeName == "" {
+			continue
+		}
+		p := pools[nodePools[pod.Spec.NodeName]]
+		if p == nil {
+			continue
+		}
+		cpu, mem := podRequests(pod)
+		p.pods++
+		p.reqCPU += cpu
+		p.reqMem += mem
+		if cpu > p.largestCPU {
+			p.largestCPU = cpu
+		}
+		if mem > p.largestMem {
+			p.largestMem = mem
+		}
+	}
+
+	result := make([]poolHeadroom, 0, len(pools))
+	for _, p := range pools {
+		p.pauseCPU = min64(p.largestCPU, p.nodeCPU)
+		p.pauseMem = min64(p.largestMem, p.nodeMem)
+		if p.pauseCPU > 0 || p.pauseMem > 0 {
+			share := float64(percent) / 100
+			p.replicas = 1
+			for _, r := range []struct{ requests, size int64 }{{p.reqCPU, p.pauseCPU}, {p.reqMem, p.pauseMem}} {
+				if r.size == 0 {
+					continue
+				}
+				if n := int(math.Ceil(share * float64(r.requests) / float64(r.size))); n > p.replicas {
+					p.replicas = n
+				}
+			}
+			p.perNode = fitsPerNode(p.nodeCPU, p.pauseCPU)
+			if n := fitsPerNode(p.nodeMem, p.pauseMem); n < p.perNode {
+				p.perNode = n
+			}
+		}
+		result = append(result, *p)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].pool < result[j].pool })
+	return result
+}
+
+// fitsPerNode returns how often size fits into allocatable; a zero size does
+// not limit the count
+func fitsPerNode(allocatable, size int64) int {
+	if size == 0 {
+		return math.MaxInt32
+	}
+	return int(allocatable / size)
+}
+
+// min64 returns the smaller of a and b
+func min64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// createHeadroomSheet lists the pause pod recommendation per node pool
+func createHeadroomSheet(f *excelize.File, headroom []poolHeadroom, percent int, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create headroom sheet: %w", err)
+	}
+
+	headers := []string{
+		"Node Pool", "Nodes", "Pods", "Node Allocatable CPU (cores)", "Node Allocatable Memory (Gi)",
+		"Request CPU (cores)", "Request Memory (Gi)", "Largest Pod CPU (m)", "Largest Pod Memory (Mi)",
+		"Pause Pod CPU (m)", "Pause Pod Memory (Mi)", "Pause Pods", "Pause Pods per Node", "Headroom CPU (cores)", "Headroom Memory (Gi)",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, h := range headroom {
+		var perNode interface{}
+		if h.replicas > 0 {
+			perNode = h.perNode
+		}
+		data := []interface{}{
+			h.pool,
+			h.nodes,
+			h.pods,
+			milliToCores(h.nodeCPU),
+			bytesToGi(h.nodeMem),
+			milliToCores(h.reqCPU),
+			bytesToGi(h.reqMem),
+			h.largestCPU,
+			bytesToWholeMi(h.largestMem),
+			h.pauseCPU,
+			bytesToWholeMi(h.pauseMem),
+			h.replicas,
+			perNode,
+			milliToCores(h.pauseCPU * int64(h.replicas)),
+			bytesToGi(h.pauseMem * int64(h.replicas)),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("node pool '%s'", h.pool)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	if row > 2 {
+		last := row - 1
+		f.SetCellStyle(sheetName, "D2", fmt.Sprintf("G%d", last), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "H2", fmt.Sprintf("M%d", last), export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, "N2", fmt.Sprintf("O%d", last), export.DecimalStyle(f, false))
+	}
+	row++
+	notes := []string{
+		fmt.Sprintf("Pause pods are sized like the largest pod of the pool (capped at the smallest node) and cover %d%% of its requests (-headroom-percent).", percent),
+		"Run them as a Deployment of registry.k8s.io/pause with a negative PriorityClass: the scheduler evicts them for real pods, and the cluster autoscaler adds a node for the evicted pause pods in the background.",
+	}
+	for _, note := range notes {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), note)
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 24)
+	f.SetColWidth(sheetName, "B", "C", 10)
+	f.SetColWidth(sheetName, "D", "O", 20)
+
+	return nil
+}
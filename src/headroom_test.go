@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHeadroomByPool(t *testing.T) {
+	node := func(name, pool, cpu, mem string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(mem),
+			}},
+		}
+	}
+	pod := func(nodeName string, phase corev1.PodPhase, cpu, mem string) corev1.Pod {
+		return corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{testSidecarContainer("app", cpu, mem)}},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		node("general-1", "general", "4", "16Gi"),
+		node("general-2", "general", "8", "32Gi"),
+		node("big-1", "big", "2", "8Gi"),
+		node("empty-1", "spot", "4", "16Gi"),
+	}}
+	pods := []corev1.Pod{
+		pod("general-1", corev1.PodRunning, "1", "2Gi"),
+		pod("general-1", corev1.PodRunning, "500m", "6Gi"),
+		pod("general-2", corev1.PodRunning, "2", "4Gi"),
+		pod("general-2", corev1.PodRunning, "2", "4Gi"),
+		pod("general-2", corev1.PodSucceeded, "3", "12Gi"),
+		pod("big-1", corev1.PodRunning, "3", "4Gi"), // Larger than its node
+		pod("", corev1.PodPending, "1", "1Gi"),
+	}
+
+	got := headroomByPool(pods, nodes, 50)
+	if len(got) != 3 {
+		t.Fatalf("headroomByPool() returned %d pools, want 3: %+v", len(got), got)
+	}
+
+	big, general, spot := got[0], got[1], got[2]
+	if big.pauseCPU != 2000 || big.pauseMem != 4<<30 || big.replicas != 1 || big.perNode != 1 {
+		t.Errorf("big = %+v, want a pause pod capped at the node CPU", big)
+	}
+	// 50% of 5.5 cores / 2 cores = 2 replicas, 50% of 16Gi / 6Gi = 2 replicas
+	if general.nodes != 2 || general.pods != 4 || general.pauseCPU != 2000 || general.pauseMem != 6<<30 || general.replicas != 2 || general.perNode != 2 {
+		t.Errorf("general = %+v", general)
+	}
+	if spot.pods != 0 || spot.replicas != 0 {
+		t.Errorf("spot = %+v, want no pause pods without pods", spot)
+	}
+
+	if headroomByPool(pods, nil, 10) != nil {
+		t.Error("missing nodes must return no recommendation")
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DeploymentRevisionAnnotation holds the rollout revision of a Deployment's ReplicaSet
+const DeploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
+// resourceChange is a container whose requests or limits changed in a Deployment rollout
+type resourceChange struct {
+	workload      workloadKey
+	container     string
+	revision      int64
+	changedAt     time.Time
+	before, after corev1.ResourceRequirements
+}
+
+// deploymentRevision is one ReplicaSet of a Deployment's rollout history
+type deploymentRevision struct {
+	revision   int64
+	created    time.Time
+	containers map[string]corev1.ResourceRequirements
+}
+
+// resourceChanges compares consecutive Deployment revisions and returns container
+// resource changes rolled out at or after since, newest first
+func resourceChanges(replicaSets []appsv1.ReplicaSet, since time.Time) []resourceChange {
+	history := make(map[workloadKey][]deploymentRevision)
+	for _, rs := range replicaSets {
+		owner := deploymentOwner(&rs)
+		if owner == "" {
+			continue
+		}
+		revision, err := strconv.ParseInt(rs.Annotations[DeploymentRevisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		containers := make(map[string]corev1.ResourceRequirements, len(rs.Spec.Template.Spec.Containers))
+		for _, c := range rs.Spec.Template.Spec.Containers {
+			containers[c.Name] = c.Resources
+		}
+
+		key := workloadKey{namespace: rs.Namespace, kind: "Deployment", name: owner}
+		history[key] = append(history[key], deploymentRevision{
+			revision:   revision,
+			created:    rs.CreationTimestamp.Time,
+			containers: containers,
+		})
+	}
+
+	changes := make([]resourceChange, 0) // Non-nil: an empty result still creates the sheet
+	for key, revisions := range history {
+		sort.Slice(revisions, func(i, j int) bool { return revisions[i].revision < revisions[j].revision })
+
+		for i := 1; i < len(revisions); i++ {
+			prev, cur := revisions[i-1], revisions[i]
+			if cur.created.Before(since) {
+				continue
+			}
+			for name, after := range cur.containers {
+				before, existed := prev.containers[name]
+				if !existed || resourcesEqual(before, after) {
+					continue
+				}
+				changes = append(changes, resourceChange{
+					workload:  key,
+					container: name,
+					revision:  cur.revision,
+					changedAt: cur.created,
+					before:    before,
+					after:     after,
+				})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if !changes[i].changedAt.Equal(changes[j].changedAt) {
+			return changes[i].changedAt.After(changes[j].changedAt)
+		}
+		if changes[i].workload != changes[j].workload {
+			return changes[i].workload.String() < changes[j].workload.String()
+		}
+		return changes[i].container < changes[j].container
+	})
+	return changes
+}
+
+// deploymentOwner returns the name of the Deployment controlling a ReplicaSet
+func deploymentOwner(rs *appsv1.ReplicaSet) string {
+	for _, ref := range rs.OwnerReferences {
+		if ref.Kind == "Deployment" && (ref.Controller == nil || *ref.Controller) {
+			return ref.Name
+		}
+	}
+	return ""
+}
+
+// resourcesEqual compares CPU and memory requests and limits semantically ("1" == "1000m")
+func resourcesEqual(a, b corev1.ResourceRequirements) bool {
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if !quantityEqual(a.Requests, b.Requests, name) || !quantityEqual(a.Limits, b.Limits, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func quantityEqual(a, b corev1.ResourceList, name corev1.ResourceName) bool {
+	qa, okA := a[name]
+	qb, okB := b[name]
+	if okA != okB {
+		return false
+	}
+	return qa.Cmp(qb) == 0
+}
+
+// quantityString returns the canonical quantity or "-" when unset
+func quantityString(list corev1.ResourceList, name corev1.ResourceName) string {
+	if q, ok := list[name]; ok {
+		return q.String()
+	}
+	return "-"
+}
+
+// createResourceChangesSheet lists container resource changes from recent Deployment rollouts.
+// Only workloads present in the report are listed.
+func createResourceChangesSheet(f *excelize.File, changes []resourceChange, workloadTotals map[workloadKey]workloadTotal, location *time.Location, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create resource changes sheet: %w", err)
+	}
+
+	headers := []string{
+		"Workload", "Container", "Revision", "Changed At",
+		"Request CPU Before", "Request CPU After", "Limit CPU Before", "Limit CPU After",
+		"Request Memory Before", "Request Memory After", "Limit Memory Before", "Limit Memory After",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, change := range changes {
+		if _, ok := workloadTotals[change.workload]; !ok {
+			continue
+		}
+		data := []interface{}{
+			change.workload.String(),
+			change.container,
+			change.revision,
+			change.changedAt.In(location).Format("2006-01-02 15:04"),
+			quantityString(change.before.Requests, corev1.ResourceCPU),
+			quantityString(change.after.Requests, corev1.ResourceCPU),
+			quantityString(change.before.Limits, corev1.ResourceCPU),
+			quantityString(change.after.Limits, corev1.ResourceCPU),
+			quantityString(change.before.Requests, corev1.ResourceMemory),
+			quantityString(change.after.Requests, corev1.ResourceMemory),
+			quantityString(change.before.Limits, corev1.ResourceMemory),
+			quantityString(change.after.Limits, corev1.ResourceMemory),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("workload '%s'", change.workload)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", "B", 20)
+	f.SetColWidth(sheetName, "C", "D", 16)
+	f.SetColWidth(sheetName, "E", "L", 14)
+
+	return nil
+}
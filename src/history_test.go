@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testReplicaSet(deployment, revision string, created time.Time, cpu, memory string) appsv1.ReplicaSet {
+	controller := true
+	return appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              deployment + "-" + revision,
+			Namespace:         "shop",
+			Annotations:       map[string]string{DeploymentRevisionAnnotation: revision},
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences:   []metav1.OwnerReference{{Kind: "Deployment", Name: deployment, Controller: &controller}},
+		},
+		Spec: appsv1.ReplicaSetSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name: "app",
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse(cpu),
+								corev1.ResourceMemory: resource.MustParse(memory),
+							},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestResourceChanges(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -7)
+	day := 24 * time.Hour
+
+	replicaSets := []appsv1.ReplicaSet{
+		// web: CPU raised 2 days ago, then an image-only rollout yesterday
+		testReplicaSet("web", "1", now.Add(-30*day), "100m", "128Mi"),
+		testReplicaSet("web", "3", now.Add(-1*day), "250m", "128Mi"),
+		testReplicaSet("web", "2", now.Add(-2*day), "250m", "128Mi"),
+		// api: changed before the window
+		testReplicaSet("api", "1", now.Add(-30*day), "100m", "128Mi"),
+		testReplicaSet("api", "2", now.Add(-10*day), "100m", "256Mi"),
+		// worker: same values in a different notation
+		testReplicaSet("worker", "1", now.Add(-5*day), "1", "1Gi"),
+		testReplicaSet("worker", "2", now.Add(-1*day), "1000m", "1024Mi"),
+	}
+	// Unowned ReplicaSets are ignored
+	orphan := testReplicaSet("orphan", "2", now, "1", "1Gi")
+	orphan.OwnerReferences = nil
+	replicaSets = append(replicaSets, orphan)
+
+	changes := resourceChanges(replicaSets, since)
+	if len(changes) != 1 {
+		t.Fatalf("resourceChanges() returned %d changes, want 1: %+v", len(changes), changes)
+	}
+
+	got := changes[0]
+	if got.workload.String() != "shop/Deployment/web" || got.container != "app" || got.revision != 2 {
+		t.Errorf("resourceChanges()[0] = %s %s rev %d, want shop/Deployment/web app rev 2", got.workload, got.container, got.revision)
+	}
+	if before, after := quantityString(got.before.Requests, corev1.ResourceCPU), quantityString(got.after.Requests, corev1.ResourceCPU); before != "100m" || after != "250m" {
+		t.Errorf("CPU request change = %s -> %s, want 100m -> 250m", before, after)
+	}
+
+	if changes := resourceChanges(nil, since); changes == nil || len(changes) != 0 {
+		t.Errorf("resourceChanges(nil) = %#v, want empty non-nil slice", changes)
+	}
+}
+
+func TestResourcesEqual(t *testing.T) {
+	list := func(cpu string) corev1.ResourceList {
+		return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+	}
+	tests := []struct {
+		name string
+		a, b corev1.ResourceRequirements
+		want bool
+	}{
+		{"both empty", corev1.ResourceRequirements{}, corev1.ResourceRequirements{}, true},
+		{"same value different notation", corev1.ResourceRequirements{Limits: list("1")}, corev1.ResourceRequirements{Limits: list("1000m")}, true},
+		{"different value", corev1.ResourceRequirements{Requests: list("1")}, corev1.ResourceRequirements{Requests: list("2")}, false},
+		{"limit removed", corev1.ResourceRequirements{Limits: list("1")}, corev1.ResourceRequirements{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resourcesEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("resourcesEqual() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
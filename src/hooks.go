@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// HookProtocolVersion is the version of the hook input; hooks check it before
+// relying on the snapshot layout
+const HookProtocolVersion = 1
+
+// DefaultHookTimeout bounds a hook without timeoutSeconds
+const DefaultHookTimeout = 30 * time.Second
+
+// hookSpec is an enrichment hook as written in the config file. The command
+// receives the snapshot as JSON on stdin and prints extra Resources columns
+// and sheets as JSON on stdout.
+//
+// Columns lists the column names the hook may return, so conflicts are found
+// when the config is loaded rather than on each report.
+//
+//	hooks:
+//	  - name: cmdb
+//	    command: ["/usr/local/bin/cmdb-enrich", "--env", "prod"]
+//	    columns: ["CMDB ID"]
+//	    timeoutSeconds: 60
+type hookSpec struct {
+	Name           string   `json:"name"`
+	Command        []string `json:"command"`
+	Columns        []string `json:"columns,omitempty"`
+	TimeoutSeconds int      `json:"timeoutSeconds,omitempty"`
+}
+
+// hook is a validated enrichment hook
+type hook struct {
+	name    string
+	command []string
+	columns map[string]bool // Declared columns, nil if the hook declares none
+	timeout time.Duration
+}
+
+// hookInput is the snapshot a hook reads from stdin
+type hookInput struct {
+	Version    int                   `json:"version"`
+	Collected  time.Time             `json:"collected"`
+	Cluster    string                `json:"cluster,omitempty"`
+	Namespace  string                `json:"namespace,omitempty"` // Empty for all namespaces
+	Pods       []corev1.Pod          `json:"pods"`
+	Namespaces *corev1.NamespaceList `json:"namespaces,omitempty"`
+	Nodes      *corev1.NodeList      `json:"nodes,omitempty"`
+}
+
+// hookColumn is an extra Resources sheet column. Values are keyed by
+// "namespace", "namespace/pod" or "namespace/pod/container"; the most
+// specific key of a row wins.
+type hookColumn struct {
+	Name   string                 `json:"name"`
+	Values map[string]interface{} `json:"values"`
+}
+
+// hookSheet is an extra sheet written after the built-in sheets
+type hookSheet struct {
+	Name   string          `json:"name"`
+	Header []string        `json:"header"`
+	Rows   [][]interface{} `json:"rows"`
+}
+
+// hookOutput is what a hook prints on stdout
+type hookOutput struct {
+	Columns []hookColumn `json:"columns,omitempty"`
+	Sheets  []hookSheet  `json:"sheets,omitempty"`
+}
+
+// enrichment are the columns and sheets of all hooks of a report
+type enrichment struct {
+	columns []hookColumn
+	sheets  []hookSheet
+}
+
+// reservedColumns maps the Resources sheet columns a hook column must not
+// duplicate to where they come from
+func reservedColumns(opts reportOptions) map[string]string {
+	reserved := make(map[string]string)
+	for _, headers := range [][]string{resourceHeaders, usageHeaders, rawQuantityHeaders, teamHeaders, gitopsHeaders,
+		extendedResourceHeaders(opts.extendedResources)} {
+		for _, header := range headers {
+			reserved[header] = "a built-in column"
+		}
+	}
+	if opts.quantiles != nil {
+		for _, header := range opts.quantiles.headers() {
+			reserved[header] = "a built-in column"
+		}
+	}
+	for _, column := range opts.customColumns {
+		reserved[column.name] = fmt.Sprintf("custom column '%s'", column.name)
+	}
+	return reserved
+}
+
+// parseHooks validates the hook specs from the config file; declared columns
+// must not duplicate reserved columns or the columns of another hook
+func parseHooks(specs []hookSpec, reserved map[string]string) ([]hook, error) {
+	hooks := make([]hook, 0, len(specs))
+	seen := make(map[string]bool)
+	columns := make(map[string]string) // Declared column name to hook
+	for _, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("hook without name")
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("duplicate hook '%s'", spec.Name)
+		}
+		seen[spec.Name] = true
+		if len(spec.Command) == 0 || spec.Command[0] == "" {
+			return nil, fmt.Errorf("hook '%s' needs a command", spec.Name)
+		}
+		if spec.TimeoutSeconds < 0 {
+			return nil, fmt.Errorf("hook '%s' has a negative timeoutSeconds", spec.Name)
+		}
+		h := hook{name: spec.Name, command: spec.Command, timeout: DefaultHookTimeout}
+		for _, column := range spec.Columns {
+			if column == "" {
+				return nil, fmt.Errorf("hook '%s' declares a column without name", spec.Name)
+			}
+			if origin, ok := reserved[column]; ok {
+				return nil, fmt.Errorf("hook '%s' column '%s' duplicates %s", spec.Name, column, origin)
+			}
+			if other, ok := columns[column]; ok {
+				return nil, fmt.Errorf("hooks '%s' and '%s' both declare column '%s'", other, spec.Name, column)
+			}
+			columns[column] = spec.Name
+			if h.columns == nil {
+				h.columns = make(map[string]bool)
+			}
+			h.columns[column] = true
+		}
+		if spec.TimeoutSeconds > 0 {
+			h.timeout = time.Duration(spec.TimeoutSeconds) * time.Second
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+// run executes the hook with input on stdin; its stderr goes to ours so hook
+// diagnostics end up next to the report logs
+func (h hook) run(input []byte) (*hookOutput, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.command[0], h.command[1:]...) //nolint:gosec // Command from the config file
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stderr = os.Stderr
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("hook '%s' timed out after %s", h.name, h.timeout)
+		}
+		return nil, fmt.Errorf("hook '%s' failed: %w", h.name, err)
+	}
+
+	var output hookOutput
+	decoder := json.NewDecoder(&out)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&output); err != nil {
+		return nil, fmt.Errorf("invalid output of hook '%s': %w", h.name, err)
+	}
+	return &output, nil
+}
+
+// runHooks runs the hooks on a snapshot. A failing hook is logged and skipped
+// so an unreachable CMDB does not stop the report, and so is a returned column
+// or sheet whose name is missing, undeclared or taken.
+func runHooks(hooks []hook, snap *clusterSnapshot, meta reportMetadata, reserved map[string]string) (*enrichment, error) {
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+	input, err := json.Marshal(hookInput{
+		Version:    HookProtocolVersion,
+		Collected:  snap.collected,
+		Cluster:    meta.cluster.name,
+		Namespace:  meta.namespace,
+		Pods:       snap.pods,
+		Namespaces: snap.namespaces,
+		Nodes:      snap.nodes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode hook input: %w", err)
+	}
+
+	e := &enrichment{}
+	columns := make(map[string]string) // Column name to hook
+	sheets := make(map[string]string)  // Sheet name to hook
+	for _, h := range hooks {
+		output, err := h.run(input)
+		if err != nil {
+			logrus.Warnf("Skipping enrichment: %v", err)
+			continue
+		}
+		for _, c := range output.Columns {
+			switch origin, isReserved := reserved[c.Name]; {
+			case c.Name == "":
+				logrus.Warnf("Skipping enrichment: hook '%s' returned a column without name", h.name)
+			case h.columns != nil && !h.columns[c.Name]:
+				logrus.Warnf("Skipping enrichment: hook '%s' returned undeclared column '%s'", h.name, c.Name)
+			case isReserved:
+				logrus.Warnf("Skipping enrichment: hook '%s' column '%s' duplicates %s", h.name, c.Name, origin)
+			case columns[c.Name] != "":
+				logrus.Warnf("Skipping enrichment: hooks '%s' and '%s' both return column '%s'", columns[c.Name], h.name, c.Name)
+			default:
+				columns[c.Name] = h.name
+				e.columns = append(e.columns, c)
+			}
+		}
+		for _, s := range output.Sheets {
+			switch other := sheets[strings.ToLower(s.Name)]; {
+			case s.Name == "":
+				logrus.Warnf("Skipping enrichment: hook '%s' returned a sheet without name", h.name)
+			case other != "":
+				logrus.Warnf("Skipping enrichment: hooks '%s' and '%s' both return sheet '%s'", other, h.name, s.Name)
+			default:
+				sheets[strings.ToLower(s.Name)] = h.name
+				e.sheets = append(e.sheets, s)
+			}
+		}
+		logrus.Debugf("Hook '%s' returned %d columns and %d sheets", h.name, len(output.Columns), len(output.Sheets))
+	}
+	return e, nil
+}
+
+// headers returns the names of the enrichment columns
+func (e *enrichment) headers() []string {
+	if e == nil {
+		return nil
+	}
+	headers := make([]string, 0, len(e.columns))
+	for _, c := range e.columns {
+		headers = append(headers, c.Name)
+	}
+	return headers
+}
+
+// values returns the enrichment column cells of a container row
+func (e *enrichment) values(namespace, pod, container string) []interface{} {
+	if e == nil {
+		return nil
+	}
+	keys := []string{namespace + "/" + pod + "/" + container, namespace + "/" + pod, namespace}
+	values := make([]interface{}, 0, len(e.columns))
+	for _, c := range e.columns {
+		var value interface{} = ""
+		for _, key := range keys {
+			if v, ok := c.Values[key]; ok {
+				value = v
+				break
+			}
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// columnsOnly drops the sheets, which cover the whole cluster, for split
+// workbooks; the columns follow the rows of each group
+func (e *enrichment) columnsOnly() *enrichment {
+	if e == nil {
+		return nil
+	}
+	return &enrichment{columns: e.columns}
+}
+
+// createHookSheets writes the sheets returned by the hooks; a sheet named like
+// a report sheet is logged and skipped
+func createHookSheets(f *excelize.File, sheets []hookSheet) error {
+	for _, s := range sheets {
+		if idx, _ := f.GetSheetIndex(s.Name); idx >= 0 {
+			logrus.Warnf("Skipping enrichment: hook sheet '%s' conflicts with a report sheet", s.Name)
+			continue
+		}
+		if _, err := f.NewSheet(s.Name); err != nil {
+			return fmt.Errorf("failed to create hook sheet '%s': %w", s.Name, err)
+		}
+		if err := f.SetSheetRow(s.Name, "A1", &s.Header); err != nil {
+			return fmt.Errorf("failed to set headers of hook sheet '%s': %w", s.Name, err)
+		}
+		if len(s.Header) > 0 {
+			last, _ := excelize.CoordinatesToCellName(len(s.Header), 1)
+			f.SetCellStyle(s.Name, "A1", last, export.BoldStyle(f))
+		}
+		for i, row := range s.Rows {
+			if err := export.SetRow(f, s.Name, i+2, row, fmt.Sprintf("hook sheet '%s' row %d", s.Name, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
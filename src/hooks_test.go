@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseHooks(t *testing.T) {
+	tests := []struct {
+		name        string
+		specs       []hookSpec
+		wantTimeout time.Duration
+		wantErr     bool
+	}{
+		{"default timeout", []hookSpec{{Name: "cmdb", Command: []string{"cmdb-enrich"}}}, DefaultHookTimeout, false},
+		{"custom timeout", []hookSpec{{Name: "cmdb", Command: []string{"cmdb-enrich"}, TimeoutSeconds: 5}}, 5 * time.Second, false},
+		{"missing name", []hookSpec{{Command: []string{"cmdb-enrich"}}}, 0, true},
+		{"missing command", []hookSpec{{Name: "cmdb"}}, 0, true},
+		{"negative timeout", []hookSpec{{Name: "cmdb", Command: []string{"cmdb-enrich"}, TimeoutSeconds: -1}}, 0, true},
+		{"duplicate", []hookSpec{{Name: "cmdb", Command: []string{"a"}}, {Name: "cmdb", Command: []string{"b"}}}, 0, true},
+		{"declared columns", []hookSpec{{Name: "cmdb", Command: []string{"a"}, Columns: []string{"CMDB ID"}}, {Name: "cost", Command: []string{"b"}, Columns: []string{"Cost Center"}}}, DefaultHookTimeout, false},
+		{"unnamed column", []hookSpec{{Name: "cmdb", Command: []string{"a"}, Columns: []string{""}}}, 0, true},
+		{"built-in column", []hookSpec{{Name: "cmdb", Command: []string{"a"}, Columns: []string{"Namespace"}}}, 0, true},
+		{"team column", []hookSpec{{Name: "cmdb", Command: []string{"a"}, Columns: []string{"Owner"}}}, 0, true},
+		{"custom column", []hookSpec{{Name: "cmdb", Command: []string{"a"}, Columns: []string{"CPU Ratio"}}}, 0, true},
+		{"column of another hook", []hookSpec{{Name: "cmdb", Command: []string{"a"}, Columns: []string{"CMDB ID"}}, {Name: "other", Command: []string{"b"}, Columns: []string{"CMDB ID"}}}, 0, true},
+	}
+	reserved := reservedColumns(reportOptions{customColumns: []customColumn{{name: "CPU Ratio"}}})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks, err := parseHooks(tt.specs, reserved)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseHooks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && hooks[0].timeout != tt.wantTimeout {
+				t.Errorf("timeout = %v, want %v", hooks[0].timeout, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestEnrichmentValues(t *testing.T) {
+	e := &enrichment{columns: []hookColumn{
+		{Name: "Cost Center", Values: map[string]interface{}{"shop": "CC-1", "shop/cart-1": "CC-2", "shop/cart-1/proxy": "CC-3"}},
+		{Name: "CMDB ID", Values: map[string]interface{}{"search/index-0": 42.0}},
+	}}
+	tests := []struct {
+		namespace, pod, container string
+		want                      []interface{}
+	}{
+		{"shop", "cart-1", "proxy", []interface{}{"CC-3", ""}},
+		{"shop", "cart-1", "app", []interface{}{"CC-2", ""}},
+		{"shop", "web-1", "app", []interface{}{"CC-1", ""}},
+		{"search", "index-0", "app", []interface{}{"", 42.0}},
+	}
+	for _, tt := range tests {
+		if got := e.values(tt.namespace, tt.pod, tt.container); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("values(%s/%s/%s) = %v, want %v", tt.namespace, tt.pod, tt.container, got, tt.want)
+		}
+	}
+	if !reflect.DeepEqual(e.headers(), []string{"Cost Center", "CMDB ID"}) {
+		t.Errorf("headers = %v", e.headers())
+	}
+
+	var none *enrichment
+	if none.headers() != nil || none.values("shop", "cart-1", "app") != nil || none.columnsOnly() != nil {
+		t.Error("nil enrichment should add no columns")
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook test scripts need a POSIX shell")
+	}
+	dir := t.TempDir()
+	inputPath := filepath.Join(dir, "input.json")
+	cmdb := hookSpec{Name: "cmdb", Command: []string{"sh", "-c",
+		`cat > "$0"; echo '{"columns":[{"name":"CMDB ID","values":{"shop":"CI-1"}}],"sheets":[{"name":"CMDB","header":["Namespace","CI"],"rows":[["shop","CI-1"]]}]}'`,
+		inputPath}}
+	failing := hookSpec{Name: "broken", Command: []string{"sh", "-c", "exit 3"}}
+	garbage := hookSpec{Name: "garbage", Command: []string{"sh", "-c", "echo not json"}}
+	duplicate := hookSpec{Name: "other", Command: []string{"sh", "-c",
+		`echo '{"columns":[{"name":"CMDB ID","values":{}},{"name":"Node","values":{}},{"name":"","values":{}},{"name":"Rack","values":{}}],"sheets":[{"name":"cmdb"},{"name":"Racks"}]}'`}}
+
+	snap := &clusterSnapshot{
+		collected: time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC),
+		pods:      []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "cart-1", Namespace: "shop"}}},
+	}
+	meta := reportMetadata{cluster: clusterIdentity{name: "prod"}}
+
+	hooks, err := parseHooks([]hookSpec{cmdb, failing, garbage}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := runHooks(hooks, snap, meta, nil)
+	if err != nil {
+		t.Fatalf("runHooks: %v", err)
+	}
+	if !reflect.DeepEqual(e.headers(), []string{"CMDB ID"}) || len(e.sheets) != 1 || e.sheets[0].Name != "CMDB" {
+		t.Errorf("failing hooks should be skipped, got columns %v and %d sheets", e.headers(), len(e.sheets))
+	}
+
+	data, err := os.ReadFile(inputPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var input hookInput
+	if err := json.Unmarshal(data, &input); err != nil {
+		t.Fatalf("hook input is not JSON: %v", err)
+	}
+	if input.Version != HookProtocolVersion || input.Cluster != "prod" || len(input.Pods) != 1 || input.Pods[0].Name != "cart-1" {
+		t.Errorf("unexpected hook input: %+v", input)
+	}
+
+	// Taken or missing names skip the column or sheet, not the report
+	reserved := reservedColumns(reportOptions{})
+	hooks, _ = parseHooks([]hookSpec{cmdb, duplicate}, reserved)
+	e, err = runHooks(hooks, snap, meta, reserved)
+	if err != nil {
+		t.Fatalf("runHooks with name conflicts: %v", err)
+	}
+	if !reflect.DeepEqual(e.headers(), []string{"CMDB ID", "Rack"}) || len(e.sheets) != 2 || e.sheets[1].Name != "Racks" {
+		t.Errorf("conflicting names should be skipped, got columns %v and sheets %+v", e.headers(), e.sheets)
+	}
+
+	// Declared columns restrict what a hook may return
+	duplicate.Columns = []string{"Rack Unit"}
+	hooks, _ = parseHooks([]hookSpec{duplicate}, reserved)
+	if e, _ = runHooks(hooks, snap, meta, reserved); len(e.columns) != 0 {
+		t.Errorf("undeclared columns should be skipped, got %v", e.headers())
+	}
+
+	if e, err := runHooks(nil, snap, meta, nil); e != nil || err != nil {
+		t.Errorf("runHooks without hooks = %v, %v", e, err)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLanguage is the language of the Insights text; its catalog is empty
+// as the message IDs are the English text
+const DefaultLanguage = "en"
+
+// messageCatalog translates Insights messages, keyed by the English text or
+// format string (gettext style); messages without an entry stay English
+type messageCatalog map[string]string
+
+// catalogs are the built-in languages
+var catalogs = map[string]messageCatalog{
+	DefaultLanguage: {},
+	"de": {
+		// Section titles
+		"📊 KUBERNETES RESOURCE INSIGHTS": "📊 KUBERNETES-RESSOURCEN-ANALYSE",
+		"🎯 RESOURCE EFFICIENCY ANALYSIS": "🎯 RESSOURCENEFFIZIENZ",
+		"🏗️ NODE DISTRIBUTION ANALYSIS":  "🏗️ VERTEILUNG AUF NODES",
+		"💤 IDLE NAMESPACES":              "💤 UNGENUTZTE NAMESPACES",
+		"⚖️ TENANT FAIRNESS":             "⚖️ FAIRNESS ZWISCHEN MANDANTEN",
+		"💡 OPTIMIZATION RECOMMENDATIONS": "💡 OPTIMIERUNGSEMPFEHLUNGEN",
+		"Cluster CPU Efficiency":         "CPU-Effizienz des Clusters",
+		"Cluster Memory Efficiency":      "Speicher-Effizienz des Clusters",
+		"Over-provisioned Namespaces":    "Überdimensionierte Namespaces",
+		"Well-balanced Namespaces":       "Ausgewogene Namespaces",
+		"Under-provisioned Namespaces":   "Unterdimensionierte Namespaces",
+		"Potential CPU Savings":          "Mögliche CPU-Einsparung",
+		"Potential Memory Savings":       "Mögliche Speicher-Einsparung",
+		"If limits = requests":           "Wenn Limits = Requests",
+		"%.1f cores":                     "%.1f Kerne",
+		"%.1f Gi":                        "%.1f Gi",
+		"< %g%% efficiency":              "< %g%% Effizienz",
+		"> %g%% efficiency":              "> %g%% Effizienz",
+		"%g-%g%% efficiency":             "%g-%g%% Effizienz",
+		"⚠️ Under-provisioned":           "⚠️ Unterdimensioniert",
+		"✅ Well-balanced":                "✅ Ausgewogen",
+		"⚡ Over-provisioned":             "⚡ Überdimensioniert",
+		"🔴 Severely over-provisioned":    "🔴 Stark überdimensioniert",
+		"Total Nodes":                    "Nodes gesamt",
+		"Average Pods per Node":          "Pods pro Node (Durchschnitt)",
+		"Pod Distribution StdDev":        "Standardabweichung der Pod-Verteilung",
+		"Lower = better balance":         "Niedriger = bessere Verteilung",
+		"Most Loaded Node":               "Am stärksten belasteter Node",
+		"Least Loaded Node":              "Am wenigsten belasteter Node",
+		"%d pods":                        "%d Pods",
+		"Load Balance Score":             "Lastverteilungs-Score",
+		"0-100 (100 = perfect)":          "0-100 (100 = perfekt)",
+		"No idle namespaces":             "Keine ungenutzten Namespaces",
+		"Namespace":                      "Namespace",
+		"Pods":                           "Pods",
+		"Last Activity":                  "Letzte Aktivität",
+		"Request CPU (cores)":            "CPU-Request (Kerne)",
+		"Request Memory (Gi)":            "Speicher-Request (Gi)",
+		"Reclaimable":                    "Freisetzbar",
+		"namespaces":                     "Namespaces",
+		"teams":                          "Teams",
+		"CPU Request Gini (%s)":          "Gini der CPU-Requests (%s)",
+		"Memory Request Gini (%s)":       "Gini der Speicher-Requests (%s)",
+		"CPU Jain's Index (%s)":          "Jain-Index CPU (%s)",
+		"Memory Jain's Index (%s)":       "Jain-Index Speicher (%s)",
+		"1 = equal shares":               "1 = gleiche Anteile",
+		"Top %.0f%% %s CPU Share":        "CPU-Anteil der Top %.0f%% %s",
+		"Top %.0f%% %s Memory Share":     "Speicher-Anteil der Top %.0f%% %s",
+		"Share of all CPU requests":      "Anteil an allen CPU-Requests",
+		"Share of all memory requests":   "Anteil an allen Speicher-Requests",
+		"✅ Evenly shared":                "✅ Gleichmäßig verteilt",
+		"⚡ Moderately concentrated":      "⚡ Mäßig konzentriert",
+		"⚠️ Few tenants dominate":        "⚠️ Wenige Mandanten dominieren",
+		"No pod created or restarted in the last %d days; their requests are reclaimable": "In den letzten %d Tagen wurde kein Pod erstellt oder neu gestartet; die Requests sind freisetzbar",
+		// Recommendations
+		"Consider reducing CPU limits - cluster is over-provisioned":    "CPU-Limits senken - der Cluster ist überdimensioniert",
+		"Consider reducing Memory limits - cluster is over-provisioned": "Speicher-Limits senken - der Cluster ist überdimensioniert",
+		"⚠️ CPU limits too tight - risk of throttling":                  "⚠️ CPU-Limits zu knapp - Gefahr von Drosselung",
+		"⚠️ Memory limits too tight - risk of OOM kills":                "⚠️ Speicher-Limits zu knapp - Gefahr von OOM-Kills",
+		"Focus on right-sizing over-provisioned namespaces first":       "Zuerst überdimensionierte Namespaces richtig dimensionieren",
+		"Consider pod anti-affinity rules for better node distribution": "Pod-Anti-Affinity-Regeln für eine bessere Verteilung auf Nodes nutzen",
+		"✅ Cluster resource allocation looks well-balanced!":            "✅ Die Ressourcenverteilung des Clusters ist ausgewogen!",
+	},
+}
+
+// formatVerbs matches the fmt verbs of a message, which a translation must keep
+var formatVerbs = regexp.MustCompile(`%[-+# 0]*[0-9.]*[a-zA-Z%]`)
+
+// parseMessages selects the catalog of a language and applies the config file
+// translations on top; an unknown language needs translations of its own
+func parseMessages(language string, overrides map[string]string) (messageCatalog, error) {
+	language = strings.TrimSpace(strings.ToLower(language))
+	if language == "" {
+		language = DefaultLanguage
+	}
+	base, ok := catalogs[language]
+	if !ok && len(overrides) == 0 {
+		return nil, fmt.Errorf("unknown language '%s' (built in: %s; add others with messages)", language, strings.Join(languageNames(), ", "))
+	}
+	catalog := make(messageCatalog, len(base)+len(overrides))
+	for id, text := range base {
+		catalog[id] = text
+	}
+	for id, text := range overrides {
+		if !reflect.DeepEqual(formatVerbs.FindAllString(id, -1), formatVerbs.FindAllString(text, -1)) {
+			return nil, fmt.Errorf("translation of '%s' must keep its placeholders %v in order", id, formatVerbs.FindAllString(id, -1))
+		}
+		catalog[id] = text
+	}
+	return catalog, nil
+}
+
+// languageNames returns the sorted built-in languages
+func languageNames() []string {
+	names := make([]string, 0, len(catalogs))
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// text translates a message; a nil catalog keeps it English
+func (c messageCatalog) text(id string) string {
+	if text, ok := c[id]; ok {
+		return text
+	}
+	return id
+}
+
+// sprintf translates a format string and formats it
+func (c messageCatalog) sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(c.text(format), args...)
+}
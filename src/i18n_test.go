@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		language  string
+		overrides map[string]string
+		id, want  string
+		wantErr   bool
+	}{
+		{"default", "", nil, "Total Nodes", "Total Nodes", false},
+		{"german", " DE ", nil, "Total Nodes", "Nodes gesamt", false},
+		{"override", "de", map[string]string{"Total Nodes": "Anzahl Nodes"}, "Total Nodes", "Anzahl Nodes", false},
+		{"custom language", "fr", map[string]string{"%d pods": "%d pods (fr)"}, "%d pods", "%d pods (fr)", false},
+		{"custom language keeps missing English", "fr", map[string]string{"%d pods": "%d pods"}, "Total Nodes", "Total Nodes", false},
+		{"unknown language", "fr", nil, "", "", true},
+		{"lost placeholder", "en", map[string]string{"%d pods": "Pods"}, "", "", true},
+		{"reordered placeholders", "en", map[string]string{"Top %.0f%% %s CPU Share": "%s CPU top %.0f%%"}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, err := parseMessages(tt.language, tt.overrides)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseMessages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && msgs.text(tt.id) != tt.want {
+				t.Errorf("text(%q) = %q, want %q", tt.id, msgs.text(tt.id), tt.want)
+			}
+		})
+	}
+}
+
+func TestCatalogPlaceholders(t *testing.T) {
+	for language, catalog := range catalogs {
+		for id, text := range catalog {
+			if !reflect.DeepEqual(formatVerbs.FindAllString(id, -1), formatVerbs.FindAllString(text, -1)) {
+				t.Errorf("%s translation of %q changes its placeholders: %q", language, id, text)
+			}
+		}
+	}
+}
+
+func TestMessageCatalogSprintf(t *testing.T) {
+	if got := messageCatalog(nil).sprintf("%d pods", 3); got != "3 pods" {
+		t.Errorf("nil sprintf() = %q", got)
+	}
+	if got := catalogs["de"].sprintf("Top %.0f%% %s CPU Share", 10.0, "Teams"); got != "CPU-Anteil der Top 10% Teams" {
+		t.Errorf("de sprintf() = %q", got)
+	}
+	if got := plainText(catalogs["de"].text("⚡ Over-provisioned")); got != "[!] Ueberdimensioniert" {
+		t.Errorf("plainText(de) = %q", got)
+	}
+}
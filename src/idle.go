@@ -0,0 +1,70 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultIdleDays is the inactivity period after which a namespace is reported as idle
+const DefaultIdleDays = 14
+
+// namespaceActivity tracks the most recent sign of life of a namespace's pods
+type namespaceActivity struct {
+	pods         int
+	lastActivity time.Time // Newest pod creation or container restart
+}
+
+// observe records a pod's creation and its latest container restart
+func (a *namespaceActivity) observe(pod *corev1.Pod, lastRestart time.Time) {
+	a.pods++
+	for _, t := range []time.Time{pod.CreationTimestamp.Time, lastRestart} {
+		if t.After(a.lastActivity) {
+			a.lastActivity = t
+		}
+	}
+}
+
+// idleNamespace is a namespace without pod activity whose requests are reclaimable
+type idleNamespace struct {
+	namespace      string
+	pods           int
+	lastActivity   time.Time
+	reqCPU, reqMem int64 // Reserved requests in millicores and bytes
+}
+
+// idleNamespaces returns namespaces whose pods were neither created nor restarted
+// within idleAfter before now, largest CPU reservation first. Namespaces without
+// requests are skipped as there is nothing to reclaim.
+func idleNamespaces(activity map[string]namespaceActivity, totals map[string]namespaceTotal, now time.Time, idleAfter time.Duration) []idleNamespace {
+	cutoff := now.Add(-idleAfter)
+	var idle []idleNamespace
+	for ns, a := range activity {
+		if a.pods == 0 || a.lastActivity.After(cutoff) {
+			continue
+		}
+		t := totals[ns]
+		if t.reqCPU == 0 && t.reqMem == 0 {
+			continue
+		}
+		idle = append(idle, idleNamespace{
+			namespace:    ns,
+			pods:         a.pods,
+			lastActivity: a.lastActivity,
+			reqCPU:       t.reqCPU,
+			reqMem:       t.reqMem,
+		})
+	}
+
+	sort.Slice(idle, func(i, j int) bool {
+		if idle[i].reqCPU != idle[j].reqCPU {
+			return idle[i].reqCPU > idle[j].reqCPU
+		}
+		if idle[i].reqMem != idle[j].reqMem {
+			return idle[i].reqMem > idle[j].reqMem
+		}
+		return idle[i].namespace < idle[j].namespace
+	})
+	return idle
+}
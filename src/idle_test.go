@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceActivityObserve(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	pod := func(created time.Time) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.NewTime(created)}}
+	}
+
+	var a namespaceActivity
+	a.observe(pod(now.AddDate(0, 0, -30)), time.Time{})
+	a.observe(pod(now.AddDate(0, 0, -40)), now.AddDate(0, 0, -3)) // Restart newer than any creation
+	a.observe(pod(now.AddDate(0, 0, -10)), time.Time{})
+
+	if a.pods != 3 {
+		t.Errorf("pods = %d, want 3", a.pods)
+	}
+	if want := now.AddDate(0, 0, -3); !a.lastActivity.Equal(want) {
+		t.Errorf("lastActivity = %v, want %v", a.lastActivity, want)
+	}
+}
+
+func TestIdleNamespaces(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	idleAfter := 14 * 24 * time.Hour
+
+	activity := map[string]namespaceActivity{
+		"active":    {pods: 2, lastActivity: now.AddDate(0, 0, -1)},
+		"legacy":    {pods: 3, lastActivity: now.AddDate(0, 0, -60)},
+		"sandbox":   {pods: 1, lastActivity: now.AddDate(0, 0, -20)},
+		"archive":   {pods: 1, lastActivity: now.AddDate(0, 0, -20)},
+		"boundary":  {pods: 1, lastActivity: now.Add(-idleAfter)},
+		"norequest": {pods: 1, lastActivity: now.AddDate(0, 0, -90)},
+	}
+	totals := map[string]namespaceTotal{
+		"active":   {reqCPU: 4000, reqMem: 8 * BytesPerGi},
+		"legacy":   {reqCPU: 500, reqMem: 1 * BytesPerGi},
+		"sandbox":  {reqCPU: 2000, reqMem: 2 * BytesPerGi},
+		"archive":  {reqCPU: 500, reqMem: 4 * BytesPerGi},
+		"boundary": {reqCPU: 100},
+	}
+
+	got := idleNamespaces(activity, totals, now, idleAfter)
+
+	want := []string{"sandbox", "archive", "legacy", "boundary"}
+	if len(got) != len(want) {
+		t.Fatalf("idleNamespaces() returned %d namespaces, want %d: %+v", len(got), len(want), got)
+	}
+	for i, ns := range want {
+		if got[i].namespace != ns {
+			t.Errorf("idleNamespaces()[%d] = %s, want %s", i, got[i].namespace, ns)
+		}
+	}
+	if got[0].reqCPU != 2000 || got[0].reqMem != 2*BytesPerGi || got[0].pods != 1 {
+		t.Errorf("sandbox = %+v", got[0])
+	}
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultInitConfig is the config file written by the init wizard
+const DefaultInitConfig = "pod-resource-calculator.yaml"
+
+// Delivery choices of the init wizard
+const (
+	DeliveryFile   = "file"   // Excel workbook
+	DeliveryBI     = "bi"     // CSV for Power BI and spreadsheets
+	DeliveryServer = "server" // Web server with an always current report
+)
+
+// permissionCheck is an API permission the report uses
+type permissionCheck struct {
+	group, resource string
+	namespaced      bool
+	purpose         string
+	required        bool
+}
+
+// reportPermissions are the list permissions of the report, tested by init
+var reportPermissions = []permissionCheck{
+	{"", "pods", true, "Resources, Namespaces and Nodes sheets", true},
+	{"", "nodes", false, "Node capacity and saturation", false},
+	{"", "namespaces", false, "Pod Security sheet and namespace age", false},
+	{"apps", "replicasets", true, "Resource Changes sheet (-change-days)", false},
+	{"apps", "statefulsets", true, "StatefulSet Footprint sheet", false},
+	{"autoscaling", "horizontalpodautoscalers", true, "HPA Scaling sheet", false},
+	{"batch", "jobs", true, "Finished Jobs on the Cleanup sheet and the Job Audit sheet (-job-audit)", false},
+	{"batch", "cronjobs", true, "CronJob Forecast sheet (-cronjob-forecast)", false},
+}
+
+// permissionTester reports whether a permission is granted in namespace
+// (empty for all namespaces)
+type permissionTester func(check permissionCheck, namespace string) (bool, error)
+
+// initArgs are the flags of the init subcommand
+type initArgs struct {
+	kubeconfig *kubeconfigPaths
+	config     *string
+	force      *bool
+}
+
+// initFlags defines the flags of the init subcommand
+func initFlags() (*flag.FlagSet, *initArgs) {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	return fs, &initArgs{
+		kubeconfig: kubeconfigFlag(fs),
+		config:     fs.String("config", DefaultInitConfig, "Config file to write"),
+		force:      fs.Bool("force", false, "Overwrite an existing config file without asking"),
+	}
+}
+
+// wizard asks questions on out and reads the answers line by line from in
+type wizard struct {
+	in  *bufio.Scanner
+	out io.Writer
+	eof bool // End of input, all further questions take their default
+}
+
+func newWizard(in io.Reader, out io.Writer) *wizard {
+	return &wizard{in: bufio.NewScanner(in), out: out}
+}
+
+// ask returns the trimmed answer, or def for an empty answer or end of input
+func (w *wizard) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	if w.eof || !w.in.Scan() {
+		w.eof = true
+		fmt.Fprintln(w.out)
+		return def
+	}
+	if answer := strings.TrimSpace(w.in.Text()); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// askValid asks until valid accepts the answer; at the end of input the
+// default is returned
+func (w *wizard) askValid(question, def string, valid func(string) error) string {
+	for {
+		answer := w.ask(question, def)
+		err := valid(answer)
+		if err == nil || w.eof {
+			return answer
+		}
+		fmt.Fprintf(w.out, "  %v\n", err)
+	}
+}
+
+// askFloat asks for a non-negative number
+func (w *wizard) askFloat(question string, def float64) float64 {
+	answer := w.askValid(question, strconv.FormatFloat(def, 'f', -1, 64), func(s string) error {
+		if v, err := strconv.ParseFloat(s, 64); err != nil || v < 0 {
+			return fmt.Errorf("enter a number of at least 0")
+		}
+		return nil
+	})
+	v, _ := strconv.ParseFloat(answer, 64)
+	return v
+}
+
+// askChoice asks for one of choices
+func (w *wizard) askChoice(question string, choices []string, def string) string {
+	return w.askValid(fmt.Sprintf("%s (%s)", question, strings.Join(choices, ", ")), def, func(s string) error {
+		for _, c := range choices {
+			if s == c {
+				return nil
+			}
+		}
+		return fmt.Errorf("choose one of %s", strings.Join(choices, ", "))
+	})
+}
+
+// wizardAnswers are the choices of the init wizard
+type wizardAnswers struct {
+	context, currentContext  string
+	namespace                string
+	saturationPercent        float64 // 0 keeps the capacity-saturation rule off
+	failOn                   string
+	currency                 string
+	cpuCoreMonth, memGiMonth float64 // 0 skips the Cost sheet
+	timezone                 string
+	delivery                 string
+}
+
+// config returns the config file of the answers; the defaults of the other
+// settings stay implicit so later releases can improve them
+func (a wizardAnswers) config() *config {
+	cfg := &config{Timezone: a.timezone}
+	if a.failOn != "off" {
+		cfg.Validation.FailOn = a.failOn
+	}
+	if a.saturationPercent > 0 {
+		threshold := a.saturationPercent / 100
+		cfg.Validation.Rules = map[string]validationRuleSpec{
+			RuleCapacitySaturation: {Severity: "warn", Threshold: &threshold},
+		}
+	}
+	if a.cpuCoreMonth > 0 || a.memGiMonth > 0 {
+		cfg.Pricing = &pricingSpec{Currency: a.currency, CPUCoreMonth: a.cpuCoreMonth, MemoryGiMonth: a.memGiMonth}
+	}
+	return cfg
+}
+
+// command returns the command line that runs the report of the answers
+func (a wizardAnswers) command(program, configPath string) string {
+	args := []string{program}
+	if a.delivery == DeliveryServer {
+		args = append(args, CommandServe)
+	}
+	args = append(args, "-config", configPath)
+	if a.namespace != "" {
+		args = append(args, "-namespace", a.namespace)
+	}
+	if a.delivery == DeliveryBI {
+		args = append(args, "-format", FormatBI)
+	}
+	return strings.Join(args, " ")
+}
+
+// runWizard asks for the report settings; contexts are the kubeconfig
+// contexts, and test checks the permissions of the chosen context
+func runWizard(w *wizard, contexts []string, current string, test func(context string) permissionTester) wizardAnswers {
+	a := wizardAnswers{currentContext: current, context: current}
+
+	fmt.Fprintln(w.out, "Pod Resource Calculator setup. Press Enter to accept the value in brackets.")
+	fmt.Fprintln(w.out)
+	if len(contexts) > 0 {
+		fmt.Fprintln(w.out, "Clusters (kubeconfig contexts):")
+		for _, c := range contexts {
+			marker := " "
+			if c == current {
+				marker = "*"
+			}
+			fmt.Fprintf(w.out, "  %s %s\n", marker, c)
+		}
+		a.context = w.askChoice("Cluster to report on", contexts, current)
+	} else {
+		fmt.Fprintln(w.out, "No kubeconfig contexts found, skipping the cluster checks.")
+	}
+
+	a.namespace = w.askValid("Namespace to report on, empty for all namespaces", "", validateNamespace)
+
+	if test != nil && a.context != "" {
+		fmt.Fprintf(w.out, "\nChecking permissions of '%s':\n", a.context)
+		tester := test(a.context)
+		for _, check := range reportPermissions {
+			namespace := ""
+			if check.namespaced {
+				namespace = a.namespace
+			}
+			allowed, err := tester(check, namespace)
+			status := "ok"
+			switch {
+			case err != nil:
+				status = fmt.Sprintf("unknown (%v)", err)
+			case !allowed && check.required:
+				status = "MISSING - the report cannot run"
+			case !allowed:
+				status = "missing - " + check.purpose + " stays empty"
+			}
+			fmt.Fprintf(w.out, "  list %-26s %s\n", check.resource, status)
+		}
+		fmt.Fprintln(w.out, "Missing permissions are listed under RBAC Requirements in the README.")
+	}
+
+	fmt.Fprintln(w.out)
+	a.saturationPercent = w.askFloat("Warn when requests exceed this % of allocatable CPU or memory (0 = off)", 80)
+	a.failOn = w.askChoice("Fail scheduled runs on findings of severity", []string{"off", "info", "warn", "error"}, "off")
+
+	fmt.Fprintln(w.out)
+	a.cpuCoreMonth = w.askFloat("Price of one CPU core per month for the Cost sheet (0 = no Cost sheet)", 0)
+	if a.cpuCoreMonth > 0 {
+		a.memGiMonth = w.askFloat("Price of one GiB of memory per month", 0)
+		a.currency = w.ask("Currency", "EUR")
+	}
+	a.timezone = w.askValid("Time zone of the report, e.g. Europe/Berlin, empty for local time", "", func(s string) error {
+		_, err := loadTimezone(s)
+		return err
+	})
+
+	fmt.Fprintln(w.out)
+	fmt.Fprintln(w.out, "Delivery: file = Excel workbook, bi = CSV for Power BI and spreadsheets, server = web page with an always current report")
+	a.delivery = w.askChoice("Delivery", []string{DeliveryFile, DeliveryBI, DeliveryServer}, DeliveryFile)
+	return a
+}
+
+// accessReviewTester tests permissions with SelfSubjectAccessReviews of the
+// given context
+func accessReviewTester(kubeconfigPath, contextName string) permissionTester {
+	restConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(kubeconfigRules(kubeconfigPath), &clientcmd.ConfigOverrides{CurrentContext: contextName}).ClientConfig()
+	var clientSet kubernetes.Interface
+	if err == nil {
+		clientSet, err = kubernetes.NewForConfig(restConfig)
+	}
+	return func(check permissionCheck, namespace string) (bool, error) {
+		if err != nil {
+			return false, err
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultAPITimeout)
+		defer cancel()
+		review, reviewErr := clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace, Verb: "list", Group: check.group, Resource: check.resource,
+			}},
+		}, metav1.CreateOptions{})
+		if reviewErr != nil {
+			return false, reviewErr
+		}
+		return review.Status.Allowed, nil
+	}
+}
+
+// runInit implements the init subcommand: an interactive wizard that writes a
+// config file and prints the command line of the first report
+func runInit(args []string, program string) error {
+	flags, a := initFlags()
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := validatePath(*a.config); err != nil {
+		return fmt.Errorf("invalid config path: %w", err)
+	}
+	if err := a.kubeconfig.validate(); err != nil {
+		return fmt.Errorf("invalid kubeconfig path: %w", err)
+	}
+
+	var contexts []string
+	var current string
+	if raw, err := kubeconfigRules(a.kubeconfig.String()).Load(); err != nil {
+		logrus.Debugf("Could not read kubeconfig: %v", err)
+	} else {
+		for name := range raw.Contexts {
+			contexts = append(contexts, name)
+		}
+		sort.Strings(contexts)
+		current = raw.CurrentContext
+	}
+
+	// Prompts are human-facing and go to stderr like the logs
+	w := newWizard(os.Stdin, os.Stderr)
+	answers := runWizard(w, contexts, current, func(contextName string) permissionTester {
+		return accessReviewTester(a.kubeconfig.String(), contextName)
+	})
+
+	if _, err := os.Stat(*a.config); err == nil && !*a.force {
+		if w.askChoice(fmt.Sprintf("%s exists, overwrite", *a.config), []string{"yes", "no"}, "no") != "yes" {
+			return fmt.Errorf("%s exists, not overwritten", *a.config)
+		}
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to check %s: %w", *a.config, err)
+	}
+	data, err := yaml.Marshal(answers.config())
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(*a.config, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", *a.config, err)
+	}
+
+	fmt.Fprintf(w.out, "\nConfig written to %s. Run your first report with:\n\n", *a.config)
+	if answers.context != "" && answers.context != answers.currentContext {
+		fmt.Fprintf(w.out, "  kubectl config use-context %s\n", answers.context)
+	}
+	fmt.Fprintf(w.out, "  %s\n", answers.command(program, *a.config))
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunWizard(t *testing.T) {
+	input := strings.Join([]string{
+		"staging",       // Unknown context, asked again
+		"prod",          // Context
+		"Shop",          // Invalid namespace, asked again
+		"shop",          // Namespace
+		"",              // Saturation: default 80%
+		"warn",          // Fail on
+		"95.5",          // CPU core price
+		"12",            // Memory price
+		"",              // Currency: default
+		"Europe/Berlin", // Time zone
+		"server",        // Delivery
+	}, "\n")
+	var out bytes.Buffer
+	w := newWizard(strings.NewReader(input), &out)
+
+	var tested []string
+	test := func(context string) permissionTester {
+		return func(check permissionCheck, namespace string) (bool, error) {
+			tested = append(tested, fmt.Sprintf("%s/%s/%s", context, check.resource, namespace))
+			return check.resource != "jobs", nil
+		}
+	}
+	got := runWizard(w, []string{"dev", "prod"}, "dev", test)
+
+	want := wizardAnswers{
+		context: "prod", currentContext: "dev", namespace: "shop",
+		saturationPercent: 80, failOn: "warn",
+		currency: "EUR", cpuCoreMonth: 95.5, memGiMonth: 12,
+		timezone: "Europe/Berlin", delivery: DeliveryServer,
+	}
+	if got != want {
+		t.Errorf("runWizard() = %+v, want %+v", got, want)
+	}
+	if tested[0] != "prod/pods/shop" || tested[1] != "prod/nodes/" {
+		t.Errorf("tested permissions = %v, want namespaced checks in shop", tested)
+	}
+	if !strings.Contains(out.String(), "list jobs") || !strings.Contains(out.String(), "missing - Finished Jobs") {
+		t.Errorf("wizard output lacks the missing jobs permission:\n%s", out.String())
+	}
+}
+
+func TestRunWizardEndOfInput(t *testing.T) {
+	// Closed stdin takes every default instead of looping
+	w := newWizard(strings.NewReader(""), &bytes.Buffer{})
+	got := runWizard(w, nil, "", nil)
+	want := wizardAnswers{saturationPercent: 80, failOn: "off", delivery: DeliveryFile}
+	if got != want {
+		t.Errorf("runWizard() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWizardAnswersConfig(t *testing.T) {
+	threshold := 0.9
+	tests := []struct {
+		name    string
+		answers wizardAnswers
+		want    *config
+		command string
+	}{
+		{"defaults", wizardAnswers{failOn: "off", delivery: DeliveryFile}, &config{},
+			"PodResourceCalculator -config report.yaml"},
+		{"all", wizardAnswers{
+			namespace: "shop", saturationPercent: 90, failOn: "error",
+			currency: "USD", cpuCoreMonth: 20, memGiMonth: 3, timezone: "UTC", delivery: DeliveryBI,
+		}, &config{
+			Timezone: "UTC",
+			Validation: validationSpec{FailOn: "error", Rules: map[string]validationRuleSpec{
+				RuleCapacitySaturation: {Severity: "warn", Threshold: &threshold},
+			}},
+			Pricing: &pricingSpec{Currency: "USD", CPUCoreMonth: 20, MemoryGiMonth: 3},
+		}, "PodResourceCalculator -config report.yaml -namespace shop -format bi"},
+		{"server", wizardAnswers{failOn: "off", delivery: DeliveryServer}, &config{},
+			"PodResourceCalculator serve -config report.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.answers.config(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("config() = %+v, want %+v", got, tt.want)
+			}
+			if got := tt.answers.command("PodResourceCalculator", "report.yaml"); got != tt.command {
+				t.Errorf("command() = %q, want %q", got, tt.command)
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Job run states on the Job Audit sheet
+const (
+	JobRunComplete = "Complete"
+	JobRunFailed   = "Failed"
+	JobRunRunning  = "Running"
+)
+
+// jobRun is one started Job with the requests of its pod template
+type jobRun struct {
+	job            workloadKey // namespace/Job/name
+	cronJob        string      // Owning CronJob, empty for a standalone Job
+	state          string      // JobRunComplete, JobRunFailed or JobRunRunning
+	start, end     time.Time   // end is zero while running
+	succeeded      int32       // Succeeded pods
+	failed         int32       // Failed pods, retries included
+	parallelism    int32       // Pods running at once, capped at the completions
+	reqCPU, reqMem int64       // Requests of one pod in millicores and bytes
+}
+
+// jobRuns describes the Jobs that have started, oldest first. Suspended Jobs
+// that never started are skipped.
+func jobRuns(jobs []batchv1.Job) []jobRun {
+	var runs []jobRun
+	for i := range jobs {
+		job := &jobs[i]
+		if job.Status.StartTime == nil {
+			continue
+		}
+		run := jobRun{
+			job:         workloadKey{namespace: job.Namespace, kind: "Job", name: job.Name},
+			state:       JobRunRunning,
+			start:       job.Status.StartTime.Time,
+			succeeded:   job.Status.Succeeded,
+			failed:      job.Status.Failed,
+			parallelism: 1,
+		}
+		for _, ref := range job.OwnerReferences {
+			if ref.Kind == "CronJob" && (ref.Controller == nil || *ref.Controller) {
+				run.cronJob = ref.Name
+			}
+		}
+		if job.Spec.Parallelism != nil {
+			run.parallelism = *job.Spec.Parallelism
+		}
+		if job.Spec.Completions != nil && *job.Spec.Completions < run.parallelism {
+			run.parallelism = *job.Spec.Completions
+		}
+		for _, cond := range job.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch cond.Type {
+			case batchv1.JobComplete:
+				run.state, run.end = JobRunComplete, cond.LastTransitionTime.Time
+			case batchv1.JobFailed:
+				run.state, run.end = JobRunFailed, cond.LastTransitionTime.Time
+			}
+		}
+		if run.state == JobRunComplete && job.Status.CompletionTime != nil {
+			run.end = job.Status.CompletionTime.Time
+		}
+		run.reqCPU, run.reqMem = podRequests(&corev1.Pod{Spec: job.Spec.Template.Spec})
+		runs = append(runs, run)
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		if !runs[i].start.Equal(runs[j].start) {
+			return runs[i].start.Before(runs[j].start)
+		}
+		return runs[i].job.String() < runs[j].job.String()
+	})
+	return runs
+}
+
+// group returns the CronJob of the run, or the Job itself when standalone
+func (r jobRun) group() workloadKey {
+	if r.cronJob != "" {
+		return workloadKey{namespace: r.job.namespace, kind: "CronJob", name: r.cronJob}
+	}
+	return r.job
+}
+
+// duration returns how long the run took, or has been running at now
+func (r jobRun) duration(now time.Time) time.Duration {
+	if r.end.IsZero() {
+		return now.Sub(r.start)
+	}
+	return r.end.Sub(r.start)
+}
+
+// requestHours returns the CPU core-hours and memory GiB-hours reserved by the
+// run: the requests of its parallel pods over its duration
+func (r jobRun) requestHours(now time.Time) (cpu, mem float64) {
+	hours := r.duration(now).Hours() * float64(r.parallelism)
+	return milliToCores(r.reqCPU) * hours, bytesToGi(r.reqMem) * hours
+}
+
+// jobAudit sums the runs of one CronJob or standalone Job
+type jobAudit struct {
+	workload                  workloadKey
+	runs                      int
+	complete, failed          int
+	running                   int
+	reqCPU, reqMem            int64 // Per-run requests of the latest run
+	totalDuration             time.Duration
+	maxConcurrency            int
+	cpuHours, memHours        float64
+	succeededPods, failedPods int32
+}
+
+// averageDuration returns the mean run duration
+func (a jobAudit) averageDuration() time.Duration {
+	if a.runs == 0 {
+		return 0
+	}
+	return a.totalDuration / time.Duration(a.runs)
+}
+
+// auditJobs groups the runs by CronJob, standalone Jobs on their own, ordered
+// by CPU request-hours so the most expensive batch workloads come first
+func auditJobs(runs []jobRun, now time.Time) []jobAudit {
+	grouped := make(map[workloadKey][]jobRun)
+	for _, run := range runs {
+		grouped[run.group()] = append(grouped[run.group()], run)
+	}
+
+	audits := make([]jobAudit, 0, len(grouped))
+	for key, group := range grouped {
+		a := jobAudit{workload: key, runs: len(group), maxConcurrency: maxConcurrentRuns(group, now)}
+		for _, run := range group { // Oldest first, so the latest run's requests remain
+			switch run.state {
+			case JobRunComplete:
+				a.complete++
+			case JobRunFailed:
+				a.failed++
+			default:
+				a.running++
+			}
+			a.reqCPU = run.reqCPU * int64(run.parallelism)
+			a.reqMem = run.reqMem * int64(run.parallelism)
+			a.totalDuration += run.duration(now)
+			cpu, mem := run.requestHours(now)
+			a.cpuHours += cpu
+			a.memHours += mem
+			a.succeededPods += run.succeeded
+			a.failedPods += run.failed
+		}
+		audits = append(audits, a)
+	}
+	sort.Slice(audits, func(i, j int) bool {
+		if audits[i].cpuHours != audits[j].cpuHours {
+			return audits[i].cpuHours > audits[j].cpuHours
+		}
+		return audits[i].workload.String() < audits[j].workload.String()
+	})
+	return audits
+}
+
+// maxConcurrentRuns returns the largest number of runs that overlapped in time;
+// running runs last until now
+func maxConcurrentRuns(runs []jobRun, now time.Time) int {
+	type event struct {
+		at    time.Time
+		delta int
+	}
+	events := make([]event, 0, 2*len(runs))
+	for _, run := range runs {
+		end := run.end
+		if end.IsZero() {
+			end = now
+		}
+		events = append(events, event{run.start, 1}, event{end, -1})
+	}
+	// A run ending when the next starts does not overlap it
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].at.Equal(events[j].at) {
+			return events[i].at.Before(events[j].at)
+		}
+		return events[i].delta < events[j].delta
+	})
+	current, peak := 0, 0
+	for _, e := range events {
+		current += e.delta
+		if current > peak {
+			peak = current
+		}
+	}
+	return peak
+}
+
+// createJobAuditSheet summarizes the Job runs per CronJob and standalone Job,
+// followed by one row per run. Only Jobs still in the cluster are counted.
+func createJobAuditSheet(f *excelize.File, runs []jobRun, now time.Time, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create job audit sheet: %w", err)
+	}
+
+	f.SetCellValue(sheetName, "A1", "Jobs still in the cluster; runs removed by ttlSecondsAfterFinished or the CronJob history limits are not counted")
+
+	decimalStyle := export.DecimalStyle(f, false)
+	summaryHeaders := []interface{}{
+		"Workload", "Runs", "Complete", "Failed", "Running", "Max Concurrent Runs", "Avg Duration (min)",
+		"Request CPU per Run (cores)", "Request Memory per Run (Gi)", "CPU Request-Hours (core-h)", "Memory Request-Hours (GiB-h)",
+		"Succeeded Pods", "Failed Pods",
+	}
+	if err := export.SetRow(f, sheetName, 3, summaryHeaders, "job audit headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, "A3", "M3", export.BoldStyle(f))
+
+	row := 4
+	var totalCPU, totalMem float64
+	for _, a := range auditJobs(runs, now) {
+		data := []interface{}{
+			a.workload.String(), a.runs, a.complete, a.failed, a.running, a.maxConcurrency,
+			a.averageDuration().Minutes(),
+			milliToCores(a.reqCPU), bytesToGi(a.reqMem), a.cpuHours, a.memHours,
+			a.succeededPods, a.failedPods,
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("workload '%s'", a.workload)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("K%d", row), decimalStyle)
+		totalCPU += a.cpuHours
+		totalMem += a.memHours
+		row++
+	}
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Total")
+	f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), len(runs))
+	f.SetCellValue(sheetName, fmt.Sprintf("J%d", row), totalCPU)
+	f.SetCellValue(sheetName, fmt.Sprintf("K%d", row), totalMem)
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), export.BoldStyle(f))
+	f.SetCellStyle(sheetName, fmt.Sprintf("J%d", row), fmt.Sprintf("K%d", row), export.DecimalStyle(f, true))
+
+	row += 2
+	runHeaders := []interface{}{
+		"Job", "CronJob", "State", "Started", "Duration (min)", "Parallel Pods",
+		"Request CPU per Pod (cores)", "Request Memory per Pod (Gi)", "CPU Request-Hours (core-h)", "Memory Request-Hours (GiB-h)",
+		"Succeeded Pods", "Failed Pods",
+	}
+	if err := export.SetRow(f, sheetName, row, runHeaders, "job run headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("L%d", row), export.BoldStyle(f))
+	row++
+	location := now.Location()
+	for i := len(runs) - 1; i >= 0; i-- { // Latest run first
+		run := runs[i]
+		cpu, mem := run.requestHours(now)
+		data := []interface{}{
+			run.job.String(), valueOrDash(run.cronJob), run.state,
+			run.start.In(location).Format("2006-01-02 15:04"), run.duration(now).Minutes(), run.parallelism,
+			milliToCores(run.reqCPU), bytesToGi(run.reqMem), cpu, mem,
+			run.succeeded, run.failed,
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("job '%s'", run.job)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("E%d", row), fmt.Sprintf("E%d", row), decimalStyle)
+		f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("J%d", row), decimalStyle)
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", "D", 18)
+	f.SetColWidth(sheetName, "E", "M", 22)
+
+	return nil
+}
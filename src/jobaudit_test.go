@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestJobRuns(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	int32p := func(v int32) *int32 { return &v }
+	job := func(name, cronJob string, start time.Duration, parallelism *int32, conditions ...batchv1.JobCondition) batchv1.Job {
+		j := batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "batch", Name: name},
+			Spec: batchv1.JobSpec{Parallelism: parallelism, Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "run",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("2"),
+					corev1.ResourceMemory: resource.MustParse("4Gi"),
+				}},
+			}}}}},
+			Status: batchv1.JobStatus{Conditions: conditions},
+		}
+		if start != 0 {
+			j.Status.StartTime = &metav1.Time{Time: now.Add(-start)}
+		}
+		if cronJob != "" {
+			j.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: cronJob}}
+		}
+		return j
+	}
+	finished := func(condition batchv1.JobConditionType, ago time.Duration) batchv1.JobCondition {
+		return batchv1.JobCondition{Type: condition, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Time{Time: now.Add(-ago)}}
+	}
+
+	runs := jobRuns([]batchv1.Job{
+		job("report-2", "report", 90*time.Minute, nil),                                        // Still running, overlaps report-1
+		job("report-1", "report", 3*time.Hour, nil, finished(batchv1.JobComplete, time.Hour)), // 2 hours
+		job("report-0", "report", 5*time.Hour, nil, finished(batchv1.JobFailed, 3*time.Hour)), // Ends as report-1 starts
+		job("backfill", "", 2*time.Hour, int32p(4), finished(batchv1.JobComplete, time.Hour)), // 1 hour, 4 pods
+		job("suspended", "", 0, nil),
+	})
+	if len(runs) != 4 || runs[0].job.name != "report-0" || runs[3].state != JobRunRunning {
+		t.Fatalf("jobRuns() = %+v", runs)
+	}
+	if runs[0].state != JobRunFailed || runs[0].cronJob != "report" || runs[2].parallelism != 4 {
+		t.Errorf("jobRuns() = %+v", runs)
+	}
+
+	audits := auditJobs(runs, now)
+	if len(audits) != 2 {
+		t.Fatalf("auditJobs() = %+v", audits)
+	}
+	report, backfill := audits[0], audits[1] // Most request-hours first
+	if report.workload.String() != "batch/CronJob/report" {
+		t.Fatalf("auditJobs() starts with %s, want the CronJob", report.workload)
+	}
+	if report.runs != 3 || report.complete != 1 || report.failed != 1 || report.running != 1 || report.maxConcurrency != 2 {
+		t.Errorf("report audit = %+v", report)
+	}
+	// 2h + 2h + 1.5h at 2 cores
+	if report.cpuHours != 11 || report.averageDuration() != 110*time.Minute {
+		t.Errorf("report cpuHours = %v, average = %v; want 11 and 110m", report.cpuHours, report.averageDuration())
+	}
+	// 1h of 4 pods at 2 cores and 4Gi
+	if backfill.cpuHours != 8 || backfill.memHours != 16 || backfill.reqCPU != 8000 {
+		t.Errorf("backfill audit = %+v", backfill)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createJobAuditSheet(f, runs, now, "Job Audit"); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A4": "batch/CronJob/report", "A6": "Total", "B6": "4", "A9": "batch/Job/report-2", "C9": JobRunRunning} {
+		if got, _ := f.GetCellValue("Job Audit", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// jsonReport is the -format json document: the report of the pkg/report
+// library with the validation findings
+type jsonReport struct {
+	*report.Report
+	Findings []findingsRecord `json:"findings"`
+}
+
+// jsonFilename turns a default report filename into the default JSON name
+func jsonFilename(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ".json"
+}
+
+// buildJSONReport assembles the JSON document of a snapshot's pods, the same
+// active containers the Resources sheet lists
+func buildJSONReport(snap *clusterSnapshot, opts reportOptions, meta reportMetadata) jsonReport {
+	meta.generated = snap.collected
+	sizes := opts.tshirtSizes
+	if len(sizes) == 0 {
+		sizes, _ = parseTShirtSizes(defaultTShirtSizes)
+	}
+
+	nsLabels := namespaceLabelIndex(snap.namespaces)
+	doc := jsonReport{
+		Report: report.Build(snap.collected, snap.pods, snap.namespaces, snap.nodes, report.Options{
+			Cluster: meta.cluster.name,
+			Group:   meta.group,
+			TShirtSize: func(reqCPU, reqMem int64) string {
+				return classifyTShirt(sizes, reqCPU, reqMem)
+			},
+			Team: func(pod *corev1.Pod) string {
+				info, _ := opts.teams.resolve(pod.Labels, nsLabels[pod.Namespace], pod.Namespace)
+				return info.Name
+			},
+			Scoring: opts.scoring, // nil falls back to the default model
+		}),
+		Findings: buildAPIViews(snap, opts.validation, meta).recommendations,
+	}
+	if doc.Findings == nil {
+		doc.Findings = []findingsRecord{}
+	}
+	return doc
+}
+
+// writeJSONReport writes the JSON document, indented, to filename or stdout
+func writeJSONReport(filename string, doc jsonReport) (err error) {
+	if filename == StdoutPath {
+		if err := encodeJSONReport(stdout, doc); err != nil {
+			return fmt.Errorf("failed to write to stdout: %w", err)
+		}
+		return nil
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filename, err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to write %s: %w", filename, cerr)
+		}
+	}()
+	if err := encodeJSONReport(file, doc); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return nil
+}
+
+func encodeJSONReport(out io.Writer, doc jsonReport) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
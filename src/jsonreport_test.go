@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestBuildJSONReport(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(now)
+	rules, _ := parseValidationRules(validationSpec{})
+	teams, _ := parseTeamMapping([]byte("teams:\n  payments:\n    namespaces: [shop]\n"))
+	opts := reportOptions{validation: rules, teams: teams}
+
+	report := buildJSONReport(snap, opts, reportMetadata{cluster: clusterIdentity{name: "prod"}})
+	if report.Cluster != "prod" || !report.Generated.Equal(now) {
+		t.Errorf("header = %q, %v", report.Cluster, report.Generated)
+	}
+	if len(report.Containers) != 3 || len(report.Namespaces) != 2 || len(report.Nodes) != 2 {
+		t.Fatalf("containers, namespaces, nodes = %d, %d, %d", len(report.Containers), len(report.Namespaces), len(report.Nodes))
+	}
+	web := report.Containers[0]
+	if web.Pod != "web-1" || web.Team != "payments" || web.NodePool != "general" || *web.RequestCPUMillicores != 500 ||
+		*web.RequestMemoryBytes != BytesPerGi || web.LimitCPUMillicores != nil {
+		t.Errorf("web-1 = %+v", web)
+	}
+	// No limits: no efficiency to rate
+	if report.Insights.CPUEfficiency != nil || report.Insights.CPURating != "" || len(report.Insights.Recommendations) == 0 {
+		t.Errorf("insights without limits = %+v", report.Insights)
+	}
+	if len(report.Findings) != 2 {
+		t.Errorf("findings = %+v", report.Findings)
+	}
+
+	for i := range snap.pods {
+		snap.pods[i].Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("4"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		}
+	}
+	insights := buildJSONReport(snap, opts, reportMetadata{}).Insights
+	if insights.CPUEfficiency == nil || *insights.CPUEfficiency != 0.25 || insights.CPURating != "🔴 Severely over-provisioned" {
+		t.Errorf("cpu efficiency = %v, %q", insights.CPUEfficiency, insights.CPURating)
+	}
+	if insights.MemoryEfficiency == nil || *insights.MemoryEfficiency != 1 || insights.PotentialCPUSavingsMillicores != 9000 {
+		t.Errorf("insights = %+v", insights)
+	}
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	rules, _ := parseValidationRules(validationSpec{})
+	report := buildJSONReport(testAPISnapshot(now), reportOptions{validation: rules}, reportMetadata{})
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := writeJSONReport(path, report); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("export is not JSON: %v", err)
+	}
+	for _, key := range []string{"generated", "containers", "namespaces", "nodes", "insights", "findings"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("export lacks %q", key)
+		}
+	}
+
+	var buf bytes.Buffer
+	saved := stdout
+	stdout = &buf
+	defer func() { stdout = saved }()
+	if err := writeJSONReport(StdoutPath, report); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("stdout differs from the file export")
+	}
+}
+
+func TestJSONFilename(t *testing.T) {
+	if got := jsonFilename("resource_prod_2024-05-10.xlsx"); got != "resource_prod_2024-05-10.json" {
+		t.Errorf("jsonFilename() = %q", got)
+	}
+}
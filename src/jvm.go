@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// jvmOptionVars are the environment variables the JVM or common launch scripts
+// read options from
+var jvmOptionVars = []string{"JAVA_TOOL_OPTIONS", "JDK_JAVA_OPTIONS", "_JAVA_OPTIONS", "JAVA_OPTS", "CATALINA_OPTS", "JVM_OPTS"}
+
+// jvmImageHints are image repository fragments of JVM base images and
+// applications
+var jvmImageHints = []string{
+	"openjdk", "temurin", "corretto", "zulu", "jdk", "jre", "java", "graalvm", "semeru",
+	"tomcat", "jetty", "wildfly", "jboss", "keycloak", "elasticsearch", "logstash", "kafka", "zookeeper", "jenkins", "sonarqube",
+}
+
+// JVM heap ratios of the memory limit: a heap above JVMHeapDangerRatio leaves
+// too little for metaspace, thread stacks, code cache and direct buffers; one
+// below JVMHeapLowRatio leaves most of the limit unused
+const (
+	JVMHeapDangerRatio       = 0.8
+	JVMHeapLowRatio          = 0.4
+	JVMDefaultRAMPercentage  = 25.0 // Heap share of the container memory without heap options
+	JVMRecommendedPercentage = 75.0
+)
+
+// JVM memory risk levels, highest first
+const (
+	JVMRiskHigh   = "High"
+	JVMRiskMedium = "Medium"
+	JVMRiskLow    = "Low"
+)
+
+var jvmRiskOrder = map[string]int{JVMRiskHigh: 0, JVMRiskMedium: 1, JVMRiskLow: 2}
+
+// jvmOptions are the memory options of a JVM found in the container command,
+// arguments and option environment variables; the last occurrence wins like in
+// the JVM
+type jvmOptions struct {
+	source             string // Where the options were found, e.g. env JAVA_OPTS
+	maxHeap            int64  // -Xmx in bytes, 0 when unset
+	maxRAMPercentage   float64
+	noContainerSupport bool // -XX:-UseContainerSupport
+}
+
+// jvmContainer is a likely JVM container with its memory guidance
+type jvmContainer struct {
+	workload       workloadKey
+	container      string
+	pods           int
+	image          string
+	options        jvmOptions
+	reqMem, limMem int64
+	heap           int64 // Effective maximum heap in bytes, 0 when unknown
+	risk           string
+	recommendation string
+}
+
+// parseJVMSize parses a JVM size like 512m, 2g or 1048576 into bytes
+func parseJVMSize(s string) (int64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	multiplier := int64(1)
+	switch s[len(s)-1] {
+	case 'k', 'K':
+		multiplier = 1 << 10
+	case 'm', 'M':
+		multiplier = 1 << 20
+	case 'g', 'G':
+		multiplier = 1 << 30
+	case 't', 'T':
+		multiplier = 1 << 40
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n * multiplier, true
+}
+
+// parseJVMOptions applies the memory options among args to opts and reports
+// whether any was found
+func parseJVMOptions(args []string, opts *jvmOptions) bool {
+	found := false
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "-Xmx"):
+			if size, ok := parseJVMSize(strings.TrimPrefix(arg, "-Xmx")); ok {
+				opts.maxHeap, found = size, true
+			}
+		case strings.HasPrefix(arg, "-XX:MaxHeapSize="):
+			if size, ok := parseJVMSize(strings.TrimPrefix(arg, "-XX:MaxHeapSize=")); ok {
+				opts.maxHeap, found = size, true
+			}
+		case strings.HasPrefix(arg, "-XX:MaxRAMPercentage="):
+			if p, err := strconv.ParseFloat(strings.TrimPrefix(arg, "-XX:MaxRAMPercentage="), 64); err == nil {
+				opts.maxRAMPercentage, found = p, true
+			}
+		case arg == "-XX:-UseContainerSupport":
+			opts.noContainerSupport, found = true, true
+		case arg == "-XX:+UseContainerSupport":
+			opts.noContainerSupport, found = false, true
+		}
+	}
+	return found
+}
+
+// detectJVM reports whether a container likely runs a JVM and returns its
+// memory options from the command, arguments and option variables. Variables
+// set with valueFrom cannot be resolved and are ignored.
+func detectJVM(c corev1.Container) (jvmOptions, bool) {
+	var opts jvmOptions
+	jvm := false
+	for _, arg := range append(append([]string{}, c.Command...), c.Args...) {
+		if arg == "java" || strings.HasSuffix(arg, "/java") || strings.HasSuffix(arg, ".jar") {
+			jvm = true
+		}
+		for _, field := range strings.Fields(arg) { // Shell wrappers pass "java -Xmx1g -jar app.jar" as one argument
+			if parseJVMOptions([]string{field}, &opts) {
+				opts.source = "command"
+				jvm = true
+			}
+		}
+	}
+	for _, env := range c.Env {
+		for _, name := range jvmOptionVars {
+			if env.Name != name {
+				continue
+			}
+			jvm = true
+			if parseJVMOptions(strings.Fields(env.Value), &opts) {
+				opts.source = "env " + name
+			}
+		}
+	}
+	if !jvm {
+		repo := strings.ToLower(imageRepository(c.Image))
+		for _, hint := range jvmImageHints {
+			if strings.Contains(repo, hint) {
+				jvm = true
+				break
+			}
+		}
+	}
+	return opts, jvm
+}
+
+// assessJVM returns the effective maximum heap of a JVM with the memory limit
+// limMem and request reqMem in bytes, its risk level and the recommendation;
+// an empty risk means the settings look sound
+func assessJVM(opts jvmOptions, reqMem, limMem int64) (heap int64, risk, recommendation string) {
+	percentage := opts.maxRAMPercentage
+	if percentage == 0 {
+		percentage = JVMDefaultRAMPercentage
+	}
+	heap = opts.maxHeap
+	if heap == 0 && limMem > 0 && !opts.noContainerSupport {
+		heap = int64(float64(limMem) * percentage / 100)
+	}
+	recommended := func() string {
+		return fmt.Sprintf("%dMi", bytesToWholeMi(int64(float64(limMem)*JVMRecommendedPercentage/100)))
+	}
+
+	switch {
+	case limMem == 0:
+		return heap, JVMRiskMedium, "Set a memory limit: without one the heap is sized from the node memory and the pod can grow until the node evicts it"
+	case opts.noContainerSupport:
+		return heap, JVMRiskHigh, "Remove -XX:-UseContainerSupport: the JVM sizes its heap from the node memory and ignores the limit"
+	case heap >= limMem:
+		return heap, JVMRiskHigh, fmt.Sprintf("The maximum heap reaches the memory limit: lower it to %s (-XX:MaxRAMPercentage=%.0f) or raise the limit", recommended(), JVMRecommendedPercentage)
+	case float64(heap) > JVMHeapDangerRatio*float64(limMem):
+		return heap, JVMRiskHigh, fmt.Sprintf("The heap leaves %dMi for metaspace, thread stacks and direct buffers: lower it to %s or raise the limit", bytesToWholeMi(limMem-heap), recommended())
+	case opts.maxHeap == 0 && opts.maxRAMPercentage == 0:
+		return heap, JVMRiskLow, fmt.Sprintf("No heap option: the JVM uses %.0f%% of the limit, set -XX:MaxRAMPercentage=%.0f", JVMDefaultRAMPercentage, JVMRecommendedPercentage)
+	case float64(heap) < JVMHeapLowRatio*float64(limMem):
+		return heap, JVMRiskLow, fmt.Sprintf("The heap uses %.0f%% of the limit: raise it to %s or lower the limit", 100*float64(heap)/float64(limMem), recommended())
+	case reqMem > 0 && heap > reqMem:
+		return heap, JVMRiskMedium, "The memory request is below the heap: raise it to the heap plus the non-heap memory so the node is not overcommitted"
+	}
+	return heap, "", ""
+}
+
+// jvmContainers finds the likely JVM containers of active pods with memory
+// settings worth a look, one row per workload and container, highest risk first
+func jvmContainers(pods []corev1.Pod) []jvmContainer {
+	type jvmKey struct {
+		workload  workloadKey
+		container string
+	}
+	found := make(map[jvmKey]*jvmContainer)
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		workload := workloadOf(pod)
+		for _, c := range podContainers(pod) {
+			key := jvmKey{workload, c.Name}
+			if j := found[key]; j != nil {
+				j.pods++
+				continue
+			}
+			opts, ok := detectJVM(c)
+			if !ok {
+				continue
+			}
+			reqMem := quantityBytes(c.Resources.Requests.Memory())
+			limMem := quantityBytes(c.Resources.Limits.Memory())
+			heap, risk, recommendation := assessJVM(opts, reqMem, limMem)
+			if risk == "" {
+				continue
+			}
+			found[key] = &jvmContainer{
+				workload:       workload,
+				container:      c.Name,
+				pods:           1,
+				image:          c.Image,
+				options:        opts,
+				reqMem:         reqMem,
+				limMem:         limMem,
+				heap:           heap,
+				risk:           risk,
+				recommendation: recommendation,
+			}
+		}
+	}
+
+	result := make([]jvmContainer, 0, len(found))
+	for _, j := range found {
+		result = append(result, *j)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.risk != b.risk {
+			return jvmRiskOrder[a.risk] < jvmRiskOrder[b.risk]
+		}
+		if a.workload != b.workload {
+			return a.workload.String() < b.workload.String()
+		}
+		return a.container < b.container
+	})
+	return result
+}
+
+// createJVMSheet lists the JVM containers whose heap settings do not fit their
+// memory limits with a tailored recommendation
+func createJVMSheet(f *excelize.File, containers []jvmContainer, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create JVM memory sheet: %w", err)
+	}
+
+	headers := []string{
+		"Risk", "Namespace", "Workload Kind", "Workload", "Container", "Pods", "Image", "Options From",
+		"Max Heap (Mi)", "Request Memory (Mi)", "Limit Memory (Mi)", "Heap / Limit", "Recommendation",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, j := range containers {
+		var heap interface{}
+		if j.heap > 0 {
+			heap = bytesToWholeMi(j.heap)
+		}
+		data := []interface{}{
+			j.risk,
+			j.workload.namespace,
+			j.workload.kind,
+			j.workload.name,
+			j.container,
+			j.pods,
+			j.image,
+			valueOrDash(j.options.source),
+			heap,
+			bytesToWholeMi(j.reqMem),
+			bytesToWholeMi(j.limMem),
+			ratio(j.heap, j.limMem),
+			j.recommendation,
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("container '%s'", j.container)); err != nil {
+			return err
+		}
+		if j.risk == JVMRiskHigh {
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.BoldStyle(f))
+		}
+		row++
+	}
+
+	if row > 2 {
+		last := row - 1
+		f.SetCellStyle(sheetName, "I2", fmt.Sprintf("K%d", last), export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, "L2", fmt.Sprintf("L%d", last), export.PercentStyle(f, "0%"))
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 10)
+	f.SetColWidth(sheetName, "B", "E", 22)
+	f.SetColWidth(sheetName, "F", "F", 8)
+	f.SetColWidth(sheetName, "G", "G", 40)
+	f.SetColWidth(sheetName, "H", "L", 18)
+	f.SetColWidth(sheetName, "M", "M", 90)
+
+	return nil
+}
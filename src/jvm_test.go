@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseJVMSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"512m", 512 << 20, true},
+		{"2G", 2 << 30, true},
+		{"1048576", 1 << 20, true},
+		{"", 0, false},
+		{"big", 0, false},
+		{"-1g", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseJVMSize(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseJVMSize(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetectJVM(t *testing.T) {
+	tests := []struct {
+		name      string
+		container corev1.Container
+		jvm       bool
+		want      jvmOptions
+	}{
+		{"image", corev1.Container{Image: "eclipse-temurin:21-jre"}, true, jvmOptions{}},
+		{"no JVM", corev1.Container{Image: "nginx:1.27"}, false, jvmOptions{}},
+		{"env", corev1.Container{Image: "registry.example.com/shop/api:1.0", Env: []corev1.EnvVar{
+			{Name: "JAVA_OPTS", Value: "-Xms256m -Xmx1g -XX:MaxRAMPercentage=50"},
+		}}, true, jvmOptions{source: "env JAVA_OPTS", maxHeap: 1 << 30, maxRAMPercentage: 50}},
+		{"shell command", corev1.Container{Image: "app:1", Command: []string{"sh", "-c", "exec java -Xmx768m -jar /app.jar"}},
+			true, jvmOptions{source: "command", maxHeap: 768 << 20}},
+		{"container support off", corev1.Container{Image: "app:1", Args: []string{"-XX:-UseContainerSupport", "-jar", "app.jar"}},
+			true, jvmOptions{source: "command", noContainerSupport: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, jvm := detectJVM(tt.container)
+			if jvm != tt.jvm || got != tt.want {
+				t.Errorf("detectJVM() = %+v, %v, want %+v, %v", got, jvm, tt.want, tt.jvm)
+			}
+		})
+	}
+}
+
+func TestAssessJVM(t *testing.T) {
+	const gi = int64(1 << 30)
+	tests := []struct {
+		name           string
+		opts           jvmOptions
+		reqMem, limMem int64
+		heap           int64
+		risk           string
+	}{
+		{"no limit", jvmOptions{maxHeap: gi}, gi, 0, gi, JVMRiskMedium},
+		{"container support off", jvmOptions{noContainerSupport: true}, gi, 2 * gi, 0, JVMRiskHigh},
+		{"heap at limit", jvmOptions{maxHeap: 2 * gi}, 2 * gi, 2 * gi, 2 * gi, JVMRiskHigh},
+		{"heap at 90%", jvmOptions{maxRAMPercentage: 90}, 2 * gi, 2 * gi, 2 * gi * 9 / 10, JVMRiskHigh},
+		{"default heap", jvmOptions{}, 2 * gi, 2 * gi, gi / 2, JVMRiskLow},
+		{"small heap", jvmOptions{maxHeap: gi / 2}, 2 * gi, 2 * gi, gi / 2, JVMRiskLow},
+		{"request below heap", jvmOptions{maxHeap: gi * 3 / 2}, gi, 2 * gi, gi * 3 / 2, JVMRiskMedium},
+		{"sound", jvmOptions{maxRAMPercentage: 75}, 2 * gi, 2 * gi, gi * 3 / 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heap, risk, recommendation := assessJVM(tt.opts, tt.reqMem, tt.limMem)
+			if heap != tt.heap || risk != tt.risk || (risk != "") != (recommendation != "") {
+				t.Errorf("assessJVM() = %d, %q, %q, want %d, %q", heap, risk, recommendation, tt.heap, tt.risk)
+			}
+		})
+	}
+}
+
+func TestJVMContainers(t *testing.T) {
+	pod := func(name string, limit string, opts string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "shop",
+				Name:            name,
+				Labels:          map[string]string{"pod-template-hash": "abc"},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-abc"}},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name:  "api",
+				Image: "registry.example.com/shop/api:1.0",
+				Env:   []corev1.EnvVar{{Name: "JAVA_TOOL_OPTIONS", Value: opts}},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(limit)},
+					Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(limit)},
+				},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	got := jvmContainers([]corev1.Pod{pod("api-abc-1", "1Gi", "-Xmx1g"), pod("api-abc-2", "1Gi", "-Xmx1g")})
+	if len(got) != 1 {
+		t.Fatalf("jvmContainers() returned %d rows, want 1: %+v", len(got), got)
+	}
+	if j := got[0]; j.workload.name != "api" || j.pods != 2 || j.risk != JVMRiskHigh || j.options.source != "env JAVA_TOOL_OPTIONS" {
+		t.Errorf("row = %+v", j)
+	}
+
+	if got := jvmContainers([]corev1.Pod{pod("api-abc-1", "2Gi", "-XX:MaxRAMPercentage=75")}); len(got) != 0 {
+		t.Errorf("jvmContainers() = %+v, want no rows for sound settings", got)
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EvictionMemoryAvailable is the kubelet eviction signal of free node memory
+const EvictionMemoryAvailable = "memory.available"
+
+// kubeletEviction is the memory eviction thresholds of a node's kubelet
+type kubeletEviction struct {
+	hardMem int64 // memory.available of evictionHard in bytes, 0 when unset
+	softMem int64 // memory.available of evictionSoft in bytes, 0 when unset
+}
+
+// margin returns the allocatable memory kubelet evicts pods from anyway.
+// Allocatable already excludes the hard threshold, so only a higher soft
+// threshold takes memory from pods.
+func (e kubeletEviction) margin() int64 {
+	if e.softMem > e.hardMem {
+		return e.softMem - e.hardMem
+	}
+	return 0
+}
+
+// kubeletEvictions are the eviction thresholds by node name
+type kubeletEvictions map[string]kubeletEviction
+
+// kubeletConfigz is the part of the kubelet /configz response that is read
+type kubeletConfigz struct {
+	KubeletConfig struct {
+		EvictionHard map[string]string `json:"evictionHard"`
+		EvictionSoft map[string]string `json:"evictionSoft"`
+	} `json:"kubeletconfig"`
+}
+
+// parseKubeletEviction reads the memory eviction thresholds from a /configz
+// response; percentages are of the node's memory capacity
+func parseKubeletEviction(data []byte, capacity int64) (kubeletEviction, error) {
+	var configz kubeletConfigz
+	if err := json.Unmarshal(data, &configz); err != nil {
+		return kubeletEviction{}, fmt.Errorf("failed to parse kubelet config: %w", err)
+	}
+	var e kubeletEviction
+	var err error
+	if e.hardMem, err = evictionThreshold(configz.KubeletConfig.EvictionHard[EvictionMemoryAvailable], capacity); err != nil {
+		return e, fmt.Errorf("evictionHard: %w", err)
+	}
+	if e.softMem, err = evictionThreshold(configz.KubeletConfig.EvictionSoft[EvictionMemoryAvailable], capacity); err != nil {
+		return e, fmt.Errorf("evictionSoft: %w", err)
+	}
+	return e, nil
+}
+
+// evictionThreshold converts a threshold quantity or percentage of capacity to
+// bytes; empty yields 0
+func evictionThreshold(value string, capacity int64) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if percent, ok := strings.CutSuffix(value, "%"); ok {
+		p, err := strconv.ParseFloat(percent, 64)
+		if err != nil || p < 0 || p > 100 {
+			return 0, fmt.Errorf("invalid %s threshold '%s'", EvictionMemoryAvailable, value)
+		}
+		return int64(p / 100 * float64(capacity)), nil
+	}
+	q, err := resource.ParseQuantity(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s threshold '%s'", EvictionMemoryAvailable, value)
+	}
+	return q.Value(), nil
+}
+
+// fetchKubeletEvictions reads the kubelet config of every node through the API
+// server's node proxy, which needs get on nodes/proxy. Nodes whose config
+// cannot be read are left out; a forbidden proxy stops after the first node.
+func fetchKubeletEvictions(ctx context.Context, clientSet kubernetes.Interface, nodes *corev1.NodeList) kubeletEvictions {
+	evictions := make(kubeletEvictions)
+	if nodes == nil {
+		return evictions
+	}
+	failed := 0
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		data, err := clientSet.CoreV1().RESTClient().Get().
+			Resource("nodes").Name(node.Name).SubResource("proxy").Suffix("configz").DoRaw(ctx)
+		if err == nil {
+			var e kubeletEviction
+			if e, err = parseKubeletEviction(data, node.Status.Capacity.Memory().Value()); err == nil {
+				evictions[node.Name] = e
+				continue
+			}
+		}
+		if apierrors.IsForbidden(err) {
+			logrus.Warnf("Kubelet config not readable, eviction thresholds are left out: %v", err)
+			return evictions
+		}
+		logrus.Debugf("Failed to read kubelet config of node %s: %v", node.Name, err)
+		failed++
+	}
+	if failed > 0 {
+		logrus.Warnf("Failed to read the kubelet config of %s", pluralize(failed, "node"))
+	}
+	return evictions
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseKubeletEviction(t *testing.T) {
+	var capacity int64 = 16 << 30
+	soft := int64(0.05 * float64(capacity))
+	data := []byte(`{"kubeletconfig": {
+		"evictionHard": {"memory.available": "100Mi", "nodefs.available": "10%"},
+		"evictionSoft": {"memory.available": "5%"}
+	}}`)
+	e, err := parseKubeletEviction(data, capacity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 5% of 16Gi is 819.2Mi
+	if e.hardMem != 100<<20 || e.softMem != soft || e.margin() != soft-100<<20 {
+		t.Errorf("parseKubeletEviction() = %+v, margin %d", e, e.margin())
+	}
+
+	if e, err := parseKubeletEviction([]byte(`{"kubeletconfig": {}}`), capacity); err != nil || e.hardMem != 0 || e.margin() != 0 {
+		t.Errorf("parseKubeletEviction(no thresholds) = %+v, %v", e, err)
+	}
+	for _, invalid := range []string{`not json`, `{"kubeletconfig": {"evictionSoft": {"memory.available": "120%"}}}`, `{"kubeletconfig": {"evictionHard": {"memory.available": "lots"}}}`} {
+		if _, err := parseKubeletEviction([]byte(invalid), capacity); err == nil {
+			t.Errorf("parseKubeletEviction(%s) expected error", invalid)
+		}
+	}
+}
+
+func TestKubeletEvictionMargin(t *testing.T) {
+	tests := []struct {
+		name string
+		e    kubeletEviction
+		want int64
+	}{
+		{"hard only", kubeletEviction{hardMem: 100 << 20}, 0},
+		{"soft above hard", kubeletEviction{hardMem: 100 << 20, softMem: 500 << 20}, 400 << 20},
+		{"soft below hard", kubeletEviction{hardMem: 500 << 20, softMem: 100 << 20}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.e.margin(); got != tt.want {
+				t.Errorf("margin() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
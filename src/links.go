@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// rowLinkSpec is a hyperlink on a Resources sheet column as written in the
+// config file; {field} placeholders are replaced with the row's values
+//
+//	links:
+//	  - column: pod
+//	    url: "https://grafana.example.com/d/pods?var-namespace={namespace}&var-pod={pod}"
+//	    tooltip: Open in Grafana
+type rowLinkSpec struct {
+	Column  string `json:"column"`            // namespace, pod, container or node
+	URL     string `json:"url"`               // http(s) or custom scheme URL template, e.g. lens://
+	Tooltip string `json:"tooltip,omitempty"` // Shown when hovering the cell
+}
+
+// rowLink is a validated hyperlink template
+type rowLink struct {
+	name    string // Linked column, also a placeholder
+	column  int    // Resources sheet column, 1-based
+	url     string
+	tooltip string
+}
+
+// linkValues are the row values available to link placeholders
+type linkValues struct {
+	cluster, namespace, pod, container string
+	node, nodeIP                       string
+	team, workloadKind, workloadName   string
+}
+
+// linkColumns maps the linkable columns to their Resources sheet column
+var linkColumns = map[string]int{
+	"namespace": 1,  // A
+	"pod":       2,  // B
+	"container": 3,  // C
+	"node":      25, // Y
+}
+
+// linkFields maps link placeholders to row values
+var linkFields = map[string]func(linkValues) string{
+	"cluster":       func(v linkValues) string { return v.cluster },
+	"namespace":     func(v linkValues) string { return v.namespace },
+	"pod":           func(v linkValues) string { return v.pod },
+	"container":     func(v linkValues) string { return v.container },
+	"node":          func(v linkValues) string { return v.node },
+	"node_ip":       func(v linkValues) string { return v.nodeIP },
+	"team":          func(v linkValues) string { return v.team },
+	"workload_kind": func(v linkValues) string { return v.workloadKind },
+	"workload_name": func(v linkValues) string { return v.workloadName },
+}
+
+// parseRowLinks validates the link templates of the config file, at most one
+// per column
+func parseRowLinks(specs []rowLinkSpec) ([]rowLink, error) {
+	links := make([]rowLink, 0, len(specs))
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		name := strings.ToLower(strings.TrimSpace(spec.Column))
+		column, ok := linkColumns[name]
+		if !ok {
+			return nil, fmt.Errorf("link on unknown column '%s' (valid: namespace, pod, container, node)", spec.Column)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("more than one link on column '%s'", name)
+		}
+		seen[name] = true
+
+		for _, match := range formulaPlaceholder.FindAllStringSubmatch(spec.URL, -1) {
+			if _, ok := linkFields[match[1]]; !ok {
+				return nil, fmt.Errorf("link on column '%s' references unknown field '%s'", name, match[1])
+			}
+		}
+		// Placeholders are not valid URL characters everywhere, e.g. in the host
+		u, err := url.Parse(formulaPlaceholder.ReplaceAllString(spec.URL, "x"))
+		if err != nil || u.Scheme == "" {
+			return nil, fmt.Errorf("link on column '%s' needs an absolute URL, got '%s'", name, spec.URL)
+		}
+		links = append(links, rowLink{name: name, column: column, url: spec.URL, tooltip: spec.Tooltip})
+	}
+	return links, nil
+}
+
+// target returns the link URL of a row with the placeholders replaced by the
+// escaped row values
+func (l rowLink) target(values linkValues) string {
+	return formulaPlaceholder.ReplaceAllStringFunc(l.url, func(placeholder string) string {
+		return url.QueryEscape(linkFields[strings.Trim(placeholder, "{}")](values))
+	})
+}
+
+// setRowLinks turns the linked cells of a Resources sheet row into hyperlinks,
+// skipping empty ones such as the node of a pending pod. Excel caps the
+// hyperlinks of a sheet; cells past the cap stay plain text.
+func setRowLinks(f *excelize.File, sheetName string, row int, links []rowLink, values linkValues) error {
+	for _, link := range links {
+		if linkFields[link.name](values) == "" {
+			continue
+		}
+		cell, err := excelize.CoordinatesToCellName(link.column, row)
+		if err != nil {
+			return fmt.Errorf("failed to get cell name for link: %w", err)
+		}
+		var opts []excelize.HyperlinkOpts
+		if link.tooltip != "" {
+			tooltip := link.tooltip
+			opts = append(opts, excelize.HyperlinkOpts{Tooltip: &tooltip})
+		}
+		if err := f.SetCellHyperLink(sheetName, cell, link.target(values), "External", opts...); err != nil {
+			if errors.Is(err, excelize.ErrTotalSheetHyperlinks) {
+				return nil
+			}
+			return fmt.Errorf("failed to set link on %s: %w", cell, err)
+		}
+		f.SetCellStyle(sheetName, cell, cell, getLinkStyle(f))
+	}
+	return nil
+}
+
+// getLinkStyle shows a hyperlink cell as Excel does: blue and underlined
+func getLinkStyle(f *excelize.File) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Color: "0563C1", Underline: "single"},
+	})
+	return style
+}
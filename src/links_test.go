@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestParseRowLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []rowLinkSpec
+		wantErr bool
+	}{
+		{"none", nil, false},
+		{"pod", []rowLinkSpec{{Column: "Pod", URL: "https://grafana.example.com/d/pods?var-namespace={namespace}&var-pod={pod}"}}, false},
+		{"custom scheme", []rowLinkSpec{{Column: "node", URL: "lens://app/cluster/{cluster}/nodes/{node}"}}, false},
+		{"placeholder in host", []rowLinkSpec{{Column: "namespace", URL: "https://{cluster}.portal.example.com/ns/{namespace}"}}, false},
+		{"unknown column", []rowLinkSpec{{Column: "status", URL: "https://example.com"}}, true},
+		{"duplicate column", []rowLinkSpec{{Column: "pod", URL: "https://a.example.com"}, {Column: "pod", URL: "https://b.example.com"}}, true},
+		{"unknown field", []rowLinkSpec{{Column: "pod", URL: "https://example.com/{image}"}}, true},
+		{"relative URL", []rowLinkSpec{{Column: "pod", URL: "/pods/{pod}"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseRowLinks(tt.specs); (err != nil) != tt.wantErr {
+				t.Errorf("parseRowLinks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRowLinkTarget(t *testing.T) {
+	links, err := parseRowLinks([]rowLinkSpec{{Column: "pod", URL: "https://grafana.example.com/d/pods?var-cluster={cluster}&var-pod={pod}&team={team}"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := linkValues{cluster: "prod", pod: "web-1", team: "payments & search"}
+	want := "https://grafana.example.com/d/pods?var-cluster=prod&var-pod=web-1&team=payments+%26+search"
+	if got := links[0].target(values); got != want {
+		t.Errorf("target() = %q, want %q", got, want)
+	}
+}
+
+func TestSetRowLinks(t *testing.T) {
+	links, err := parseRowLinks([]rowLinkSpec{
+		{Column: "pod", URL: "https://portal.example.com/{namespace}/{pod}", Tooltip: "Open in portal"},
+		{Column: "node", URL: "https://portal.example.com/nodes/{node}"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := setRowLinks(f, "Sheet1", 3, links, linkValues{namespace: "shop", pod: "web-1", node: "n1"}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, target, _ := f.GetCellHyperLink("Sheet1", "B3"); !ok || target != "https://portal.example.com/shop/web-1" {
+		t.Errorf("B3 link = %v, %q", ok, target)
+	}
+	if ok, target, _ := f.GetCellHyperLink("Sheet1", "Y3"); !ok || target != "https://portal.example.com/nodes/n1" {
+		t.Errorf("Y3 link = %v, %q", ok, target)
+	}
+
+	// A pending pod has no node to link
+	if err := setRowLinks(f, "Sheet1", 4, links, linkValues{namespace: "shop", pod: "web-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _, _ := f.GetCellHyperLink("Sheet1", "Y4"); ok {
+		t.Errorf("Y4 links an empty node")
+	}
+}
@@ -13,10 +13,17 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ohauer/PodResourceCalculator/pkg/collector"
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
 	"github.com/sirupsen/logrus"
 	"github.com/xuri/excelize/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -88,16 +95,202 @@ func validateNamespace(namespace string) error {
 }
 
 func main() {
+	cmd, args, err := splitCommand(os.Args[1:])
+	if err != nil {
+		logrus.Fatalf("Invalid command: %v", err)
+	}
+	switch cmd {
+	case CommandSimulate:
+		if err := runSimulate(args); err != nil {
+			logrus.Fatalf("Simulation failed: %v", err)
+		}
+		return
+	case CommandWebhook:
+		if err := runWebhook(args); err != nil {
+			logrus.Fatalf("Webhook failed: %v", err)
+		}
+		return
+	case CommandRender:
+		err := runRender(args)
+		if isFindingsError(err) {
+			logrus.Errorf("Validation failed: %v", err)
+			os.Exit(ExitFindings)
+		}
+		if err != nil {
+			logrus.Fatalf("Render failed: %v", err)
+		}
+		return
+	case CommandCompare:
+		if err := runCompare(args); err != nil {
+			logrus.Fatalf("Compare failed: %v", err)
+		}
+		return
+	case CommandFleet:
+		if err := runFleet(args); err != nil {
+			logrus.Fatalf("Fleet rollup failed: %v", err)
+		}
+		return
+	case CommandParity:
+		if err := runParity(args); err != nil {
+			logrus.Fatalf("Parity check failed: %v", err)
+		}
+		return
+	case CommandIngestReview:
+		if err := runIngestReview(args); err != nil {
+			logrus.Fatalf("Review ingest failed: %v", err)
+		}
+		return
+	case CommandInit:
+		if err := runInit(args, filepath.Base(os.Args[0])); err != nil {
+			logrus.Fatalf("Setup failed: %v", err)
+		}
+		return
+	case CommandSelfUpdate:
+		if err := runSelfUpdate(args); err != nil {
+			logrus.Fatalf("Self-update failed: %v", err)
+		}
+		return
+	}
+
 	var (
 		namespace  = flag.String("namespace", os.Getenv("K8S_NAMESPACE"), "Kubernetes namespace (default: all namespaces)")
-		kubeconfig = flag.String("kubeconfig", "", "Path to kubeconfig file (default: ~/.kube/config)")
-		output     = flag.String("output", "", "Output filename (default: resource_YYYY-MM-DD.xlsx)")
+		optOutKey  = flag.String("exclude-annotation", "", "Omit pods and namespaces with this annotation set to \"true\", none to report them (default: "+collector.DefaultExcludeAnnotation+")")
+		nsPattern  = flag.String("namespace-pattern", "", "Select namespaces whose names match these comma separated globs (team-*-prod) or /regular expressions/")
+		contextNS  = flag.Bool("use-context-namespace", false, "Without -namespace, use the namespace of the kubeconfig context like kubectl instead of all namespaces")
+		kubeconfig = kubeconfigFlag(flag.CommandLine)
+		output     = flag.String("output", "", "Output filename, - for stdout with -format bi or json (default: resource_YYYY-MM-DD.xlsx)")
 		verbose    = flag.Bool("verbose", false, "Enable verbose logging")
+		quiet      = flag.Bool("quiet", false, "Only log errors (logs always go to stderr)")
+		teamMap    = flag.String("team-mapping", "", "Path or URL of a team mapping file (YAML/JSON) adding ownership columns")
+		usageFile  = flag.String("usage-history", "", "Path of a container usage history file (YAML/JSON), e.g. exported from Prometheus, for the Startup Spikes sheet")
+		baseline   = flag.String("baseline", "", "Path of a baseline file written with -save-baseline; the Baseline sheet marks requests that changed since")
+		baseThresh = flag.Int("baseline-threshold", DefaultBaselineThreshold, "Mark namespaces and workloads whose requests changed by more than N% against -baseline")
+		saveBase   = flag.String("save-baseline", "", "Also write the namespace and workload requests of this run as a baseline file")
+		reviewFile = flag.String("review", "", "Path of a review file (YAML/JSON, see ingest-review); accepted and rejected findings are acknowledged")
+		splitBy    = flag.String("split-by", "", "Also write one workbook per group: label:<key>, team or namespace")
+		configPath = flag.String("config", "", "Path to config file (YAML/JSON) with report settings")
+		sheets     = flag.String("sheets", "", "Comma-separated sheets to generate (default: all, see README)")
+		rawQty     = flag.Bool("raw-quantities", false, "Add canonical/exact quantity columns for precision audits")
+		sortBy     = flag.String("sort-by", "", "Sort Resources rows, e.g. request_cpu:desc,namespace (see README)")
+		groupByPod = flag.Bool("group-by-pod", false, "Group container rows under collapsible pod subtotal rows")
+		nsSubtotal = flag.Bool("namespace-subtotals", false, "Insert a subtotal row per namespace in the Resources sheet")
+		changeDays = flag.Int("change-days", 0, "List Deployment resource changes rolled out in the last N days (0 = off)")
+		forecastHr = flag.Int("cronjob-forecast", 0, "Forecast the requests of overlapping CronJob runs over the next N hours on the CronJob Forecast sheet (0 = off)")
+		jobAuditOn = flag.Bool("job-audit", false, "Add the Job Audit sheet: Job and CronJob runs, requests per run, concurrency and request-hours")
+		withUsage  = flag.Bool("with-usage", false, "Add actual CPU and memory usage from metrics-server (metrics.k8s.io) and usage % of request columns to the Resources sheet")
+		promURL    = flag.String("prometheus-url", "", "Prometheus base URL for historical container usage quantiles on the Resources sheet (bearer token from PROMETHEUS_TOKEN)")
+		promQuants = flag.String("prometheus-quantiles", DefaultPrometheusQuantiles, "Comma-separated usage quantiles queried with -prometheus-url")
+		promDays   = flag.Int("prometheus-days", DefaultPrometheusDays, "Lookback window of -prometheus-url in days")
+		eventHours = flag.Int("event-hours", DefaultEventHours, "Summarize the Warning events of the last N hours per namespace and reason on the Events sheet (0 = off)")
+		kubeletCfg = flag.Bool("kubelet-config", false, "Read each node's kubelet config through the node proxy to add eviction thresholds to the Kubelet Reserved sheet")
+		timezone   = flag.String("timezone", "", "Time zone for report timestamps, e.g. Europe/Berlin (default: local time)")
+		clusterArg = flag.String("cluster-name", "", "Cluster name for the filename and Overview sheet (default: from kubeconfig context)")
+		ascii      = flag.Bool("ascii", false, "Plain-ASCII output: no emoji or unicode decorations in sheets and logs")
+		themeName  = flag.String("theme", "", "Workbook color theme: default, light, dark or cvd (color-vision-deficiency safe)")
+		language   = flag.String("language", "", "Language of the Insights sheet text: en or de; others via messages in the config file")
+		idleDays   = flag.Int("idle-days", DefaultIdleDays, "Report namespaces without pod creations or restarts for N days as idle (0 = off)")
+		failedDays = flag.Int("failed-pod-days", DefaultFailedPodDays, "List failed pods older than N days on the Cleanup sheet")
+		topoKeys   = flag.String("topology-keys", DefaultTopologyKeys, "Comma separated node labels of the failure domains on the Topology sheet, e.g. topology.kubernetes.io/zone,rack")
+		pendingPod = flag.String("pending-pods", PendingInclude, "Pending pods in totals and percentages: include, separate (own Pending Pods summary only) or exclude")
+		headroomPc = flag.Int("headroom-percent", DefaultHeadroomPercent, "Size overprovisioning pause pods to keep N% of each node pool's requests free")
+		gitops     = flag.Bool("gitops", false, "Add Argo CD / Flux owner columns (application and source repository)")
+		gitopsPR   = flag.String("gitops-pr", "", "Write the recommended requests to the GitOps repositories of the workloads: dry-run prints the diff, push pushes a branch (requires -gitops and -prometheus-url)")
+		failOn     = flag.String("fail-on", "", "Exit with code 2 when validation findings reach this severity: info, warn or error")
+		findings   = flag.String("findings", "", "Also write validation findings to this file, - for stdout (JSON, or SARIF for *.sarif)")
+		findingsAs = flag.String("findings-format", "", "Findings file format: json or sarif (default: from file extension)")
+		serve      = flag.String("serve", "", "Server mode: listen on this address (e.g. :8080), serve the report and regenerate it when pods change")
+		grpcAddr   = flag.String("grpc-listen", "", "Server mode: also serve the REST API views over gRPC on this address (e.g. :9090)")
+		cacheTTL   = flag.Duration("snapshot-ttl", DefaultSnapshotTTL, "Server mode: reuse a cluster snapshot this long before scanning again (0 = always scan)")
+		cfgReload  = flag.Duration("config-reload", DefaultConfigReload, "Server mode: check -config this often and apply changed thresholds, sheets and alerts without a restart (0 = off)")
+		format     = flag.String("format", FormatXLSX, "Output format: xlsx (report workbook), bi (flat table for Power BI, CSV or single-table workbook), json (containers, totals and insights) or markdown (totals, insights and findings as tables)")
+		appendTo   = flag.String("append", "", "Also add this run's namespace summary as a dated sheet to this multi-run workbook and update its Trend sheet")
+		csvDelim   = flag.String("csv-delimiter", "", "BI CSV field separator, a single character or 'tab', e.g. ';' for European Excel locales (default: ',')")
+		decComma   = flag.Bool("decimal-comma", false, "BI CSV: write decimals with a comma (requires a -csv-delimiter other than ',')")
+		wireFormat = flag.String("api-encoding", APIEncodingProtobuf, "Wire encoding of API requests: protobuf or json")
+		cpuProfile = flag.String("profile", "", "Write a CPU profile of the run to this file (go tool pprof)")
+		traceFile  = flag.String("trace", "", "Write an execution trace of the run to this file (go tool trace)")
+		pprofAddr  = flag.String("pprof", "", "Server mode: serve pprof endpoints on this address, e.g. localhost:6060")
+		otelURL    = flag.String("otel-endpoint", "", "Export OpenTelemetry traces over OTLP/HTTP to this collector, e.g. http://otel-collector:4318 (default: OTEL_EXPORTER_OTLP_ENDPOINT)")
+		memLimit   = flag.String("memory-limit", "", "Soft memory limit of the Go runtime, e.g. 200Mi, or auto for 90% of the container limit (default: GOMEMLIMIT)")
+		gcPercent  = flag.Int("gc-percent", 0, "GC target percentage, lower trades CPU for memory; -1 collects only at -memory-limit (default: GOGC)")
+		chunkSize  = flag.Int64("chunk-size", 0, "With -list-mode consistent, list pods in pages of N to bound memory (0 = single list)")
+		listMode   = flag.String("list-mode", collector.ListModeAuto, "Pod list semantics: auto, stream (WatchList), cache (resourceVersion=0) or consistent (from etcd)")
+		metaCache  = flag.String("metadata-cache", "", "Cache node and namespace metadata in this file; later runs only fetch the changes since (default: off)")
+		metaMaxAge = flag.Duration("metadata-cache-max-age", collector.DefaultMetadataCacheMaxAge, "List nodes and namespaces fully when the metadata cache is older than this")
+		bundlePath = flag.String("bundle", "", "Write an offline bundle (snapshot and render settings, .gz compressed) for the render subcommand instead of the report")
 	)
-	flag.Parse()
+	program := filepath.Base(os.Args[0])
+	flag.Usage = func() { printUsage(flag.CommandLine.Output(), program, flag.CommandLine) }
+	switch cmd {
+	case CommandHelp:
+		printUsage(os.Stdout, program, flag.CommandLine)
+		return
+	case CommandCompletion:
+		if len(args) != 1 {
+			logrus.Fatalf("Usage: %s completion bash|zsh|fish", program)
+		}
+		script, err := completionScript(args[0], program, commandFlags(flag.CommandLine))
+		if err != nil {
+			logrus.Fatalf("Invalid shell: %v", err)
+		}
+		fmt.Print(script)
+		return
+	}
+	// Exits with the usage on invalid flags
+	_ = flag.CommandLine.Parse(args)
+	if flag.NArg() > 0 {
+		logrus.Fatalf("Invalid arguments: unexpected '%s' (flags start with '-')", strings.Join(flag.Args(), " "))
+	}
+	if cmd == CommandServe && *serve == "" {
+		*serve = DefaultListenAddr
+	}
 
-	if *verbose {
-		logrus.SetLevel(logrus.DebugLevel)
+	if err := configureLogging(*verbose, *quiet); err != nil {
+		logrus.Fatalf("Invalid flags: %v", err)
+	}
+	if *ascii {
+		logrus.SetFormatter(&logrus.TextFormatter{DisableColors: true})
+	}
+	if err := configureMemory(*memLimit, *gcPercent); err != nil {
+		logrus.Fatalf("Invalid memory settings: %v", err)
+	}
+	for _, path := range []string{*cpuProfile, *traceFile} {
+		if err := validatePath(path); err != nil {
+			logrus.Fatalf("Invalid profile path: %v", err)
+		}
+	}
+	if *pprofAddr != "" && *serve == "" {
+		logrus.Fatalf("Invalid flags: -pprof requires server mode, use -profile or -trace for one-shot runs")
+	}
+	if *grpcAddr != "" && *serve == "" {
+		logrus.Fatalf("Invalid flags: -grpc-listen requires server mode")
+	}
+	stopProfiling, err := startProfiling(*cpuProfile, *traceFile)
+	if err != nil {
+		logrus.Fatalf("Failed to start profiling: %v", err)
+	}
+	defer stopProfiling()
+	logrus.RegisterExitHandler(stopProfiling)
+	stopTracing, err := startTracing(*otelURL)
+	if err != nil {
+		logrus.Fatalf("Invalid tracing settings: %v", err)
+	}
+	defer stopTracing()
+	logrus.RegisterExitHandler(stopTracing)
+
+	// Validate kubeconfig paths
+	if err := kubeconfig.validate(); err != nil {
+		logrus.Fatalf("Invalid kubeconfig path: %v", err)
+	}
+
+	// Namespace of the kubeconfig context, like kubectl without -n
+	if *contextNS && *namespace == "" && *nsPattern == "" {
+		ns, err := contextNamespace(kubeconfig.String())
+		if err != nil {
+			logrus.Fatalf("Failed to determine the context namespace: %v", err)
+		}
+		logrus.Infof("Using namespace of the current context: %s", ns)
+		*namespace = ns
 	}
 
 	// Validate namespace
@@ -106,59 +299,966 @@ func main() {
 			logrus.Fatalf("Invalid namespace: %v", err)
 		}
 	}
+	split, err := parseSplitBy(*splitBy)
+	if err != nil {
+		logrus.Fatalf("Invalid split-by: %v", err)
+	}
 
-	// Validate kubeconfig path
-	if *kubeconfig != "" {
-		if err := validatePath(*kubeconfig); err != nil {
-			logrus.Fatalf("Invalid kubeconfig path: %v", err)
-		}
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		logrus.Fatalf("Failed to load config: %v", err)
+	}
+	overrides := configOverrides{
+		timezone:          *timezone,
+		failOn:            *failOn,
+		sheets:            *sheets,
+		theme:             *themeName,
+		language:          *language,
+		excludeAnnotation: *optOutKey,
+		namespacePattern:  *nsPattern,
+		check:             cmd == CommandCheck,
+	}
+	overrides.apply(cfg)
+
+	selector, err := parseNamespacePatterns(cfg.NamespacePattern)
+	if err != nil {
+		logrus.Fatalf("Invalid namespace-pattern: %v", err)
+	}
+	if selector != nil && *namespace != "" {
+		logrus.Fatalf("Invalid flags: -namespace and -namespace-pattern are mutually exclusive")
+	}
+
+	// Report timestamps use the configured time zone instead of implicit local time
+	location, err := loadTimezone(cfg.Timezone)
+	if err != nil {
+		logrus.Fatalf("Invalid timezone: %v", err)
+	}
+	now := time.Now().In(location)
+
+	// Cluster identity for the default filename and report metadata
+	cluster := resolveClusterIdentity(kubeconfig.String(), *clusterArg)
+	if cluster.name != "" {
+		logrus.Infof("Cluster: %s", cluster.name)
+	}
+
+	reportFormat, err := parseFormat(*format)
+	if err != nil {
+		logrus.Fatalf("Invalid format: %v", err)
 	}
 
 	// Validate output filename
-	filename := getOutputFilename(*output)
+	filename := getOutputFilename(*output, filenameClusterName(cluster.name), now)
+	if reportFormat == FormatBI && *output == "" {
+		filename = biFilename(filename)
+	}
+	if reportFormat == FormatJSON && *output == "" {
+		filename = jsonFilename(filename)
+	}
+	if reportFormat == FormatMarkdown && *output == "" {
+		filename = markdownFilename(filename)
+	}
 	if err := validatePath(filename); err != nil {
 		logrus.Fatalf("Invalid output filename: %v", err)
 	}
 
-	clientSet, err := getK8sClient(*kubeconfig)
+	excludeKey, err := collector.ParseExcludeAnnotation(cfg.ExcludeAnnotation)
+	if err != nil {
+		logrus.Fatalf("Invalid exclude annotation: %v", err)
+	}
+	settings := renderSettings{
+		Cluster:            cluster.name,
+		Context:            cluster.context,
+		Namespace:          *namespace,
+		NamespacePattern:   cfg.NamespacePattern,
+		SplitBy:            *splitBy,
+		RawQuantities:      *rawQty,
+		SortBy:             *sortBy,
+		GroupByPod:         *groupByPod,
+		NamespaceSubtotals: *nsSubtotal,
+		ASCII:              *ascii,
+		IdleDays:           *idleDays,
+		FailedPodDays:      *failedDays,
+		HeadroomPercent:    *headroomPc,
+		BaselineThreshold:  *baseThresh,
+		PendingPods:        *pendingPod,
+		TopologyKeys:       *topoKeys,
+		ForecastHours:      *forecastHr,
+		Format:             reportFormat,
+		CSVDelimiter:       *csvDelim,
+		DecimalComma:       *decComma,
+	}
+	csvFormat, err := settings.csvDialect(filename)
+	if err != nil {
+		logrus.Fatalf("Invalid flags: %v", err)
+	}
+	if *teamMap != "" {
+		mappingCtx, mappingCancel := context.WithTimeout(context.Background(), DefaultAPITimeout)
+		teams, err := loadTeamMapping(mappingCtx, *teamMap)
+		mappingCancel()
+		if err != nil {
+			logrus.Fatalf("Failed to load team mapping: %v", err)
+		}
+		settings.Teams = teams
+		logrus.Infof("Loaded team mapping with %d teams", len(teams.Teams))
+	}
+	if *usageFile != "" {
+		history, err := loadUsageHistory(*usageFile)
+		if err != nil {
+			logrus.Fatalf("Failed to load usage history: %v", err)
+		}
+		settings.UsageHistory = history
+		logrus.Infof("Loaded usage history of %d containers", len(history.Containers))
+	}
+	if *baseline != "" {
+		b, err := loadBaseline(*baseline)
+		if err != nil {
+			logrus.Fatalf("Failed to load baseline: %v", err)
+		}
+		settings.Baseline = b
+		logrus.Infof("Loaded baseline of %s with %d namespaces", b.Collected.Format(time.RFC3339), len(b.Namespaces))
+	}
+	if *reviewFile != "" {
+		review, err := loadReview(*reviewFile)
+		if err != nil {
+			logrus.Fatalf("Failed to load review: %v", err)
+		}
+		settings.Review = review
+		logrus.Infof("Loaded %s", pluralize(len(review.Decisions), "review decision"))
+	}
+	opts, err := settings.reportOptions(cfg, now)
+	if err != nil {
+		logrus.Fatalf("Invalid settings: %v", err)
+	}
+	if *findings != "" {
+		if reportFormat != FormatXLSX {
+			logrus.Fatalf("Invalid flags: -findings requires the xlsx format")
+		}
+		if err := validatePath(*findings); err != nil {
+			logrus.Fatalf("Invalid findings path: %v", err)
+		}
+		opts.findingsPath = *findings
+		if opts.findingsFormat, err = findingsFormat(*findings, *findingsAs); err != nil {
+			logrus.Fatalf("Invalid findings format: %v", err)
+		}
+	}
+	if split != nil && split.kind == "team" && opts.teams == nil {
+		logrus.Fatalf("Invalid split-by: 'team' requires -team-mapping")
+	}
+	if err := validateStdout(filename, reportFormat, opts.findingsPath, split != nil, *serve != ""); err != nil {
+		logrus.Fatalf("Invalid flags: %v", err)
+	}
+
+	podListMode, err := collector.ParseListMode(*listMode)
+	if err != nil {
+		logrus.Fatalf("Invalid list-mode: %v", err)
+	}
+	if *chunkSize < 0 || *chunkSize > 0 && podListMode != collector.ListModeConsistent {
+		logrus.Fatalf("Invalid chunk-size: must not be negative and requires -list-mode consistent (streamed lists already arrive pod by pod)")
+	}
+
+	prometheus, err := newPrometheusSource(*promURL, *promQuants, *promDays)
+	if err != nil {
+		logrus.Fatalf("Invalid prometheus flags: %v", err)
+	}
+	if *eventHours < 0 {
+		logrus.Fatalf("Invalid event-hours: must not be negative")
+	}
+	if err := validateGitOpsPR(*gitopsPR); err != nil {
+		logrus.Fatalf("Invalid gitops-pr: %v", err)
+	}
+	if *gitopsPR != "" {
+		if !*gitops || prometheus == nil {
+			logrus.Fatalf("Invalid flags: -gitops-pr requires -gitops and -prometheus-url")
+		}
+		if *serve != "" || *bundlePath != "" || cmd == CommandCheck {
+			logrus.Fatalf("Invalid flags: -gitops-pr cannot be combined with check, -serve or -bundle")
+		}
+		if *gitopsPR == GitOpsPRDryRun && (filename == StdoutPath || opts.findingsPath == StdoutPath) {
+			logrus.Fatalf("Invalid flags: -gitops-pr dry-run prints its diff to stdout, write the other outputs to files")
+		}
+	}
+
+	encoding, err := parseAPIEncoding(*wireFormat)
+	if err != nil {
+		logrus.Fatalf("Invalid api-encoding: %v", err)
+	}
+	clientSet, err := getK8sClient(kubeconfig.String(), encoding)
 	if err != nil {
 		logrus.Fatalf("Failed to connect to Kubernetes: %v", err)
 	}
 
-	logrus.Infof("Fetching pods from namespace: %s", getNamespaceDisplay(*namespace))
+	job := reportJob{
+		clientSet:  clientSet,
+		kubeconfig: kubeconfig.String(),
+		namespace:  *namespace,
+		selector:   selector,
+		excludeKey: excludeKey,
+		changeDays: *changeDays,
+		jobAudit:   *jobAuditOn,
+		kubelet:    *kubeletCfg,
+		liveUsage:  *withUsage,
+		eventHours: *eventHours,
+		prometheus: prometheus,
+		gitops:     *gitops,
+		gitopsPR:   *gitopsPR,
+		split:      split,
+		filename:   filename,
+		format:     reportFormat,
+		csv:        csvFormat,
+		opts:       opts,
+		pods:       collector.NewPodLister(podListMode, *chunkSize),
+	}
+	if *metaCache != "" {
+		if err := validatePath(*metaCache); err != nil {
+			logrus.Fatalf("Invalid metadata cache path: %v", err)
+		}
+		if *metaMaxAge <= 0 {
+			logrus.Fatalf("Invalid metadata-cache-max-age: must be positive")
+		}
+		job.metadataCache = &collector.MetadataCache{Path: *metaCache, MaxAge: *metaMaxAge, Cluster: cluster.cacheKey()}
+	}
+	if job.alerts, err = parseAlerts(cfg.Alerts, cluster.name); err != nil {
+		logrus.Fatalf("Invalid config: %v", err)
+	}
+	if *appendTo != "" {
+		if *serve != "" || *bundlePath != "" {
+			logrus.Fatalf("Invalid flags: -append cannot be combined with -serve or -bundle")
+		}
+		if err := validatePath(*appendTo); err != nil {
+			logrus.Fatalf("Invalid append path: %v", err)
+		}
+		job.appendPath = *appendTo
+	}
+	if *saveBase != "" {
+		if *serve != "" || *bundlePath != "" || cmd == CommandCheck {
+			logrus.Fatalf("Invalid flags: -save-baseline cannot be combined with check, -serve or -bundle")
+		}
+		if err := validatePath(*saveBase); err != nil {
+			logrus.Fatalf("Invalid baseline path: %v", err)
+		}
+		job.baselinePath = *saveBase
+	}
+	if cmd == CommandCheck {
+		if *serve != "" || *bundlePath != "" || *appendTo != "" {
+			logrus.Fatalf("Invalid flags: check cannot be combined with -serve, -bundle or -append")
+		}
+		err := job.check(now)
+		if isFindingsError(err) {
+			logrus.Errorf("Validation failed: %v", err)
+			logrus.Exit(ExitFindings) // Runs the exit handlers that finish profiles and traces
+		}
+		if err != nil {
+			logrus.Fatalf("Check failed: %v", err)
+		}
+		return
+	}
+	if *bundlePath != "" {
+		if *serve != "" {
+			logrus.Fatalf("Invalid flags: -bundle and -serve are mutually exclusive")
+		}
+		if err := validatePath(*bundlePath); err != nil {
+			logrus.Fatalf("Invalid bundle path: %v", err)
+		}
+		snap, err := job.collect(context.Background(), now)
+		if err != nil {
+			logrus.Fatalf("Failed to collect snapshot: %v", err)
+		}
+		if err := writeBundle(*bundlePath, newOfflineBundle(snap, settings, cfg)); err != nil {
+			logrus.Fatalf("Failed to write bundle: %v", err)
+		}
+		logrus.Infof("Offline bundle created: %s", *bundlePath)
+		return
+	}
+	if *serve != "" {
+		if reportFormat != FormatXLSX {
+			logrus.Fatalf("Invalid flags: server mode serves the xlsx format only")
+		}
+		if *cacheTTL < 0 {
+			logrus.Fatalf("Invalid snapshot-ttl: must not be negative")
+		}
+		if *pprofAddr != "" {
+			go servePprof(*pprofAddr)
+		}
+		var watcher *configWatcher
+		if *configPath != "" && *cfgReload > 0 {
+			reload := func(job reportJob, now time.Time) (reportJob, error) {
+				return reloadJob(job, *configPath, overrides, settings, now)
+			}
+			if watcher, err = newConfigWatcher(*configPath, *cfgReload, reload); err != nil {
+				logrus.Fatalf("Failed to watch config: %v", err)
+			}
+		}
+		if err := serveReports(*serve, *grpcAddr, job, location, *cacheTTL, watcher); err != nil {
+			logrus.Fatalf("Server failed: %v", err)
+		}
+		return
+	}
+
+	started := time.Now()
+	err = job.run(now)
+	if isFindingsError(err) {
+		job.alerts.observe(nil, time.Since(started))
+		logrus.Errorf("Validation failed: %v", err)
+		logrus.Exit(ExitFindings)
+	}
+	job.alerts.observe(err, time.Since(started))
+	if err != nil {
+		logrus.Fatalf("Failed to generate report: %v", err)
+	}
+}
+
+// renderSettings are the flags that shape the workbook. Offline bundles store
+// them next to the config file so the render subcommand builds the same report.
+type renderSettings struct {
+	Cluster            string            `json:"cluster,omitempty"`
+	Context            string            `json:"context,omitempty"`
+	Namespace          string            `json:"namespace,omitempty"`
+	NamespacePattern   string            `json:"namespacePattern,omitempty"`
+	SplitBy            string            `json:"splitBy,omitempty"`
+	Teams              *teamMapping      `json:"teams,omitempty"`
+	UsageHistory       *usageHistory     `json:"usageHistory,omitempty"`
+	Baseline           *baselineSnapshot `json:"baseline,omitempty"`
+	Review             *reviewFile       `json:"review,omitempty"`
+	RawQuantities      bool              `json:"rawQuantities,omitempty"`
+	SortBy             string            `json:"sortBy,omitempty"`
+	GroupByPod         bool              `json:"groupByPod,omitempty"`
+	NamespaceSubtotals bool              `json:"namespaceSubtotals,omitempty"`
+	ASCII              bool              `json:"ascii,omitempty"`
+	IdleDays           int               `json:"idleDays"`
+	FailedPodDays      int               `json:"failedPodDays"`
+	HeadroomPercent    int               `json:"headroomPercent,omitempty"`
+	BaselineThreshold  int               `json:"baselineThreshold,omitempty"`
+	PendingPods        string            `json:"pendingPods,omitempty"`
+	TopologyKeys       string            `json:"topologyKeys,omitempty"`
+	ForecastHours      int               `json:"forecastHours,omitempty"`
+	Format             string            `json:"format,omitempty"`
+	CSVDelimiter       string            `json:"csvDelimiter,omitempty"`
+	DecimalComma       bool              `json:"decimalComma,omitempty"`
+}
+
+// csvDialect returns the CSV dialect of a report written to filename; the
+// locale settings only apply to CSV BI exports
+func (s renderSettings) csvDialect(filename string) (csvDialect, error) {
+	if s.CSVDelimiter == "" && !s.DecimalComma {
+		return defaultCSVDialect, nil
+	}
+	if s.Format != FormatBI || !isCSV(filename) {
+		return defaultCSVDialect, fmt.Errorf("csv-delimiter and decimal-comma require a CSV BI export (-format bi)")
+	}
+	return parseCSVDialect(s.CSVDelimiter, s.DecimalComma)
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// reportOptions builds the render options of a report generated at now from
+// the settings and the config file
+func (s renderSettings) reportOptions(cfg *config, now time.Time) (reportOptions, error) {
+	var err error
+	opts := reportOptions{rawQuantities: s.RawQuantities, groupByPod: s.GroupByPod, namespaceSubtotals: s.NamespaceSubtotals, plainText: s.ASCII, teams: s.Teams, usage: s.UsageHistory, baseline: s.Baseline}
+	opts.metadata = reportMetadata{cluster: clusterIdentity{name: s.Cluster, context: s.Context}, namespace: s.Namespace, pattern: s.NamespacePattern, generated: now}
+	opts.idleAfter = time.Duration(s.IdleDays) * 24 * time.Hour
+	opts.failedPodAge = time.Duration(s.FailedPodDays) * 24 * time.Hour
+	switch {
+	case s.HeadroomPercent == 0: // Bundles written before the setting existed
+		opts.headroomPercent = DefaultHeadroomPercent
+	case s.HeadroomPercent < 0 || s.HeadroomPercent > 100:
+		return opts, fmt.Errorf("headroom-percent must be between 1 and 100, got %d", s.HeadroomPercent)
+	default:
+		opts.headroomPercent = s.HeadroomPercent
+	}
+	switch {
+	case s.BaselineThreshold == 0: // Bundles written before the setting existed
+		opts.baselineThreshold = DefaultBaselineThreshold
+	case s.BaselineThreshold < 0:
+		return opts, fmt.Errorf("baseline-threshold must be positive, got %d", s.BaselineThreshold)
+	default:
+		opts.baselineThreshold = s.BaselineThreshold
+	}
+	if s.ForecastHours < 0 {
+		return opts, fmt.Errorf("cronjob-forecast must not be negative, got %d", s.ForecastHours)
+	}
+	opts.forecastHorizon = time.Duration(s.ForecastHours) * time.Hour
+	if opts.pendingPods, err = parsePendingMode(s.PendingPods); err != nil {
+		return opts, fmt.Errorf("invalid pending-pods: %w", err)
+	}
+	if opts.topologyKeys, err = parseTopologyKeys(s.TopologyKeys); err != nil {
+		return opts, fmt.Errorf("invalid topology-keys: %w", err)
+	}
+	if opts.tshirtSizes, err = parseTShirtSizes(cfg.TShirtSizes); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.customColumns, err = parseCustomColumns(cfg.Columns); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.links, err = parseRowLinks(cfg.Links); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.agents, err = parseAgents(cfg.Agents); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.validation, err = parseValidationRules(cfg.Validation); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	opts.validation.review = s.Review
+	if opts.pricing, err = parsePricing(cfg.Pricing); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.computeUnit, err = parseComputeUnit(cfg.ComputeUnit); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.security, err = parseSecurityRules(cfg.Security); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.extendedResources, err = parseExtendedResources(cfg.ExtendedResources); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.hooks, err = parseHooks(cfg.Hooks, reservedColumns(opts)); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.sheets, err = parseSheetSelection(cfg.Sheets); err != nil {
+		return opts, fmt.Errorf("invalid sheet selection: %w", err)
+	}
+	if opts.theme, err = parseTheme(cfg.Theme); err != nil {
+		return opts, fmt.Errorf("invalid theme: %w", err)
+	}
+	if opts.scoring, err = parseScoring(cfg.Scoring); err != nil {
+		return opts, fmt.Errorf("invalid config: %w", err)
+	}
+	if opts.messages, err = parseMessages(cfg.Language, cfg.Messages); err != nil {
+		return opts, fmt.Errorf("invalid language: %w", err)
+	}
+	if opts.sortKeys, err = parseSortKeys(s.SortBy); err != nil {
+		return opts, fmt.Errorf("invalid sort-by: %w", err)
+	}
+	return opts, nil
+}
+
+// reportJob fetches the cluster data and writes the report and its split
+// workbooks; server mode runs it again whenever the pods changed
+type reportJob struct {
+	clientSet    kubernetes.Interface
+	kubeconfig   string
+	namespace    string
+	selector     *namespaceSelector // -namespace-pattern, nil for all namespaces
+	excludeKey   string             // Opt-out annotation, empty to report annotated objects too
+	changeDays   int
+	jobAudit     bool              // Collect Job runs for the Job Audit sheet
+	kubelet      bool              // Read kubelet configs for their eviction thresholds
+	liveUsage    bool              // Query metrics-server for the current container usage
+	eventHours   int               // Window of the Events sheet, 0 disables it
+	prometheus   *prometheusSource // Usage quantiles source, nil without -prometheus-url
+	gitops       bool
+	gitopsPR     string // -gitops-pr mode, empty to leave the GitOps repositories alone
+	split        *splitSpec
+	filename     string
+	format       string     // FormatXLSX, FormatBI, FormatJSON or FormatMarkdown
+	csv          csvDialect // Locale of CSV BI exports
+	appendPath   string     // Multi-run workbook receiving each run's summary, empty to skip
+	baselinePath string     // Baseline file written from each run, empty to skip
+	alerts       *alerter   // Failure and duration SLO notifications, nil without alerts config
+	opts         reportOptions
+
+	metadataCache *collector.MetadataCache // Node and namespace cache, nil to list them on every run
+	pods          *collector.PodLister     // List semantics of the pod lists, nil for consistent lists
+}
+
+// clusterSnapshot is the cluster data of one collection, rendered into the
+// workbooks and served by the server mode API
+type clusterSnapshot struct {
+	collected       time.Time
+	pods            []corev1.Pod
+	resourceVersion string                      // Of the pod list
+	refreshed       map[string]namespaceRefresh // Namespaces whose pods were listed again since
+	namespaces      *corev1.NamespaceList       // nil when namespaces could not be listed
+	nodes           *corev1.NodeList            // nil when nodes could not be listed
+	evictions       kubeletEvictions            // nil without -kubelet-config
+	metrics         containerMetrics            // nil without -with-usage
+	quantiles       *usageQuantiles             // nil without -prometheus-url
+	events          []warningEvents             // nil when not collected
+	resourceChanges []resourceChange
+	hpaScaling      []hpaScaling
+	statefulSets    []statefulSetFootprint
+	finishedJobs    map[workloadKey]bool
+	jobRuns         []jobRun
+	cronJobs        []cronJobSpec
+	gitops          *gitopsIndex
+	excluded        map[string]bool // Namespaces opted out by annotation, their pods are dropped
+	pending         []corev1.Pod    // Pending pods held back from pods by -pending-pods separate or exclude
+	pendingMode     string          // -pending-pods mode of pending, empty when Pending pods stay in pods
+}
+
+// run collects a snapshot at now and renders it. A findingsError is returned
+// only after all workbooks have been written.
+func (j reportJob) run(now time.Time) (err error) {
+	ctx, span := tracer.Start(context.Background(), "report", trace.WithAttributes(attribute.String("k8s.namespace.name", getNamespaceDisplay(j.namespace))))
+	defer func() { endSpan(span, err) }()
+
+	snap, err := j.collect(ctx, now)
+	if err != nil {
+		return err
+	}
+	return j.render(ctx, snap)
+}
+
+// collect fetches the cluster data of one report at now
+func (j reportJob) collect(ctx context.Context, now time.Time) (_ *clusterSnapshot, err error) {
+	ctx, span := tracer.Start(ctx, "collect")
+	defer func() { endSpan(span, err) }()
+
+	snap := &clusterSnapshot{collected: now}
+
+	logrus.Infof("Fetching pods from namespace: %s", getNamespaceDisplay(j.namespace))
+	if j.selector != nil {
+		logrus.Infof("Selecting namespaces matching: %s", j.selector.patterns)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	pods, err := clientSet.CoreV1().Pods(*namespace).List(ctx, metav1.ListOptions{})
+	// Pods, namespaces for PSS data and nodes for capacity data, without the
+	// opted out ones
+	listed, err := collector.List(ctx, j.clientSet, collector.Options{
+		Namespace:         j.namespace,
+		Lister:            j.pods,
+		MetadataCache:     j.metadataCache,
+		ExcludeAnnotation: j.excludeKey,
+		Now:               func() time.Time { return now },
+	})
 	if err != nil {
-		logrus.Fatalf("Failed to list pods: %v", err)
+		return nil, err
+	}
+	for _, warning := range listed.Warnings {
+		logrus.Warnf("Incomplete cluster data: %s", warning)
 	}
+	snap.pods = j.selector.pods(listed.Pods)
+	snap.resourceVersion = listed.ResourceVersion
+	snap.excluded = listed.Excluded
+	span.SetAttributes(attribute.Int("k8s.pod.count", len(listed.Pods)))
 
-	logrus.Infof("Found %d pods", len(pods.Items))
+	logrus.Infof("Found %d pods", len(snap.pods))
+
+	snap.nodes = listed.Nodes
+	snap.namespaces = j.selector.namespaces(listed.Namespaces)
+
+	// Fetch kubelet configs for the eviction thresholds of the nodes
+	if j.kubelet && snap.nodes != nil && j.opts.sheets.enabled(SheetReserved) {
+		snap.evictions = fetchKubeletEvictions(ctx, j.clientSet, snap.nodes)
+		logrus.Infof("Read the kubelet config of %s", pluralize(len(snap.evictions), "node"))
+	}
+
+	// Fetch the current container usage from metrics-server
+	if j.liveUsage && j.opts.sheets.enabled(SheetResources) {
+		metrics, err := fetchPodMetrics(ctx, j.clientSet, j.namespace)
+		if err != nil {
+			logrus.Warnf("Failed to read pod metrics, the usage columns stay empty: %v", err)
+			metrics = containerMetrics{}
+		}
+		snap.metrics = metrics.retain(snap.pods)
+		logrus.Infof("Read the usage of %s", pluralize(len(snap.metrics), "container"))
+	}
 
-	// Fetch namespaces for PSS data
-	namespaces, err := clientSet.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	// Fetch the historical usage quantiles from Prometheus
+	if j.prometheus != nil && j.wantsQuantiles() {
+		quantiles, err := j.prometheus.fetch(ctx, j.namespace)
+		if err != nil {
+			logrus.Warnf("Failed to read usage quantiles, the quantile columns stay empty: %v", err)
+			quantiles = &usageQuantiles{quantiles: j.prometheus.quantiles}
+		}
+		snap.quantiles = quantiles.retain(snap.pods)
+		logrus.Infof("Read %d-day usage quantiles of %s", j.prometheus.days, pluralize(len(snap.quantiles.containers), "container"))
+	}
+
+	// Fetch the Warning events that explain pending pods, restarts and evictions
+	if j.eventHours > 0 && j.opts.sheets.enabled(SheetEvents) {
+		events, err := fetchWarningEvents(ctx, j.clientSet, j.namespace)
+		if err != nil {
+			logrus.Warnf("Failed to list events for the events sheet: %v", err)
+		} else {
+			snap.events = j.selector.events(summarizeWarningEvents(events, now.Add(-time.Duration(j.eventHours)*time.Hour)))
+			logrus.Infof("Summarized the Warning events of the last %d hours into %d namespace and reason rows", j.eventHours, len(snap.events))
+		}
+	}
+
+	// Fetch ReplicaSets for Deployment rollout history
+	if j.changeDays > 0 {
+		replicaSets, err := j.clientSet.AppsV1().ReplicaSets(j.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logrus.Warnf("Failed to list replicasets for resource change history: %v", err)
+		} else {
+			since := now.AddDate(0, 0, -j.changeDays)
+			snap.resourceChanges = j.selector.resourceChanges(resourceChanges(replicaSets.Items, since))
+			logrus.Infof("Found %d container resource changes in the last %d days", len(snap.resourceChanges), j.changeDays)
+		}
+	}
+
+	// Fetch HPAs to flag workloads pinned at their replica bounds
+	if j.opts.sheets.enabled(SheetScaling) {
+		hpas, err := j.clientSet.AutoscalingV2().HorizontalPodAutoscalers(j.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logrus.Warnf("Failed to list horizontal pod autoscalers for scaling anomalies: %v", err)
+		} else {
+			snap.hpaScaling = j.selector.hpaScaling(hpaScalingStates(hpas.Items))
+		}
+	}
+
+	// Fetch StatefulSets to combine their compute with their claim templates
+	if j.opts.sheets.enabled(SheetStatefulSets) {
+		statefulSets, err := j.clientSet.AppsV1().StatefulSets(j.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logrus.Warnf("Failed to list statefulsets for their footprint: %v", err)
+		} else {
+			snap.statefulSets = j.selector.statefulSets(statefulSetFootprints(statefulSets.Items))
+		}
+	}
+
+	// Fetch Jobs to find finished Jobs whose pods still hold requests, for the
+	// Job Audit sheet and the run times of the CronJob forecast
+	var runs []jobRun
+	if j.opts.sheets.enabled(SheetCleanup) || j.jobAudit || j.opts.forecastHorizon > 0 {
+		jobs, err := j.clientSet.BatchV1().Jobs(j.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logrus.Warnf("Failed to list jobs for cleanup candidates, job audit and forecast: %v", err)
+		} else {
+			snap.finishedJobs = finishedJobs(jobs.Items)
+			runs = jobRuns(jobs.Items)
+			if j.jobAudit {
+				snap.jobRuns = j.selector.jobRuns(runs)
+				logrus.Infof("Found %s", pluralize(len(snap.jobRuns), "started Job"))
+			}
+		}
+	}
+
+	// Fetch CronJobs to forecast the requests of their runs
+	if j.opts.forecastHorizon > 0 {
+		cronJobs, err := j.clientSet.BatchV1().CronJobs(j.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logrus.Warnf("Failed to list cronjobs for the forecast: %v", err)
+		} else {
+			snap.cronJobs = j.selector.cronJobs(cronJobSpecs(cronJobs.Items, runs))
+			logrus.Infof("Found %s", pluralize(len(snap.cronJobs), "CronJob"))
+		}
+	}
+
+	// Resolve Argo CD / Flux ownership of workloads
+	if j.gitops {
+		var dyn dynamic.Interface
+		if restConfig, err := getRestConfig(j.kubeconfig); err == nil {
+			dyn, err = dynamic.NewForConfig(restConfig)
+			if err != nil {
+				logrus.Warnf("Failed to create dynamic client for GitOps repositories: %v", err)
+			}
+		}
+		snap.gitops, err = loadGitOpsIndex(ctx, j.clientSet, dyn, j.namespace)
+		if err != nil {
+			logrus.Warnf("Failed to resolve GitOps ownership: %v", err)
+		} else {
+			logrus.Infof("Found %d GitOps managed workloads", len(snap.gitops.workloads))
+		}
+	}
+
+	dropExcluded(snap)
+	separatePending(snap, j.opts.pendingPods)
+	return snap, nil
+}
+
+// listNamespacePods lists the pods of one namespace for a partial refresh
+func (j reportJob) listNamespacePods(namespace string) (_ *corev1.PodList, err error) {
+	ctx, span := tracer.Start(context.Background(), "collect namespace", trace.WithAttributes(attribute.String("k8s.namespace.name", namespace)))
+	defer func() { endSpan(span, err) }()
+
+	ctx, cancel := context.WithTimeout(ctx, DefaultAPITimeout)
+	defer cancel()
+
+	pods, err := j.pods.List(ctx, j.clientSet, namespace)
 	if err != nil {
-		logrus.Warnf("Failed to list namespaces for PSS data: %v", err)
-		namespaces = nil
+		return nil, fmt.Errorf("failed to list pods in namespace '%s': %w", namespace, err)
 	}
+	pods.Items = collector.OptedOutPods(pods.Items, j.excludeKey, nil)
+	return pods, nil
+}
+
+// check collects the cluster data and evaluates the validation rules without
+// writing a report; the findings are written when a findings path is set
+func (j reportJob) check(now time.Time) (err error) {
+	ctx, span := tracer.Start(context.Background(), "check", trace.WithAttributes(attribute.String("k8s.namespace.name", getNamespaceDisplay(j.namespace))))
+	defer func() { endSpan(span, err) }()
 
-	// Fetch nodes for capacity data
-	nodes, err := clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	snap, err := j.collect(ctx, now)
 	if err != nil {
-		logrus.Warnf("Failed to list nodes for capacity data: %v", err)
-		nodes = nil
+		return err
+	}
+	_, validateSpan := tracer.Start(ctx, "validate")
+	findings := snapshotFindings(snap, j.opts.validation, j.opts.forecastHorizon)
+	validateSpan.End()
+	if j.opts.findingsPath != "" {
+		meta := j.opts.metadata
+		meta.generated = snap.collected
+		if err := writeFindings(j.opts.findingsPath, j.opts.findingsFormat, findings, meta); err != nil {
+			return err
+		}
+		logrus.Infof("Findings written: %s", outputName(j.opts.findingsPath))
 	}
+	j.notifyConditions(snap)
+	return j.opts.validation.failingFindings(findings)
+}
 
-	if err := generateExcel(pods.Items, namespaces, nodes, filename); err != nil {
-		logrus.Fatalf("Failed to generate Excel file: %v", err)
+// render writes the workbook and the split workbooks of a snapshot, or the
+// BI or JSON export of each with -format bi or json
+func (j reportJob) render(ctx context.Context, snap *clusterSnapshot) (err error) {
+	ctx, span := tracer.Start(ctx, "render", trace.WithAttributes(attribute.String("report.format", j.format)))
+	defer func() {
+		if isFindingsError(err) {
+			endSpan(span, nil) // Findings are a result, not a failed render
+			return
+		}
+		endSpan(span, err)
+	}()
+
+	switch j.format {
+	case FormatBI:
+		err = j.renderBI(snap)
+	case FormatJSON:
+		err = j.renderJSON(snap)
+	case FormatMarkdown:
+		err = j.renderMarkdown(snap)
+	default:
+		err = j.renderWorkbooks(ctx, snap)
+	}
+	if err != nil && !isFindingsError(err) {
+		return err
 	}
 
-	logrus.Infof("Excel file created: %s", filename)
+	if j.appendPath != "" {
+		if err := appendRun(j.appendPath, snap); err != nil {
+			return fmt.Errorf("failed to append run: %w", err)
+		}
+		logrus.Infof("Run appended to %s", j.appendPath)
+	}
+
+	if j.baselinePath != "" {
+		if err := writeBaseline(j.baselinePath, newBaseline(snap.pods, snap.collected)); err != nil {
+			return err
+		}
+		logrus.Infof("Baseline written: %s", j.baselinePath)
+	}
+
+	if j.gitopsPR != "" {
+		openRemediations(ctx, snap, j.gitopsPR, stdout)
+	}
+
+	j.notifyConditions(snap)
+
+	return err
+}
+
+// notifyConditions sends the findings of the alert condition rules of a snapshot
+func (j reportJob) notifyConditions(snap *clusterSnapshot) {
+	if j.alerts.watchesConditions() {
+		j.alerts.notifyConditions(validateResources(snapshotValidationInput(snap, j.opts.forecastHorizon), j.alerts.conditionRules(j.opts.validation)))
+	}
+}
+
+// wantsQuantiles reports whether the usage quantiles are used: by the
+// Resources sheet, -gitops-pr or the savings-opportunity rule or alert condition
+func (j reportJob) wantsQuantiles() bool {
+	return j.opts.sheets.enabled(SheetResources) || j.gitopsPR != "" ||
+		j.opts.validation.rules[RuleSavingsOpportunity].severity != severityOff ||
+		j.alerts.watchesConditions() && j.alerts.conditions[RuleSavingsOpportunity]
+}
+
+// renderWorkbooks writes the workbook and the split workbooks of a snapshot
+func (j reportJob) renderWorkbooks(ctx context.Context, snap *clusterSnapshot) error {
+	opts := j.opts
+	opts.metadata.generated = snap.collected
+	opts.resourceChanges = snap.resourceChanges
+	opts.hpaScaling = snap.hpaScaling
+	opts.evictions = snap.evictions
+	opts.metrics = snap.metrics
+	opts.quantiles = snap.quantiles
+	opts.events = snap.events
+	opts.statefulSets = snap.statefulSets
+	opts.finishedJobs = snap.finishedJobs
+	opts.jobRuns = snap.jobRuns
+	opts.cronJobs = snap.cronJobs
+	opts.gitops = snap.gitops
+	opts.pending = snap.pending
+	opts.traceContext = ctx
+	if len(opts.hooks) > 0 {
+		_, hookSpan := tracer.Start(ctx, "hooks", trace.WithAttributes(attribute.Int("report.hooks", len(opts.hooks))))
+		enriched, err := runHooks(opts.hooks, snap, opts.metadata, reservedColumns(opts))
+		endSpan(hookSpan, err)
+		if err != nil {
+			return err
+		}
+		opts.enrichment = enriched
+	}
+
+	findingsErr := generateExcel(snap.pods, snap.namespaces, snap.nodes, j.filename, opts)
+	if findingsErr != nil && !isFindingsError(findingsErr) {
+		return fmt.Errorf("failed to generate Excel file: %w", findingsErr)
+	}
+
+	logrus.Infof("Excel file created: %s", j.filename)
+
+	if j.split != nil {
+		groups := splitPods(snap.pods, j.split, namespaceLabelIndex(snap.namespaces), opts.teams)
+		pendingGroups := splitPods(snap.pending, j.split, namespaceLabelIndex(snap.namespaces), opts.teams)
+		names := sortedGroups(groups)
+		files := splitFilenames(j.filename, names)
+		for _, group := range names {
+			groupFile := files[group]
+			groupOpts := opts
+			groupOpts.pending = pendingGroups[group]
+			groupOpts.metadata.group = group
+			groupOpts.findingsPath = "" // Findings of the full report cover all groups
+			groupOpts.jobRuns = nil     // So do its Job audit and CronJob forecast
+			groupOpts.cronJobs = nil
+			if opts.events != nil {
+				groupOpts.events = eventsOfPods(opts.events, groups[group])
+			}
+			groupOpts.enrichment = opts.enrichment.columnsOnly()
+			groupOpts.clusterPods = snap.pods
+			if err := generateExcel(groups[group], filterNamespaces(snap.namespaces, groups[group]), snap.nodes, groupFile, groupOpts); err != nil && !isFindingsError(err) {
+				return fmt.Errorf("failed to generate Excel file for group '%s': %w", group, err)
+			}
+			logrus.Infof("Excel file created for group '%s': %s", group, groupFile)
+		}
+	}
+
+	return findingsErr
+}
+
+// renderBI writes the flat BI export of a snapshot and of each split group
+func (j reportJob) renderBI(snap *clusterSnapshot) error {
+	meta := j.opts.metadata
+	meta.generated = snap.collected
+
+	rows := biRows(snap.pods, snap.nodes, snap.namespaces, meta, j.opts.teams)
+	if err := writeBIExport(j.filename, rows, j.csv); err != nil {
+		return fmt.Errorf("failed to write BI export: %w", err)
+	}
+	logrus.Infof("BI export created: %s (%d rows)", outputName(j.filename), len(rows))
+
+	if j.split != nil {
+		groups := splitPods(snap.pods, j.split, namespaceLabelIndex(snap.namespaces), j.opts.teams)
+		names := sortedGroups(groups)
+		files := splitFilenames(j.filename, names)
+		for _, group := range names {
+			groupFile := files[group]
+			groupMeta := meta
+			groupMeta.group = group
+			if err := writeBIExport(groupFile, biRows(groups[group], snap.nodes, snap.namespaces, groupMeta, j.opts.teams), j.csv); err != nil {
+				return fmt.Errorf("failed to write BI export for group '%s': %w", group, err)
+			}
+			logrus.Infof("BI export created for group '%s': %s", group, groupFile)
+		}
+	}
+	return nil
+}
+
+// renderJSON writes the JSON export of a snapshot and of each split group
+func (j reportJob) renderJSON(snap *clusterSnapshot) error {
+	doc := buildJSONReport(snap, j.opts, j.opts.metadata)
+	if err := writeJSONReport(j.filename, doc); err != nil {
+		return fmt.Errorf("failed to write JSON export: %w", err)
+	}
+	logrus.Infof("JSON export created: %s (%d containers)", outputName(j.filename), len(doc.Containers))
+
+	if j.split != nil {
+		groups := splitPods(snap.pods, j.split, namespaceLabelIndex(snap.namespaces), j.opts.teams)
+		names := sortedGroups(groups)
+		files := splitFilenames(j.filename, names)
+		for _, group := range names {
+			groupFile := files[group]
+			groupSnap := *snap
+			groupSnap.pods = groups[group]
+			groupSnap.namespaces = filterNamespaces(snap.namespaces, groups[group])
+			groupMeta := j.opts.metadata
+			groupMeta.group = group
+			if err := writeJSONReport(groupFile, buildJSONReport(&groupSnap, j.opts, groupMeta)); err != nil {
+				return fmt.Errorf("failed to write JSON export for group '%s': %w", group, err)
+			}
+			logrus.Infof("JSON export created for group '%s': %s", group, groupFile)
+		}
+	}
+	return nil
+}
+
+// renderMarkdown writes the Markdown summary of a snapshot and of each split group
+func (j reportJob) renderMarkdown(snap *clusterSnapshot) error {
+	doc := buildJSONReport(snap, j.opts, j.opts.metadata)
+	if err := writeMarkdownReport(j.filename, doc); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+	logrus.Infof("Markdown report created: %s (%d namespaces)", outputName(j.filename), len(doc.Namespaces))
+
+	if j.split != nil {
+		groups := splitPods(snap.pods, j.split, namespaceLabelIndex(snap.namespaces), j.opts.teams)
+		names := sortedGroups(groups)
+		files := splitFilenames(j.filename, names)
+		for _, group := range names {
+			groupFile := files[group]
+			groupSnap := *snap
+			groupSnap.pods = groups[group]
+			groupSnap.namespaces = filterNamespaces(snap.namespaces, groups[group])
+			groupMeta := j.opts.metadata
+			groupMeta.group = group
+			if err := writeMarkdownReport(groupFile, buildJSONReport(&groupSnap, j.opts, groupMeta)); err != nil {
+				return fmt.Errorf("failed to write Markdown report for group '%s': %w", group, err)
+			}
+			logrus.Infof("Markdown report created for group '%s': %s", group, groupFile)
+		}
+	}
+	return nil
+}
+
+// Wire encodings of -api-encoding
+const (
+	APIEncodingProtobuf = "protobuf" // Smaller pod lists and cheaper decoding than JSON
+	APIEncodingJSON     = "json"     // For proxies or debugging tools that only handle JSON
+)
+
+// parseAPIEncoding validates -api-encoding; empty selects protobuf
+func parseAPIEncoding(s string) (string, error) {
+	switch s {
+	case "", APIEncodingProtobuf:
+		return APIEncodingProtobuf, nil
+	case APIEncodingJSON:
+		return APIEncodingJSON, nil
+	}
+	return "", fmt.Errorf("unknown API encoding '%s' (valid: %s, %s)", s, APIEncodingProtobuf, APIEncodingJSON)
+}
+
+// setAPIEncoding configures the wire encoding of the built-in resources.
+// Protobuf still accepts JSON, which the server sends for types without a
+// protobuf encoding.
+func setAPIEncoding(config *rest.Config, encoding string) {
+	if encoding == APIEncodingJSON {
+		config.ContentType = k8sruntime.ContentTypeJSON
+		config.AcceptContentTypes = k8sruntime.ContentTypeJSON
+		return
+	}
+	config.ContentType = k8sruntime.ContentTypeProtobuf
+	config.AcceptContentTypes = k8sruntime.ContentTypeProtobuf + "," + k8sruntime.ContentTypeJSON
+}
+
+func getK8sClient(kubeconfigPath, encoding string) (kubernetes.Interface, error) {
+	config, err := getRestConfig(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	setAPIEncoding(config, encoding)
+	logrus.Debugf("Using %s encoding for API requests", encoding)
+
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	return clientSet, nil
 }
 
-func getK8sClient(kubeconfigPath string) (kubernetes.Interface, error) {
+// getRestConfig builds the client configuration from the in-cluster environment
+// or the kubeconfig files of kubeconfigRules
+func getRestConfig(kubeconfigPath string) (*rest.Config, error) {
 	var config *rest.Config
 	var err error
 
@@ -168,24 +1268,15 @@ func getK8sClient(kubeconfigPath string) (kubernetes.Interface, error) {
 		config, err = rest.InClusterConfig()
 	} else {
 		logrus.Debug("Using kubeconfig file")
-		if kubeconfigPath == "" {
-			if home := homeDir(); home != "" {
-				kubeconfigPath = filepath.Join(home, ".kube", "config")
-			}
-		}
-		config, err = clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(kubeconfigRules(kubeconfigPath), &clientcmd.ConfigOverrides{}).ClientConfig()
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to build config: %w", err)
 	}
+	traceRequests(config)
 
-	clientSet, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create clientset: %w", err)
-	}
-
-	return clientSet, nil
+	return config, nil
 }
 
 func homeDir() string {
@@ -202,45 +1293,232 @@ func getNamespaceDisplay(namespace string) string {
 	return namespace
 }
 
-func getOutputFilename(output string) string {
+func getOutputFilename(output, cluster string, now time.Time) string {
 	if output != "" {
 		return filepath.Clean(output)
 	}
-	return fmt.Sprintf("resource_%s.xlsx", time.Now().Format("2006-01-02"))
+	if cluster != "" {
+		return fmt.Sprintf("resource_%s_%s.xlsx", cluster, now.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("resource_%s.xlsx", now.Format("2006-01-02"))
+}
+
+// namespaceTotal aggregates container resources of a namespace
+type namespaceTotal struct {
+	reqCPU, limCPU int64
+	reqMem, limMem int64
+}
+
+// nodeTotal aggregates pod resources scheduled on a node together with its capacity
+type nodeTotal struct {
+	podCount           int
+	reqCPU, limCPU     int64
+	reqMem, limMem     int64
+	capCPU, capMem     int64 // Capacity (total)
+	allocCPU, allocMem int64 // Allocatable (capacity - system reservations)
+	nodeName, nodeIP   string
+}
+
+// aggregateTotals sums container requests and limits of active pods per
+// namespace and per node (keyed by host IP), with node capacity from the nodes list
+func aggregateTotals(pods []corev1.Pod, nodes *corev1.NodeList) (map[string]namespaceTotal, map[string]nodeTotal) {
+	nsSums, nodeSums := report.Aggregate(pods, nodes)
+	namespaceTotals := make(map[string]namespaceTotal, len(nsSums))
+	for ns, t := range nsSums {
+		namespaceTotals[ns] = namespaceTotal{reqCPU: t.RequestCPU, limCPU: t.LimitCPU, reqMem: t.RequestMemory, limMem: t.LimitMemory}
+	}
+	nodeTotals := make(map[string]nodeTotal, len(nodeSums))
+	for ip, t := range nodeSums {
+		nodeTotals[ip] = nodeTotal{
+			podCount: t.Pods,
+			reqCPU:   t.RequestCPU,
+			limCPU:   t.LimitCPU,
+			reqMem:   t.RequestMemory,
+			limMem:   t.LimitMemory,
+			capCPU:   t.CapacityCPU,
+			capMem:   t.CapacityMemory,
+			allocCPU: t.AllocatableCPU,
+			allocMem: t.AllocatableMemory,
+			nodeName: t.Name,
+			nodeIP:   t.IP,
+		}
+	}
+	return namespaceTotals, nodeTotals
+}
+
+// reportOptions holds optional report features selected on the command line
+type reportOptions struct {
+	teams              *teamMapping           // Ownership enrichment, nil when no mapping was given
+	usage              *usageHistory          // Container usage samples, nil disables the Startup Spikes sheet
+	baseline           *baselineSnapshot      // Stored requests to compare against, nil disables the Baseline sheet
+	tshirtSizes        []tshirtSize           // Size classes, defaults when empty
+	customColumns      []customColumn         // User-defined computed columns from the config file
+	links              []rowLink              // Resources sheet hyperlinks from the config file
+	agents             []agentSpec            // Platform agents, defaults when empty
+	validation         validationRules        // Validation rule settings, defaults when zero
+	findingsPath       string                 // Findings export file, empty to skip
+	findingsFormat     string                 // FindingsFormatJSON or FindingsFormatSARIF
+	pricing            *pricingSpec           // Cost model, nil disables the Cost sheet
+	computeUnit        *computeUnit           // Chargeback unit, nil disables the Compute Units sheet
+	security           securityRules          // Thresholds of the Security Anomalies sheet
+	extendedResources  []extendedResource     // Mapped device plugin resources with Resources sheet columns
+	sheets             sheetSelection         // Enabled sheets, nil for all
+	rawQuantities      bool                   // Add canonical/exact quantity audit columns
+	sortKeys           []sortKey              // Resources sheet row order, pod order when empty
+	groupByPod         bool                   // Group container rows under collapsible pod subtotal rows
+	namespaceSubtotals bool                   // Insert a subtotal row above each namespace's rows
+	theme              theme                  // Colors of color-coded cells, default theme when zero
+	scoring            scoringModel           // Efficiency ratings and recommendations, default model when nil
+	messages           messageCatalog         // Translations of the Insights text, English when nil
+	plainText          bool                   // Replace emoji and unicode decorations with ASCII
+	metadata           reportMetadata         // Cluster, scope and generation time for the Overview sheet
+	idleAfter          time.Duration          // Inactivity before a namespace counts as idle, 0 disables
+	failedPodAge       time.Duration          // Age from which failed pods are cleanup candidates
+	headroomPercent    int                    // Share of each node pool's requests kept free by pause pods
+	baselineThreshold  int                    // Request change in percent from which the Baseline sheet marks rows
+	pendingPods        string                 // PendingInclude, PendingSeparate or PendingExclude, include when empty
+	pending            []corev1.Pod           // Pending pods held back from the workbook's pods by -pending-pods separate or exclude
+	clusterPods        []corev1.Pod           // Pods of the whole cluster behind the "% of Cluster" columns of a split group, nil for the workbook's pods
+	topologyKeys       []string               // Node labels of the failure domains on the Topology sheet
+	finishedJobs       map[workloadKey]bool   // Completed or failed Jobs, nil when not collected
+	jobRuns            []jobRun               // Started Jobs for the Job Audit sheet, nil when not collected
+	cronJobs           []cronJobSpec          // CronJobs for the CronJob Forecast sheet, nil when not collected
+	forecastHorizon    time.Duration          // Hours ahead of the CronJob forecast, 0 disables
+	hpaScaling         []hpaScaling           // HPA replica states, nil when not collected
+	evictions          kubeletEvictions       // Kubelet eviction thresholds, nil when not collected
+	metrics            containerMetrics       // Current container usage, nil disables the usage columns
+	quantiles          *usageQuantiles        // Historical usage quantiles, nil disables the quantile columns
+	events             []warningEvents        // Warning events per namespace and reason, nil disables the Events sheet
+	statefulSets       []statefulSetFootprint // StatefulSet compute and claim templates, nil when not collected
+	resourceChanges    []resourceChange       // Recent Deployment resource changes, nil when not collected
+	gitops             *gitopsIndex           // Argo CD / Flux owner columns, nil when not collected
+	hooks              []hook                 // Enrichment commands from the config file
+	enrichment         *enrichment            // Columns and sheets returned by the hooks, nil without hooks
+	traceContext       context.Context        // Parent of the workbook spans, nil for a new trace
+}
+
+// resourceHeaders are the Resources sheet columns of every report
+var resourceHeaders = []string{
+	"Namespace", "Pod", "Container",
+	"Request CPU (m)", "Request CPU", "Request Memory (Mi)", "Request Memory",
+	"Limit CPU (m)", "Limit CPU", "Limit Memory (Mi)", "Limit Memory",
+	"Pod Age", "Restart Count", "Last Restart",
+	"Request Storage (Gi)", "Request Storage", "Limit Storage (Gi)", "Limit Storage",
+	"Request GPU", "Request GPU (str)", "Limit GPU", "Limit GPU (str)",
+	"Status", "QoS Class", "Node",
+	"CPU Efficiency %", "Memory Efficiency %", "CPU % of Cluster", "Memory % of Cluster",
+	"T-Shirt Size",
+}
+
+// teamHeaders and gitopsHeaders are the Resources sheet columns added with a
+// team mapping and with GitOps ownership
+var (
+	teamHeaders   = []string{"Team", "Owner", "Owner Email"}
+	gitopsHeaders = []string{"GitOps Tool", "GitOps App", "GitOps Repo"}
+)
+
+// resourceRow is a buffered Resources sheet row, written after optional sorting
+type resourceRow struct {
+	data    []interface{}
+	fields  rowFields  // Input for custom columns
+	links   linkValues // Input for hyperlinks
+	context string     // Error context, e.g. "pod 'x' container 'y'"
 }
 
-func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *corev1.NodeList, filename string) error {
-	f := excelize.NewFile()
+func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *corev1.NodeList, filename string, opts reportOptions) (err error) {
+	ctx, span := tracer.Start(contextOrBackground(opts.traceContext), "workbook", trace.WithAttributes(
+		attribute.String("report.file", outputName(filename)),
+		attribute.Int("k8s.pod.count", len(pods)),
+	))
 	defer func() {
-		if err := f.Close(); err != nil {
-			logrus.Warnf("Failed to close Excel file: %v", err)
+		if isFindingsError(err) {
+			endSpan(span, nil)
+			return
 		}
+		endSpan(span, err)
 	}()
 
+	if len(opts.tshirtSizes) == 0 {
+		opts.tshirtSizes, _ = parseTShirtSizes(defaultTShirtSizes)
+	}
+	if len(opts.agents) == 0 {
+		opts.agents = defaultAgents
+	}
+	if opts.validation.rules == nil {
+		opts.validation, _ = parseValidationRules(validationSpec{})
+	}
+	if opts.theme == (theme{}) {
+		opts.theme = themes[DefaultTheme]
+	}
+	if opts.scoring == nil {
+		opts.scoring = thresholdScoring{report.ScoringModels[DefaultScoringModel]}
+	}
+	if opts.metadata.generated.IsZero() {
+		opts.metadata.generated = time.Now()
+	}
+	if opts.pendingPods == "" {
+		opts.pendingPods = PendingInclude
+	}
+
+	// Pending pods hold no node resources yet; with separate and exclude the
+	// snapshot already keeps them out of pods, and so out of every total and
+	// percentage (see separatePending)
+	_, pendingList := splitPendingPods(pods)
+	pendingList = append(pendingList, opts.pending...)
+
 	// Define sheet names
 	sheet1Name, sheet2Name, sheet3Name, sheet4Name, sheet5Name, sheet6Name := "Resources", "Namespaces", "Nodes", "Chart", "Insights", "Pod Security"
-
-	index, err := f.NewSheet(sheet1Name)
+	heatmapSheetName, requestLimitSheetName, distributionSheetName := "Node Heatmap", "Request vs Limit", "Request Distribution"
+	tshirtSheetName, overviewSheetName, changesSheetName := "T-Shirt Sizes", "Overview", "Resource Changes"
+	baselineSheetName, pendingSheetName, jobAuditSheetName := "Baseline", "Pending Pods", "Job Audit"
+	forecastSheetName := "CronJob Forecast"
+	cleanupSheetName, scalingSheetName, deliverySheetName := "Cleanup", "HPA Scaling", "Progressive Delivery"
+	warningsSheetName, archSheetName, costSheetName := WarningsSheetName, "Architecture", "Cost"
+	topologySheetName, computeUnitSheetName := "Topology", "Compute Units"
+	extendedSheetName := "Extended Resources"
+	sidecarSheetName, platformSheetName, vendorSheetName := "Sidecar Overhead", "Platform Overhead", "Image Vendors"
+	containerSheetName, reliabilitySheetName, startupSheetName := "Container Groups", "Reliability", "Startup Spikes"
+	jvmSheetName, reservedSheetName, headroomSheetName := "JVM Memory", "Kubelet Reserved", "Autoscaler Headroom"
+	eventsSheetName := "Events"
+	statefulSetSheetName, securitySheetName, schemaSheetName := "StatefulSet Footprint", "Security Anomalies", "Schema"
+
+	f, err := export.New(sheet1Name)
 	if err != nil {
-		return fmt.Errorf("failed to create sheet: %w", err)
-	}
-	f.SetActiveSheet(index)
-
-	// Delete default Sheet1
-	if err := f.DeleteSheet("Sheet1"); err != nil {
-		return fmt.Errorf("failed to delete default sheet: %w", err)
+		return err
 	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			logrus.Warnf("Failed to close Excel file: %v", err)
+		}
+	}()
 
 	// Set headers - prioritize main resource columns from original design
-	headers := []string{
-		"Namespace", "Pod", "Container",
-		"Request CPU (m)", "Request CPU", "Request Memory (Mi)", "Request Memory",
-		"Limit CPU (m)", "Limit CPU", "Limit Memory (Mi)", "Limit Memory",
-		"Pod Age", "Restart Count", "Last Restart",
-		"Request Storage (Gi)", "Request Storage", "Limit Storage (Gi)", "Limit Storage",
-		"Request GPU", "Request GPU (str)", "Limit GPU", "Limit GPU (str)",
-		"Status", "QoS Class", "Node",
-		"CPU Efficiency %", "Memory Efficiency %", "CPU % of Cluster", "Memory % of Cluster",
+	headers := append([]string(nil), resourceHeaders...)
+	usageColumnStart := len(headers) + 1
+	if opts.metrics != nil {
+		headers = append(headers, usageHeaders...)
+	}
+	quantileColumnStart := len(headers) + 1
+	if opts.quantiles != nil {
+		headers = append(headers, opts.quantiles.headers()...)
+	}
+	if opts.rawQuantities {
+		headers = append(headers, rawQuantityHeaders...)
+	}
+	extendedColumnStart := len(headers) + 1
+	headers = append(headers, extendedResourceHeaders(opts.extendedResources)...)
+	teamColumnStart := len(headers) + 1
+	if opts.teams != nil {
+		headers = append(headers, teamHeaders...)
+	}
+	gitopsColumnStart := len(headers) + 1
+	if opts.gitops != nil {
+		headers = append(headers, gitopsHeaders...)
+	}
+	headers = append(headers, opts.enrichment.headers()...)
+	customColumnStart := len(headers) + 1
+	for _, column := range opts.customColumns {
+		headers = append(headers, column.name)
 	}
 
 	if err := f.SetSheetRow(sheet1Name, "A2", &headers); err != nil {
@@ -248,18 +1526,27 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 	}
 
 	// Set auto filter
-	if err := f.AutoFilter(sheet1Name, "A2:AC2", []excelize.AutoFilterOptions{}); err != nil {
+	lastHeaderCell, _ := excelize.CoordinatesToCellName(len(headers), 2)
+	if err := f.AutoFilter(sheet1Name, "A2:"+lastHeaderCell, []excelize.AutoFilterOptions{}); err != nil {
 		return fmt.Errorf("failed to set auto filter: %w", err)
 	}
 
+	// Namespace labels are needed for team resolution
+	namespaceLabels := namespaceLabelIndex(namespaces)
+
 	// Single-pass data processing with aggregation
 	logrus.Infof("Processing %d pods...", len(pods))
 	logMemoryUsage("start processing")
 
-	// Pre-calculate cluster totals for percentage calculations
+	// Pre-calculate cluster totals for percentage calculations; split group
+	// workbooks share their requests out of the whole cluster
+	sharePods := pods
+	if opts.clusterPods != nil {
+		sharePods = opts.clusterPods
+	}
 	var clusterTotalReqCPU, clusterTotalReqMem int64
-	for _, pod := range pods {
-		if pod.Status.Phase != corev1.PodRunning && pod.Status.Phase != corev1.PodPending {
+	for _, pod := range sharePods {
+		if !isActivePod(&pod) {
 			continue
 		}
 		for _, container := range pod.Spec.Containers {
@@ -273,21 +1560,20 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 	}
 
 	// Data structures for aggregation
-	namespaceTotals := make(map[string]struct {
-		reqCPU, limCPU int64
-		reqMem, limMem int64
-	})
-	nodeTotals := make(map[string]struct {
-		podCount         int
-		reqCPU, limCPU   int64
-		reqMem, limMem   int64
-		capCPU, capMem   int64 // Capacity (total)
-		allocCPU, allocMem int64 // Allocatable (capacity - system reservations)
-		nodeName, nodeIP string
-	})
+	_, aggregateSpan := tracer.Start(ctx, "aggregate")
+	namespaceTotals, nodeTotals := aggregateTotals(pods, nodes)
+	workloadTotals := make(map[workloadKey]workloadTotal)
+	activity := make(map[string]namespaceActivity)
+	var teamTotals map[string]namespaceTotal // Requests per team, nil without team mapping
+	if opts.teams != nil {
+		teamTotals = make(map[string]namespaceTotal)
+	}
+	var cpuRequests, memRequests []int64 // Per-container request sizes for histograms
+	tshirtCounts := make(map[string]map[string]int)
 
-	row := 3
 	processedContainers := 0
+	writeResources := opts.sheets.enabled(SheetResources)
+	var resourceRows []resourceRow
 	for i, pod := range pods {
 		if i%50 == 0 && i > 0 {
 			logrus.Infof("Processed %d/%d pods (%d containers)", i, len(pods), processedContainers)
@@ -297,19 +1583,13 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 		}
 
 		// Filter by pod status
-		if pod.Status.Phase != corev1.PodRunning && pod.Status.Phase != corev1.PodPending {
+		if !isActivePod(&pod) {
 			continue
 		}
 
-		// Track pod count per node
-		node := pod.Status.HostIP
-		if node == "" {
-			node = "Unknown"
-		}
-		nodeTotal := nodeTotals[node]
-		nodeTotal.podCount++
-		nodeTotal.nodeIP = node
-		nodeTotal.nodeName = pod.Spec.NodeName
+		workload := workloadOf(&pod)
+		workloadSum := workloadTotals[workload]
+		workloadSum.pods++
 
 		// Calculate pod age
 		podAge := time.Since(pod.CreationTimestamp.Time).Round(time.Second).String()
@@ -330,6 +1610,9 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 		if !lastRestart.IsZero() {
 			lastRestartStr = time.Since(lastRestart).Round(time.Second).String() + " ago"
 		}
+		nsActivity := activity[pod.Namespace]
+		nsActivity.observe(&pod, lastRestart)
+		activity[pod.Namespace] = nsActivity
 
 		for _, container := range pod.Spec.Containers {
 			reqCPU := container.Resources.Requests.Cpu()
@@ -348,7 +1631,7 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 			reqMemVal := float64(0)
 			reqMemStr := "-"
 			if reqMem != nil && !reqMem.IsZero() {
-				reqMemVal = float64(reqMem.Value()) / (1024 * 1024)
+				reqMemVal = bytesToMi(reqMem.Value())
 				reqMemStr = reqMem.String()
 			}
 
@@ -362,7 +1645,7 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 			limMemVal := float64(0)
 			limMemStr := "-"
 			if limMem != nil && !limMem.IsZero() {
-				limMemVal = float64(limMem.Value()) / (1024 * 1024)
+				limMemVal = bytesToMi(limMem.Value())
 				limMemStr = limMem.String()
 			}
 
@@ -373,14 +1656,14 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 			reqStorageVal := float64(0)
 			reqStorageStr := "-"
 			if reqStorage != nil && !reqStorage.IsZero() {
-				reqStorageVal = float64(reqStorage.Value()) / (1024 * 1024 * 1024) // Convert to Gi
+				reqStorageVal = bytesToGi(reqStorage.Value())
 				reqStorageStr = reqStorage.String()
 			}
 
 			limStorageVal := float64(0)
 			limStorageStr := "-"
 			if limStorage != nil && !limStorage.IsZero() {
-				limStorageVal = float64(limStorage.Value()) / (1024 * 1024 * 1024) // Convert to Gi
+				limStorageVal = bytesToGi(limStorage.Value())
 				limStorageStr = limStorage.String()
 			}
 
@@ -402,14 +1685,13 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 				limGPUStr = limGPU.String()
 			}
 
-			// Calculate efficiency percentages
-			cpuEfficiency := ""
-			memEfficiency := ""
+			// Calculate efficiency ratios (written as Excel percentages, nil leaves the cell empty)
+			var cpuEfficiency, memEfficiency interface{}
 			if limCPUVal > 0 && reqCPUVal > 0 {
-				cpuEfficiency = fmt.Sprintf("%.1f%%", float64(reqCPUVal)/float64(limCPUVal)*100)
+				cpuEfficiency = float64(reqCPUVal) / float64(limCPUVal)
 			}
 			if limMemVal > 0 && reqMemVal > 0 {
-				memEfficiency = fmt.Sprintf("%.1f%%", float64(reqMemVal)/float64(limMemVal)*100)
+				memEfficiency = reqMemVal / limMemVal
 			}
 
 			// Aggregate data for other sheets
@@ -417,35 +1699,39 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 			if ns == "" {
 				ns = "default"
 			}
-			nsTotals := namespaceTotals[ns]
-			nsTotals.reqCPU += reqCPUVal
-			nsTotals.limCPU += limCPUVal
+
+			// Update workload totals
+			workloadSum.reqCPU += reqCPUVal
+			workloadSum.limCPU += limCPUVal
+			workloadSum.missingCPULimit = workloadSum.missingCPULimit || limCPUVal == 0
 			if reqMem != nil {
-				nsTotals.reqMem += reqMem.Value()
+				workloadSum.reqMem += reqMem.Value()
 			}
 			if limMem != nil {
-				nsTotals.limMem += limMem.Value()
+				workloadSum.limMem += limMem.Value()
 			}
-			namespaceTotals[ns] = nsTotals
+			workloadSum.missingMemLimit = workloadSum.missingMemLimit || limMemVal == 0
 
-			// Update node totals (accumulated for all containers in this pod)
-			nodeTotal.reqCPU += reqCPUVal
-			nodeTotal.limCPU += limCPUVal
+			reqMemBytes := int64(0)
 			if reqMem != nil {
-				nodeTotal.reqMem += reqMem.Value()
+				reqMemBytes = reqMem.Value()
 			}
-			if limMem != nil {
-				nodeTotal.limMem += limMem.Value()
+			cpuRequests = append(cpuRequests, reqCPUVal)
+			memRequests = append(memRequests, reqMemBytes)
+
+			tshirt := classifyTShirt(opts.tshirtSizes, reqCPUVal, reqMemBytes)
+			if tshirtCounts[ns] == nil {
+				tshirtCounts[ns] = make(map[string]int)
 			}
+			tshirtCounts[ns][tshirt]++
 
 			// Calculate cluster percentages
-			cpuClusterPct := ""
-			memClusterPct := ""
+			var cpuClusterPct, memClusterPct interface{}
 			if clusterTotalReqCPU > 0 {
-				cpuClusterPct = fmt.Sprintf("%.2f%%", float64(reqCPUVal)/float64(clusterTotalReqCPU)*100)
+				cpuClusterPct = float64(reqCPUVal) / float64(clusterTotalReqCPU)
 			}
 			if clusterTotalReqMem > 0 && reqMem != nil {
-				memClusterPct = fmt.Sprintf("%.2f%%", float64(reqMem.Value())/float64(clusterTotalReqMem)*100)
+				memClusterPct = float64(reqMem.Value()) / float64(clusterTotalReqMem)
 			}
 
 			rowData := []interface{}{
@@ -470,117 +1756,673 @@ func generateExcel(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *c
 				memEfficiency,
 				cpuClusterPct,
 				memClusterPct,
+				tshirt,
+			}
+			if opts.metrics != nil {
+				rowData = append(rowData, opts.metrics.columns(pod.Namespace, pod.Name, container.Name, reqCPUVal, reqMemBytes)...)
+			}
+			if opts.quantiles != nil {
+				rowData = append(rowData, opts.quantiles.columns(pod.Namespace, pod.Name, container.Name)...)
+			}
+			if opts.rawQuantities {
+				rowData = append(rowData, rawQuantityColumns(reqCPU, reqMem, limCPU, limMem)...)
+			}
+			rowData = append(rowData, extendedResourceColumns(opts.extendedResources, container.Resources)...)
+			var team teamInfo
+			if opts.teams != nil {
+				team, _ = opts.teams.resolve(pod.Labels, namespaceLabels[pod.Namespace], pod.Namespace)
+				rowData = append(rowData, team.Name, team.Owner, team.Email)
+
+				teamSum := teamTotals[team.Name]
+				teamSum.reqCPU += reqCPUVal
+				teamSum.reqMem += quantityBytes(reqMem)
+				teamTotals[team.Name] = teamSum
+			}
+			if opts.gitops != nil {
+				owner, _ := opts.gitops.resolve(workload, pod.Labels, pod.Annotations)
+				rowData = append(rowData, owner.tool, owner.app, owner.repo)
+			}
+			rowData = append(rowData, opts.enrichment.values(pod.Namespace, pod.Name, container.Name)...)
+
+			if writeResources {
+				resourceRows = append(resourceRows, resourceRow{
+					data: rowData,
+					fields: rowFields{
+						Namespace:       pod.Namespace,
+						Pod:             pod.Name,
+						Container:       container.Name,
+						Node:            pod.Status.HostIP,
+						Status:          string(pod.Status.Phase),
+						QoSClass:        getQoSClass(container),
+						TShirtSize:      tshirt,
+						Team:            team.Name,
+						RequestCPU:      reqCPUVal,
+						LimitCPU:        limCPUVal,
+						RequestMemoryMi: reqMemVal,
+						LimitMemoryMi:   limMemVal,
+						RestartCount:    totalRestarts,
+						Labels:          pod.Labels,
+						Annotations:     pod.Annotations,
+					},
+					links: linkValues{
+						cluster:      opts.metadata.cluster.name,
+						namespace:    pod.Namespace,
+						pod:          pod.Name,
+						container:    container.Name,
+						node:         pod.Spec.NodeName,
+						nodeIP:       pod.Status.HostIP,
+						team:         team.Name,
+						workloadKind: workload.kind,
+						workloadName: workload.name,
+					},
+					context: fmt.Sprintf("pod '%s' container '%s'", pod.Name, container.Name),
+				})
 			}
 
-			// Write to Resources sheet with enhanced error context
-			context := fmt.Sprintf("pod '%s' container '%s'", pod.Name, container.Name)
-			if err := setRowWithContext(f, sheet1Name, row, rowData, context); err != nil {
-				return err
+			processedContainers++
+		}
+
+		workloadTotals[workload] = workloadSum
+	}
+
+	aggregateSpan.SetAttributes(attribute.Int("report.containers", processedContainers))
+	aggregateSpan.End()
+	logrus.Infof("Completed processing: %d pods, %d containers", len(pods), processedContainers)
+	logMemoryUsage("after processing")
+
+	// CronJob runs expected in the forecast horizon
+	var forecast *cronJobForecast
+	if opts.cronJobs != nil && opts.forecastHorizon > 0 {
+		forecast = newCronJobForecast(opts.cronJobs, pods, nodes, opts.metadata.generated, opts.forecastHorizon)
+	}
+
+	// Data validation and warnings
+	saturation := saturationByPool(nodes, nodeTotals)
+	findings := validateAndWarnResources(validationInput{
+		namespaceTotals:  namespaceTotals,
+		nodeTotals:       nodeTotals,
+		saturation:       saturation,
+		namespaceCreated: namespaceCreation(namespaces),
+		specDrift:        workloadSpecDrift(pods),
+		forecast:         forecast,
+		savings:          savingsOpportunity(pods, opts.quantiles),
+		now:              opts.metadata.generated,
+	}, processedContainers, opts.validation)
+
+	if writeResources {
+		sortRows(resourceRows, opts.sortKeys)
+		row, err := writeResourceRows(f, sheet1Name, resourceRows, customColumnStart, opts)
+		if err != nil {
+			return err
+		}
+
+		// Add summary formulas
+		if err := addSummaryFormulas(f, sheet1Name, row); err != nil {
+			return fmt.Errorf("failed to add summary formulas: %w", err)
+		}
+
+		// Position independent column names for parsers
+		if err := setSchemaNames(f, sheet1Name, schemaColumns(headers), row-1); err != nil {
+			return err
+		}
+
+		// Set column widths for better readability
+		if err := setColumnWidths(f, sheet1Name); err != nil {
+			return fmt.Errorf("failed to set column widths: %w", err)
+		}
+		if opts.metrics != nil {
+			first, _ := excelize.ColumnNumberToName(usageColumnStart)
+			last, _ := excelize.ColumnNumberToName(usageColumnStart + len(usageHeaders) - 1)
+			if err := f.SetColWidth(sheet1Name, first, last, 18); err != nil {
+				return fmt.Errorf("failed to set usage column widths: %w", err)
+			}
+		}
+		if opts.quantiles != nil {
+			first, _ := excelize.ColumnNumberToName(quantileColumnStart)
+			last, _ := excelize.ColumnNumberToName(quantileColumnStart + 2*len(opts.quantiles.quantiles) - 1)
+			if err := f.SetColWidth(sheet1Name, first, last, 16); err != nil {
+				return fmt.Errorf("failed to set quantile column widths: %w", err)
+			}
+		}
+		if opts.rawQuantities {
+			first, _ := excelize.ColumnNumberToName(extendedColumnStart - len(rawQuantityHeaders))
+			last, _ := excelize.ColumnNumberToName(extendedColumnStart - 1)
+			if err := f.SetColWidth(sheet1Name, first, last, 20); err != nil {
+				return fmt.Errorf("failed to set raw quantity column widths: %w", err)
+			}
+		}
+		if len(opts.extendedResources) > 0 {
+			first, _ := excelize.ColumnNumberToName(extendedColumnStart)
+			last, _ := excelize.ColumnNumberToName(teamColumnStart - 1)
+			if err := f.SetColWidth(sheet1Name, first, last, 20); err != nil {
+				return fmt.Errorf("failed to set extended resource column widths: %w", err)
+			}
+		}
+		if opts.teams != nil {
+			first, _ := excelize.ColumnNumberToName(teamColumnStart)
+			last, _ := excelize.ColumnNumberToName(teamColumnStart + 2)
+			if err := f.SetColWidth(sheet1Name, first, last, 22); err != nil {
+				return fmt.Errorf("failed to set team column widths: %w", err)
+			}
+		}
+		if opts.gitops != nil {
+			first, _ := excelize.ColumnNumberToName(gitopsColumnStart)
+			last, _ := excelize.ColumnNumberToName(gitopsColumnStart + 1)
+			repo, _ := excelize.ColumnNumberToName(gitopsColumnStart + 2)
+			if err := f.SetColWidth(sheet1Name, first, last, 22); err != nil {
+				return fmt.Errorf("failed to set GitOps column widths: %w", err)
+			}
+			if err := f.SetColWidth(sheet1Name, repo, repo, 50); err != nil {
+				return fmt.Errorf("failed to set GitOps column widths: %w", err)
 			}
+		}
+	}
+
+	// Create summary sheet with charts
+	if opts.sheets.enabled(SheetNamespaces) {
+		lifecycles := namespaceLifecycles(namespaces)
+		if err := createSummarySheetFromData(f, namespaceTotals, lifecycles, opts.metadata.generated, sheet2Name); err != nil {
+			return fmt.Errorf("failed to create summary sheet: %w", err)
+		}
+	}
+
+	// Create node utilization sheet
+	if opts.sheets.enabled(SheetNodes) {
+		if err := createNodeSheetFromData(f, nodeTotals, sheet3Name); err != nil {
+			return fmt.Errorf("failed to create node sheet: %w", err)
+		}
+	}
+
+	// Create kubelet reserved capacity per node
+	if nodes != nil && opts.sheets.enabled(SheetReserved) {
+		reservations := nodeReservations(nodes)
+		if opts.evictions != nil {
+			applyKubeletEvictions(reservations, opts.evictions, pods)
+		}
+		if err := createReservedSheet(f, reservations, reservedSheetName); err != nil {
+			return fmt.Errorf("failed to create kubelet reserved sheet: %w", err)
+		}
+	}
+
+	// Create node allocation heatmap
+	if opts.sheets.enabled(SheetHeatmap) {
+		if err := createNodeHeatmapSheet(f, nodeTotals, heatmapSheetName, opts.theme); err != nil {
+			return fmt.Errorf("failed to create node heatmap sheet: %w", err)
+		}
+	}
+
+	// Create CPU architecture segmentation
+	if opts.sheets.enabled(SheetArch) {
+		archs, archNamespaces, pinned := archSegments(pods, nodes)
+		if err := createArchSheet(f, archs, archNamespaces, pinned, archSheetName); err != nil {
+			return fmt.Errorf("failed to create architecture sheet: %w", err)
+		}
+	}
+
+	// Create failure domain capacity and workload spread per topology key
+	if nodes != nil && opts.sheets.enabled(SheetTopology) {
+		views := make([]topologyView, 0, len(opts.topologyKeys))
+		for _, key := range opts.topologyKeys {
+			views = append(views, newTopologyView(pods, nodes, key))
+		}
+		if err := createTopologySheet(f, views, topologySheetName, opts.theme); err != nil {
+			return fmt.Errorf("failed to create topology sheet: %w", err)
+		}
+	}
+
+	// Create dedicated chart sheet
+	if opts.sheets.enabled(SheetChart) {
+		if err := createChartSheetFromData(f, namespaceTotals, sheet4Name, sheet2Name); err != nil {
+			return fmt.Errorf("failed to create chart sheet: %w", err)
+		}
+	}
+
+	// Create per-workload request vs limit scatter charts
+	if opts.sheets.enabled(SheetRequestLimit) {
+		if err := createRequestLimitSheet(f, workloadTotals, requestLimitSheetName); err != nil {
+			return fmt.Errorf("failed to create request vs limit sheet: %w", err)
+		}
+	}
+
+	// Create resource change history from Deployment rollouts
+	if opts.resourceChanges != nil && opts.sheets.enabled(SheetChanges) {
+		if err := createResourceChangesSheet(f, opts.resourceChanges, workloadTotals, opts.metadata.generated.Location(), changesSheetName); err != nil {
+			return fmt.Errorf("failed to create resource changes sheet: %w", err)
+		}
+	}
+
+	// Create request changes against the stored baseline
+	if opts.baseline != nil && opts.sheets.enabled(SheetBaseline) {
+		if err := createBaselineSheet(f, opts.baseline, newBaseline(pods, opts.metadata.generated), opts.baselineThreshold, baselineSheetName, opts.theme); err != nil {
+			return fmt.Errorf("failed to create baseline sheet: %w", err)
+		}
+	}
+
+	// Create HPA replica bounds and anomalies
+	if opts.hpaScaling != nil && opts.sheets.enabled(SheetScaling) {
+		if err := createScalingSheet(f, opts.hpaScaling, workloadTotals, scalingSheetName, opts.theme); err != nil {
+			return fmt.Errorf("failed to create HPA scaling sheet: %w", err)
+		}
+	}
+
+	// Create canary / blue-green pairs with their temporary footprint
+	if opts.sheets.enabled(SheetDelivery) {
+		if err := createDeliverySheet(f, deliveryGroups(pods), deliverySheetName); err != nil {
+			return fmt.Errorf("failed to create progressive delivery sheet: %w", err)
+		}
+	}
+
+	// Create mesh sidecar overhead per namespace
+	if opts.sheets.enabled(SheetSidecars) {
+		if err := createSidecarSheet(f, sidecarOverheads(pods), sidecarSheetName); err != nil {
+			return fmt.Errorf("failed to create sidecar overhead sheet: %w", err)
+		}
+	}
+
+	// Create monthly cost weighted by node pool and zone
+	if opts.pricing != nil && opts.sheets.enabled(SheetCost) {
+		namespaceCosts, locationCosts := podCosts(pods, nodes, opts.pricing)
+		if err := createCostSheet(f, namespaceCosts, locationCosts, opts.pricing, costSheetName); err != nil {
+			return fmt.Errorf("failed to create cost sheet: %w", err)
+		}
+	}
 
-			// Format memory columns to integer (no decimal places)
-			fCell, _ := excelize.CoordinatesToCellName(6, row)  // Column F (Request Memory Mi)
-			jCell, _ := excelize.CoordinatesToCellName(10, row) // Column J (Limit Memory Mi)
-			f.SetCellStyle(sheet1Name, fCell, fCell, getIntegerStyle(f))
-			f.SetCellStyle(sheet1Name, jCell, jCell, getIntegerStyle(f))
-
-			// Apply conditional formatting for efficiency
-			zCell, _ := excelize.CoordinatesToCellName(26, row)  // CPU Efficiency
-			aaCell, _ := excelize.CoordinatesToCellName(27, row) // Memory Efficiency
-			if cpuEfficiency != "" {
-				f.SetCellStyle(sheet1Name, zCell, zCell, getEfficiencyStyle(f, cpuEfficiency))
+	// Create compute unit consumption for chargeback
+	if opts.computeUnit != nil && opts.sheets.enabled(SheetComputeUnits) {
+		namespaceUnits, workloadUnits := computeUnitUsages(pods, opts.computeUnit)
+		if err := createComputeUnitSheet(f, namespaceUnits, workloadUnits, opts.computeUnit, computeUnitSheetName); err != nil {
+			return fmt.Errorf("failed to create compute units sheet: %w", err)
+		}
+	}
+
+	// Create StatefulSet compute and storage footprint
+	if opts.statefulSets != nil && opts.sheets.enabled(SheetStatefulSets) {
+		if err := createStatefulSetSheet(f, opts.statefulSets, opts.pricing, statefulSetSheetName); err != nil {
+			return fmt.Errorf("failed to create statefulset sheet: %w", err)
+		}
+	}
+
+	// Create device plugin resources with capacity and cost
+	if opts.sheets.enabled(SheetExtended) {
+		resources := discoverExtendedResources(opts.extendedResources, pods, nodes)
+		if len(resources) > 0 {
+			currency := DefaultCurrency
+			if opts.pricing != nil {
+				currency = opts.pricing.Currency
 			}
-			if memEfficiency != "" {
-				f.SetCellStyle(sheet1Name, aaCell, aaCell, getEfficiencyStyle(f, memEfficiency))
+			usages := extendedUsages(resources, pods, nodes)
+			shares := gpuShares(pods, nodes)
+			if err := createExtendedResourceSheet(f, resources, usages, shares, currency, extendedSheetName); err != nil {
+				return fmt.Errorf("failed to create extended resources sheet: %w", err)
 			}
+		}
+	}
 
-			row++
-			processedContainers++
+	// Create observability agent overhead vs application requests
+	if opts.sheets.enabled(SheetPlatform) {
+		agents, reqCPU, reqMem := agentOverheads(pods, opts.agents)
+		requests := namespaceTotal{reqCPU: reqCPU, reqMem: reqMem}
+		cluster := requests
+		if opts.clusterPods != nil {
+			_, cluster.reqCPU, cluster.reqMem = agentOverheads(opts.clusterPods, nil)
+		}
+		if err := createPlatformSheet(f, agents, requests, cluster, platformSheetName); err != nil {
+			return fmt.Errorf("failed to create platform overhead sheet: %w", err)
+		}
+	}
+
+	// Create overprovisioning pause pod sizing per node pool
+	if nodes != nil && opts.sheets.enabled(SheetHeadroom) {
+		headroom := headroomByPool(pods, nodes, opts.headroomPercent)
+		if err := createHeadroomSheet(f, headroom, opts.headroomPercent, headroomSheetName); err != nil {
+			return fmt.Errorf("failed to create headroom sheet: %w", err)
+		}
+	}
+
+	// Create requests per image registry and organization
+	if opts.sheets.enabled(SheetVendors) {
+		vendors, clusterCPU, clusterMem := vendorTotals(pods)
+		if opts.clusterPods != nil {
+			_, clusterCPU, clusterMem = vendorTotals(opts.clusterPods)
+		}
+		if err := createVendorSheet(f, vendors, clusterCPU, clusterMem, vendorSheetName); err != nil {
+			return fmt.Errorf("failed to create image vendors sheet: %w", err)
+		}
+	}
+
+	// Create requests per container name across all pods
+	if opts.sheets.enabled(SheetContainers) {
+		if err := createContainerGroupSheet(f, containerGroups(pods), containerSheetName); err != nil {
+			return fmt.Errorf("failed to create container groups sheet: %w", err)
+		}
+	}
+
+	// Create request size histograms
+	if opts.sheets.enabled(SheetDistribution) {
+		if err := createRequestDistributionSheet(f, cpuRequests, memRequests, distributionSheetName); err != nil {
+			return fmt.Errorf("failed to create request distribution sheet: %w", err)
+		}
+	}
+
+	// Create T-shirt size distribution per namespace
+	if opts.sheets.enabled(SheetTShirt) {
+		if err := createTShirtSheet(f, tshirtCounts, tshirtSizeNames(opts.tshirtSizes), tshirtSheetName); err != nil {
+			return fmt.Errorf("failed to create T-shirt size sheet: %w", err)
 		}
+	}
 
-		// Update node totals once after processing all containers in the pod
-		nodeTotals[node] = nodeTotal
+	// Create data science insights sheet
+	if opts.sheets.enabled(SheetInsights) {
+		var idle []idleNamespace
+		if opts.idleAfter > 0 {
+			idle = idleNamespaces(activity, namespaceTotals, opts.metadata.generated, opts.idleAfter)
+		}
+		if err := createInsightsSheet(f, namespaceTotals, teamTotals, nodeTotals, idle, opts, sheet5Name); err != nil {
+			return fmt.Errorf("failed to create insights sheet: %w", err)
+		}
 	}
 
-	logrus.Infof("Completed processing: %d pods, %d containers", len(pods), processedContainers)
-	logMemoryUsage("after processing")
+	// Create cleanup candidates (unowned, finished Job and old failed pods)
+	if opts.sheets.enabled(SheetCleanup) {
+		candidates := cleanupCandidates(pods, opts.finishedJobs, opts.metadata.generated, opts.failedPodAge)
+		if err := createCleanupSheet(f, candidates, cleanupSheetName); err != nil {
+			return fmt.Errorf("failed to create cleanup sheet: %w", err)
+		}
+	}
 
-	// Data validation and warnings
-	validateAndWarnResources(namespaceTotals, nodeTotals, processedContainers)
+	// Create the Pending pod summary
+	if len(pendingList) > 0 && opts.pendingPods != PendingExclude && opts.sheets.enabled(SheetPending) {
+		if err := createPendingSheet(f, pendingPodList(pendingList, opts.metadata.generated), opts.pendingPods, pendingSheetName, opts.theme); err != nil {
+			return fmt.Errorf("failed to create pending sheet: %w", err)
+		}
+	}
 
-	// Add summary formulas
-	if err := addSummaryFormulas(f, sheet1Name, row); err != nil {
-		return fmt.Errorf("failed to add summary formulas: %w", err)
+	// Create Job runs and request-hours per CronJob
+	if len(opts.jobRuns) > 0 && opts.sheets.enabled(SheetJobAudit) {
+		if err := createJobAuditSheet(f, opts.jobRuns, opts.metadata.generated, jobAuditSheetName); err != nil {
+			return fmt.Errorf("failed to create job audit sheet: %w", err)
+		}
 	}
 
-	// Set column widths for better readability
-	if err := setColumnWidths(f, sheet1Name); err != nil {
-		return fmt.Errorf("failed to set column widths: %w", err)
+	// Create the CronJob peak windows of the forecast
+	if forecast != nil && opts.sheets.enabled(SheetForecast) {
+		if err := createForecastSheet(f, forecast, opts.validation.rules[RuleCronJobBurst].threshold, forecastSheetName, opts.theme); err != nil {
+			return fmt.Errorf("failed to create forecast sheet: %w", err)
+		}
 	}
 
-	// Create summary sheet with charts
-	if err := createSummarySheetFromData(f, namespaceTotals, sheet2Name); err != nil {
-		return fmt.Errorf("failed to create summary sheet: %w", err)
+	// Create probe settings at risk under CPU throttling
+	if opts.sheets.enabled(SheetReliability) && hasProbes(pods) {
+		if err := createReliabilitySheet(f, probeRisks(pods), reliabilitySheetName); err != nil {
+			return fmt.Errorf("failed to create reliability sheet: %w", err)
+		}
 	}
 
-	// Populate node capacity from nodes list
-	if nodes != nil {
-		for _, node := range nodes.Items {
-			// Match by node name or IP
-			for nodeKey, totals := range nodeTotals {
-				if totals.nodeName == node.Name || nodeKey == getNodeIP(&node) {
-					// Store both Capacity and Allocatable
-					totals.capCPU = node.Status.Capacity.Cpu().MilliValue()
-					totals.capMem = node.Status.Capacity.Memory().Value()
-					totals.allocCPU = node.Status.Allocatable.Cpu().MilliValue()
-					totals.allocMem = node.Status.Allocatable.Memory().Value()
-					nodeTotals[nodeKey] = totals
-					break
-				}
-			}
+	// Create startup usage spikes from the usage history
+	if opts.usage != nil && opts.sheets.enabled(SheetStartup) {
+		if err := createStartupSheet(f, startupSpikes(pods, opts.usage), opts.usage.startupWindow(), startupSheetName); err != nil {
+			return fmt.Errorf("failed to create startup spikes sheet: %w", err)
 		}
 	}
 
-	// Create node utilization sheet
-	if err := createNodeSheetFromData(f, nodeTotals, sheet3Name); err != nil {
-		return fmt.Errorf("failed to create node sheet: %w", err)
+	// Create heap vs memory limit guidance for JVM containers
+	if opts.sheets.enabled(SheetJVM) {
+		if err := createJVMSheet(f, jvmContainers(pods), jvmSheetName); err != nil {
+			return fmt.Errorf("failed to create JVM memory sheet: %w", err)
+		}
 	}
 
-	// Create dedicated chart sheet
-	if err := createChartSheetFromData(f, namespaceTotals, sheet4Name, sheet2Name); err != nil {
-		return fmt.Errorf("failed to create chart sheet: %w", err)
+	// Create the Warning events behind the numbers
+	if opts.events != nil && opts.sheets.enabled(SheetEvents) {
+		if err := createEventsSheet(f, opts.events, pods, namespaceTotals, opts.metadata.generated.Location(), eventsSheetName); err != nil {
+			return fmt.Errorf("failed to create events sheet: %w", err)
+		}
 	}
 
-	// Create data science insights sheet
-	if err := createInsightsSheet(f, namespaceTotals, nodeTotals, sheet5Name); err != nil {
-		return fmt.Errorf("failed to create insights sheet: %w", err)
+	// Create validation findings
+	if opts.sheets.enabled(SheetWarnings) {
+		if err := createWarningsSheet(f, findings, warningsSheetName); err != nil {
+			return fmt.Errorf("failed to create warnings sheet: %w", err)
+		}
 	}
 
 	// Create Pod Security Standards sheet
-	if namespaces != nil {
+	if namespaces != nil && opts.sheets.enabled(SheetPodSecurity) {
 		if err := createPodSecuritySheet(f, namespaces, sheet6Name); err != nil {
 			return fmt.Errorf("failed to create pod security sheet: %w", err)
 		}
 	}
 
-	// Freeze panes
-	if err := setPanes(f, sheet1Name); err != nil {
-		return fmt.Errorf("failed to set panes: %w", err)
+	// Create resource anomalies for security reviewers
+	if opts.sheets.enabled(SheetSecurity) {
+		if err := createSecurityAnomaliesSheet(f, securityAnomalies(pods, opts.security), opts.security, securitySheetName); err != nil {
+			return fmt.Errorf("failed to create security anomalies sheet: %w", err)
+		}
+	}
+
+	// Create the sheets of the enrichment hooks
+	if opts.enrichment != nil {
+		if err := createHookSheets(f, opts.enrichment.sheets); err != nil {
+			return err
+		}
+	}
+
+	// Create schema version, changelog and column IDs
+	if opts.sheets.enabled(SheetSchema) {
+		if err := createSchemaSheet(f, schemaColumns(headers), schemaSheetName); err != nil {
+			return fmt.Errorf("failed to create schema sheet: %w", err)
+		}
+	}
+
+	// Create overview sheet with report metadata as first sheet
+	if opts.sheets.enabled(SheetOverview) {
+		stats := reportStats{containers: processedContainers, namespaces: len(namespaceTotals), nodes: len(nodeTotals), pending: len(pendingList), pendingMode: opts.pendingPods}
+		for _, totals := range nodeTotals {
+			stats.pods += totals.podCount
+		}
+		if err := createOverviewSheet(f, opts.metadata, stats, saturation, overviewSheetName, opts.theme); err != nil {
+			return fmt.Errorf("failed to create overview sheet: %w", err)
+		}
+		if err := f.MoveSheet(overviewSheetName, f.GetSheetList()[0]); err != nil {
+			return fmt.Errorf("failed to move overview sheet: %w", err)
+		}
+	}
+
+	if writeResources {
+		// Freeze panes
+		if err := setPanes(f, sheet1Name); err != nil {
+			return fmt.Errorf("failed to set panes: %w", err)
+		}
+	} else if len(f.GetSheetList()) > 1 {
+		// The Resources sheet is always created first so the default sheet can be removed
+		if err := f.DeleteSheet(sheet1Name); err != nil {
+			return fmt.Errorf("failed to delete disabled resources sheet: %w", err)
+		}
+	}
+
+	// Named cluster totals and document properties for downstream automation
+	namespaceSheet := ""
+	if opts.sheets.enabled(SheetNamespaces) {
+		namespaceSheet = sheet2Name
+	}
+	if err := setWorkbookNames(f, opts.metadata, namespaceTotals, namespaceSheet); err != nil {
+		return err
+	}
+	if err := setSchemaVersion(f); err != nil {
+		return err
+	}
+
+	// Repeat the Resources totals and header rows; the chart sheet has no header row
+	if err := setPrintLayout(f, opts.metadata, map[string]int{sheet1Name: 2, sheet4Name: 0}); err != nil {
+		return fmt.Errorf("failed to set print layout: %w", err)
 	}
 
-	// Set Resources sheet as active for better UX
+	// Set Resources sheet (or the first remaining sheet) as active for better UX
 	if idx, err := f.GetSheetIndex(sheet1Name); err == nil && idx >= 0 {
 		f.SetActiveSheet(idx)
+	} else {
+		f.SetActiveSheet(0)
 	}
 
 	// Save file
-	if err := f.SaveAs(filename); err != nil {
+	_, saveSpan := tracer.Start(ctx, "save")
+	err = f.SaveAs(filename)
+	endSpan(saveSpan, err)
+	if err != nil {
 		return fmt.Errorf("failed to save file: %w", err)
 	}
 
+	if opts.findingsPath != "" {
+		if err := writeFindings(opts.findingsPath, opts.findingsFormat, findings, opts.metadata); err != nil {
+			return err
+		}
+		logrus.Infof("Findings written: %s", outputName(opts.findingsPath))
+	}
+
+	return opts.validation.failingFindings(findings)
+}
+
+// writeResourceRows writes the container rows to the Resources sheet starting at row 3,
+// optionally grouped under collapsible namespace and pod subtotal rows. It returns the first free row.
+func writeResourceRows(f *excelize.File, sheetName string, rows []resourceRow, customColumnStart int, opts reportOptions) (int, error) {
+	if len(opts.links) > 0 && len(rows)*len(opts.links) > excelize.TotalSheetHyperlinks {
+		logrus.Warnf("Resources sheet needs %d links, Excel allows %d; later rows stay without links", len(rows)*len(opts.links), excelize.TotalSheetHyperlinks)
+	}
+	if opts.groupByPod || opts.namespaceSubtotals {
+		// Subtotal rows sit above the rows they summarize
+		summaryBelow := false
+		if err := f.SetSheetProps(sheetName, &excelize.SheetPropsOptions{OutlineSummaryBelow: &summaryBelow}); err != nil {
+			return 0, fmt.Errorf("failed to set outline properties: %w", err)
+		}
+	}
+
+	namespaceGroups := []rowGroup{{rows: rows}}
+	if opts.namespaceSubtotals {
+		namespaceGroups = groupByNamespace(rows)
+	}
+
+	row := 3
+	for _, nsGroup := range namespaceGroups {
+		podGroups := []rowGroup{{rows: nsGroup.rows}}
+		if opts.groupByPod {
+			podGroups = groupByPod(nsGroup.rows)
+		}
+
+		level := 0
+		if opts.namespaceSubtotals {
+			span := len(nsGroup.rows)
+			if opts.groupByPod {
+				span += len(podGroups)
+			}
+			if err := writeNamespaceSubtotal(f, sheetName, row, row+1, row+span, nsGroup, opts.theme); err != nil {
+				return 0, err
+			}
+			row++
+			level++
+		}
+
+		for _, podGroup := range podGroups {
+			containerLevel := level
+			if opts.groupByPod {
+				if err := writePodSubtotal(f, sheetName, row, row+1, row+len(podGroup.rows), podGroup); err != nil {
+					return 0, err
+				}
+				if err := setOutlineLevel(f, sheetName, row, level); err != nil {
+					return 0, err
+				}
+				row++
+				containerLevel++
+			}
+
+			for _, r := range podGroup.rows {
+				if err := writeResourceRow(f, sheetName, row, r, customColumnStart, opts); err != nil {
+					return 0, err
+				}
+				if err := setOutlineLevel(f, sheetName, row, containerLevel); err != nil {
+					return 0, fmt.Errorf("%s: %w", r.context, err)
+				}
+				row++
+			}
+		}
+	}
+	return row, nil
+}
+
+// setOutlineLevel groups a row at the given outline level; level 0 rows stay ungrouped
+func setOutlineLevel(f *excelize.File, sheetName string, row, level int) error {
+	if level == 0 {
+		return nil
+	}
+	if err := f.SetRowOutlineLevel(sheetName, row, uint8(level)); err != nil {
+		return fmt.Errorf("failed to set outline level for row %d: %w", row, err)
+	}
+	return nil
+}
+
+// writeResourceRow writes a single container row including custom columns and styles
+func writeResourceRow(f *excelize.File, sheetName string, row int, r resourceRow, customColumnStart int, opts reportOptions) error {
+	// Write to Resources sheet with enhanced error context
+	if err := export.SetRow(f, sheetName, row, r.data, r.context); err != nil {
+		return err
+	}
+
+	if len(opts.customColumns) > 0 {
+		if err := setCustomColumns(f, sheetName, row, customColumnStart, opts.customColumns, r.fields); err != nil {
+			return fmt.Errorf("%s: %w", r.context, err)
+		}
+	}
+	if len(opts.links) > 0 {
+		if err := setRowLinks(f, sheetName, row, opts.links, r.links); err != nil {
+			return fmt.Errorf("%s: %w", r.context, err)
+		}
+	}
+
+	// Format memory columns to integer (no decimal places)
+	fCell, _ := excelize.CoordinatesToCellName(6, row)  // Column F (Request Memory Mi)
+	jCell, _ := excelize.CoordinatesToCellName(10, row) // Column J (Limit Memory Mi)
+	f.SetCellStyle(sheetName, fCell, fCell, export.IntegerStyle(f))
+	f.SetCellStyle(sheetName, jCell, jCell, export.IntegerStyle(f))
+
+	// Apply conditional formatting for efficiency
+	zCell, _ := excelize.CoordinatesToCellName(26, row)  // CPU Efficiency
+	aaCell, _ := excelize.CoordinatesToCellName(27, row) // Memory Efficiency
+	if eff, ok := r.data[25].(float64); ok {
+		f.SetCellStyle(sheetName, zCell, zCell, getEfficiencyStyle(f, eff, opts.theme))
+	}
+	if eff, ok := r.data[26].(float64); ok {
+		f.SetCellStyle(sheetName, aaCell, aaCell, getEfficiencyStyle(f, eff, opts.theme))
+	}
+
+	// Cluster share columns
+	abCell, _ := excelize.CoordinatesToCellName(28, row) // CPU % of Cluster
+	acCell, _ := excelize.CoordinatesToCellName(29, row) // Memory % of Cluster
+	f.SetCellStyle(sheetName, abCell, acCell, export.PercentStyle(f, "0.00%"))
+
+	// Usage % of request columns follow T-Shirt Size with -with-usage
+	if opts.metrics != nil {
+		first, _ := excelize.CoordinatesToCellName(33, row) // CPU Usage % of Request
+		last, _ := excelize.CoordinatesToCellName(34, row)  // Memory Usage % of Request
+		f.SetCellStyle(sheetName, first, last, export.PercentStyle(f, "0.0%"))
+	}
 	return nil
 }
 
+// isActivePod reports whether a pod is Running or Pending and therefore holds resources
+func isActivePod(pod *corev1.Pod) bool {
+	return report.IsActive(pod)
+}
+
+// namespaceLabelIndex maps namespace names to their labels
+func namespaceLabelIndex(namespaces *corev1.NamespaceList) map[string]map[string]string {
+	index := make(map[string]map[string]string)
+	if namespaces != nil {
+		for _, ns := range namespaces.Items {
+			index[ns.Name] = ns.Labels
+		}
+	}
+	return index
+}
+
 func addSummaryFormulas(f *excelize.File, sheetName string, lastRow int) error {
 	formulas := map[string]string{
 		"D1": fmt.Sprintf("ROUND(SUBTOTAL(109,D3:D%d)/1000,2)", lastRow-1), // CPU requests in cores
@@ -644,6 +2486,7 @@ func setColumnWidths(f *excelize.File, sheetName string) error {
 		"AA": 18, // Memory Efficiency %
 		"AB": 16, // CPU % of Cluster
 		"AC": 18, // Memory % of Cluster
+		"AD": 13, // T-Shirt Size
 	}
 
 	for col, width := range columnWidths {
@@ -655,54 +2498,30 @@ func setColumnWidths(f *excelize.File, sheetName string) error {
 	return nil
 }
 
-func getIntegerStyle(f *excelize.File) int {
-	style, _ := f.NewStyle(&excelize.Style{
-		NumFmt: 1, // 0 format (no decimal places)
-	})
-	return style
-}
-
-func getEfficiencyStyle(f *excelize.File, efficiency string) int {
-	// Extract percentage value
-	pctStr := strings.TrimSuffix(efficiency, "%")
-	var pct float64
-	if _, err := fmt.Sscanf(pctStr, "%f", &pct); err != nil {
-		logrus.Warnf("Failed to parse efficiency percentage '%s': %v", pctStr, err)
-		pct = 0
-	}
-
-	// Color based on efficiency
-	var fillColor string
-	if pct >= 80 {
-		fillColor = "FF6B6B" // Red - high usage
-	} else if pct >= 60 {
-		fillColor = "FFE66D" // Yellow - medium usage
-	} else if pct >= 40 {
-		fillColor = "4ECDC4" // Teal - low usage
-	} else {
-		fillColor = "95E1D3" // Light green - very low usage
-	}
+// getEfficiencyStyle colors an efficiency ratio (request/limit) and formats it as percentage
+func getEfficiencyStyle(f *excelize.File, efficiency float64, t theme) int {
+	colors := t.efficiencyColors(efficiency)
 
+	format := "0.0%"
 	style, _ := f.NewStyle(&excelize.Style{
+		CustomNumFmt: &format,
+		Font:         &excelize.Font{Color: colors.font},
 		Fill: excelize.Fill{
 			Type:    "pattern",
-			Color:   []string{fillColor},
+			Color:   []string{colors.fill},
 			Pattern: 1,
 		},
 	})
 	return style
 }
-func createSummarySheetFromData(f *excelize.File, namespaceTotals map[string]struct {
-	reqCPU, limCPU int64
-	reqMem, limMem int64
-}, sheetName string) error {
+func createSummarySheetFromData(f *excelize.File, namespaceTotals map[string]namespaceTotal, lifecycles map[string]namespaceLifecycle, now time.Time, sheetName string) error {
 	_, err := f.NewSheet(sheetName)
 	if err != nil {
 		return fmt.Errorf("failed to create summary sheet: %w", err)
 	}
 
 	// Set headers
-	headers := []string{"Namespace", "Request CPU (cores)", "Limit CPU (cores)", "Request Memory (Mi)", "Limit Memory (Mi)"}
+	headers := []string{"Namespace", "Request CPU (cores)", "Limit CPU (cores)", "Request Memory (Mi)", "Limit Memory (Mi)", "Phase", "Age (days)", "Lifecycle", "Created By"}
 	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
 		return fmt.Errorf("failed to set headers: %w", err)
 	}
@@ -727,21 +2546,29 @@ func createSummarySheetFromData(f *excelize.File, namespaceTotals map[string]str
 
 		data := []interface{}{
 			ns,
-			float64(totals.reqCPU) / 1000,
-			float64(totals.limCPU) / 1000,
-			float64(totals.reqMem) / (1024 * 1024),
-			float64(totals.limMem) / (1024 * 1024),
+			milliToCores(totals.reqCPU),
+			milliToCores(totals.limCPU),
+			bytesToMi(totals.reqMem),
+			bytesToMi(totals.limMem),
+		}
+		// Phase, age, lifecycle note and source stay empty when namespaces could not be listed
+		if lifecycle, ok := lifecycles[ns]; ok {
+			data = append(data, string(lifecycle.phase), lifecycle.ageDays(now), lifecycle.note(totals, now), valueOrDash(lifecycle.createdBy))
 		}
 
-		if err := setRowWithContext(f, sheetName, row, data, fmt.Sprintf("namespace '%s'", ns)); err != nil {
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("namespace '%s'", ns)); err != nil {
 			return err
 		}
 
+		// Format age as decimal days
+		gCell, _ := excelize.CoordinatesToCellName(7, row)
+		f.SetCellStyle(sheetName, gCell, gCell, export.DecimalStyle(f, false))
+
 		// Format memory columns to integer
 		dCell, _ := excelize.CoordinatesToCellName(4, row)
 		eCell, _ := excelize.CoordinatesToCellName(5, row)
-		f.SetCellStyle(sheetName, dCell, dCell, getIntegerStyle(f))
-		f.SetCellStyle(sheetName, eCell, eCell, getIntegerStyle(f))
+		f.SetCellStyle(sheetName, dCell, dCell, export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, eCell, eCell, export.IntegerStyle(f))
 
 		row++
 	}
@@ -749,18 +2576,18 @@ func createSummarySheetFromData(f *excelize.File, namespaceTotals map[string]str
 	// Add cluster totals row
 	totalData := []interface{}{
 		"CLUSTER TOTAL",
-		float64(totalReqCPU) / 1000,
-		float64(totalLimCPU) / 1000,
-		float64(totalReqMem) / (1024 * 1024),
-		float64(totalLimMem) / (1024 * 1024),
+		milliToCores(totalReqCPU),
+		milliToCores(totalLimCPU),
+		bytesToMi(totalReqMem),
+		bytesToMi(totalLimMem),
 	}
 
-	if err := setRowWithContext(f, sheetName, row, totalData, "cluster totals"); err != nil {
+	if err := export.SetRow(f, sheetName, row, totalData, "cluster totals"); err != nil {
 		return err
 	}
 
 	// Format totals row with bold style
-	totalStyle := getBoldStyle(f)
+	totalStyle := export.BoldStyle(f)
 	for col := 1; col <= 5; col++ {
 		cell, _ := excelize.CoordinatesToCellName(col, row)
 		f.SetCellStyle(sheetName, cell, cell, totalStyle)
@@ -769,12 +2596,12 @@ func createSummarySheetFromData(f *excelize.File, namespaceTotals map[string]str
 	// Format memory columns in totals to integer
 	dCell, _ := excelize.CoordinatesToCellName(4, row)
 	eCell, _ := excelize.CoordinatesToCellName(5, row)
-	f.SetCellStyle(sheetName, dCell, dCell, getBoldIntegerStyle(f))
-	f.SetCellStyle(sheetName, eCell, eCell, getBoldIntegerStyle(f))
+	f.SetCellStyle(sheetName, dCell, dCell, export.BoldIntegerStyle(f))
+	f.SetCellStyle(sheetName, eCell, eCell, export.BoldIntegerStyle(f))
 
 	// Set column widths
 	summaryColumnWidths := map[string]float64{
-		"A": 20, "B": 18, "C": 16, "D": 20, "E": 18,
+		"A": 20, "B": 18, "C": 16, "D": 20, "E": 18, "F": 14, "G": 12, "H": 38, "I": 40,
 	}
 
 	for col, width := range summaryColumnWidths {
@@ -786,14 +2613,7 @@ func createSummarySheetFromData(f *excelize.File, namespaceTotals map[string]str
 	return nil
 }
 
-func createNodeSheetFromData(f *excelize.File, nodeTotals map[string]struct {
-	podCount           int
-	reqCPU, limCPU     int64
-	reqMem, limMem     int64
-	capCPU, capMem     int64
-	allocCPU, allocMem int64
-	nodeName, nodeIP   string
-}, sheetName string) error {
+func createNodeSheetFromData(f *excelize.File, nodeTotals map[string]nodeTotal, sheetName string) error {
 	_, err := f.NewSheet(sheetName)
 	if err != nil {
 		return fmt.Errorf("failed to create node sheet: %w", err)
@@ -818,27 +2638,26 @@ func createNodeSheetFromData(f *excelize.File, nodeTotals map[string]struct {
 		totals := nodeTotals[node]
 
 		// Calculate utilization percentages based on Allocatable
-		cpuUtil := "-"
-		memUtil := "-"
+		var cpuUtil, memUtil interface{}
 		if totals.allocCPU > 0 {
-			cpuUtil = fmt.Sprintf("%.1f%%", float64(totals.reqCPU)/float64(totals.allocCPU)*100)
+			cpuUtil = float64(totals.reqCPU) / float64(totals.allocCPU)
 		}
 		if totals.allocMem > 0 {
-			memUtil = fmt.Sprintf("%.1f%%", float64(totals.reqMem)/float64(totals.allocMem)*100)
+			memUtil = float64(totals.reqMem) / float64(totals.allocMem)
 		}
 
 		data := []interface{}{
 			node,
 			totals.podCount,
-			float64(totals.capCPU) / 1000,
-			float64(totals.allocCPU) / 1000,
-			float64(totals.reqCPU) / 1000,
-			float64(totals.limCPU) / 1000,
+			milliToCores(totals.capCPU),
+			milliToCores(totals.allocCPU),
+			milliToCores(totals.reqCPU),
+			milliToCores(totals.limCPU),
 			cpuUtil,
-			float64(totals.capMem) / (1024 * 1024),
-			float64(totals.allocMem) / (1024 * 1024),
-			float64(totals.reqMem) / (1024 * 1024),
-			float64(totals.limMem) / (1024 * 1024),
+			bytesToMi(totals.capMem),
+			bytesToMi(totals.allocMem),
+			bytesToMi(totals.reqMem),
+			bytesToMi(totals.limMem),
 			memUtil,
 		}
 
@@ -856,17 +2675,16 @@ func createNodeSheetFromData(f *excelize.File, nodeTotals map[string]struct {
 		iCell, _ := excelize.CoordinatesToCellName(9, row)
 		jCell, _ := excelize.CoordinatesToCellName(10, row)
 		kCell, _ := excelize.CoordinatesToCellName(11, row)
-		f.SetCellStyle(sheetName, hCell, hCell, getIntegerStyle(f))
-		f.SetCellStyle(sheetName, iCell, iCell, getIntegerStyle(f))
-		f.SetCellStyle(sheetName, jCell, jCell, getIntegerStyle(f))
-		f.SetCellStyle(sheetName, kCell, kCell, getIntegerStyle(f))
+		f.SetCellStyle(sheetName, hCell, hCell, export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, iCell, iCell, export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, jCell, jCell, export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, kCell, kCell, export.IntegerStyle(f))
 
-		// Right-align utilization percentage columns (G and L)
+		// Format utilization columns (G and L) as percentages
 		gCell, _ := excelize.CoordinatesToCellName(7, row)
 		lCell, _ := excelize.CoordinatesToCellName(12, row)
-		rightAlignStyle, _ := f.NewStyle(&excelize.Style{Alignment: &excelize.Alignment{Horizontal: "right"}})
-		f.SetCellStyle(sheetName, gCell, gCell, rightAlignStyle)
-		f.SetCellStyle(sheetName, lCell, lCell, rightAlignStyle)
+		f.SetCellStyle(sheetName, gCell, gCell, export.PercentStyle(f, "0.0%"))
+		f.SetCellStyle(sheetName, lCell, lCell, export.PercentStyle(f, "0.0%"))
 
 		row++
 	}
@@ -895,10 +2713,7 @@ func createNodeSheetFromData(f *excelize.File, nodeTotals map[string]struct {
 
 	return nil
 }
-func createChartSheetFromData(f *excelize.File, namespaceTotals map[string]struct {
-	reqCPU, limCPU int64
-	reqMem, limMem int64
-}, chartSheetName, summarySheetName string) error {
+func createChartSheetFromData(f *excelize.File, namespaceTotals map[string]namespaceTotal, chartSheetName, summarySheetName string) error {
 	if len(namespaceTotals) == 0 {
 		return fmt.Errorf("no namespace data available for chart creation")
 	}
@@ -985,20 +2800,6 @@ func createChartSheetFromData(f *excelize.File, namespaceTotals map[string]struc
 	return nil
 }
 
-// Enhanced error context for row operations
-func setRowWithContext(f *excelize.File, sheetName string, row int, data []interface{}, context string) error {
-	cellName, err := excelize.CoordinatesToCellName(1, row)
-	if err != nil {
-		return fmt.Errorf("failed to get cell name for row %d in %s: %w", row, context, err)
-	}
-
-	if err := f.SetSheetRow(sheetName, cellName, &data); err != nil {
-		return fmt.Errorf("failed to set row data for %s at row %d: %w", context, row, err)
-	}
-
-	return nil
-}
-
 // Memory usage monitoring
 func logMemoryUsage(stage string) {
 	var m runtime.MemStats
@@ -1007,78 +2808,6 @@ func logMemoryUsage(stage string) {
 		stage, m.Alloc/1024, m.Sys/1024)
 }
 
-// Data validation and warnings
-func validateAndWarnResources(namespaceTotals map[string]struct {
-	reqCPU, limCPU int64
-	reqMem, limMem int64
-}, nodeTotals map[string]struct {
-	podCount           int
-	reqCPU, limCPU     int64
-	reqMem, limMem     int64
-	capCPU, capMem     int64
-	allocCPU, allocMem int64
-	nodeName, nodeIP   string
-}, containerCount int) {
-
-	var warnings []string
-
-	// Check for namespaces without limits
-	noLimitsNS := 0
-	for ns, totals := range namespaceTotals {
-		if totals.limCPU == 0 && totals.limMem == 0 {
-			noLimitsNS++
-			if noLimitsNS <= 3 { // Show first 3
-				warnings = append(warnings, fmt.Sprintf("Namespace '%s' has no resource limits", ns))
-			}
-		}
-	}
-	if noLimitsNS > 3 {
-		warnings = append(warnings, fmt.Sprintf("... and %d more namespaces without limits", noLimitsNS-3))
-	}
-
-	// Check for unbalanced nodes
-	if len(nodeTotals) > 1 {
-		var podCounts []int
-		for _, totals := range nodeTotals {
-			podCounts = append(podCounts, totals.podCount)
-		}
-
-		// Simple imbalance check
-		minPods, maxPods := podCounts[0], podCounts[0]
-		for _, count := range podCounts {
-			if count < minPods {
-				minPods = count
-			}
-			if count > maxPods {
-				maxPods = count
-			}
-		}
-
-		if maxPods > minPods*2 {
-			warnings = append(warnings, fmt.Sprintf("Pod distribution imbalanced: %d-%d pods per node", minPods, maxPods))
-		}
-	}
-
-	// Log warnings
-	if len(warnings) > 0 {
-		logrus.Warn("Resource validation warnings:")
-		for _, warning := range warnings {
-			logrus.Warn("  - " + warning)
-		}
-	}
-
-	logrus.Infof("Validation complete: %d namespaces, %d nodes, %d containers",
-		len(namespaceTotals), len(nodeTotals), containerCount)
-}
-
-// Bold style for totals
-func getBoldStyle(f *excelize.File) int {
-	style, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true},
-	})
-	return style
-}
-
 // createPodSecuritySheet creates a sheet with Pod Security Standards information
 func createPodSecuritySheet(f *excelize.File, namespaces *corev1.NamespaceList, sheetName string) error {
 	_, err := f.NewSheet(sheetName)
@@ -1135,12 +2864,7 @@ func getLabel(labels map[string]string, key string) string {
 
 // getNodeIP extracts the internal IP from a node
 func getNodeIP(node *corev1.Node) string {
-	for _, addr := range node.Status.Addresses {
-		if addr.Type == corev1.NodeInternalIP {
-			return addr.Address
-		}
-	}
-	return ""
+	return report.NodeIP(node)
 }
 
 // getQoSClass determines the QoS class for a container
@@ -1168,28 +2892,21 @@ func getQoSClass(container corev1.Container) string {
 	return "Burstable"
 }
 
-// Bold number style for totals
-func getBoldIntegerStyle(f *excelize.File) int {
-	style, _ := f.NewStyle(&excelize.Style{
-		Font:   &excelize.Font{Bold: true},
-		NumFmt: 1, // 0 format (no decimal places)
-	})
-	return style
-}
-
 // Percentage calculation helper
 // Data Science Insights Sheet
-func createInsightsSheet(f *excelize.File, namespaceTotals map[string]struct {
-	reqCPU, limCPU int64
-	reqMem, limMem int64
-}, nodeTotals map[string]struct {
-	podCount           int
-	reqCPU, limCPU     int64
-	reqMem, limMem     int64
-	capCPU, capMem     int64
-	allocCPU, allocMem int64
-	nodeName, nodeIP   string
-}, sheetName string) error {
+func createInsightsSheet(f *excelize.File, namespaceTotals, teamTotals map[string]namespaceTotal, nodeTotals map[string]nodeTotal, idle []idleNamespace, opts reportOptions, sheetName string) error {
+	// setValue writes a cell, translating text and converting it to plain ASCII
+	// when requested
+	msgs := opts.messages
+	setValue := func(cell string, value interface{}) {
+		if text, ok := value.(string); ok {
+			value = msgs.text(text)
+			if opts.plainText {
+				value = plainText(value.(string))
+			}
+		}
+		f.SetCellValue(sheetName, cell, value)
+	}
 
 	_, err := f.NewSheet(sheetName)
 	if err != nil {
@@ -1199,62 +2916,50 @@ func createInsightsSheet(f *excelize.File, namespaceTotals map[string]struct {
 	row := 1
 
 	// Title
-	f.SetCellValue(sheetName, "A1", "📊 KUBERNETES RESOURCE INSIGHTS")
-	f.SetCellStyle(sheetName, "A1", "A1", getTitleStyle(f))
+	setValue("A1", "📊 KUBERNETES RESOURCE INSIGHTS")
+	f.SetCellStyle(sheetName, "A1", "A1", export.TitleStyle(f))
 	row += 3
 
 	// 1. Resource Efficiency Analysis
-	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "🎯 RESOURCE EFFICIENCY ANALYSIS")
-	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), getHeaderStyle(f))
+	setValue(fmt.Sprintf("A%d", row), "🎯 RESOURCE EFFICIENCY ANALYSIS")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
 	row += 2
 
-	var totalReqCPU, totalLimCPU, totalReqMem, totalLimMem int64
-	var overProvisionedNS, underProvisionedNS, balancedNS int
-
-	for _, totals := range namespaceTotals {
-		totalReqCPU += totals.reqCPU
-		totalLimCPU += totals.limCPU
-		totalReqMem += totals.reqMem
-		totalLimMem += totals.limMem
-
-		// Efficiency classification
-		cpuEff := float64(totals.reqCPU) / float64(totals.limCPU) * 100
-		memEff := float64(totals.reqMem) / float64(totals.limMem) * 100
-		avgEff := (cpuEff + memEff) / 2
+	score := scoreCluster(namespaceTotals, nodeTotals, opts.scoring)
 
-		if avgEff < 50 {
-			overProvisionedNS++
-		} else if avgEff > 80 {
-			underProvisionedNS++
-		} else {
-			balancedNS++
-		}
+	// Efficiency ratios for percentage cells; "-" when no limits are set
+	var clusterCPURatio, clusterMemRatio interface{} = "-", "-"
+	if score.LimitCPU > 0 {
+		clusterCPURatio = score.CPUEfficiency / 100
+	}
+	if score.LimitMemory > 0 {
+		clusterMemRatio = score.MemoryEfficiency / 100
 	}
-
-	clusterCPUEff := float64(totalReqCPU) / float64(totalLimCPU) * 100
-	clusterMemEff := float64(totalReqMem) / float64(totalLimMem) * 100
 
 	insights := [][]interface{}{
-		{"Cluster CPU Efficiency", fmt.Sprintf("%.1f%%", clusterCPUEff), getEfficiencyRating(clusterCPUEff)},
-		{"Cluster Memory Efficiency", fmt.Sprintf("%.1f%%", clusterMemEff), getEfficiencyRating(clusterMemEff)},
-		{"Over-provisioned Namespaces", overProvisionedNS, "< 50% efficiency"},
-		{"Well-balanced Namespaces", balancedNS, "50-80% efficiency"},
-		{"Under-provisioned Namespaces", underProvisionedNS, "> 80% efficiency"},
-		{"Potential CPU Savings", fmt.Sprintf("%.1f cores", float64(totalLimCPU-totalReqCPU)/1000), "If limits = requests"},
-		{"Potential Memory Savings", fmt.Sprintf("%.1f Gi", float64(totalLimMem-totalReqMem)/(1024*1024*1024)), "If limits = requests"},
+		{"Cluster CPU Efficiency", clusterCPURatio, opts.scoring.Rating(score.CPUEfficiency)},
+		{"Cluster Memory Efficiency", clusterMemRatio, opts.scoring.Rating(score.MemoryEfficiency)},
+		{"Over-provisioned Namespaces", score.OverProvisioned, opts.scoring.describe(report.NamespaceOverProvisioned, msgs)},
+		{"Well-balanced Namespaces", score.Balanced, opts.scoring.describe(report.NamespaceBalanced, msgs)},
+		{"Under-provisioned Namespaces", score.UnderProvisioned, opts.scoring.describe(report.NamespaceUnderProvisioned, msgs)},
+		{"Potential CPU Savings", msgs.sprintf("%.1f cores", milliToCores(score.LimitCPU-score.RequestCPU)), "If limits = requests"},
+		{"Potential Memory Savings", msgs.sprintf("%.1f Gi", bytesToGi(score.LimitMemory-score.RequestMemory)), "If limits = requests"},
 	}
 
 	for _, insight := range insights {
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), insight[0])
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), insight[1])
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), insight[2])
+		setValue(fmt.Sprintf("A%d", row), insight[0])
+		setValue(fmt.Sprintf("B%d", row), insight[1])
+		setValue(fmt.Sprintf("C%d", row), insight[2])
+		if _, ok := insight[1].(float64); ok {
+			f.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("B%d", row), export.PercentStyle(f, "0.0%"))
+		}
 		row++
 	}
 	row += 2
 
 	// 2. Node Distribution Analysis
-	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "🏗️ NODE DISTRIBUTION ANALYSIS")
-	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), getHeaderStyle(f))
+	setValue(fmt.Sprintf("A%d", row), "🏗️ NODE DISTRIBUTION ANALYSIS")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
 	row += 2
 
 	var podCounts []int
@@ -1266,29 +2971,98 @@ func createInsightsSheet(f *excelize.File, namespaceTotals map[string]struct {
 		{"Total Nodes", len(nodeTotals), ""},
 		{"Average Pods per Node", fmt.Sprintf("%.1f", average(podCounts)), ""},
 		{"Pod Distribution StdDev", fmt.Sprintf("%.1f", stdDev(podCounts)), "Lower = better balance"},
-		{"Most Loaded Node", fmt.Sprintf("%d pods", max(podCounts)), ""},
-		{"Least Loaded Node", fmt.Sprintf("%d pods", min(podCounts)), ""},
+		{"Most Loaded Node", msgs.sprintf("%d pods", max(podCounts)), ""},
+		{"Least Loaded Node", msgs.sprintf("%d pods", min(podCounts)), ""},
 		{"Load Balance Score", getBalanceScore(podCounts), "0-100 (100 = perfect)"},
 	}
 
 	for _, insight := range nodeInsights {
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), insight[0])
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), insight[1])
-		f.SetCellValue(sheetName, fmt.Sprintf("C%d", row), insight[2])
+		setValue(fmt.Sprintf("A%d", row), insight[0])
+		setValue(fmt.Sprintf("B%d", row), insight[1])
+		setValue(fmt.Sprintf("C%d", row), insight[2])
+		row++
+	}
+	row += 2
+
+	// 3. Idle Namespaces (no pod created or restarted within the idle period)
+	if opts.idleAfter > 0 {
+		setValue(fmt.Sprintf("A%d", row), "💤 IDLE NAMESPACES")
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
+		row++
+		setValue(fmt.Sprintf("A%d", row), msgs.sprintf("No pod created or restarted in the last %d days; their requests are reclaimable", int(opts.idleAfter.Hours()/24)))
+		row += 2
+
+		if len(idle) == 0 {
+			setValue(fmt.Sprintf("A%d", row), "No idle namespaces")
+			row++
+		} else {
+			headers := []interface{}{msgs.text("Namespace"), msgs.text("Pods"), msgs.text("Last Activity"), msgs.text("Request CPU (cores)"), msgs.text("Request Memory (Gi)")}
+			if err := export.SetRow(f, sheetName, row, headers, "idle namespace headers"); err != nil {
+				return err
+			}
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("E%d", row), export.BoldStyle(f))
+			row++
+
+			first := row
+			location := opts.metadata.generated.Location()
+			for _, ns := range idle {
+				data := []interface{}{
+					ns.namespace,
+					ns.pods,
+					ns.lastActivity.In(location).Format("2006-01-02"),
+					milliToCores(ns.reqCPU),
+					bytesToGi(ns.reqMem),
+				}
+				if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("idle namespace '%s'", ns.namespace)); err != nil {
+					return err
+				}
+				row++
+			}
+
+			setValue(fmt.Sprintf("A%d", row), "Reclaimable")
+			for _, col := range []string{"D", "E"} {
+				f.SetCellFormula(sheetName, fmt.Sprintf("%s%d", col, row), fmt.Sprintf("SUM(%s%d:%s%d)", col, first, col, row-1))
+			}
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row), export.BoldStyle(f))
+			f.SetCellStyle(sheetName, fmt.Sprintf("D%d", first), fmt.Sprintf("E%d", row-1), export.DecimalStyle(f, false))
+			f.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("E%d", row), export.DecimalStyle(f, true))
+			row++
+		}
+		row += 2
+	}
+
+	// 4. Tenant Fairness (how evenly requests are shared across namespaces and teams)
+	setValue(fmt.Sprintf("A%d", row), "⚖️ TENANT FAIRNESS")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
+	row += 2
+
+	fairness := fairnessInsights(msgs, "namespaces", namespaceTotals)
+	if teamTotals != nil {
+		fairness = append(fairness, fairnessInsights(msgs, "teams", teamTotals)...)
+	}
+	for _, insight := range fairness {
+		setValue(fmt.Sprintf("A%d", row), insight[0])
+		setValue(fmt.Sprintf("B%d", row), insight[1])
+		setValue(fmt.Sprintf("C%d", row), insight[2])
+		style := export.DecimalStyle(f, false)
+		if format := insight[3].(string); strings.HasSuffix(format, "%") {
+			style = export.PercentStyle(f, format)
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("B%d", row), fmt.Sprintf("B%d", row), style)
 		row++
 	}
 	row += 2
 
-	// 3. Recommendations
-	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "💡 OPTIMIZATION RECOMMENDATIONS")
-	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), getHeaderStyle(f))
+	// 5. Recommendations
+	setValue(fmt.Sprintf("A%d", row), "💡 OPTIMIZATION RECOMMENDATIONS")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
 	row += 2
 
-	recommendations := generateRecommendations(clusterCPUEff, clusterMemEff, overProvisionedNS, underProvisionedNS, getBalanceScoreValue(podCounts))
+	recommendations := opts.scoring.Recommendations(score)
 
 	for _, rec := range recommendations {
-		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "•")
-		f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), rec)
+		setValue(fmt.Sprintf("A%d", row), "•")
+		setValue(fmt.Sprintf("B%d", row), rec)
 		row++
 	}
 
@@ -1296,6 +3070,7 @@ func createInsightsSheet(f *excelize.File, namespaceTotals map[string]struct {
 	f.SetColWidth(sheetName, "A", "A", 25)
 	f.SetColWidth(sheetName, "B", "B", 20)
 	f.SetColWidth(sheetName, "C", "C", 30)
+	f.SetColWidth(sheetName, "D", "E", 20)
 
 	return nil
 }
@@ -1351,73 +3126,5 @@ func min(values []int) int {
 }
 
 func getBalanceScore(values []int) string {
-	return fmt.Sprintf("%.0f", getBalanceScoreValue(values))
-}
-
-func getBalanceScoreValue(values []int) float64 {
-	if len(values) <= 1 {
-		return 100
-	}
-	std := stdDev(values)
-	avg := average(values)
-	if avg == 0 {
-		return 100
-	}
-	cv := std / avg                  // Coefficient of variation
-	return math.Max(0, 100-(cv*100)) // Lower CV = better balance
-}
-
-func getEfficiencyRating(eff float64) string {
-	if eff >= 80 {
-		return "⚠️ Under-provisioned"
-	}
-	if eff >= 60 {
-		return "✅ Well-balanced"
-	}
-	if eff >= 40 {
-		return "⚡ Over-provisioned"
-	}
-	return "🔴 Severely over-provisioned"
-}
-
-func generateRecommendations(cpuEff, memEff float64, overProv, underProv int, balanceScore float64) []string {
-	var recs []string
-
-	if cpuEff < 50 {
-		recs = append(recs, "Consider reducing CPU limits - cluster is over-provisioned")
-	}
-	if memEff < 50 {
-		recs = append(recs, "Consider reducing Memory limits - cluster is over-provisioned")
-	}
-	if cpuEff > 80 {
-		recs = append(recs, "⚠️ CPU limits too tight - risk of throttling")
-	}
-	if memEff > 80 {
-		recs = append(recs, "⚠️ Memory limits too tight - risk of OOM kills")
-	}
-	if overProv > underProv {
-		recs = append(recs, "Focus on right-sizing over-provisioned namespaces first")
-	}
-	if balanceScore < 70 {
-		recs = append(recs, "Consider pod anti-affinity rules for better node distribution")
-	}
-	if len(recs) == 0 {
-		recs = append(recs, "✅ Cluster resource allocation looks well-balanced!")
-	}
-
-	return recs
-}
-
-func getTitleStyle(f *excelize.File) int {
-	style, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true, Size: 16},
-	})
-	return style
-}
-
-func getHeaderStyle(f *excelize.File) int {
-	style, _ := f.NewStyle(&excelize.Style{
-		Font: &excelize.Font{Bold: true, Size: 12},
-	})
-	return style
+	return fmt.Sprintf("%.0f", report.BalanceScore(values))
 }
@@ -1,7 +1,16 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 )
 
 func TestValidatePath(t *testing.T) {
@@ -64,21 +73,23 @@ func TestValidateNamespace(t *testing.T) {
 
 func TestGetOutputFilename(t *testing.T) {
 	tests := []struct {
-		name   string
-		output string
-		want   string
+		name    string
+		output  string
+		cluster string
+		want    string
 	}{
-		{"empty returns default", "", "resource_"},
-		{"custom filename", "custom.xlsx", "custom.xlsx"},
-		{"path with traversal gets cleaned", "../output.xlsx", "../output.xlsx"},
+		{"empty returns default", "", "", "resource_"},
+		{"default with cluster", "", "prod-eu", "resource_prod-eu_"},
+		{"custom filename", "custom.xlsx", "prod-eu", "custom.xlsx"},
+		{"path with traversal gets cleaned", "../output.xlsx", "", "../output.xlsx"},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := getOutputFilename(tt.output)
-			if tt.want == "resource_" {
-				// Check it starts with resource_ (date will vary)
-				if len(got) < 18 || got[:9] != "resource_" {
+			got := getOutputFilename(tt.output, tt.cluster, time.Now())
+			if strings.HasPrefix(tt.want, "resource_") {
+				// Check the prefix and that a date follows (date will vary)
+				if len(got) < len(tt.want)+len("2006-01-02.xlsx") || !strings.HasPrefix(got, tt.want) {
 					t.Errorf("getOutputFilename() = %v, want prefix %v", got, tt.want)
 				}
 			} else if got != tt.want {
@@ -107,3 +118,60 @@ func TestGetNamespaceDisplay(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAPIEncoding(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", APIEncodingProtobuf, false},
+		{"protobuf", APIEncodingProtobuf, false},
+		{"json", APIEncodingJSON, false},
+		{"cbor", "", true},
+	}
+	for _, tt := range tests {
+		got, err := parseAPIEncoding(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseAPIEncoding(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestSetAPIEncoding(t *testing.T) {
+	tests := []struct {
+		encoding   string
+		wantAccept string
+	}{
+		{APIEncodingProtobuf, "application/vnd.kubernetes.protobuf,application/json"},
+		{APIEncodingJSON, "application/json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			var accept string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get("Accept")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"resourceVersion":"7"},"items":[]}`))
+			}))
+			defer srv.Close()
+
+			config := &rest.Config{Host: srv.URL}
+			setAPIEncoding(config, tt.encoding)
+			clientSet, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pods, err := clientSet.CoreV1().Pods("").List(context.Background(), metav1.ListOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if accept != tt.wantAccept {
+				t.Errorf("Accept = %q, want %q", accept, tt.wantAccept)
+			}
+			if pods.ResourceVersion != "7" {
+				t.Errorf("resourceVersion = %q, want 7", pods.ResourceVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// markdownFilename turns a default report filename into the default Markdown name
+func markdownFilename(filename string) string {
+	return strings.TrimSuffix(filename, filepath.Ext(filename)) + ".md"
+}
+
+// writeMarkdownReport writes the Markdown summary of a report document to
+// filename or stdout
+func writeMarkdownReport(filename string, doc jsonReport) (err error) {
+	if filename == StdoutPath {
+		if err := renderMarkdownReport(stdout, doc); err != nil {
+			return fmt.Errorf("failed to write to stdout: %w", err)
+		}
+		return nil
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", filename, err)
+	}
+	defer func() {
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to write %s: %w", filename, cerr)
+		}
+	}()
+	if err := renderMarkdownReport(file, doc); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+	return nil
+}
+
+// renderMarkdownReport writes the namespace and node totals, the insights and
+// the findings of a report as GitHub flavored Markdown tables, for chat
+// messages, wiki pages and pull request comments. The containers stay in the
+// JSON and BI exports.
+func renderMarkdownReport(out io.Writer, doc jsonReport) error {
+	var b strings.Builder
+	title := "Resource Report"
+	if doc.Cluster != "" {
+		title += ": " + doc.Cluster
+	}
+	if doc.Group != "" {
+		title += " (" + doc.Group + ")"
+	}
+	fmt.Fprintf(&b, "# %s\n\nGenerated %s, %s.\n", markdownCell(title), doc.Generated.Format(time.RFC3339), pluralize(len(doc.Containers), "container"))
+
+	b.WriteString("\n## Namespaces\n\n")
+	rows := make([][]string, 0, len(doc.Namespaces))
+	for _, ns := range doc.Namespaces {
+		rows = append(rows, []string{ns.Name, valueOrDash(ns.Phase),
+			formatCores(ns.RequestCPUMillicores), formatCores(ns.LimitCPUMillicores),
+			formatMi(ns.RequestMemoryBytes), formatMi(ns.LimitMemoryBytes)})
+	}
+	writeMarkdownTable(&b, []string{"Namespace", "Phase", "Request CPU (cores)", "Limit CPU (cores)", "Request Memory (Mi)", "Limit Memory (Mi)"}, rows)
+
+	b.WriteString("\n## Nodes\n\n")
+	rows = rows[:0]
+	for _, n := range doc.Nodes {
+		rows = append(rows, []string{valueOrDash(n.Name), n.IP, fmt.Sprint(n.Pods),
+			formatCores(n.RequestCPUMillicores), formatCores(n.AllocatableCPUMillicores), formatRatio(n.CPURequestRatio),
+			formatMi(n.RequestMemoryBytes), formatMi(n.AllocatableMemoryBytes), formatRatio(n.MemoryRequestRatio)})
+	}
+	writeMarkdownTable(&b, []string{"Node", "IP", "Pods", "Request CPU (cores)", "Allocatable CPU (cores)", "CPU Requested",
+		"Request Memory (Mi)", "Allocatable Memory (Mi)", "Memory Requested"}, rows)
+
+	insights := doc.Insights
+	b.WriteString("\n## Insights\n\n")
+	fmt.Fprintf(&b, "- CPU efficiency: %s\n", strings.TrimSpace(formatRatio(insights.CPUEfficiency)+" "+insights.CPURating))
+	fmt.Fprintf(&b, "- Memory efficiency: %s\n", strings.TrimSpace(formatRatio(insights.MemoryEfficiency)+" "+insights.MemoryRating))
+	fmt.Fprintf(&b, "- Namespaces: %d over-provisioned, %d balanced, %d under-provisioned\n",
+		insights.OverProvisionedNamespaces, insights.BalancedNamespaces, insights.UnderProvisionedNamespaces)
+	fmt.Fprintf(&b, "- Potential savings if limits = requests: %s cores, %s Mi\n",
+		formatCores(insights.PotentialCPUSavingsMillicores), formatMi(insights.PotentialMemorySavingsBytes))
+	fmt.Fprintf(&b, "- Load Balance Score: %.0f\n", insights.LoadBalanceScore)
+	for _, rec := range insights.Recommendations {
+		fmt.Fprintf(&b, "- %s\n", markdownCell(rec))
+	}
+
+	b.WriteString("\n## Findings\n\n")
+	rows = rows[:0]
+	for _, f := range doc.Findings {
+		rows = append(rows, []string{f.Severity, f.Rule, f.Subject, f.Message})
+	}
+	writeMarkdownTable(&b, []string{"Severity", "Rule", "Subject", "Message"}, rows)
+
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+// writeMarkdownTable writes a table, or a note when it has no rows
+func writeMarkdownTable(b *strings.Builder, headers []string, rows [][]string) {
+	if len(rows) == 0 {
+		b.WriteString("None.\n")
+		return
+	}
+	writeRow := func(cells []string) {
+		for _, cell := range cells {
+			b.WriteString("| " + markdownCell(cell) + " ")
+		}
+		b.WriteString("|\n")
+	}
+	writeRow(headers)
+	b.WriteString(strings.Repeat("|---", len(headers)) + "|\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
+
+// markdownCell escapes the characters that break a table cell or a list item
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// formatCores formats millicores as cores with two decimal places
+func formatCores(milli int64) string {
+	return fmt.Sprintf("%.2f", milliToCores(milli))
+}
+
+// formatMi formats bytes as whole Mi
+func formatMi(bytes int64) string {
+	return fmt.Sprintf("%.0f", bytesToMi(bytes))
+}
+
+// formatRatio formats a ratio as a percentage, a dash when unknown
+func formatRatio(ratio *float64) string {
+	if ratio == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", *ratio*100)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+)
+
+func TestWriteMarkdownReport(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	rules, _ := parseValidationRules(validationSpec{})
+	doc := buildJSONReport(testAPISnapshot(now), reportOptions{validation: rules}, reportMetadata{})
+
+	path := filepath.Join(t.TempDir(), "report.md")
+	if err := writeMarkdownReport(path, doc); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Resource Report\n", "## Namespaces\n\n| Namespace |", "## Nodes\n", "## Insights\n", "## Findings\n"} {
+		if !bytes.Contains(data, []byte(want)) {
+			t.Errorf("report lacks %q:\n%s", want, data)
+		}
+	}
+	for _, ns := range doc.Namespaces {
+		if !bytes.Contains(data, []byte("| "+ns.Name+" |")) {
+			t.Errorf("report lacks namespace %q", ns.Name)
+		}
+	}
+
+	var buf bytes.Buffer
+	saved := stdout
+	stdout = &buf
+	defer func() { stdout = saved }()
+	if err := writeMarkdownReport(StdoutPath, doc); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("stdout differs from the file export")
+	}
+}
+
+func TestRenderMarkdownReport(t *testing.T) {
+	ratio := 0.5
+	doc := jsonReport{
+		Report: &report.Report{
+			Generated: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC),
+			Cluster:   "prod",
+			Namespaces: []report.Namespace{
+				{Name: "shop", Phase: "Active", RequestCPUMillicores: 1500, LimitCPUMillicores: 3000, RequestMemoryBytes: 512 * 1024 * 1024},
+			},
+			Nodes: []report.Node{{IP: "Unknown", Pods: 1}},
+			Insights: report.Insights{
+				CPUEfficiency:   &ratio,
+				CPURating:       "Good",
+				Recommendations: []string{"Set limits"},
+			},
+		},
+		Findings: []findingsRecord{{Rule: "no-limits", Severity: "warning", Subject: "shop/web|api", Message: "No limits"}},
+	}
+
+	var buf bytes.Buffer
+	if err := renderMarkdownReport(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# Resource Report: prod\n\nGenerated 2024-05-10T12:00:00Z, 0 containers.\n",
+		"| shop | Active | 1.50 | 3.00 | 512 | 0 |\n",
+		"| - | Unknown | 1 | 0.00 | 0.00 | - | 0 | 0 | - |\n",
+		"- CPU efficiency: 50.0% Good\n",
+		"- Memory efficiency: -\n",
+		"- Set limits\n",
+		"| warning | no-limits | shop/web\\|api | No limits |\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report lacks %q:\n%s", want, got)
+		}
+	}
+
+	buf.Reset()
+	if err := renderMarkdownReport(&buf, jsonReport{Report: &report.Report{}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(buf.String(), "None.\n"); n != 3 {
+		t.Errorf("empty report has %d empty tables, want 3:\n%s", n, buf.String())
+	}
+}
+
+func TestMarkdownFilename(t *testing.T) {
+	if got := markdownFilename("resource_prod_2024-05-10.xlsx"); got != "resource_prod_2024-05-10.md" {
+		t.Errorf("markdownFilename() = %q", got)
+	}
+}
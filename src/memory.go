@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// MemoryLimitAuto as -memory-limit derives the limit from the cgroup
+const MemoryLimitAuto = "auto"
+
+// MemoryLimitHeadroom is the share of the cgroup limit the Go heap may use with
+// -memory-limit auto; the rest covers stacks, excelize buffers and the runtime
+const MemoryLimitHeadroom = 0.9
+
+// cgroupMemoryFiles hold the memory limit of the container: cgroup v2, then v1
+var cgroupMemoryFiles = []string{
+	"/sys/fs/cgroup/memory.max",
+	"/sys/fs/cgroup/memory/memory.limit_in_bytes",
+}
+
+// cgroupUnlimited is the cgroup v1 limit of an unlimited container (page aligned MaxInt64)
+const cgroupUnlimited = 1 << 62
+
+// parseMemoryLimit returns the soft memory limit in bytes of -memory-limit: a
+// Kubernetes quantity like 200Mi, or auto for a share of the cgroup limit. 0
+// means no limit.
+func parseMemoryLimit(s string, readFile func(string) ([]byte, error)) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if s == MemoryLimitAuto {
+		for _, path := range cgroupMemoryFiles {
+			data, err := readFile(path)
+			if err != nil {
+				continue
+			}
+			value := strings.TrimSpace(string(data))
+			if value == "max" {
+				return 0, nil
+			}
+			limit, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid cgroup memory limit in %s: %w", path, err)
+			}
+			if limit >= cgroupUnlimited {
+				return 0, nil
+			}
+			return int64(float64(limit) * MemoryLimitHeadroom), nil
+		}
+		return 0, fmt.Errorf("no cgroup memory limit found, set the limit explicitly")
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory limit '%s': %w", s, err)
+	}
+	if q.Sign() <= 0 {
+		return 0, fmt.Errorf("memory limit must be positive")
+	}
+	return q.Value(), nil
+}
+
+// configureMemory applies -memory-limit and -gc-percent to the Go runtime. The
+// GOMEMLIMIT and GOGC environment variables apply when the flags are unset.
+func configureMemory(memoryLimit string, gcPercent int) error {
+	limit, err := parseMemoryLimit(memoryLimit, os.ReadFile)
+	if err != nil {
+		return err
+	}
+	if limit > 0 {
+		debug.SetMemoryLimit(limit)
+		logrus.Debugf("Soft memory limit: %d MiB", limit>>20)
+	} else if memoryLimit == MemoryLimitAuto {
+		logrus.Debugf("Container has no memory limit, keeping the Go default")
+	}
+	if gcPercent != 0 {
+		if gcPercent < -1 {
+			return fmt.Errorf("gc-percent must be positive, or -1 to collect only at the memory limit")
+		}
+		debug.SetGCPercent(gcPercent)
+		logrus.Debugf("GC percent: %d", gcPercent)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestParseMemoryLimit(t *testing.T) {
+	cgroup := func(files map[string]string) func(string) ([]byte, error) {
+		return func(path string) ([]byte, error) {
+			if data, ok := files[path]; ok {
+				return []byte(data), nil
+			}
+			return nil, os.ErrNotExist
+		}
+	}
+	v2 := "/sys/fs/cgroup/memory.max"
+	v1 := "/sys/fs/cgroup/memory/memory.limit_in_bytes"
+	tests := []struct {
+		name    string
+		input   string
+		files   map[string]string
+		want    int64
+		wantErr bool
+	}{
+		{"unset", "", nil, 0, false},
+		{"quantity", "200Mi", nil, 200 << 20, false},
+		{"decimal quantity", "1G", nil, 1000000000, false},
+		{"auto cgroup v2", "auto", map[string]string{v2: "268435456\n"}, 241591910, false},
+		{"auto cgroup v2 unlimited", "auto", map[string]string{v2: "max\n"}, 0, false},
+		{"auto cgroup v1", "auto", map[string]string{v1: "536870912\n"}, 483183820, false},
+		{"auto cgroup v1 unlimited", "auto", map[string]string{v1: "9223372036854771712\n"}, 0, false},
+		{"auto without cgroup", "auto", nil, 0, true},
+		{"invalid", "lots", nil, 0, true},
+		{"negative", "-1Mi", nil, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseMemoryLimit(tt.input, cgroup(tt.files))
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseMemoryLimit(%q) = %d, %v; want %d", tt.input, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+)
+
+// MetricsAPIPath is the metrics-server API of current pod usage
+const MetricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+// usageHeaders are the Resources sheet columns of -with-usage
+var usageHeaders = []string{"Actual CPU (m)", "Actual Memory (Mi)", "CPU Usage % of Request", "Memory Usage % of Request"}
+
+// metricsSample is the current usage of a container from metrics-server
+type metricsSample struct {
+	cpu int64 // Millicores
+	mem int64 // Bytes (working set)
+}
+
+// containerMetrics is the usage by "namespace/pod/container"
+type containerMetrics map[string]metricsSample
+
+// podMetricsList is the part of a metrics.k8s.io PodMetricsList that is read
+type podMetricsList struct {
+	Items []struct {
+		Metadata struct {
+			Namespace string `json:"namespace"`
+			Name      string `json:"name"`
+		} `json:"metadata"`
+		Containers []struct {
+			Name  string              `json:"name"`
+			Usage corev1.ResourceList `json:"usage"`
+		} `json:"containers"`
+	} `json:"items"`
+}
+
+// parsePodMetrics reads the container usage of a PodMetricsList response
+func parsePodMetrics(data []byte) (containerMetrics, error) {
+	var list podMetricsList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse pod metrics: %w", err)
+	}
+	metrics := make(containerMetrics)
+	for _, item := range list.Items {
+		for _, c := range item.Containers {
+			metrics[item.Metadata.Namespace+"/"+item.Metadata.Name+"/"+c.Name] = metricsSample{
+				cpu: quantityMilli(c.Usage.Cpu()),
+				mem: quantityBytes(c.Usage.Memory()),
+			}
+		}
+	}
+	return metrics, nil
+}
+
+// fetchPodMetrics lists the current pod usage of namespace (all when empty)
+// from metrics-server, which needs list on pods.metrics.k8s.io. The response is
+// requested as JSON: the client asks for protobuf by default (-api-encoding),
+// which parsePodMetrics cannot read.
+func fetchPodMetrics(ctx context.Context, clientSet kubernetes.Interface, namespace string) (containerMetrics, error) {
+	req := clientSet.CoreV1().RESTClient().Get().AbsPath(MetricsAPIPath, "pods")
+	if namespace != "" {
+		req = clientSet.CoreV1().RESTClient().Get().AbsPath(MetricsAPIPath, "namespaces", namespace, "pods")
+	}
+	data, err := req.SetHeader("Accept", k8sruntime.ContentTypeJSON).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metrics-server (is it installed?): %w", err)
+	}
+	return parsePodMetrics(data)
+}
+
+// retain drops the usage of containers not in pods, e.g. of namespaces outside
+// the patterns or opted out
+func (m containerMetrics) retain(pods []corev1.Pod) containerMetrics {
+	if m == nil {
+		return nil
+	}
+	kept := make(containerMetrics, len(m))
+	for i := range pods {
+		pod := &pods[i]
+		for _, c := range podContainers(pod) {
+			key := pod.Namespace + "/" + pod.Name + "/" + c.Name
+			if usage, ok := m[key]; ok {
+				kept[key] = usage
+			}
+		}
+	}
+	return kept
+}
+
+// columns returns the usage cells of a container row with requests in
+// millicores and bytes; cells stay empty without a sample or request
+func (m containerMetrics) columns(namespace, pod, container string, reqCPU, reqMem int64) []interface{} {
+	usage, ok := m[namespace+"/"+pod+"/"+container]
+	if !ok {
+		return []interface{}{nil, nil, nil, nil}
+	}
+	var cpuPct, memPct interface{}
+	if reqCPU > 0 {
+		cpuPct = float64(usage.cpu) / float64(reqCPU)
+	}
+	if reqMem > 0 {
+		memPct = float64(usage.mem) / float64(reqMem)
+	}
+	return []interface{}{usage.cpu, bytesToWholeMi(usage.mem), cpuPct, memPct}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestParsePodMetrics(t *testing.T) {
+	data := []byte(`{"kind":"PodMetricsList","items":[
+		{"metadata":{"namespace":"shop","name":"web-1"},"containers":[
+			{"name":"app","usage":{"cpu":"250m","memory":"512Mi"}},
+			{"name":"proxy","usage":{"cpu":"1500000n","memory":"20Mi"}}]}]}`)
+	got, err := parsePodMetrics(data)
+	if err != nil {
+		t.Fatalf("parsePodMetrics() error = %v", err)
+	}
+	want := containerMetrics{
+		"shop/web-1/app":   {cpu: 250, mem: 512 << 20},
+		"shop/web-1/proxy": {cpu: 2, mem: 20 << 20},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePodMetrics() = %+v, want %+v", got, want)
+	}
+	if _, err := parsePodMetrics([]byte("not json")); err == nil {
+		t.Error("parsePodMetrics(invalid) expected error")
+	}
+}
+
+func TestContainerMetricsRetain(t *testing.T) {
+	m := containerMetrics{
+		"shop/web-1/app":   {cpu: 100},
+		"shop/web-2/app":   {cpu: 200},
+		"system/dns-1/dns": {cpu: 10},
+	}
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web-1"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}}
+	if got, want := m.retain(pods), (containerMetrics{"shop/web-1/app": {cpu: 100}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("retain() = %+v, want %+v", got, want)
+	}
+	if got := containerMetrics(nil).retain(pods); got != nil {
+		t.Errorf("nil retain() = %+v, want nil", got)
+	}
+}
+
+func TestContainerMetricsColumns(t *testing.T) {
+	m := containerMetrics{"shop/web-1/app": {cpu: 125, mem: 256 << 20}}
+	tests := []struct {
+		name           string
+		container      string
+		reqCPU, reqMem int64
+		want           []interface{}
+	}{
+		{"with requests", "app", 500, 1 << 30, []interface{}{int64(125), bytesToWholeMi(256 << 20), 0.25, 0.25}},
+		{"without requests", "app", 0, 0, []interface{}{int64(125), bytesToWholeMi(256 << 20), nil, nil}},
+		{"no sample", "proxy", 500, 1 << 30, []interface{}{nil, nil, nil, nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := m.columns("shop", "web-1", tt.container, tt.reqCPU, tt.reqMem); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("columns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchPodMetrics(t *testing.T) {
+	for _, encoding := range []string{APIEncodingProtobuf, APIEncodingJSON} {
+		t.Run(encoding, func(t *testing.T) {
+			var accept, path string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept, path = r.Header.Get("Accept"), r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"kind":"PodMetricsList","items":[
+					{"metadata":{"namespace":"shop","name":"web-1"},"containers":[{"name":"app","usage":{"cpu":"250m","memory":"512Mi"}}]}]}`))
+			}))
+			defer srv.Close()
+
+			// The client of the report, which asks for protobuf by default
+			config := &rest.Config{Host: srv.URL}
+			setAPIEncoding(config, encoding)
+			clientSet, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := fetchPodMetrics(context.Background(), clientSet, "shop")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if accept != "application/json" {
+				t.Errorf("Accept = %q, want application/json", accept)
+			}
+			if path != MetricsAPIPath+"/namespaces/shop/pods" {
+				t.Errorf("path = %q", path)
+			}
+			if got["shop/web-1/app"] != (metricsSample{cpu: 250, mem: 512 << 20}) {
+				t.Errorf("fetchPodMetrics() = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Workbook defined names for downstream automation
+const (
+	NameClusterTotalCPU = "ClusterTotalCPU" // Requested CPU in cores
+	NameClusterTotalMem = "ClusterTotalMem" // Requested memory in Mi
+	NameClusterLimitCPU = "ClusterLimitCPU" // CPU limits in cores
+	NameClusterLimitMem = "ClusterLimitMem" // Memory limits in Mi
+	NameReportDate      = "report_date"     // Generation time as RFC 3339 text
+)
+
+// ReportGenerator is the creator written into the document properties
+const ReportGenerator = "PodResourceCalculator"
+
+// clusterTotals sums the requests and limits of all namespaces
+func clusterTotals(namespaceTotals map[string]namespaceTotal) namespaceTotal {
+	var sum namespaceTotal
+	for _, totals := range namespaceTotals {
+		sum.reqCPU += totals.reqCPU
+		sum.limCPU += totals.limCPU
+		sum.reqMem += totals.reqMem
+		sum.limMem += totals.limMem
+	}
+	return sum
+}
+
+// setWorkbookNames adds workbook scoped defined names for the cluster totals
+// and the report date, and document properties with the report metadata, so
+// formulas and BI tools do not depend on cell positions. The totals refer to
+// the CLUSTER TOTAL row of the Namespaces sheet when it is part of the
+// workbook (namespaceSheet not empty) and are constants otherwise.
+func setWorkbookNames(f *excelize.File, meta reportMetadata, namespaceTotals map[string]namespaceTotal, namespaceSheet string) error {
+	sum := clusterTotals(namespaceTotals)
+	values := []struct {
+		name   string
+		column string // Column of the Namespaces sheet
+		value  float64
+	}{
+		{NameClusterTotalCPU, "B", milliToCores(sum.reqCPU)},
+		{NameClusterLimitCPU, "C", milliToCores(sum.limCPU)},
+		{NameClusterTotalMem, "D", bytesToMi(sum.reqMem)},
+		{NameClusterLimitMem, "E", bytesToMi(sum.limMem)},
+	}
+	// The totals row follows the header and one row per namespace
+	totalRow := len(namespaceTotals) + 2
+	for _, v := range values {
+		refersTo := strconv.FormatFloat(v.value, 'f', -1, 64)
+		if namespaceSheet != "" {
+			refersTo = fmt.Sprintf("'%s'!$%s$%d", strings.ReplaceAll(namespaceSheet, "'", "''"), v.column, totalRow)
+		}
+		if err := f.SetDefinedName(&excelize.DefinedName{Name: v.name, RefersTo: refersTo}); err != nil {
+			return fmt.Errorf("failed to set defined name %s: %w", v.name, err)
+		}
+	}
+	generated := meta.generated.Format(time.RFC3339)
+	if err := f.SetDefinedName(&excelize.DefinedName{Name: NameReportDate, RefersTo: strconv.Quote(generated)}); err != nil {
+		return fmt.Errorf("failed to set defined name %s: %w", NameReportDate, err)
+	}
+
+	if err := f.SetDocProps(&excelize.DocProperties{
+		Title:       reportTitle(meta),
+		Subject:     meta.scopeDisplay(),
+		Creator:     ReportGenerator,
+		Created:     meta.generated.UTC().Format(time.RFC3339),
+		Keywords:    "kubernetes, resources, requests, limits",
+		Description: "Kubernetes pod resource requests and limits",
+	}); err != nil {
+		return fmt.Errorf("failed to set document properties: %w", err)
+	}
+
+	props := []excelize.CustomProperty{
+		{Name: "Cluster", Value: meta.cluster.name},
+		{Name: "Context", Value: meta.cluster.context},
+		{Name: "Namespace", Value: meta.namespace},
+		{Name: "Group", Value: meta.group},
+		{Name: "Generated", Value: meta.generated},
+	}
+	for _, v := range values {
+		props = append(props, excelize.CustomProperty{Name: v.name, Value: v.value})
+	}
+	for _, prop := range props {
+		if s, ok := prop.Value.(string); ok && s == "" {
+			continue
+		}
+		if err := f.SetCustomProps(prop); err != nil {
+			return fmt.Errorf("failed to set document property %s: %w", prop.Name, err)
+		}
+	}
+	return nil
+}
+
+// reportTitle names the report after the cluster and split group
+func reportTitle(meta reportMetadata) string {
+	title := "Pod Resource Report"
+	if meta.cluster.name != "" {
+		title += " - " + meta.cluster.name
+	}
+	if meta.group != "" {
+		title += " (" + meta.group + ")"
+	}
+	return title
+}
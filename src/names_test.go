@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSetWorkbookNames(t *testing.T) {
+	generated := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	meta := reportMetadata{cluster: clusterIdentity{name: "prod"}, generated: generated}
+	totals := map[string]namespaceTotal{
+		"shop":   {reqCPU: 1500, limCPU: 3000, reqMem: 2 * BytesPerGi, limMem: 4 * BytesPerGi},
+		"search": {reqCPU: 500, reqMem: BytesPerGi},
+	}
+
+	tests := []struct {
+		name           string
+		namespaceSheet string
+		want           map[string]string
+	}{
+		{"namespaces sheet", "Namespaces", map[string]string{
+			NameClusterTotalCPU: "'Namespaces'!$B$4",
+			NameClusterLimitCPU: "'Namespaces'!$C$4",
+			NameClusterTotalMem: "'Namespaces'!$D$4",
+			NameClusterLimitMem: "'Namespaces'!$E$4",
+			NameReportDate:      `"2024-05-10T12:00:00Z"`,
+		}},
+		{"constants", "", map[string]string{
+			NameClusterTotalCPU: "2",
+			NameClusterLimitCPU: "3",
+			NameClusterTotalMem: "3072",
+			NameClusterLimitMem: "4096",
+			NameReportDate:      `"2024-05-10T12:00:00Z"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := excelize.NewFile()
+			defer f.Close()
+			if tt.namespaceSheet != "" {
+				if err := createSummarySheetFromData(f, totals, nil, generated, tt.namespaceSheet); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := setWorkbookNames(f, meta, totals, tt.namespaceSheet); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			for _, dn := range f.GetDefinedName() {
+				got[dn.Name] = dn.RefersTo
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s refers to %q, want %q", name, got[name], want)
+				}
+			}
+			if tt.namespaceSheet != "" {
+				if v, _ := f.GetCellValue(tt.namespaceSheet, "B4"); v != "2" {
+					t.Errorf("ClusterTotalCPU cell = %q, want the CLUSTER TOTAL request CPU", v)
+				}
+			}
+
+			props, err := f.GetCustomProps()
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := map[string]interface{}{}
+			for _, p := range props {
+				values[p.Name] = p.Value
+			}
+			if values["Cluster"] != "prod" || values[NameClusterTotalCPU] != 2.0 || values["Context"] != nil {
+				t.Errorf("custom properties = %v", values)
+			}
+			if doc, _ := f.GetDocProps(); doc.Title != "Pod Resource Report - prod" || doc.Creator != ReportGenerator {
+				t.Errorf("document properties = %+v", doc)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// YoungNamespaceAge is the age below which a namespace is flagged as possible
+// test or preview sprawl on the Namespaces sheet
+const YoungNamespaceAge = 48 * time.Hour
+
+// Lifecycle notes of the Namespaces sheet
+const (
+	NoteTerminatingWithResources = "Terminating but still holds resources"
+	NoteYoungNamespace           = "Created less than 2 days ago"
+)
+
+// Annotations and labels left on namespaces by the systems that create them
+const (
+	HNCSubnamespaceAnnotation = "hnc.x-k8s.io/subnamespace-of"
+	RancherProjectAnnotation  = "field.cattle.io/projectId" // "<cluster>:<project>"
+	RancherCreatorAnnotation  = "field.cattle.io/creatorId"
+	HelmReleaseNameAnnotation = "meta.helm.sh/release-name"
+	HelmReleaseNSAnnotation   = "meta.helm.sh/release-namespace"
+	ManagedByLabel            = "app.kubernetes.io/managed-by"
+)
+
+// namespaceLifecycle is the phase, creation time and creation source of a namespace
+type namespaceLifecycle struct {
+	phase     corev1.NamespacePhase
+	created   time.Time
+	createdBy string // Provisioning system, empty when unknown
+}
+
+// namespaceLifecycles indexes namespace phase and creation time by name; nil
+// when the namespaces could not be listed
+func namespaceLifecycles(namespaces *corev1.NamespaceList) map[string]namespaceLifecycle {
+	if namespaces == nil {
+		return nil
+	}
+	lifecycles := make(map[string]namespaceLifecycle, len(namespaces.Items))
+	for _, ns := range namespaces.Items {
+		phase := ns.Status.Phase
+		if ns.DeletionTimestamp != nil {
+			phase = corev1.NamespaceTerminating
+		}
+		lifecycles[ns.Name] = namespaceLifecycle{phase: phase, created: ns.CreationTimestamp.Time, createdBy: namespaceSource(ns.Labels, ns.Annotations)}
+	}
+	return lifecycles
+}
+
+// namespaceSource names the system that provisioned a namespace from the marks
+// of the hierarchical namespace controller, Rancher, Argo CD, Flux and Helm,
+// else the app.kubernetes.io/managed-by label. Empty for namespaces created by
+// hand or by a system that leaves no mark.
+func namespaceSource(labels, annotations map[string]string) string {
+	if parent := annotations[HNCSubnamespaceAnnotation]; parent != "" {
+		return "HNC subnamespace of " + parent
+	}
+	if id := annotations[RancherProjectAnnotation]; id != "" {
+		cluster, project, ok := strings.Cut(id, ":")
+		source := "Rancher project " + project
+		if !ok {
+			source = "Rancher project " + cluster
+		}
+		if creator := annotations[RancherCreatorAnnotation]; creator != "" {
+			source += " (creator " + creator + ")"
+		}
+		return source
+	}
+	if owner, ok := gitopsOwnerFromMetadata(labels, annotations); ok {
+		return owner.tool + " " + owner.app
+	}
+	if release := annotations[HelmReleaseNameAnnotation]; release != "" {
+		return "Helm release " + annotations[HelmReleaseNSAnnotation] + "/" + release
+	}
+	return labels[ManagedByLabel]
+}
+
+// ageDays returns the namespace age in days at now
+func (l namespaceLifecycle) ageDays(now time.Time) float64 {
+	return now.Sub(l.created).Hours() / 24
+}
+
+// note flags a Terminating namespace that still has requests or limits and a
+// namespace younger than YoungNamespaceAge
+func (l namespaceLifecycle) note(totals namespaceTotal, now time.Time) string {
+	holdsResources := totals.reqCPU > 0 || totals.reqMem > 0 || totals.limCPU > 0 || totals.limMem > 0
+	if l.phase == corev1.NamespaceTerminating && holdsResources {
+		return NoteTerminatingWithResources
+	}
+	if !l.created.IsZero() && now.Sub(l.created) < YoungNamespaceAge {
+		return NoteYoungNamespace
+	}
+	return ""
+}
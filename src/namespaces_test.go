@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceLifecycles(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	deleted := metav1.NewTime(now)
+	namespaces := &corev1.NamespaceList{Items: []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "shop", CreationTimestamp: metav1.NewTime(now.AddDate(0, 0, -30)), Annotations: map[string]string{HNCSubnamespaceAnnotation: "retail"}}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "old", CreationTimestamp: metav1.NewTime(now.AddDate(0, 0, -90)), DeletionTimestamp: &deleted}, Status: corev1.NamespaceStatus{Phase: corev1.NamespaceActive}},
+	}}
+
+	lifecycles := namespaceLifecycles(namespaces)
+	if got := lifecycles["shop"]; got.phase != corev1.NamespaceActive || got.ageDays(now) != 30 || got.createdBy != "HNC subnamespace of retail" {
+		t.Errorf("shop = %+v, age %v, want Active, 30 days and created by HNC", got, got.ageDays(now))
+	}
+	if got := lifecycles["old"].phase; got != corev1.NamespaceTerminating {
+		t.Errorf("old phase = %s, want Terminating from the deletion timestamp", got)
+	}
+	if namespaceLifecycles(nil) != nil {
+		t.Error("namespaceLifecycles(nil) should be nil")
+	}
+}
+
+func TestNamespaceLifecycleNote(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	holding := namespaceTotal{reqCPU: 100}
+	tests := []struct {
+		name      string
+		lifecycle namespaceLifecycle
+		totals    namespaceTotal
+		want      string
+	}{
+		{"active", namespaceLifecycle{phase: corev1.NamespaceActive, created: now.AddDate(0, 0, -10)}, holding, ""},
+		{"terminating with resources", namespaceLifecycle{phase: corev1.NamespaceTerminating, created: now.AddDate(0, 0, -10)}, holding, NoteTerminatingWithResources},
+		{"terminating and empty", namespaceLifecycle{phase: corev1.NamespaceTerminating, created: now.AddDate(0, 0, -10)}, namespaceTotal{}, ""},
+		{"young", namespaceLifecycle{phase: corev1.NamespaceActive, created: now.Add(-3 * time.Hour)}, holding, NoteYoungNamespace},
+		{"just old enough", namespaceLifecycle{phase: corev1.NamespaceActive, created: now.Add(-YoungNamespaceAge)}, holding, ""},
+		{"young and terminating", namespaceLifecycle{phase: corev1.NamespaceTerminating, created: now.Add(-time.Hour)}, holding, NoteTerminatingWithResources},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.lifecycle.note(tt.totals, now); got != tt.want {
+				t.Errorf("note() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNamespaceSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		annotations map[string]string
+		want        string
+	}{
+		{"unknown", nil, nil, ""},
+		{"hnc", nil, map[string]string{HNCSubnamespaceAnnotation: "team-a"}, "HNC subnamespace of team-a"},
+		{"rancher", nil, map[string]string{RancherProjectAnnotation: "c-m8x2k:p-7fz4q", RancherCreatorAnnotation: "u-abc12"}, "Rancher project p-7fz4q (creator u-abc12)"},
+		{"rancher without cluster", nil, map[string]string{RancherProjectAnnotation: "p-7fz4q"}, "Rancher project p-7fz4q"},
+		{"argo cd", nil, map[string]string{ArgoTrackingIDAnnotation: "platform:/Namespace:/shop"}, "Argo CD platform"},
+		{"flux", map[string]string{FluxKustomizationName: "tenants", FluxKustomizationNS: "flux-system"}, nil, "Flux Kustomization flux-system/tenants"},
+		{"helm", nil, map[string]string{HelmReleaseNameAnnotation: "shop", HelmReleaseNSAnnotation: "apps"}, "Helm release apps/shop"},
+		{"managed-by", map[string]string{ManagedByLabel: "crossplane"}, nil, "crossplane"},
+		{"hnc before managed-by", map[string]string{ManagedByLabel: "Helm"}, map[string]string{HNCSubnamespaceAnnotation: "team-a"}, "HNC subnamespace of team-a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := namespaceSource(tt.labels, tt.annotations); got != tt.want {
+				t.Errorf("namespaceSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
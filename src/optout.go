@@ -0,0 +1,56 @@
+package main
+
+// dropExcluded drops the rollout changes, HPA states and the other namespaced
+// collections of the namespaces the collector excluded by the opt-out
+// annotation; their pods are already gone. The excluded namespaces are kept
+// for partial refreshes.
+func dropExcluded(snap *clusterSnapshot) {
+	if len(snap.excluded) == 0 {
+		return
+	}
+
+	var changes []resourceChange
+	for _, c := range snap.resourceChanges {
+		if !snap.excluded[c.workload.namespace] {
+			changes = append(changes, c)
+		}
+	}
+	snap.resourceChanges = changes
+	var scaling []hpaScaling
+	for _, s := range snap.hpaScaling {
+		if !snap.excluded[s.workload.namespace] {
+			scaling = append(scaling, s)
+		}
+	}
+	snap.hpaScaling = scaling
+	var statefulSets []statefulSetFootprint
+	for _, fp := range snap.statefulSets {
+		if !snap.excluded[fp.workload.namespace] {
+			statefulSets = append(statefulSets, fp)
+		}
+	}
+	snap.statefulSets = statefulSets
+	var runs []jobRun
+	for _, r := range snap.jobRuns {
+		if !snap.excluded[r.job.namespace] {
+			runs = append(runs, r)
+		}
+	}
+	snap.jobRuns = runs
+	var cronJobs []cronJobSpec
+	for _, spec := range snap.cronJobs {
+		if !snap.excluded[spec.cronJob.namespace] {
+			cronJobs = append(cronJobs, spec)
+		}
+	}
+	snap.cronJobs = cronJobs
+	if snap.events != nil {
+		events := make([]warningEvents, 0, len(snap.events))
+		for _, e := range snap.events {
+			if !snap.excluded[e.namespace] {
+				events = append(events, e)
+			}
+		}
+		snap.events = events
+	}
+}
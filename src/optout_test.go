@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDropExcluded(t *testing.T) {
+	pod := func(namespace, name string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	snap := &clusterSnapshot{
+		pods:     []corev1.Pod{pod("shop", "web")},
+		excluded: map[string]bool{"preview-42": true},
+		resourceChanges: []resourceChange{
+			{workload: workloadKey{namespace: "preview-42", kind: "Deployment", name: "web"}},
+			{workload: workloadKey{namespace: "shop", kind: "Deployment", name: "web"}},
+		},
+		events: []warningEvents{{namespace: "preview-42"}},
+	}
+	dropExcluded(snap)
+	if len(snap.resourceChanges) != 1 || snap.resourceChanges[0].workload.namespace != "shop" || len(snap.events) != 0 {
+		t.Errorf("changes = %+v, events = %+v", snap.resourceChanges, snap.events)
+	}
+
+	// A partial refresh keeps opted out namespaces out
+	refreshed := snap.withNamespacePods("preview-42", []corev1.Pod{pod("preview-42", "web")}, "7", time.Now())
+	if len(refreshed.pods) != 1 || refreshed.pods[0].Namespace != "shop" {
+		t.Errorf("refreshed pods = %+v", refreshed.pods)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+)
+
+// subtotalColumns are the Resources sheet columns summed on subtotal rows
+var subtotalColumns = []string{"D", "F", "H", "J", "O", "Q", "S", "U"}
+
+// rowGroup is the set of Resources rows belonging to one namespace or pod
+type rowGroup struct {
+	namespace, pod string // pod is empty for namespace groups
+	rows           []resourceRow
+}
+
+// groupByPod collects rows per pod, keeping pods in order of their first row
+func groupByPod(rows []resourceRow) []rowGroup {
+	return groupRows(rows, func(r resourceRow) rowGroup {
+		return rowGroup{namespace: r.fields.Namespace, pod: r.fields.Pod}
+	})
+}
+
+// groupByNamespace collects rows per namespace, keeping namespaces in order of their first row
+func groupByNamespace(rows []resourceRow) []rowGroup {
+	return groupRows(rows, func(r resourceRow) rowGroup {
+		return rowGroup{namespace: r.fields.Namespace}
+	})
+}
+
+// groupRows collects rows into the groups returned by keyOf, in order of first appearance
+func groupRows(rows []resourceRow, keyOf func(resourceRow) rowGroup) []rowGroup {
+	var groups []rowGroup
+	index := make(map[string]int)
+	for _, r := range rows {
+		group := keyOf(r)
+		key := group.namespace + "/" + group.pod
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, group)
+		}
+		groups[i].rows = append(groups[i].rows, r)
+	}
+	return groups
+}
+
+// writePodSubtotal writes a bold pod summary row above its container rows (first..last)
+func writePodSubtotal(f *excelize.File, sheetName string, row, first, last int, g rowGroup) error {
+	data := []interface{}{g.namespace, g.pod, fmt.Sprintf("Pod total (%s)", pluralize(len(g.rows), "container"))}
+	if err := writeSubtotalRow(f, sheetName, row, first, last, data, fmt.Sprintf("pod '%s' subtotal", g.pod)); err != nil {
+		return err
+	}
+
+	lastCell := fmt.Sprintf("%s%d", subtotalColumns[len(subtotalColumns)-1], row)
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), lastCell, export.BoldStyle(f))
+	f.SetCellStyle(sheetName, fmt.Sprintf("F%d", row), fmt.Sprintf("F%d", row), export.BoldIntegerStyle(f))
+	f.SetCellStyle(sheetName, fmt.Sprintf("J%d", row), fmt.Sprintf("J%d", row), export.BoldIntegerStyle(f))
+	return nil
+}
+
+// writeNamespaceSubtotal writes a highlighted namespace summary row above its rows (first..last).
+// The namespace stays in column A so the row survives an auto filter on that namespace.
+func writeNamespaceSubtotal(f *excelize.File, sheetName string, row, first, last int, g rowGroup, t theme) error {
+	data := []interface{}{g.namespace, "", fmt.Sprintf("Namespace total (%s)", pluralize(len(g.rows), "container"))}
+	if err := writeSubtotalRow(f, sheetName, row, first, last, data, fmt.Sprintf("namespace '%s' subtotal", g.namespace)); err != nil {
+		return err
+	}
+
+	lastCell := fmt.Sprintf("%s%d", subtotalColumns[len(subtotalColumns)-1], row)
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), lastCell, getNamespaceSubtotalStyle(f, 0, t))
+	f.SetCellStyle(sheetName, fmt.Sprintf("F%d", row), fmt.Sprintf("F%d", row), getNamespaceSubtotalStyle(f, 1, t))
+	f.SetCellStyle(sheetName, fmt.Sprintf("J%d", row), fmt.Sprintf("J%d", row), getNamespaceSubtotalStyle(f, 1, t))
+	return nil
+}
+
+// writeSubtotalRow writes the label cells and SUBTOTAL formulas of a summary row.
+// SUBTOTAL(109) skips hidden rows and nested subtotals, so the sheet totals in row 1
+// and enclosing groups never count a container twice.
+func writeSubtotalRow(f *excelize.File, sheetName string, row, first, last int, data []interface{}, context string) error {
+	if err := export.SetRow(f, sheetName, row, data, context); err != nil {
+		return err
+	}
+
+	for _, col := range subtotalColumns {
+		cell := fmt.Sprintf("%s%d", col, row)
+		formula := fmt.Sprintf("SUBTOTAL(109,%s%d:%s%d)", col, first, col, last)
+		if err := f.SetCellFormula(sheetName, cell, formula); err != nil {
+			return fmt.Errorf("failed to set %s formula: %w", context, err)
+		}
+	}
+	return nil
+}
+
+// getNamespaceSubtotalStyle returns a bold, shaded style; numFmt 1 formats integers
+func getNamespaceSubtotalStyle(f *excelize.File, numFmt int, t theme) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true, Color: t.subtotal.font},
+		Fill:   excelize.Fill{Type: "pattern", Color: []string{t.subtotal.fill}, Pattern: 1},
+		NumFmt: numFmt,
+	})
+	return style
+}
+
+// pluralize formats a count with a singular or plural noun, e.g. "2 containers"
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
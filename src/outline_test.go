@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGroupByPod(t *testing.T) {
+	row := func(ns, pod, container string) resourceRow {
+		return resourceRow{fields: rowFields{Namespace: ns, Pod: pod, Container: container}}
+	}
+	rows := []resourceRow{
+		row("shop", "web-1", "app"),
+		row("shop", "api-1", "app"),
+		row("shop", "web-1", "sidecar"),
+		row("search", "web-1", "app"),
+		row("shop", "api-1", "sidecar"),
+	}
+
+	groups := groupByPod(rows)
+
+	var got [][]string
+	for _, g := range groups {
+		names := []string{g.namespace + "/" + g.pod}
+		for _, r := range g.rows {
+			names = append(names, r.fields.Container)
+		}
+		got = append(got, names)
+	}
+	want := [][]string{
+		{"shop/web-1", "app", "sidecar"},
+		{"shop/api-1", "app", "sidecar"},
+		{"search/web-1", "app"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groupByPod() = %v, want %v", got, want)
+	}
+
+	var namespaces []string
+	for _, g := range groupByNamespace(rows) {
+		namespaces = append(namespaces, fmt.Sprintf("%s:%d", g.namespace, len(g.rows)))
+	}
+	if want := []string{"shop:4", "search:1"}; !reflect.DeepEqual(namespaces, want) {
+		t.Errorf("groupByNamespace() = %v, want %v", namespaces, want)
+	}
+
+	if groups := groupByPod(nil); groups != nil {
+		t.Errorf("groupByPod(nil) = %v, want nil", groups)
+	}
+}
+
+func TestPluralize(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0 containers"},
+		{1, "1 container"},
+		{3, "3 containers"},
+	}
+	for _, tt := range tests {
+		if got := pluralize(tt.n, "container"); got != tt.want {
+			t.Errorf("pluralize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
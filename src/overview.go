@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+)
+
+// reportMetadata describes where and when a report was generated
+type reportMetadata struct {
+	cluster   clusterIdentity
+	namespace string    // Namespace filter, empty for all namespaces
+	pattern   string    // Namespace patterns of -namespace-pattern, empty without
+	group     string    // Split group of a per-group workbook, empty for the full report
+	generated time.Time // Generation time, now when zero
+}
+
+// scopeDisplay describes the namespaces of the report
+func (m reportMetadata) scopeDisplay() string {
+	if m.pattern != "" {
+		return "namespaces matching " + m.pattern
+	}
+	return getNamespaceDisplay(m.namespace)
+}
+
+// reportStats are the headline counts shown on the Overview sheet
+type reportStats struct {
+	pods, containers, namespaces, nodes int
+	pending                             int    // Pending pods, counted in pods only with PendingInclude
+	pendingMode                         string // -pending-pods mode
+}
+
+// createOverviewSheet writes the report metadata, headline counts and, when node
+// data is available, the request saturation per node pool
+func createOverviewSheet(f *excelize.File, meta reportMetadata, stats reportStats, saturation []poolSaturation, sheetName string, t theme) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create overview sheet: %w", err)
+	}
+
+	f.SetCellValue(sheetName, "A1", "Pod Resource Report")
+	f.SetCellStyle(sheetName, "A1", "A1", export.TitleStyle(f))
+
+	rows := [][]interface{}{
+		{"Cluster", valueOrDash(meta.cluster.name)},
+		{"Context", valueOrDash(meta.cluster.context)},
+		{"Namespace", meta.scopeDisplay()},
+	}
+	if meta.group != "" {
+		rows = append(rows, []interface{}{"Group", meta.group})
+	}
+	rows = append(rows,
+		[]interface{}{"Generated", meta.generated.Format(time.RFC3339)},
+		[]interface{}{"Time Zone", meta.generated.Location().String()},
+		[]interface{}{"Schema Version", SchemaVersion},
+		[]interface{}{"Pods", stats.pods},
+		[]interface{}{"Containers", stats.containers},
+		[]interface{}{"Namespaces", stats.namespaces},
+		[]interface{}{"Nodes", stats.nodes},
+	)
+	if stats.pending > 0 {
+		counted := "included in totals"
+		switch stats.pendingMode {
+		case PendingSeparate:
+			counted = "not in totals, see Pending Pods sheet"
+		case PendingExclude:
+			counted = "excluded from the report"
+		}
+		rows = append(rows, []interface{}{"Pending Pods", fmt.Sprintf("%d (%s)", stats.pending, counted)})
+	}
+
+	row := 3
+	for _, data := range rows {
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("overview '%s'", data[0])); err != nil {
+			return err
+		}
+		cell := fmt.Sprintf("A%d", row)
+		f.SetCellStyle(sheetName, cell, cell, export.BoldStyle(f))
+		row++
+	}
+
+	if len(saturation) > 0 {
+		if err := writeSaturationTable(f, sheetName, row+1, saturation, t); err != nil {
+			return err
+		}
+		f.SetColWidth(sheetName, "C", "H", 22)
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 20)
+	f.SetColWidth(sheetName, "B", "B", 50)
+
+	return nil
+}
+
+// valueOrDash returns s or "-" when s is empty
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
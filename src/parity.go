@@ -0,0 +1,257 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultParityMinPercent flags staging workloads requesting less than this
+// share of their production counterpart
+const DefaultParityMinPercent = 25
+
+// Parity findings of a workload
+const (
+	ParitySame        = "same spec as prod"
+	ParityBelow       = "below minimum share of prod"
+	ParityAbove       = "larger than prod"
+	ParityOnlyProd    = "only in prod"
+	ParityOnlyStaging = "only in staging"
+)
+
+// parityArgs are the flags of the parity subcommand
+type parityArgs struct {
+	prod, staging                   *string
+	prodNamespace, stagingNamespace *string
+	matchLabel                      *string
+	minPercent                      *int
+	divergentOnly                   *bool
+	verbose, quiet                  *bool
+}
+
+// parityFlags defines the flags of the parity subcommand
+func parityFlags() (*flag.FlagSet, *parityArgs) {
+	fs := flag.NewFlagSet("parity", flag.ExitOnError)
+	return fs, &parityArgs{
+		prod:             fs.String("prod", "", "Offline bundle of the reference environment"),
+		staging:          fs.String("staging", "", "Offline bundle of the compared environment (default: the -prod bundle, to compare two namespaces)"),
+		prodNamespace:    fs.String("prod-namespace", "", "Only match workloads of this namespace in the -prod bundle"),
+		stagingNamespace: fs.String("staging-namespace", "", "Only match workloads of this namespace in the -staging bundle"),
+		matchLabel:       fs.String("match-label", "", "Match workloads by the value of this pod label instead of kind and name (e.g. app.kubernetes.io/name)"),
+		minPercent:       fs.Int("min-percent", DefaultParityMinPercent, "Flag staging workloads requesting less than this percentage of prod per pod"),
+		divergentOnly:    fs.Bool("divergent-only", false, "Only list workloads with a finding"),
+		verbose:          fs.Bool("verbose", false, "Enable verbose logging"),
+		quiet:            fs.Bool("quiet", false, "Only log errors (logs always go to stderr)"),
+	}
+}
+
+// environmentWorkload are the pods of a workload in one environment with the
+// requests per pod and the most common resource spec
+type environmentWorkload struct {
+	pods           int
+	reqCPU, reqMem int64 // Per pod, averaged over the pods
+	limCPU, limMem int64 // Per pod, averaged over the pods
+	spec           string
+}
+
+// workloadParity pairs a workload of both environments; pods is zero on the
+// side the workload is missing
+type workloadParity struct {
+	name          string
+	prod, staging environmentWorkload
+}
+
+// shares returns the staging CPU and memory requests per pod as a share of
+// prod, nil unless the workload runs in both environments
+func (p workloadParity) shares() (cpu, mem interface{}) {
+	if p.prod.pods == 0 || p.staging.pods == 0 {
+		return nil, nil
+	}
+	return ratio(p.staging.reqCPU, p.prod.reqCPU), ratio(p.staging.reqMem, p.prod.reqMem)
+}
+
+// finding classifies the divergence of the staging spec from prod; an empty
+// finding means staging is scaled down within the expected range
+func (p workloadParity) finding(minPercent int) string {
+	switch {
+	case p.staging.pods == 0:
+		return ParityOnlyProd
+	case p.prod.pods == 0:
+		return ParityOnlyStaging
+	case p.staging.spec == p.prod.spec:
+		return ParitySame
+	case p.staging.reqCPU > p.prod.reqCPU || p.staging.reqMem > p.prod.reqMem:
+		return ParityAbove
+	}
+	minShare := float64(minPercent) / 100
+	for _, r := range []struct{ staging, prod int64 }{{p.staging.reqCPU, p.prod.reqCPU}, {p.staging.reqMem, p.prod.reqMem}} {
+		if r.prod > 0 && float64(r.staging) < minShare*float64(r.prod) {
+			return ParityBelow
+		}
+	}
+	return ""
+}
+
+// environmentWorkloads groups the active pods of namespace (all namespaces
+// when empty) by match key: the value of matchLabel, else the workload kind
+// and name, prefixed by the namespace when withNamespace is set. Bare pods and
+// pods without the label cannot be matched and are skipped.
+func environmentWorkloads(pods []corev1.Pod, namespace, matchLabel string, withNamespace bool) map[string]environmentWorkload {
+	type group struct {
+		pods                           int
+		reqCPU, reqMem, limCPU, limMem int64
+		specs                          map[string]int
+	}
+	groups := make(map[string]*group)
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) || (namespace != "" && pod.Namespace != namespace) {
+			continue
+		}
+		var key string
+		if matchLabel != "" {
+			key = pod.Labels[matchLabel]
+		} else if w := workloadOf(pod); w.kind != "Pod" {
+			key = w.kind + "/" + w.name
+		}
+		if key == "" {
+			continue
+		}
+		if withNamespace {
+			key = pod.Namespace + "/" + key
+		}
+		g := groups[key]
+		if g == nil {
+			g = &group{specs: make(map[string]int)}
+			groups[key] = g
+		}
+		g.pods++
+		for _, c := range podContainers(pod) {
+			g.reqCPU += quantityMilli(c.Resources.Requests.Cpu())
+			g.reqMem += quantityBytes(c.Resources.Requests.Memory())
+			g.limCPU += quantityMilli(c.Resources.Limits.Cpu())
+			g.limMem += quantityBytes(c.Resources.Limits.Memory())
+		}
+		g.specs[podResourceSpec(pod)]++
+	}
+
+	result := make(map[string]environmentWorkload, len(groups))
+	for key, g := range groups {
+		w := environmentWorkload{
+			pods:   g.pods,
+			reqCPU: g.reqCPU / int64(g.pods),
+			reqMem: g.reqMem / int64(g.pods),
+			limCPU: g.limCPU / int64(g.pods),
+			limMem: g.limMem / int64(g.pods),
+		}
+		best := 0
+		for spec, n := range g.specs {
+			if n > best || (n == best && spec < w.spec) {
+				w.spec, best = spec, n
+			}
+		}
+		result[key] = w
+	}
+	return result
+}
+
+// compareEnvironments pairs the workloads of both environments, sorted by
+// match key
+func compareEnvironments(prod, staging map[string]environmentWorkload) []workloadParity {
+	names := make(map[string]bool, len(prod)+len(staging))
+	for name := range prod {
+		names[name] = true
+	}
+	for name := range staging {
+		names[name] = true
+	}
+	result := make([]workloadParity, 0, len(names))
+	for name := range names {
+		result = append(result, workloadParity{name: name, prod: prod[name], staging: staging[name]})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+// printParity writes the per-pod requests of each workload in both
+// environments, the staging share of prod and the finding as an aligned table
+func printParity(w io.Writer, parity []workloadParity, minPercent int, divergentOnly bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Workload\tProd Pods\tStaging Pods\tProd CPU (m/pod)\tStaging CPU (m/pod)\tCPU Share\tProd Memory (Mi/pod)\tStaging Memory (Mi/pod)\tMemory Share\tFinding\t")
+	share := func(v interface{}) string {
+		if f, ok := v.(float64); ok {
+			return fmt.Sprintf("%.0f%%", f*100)
+		}
+		return "-"
+	}
+	counts := make(map[string]int)
+	for _, p := range parity {
+		finding := p.finding(minPercent)
+		counts[finding]++
+		if divergentOnly && finding == "" {
+			continue
+		}
+		cpuShare, memShare := p.shares()
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%d\t%d\t%s\t%s\t\n", p.name,
+			p.prod.pods, p.staging.pods,
+			p.prod.reqCPU, p.staging.reqCPU, share(cpuShare),
+			bytesToWholeMi(p.prod.reqMem), bytesToWholeMi(p.staging.reqMem), share(memShare),
+			valueOrDash(finding))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%s compared: %d same spec as prod, %d below %d%% of prod, %d larger than prod, %d only in prod, %d only in staging\n",
+		pluralize(len(parity), "workload"), counts[ParitySame], counts[ParityBelow], minPercent, counts[ParityAbove], counts[ParityOnlyProd], counts[ParityOnlyStaging])
+	return nil
+}
+
+// runParity implements the parity subcommand: it matches the workloads of two
+// environments and prints where the staging resource specs diverge from prod
+func runParity(args []string) error {
+	fs, a := parityFlags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := configureLogging(*a.verbose, *a.quiet); err != nil {
+		return err
+	}
+	if *a.prod == "" {
+		return fmt.Errorf("-prod is required")
+	}
+	if *a.minPercent < 0 || *a.minPercent > 100 {
+		return fmt.Errorf("-min-percent must be between 0 and 100")
+	}
+	stagingPath := *a.staging
+	if stagingPath == "" {
+		if *a.prodNamespace == "" || *a.stagingNamespace == "" || *a.prodNamespace == *a.stagingNamespace {
+			return fmt.Errorf("without -staging, -prod-namespace and -staging-namespace must name two namespaces")
+		}
+		stagingPath = *a.prod
+	}
+
+	prod, err := readBundle(*a.prod)
+	if err != nil {
+		return err
+	}
+	staging := prod
+	if stagingPath != *a.prod {
+		if staging, err = readBundle(stagingPath); err != nil {
+			return err
+		}
+	}
+
+	// Namespaces only take part in the match when both sides span all of them
+	withNamespace := *a.prodNamespace == "" && *a.stagingNamespace == ""
+	parity := compareEnvironments(
+		environmentWorkloads(prod.Pods, *a.prodNamespace, *a.matchLabel, withNamespace),
+		environmentWorkloads(staging.Pods, *a.stagingNamespace, *a.matchLabel, withNamespace),
+	)
+	return printParity(stdout, parity, *a.minPercent, *a.divergentOnly)
+}
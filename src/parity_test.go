@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEnvironmentParity(t *testing.T) {
+	controller := true
+	pod := func(namespace, deployment, app, cpu, mem string) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      deployment + "-abc-x",
+				Labels:    map[string]string{"pod-template-hash": "abc", "app": app},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ReplicaSet", Name: deployment + "-abc", Controller: &controller},
+				},
+			},
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{testSidecarContainer("app", cpu, mem)}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("shop", "web", "web", "1", "2Gi"),
+		pod("shop", "web", "web", "1", "2Gi"),
+		pod("shop", "api", "api", "2", "4Gi"),
+		pod("shop", "search", "search", "4", "8Gi"),
+		pod("shop", "cart", "cart", "1", "1Gi"),
+		pod("shop", "legacy", "legacy", "1", "1Gi"),
+		pod("shop-staging", "web", "web", "500m", "1Gi"),
+		pod("shop-staging", "api", "api", "2", "4Gi"),
+		pod("shop-staging", "search", "search", "200m", "512Mi"),
+		pod("shop-staging", "cart", "cart", "2", "1Gi"),
+		pod("shop-staging", "debug", "debug", "100m", "128Mi"),
+	}
+
+	prod := environmentWorkloads(pods, "shop", "", false)
+	staging := environmentWorkloads(pods, "shop-staging", "", false)
+	if w := prod["Deployment/web"]; w.pods != 2 || w.reqCPU != 1000 || w.reqMem != 2<<30 {
+		t.Errorf("prod web = %+v, want 2 pods at 1 core and 2Gi per pod", w)
+	}
+
+	want := map[string]string{
+		"Deployment/web":    "",
+		"Deployment/api":    ParitySame,
+		"Deployment/search": ParityBelow,
+		"Deployment/cart":   ParityAbove,
+		"Deployment/legacy": ParityOnlyProd,
+		"Deployment/debug":  ParityOnlyStaging,
+	}
+	parity := compareEnvironments(prod, staging)
+	if len(parity) != len(want) {
+		t.Fatalf("compareEnvironments() returned %d workloads, want %d", len(parity), len(want))
+	}
+	for _, p := range parity {
+		if got := p.finding(DefaultParityMinPercent); got != want[p.name] {
+			t.Errorf("%s finding = %q, want %q", p.name, got, want[p.name])
+		}
+	}
+
+	// Matching by label across all namespaces keeps the namespace in the key
+	byLabel := environmentWorkloads(pods, "", "app", true)
+	if _, ok := byLabel["shop-staging/web"]; !ok || len(byLabel) != 10 {
+		t.Errorf("byLabel = %v", byLabel)
+	}
+
+	var buf bytes.Buffer
+	if err := printParity(&buf, parity, DefaultParityMinPercent, true); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "Deployment/web ") || !strings.Contains(out, "5%") || !strings.Contains(out, "6 workloads compared: 1 same spec as prod") {
+		t.Errorf("parity output:\n%s", out)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Pending pod modes of -pending-pods
+const (
+	PendingInclude  = "include"  // Count Pending pods like Running pods
+	PendingSeparate = "separate" // Keep them out of the totals, list them on the Pending Pods sheet
+	PendingExclude  = "exclude"  // Leave them out of the workbook
+)
+
+// parsePendingMode validates -pending-pods; empty selects include
+func parsePendingMode(s string) (string, error) {
+	switch s {
+	case "", PendingInclude:
+		return PendingInclude, nil
+	case PendingSeparate, PendingExclude:
+		return s, nil
+	}
+	return "", fmt.Errorf("unknown pending pod mode '%s' (valid: %s, %s, %s)", s, PendingInclude, PendingSeparate, PendingExclude)
+}
+
+// separatePending holds the Pending pods of a snapshot back from its pods with
+// -pending-pods separate or exclude, so every writer (workbooks, BI and JSON
+// exports, findings, the REST API) sees the same pods. They stay in
+// snap.pending for the Pending Pods sheet and the overview count.
+func separatePending(snap *clusterSnapshot, mode string) {
+	if mode == "" || mode == PendingInclude {
+		return
+	}
+	var pending []corev1.Pod
+	snap.pods, pending = splitPendingPods(snap.pods)
+	snap.pending = append(snap.pending, pending...)
+	snap.pendingMode = mode
+}
+
+// splitPendingPods returns the pods that are not Pending and the Pending pods;
+// pods of every other phase stay in the first list
+func splitPendingPods(pods []corev1.Pod) (others, pending []corev1.Pod) {
+	others = make([]corev1.Pod, 0, len(pods))
+	for i := range pods {
+		if pods[i].Status.Phase == corev1.PodPending {
+			pending = append(pending, pods[i])
+			continue
+		}
+		others = append(others, pods[i])
+	}
+	return others, pending
+}
+
+// pendingPod is a Pending pod with the reason it has not started
+type pendingPod struct {
+	namespace, pod string
+	workload       string // namespace/kind/name
+	node           string // Empty until scheduled
+	age            time.Duration
+	reqCPU, reqMem int64
+	reason         string
+}
+
+// unschedulable reports whether the scheduler found no node for the pod
+func (p pendingPod) unschedulable() bool {
+	return p.node == "" && strings.HasPrefix(p.reason, corev1.PodReasonUnschedulable)
+}
+
+// pendingReason returns why a pod is Pending: the scheduler's message while it
+// is unscheduled, else the first waiting container reason such as
+// ContainerCreating or ImagePullBackOff
+func pendingReason(pod *corev1.Pod) string {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+			if cond.Message == "" {
+				return cond.Reason
+			}
+			return cond.Reason + ": " + cond.Message
+		}
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			return cs.Name + ": " + cs.State.Waiting.Reason
+		}
+	}
+	return ""
+}
+
+// pendingPodList describes the Pending pods, longest pending first
+func pendingPodList(pods []corev1.Pod, now time.Time) []pendingPod {
+	result := make([]pendingPod, 0, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != corev1.PodPending {
+			continue
+		}
+		cpu, mem := podRequests(pod)
+		result = append(result, pendingPod{
+			namespace: pod.Namespace,
+			pod:       pod.Name,
+			workload:  workloadOf(pod).String(),
+			node:      pod.Spec.NodeName,
+			age:       now.Sub(pod.CreationTimestamp.Time),
+			reqCPU:    cpu,
+			reqMem:    mem,
+			reason:    pendingReason(pod),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].age != result[j].age {
+			return result[i].age > result[j].age
+		}
+		return result[i].namespace+"/"+result[i].pod < result[j].namespace+"/"+result[j].pod
+	})
+	return result
+}
+
+// pendingNamespace sums the Pending pods of a namespace
+type pendingNamespace struct {
+	namespace      string
+	pods           int
+	reqCPU, reqMem int64
+	oldest         time.Duration
+}
+
+// pendingByNamespace sums Pending pods per namespace, sorted by name
+func pendingByNamespace(pending []pendingPod) []pendingNamespace {
+	index := make(map[string]*pendingNamespace)
+	for _, p := range pending {
+		ns := index[p.namespace]
+		if ns == nil {
+			ns = &pendingNamespace{namespace: p.namespace}
+			index[p.namespace] = ns
+		}
+		ns.pods++
+		ns.reqCPU += p.reqCPU
+		ns.reqMem += p.reqMem
+		if p.age > ns.oldest {
+			ns.oldest = p.age
+		}
+	}
+	result := make([]pendingNamespace, 0, len(index))
+	for _, ns := range index {
+		result = append(result, *ns)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].namespace < result[j].namespace })
+	return result
+}
+
+// hoursPending rounds a pending duration to tenths of an hour
+func hoursPending(d time.Duration) float64 {
+	return math.Round(d.Hours()*10) / 10
+}
+
+// createPendingSheet summarizes the Pending pods per namespace and lists each
+// pod with its reason; unschedulable pods are marked. The note in A1 tells
+// whether the other sheets count them.
+func createPendingSheet(f *excelize.File, pending []pendingPod, mode, sheetName string, t theme) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create pending sheet: %w", err)
+	}
+
+	note := "Pending pods are counted in the totals and percentages of the other sheets (-pending-pods include)"
+	if mode == PendingSeparate {
+		note = "Pending pods are not counted in the totals and percentages of the other sheets (-pending-pods separate)"
+	}
+	f.SetCellValue(sheetName, "A1", note)
+
+	colors := t.efficiency[0]
+	markStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: colors.font},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{colors.fill}, Pattern: 1},
+	})
+	decimalStyle := export.DecimalStyle(f, false)
+
+	summaryHeaders := []interface{}{"Namespace", "Pending Pods", "Request CPU (cores)", "Request Memory (Gi)", "Longest Pending (hours)"}
+	if err := export.SetRow(f, sheetName, 3, summaryHeaders, "pending summary headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, "A3", "E3", export.BoldStyle(f))
+	row := 4
+	var total pendingNamespace
+	for _, ns := range pendingByNamespace(pending) {
+		data := []interface{}{ns.namespace, ns.pods, milliToCores(ns.reqCPU), bytesToGi(ns.reqMem), hoursPending(ns.oldest)}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("pending namespace '%s'", ns.namespace)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("E%d", row), decimalStyle)
+		total.pods += ns.pods
+		total.reqCPU += ns.reqCPU
+		total.reqMem += ns.reqMem
+		if ns.oldest > total.oldest {
+			total.oldest = ns.oldest
+		}
+		row++
+	}
+	data := []interface{}{"Total", total.pods, milliToCores(total.reqCPU), bytesToGi(total.reqMem), hoursPending(total.oldest)}
+	if err := export.SetRow(f, sheetName, row, data, "pending total"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), export.BoldStyle(f))
+	f.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("E%d", row), export.DecimalStyle(f, true))
+
+	row += 2
+	headers := []interface{}{"Namespace", "Pod", "Workload", "Node", "Pending (hours)", "Request CPU (cores)", "Request Memory (Gi)", "Reason"}
+	if err := export.SetRow(f, sheetName, row, headers, "pending pod headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("H%d", row), export.BoldStyle(f))
+	row++
+	for _, p := range pending {
+		data := []interface{}{p.namespace, p.pod, p.workload, valueOrDash(p.node), hoursPending(p.age), milliToCores(p.reqCPU), bytesToGi(p.reqMem), valueOrDash(p.reason)}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("pending pod '%s'", p.pod)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("E%d", row), fmt.Sprintf("G%d", row), decimalStyle)
+		if p.unschedulable() {
+			f.SetCellStyle(sheetName, fmt.Sprintf("H%d", row), fmt.Sprintf("H%d", row), markStyle)
+		}
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "B", 30)
+	f.SetColWidth(sheetName, "C", "C", 40)
+	f.SetColWidth(sheetName, "D", "G", 20)
+	f.SetColWidth(sheetName, "H", "H", 80)
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPendingPodList(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	snap := testAPISnapshot(now)
+	pending := func(name string, age time.Duration, status corev1.PodStatus) corev1.Pod {
+		pod := snap.pods[0]
+		pod.Name = name
+		pod.CreationTimestamp = metav1.NewTime(now.Add(-age))
+		pod.Spec.NodeName = ""
+		status.Phase = corev1.PodPending
+		pod.Status = status
+		return pod
+	}
+	unschedulable := pending("web-3", 30*time.Hour, corev1.PodStatus{Conditions: []corev1.PodCondition{{
+		Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable,
+		Message: "0/2 nodes are available: 2 Insufficient cpu.",
+	}}})
+	pulling := pending("web-4", 90*time.Minute, corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+		Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}},
+	}}})
+	pulling.Spec.NodeName = "n1"
+	pods := append(snap.pods, pulling, unschedulable)
+
+	others, pendingPods := splitPendingPods(pods)
+	if len(others) != 3 || len(pendingPods) != 2 {
+		t.Fatalf("splitPendingPods() = %d and %d pods, want 3 and 2", len(others), len(pendingPods))
+	}
+
+	got := pendingPodList(pods, now)
+	if len(got) != 2 || got[0].pod != "web-3" || got[1].pod != "web-4" {
+		t.Fatalf("pendingPodList() = %+v, want web-3 then web-4", got)
+	}
+	if got[0].reason != "Unschedulable: 0/2 nodes are available: 2 Insufficient cpu." || !got[0].unschedulable() {
+		t.Errorf("web-3 reason = %q, unschedulable = %v", got[0].reason, got[0].unschedulable())
+	}
+	if got[1].reason != "app: ImagePullBackOff" || got[1].unschedulable() || got[1].reqCPU != 500 {
+		t.Errorf("web-4 = %+v", got[1])
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createPendingSheet(f, got, PendingSeparate, "Pending Pods", themes[DefaultTheme]); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A4": "shop", "B4": "2", "A5": "Total", "E5": "30.00", "B8": "web-3", "D8": "-", "D9": "n1"} {
+		if got, _ := f.GetCellValue("Pending Pods", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestParsePendingMode(t *testing.T) {
+	for in, want := range map[string]string{"": PendingInclude, "include": PendingInclude, "separate": PendingSeparate, "exclude": PendingExclude} {
+		if got, err := parsePendingMode(in); err != nil || got != want {
+			t.Errorf("parsePendingMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := parsePendingMode("ignore"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+func TestSeparatePending(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	rules, _ := parseValidationRules(validationSpec{})
+	withPending := func() *clusterSnapshot {
+		snap := testAPISnapshot(now)
+		pod := snap.pods[0]
+		pod.Name = "web-3"
+		pod.Spec.NodeName = ""
+		pod.Status = corev1.PodStatus{Phase: corev1.PodPending}
+		snap.pods = append(snap.pods, pod)
+		return snap
+	}
+	shopCPU := func(snap *clusterSnapshot) int64 {
+		for _, ns := range buildAPIViews(snap, rules, reportMetadata{}).namespaces {
+			if ns.Name == "shop" {
+				return ns.RequestCPUMillicores
+			}
+		}
+		return 0
+	}
+
+	tests := []struct {
+		mode        string
+		pods        int
+		pending     int
+		wantShopCPU int64
+	}{
+		{PendingInclude, 4, 0, 1500},
+		{PendingSeparate, 3, 1, 1000},
+		{PendingExclude, 3, 1, 1000},
+	}
+	for _, tt := range tests {
+		snap := withPending()
+		separatePending(snap, tt.mode)
+		if len(snap.pods) != tt.pods || len(snap.pending) != tt.pending {
+			t.Errorf("%s: %d pods and %d pending, want %d and %d", tt.mode, len(snap.pods), len(snap.pending), tt.pods, tt.pending)
+		}
+		// Every writer reads the same pods, the REST API here
+		if got := shopCPU(snap); got != tt.wantShopCPU {
+			t.Errorf("%s: shop requests %dm, want %dm", tt.mode, got, tt.wantShopCPU)
+		}
+	}
+
+	// A partial refresh separates the new pods of the namespace the same way
+	snap := withPending()
+	separatePending(snap, PendingSeparate)
+	pending := snap.pending[0]
+	pending.Name = "web-4"
+	refreshed := snap.withNamespacePods("shop", []corev1.Pod{snap.pods[0], pending}, "140", now)
+	if len(refreshed.pods) != 2 || len(refreshed.pending) != 1 || refreshed.pending[0].Name != "web-4" {
+		t.Errorf("refreshed = %d pods and pending %v, want 2 pods and web-4", len(refreshed.pods), refreshed.pending)
+	}
+	if len(snap.pending) != 1 || snap.pending[0].Name != "web-3" {
+		t.Error("withNamespacePods modified the pending pods of the cached snapshot")
+	}
+}
@@ -0,0 +1,115 @@
+// Package collector lists the pods, nodes and namespaces of a cluster and
+// builds their resource report, for Go programs that embed the calculator:
+//
+//	rep, err := collector.Collect(ctx, clientSet, collector.Options{Namespace: "shop"})
+//	if err != nil {
+//		return err
+//	}
+//	for _, ns := range rep.Namespaces {
+//		fmt.Println(ns.Name, ns.RequestCPUMillicores)
+//	}
+//
+// List returns the listed objects instead of the report; the binary collects
+// every snapshot through it.
+package collector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultChunkSize is the page size of consistent pod lists
+const DefaultChunkSize = 500
+
+// Options select what Collect lists
+type Options struct {
+	// Namespace limits the pods to one namespace, empty for all namespaces
+	Namespace string
+	// ChunkSize is the page size of pod lists without a Lister, 0 for
+	// DefaultChunkSize
+	ChunkSize int64
+	// Lister lists the pods, nil for consistent lists in pages of ChunkSize
+	Lister *PodLister
+	// MetadataCache keeps the node and namespace lists between collections,
+	// nil lists them every time
+	MetadataCache *MetadataCache
+	// ExcludeAnnotation drops the pods and namespaces with this annotation set
+	// to "true", empty reports them
+	ExcludeAnnotation string
+	// Report enriches the report, e.g. with the cluster name and t-shirt sizes
+	Report report.Options
+	// Now returns the report timestamp, nil for time.Now
+	Now func() time.Time
+}
+
+// Snapshot are the objects of one collection
+type Snapshot struct {
+	Collected       time.Time
+	Pods            []corev1.Pod
+	ResourceVersion string                // Of the pod list
+	Namespaces      *corev1.NamespaceList // nil when namespaces could not be listed
+	Nodes           *corev1.NodeList      // nil when nodes could not be listed
+	Excluded        map[string]bool       // Namespaces opted out by ExcludeAnnotation, their pods are dropped
+	Warnings        []string              // Data that could not be collected
+}
+
+// Collect lists the pods of the cluster and builds their report. Pods must be
+// listable; nodes and namespaces are optional and a failure to list them is
+// recorded in Report.Warnings, leaving the node capacity and namespace phases
+// empty.
+func Collect(ctx context.Context, client kubernetes.Interface, opts Options) (*report.Report, error) {
+	snap, err := List(ctx, client, opts)
+	if err != nil {
+		return nil, err
+	}
+	r := report.Build(snap.Collected, snap.Pods, snap.Namespaces, snap.Nodes, opts.Report)
+	r.Warnings = snap.Warnings
+	return r, nil
+}
+
+// List lists the pods, namespaces and nodes of the cluster without the opted
+// out ones. As with Collect, only a failure to list the pods is an error.
+func List(ctx context.Context, client kubernetes.Interface, opts Options) (*Snapshot, error) {
+	now := time.Now
+	if opts.Now != nil {
+		now = opts.Now
+	}
+	lister := opts.Lister
+	if lister == nil {
+		chunkSize := opts.ChunkSize
+		if chunkSize <= 0 {
+			chunkSize = DefaultChunkSize
+		}
+		lister = NewPodLister(ListModeConsistent, chunkSize)
+	}
+	snap := &Snapshot{Collected: now()}
+
+	pods, err := lister.List(ctx, client, opts.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	snap.Pods, snap.ResourceVersion = pods.Items, pods.ResourceVersion
+
+	if opts.MetadataCache != nil {
+		// Nodes and namespaces from the cache, updated with the changes since
+		snap.Nodes, snap.Namespaces, snap.Warnings = opts.MetadataCache.load(ctx, client, snap.Collected)
+	} else {
+		if snap.Namespaces, err = client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err != nil {
+			snap.Warnings = append(snap.Warnings, fmt.Sprintf("failed to list namespaces: %v", err))
+			snap.Namespaces = nil
+		}
+		if snap.Nodes, err = client.CoreV1().Nodes().List(ctx, metav1.ListOptions{}); err != nil {
+			snap.Warnings = append(snap.Warnings, fmt.Sprintf("failed to list nodes: %v", err))
+			snap.Nodes = nil
+		}
+	}
+
+	excludeOptedOut(snap, opts.ExcludeAnnotation)
+	return snap, nil
+}
@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// testAPIServer serves two pages of pods in namespace shop, one node and a
+// forbidden namespace list
+func testAPIServer(t *testing.T, podStatus int) kubernetes.Interface {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/namespaces/shop/pods" && podStatus != http.StatusOK:
+			w.WriteHeader(podStatus)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+		case r.URL.Path == "/api/v1/namespaces/shop/pods" && r.URL.Query().Get("continue") == "":
+			_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{"continue":"next"},"items":[
+				{"metadata":{"namespace":"shop","name":"web-1"},"spec":{"nodeName":"node-a","containers":[
+					{"name":"app","resources":{"requests":{"cpu":"500m","memory":"1Gi"},"limits":{"cpu":"1","memory":"1Gi"}}}]},
+				 "status":{"phase":"Running","hostIP":"10.0.0.1"}}]}`))
+		case r.URL.Path == "/api/v1/namespaces/shop/pods":
+			_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[
+				{"metadata":{"namespace":"shop","name":"web-2"},"spec":{"nodeName":"node-a","containers":[
+					{"name":"app","resources":{"requests":{"cpu":"500m","memory":"1Gi"}}}]},
+				 "status":{"phase":"Running","hostIP":"10.0.0.1"}}]}`))
+		case r.URL.Path == "/api/v1/nodes":
+			_, _ = w.Write([]byte(`{"kind":"NodeList","apiVersion":"v1","metadata":{},"items":[
+				{"metadata":{"name":"node-a","labels":{"karpenter.sh/nodepool":"general"}},
+				 "status":{"allocatable":{"cpu":"4","memory":"8Gi"},"addresses":[{"type":"InternalIP","address":"10.0.0.1"}]}}]}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clientSet
+}
+
+func TestCollect(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	r, err := Collect(context.Background(), testAPIServer(t, http.StatusOK), Options{
+		Namespace: "shop",
+		ChunkSize: 1,
+		Now:       func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Generated.Equal(now) || len(r.Containers) != 2 {
+		t.Fatalf("generated, containers = %v, %d", r.Generated, len(r.Containers))
+	}
+	if len(r.Namespaces) != 1 || r.Namespaces[0].RequestCPUMillicores != 1000 || r.Namespaces[0].Phase != "" {
+		t.Errorf("namespaces = %+v", r.Namespaces)
+	}
+	if len(r.Nodes) != 1 || r.Nodes[0].Pool != "general" || r.Nodes[0].AllocatableCPUMillicores != 4000 {
+		t.Errorf("nodes = %+v", r.Nodes)
+	}
+	// Namespaces are forbidden: recorded, not fatal
+	if len(r.Warnings) != 1 || !strings.Contains(r.Warnings[0], "failed to list namespaces") {
+		t.Errorf("warnings = %q", r.Warnings)
+	}
+}
+
+func TestCollectPodsForbidden(t *testing.T) {
+	_, err := Collect(context.Background(), testAPIServer(t, http.StatusForbidden), Options{Namespace: "shop"})
+	if err == nil || !strings.Contains(err.Error(), "failed to list pods") {
+		t.Errorf("Collect() error = %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	snap, err := List(context.Background(), testAPIServer(t, http.StatusOK), Options{
+		Namespace:         "shop",
+		Lister:            NewPodLister(ListModeConsistent, 1),
+		MetadataCache:     &MetadataCache{Path: path, MaxAge: time.Hour, Cluster: "prod/prod"},
+		ExcludeAnnotation: DefaultExcludeAnnotation,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap.Pods) != 2 || snap.Nodes == nil || len(snap.Nodes.Items) != 1 || snap.Namespaces != nil {
+		t.Fatalf("pods, nodes, namespaces = %d, %+v, %+v", len(snap.Pods), snap.Nodes, snap.Namespaces)
+	}
+	// Forbidden namespaces and the opt-out without them are recorded
+	if len(snap.Warnings) != 2 || !strings.Contains(snap.Warnings[0], "failed to list namespaces") || !strings.Contains(snap.Warnings[1], "only pods annotated") {
+		t.Errorf("warnings = %q", snap.Warnings)
+	}
+	if c, err := readMetadataCache(path, "prod/prod"); err != nil || c == nil || c.Nodes == nil {
+		t.Errorf("metadata cache = %+v, %v", c, err)
+	}
+}
@@ -0,0 +1,186 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Pod list modes of PodLister
+const (
+	ListModeAuto       = "auto"       // stream when the cluster supports it, else cache
+	ListModeStream     = "stream"     // WatchList: initial events of a watch, served from the watch cache
+	ListModeCache      = "cache"      // resourceVersion=0: list served from the watch cache
+	ListModeConsistent = "consistent" // Quorum list read from etcd, the Kubernetes default
+)
+
+// ListModes are the valid pod list modes
+var ListModes = []string{ListModeAuto, ListModeStream, ListModeCache, ListModeConsistent}
+
+// WatchListMinMinor is the first Kubernetes 1.x minor release that knows
+// sendInitialEvents; older servers ignore it and would never end the stream
+const WatchListMinMinor = 27
+
+// ParseListMode validates a list mode; empty selects auto
+func ParseListMode(s string) (string, error) {
+	if s == "" {
+		return ListModeAuto, nil
+	}
+	for _, m := range ListModes {
+		if s == m {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown list mode '%s' (valid: %s)", s, strings.Join(ListModes, ", "))
+}
+
+// PodLister lists pods with the list semantics of a mode. Large full LISTs
+// read from etcd make the API server buffer the whole response; the watch
+// cache modes avoid the etcd read and streaming also avoids the buffering.
+// A lister remembers across lists whether the cluster supports streaming.
+type PodLister struct {
+	mode           string
+	chunkSize      int64       // Pods per page of consistent lists, 0 for a single list
+	noStreams      atomic.Bool // Set in auto mode once the cluster rejected a streaming list
+	versionChecked atomic.Bool // Server version checked in auto mode
+}
+
+// NewPodLister returns a lister of a mode validated by ParseListMode
+func NewPodLister(mode string, chunkSize int64) *PodLister {
+	return &PodLister{mode: mode, chunkSize: chunkSize}
+}
+
+// List returns the pods of namespace, all namespaces when empty, without
+// their managed fields
+func (l *PodLister) List(ctx context.Context, clientSet kubernetes.Interface, namespace string) (*corev1.PodList, error) {
+	mode, chunkSize := ListModeConsistent, int64(0)
+	if l != nil {
+		mode, chunkSize = l.mode, l.chunkSize
+	}
+	if mode == ListModeAuto && !l.versionChecked.Swap(true) {
+		info, err := clientSet.Discovery().ServerVersion()
+		if err != nil || !supportsWatchList(info) {
+			logrus.Debugf("Streaming lists not supported by the cluster, listing from the watch cache")
+			l.noStreams.Store(true)
+		}
+	}
+
+	switch {
+	case mode == ListModeStream || mode == ListModeAuto && !l.noStreams.Load():
+		pods, err := streamPods(ctx, clientSet, namespace)
+		if err == nil || mode == ListModeStream {
+			return pods, err
+		}
+		logrus.Debugf("Streaming list failed, listing from the watch cache: %v", err)
+		l.noStreams.Store(true)
+		fallthrough
+	case mode == ListModeCache || mode == ListModeAuto:
+		pods, err := clientSet.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{ResourceVersion: "0"})
+		if err != nil {
+			return nil, err
+		}
+		trimPods(pods.Items)
+		return pods, nil
+	default:
+		return listPodPages(ctx, clientSet, namespace, chunkSize)
+	}
+}
+
+// listPodPages lists pods from etcd in pages of chunkSize, trimming each page
+// before the next one is fetched; 0 lists all pods at once
+func listPodPages(ctx context.Context, clientSet kubernetes.Interface, namespace string, chunkSize int64) (*corev1.PodList, error) {
+	pods := &corev1.PodList{}
+	opts := metav1.ListOptions{Limit: chunkSize}
+	for {
+		page, err := clientSet.CoreV1().Pods(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		trimPods(page.Items)
+		pods.Items = append(pods.Items, page.Items...)
+		pods.ResourceVersion = page.ResourceVersion
+		if page.Continue == "" {
+			return pods, nil
+		}
+		opts.Continue = page.Continue
+		logrus.Debugf("Listed %d pods", len(pods.Items))
+	}
+}
+
+// supportsWatchList reports whether a server version knows streaming lists;
+// the WatchList feature gate may still be off, which the watch reports
+func supportsWatchList(info *k8sversion.Info) bool {
+	if info == nil {
+		return false
+	}
+	major, err := strconv.Atoi(info.Major)
+	if err != nil {
+		return false
+	}
+	minor, err := strconv.Atoi(strings.TrimRight(info.Minor, "+"))
+	return err == nil && (major > 1 || major == 1 && minor >= WatchListMinMinor)
+}
+
+// streamPods lists pods as the initial events of a watch
+func streamPods(ctx context.Context, clientSet kubernetes.Interface, namespace string) (*corev1.PodList, error) {
+	sendInitialEvents := true
+	w, err := clientSet.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{
+		SendInitialEvents:    &sendInitialEvents,
+		ResourceVersionMatch: metav1.ResourceVersionMatchNotOlderThan,
+		AllowWatchBookmarks:  true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start streaming list: %w", err)
+	}
+	return collectInitialPods(ctx, w)
+}
+
+// collectInitialPods collects the pods of a watch until the bookmark that
+// ends the initial events; its resourceVersion is that of the list
+func collectInitialPods(ctx context.Context, w watch.Interface) (*corev1.PodList, error) {
+	defer w.Stop()
+	pods := &corev1.PodList{}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("streaming list interrupted after %d pods: %w", len(pods.Items), ctx.Err())
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return nil, fmt.Errorf("streaming list ended after %d pods without the initial events bookmark", len(pods.Items))
+			}
+			switch e.Type {
+			case watch.Error:
+				return nil, fmt.Errorf("streaming list failed: %w", apierrors.FromObject(e.Object))
+			case watch.Bookmark:
+				obj, ok := e.Object.(metav1.Object)
+				if ok && obj.GetAnnotations()[metav1.InitialEventsAnnotationKey] == "true" {
+					pods.ResourceVersion = obj.GetResourceVersion()
+					return pods, nil
+				}
+			case watch.Added:
+				if pod, ok := e.Object.(*corev1.Pod); ok {
+					pod.ManagedFields = nil
+					pods.Items = append(pods.Items, *pod)
+				}
+			}
+		}
+	}
+}
+
+// trimPods drops the managed fields of listed pods: the server side apply
+// bookkeeping the report never reads, often larger than the pod spec
+func trimPods(pods []corev1.Pod) {
+	for i := range pods {
+		pods[i].ManagedFields = nil
+	}
+}
@@ -0,0 +1,149 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sversion "k8s.io/apimachinery/pkg/version"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestParseListMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", ListModeAuto, false},
+		{"stream", ListModeStream, false},
+		{"cache", ListModeCache, false},
+		{"consistent", ListModeConsistent, false},
+		{"etcd", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseListMode(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseListMode(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestSupportsWatchList(t *testing.T) {
+	tests := []struct {
+		info *k8sversion.Info
+		want bool
+	}{
+		{&k8sversion.Info{Major: "1", Minor: "32"}, true},
+		{&k8sversion.Info{Major: "1", Minor: "27+"}, true},
+		{&k8sversion.Info{Major: "1", Minor: "26"}, false},
+		{&k8sversion.Info{Major: "2", Minor: "0"}, true},
+		{&k8sversion.Info{Major: "", Minor: ""}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := supportsWatchList(tt.info); got != tt.want {
+			t.Errorf("supportsWatchList(%+v) = %v, want %v", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestCollectInitialPods(t *testing.T) {
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"}}
+	}
+	endBookmark := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		ResourceVersion: "42",
+		Annotations:     map[string]string{metav1.InitialEventsAnnotationKey: "true"},
+	}}
+	progressBookmark := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "40"}}
+
+	t.Run("until the initial events bookmark", func(t *testing.T) {
+		w := watch.NewFakeWithChanSize(5, false)
+		w.Add(pod("a"))
+		w.Action(watch.Bookmark, progressBookmark)
+		w.Add(pod("b"))
+		w.Action(watch.Bookmark, endBookmark)
+		w.Add(pod("after-the-list"))
+
+		pods, err := collectInitialPods(context.Background(), w)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pods.Items) != 2 || pods.Items[0].Name != "a" || pods.Items[1].Name != "b" || pods.ResourceVersion != "42" {
+			t.Errorf("got %d pods at resourceVersion %s", len(pods.Items), pods.ResourceVersion)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Error(&apierrors.NewBadRequest("sendInitialEvents is forbidden").ErrStatus)
+		if _, err := collectInitialPods(context.Background(), w); err == nil {
+			t.Error("expected an error for a rejected streaming list")
+		}
+	})
+
+	t.Run("closed without bookmark", func(t *testing.T) {
+		w := watch.NewFakeWithChanSize(1, false)
+		w.Add(pod("a"))
+		w.Stop()
+		if _, err := collectInitialPods(context.Background(), w); err == nil {
+			t.Error("expected an error for a stream without the initial events bookmark")
+		}
+	})
+}
+
+func TestListPodPages(t *testing.T) {
+	var limits []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits = append(limits, r.URL.Query().Get("limit"))
+		next, rv, name := "page-2", "10", "a"
+		if r.URL.Query().Get("continue") == "page-2" {
+			next, rv, name = "", "11", "b"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind":"PodList","apiVersion":"v1","metadata":{"resourceVersion":%q,"continue":%q},`+
+			`"items":[{"metadata":{"name":%q,"managedFields":[{"manager":"kubectl"}]}}]}`, rv, next, name)
+	}))
+	defer srv.Close()
+
+	clientSet, err := kubernetes.NewForConfig(&rest.Config{Host: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods, err := NewPodLister(ListModeConsistent, 1).List(context.Background(), clientSet, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 2 || pods.Items[0].Name != "a" || pods.Items[1].Name != "b" || pods.ResourceVersion != "11" {
+		t.Errorf("got %d pods at resourceVersion %s", len(pods.Items), pods.ResourceVersion)
+	}
+	if pods.Items[0].ManagedFields != nil {
+		t.Error("managed fields should be dropped from each page")
+	}
+	if len(limits) != 2 || limits[0] != "1" {
+		t.Errorf("requests with limits %v, want two pages of 1", limits)
+	}
+}
+
+func TestTrimPods(t *testing.T) {
+	pods := []corev1.Pod{{ObjectMeta: metav1.ObjectMeta{
+		Name:          "cart-1",
+		Annotations:   map[string]string{"owner": "shop"},
+		ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kubectl"}},
+	}}}
+	trimPods(pods)
+	if pods[0].ManagedFields != nil {
+		t.Error("managed fields should be dropped")
+	}
+	if pods[0].Annotations["owner"] != "shop" {
+		t.Error("annotations feed custom columns and must be kept")
+	}
+}
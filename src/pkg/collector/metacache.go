@@ -0,0 +1,252 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+)
+
+// MetadataCacheVersion is the format version of the metadata cache; other
+// versions are ignored and rebuilt
+const MetadataCacheVersion = 1
+
+// Defaults of the metadata cache
+const (
+	DefaultMetadataCacheMaxAge = 24 * time.Hour // Full list at least this often
+	MetadataCacheWatchSeconds  = 1              // Server side timeout of the catch-up watch
+)
+
+// errCacheExpired is returned when the API server no longer has the events
+// since the cached resourceVersion
+var errCacheExpired = errors.New("cached resourceVersion expired")
+
+// metadataFile are the node and namespace lists of a cluster with their list
+// resourceVersions. Later runs watch from those versions and apply the changes
+// instead of listing again, so frequent runs only list pods.
+type metadataFile struct {
+	Version    int                   `json:"version"`
+	Cluster    string                `json:"cluster"` // MetadataCache.Cluster; a cache of another cluster is ignored
+	Listed     time.Time             `json:"listed"`  // Last full list
+	Nodes      *corev1.NodeList      `json:"nodes,omitempty"`
+	Namespaces *corev1.NamespaceList `json:"namespaces,omitempty"`
+}
+
+// readMetadataCache returns the cache at path, or nil when it is missing, of
+// another format version or of another cluster. An unreadable cache is nil
+// with the error.
+func readMetadataCache(path, cluster string) (*metadataFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	c := &metadataFile{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if c.Version != MetadataCacheVersion || c.Cluster != cluster {
+		logrus.Debugf("Ignoring metadata cache %s of version %d for cluster '%s'", path, c.Version, c.Cluster)
+		return nil, nil
+	}
+	return c, nil
+}
+
+// writeMetadataCache replaces the cache at path atomically, so a concurrent
+// run never reads a partial file
+func writeMetadataCache(path string, c *metadataFile) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("failed to encode metadata cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metadata-cache-*")
+	if err != nil {
+		return fmt.Errorf("failed to create metadata cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metadata cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace metadata cache %s: %w", path, err)
+	}
+	return nil
+}
+
+// MetadataCache keeps the node and namespace lists of a cluster in a file
+// between collections
+type MetadataCache struct {
+	Path    string        // Cache file, replaced atomically
+	MaxAge  time.Duration // Full list at least this often
+	Cluster string        // Identity of the cluster, e.g. its context and name
+}
+
+// load returns the nodes and namespaces of the cluster, from the cache
+// updated with the changes since it was written when possible. A list that
+// cannot be fetched is nil, as without a cache; the failures are returned as
+// warnings.
+func (m *MetadataCache) load(ctx context.Context, clientSet kubernetes.Interface, now time.Time) (*corev1.NodeList, *corev1.NamespaceList, []string) {
+	var warnings []string
+	c, err := readMetadataCache(m.Path, m.Cluster)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("ignoring metadata cache: %v", err))
+	}
+	if c != nil && now.Sub(c.Listed) > m.MaxAge {
+		logrus.Debugf("Metadata cache is older than %s, listing again", m.MaxAge)
+		c = nil
+	}
+	if c == nil {
+		c = &metadataFile{Version: MetadataCacheVersion, Cluster: m.Cluster, Listed: now}
+	}
+
+	nodesCached, namespacesCached := c.Nodes != nil, c.Namespaces != nil
+	if nodesCached {
+		if err := updateNodes(ctx, clientSet, c.Nodes); err != nil {
+			logrus.Debugf("Listing nodes again: %v", err)
+			c.Nodes, nodesCached = nil, false
+		}
+	}
+	if !nodesCached {
+		var err error
+		if c.Nodes, err = clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{}); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to list nodes: %v", err))
+			c.Nodes = nil
+		}
+	}
+	if namespacesCached {
+		if err := updateNamespaces(ctx, clientSet, c.Namespaces); err != nil {
+			logrus.Debugf("Listing namespaces again: %v", err)
+			c.Namespaces, namespacesCached = nil, false
+		}
+	}
+	if !namespacesCached {
+		var err error
+		if c.Namespaces, err = clientSet.CoreV1().Namespaces().List(ctx, metav1.ListOptions{}); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to list namespaces: %v", err))
+			c.Namespaces = nil
+		}
+	}
+	if nodesCached && namespacesCached {
+		logrus.Infof("Using cached node and namespace metadata from %s", m.Path)
+	} else if !nodesCached && !namespacesCached {
+		// Only a complete relist restarts the max age
+		c.Listed = now
+	}
+
+	if err := writeMetadataCache(m.Path, c); err != nil {
+		warnings = append(warnings, fmt.Sprintf("failed to update metadata cache: %v", err))
+	}
+	return c.Nodes, c.Namespaces, warnings
+}
+
+// watchOptions returns the options of a short watch from resourceVersion
+func watchOptions(resourceVersion string) metav1.ListOptions {
+	timeout := int64(MetadataCacheWatchSeconds)
+	return metav1.ListOptions{ResourceVersion: resourceVersion, TimeoutSeconds: &timeout, AllowWatchBookmarks: true}
+}
+
+// updateNodes applies the node changes since the list was cached
+func updateNodes(ctx context.Context, clientSet kubernetes.Interface, nodes *corev1.NodeList) error {
+	w, err := clientSet.CoreV1().Nodes().Watch(ctx, watchOptions(nodes.ResourceVersion))
+	if err != nil {
+		return fmt.Errorf("failed to watch nodes: %w", err)
+	}
+	return drainWatch(ctx, w, &nodes.ResourceVersion, func(e watch.Event) {
+		if node, ok := e.Object.(*corev1.Node); ok {
+			applyNodeEvent(nodes, e.Type, node)
+		}
+	})
+}
+
+// updateNamespaces applies the namespace changes since the list was cached
+func updateNamespaces(ctx context.Context, clientSet kubernetes.Interface, namespaces *corev1.NamespaceList) error {
+	w, err := clientSet.CoreV1().Namespaces().Watch(ctx, watchOptions(namespaces.ResourceVersion))
+	if err != nil {
+		return fmt.Errorf("failed to watch namespaces: %w", err)
+	}
+	return drainWatch(ctx, w, &namespaces.ResourceVersion, func(e watch.Event) {
+		if ns, ok := e.Object.(*corev1.Namespace); ok {
+			applyNamespaceEvent(namespaces, e.Type, ns)
+		}
+	})
+}
+
+// drainWatch applies the events of w until the server closes it, advancing
+// resourceVersion with each event
+func drainWatch(ctx context.Context, w watch.Interface, resourceVersion *string, apply func(watch.Event)) error {
+	defer w.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case e, ok := <-w.ResultChan():
+			if !ok {
+				return nil
+			}
+			if e.Type == watch.Error {
+				if err := apierrors.FromObject(e.Object); apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+					return errCacheExpired
+				}
+				return fmt.Errorf("watch failed: %w", apierrors.FromObject(e.Object))
+			}
+			if obj, ok := e.Object.(metav1.Object); ok {
+				*resourceVersion = obj.GetResourceVersion()
+			}
+			if e.Type != watch.Bookmark {
+				apply(e)
+			}
+		}
+	}
+}
+
+// applyNodeEvent adds, replaces or removes a node of the cached list
+func applyNodeEvent(nodes *corev1.NodeList, t watch.EventType, node *corev1.Node) {
+	for i := range nodes.Items {
+		if nodes.Items[i].Name != node.Name {
+			continue
+		}
+		if t == watch.Deleted {
+			nodes.Items = append(nodes.Items[:i], nodes.Items[i+1:]...)
+		} else {
+			nodes.Items[i] = *node
+		}
+		return
+	}
+	if t != watch.Deleted {
+		nodes.Items = append(nodes.Items, *node)
+	}
+}
+
+// applyNamespaceEvent adds, replaces or removes a namespace of the cached list
+func applyNamespaceEvent(namespaces *corev1.NamespaceList, t watch.EventType, ns *corev1.Namespace) {
+	for i := range namespaces.Items {
+		if namespaces.Items[i].Name != ns.Name {
+			continue
+		}
+		if t == watch.Deleted {
+			namespaces.Items = append(namespaces.Items[:i], namespaces.Items[i+1:]...)
+		} else {
+			namespaces.Items[i] = *ns
+		}
+		return
+	}
+	if t != watch.Deleted {
+		namespaces.Items = append(namespaces.Items, *ns)
+	}
+}
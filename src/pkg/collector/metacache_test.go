@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+func nodeNames(nodes *corev1.NodeList) []string {
+	var names []string
+	for _, n := range nodes.Items {
+		names = append(names, n.Name+"@"+n.ResourceVersion)
+	}
+	return names
+}
+
+func TestApplyNodeEvent(t *testing.T) {
+	node := func(name, rv string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, ResourceVersion: rv}}
+	}
+	tests := []struct {
+		name  string
+		event watch.EventType
+		node  *corev1.Node
+		want  []string
+	}{
+		{"added", watch.Added, node("c", "5"), []string{"a@1", "b@2", "c@5"}},
+		{"modified", watch.Modified, node("b", "5"), []string{"a@1", "b@5"}},
+		{"deleted", watch.Deleted, node("a", "5"), []string{"b@2"}},
+		{"deleted unknown", watch.Deleted, node("x", "5"), []string{"a@1", "b@2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := &corev1.NodeList{Items: []corev1.Node{*node("a", "1"), *node("b", "2")}}
+			applyNodeEvent(nodes, tt.event, tt.node)
+			if got := nodeNames(nodes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nodes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	namespaces := &corev1.NamespaceList{Items: []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: "shop"}}}}
+	applyNamespaceEvent(namespaces, watch.Modified, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"team": "a"}}})
+	applyNamespaceEvent(namespaces, watch.Added, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "search"}})
+	if len(namespaces.Items) != 2 || namespaces.Items[0].Labels["team"] != "a" || namespaces.Items[1].Name != "search" {
+		t.Errorf("unexpected namespaces: %+v", namespaces.Items)
+	}
+}
+
+func TestDrainWatch(t *testing.T) {
+	t.Run("applies events", func(t *testing.T) {
+		w := watch.NewFakeWithChanSize(3, false)
+		w.Add(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "c", ResourceVersion: "7"}})
+		w.Action(watch.Bookmark, &corev1.Node{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "9"}})
+		w.Stop()
+
+		nodes := &corev1.NodeList{ListMeta: metav1.ListMeta{ResourceVersion: "5"}}
+		err := drainWatch(context.Background(), w, &nodes.ResourceVersion, func(e watch.Event) {
+			applyNodeEvent(nodes, e.Type, e.Object.(*corev1.Node))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nodes.ResourceVersion != "9" || !reflect.DeepEqual(nodeNames(nodes), []string{"c@7"}) {
+			t.Errorf("got resourceVersion %s and nodes %v", nodes.ResourceVersion, nodeNames(nodes))
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		w := watch.NewFakeWithChanSize(1, false)
+		gone := apierrors.NewResourceExpired("too old resource version")
+		w.Error(&gone.ErrStatus)
+		rv := "5"
+		err := drainWatch(context.Background(), w, &rv, func(watch.Event) { t.Error("error events must not be applied") })
+		if !errors.Is(err, errCacheExpired) {
+			t.Errorf("err = %v, want errCacheExpired", err)
+		}
+	})
+}
+
+func TestMetadataCacheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metadata.json")
+	listed := time.Date(2024, 5, 10, 8, 0, 0, 0, time.UTC)
+	c := &metadataFile{
+		Version: MetadataCacheVersion, Cluster: "prod/prod", Listed: listed,
+		Nodes: &corev1.NodeList{ListMeta: metav1.ListMeta{ResourceVersion: "42"}, Items: []corev1.Node{{ObjectMeta: metav1.ObjectMeta{Name: "a"}}}},
+	}
+	if got, err := readMetadataCache(path, "prod/prod"); got != nil || err != nil {
+		t.Errorf("missing cache = %+v, %v; want nil", got, err)
+	}
+	if err := writeMetadataCache(path, c); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readMetadataCache(path, "prod/prod")
+	if err != nil || got == nil || !got.Listed.Equal(listed) || got.Nodes.ResourceVersion != "42" || len(got.Nodes.Items) != 1 {
+		t.Errorf("readMetadataCache = %+v", got)
+	}
+	if got, _ := readMetadataCache(path, "staging/staging"); got != nil {
+		t.Error("a cache of another cluster must be ignored")
+	}
+
+	c.Version = MetadataCacheVersion + 1
+	if err := writeMetadataCache(path, c); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := readMetadataCache(path, "prod/prod"); got != nil {
+		t.Error("a cache of another version must be ignored")
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readMetadataCache(path, "prod/prod"); got != nil || err == nil {
+		t.Errorf("corrupt cache = %+v, %v; want nil with an error", got, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
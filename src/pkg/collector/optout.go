@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultExcludeAnnotation opts a pod or a namespace out of the reports when
+// set to "true"
+const DefaultExcludeAnnotation = "resource-report/exclude"
+
+// ExcludeAnnotationNone as the exclude annotation reports annotated objects too
+const ExcludeAnnotationNone = "none"
+
+// ParseExcludeAnnotation validates the opt-out annotation key; empty selects
+// the default and none disables the opt-out, returned as an empty key
+func ParseExcludeAnnotation(key string) (string, error) {
+	switch key {
+	case "":
+		return DefaultExcludeAnnotation, nil
+	case ExcludeAnnotationNone:
+		return "", nil
+	}
+	if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+		return "", fmt.Errorf("invalid annotation key '%s': %s", key, strings.Join(errs, "; "))
+	}
+	return key, nil
+}
+
+// OptedOut reports whether annotations opt their object out with key
+func OptedOut(annotations map[string]string, key string) bool {
+	if key == "" {
+		return false
+	}
+	exclude, err := strconv.ParseBool(annotations[key])
+	return err == nil && exclude
+}
+
+// OptedOutPods returns the pods not opted out with key, directly or by their
+// namespace in excluded
+func OptedOutPods(pods []corev1.Pod, key string, excluded map[string]bool) []corev1.Pod {
+	if key == "" {
+		return pods
+	}
+	kept := pods[:0]
+	for _, pod := range pods {
+		if !excluded[pod.Namespace] && !OptedOut(pod.Annotations, key) {
+			kept = append(kept, pod)
+		}
+	}
+	return kept
+}
+
+// excludeOptedOut drops the pods and namespaces opted out with key from snap
+// and records the excluded namespaces
+func excludeOptedOut(snap *Snapshot, key string) {
+	if key == "" {
+		return
+	}
+	if snap.Namespaces == nil {
+		snap.Warnings = append(snap.Warnings, fmt.Sprintf("namespaces could not be listed, only pods annotated with %s are excluded", key))
+	} else {
+		kept := &corev1.NamespaceList{ListMeta: snap.Namespaces.ListMeta}
+		for _, ns := range snap.Namespaces.Items {
+			if OptedOut(ns.Annotations, key) {
+				if snap.Excluded == nil {
+					snap.Excluded = map[string]bool{}
+				}
+				snap.Excluded[ns.Name] = true
+				continue
+			}
+			kept.Items = append(kept.Items, ns)
+		}
+		snap.Namespaces = kept
+	}
+
+	total := len(snap.Pods)
+	snap.Pods = OptedOutPods(snap.Pods, key, snap.Excluded)
+	if len(snap.Excluded) > 0 || total > len(snap.Pods) {
+		logrus.Infof("Excluded %d namespaces and %d pods annotated with %s", len(snap.Excluded), total-len(snap.Pods), key)
+	}
+}
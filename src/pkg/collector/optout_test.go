@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseExcludeAnnotation(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", DefaultExcludeAnnotation, false},
+		{"none", "", false},
+		{"example.com/skip-report", "example.com/skip-report", false},
+		{"skip", "skip", false},
+		{"bad key/with/slashes", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseExcludeAnnotation(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseExcludeAnnotation(%q) = %q, %v", tt.input, got, err)
+		}
+	}
+}
+
+func TestExcludeOptedOut(t *testing.T) {
+	const key = DefaultExcludeAnnotation
+	pod := func(namespace, name, exclude string) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		if exclude != "" {
+			p.Annotations = map[string]string{key: exclude}
+		}
+		return p
+	}
+	namespace := func(name, exclude string) corev1.Namespace {
+		ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if exclude != "" {
+			ns.Annotations = map[string]string{key: exclude}
+		}
+		return ns
+	}
+	newSnapshot := func() *Snapshot {
+		return &Snapshot{
+			Pods: []corev1.Pod{
+				pod("shop", "web", ""),
+				pod("shop", "load-test", "true"),
+				pod("shop", "api", "false"),
+				pod("preview-42", "web", ""),
+			},
+			Namespaces: &corev1.NamespaceList{Items: []corev1.Namespace{
+				namespace("shop", ""),
+				namespace("preview-42", "TRUE"),
+			}},
+		}
+	}
+	podNames := func(pods []corev1.Pod) []string {
+		var names []string
+		for _, p := range pods {
+			names = append(names, p.Namespace+"/"+p.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	snap := newSnapshot()
+	excludeOptedOut(snap, key)
+	if got, want := podNames(snap.Pods), []string{"shop/api", "shop/web"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("pods = %v, want %v", got, want)
+	}
+	if len(snap.Namespaces.Items) != 1 || !snap.Excluded["preview-42"] || len(snap.Warnings) != 0 {
+		t.Errorf("namespaces = %+v, excluded = %v, warnings = %q", snap.Namespaces.Items, snap.Excluded, snap.Warnings)
+	}
+	if got := OptedOutPods([]corev1.Pod{pod("shop", "web", ""), pod("shop", "load-test", "yes"), pod("shop", "tmp", "1")}, key, nil); len(got) != 2 {
+		t.Errorf("OptedOutPods kept %v, want web and load-test (not a boolean)", podNames(got))
+	}
+
+	disabled := newSnapshot()
+	excludeOptedOut(disabled, "")
+	if len(disabled.Pods) != 4 || len(disabled.Namespaces.Items) != 2 {
+		t.Error("without a key nothing must be excluded")
+	}
+
+	withoutNamespaces := newSnapshot()
+	withoutNamespaces.Namespaces = nil
+	excludeOptedOut(withoutNamespaces, key)
+	if len(withoutNamespaces.Pods) != 3 || len(withoutNamespaces.Warnings) != 1 {
+		t.Errorf("got %d pods and warnings %q, want the pod annotations applied without namespaces", len(withoutNamespaces.Pods), withoutNamespaces.Warnings)
+	}
+}
@@ -0,0 +1,80 @@
+package export
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// IntegerStyle formats numbers without decimal places
+func IntegerStyle(f *excelize.File) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		NumFmt: 1, // 0 format (no decimal places)
+	})
+	return style
+}
+
+// BoldIntegerStyle formats totals without decimal places
+func BoldIntegerStyle(f *excelize.File) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: true},
+		NumFmt: 1, // 0 format (no decimal places)
+	})
+	return style
+}
+
+// DecimalStyle formats numbers with two decimal places, optionally bold
+func DecimalStyle(f *excelize.File, bold bool) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		Font:   &excelize.Font{Bold: bold},
+		NumFmt: 2, // 0.00 format
+	})
+	return style
+}
+
+// PercentStyle formats ratio values (0.631) as percentages (63.1%)
+func PercentStyle(f *excelize.File, format string) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		CustomNumFmt: &format,
+	})
+	return style
+}
+
+// BoldStyle marks totals
+func BoldStyle(f *excelize.File) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+	})
+	return style
+}
+
+// TitleStyle is the style of sheet titles
+func TitleStyle(f *excelize.File) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 16},
+	})
+	return style
+}
+
+// HeaderStyle is the style of section headers
+func HeaderStyle(f *excelize.File) int {
+	style, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Size: 12},
+	})
+	return style
+}
+
+// SetRow writes data to a row starting at column A; context names the row in
+// errors
+func SetRow(f *excelize.File, sheetName string, row int, data []interface{}, context string) error {
+	cellName, err := excelize.CoordinatesToCellName(1, row)
+	if err != nil {
+		return fmt.Errorf("failed to get cell name for row %d in %s: %w", row, context, err)
+	}
+
+	if err := f.SetSheetRow(sheetName, cellName, &data); err != nil {
+		return fmt.Errorf("failed to set row data for %s at row %d: %w", context, row, err)
+	}
+
+	return nil
+}
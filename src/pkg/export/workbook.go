@@ -0,0 +1,21 @@
+// Package export holds the workbook layer of PodResourceCalculator: the cell
+// styles and row helpers of every sheet the binary writes. The sheets
+// themselves stay in the binary; programs that need the report data use the
+// typed report of the collector package or its JSON form.
+package export
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// New returns a workbook whose only sheet is sheetName
+func New(sheetName string) (*excelize.File, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to create sheet '%s': %w", sheetName, err)
+	}
+	return f, nil
+}
@@ -0,0 +1,29 @@
+package export
+
+import (
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	f, err := New("Summary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if got := f.GetSheetList(); len(got) != 1 || got[0] != "Summary" {
+		t.Fatalf("sheets = %v", got)
+	}
+	data := []interface{}{"shop", 1.5, nil}
+	if err := SetRow(f, "Summary", 2, data, "namespace 'shop'"); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A2": "shop", "B2": "1.5", "C2": ""} {
+		if got, _ := f.GetCellValue("Summary", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+	if err := SetRow(f, "Summary", 0, data, "headers"); err == nil {
+		t.Error("row 0 must fail")
+	}
+}
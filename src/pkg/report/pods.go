@@ -0,0 +1,70 @@
+package report
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// DefaultNodePool groups nodes without a known node pool label
+const DefaultNodePool = "default"
+
+// NodePoolLabels are node labels naming the node pool, checked in order
+var NodePoolLabels = []string{
+	"karpenter.sh/nodepool",
+	"cloud.google.com/gke-nodepool",
+	"eks.amazonaws.com/nodegroup",
+	"alpha.eksctl.io/nodegroup-name",
+	"kubernetes.azure.com/agentpool",
+	"agentpool",
+	"node.kubernetes.io/pool",
+	"pool",
+}
+
+// IsActive reports whether a pod holds or waits for its requests: Running or
+// Pending. Succeeded and Failed pods are left out of all totals.
+func IsActive(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodRunning || pod.Status.Phase == corev1.PodPending
+}
+
+// Workload resolves the top-level controller of a pod without extra API calls,
+// the pod itself when it has none. ReplicaSets created by Deployments are
+// mapped back using the pod-template-hash label.
+func Workload(pod *corev1.Pod) (kind, name string) {
+	kind, name = "Pod", pod.Name
+	for _, ref := range pod.OwnerReferences {
+		if ref.Controller != nil && !*ref.Controller {
+			continue
+		}
+		kind, name = ref.Kind, ref.Name
+
+		if ref.Kind == "ReplicaSet" {
+			if hash := pod.Labels["pod-template-hash"]; hash != "" && strings.HasSuffix(ref.Name, "-"+hash) {
+				kind = "Deployment"
+				name = strings.TrimSuffix(ref.Name, "-"+hash)
+			}
+		}
+		break
+	}
+	return kind, name
+}
+
+// NodePool returns the node pool of a node from well-known labels
+func NodePool(node *corev1.Node) string {
+	for _, label := range NodePoolLabels {
+		if pool := node.Labels[label]; pool != "" {
+			return pool
+		}
+	}
+	return DefaultNodePool
+}
+
+// NodeIP returns the internal IP of a node, empty when it has none
+func NodeIP(node *corev1.Node) string {
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == corev1.NodeInternalIP {
+			return addr.Address
+		}
+	}
+	return ""
+}
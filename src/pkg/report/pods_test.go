@@ -0,0 +1,31 @@
+package report
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkload(t *testing.T) {
+	controller := true
+	tests := []struct {
+		name       string
+		labels     map[string]string
+		owners     []metav1.OwnerReference
+		kind, want string
+	}{
+		{"bare pod", nil, nil, "Pod", "web-7d9f-x2"},
+		{"deployment", map[string]string{"pod-template-hash": "7d9f"}, []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7d9f", Controller: &controller}}, "Deployment", "web"},
+		{"plain replicaset", nil, []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-7d9f"}}, "ReplicaSet", "web-7d9f"},
+		{"statefulset", nil, []metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &controller}}, "StatefulSet", "db"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-7d9f-x2", Labels: tt.labels, OwnerReferences: tt.owners}}
+			if kind, name := Workload(pod); kind != tt.kind || name != tt.want {
+				t.Errorf("Workload() = %s/%s, want %s/%s", kind, name, tt.kind, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+// Package report builds the typed resource report of a cluster snapshot: one
+// row per container of the running and pending pods, namespace and node
+// totals, and the efficiency insights. It is the data model of the -format
+// json export and the REST API of PodResourceCalculator, for Go programs that
+// embed the calculator instead of running it. Quantities are millicores and
+// bytes; the JSON keys are camelCase.
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GPUResource is the extended resource of the GPU requests and limits
+const GPUResource corev1.ResourceName = "nvidia.com/gpu"
+
+// Report is the resource report of a cluster snapshot
+type Report struct {
+	Generated  time.Time   `json:"generated"`
+	Cluster    string      `json:"cluster,omitempty"`
+	Group      string      `json:"group,omitempty"` // Split group of -split-by
+	Containers []Container `json:"containers"`
+	Namespaces []Namespace `json:"namespaces"`
+	Nodes      []Node      `json:"nodes"`
+	Insights   Insights    `json:"insights"`
+	Warnings   []string    `json:"-"` // Data that could not be collected, e.g. nodes without RBAC access
+}
+
+// Container is a container of a running or pending pod; unset requests and
+// limits are omitted
+type Container struct {
+	Namespace                    string     `json:"namespace"`
+	Pod                          string     `json:"pod"`
+	Container                    string     `json:"container"`
+	Team                         string     `json:"team,omitempty"`
+	WorkloadKind                 string     `json:"workloadKind,omitempty"`
+	WorkloadName                 string     `json:"workloadName,omitempty"`
+	Node                         string     `json:"node,omitempty"`
+	NodeIP                       string     `json:"nodeIp,omitempty"`
+	NodePool                     string     `json:"nodePool,omitempty"`
+	Phase                        string     `json:"phase"`
+	QoSClass                     string     `json:"qosClass,omitempty"`
+	TShirtSize                   string     `json:"tshirtSize,omitempty"`
+	PodCreated                   *time.Time `json:"podCreated,omitempty"`
+	RestartCount                 int64      `json:"restartCount"`
+	RequestCPUMillicores         *int64     `json:"requestCpuMillicores,omitempty"`
+	LimitCPUMillicores           *int64     `json:"limitCpuMillicores,omitempty"`
+	RequestMemoryBytes           *int64     `json:"requestMemoryBytes,omitempty"`
+	LimitMemoryBytes             *int64     `json:"limitMemoryBytes,omitempty"`
+	RequestEphemeralStorageBytes *int64     `json:"requestEphemeralStorageBytes,omitempty"`
+	LimitEphemeralStorageBytes   *int64     `json:"limitEphemeralStorageBytes,omitempty"`
+	RequestGPU                   *int64     `json:"requestGpu,omitempty"`
+	LimitGPU                     *int64     `json:"limitGpu,omitempty"`
+}
+
+// Namespace is the request and limit total of a namespace
+type Namespace struct {
+	Name                 string `json:"name"`
+	Phase                string `json:"phase,omitempty"` // Active or Terminating, empty when namespaces could not be listed
+	RequestCPUMillicores int64  `json:"requestCpuMillicores"`
+	LimitCPUMillicores   int64  `json:"limitCpuMillicores"`
+	RequestMemoryBytes   int64  `json:"requestMemoryBytes"`
+	LimitMemoryBytes     int64  `json:"limitMemoryBytes"`
+}
+
+// Node is the requests and capacity of a node; pods that are not scheduled
+// yet are grouped under the IP "Unknown"
+type Node struct {
+	Name                     string   `json:"name,omitempty"`
+	IP                       string   `json:"ip"`
+	Pool                     string   `json:"pool,omitempty"`
+	Pods                     int      `json:"pods"`
+	AllocatableCPUMillicores int64    `json:"allocatableCpuMillicores"`
+	RequestCPUMillicores     int64    `json:"requestCpuMillicores"`
+	LimitCPUMillicores       int64    `json:"limitCpuMillicores"`
+	AllocatableMemoryBytes   int64    `json:"allocatableMemoryBytes"`
+	RequestMemoryBytes       int64    `json:"requestMemoryBytes"`
+	LimitMemoryBytes         int64    `json:"limitMemoryBytes"`
+	CPURequestRatio          *float64 `json:"cpuRequestRatio,omitempty"` // Requests / allocatable, omitted without capacity
+	MemoryRequestRatio       *float64 `json:"memoryRequestRatio,omitempty"`
+}
+
+// Insights is the efficiency analysis of the cluster. Ratings and
+// recommendations are English.
+type Insights struct {
+	CPUEfficiency                 *float64 `json:"cpuEfficiency,omitempty"` // Request/limit, omitted without limits
+	MemoryEfficiency              *float64 `json:"memoryEfficiency,omitempty"`
+	CPURating                     string   `json:"cpuRating,omitempty"`
+	MemoryRating                  string   `json:"memoryRating,omitempty"`
+	OverProvisionedNamespaces     int      `json:"overProvisionedNamespaces"`
+	BalancedNamespaces            int      `json:"balancedNamespaces"`
+	UnderProvisionedNamespaces    int      `json:"underProvisionedNamespaces"`
+	PotentialCPUSavingsMillicores int64    `json:"potentialCpuSavingsMillicores"` // If limits = requests
+	PotentialMemorySavingsBytes   int64    `json:"potentialMemorySavingsBytes"`
+	LoadBalanceScore              float64  `json:"loadBalanceScore"` // 0-100
+	Recommendations               []string `json:"recommendations"`
+}
+
+// Options are the optional enrichments of a report
+type Options struct {
+	Cluster string
+	Group   string
+	// TShirtSize classifies a container by its requests, nil leaves the size empty
+	TShirtSize func(requestCPUMillicores, requestMemoryBytes int64) string
+	// Team resolves the owning team of a pod, nil leaves the team empty
+	Team func(pod *corev1.Pod) string
+	// Scoring rates the insights, nil for ScoringModels[DefaultScoringModel]
+	Scoring Scoring
+}
+
+// Build aggregates the pods, namespaces and nodes of a snapshot taken at
+// generated into a report. Namespaces and nodes may be nil when they could not
+// be listed; pod creation times are in the location of generated.
+func Build(generated time.Time, pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *corev1.NodeList, opts Options) *Report {
+	model := opts.Scoring
+	if model == nil {
+		model = ScoringModels[DefaultScoringModel]
+	}
+
+	namespaceTotals, nodeTotals := Aggregate(pods, nodes)
+	r := &Report{
+		Generated:  generated,
+		Cluster:    opts.Cluster,
+		Group:      opts.Group,
+		Containers: containers(generated, pods, nodes, opts),
+		Namespaces: namespaceItems(namespaceTotals, namespaces),
+		Nodes:      nodeItems(nodeTotals, nodes),
+	}
+
+	podsPerNode := make([]int, 0, len(nodeTotals))
+	for _, totals := range nodeTotals {
+		podsPerNode = append(podsPerNode, totals.Pods)
+	}
+	score := ScoreCluster(namespaceTotals, podsPerNode, model)
+	r.Insights = Insights{
+		OverProvisionedNamespaces:     score.OverProvisioned,
+		BalancedNamespaces:            score.Balanced,
+		UnderProvisionedNamespaces:    score.UnderProvisioned,
+		PotentialCPUSavingsMillicores: score.LimitCPU - score.RequestCPU,
+		PotentialMemorySavingsBytes:   score.LimitMemory - score.RequestMemory,
+		LoadBalanceScore:              score.Balance,
+		Recommendations:               model.Recommendations(score),
+	}
+	if score.LimitCPU > 0 {
+		eff := score.CPUEfficiency / 100
+		r.Insights.CPUEfficiency, r.Insights.CPURating = &eff, model.Rating(score.CPUEfficiency)
+	}
+	if score.LimitMemory > 0 {
+		eff := score.MemoryEfficiency / 100
+		r.Insights.MemoryEfficiency, r.Insights.MemoryRating = &eff, model.Rating(score.MemoryEfficiency)
+	}
+	return r
+}
+
+// Summarize returns the namespace and node totals of a snapshot, sorted by name
+func Summarize(pods []corev1.Pod, namespaces *corev1.NamespaceList, nodes *corev1.NodeList) ([]Namespace, []Node) {
+	namespaceTotals, nodeTotals := Aggregate(pods, nodes)
+	return namespaceItems(namespaceTotals, namespaces), nodeItems(nodeTotals, nodes)
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// containers returns the container rows of the active pods in pod order
+func containers(generated time.Time, pods []corev1.Pod, nodes *corev1.NodeList, opts Options) []Container {
+	pools := make(map[string]string)
+	if nodes != nil {
+		for i := range nodes.Items {
+			pools[nodes.Items[i].Name] = NodePool(&nodes.Items[i])
+		}
+	}
+
+	rows := []Container{}
+	for i := range pods {
+		pod := &pods[i]
+		if !IsActive(pod) {
+			continue
+		}
+		kind, name := Workload(pod)
+		var team string
+		if opts.Team != nil {
+			team = opts.Team(pod)
+		}
+		var restarts int64
+		for _, cs := range pod.Status.ContainerStatuses {
+			restarts += int64(cs.RestartCount)
+		}
+		var created *time.Time
+		if !pod.CreationTimestamp.IsZero() {
+			t := pod.CreationTimestamp.In(generated.Location())
+			created = &t
+		}
+		for _, c := range pod.Spec.Containers {
+			req, lim := c.Resources.Requests, c.Resources.Limits
+			var size string
+			if opts.TShirtSize != nil {
+				size = opts.TShirtSize(QuantityMilli(req.Cpu()), QuantityBytes(req.Memory()))
+			}
+			rows = append(rows, Container{
+				Namespace:                    pod.Namespace,
+				Pod:                          pod.Name,
+				Container:                    c.Name,
+				Team:                         team,
+				WorkloadKind:                 kind,
+				WorkloadName:                 name,
+				Node:                         pod.Spec.NodeName,
+				NodeIP:                       pod.Status.HostIP,
+				NodePool:                     pools[pod.Spec.NodeName],
+				Phase:                        string(pod.Status.Phase),
+				QoSClass:                     string(pod.Status.QOSClass),
+				TShirtSize:                   size,
+				PodCreated:                   created,
+				RestartCount:                 restarts,
+				RequestCPUMillicores:         quantity(req, corev1.ResourceCPU, true),
+				LimitCPUMillicores:           quantity(lim, corev1.ResourceCPU, true),
+				RequestMemoryBytes:           quantity(req, corev1.ResourceMemory, false),
+				LimitMemoryBytes:             quantity(lim, corev1.ResourceMemory, false),
+				RequestEphemeralStorageBytes: quantity(req, corev1.ResourceEphemeralStorage, false),
+				LimitEphemeralStorageBytes:   quantity(lim, corev1.ResourceEphemeralStorage, false),
+				RequestGPU:                   quantity(req, GPUResource, false),
+				LimitGPU:                     quantity(lim, GPUResource, false),
+			})
+		}
+	}
+	return rows
+}
+
+// quantity returns a quantity in millicores or base units, nil when unset
+func quantity(list corev1.ResourceList, name corev1.ResourceName, milli bool) *int64 {
+	q, ok := list[name]
+	if !ok {
+		return nil
+	}
+	v := QuantityBytes(&q)
+	if milli {
+		v = QuantityMilli(&q)
+	}
+	return &v
+}
+
+// namespaceItems returns the namespace totals sorted by name
+func namespaceItems(totals map[string]Totals, namespaces *corev1.NamespaceList) []Namespace {
+	phases := make(map[string]string)
+	if namespaces != nil {
+		for _, ns := range namespaces.Items {
+			phase := ns.Status.Phase
+			if ns.DeletionTimestamp != nil {
+				phase = corev1.NamespaceTerminating
+			}
+			phases[ns.Name] = string(phase)
+		}
+	}
+
+	items := make([]Namespace, 0, len(totals))
+	for ns, t := range totals {
+		items = append(items, Namespace{
+			Name:                 ns,
+			Phase:                phases[ns],
+			RequestCPUMillicores: t.RequestCPU,
+			LimitCPUMillicores:   t.LimitCPU,
+			RequestMemoryBytes:   t.RequestMemory,
+			LimitMemoryBytes:     t.LimitMemory,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+// nodeItems returns the node totals sorted by name and IP
+func nodeItems(totals map[string]NodeTotals, nodes *corev1.NodeList) []Node {
+	pools := make(map[string]string)
+	if nodes != nil {
+		for i := range nodes.Items {
+			pools[nodes.Items[i].Name] = NodePool(&nodes.Items[i])
+		}
+	}
+
+	items := make([]Node, 0, len(totals))
+	for ip, t := range totals {
+		node := Node{
+			Name:                     t.Name,
+			IP:                       ip,
+			Pool:                     pools[t.Name],
+			Pods:                     t.Pods,
+			AllocatableCPUMillicores: t.AllocatableCPU,
+			RequestCPUMillicores:     t.RequestCPU,
+			LimitCPUMillicores:       t.LimitCPU,
+			AllocatableMemoryBytes:   t.AllocatableMemory,
+			RequestMemoryBytes:       t.RequestMemory,
+			LimitMemoryBytes:         t.LimitMemory,
+		}
+		if t.AllocatableCPU > 0 {
+			r := float64(t.RequestCPU) / float64(t.AllocatableCPU)
+			node.CPURequestRatio = &r
+		}
+		if t.AllocatableMemory > 0 {
+			r := float64(t.RequestMemory) / float64(t.AllocatableMemory)
+			node.MemoryRequestRatio = &r
+		}
+		items = append(items, node)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Name != items[j].Name {
+			return items[i].Name < items[j].Name
+		}
+		return items[i].IP < items[j].IP
+	})
+	return items
+}
@@ -0,0 +1,133 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPod(namespace, name, node, ip string, phase corev1.PodPhase, requests, limits corev1.ResourceList) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec: corev1.PodSpec{
+			NodeName:   node,
+			Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{Requests: requests, Limits: limits}}},
+		},
+		Status: corev1.PodStatus{Phase: phase, HostIP: ip},
+	}
+}
+
+func testResources(cpu, memory string) corev1.ResourceList {
+	return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}
+}
+
+func testNode(name, ip, pool, cpu, memory string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{NodePoolLabels[0]: pool}},
+		Status: corev1.NodeStatus{
+			Addresses:   []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: ip}},
+			Allocatable: testResources(cpu, memory),
+			Capacity:    testResources(cpu, memory),
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	pods := []corev1.Pod{
+		testPod("shop", "web-1", "node-a", "10.0.0.1", corev1.PodRunning, testResources("500m", "1Gi"), testResources("2", "2Gi")),
+		testPod("shop", "web-2", "", "", corev1.PodPending, testResources("500m", "1Gi"), nil),
+		testPod("batch", "job-1", "node-a", "10.0.0.1", corev1.PodSucceeded, testResources("4", "8Gi"), nil),
+	}
+	nodes := &corev1.NodeList{Items: []corev1.Node{testNode("node-a", "10.0.0.1", "general", "4", "8Gi")}}
+	namespaces := &corev1.NamespaceList{Items: []corev1.Namespace{{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", DeletionTimestamp: &metav1.Time{Time: now}},
+		Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+	}}}
+
+	r := Build(now, pods, namespaces, nodes, Options{
+		Cluster:    "prod",
+		TShirtSize: func(cpu, memory int64) string { return "M" },
+		Team:       func(pod *corev1.Pod) string { return "payments" },
+	})
+	if r.Cluster != "prod" || !r.Generated.Equal(now) {
+		t.Errorf("header = %q, %v", r.Cluster, r.Generated)
+	}
+	// The succeeded job is not active
+	if len(r.Containers) != 2 || len(r.Namespaces) != 1 || len(r.Nodes) != 2 {
+		t.Fatalf("containers, namespaces, nodes = %d, %d, %d", len(r.Containers), len(r.Namespaces), len(r.Nodes))
+	}
+	web := r.Containers[0]
+	if web.Pod != "web-1" || web.Team != "payments" || web.TShirtSize != "M" || web.NodePool != "general" ||
+		*web.RequestCPUMillicores != 500 || *web.LimitMemoryBytes != 2<<30 || web.RequestGPU != nil {
+		t.Errorf("web-1 = %+v", web)
+	}
+	if pending := r.Containers[1]; pending.LimitCPUMillicores != nil || pending.NodePool != "" {
+		t.Errorf("web-2 = %+v", pending)
+	}
+
+	shop := r.Namespaces[0]
+	if shop.Phase != string(corev1.NamespaceTerminating) || shop.RequestCPUMillicores != 1000 || shop.LimitCPUMillicores != 2000 {
+		t.Errorf("shop = %+v", shop)
+	}
+	// Sorted by name: the unscheduled pod has no node name
+	if unknown := r.Nodes[0]; unknown.IP != "Unknown" || unknown.Pods != 1 || unknown.CPURequestRatio != nil {
+		t.Errorf("unknown node = %+v", unknown)
+	}
+	if node := r.Nodes[1]; node.Name != "node-a" || node.Pool != "general" || node.CPURequestRatio == nil || *node.CPURequestRatio != 0.125 {
+		t.Errorf("node-a = %+v", node)
+	}
+
+	if r.Insights.CPUEfficiency == nil || *r.Insights.CPUEfficiency != 0.5 || r.Insights.CPURating != "⚡ Over-provisioned" {
+		t.Errorf("cpu efficiency = %v, %q", r.Insights.CPUEfficiency, r.Insights.CPURating)
+	}
+	if r.Insights.PotentialCPUSavingsMillicores != 1000 || r.Insights.LoadBalanceScore != 100 || len(r.Insights.Recommendations) == 0 {
+		t.Errorf("insights = %+v", r.Insights)
+	}
+}
+
+func TestBuildEmpty(t *testing.T) {
+	r := Build(time.Now(), nil, nil, nil, Options{})
+	if r.Containers == nil || r.Namespaces == nil || r.Nodes == nil {
+		t.Errorf("empty report has nil lists: %+v", r)
+	}
+	// No limits: no efficiency to rate
+	if r.Insights.CPUEfficiency != nil || r.Insights.MemoryRating != "" || len(r.Insights.Recommendations) == 0 {
+		t.Errorf("insights = %+v", r.Insights)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	pods := []corev1.Pod{testPod("shop", "web-1", "node-a", "10.0.0.1", corev1.PodRunning, testResources("500m", "1Gi"), nil)}
+	r := Build(now, pods, nil, nil, Options{})
+	r.Warnings = []string{"failed to list nodes"}
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	for _, key := range []string{"generated", "containers", "namespaces", "nodes", "insights"} {
+		if _, ok := doc[key]; !ok {
+			t.Errorf("missing key %q", key)
+		}
+	}
+	for _, key := range []string{"cluster", "Warnings"} {
+		if _, ok := doc[key]; ok {
+			t.Errorf("unexpected key %q", key)
+		}
+	}
+	container := doc["containers"].([]interface{})[0].(map[string]interface{})
+	if container["requestCpuMillicores"] != 500.0 || container["limitCpuMillicores"] != nil {
+		t.Errorf("container = %v", container)
+	}
+}
@@ -0,0 +1,166 @@
+package report
+
+import (
+	"math"
+)
+
+// DefaultScoringModel is the scoring model used when none is selected
+const DefaultScoringModel = "default"
+
+// NamespaceClass is the provisioning class of a namespace
+type NamespaceClass int
+
+const (
+	NamespaceOverProvisioned NamespaceClass = iota
+	NamespaceBalanced
+	NamespaceUnderProvisioned
+)
+
+// Score is what a scoring model rates. Efficiencies are request/limit in
+// percent, NaN without limits.
+type Score struct {
+	RequestCPU, LimitCPU       int64 // Millicores
+	RequestMemory, LimitMemory int64 // Bytes
+	CPUEfficiency              float64
+	MemoryEfficiency           float64
+	OverProvisioned            int     // Namespaces
+	Balanced                   int     // Namespaces
+	UnderProvisioned           int     // Namespaces
+	Balance                    float64 // Load Balance Score, 0-100
+}
+
+// Scoring rates request/limit efficiency and words the recommendations.
+// Ratings and recommendations are English.
+type Scoring interface {
+	// Rating labels a cluster efficiency
+	Rating(eff float64) string
+	// Classify places a namespace by its CPU and memory efficiency
+	Classify(cpuEff, memEff float64) NamespaceClass
+	// Recommendations lists the optimization advice, never empty
+	Recommendations(s Score) []string
+}
+
+// Thresholds is the built-in scoring model: fixed efficiency bands, all
+// request/limit in percent
+type Thresholds struct {
+	UnderProvisioned float64 // Ratings: from here limits are too tight
+	WellBalanced     float64 // Ratings: from here limits fit the requests
+	OverProvisioned  float64 // Ratings: from here limits are generous, below severely
+	NamespaceOver    float64 // Namespaces below are over-provisioned; also the cluster recommendations
+	NamespaceUnder   float64 // Namespaces above are under-provisioned
+	MinBalance       float64 // Load Balance Score below which spreading is advised
+}
+
+// ScoringModels are the selectable scoring models
+var ScoringModels = map[string]Thresholds{
+	DefaultScoringModel: {UnderProvisioned: 80, WellBalanced: 60, OverProvisioned: 40, NamespaceOver: 50, NamespaceUnder: 80, MinBalance: 70},
+	// For clusters that pay for every idle core: limits must stay close to requests
+	"strict": {UnderProvisioned: 90, WellBalanced: 75, OverProvisioned: 60, NamespaceOver: 70, NamespaceUnder: 90, MinBalance: 80},
+	// For bursty workloads where generous limits are intended
+	"lenient": {UnderProvisioned: 70, WellBalanced: 40, OverProvisioned: 20, NamespaceOver: 30, NamespaceUnder: 70, MinBalance: 60},
+}
+
+// Rating implements Scoring
+func (m Thresholds) Rating(eff float64) string {
+	switch {
+	case eff >= m.UnderProvisioned:
+		return "⚠️ Under-provisioned"
+	case eff >= m.WellBalanced:
+		return "✅ Well-balanced"
+	case eff >= m.OverProvisioned:
+		return "⚡ Over-provisioned"
+	default:
+		return "🔴 Severely over-provisioned"
+	}
+}
+
+// Classify implements Scoring
+func (m Thresholds) Classify(cpuEff, memEff float64) NamespaceClass {
+	avgEff := (cpuEff + memEff) / 2
+	switch {
+	case avgEff < m.NamespaceOver:
+		return NamespaceOverProvisioned
+	case avgEff > m.NamespaceUnder:
+		return NamespaceUnderProvisioned
+	default:
+		return NamespaceBalanced
+	}
+}
+
+// Recommendations implements Scoring
+func (m Thresholds) Recommendations(s Score) []string {
+	var recs []string
+
+	if s.CPUEfficiency < m.NamespaceOver {
+		recs = append(recs, "Consider reducing CPU limits - cluster is over-provisioned")
+	}
+	if s.MemoryEfficiency < m.NamespaceOver {
+		recs = append(recs, "Consider reducing Memory limits - cluster is over-provisioned")
+	}
+	if s.CPUEfficiency > m.NamespaceUnder {
+		recs = append(recs, "⚠️ CPU limits too tight - risk of throttling")
+	}
+	if s.MemoryEfficiency > m.NamespaceUnder {
+		recs = append(recs, "⚠️ Memory limits too tight - risk of OOM kills")
+	}
+	if s.OverProvisioned > s.UnderProvisioned {
+		recs = append(recs, "Focus on right-sizing over-provisioned namespaces first")
+	}
+	if s.Balance < m.MinBalance {
+		recs = append(recs, "Consider pod anti-affinity rules for better node distribution")
+	}
+	if len(recs) == 0 {
+		recs = append(recs, "✅ Cluster resource allocation looks well-balanced!")
+	}
+
+	return recs
+}
+
+// ScoreCluster sums the namespace requests and limits, classifies the
+// namespaces with model and rates the spread of pods over the nodes
+func ScoreCluster(namespaceTotals map[string]Totals, podsPerNode []int, model Scoring) Score {
+	var s Score
+	for _, totals := range namespaceTotals {
+		s.RequestCPU += totals.RequestCPU
+		s.LimitCPU += totals.LimitCPU
+		s.RequestMemory += totals.RequestMemory
+		s.LimitMemory += totals.LimitMemory
+
+		cpuEff := float64(totals.RequestCPU) / float64(totals.LimitCPU) * 100
+		memEff := float64(totals.RequestMemory) / float64(totals.LimitMemory) * 100
+		switch model.Classify(cpuEff, memEff) {
+		case NamespaceOverProvisioned:
+			s.OverProvisioned++
+		case NamespaceUnderProvisioned:
+			s.UnderProvisioned++
+		default:
+			s.Balanced++
+		}
+	}
+	s.CPUEfficiency = float64(s.RequestCPU) / float64(s.LimitCPU) * 100
+	s.MemoryEfficiency = float64(s.RequestMemory) / float64(s.LimitMemory) * 100
+	s.Balance = BalanceScore(podsPerNode)
+	return s
+}
+
+// BalanceScore rates the spread of pods over the nodes from 0 to 100, where
+// 100 is an equal pod count on every node
+func BalanceScore(podsPerNode []int) float64 {
+	if len(podsPerNode) <= 1 {
+		return 100
+	}
+	var sum float64
+	for _, n := range podsPerNode {
+		sum += float64(n)
+	}
+	avg := sum / float64(len(podsPerNode))
+	if avg == 0 {
+		return 100
+	}
+	var variance float64
+	for _, n := range podsPerNode {
+		variance += (float64(n) - avg) * (float64(n) - avg)
+	}
+	cv := math.Sqrt(variance/float64(len(podsPerNode))) / avg // Coefficient of variation
+	return math.Max(0, 100-(cv*100))                          // Lower CV = better balance
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestThresholds(t *testing.T) {
+	m := ScoringModels[DefaultScoringModel]
+	ratings := map[float64]string{
+		95: "⚠️ Under-provisioned",
+		80: "⚠️ Under-provisioned",
+		65: "✅ Well-balanced",
+		45: "⚡ Over-provisioned",
+		10: "🔴 Severely over-provisioned",
+	}
+	for eff, want := range ratings {
+		if got := m.Rating(eff); got != want {
+			t.Errorf("Rating(%g) = %q, want %q", eff, got, want)
+		}
+	}
+	if got := ScoringModels["strict"].Rating(65); got != "⚡ Over-provisioned" {
+		t.Errorf("strict Rating(65) = %q", got)
+	}
+
+	classes := []struct {
+		cpuEff, memEff float64
+		want           NamespaceClass
+	}{
+		{20, 60, NamespaceOverProvisioned},
+		{50, 70, NamespaceBalanced},
+		{80, 80, NamespaceBalanced},
+		{90, 100, NamespaceUnderProvisioned},
+	}
+	for _, tt := range classes {
+		if got := m.Classify(tt.cpuEff, tt.memEff); got != tt.want {
+			t.Errorf("Classify(%g, %g) = %v, want %v", tt.cpuEff, tt.memEff, got, tt.want)
+		}
+	}
+
+	recs := m.Recommendations(Score{CPUEfficiency: 30, MemoryEfficiency: 90, OverProvisioned: 3, UnderProvisioned: 1, Balance: 50})
+	want := []string{
+		"Consider reducing CPU limits - cluster is over-provisioned",
+		"⚠️ Memory limits too tight - risk of OOM kills",
+		"Focus on right-sizing over-provisioned namespaces first",
+		"Consider pod anti-affinity rules for better node distribution",
+	}
+	if !reflect.DeepEqual(recs, want) {
+		t.Errorf("Recommendations() = %q, want %q", recs, want)
+	}
+	if recs := m.Recommendations(Score{CPUEfficiency: 65, MemoryEfficiency: 65, Balance: 90}); len(recs) != 1 || recs[0] != "✅ Cluster resource allocation looks well-balanced!" {
+		t.Errorf("Recommendations(balanced) = %q", recs)
+	}
+}
+
+func TestBalanceScore(t *testing.T) {
+	tests := []struct {
+		name        string
+		podsPerNode []int
+		want        float64
+	}{
+		{"no nodes", nil, 100},
+		{"single node", []int{7}, 100},
+		{"empty nodes", []int{0, 0}, 100},
+		{"even", []int{4, 4, 4}, 100},
+		{"uneven", []int{1, 3}, 50},
+		{"one node loaded", []int{0, 0, 0, 9}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BalanceScore(tt.podsPerNode); got != tt.want {
+				t.Errorf("BalanceScore(%v) = %g, want %g", tt.podsPerNode, got, tt.want)
+			}
+		})
+	}
+}
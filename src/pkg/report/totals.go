@@ -0,0 +1,83 @@
+package report
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Totals are summed container requests and limits
+type Totals struct {
+	RequestCPU, LimitCPU       int64 // Millicores
+	RequestMemory, LimitMemory int64 // Bytes
+}
+
+// add sums the requests and limits of a container
+func (t *Totals) add(c corev1.Container) {
+	t.RequestCPU += QuantityMilli(c.Resources.Requests.Cpu())
+	t.LimitCPU += QuantityMilli(c.Resources.Limits.Cpu())
+	t.RequestMemory += QuantityBytes(c.Resources.Requests.Memory())
+	t.LimitMemory += QuantityBytes(c.Resources.Limits.Memory())
+}
+
+// NodeTotals are the requests and limits of the pods scheduled on a node
+// together with its capacity
+type NodeTotals struct {
+	Totals
+	Pods                              int
+	CapacityCPU, CapacityMemory       int64 // Capacity (total)
+	AllocatableCPU, AllocatableMemory int64 // Allocatable (capacity - system reservations)
+	Name, IP                          string
+}
+
+// Aggregate sums the container requests and limits of active pods per
+// namespace and per node, keyed by host IP ("Unknown" for unscheduled pods),
+// with the node capacity from the nodes list (nil when it is unavailable)
+func Aggregate(pods []corev1.Pod, nodes *corev1.NodeList) (map[string]Totals, map[string]NodeTotals) {
+	namespaceTotals := make(map[string]Totals)
+	nodeTotals := make(map[string]NodeTotals)
+	for i := range pods {
+		pod := &pods[i]
+		if !IsActive(pod) {
+			continue
+		}
+
+		ip := pod.Status.HostIP
+		if ip == "" {
+			ip = "Unknown"
+		}
+		nodeSum := nodeTotals[ip]
+		nodeSum.Pods++
+		nodeSum.IP = ip
+		nodeSum.Name = pod.Spec.NodeName
+
+		ns := pod.Namespace
+		if ns == "" {
+			ns = "default"
+		}
+		nsSum := namespaceTotals[ns]
+		for _, container := range pod.Spec.Containers {
+			nsSum.add(container)
+			nodeSum.add(container)
+		}
+		namespaceTotals[ns] = nsSum
+		nodeTotals[ip] = nodeSum
+	}
+
+	// Populate node capacity from nodes list
+	if nodes != nil {
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			// Match by node name or IP
+			for ip, totals := range nodeTotals {
+				if totals.Name == node.Name || ip == NodeIP(node) {
+					totals.CapacityCPU = QuantityMilli(node.Status.Capacity.Cpu())
+					totals.CapacityMemory = QuantityBytes(node.Status.Capacity.Memory())
+					totals.AllocatableCPU = QuantityMilli(node.Status.Allocatable.Cpu())
+					totals.AllocatableMemory = QuantityBytes(node.Status.Allocatable.Memory())
+					nodeTotals[ip] = totals
+					break
+				}
+			}
+		}
+	}
+	return namespaceTotals, nodeTotals
+}
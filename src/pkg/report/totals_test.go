@@ -0,0 +1,36 @@
+package report
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestAggregate(t *testing.T) {
+	pods := []corev1.Pod{
+		testPod("shop", "web-1", "node-a", "10.0.0.1", corev1.PodRunning, testResources("500m", "1Gi"), testResources("1", "2Gi")),
+		testPod("shop", "web-2", "node-b", "10.0.0.2", corev1.PodRunning, testResources("250m", "512Mi"), nil),
+		testPod("", "orphan", "", "", corev1.PodPending, testResources("100m", "64Mi"), nil),
+		testPod("shop", "done", "node-a", "10.0.0.1", corev1.PodFailed, testResources("8", "16Gi"), nil),
+	}
+	// node-b matches by IP only
+	nodeB := testNode("renamed", "10.0.0.2", "general", "2", "4Gi")
+	nodes := &corev1.NodeList{Items: []corev1.Node{testNode("node-a", "10.0.0.1", "general", "4", "8Gi"), nodeB}}
+
+	namespaceTotals, nodeTotals := Aggregate(pods, nodes)
+	if shop := namespaceTotals["shop"]; shop != (Totals{RequestCPU: 750, LimitCPU: 1000, RequestMemory: 1536 << 20, LimitMemory: 2 << 30}) {
+		t.Errorf("shop = %+v", shop)
+	}
+	if _, ok := namespaceTotals["default"]; !ok {
+		t.Errorf("pod without namespace not counted as default: %v", namespaceTotals)
+	}
+	if a := nodeTotals["10.0.0.1"]; a.Pods != 1 || a.Name != "node-a" || a.AllocatableCPU != 4000 || a.RequestCPU != 500 {
+		t.Errorf("node-a = %+v", a)
+	}
+	if b := nodeTotals["10.0.0.2"]; b.AllocatableMemory != 4<<30 || b.Name != "node-b" {
+		t.Errorf("node-b = %+v", b)
+	}
+	if unknown := nodeTotals["Unknown"]; unknown.Pods != 1 || unknown.AllocatableCPU != 0 {
+		t.Errorf("unknown = %+v", unknown)
+	}
+}
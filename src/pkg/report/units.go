@@ -0,0 +1,41 @@
+package report
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Unit conversion factors. Memory columns always use binary (IEC) units, so a
+// decimal request like "128M" (128,000,000 bytes) is shown as 122.07 Mi.
+const (
+	BytesPerKi        = 1024
+	BytesPerMi        = 1024 * BytesPerKi
+	BytesPerGi        = 1024 * BytesPerMi
+	MilliCoresPerCore = 1000
+)
+
+// MilliToCores converts millicores to cores
+func MilliToCores(milli int64) float64 {
+	return float64(milli) / MilliCoresPerCore
+}
+
+// BytesToMi converts bytes to mebibytes
+func BytesToMi(bytes int64) float64 {
+	return float64(bytes) / BytesPerMi
+}
+
+// QuantityMilli returns a CPU quantity in millicores, 0 for unset quantities.
+// Sub-millicore values are rounded up like the Kubernetes scheduler does.
+func QuantityMilli(q *resource.Quantity) int64 {
+	if q == nil {
+		return 0
+	}
+	return q.MilliValue()
+}
+
+// QuantityBytes returns a memory or storage quantity in bytes, 0 for unset quantities
+func QuantityBytes(q *resource.Quantity) int64 {
+	if q == nil {
+		return 0
+	}
+	return q.Value()
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestQuantities(t *testing.T) {
+	tests := []struct {
+		quantity  string
+		wantMilli int64
+		wantBytes int64
+	}{
+		{"250m", 250, 1},
+		{"2", 2000, 2},
+		{"0.0001", 1, 1}, // Sub-millicore and sub-byte values round up
+		{"128M", 128000000000, 128000000},
+		{"1Gi", 1073741824000, 1073741824},
+	}
+	for _, tt := range tests {
+		q := resource.MustParse(tt.quantity)
+		if got := QuantityMilli(&q); got != tt.wantMilli {
+			t.Errorf("QuantityMilli(%s) = %d, want %d", tt.quantity, got, tt.wantMilli)
+		}
+		if got := QuantityBytes(&q); got != tt.wantBytes {
+			t.Errorf("QuantityBytes(%s) = %d, want %d", tt.quantity, got, tt.wantBytes)
+		}
+	}
+
+	if QuantityMilli(nil) != 0 || QuantityBytes(nil) != 0 {
+		t.Error("unset quantities must be 0")
+	}
+}
+
+func TestConversions(t *testing.T) {
+	if got := MilliToCores(1500); got != 1.5 {
+		t.Errorf("MilliToCores(1500) = %v, want 1.5", got)
+	}
+	if got := BytesToMi(128000000); got < 122.07 || got > 122.08 {
+		t.Errorf("BytesToMi(128M) = %v, want 122.07", got)
+	}
+	if BytesPerGi != 1<<30 {
+		t.Errorf("BytesPerGi = %d", BytesPerGi)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// setPrintLayout prepares every sheet for printing: landscape, fit to one page wide,
+// repeated header rows and page headers/footers with report metadata.
+// titleRows overrides the number of repeated top rows per sheet (default 1, 0 disables).
+func setPrintLayout(f *excelize.File, meta reportMetadata, titleRows map[string]int) error {
+	orientation := "landscape"
+	fitWidth, fitHeight := 1, 0 // 0 = as many pages tall as needed
+	fitToPage := true
+
+	title := "Pod Resource Report"
+	if meta.cluster.name != "" {
+		title += " - " + meta.cluster.name
+	}
+	header := fmt.Sprintf("&L&B%s&B&C&A&RGenerated %s", escapeHeaderText(title), meta.generated.Format("2006-01-02 15:04 MST"))
+	footer := "&LPodResourceCalculator&RPage &P of &N"
+
+	for _, sheet := range f.GetSheetList() {
+		if err := f.SetSheetProps(sheet, &excelize.SheetPropsOptions{FitToPage: &fitToPage}); err != nil {
+			return fmt.Errorf("failed to enable fit to page on sheet %s: %w", sheet, err)
+		}
+		if err := f.SetPageLayout(sheet, &excelize.PageLayoutOptions{
+			Orientation: &orientation,
+			FitToWidth:  &fitWidth,
+			FitToHeight: &fitHeight,
+		}); err != nil {
+			return fmt.Errorf("failed to set page layout on sheet %s: %w", sheet, err)
+		}
+		if err := f.SetHeaderFooter(sheet, &excelize.HeaderFooterOptions{
+			OddHeader: header,
+			OddFooter: footer,
+		}); err != nil {
+			return fmt.Errorf("failed to set header/footer on sheet %s: %w", sheet, err)
+		}
+
+		rows, ok := titleRows[sheet]
+		if !ok {
+			rows = 1
+		}
+		if rows == 0 {
+			continue
+		}
+		if err := f.SetDefinedName(&excelize.DefinedName{
+			Name:     "_xlnm.Print_Titles",
+			RefersTo: fmt.Sprintf("'%s'!$1:$%d", strings.ReplaceAll(sheet, "'", "''"), rows),
+			Scope:    sheet,
+		}); err != nil {
+			return fmt.Errorf("failed to set print titles on sheet %s: %w", sheet, err)
+		}
+	}
+	return nil
+}
+
+// escapeHeaderText escapes '&', which starts a control code in page headers and footers
+func escapeHeaderText(s string) string {
+	return strings.ReplaceAll(s, "&", "&&")
+}
@@ -0,0 +1,18 @@
+package main
+
+import "testing"
+
+func TestEscapeHeaderText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"prod-cluster", "prod-cluster"},
+		{"R&D", "R&&D"},
+		{"&&", "&&&&"},
+	}
+	for _, tt := range tests {
+		if got := escapeHeaderText(tt.in); got != tt.want {
+			t.Errorf("escapeHeaderText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	rpprof "runtime/pprof"
+	"runtime/trace"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// PathPprof is the prefix of the pprof endpoints on the -pprof listener
+const PathPprof = "/debug/pprof/"
+
+// startProfiling writes a CPU profile to cpuPath and an execution trace to
+// tracePath, each when set. The returned stop function finishes both files; it
+// is safe to call more than once, also from a fatal log exit.
+func startProfiling(cpuPath, tracePath string) (func(), error) {
+	var files []*os.File
+	var stops []func()
+	stop := func() {
+		for _, s := range stops {
+			s()
+		}
+		for _, f := range files {
+			if err := f.Close(); err != nil {
+				logrus.Warnf("Failed to write %s: %v", f.Name(), err)
+				continue
+			}
+			logrus.Infof("Profile written: %s", f.Name())
+		}
+	}
+
+	if cpuPath != "" {
+		f, err := os.Create(cpuPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		files = append(files, f)
+		if err := rpprof.StartCPUProfile(f); err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		stops = append(stops, rpprof.StopCPUProfile)
+	}
+	if tracePath != "" {
+		f, err := os.Create(tracePath)
+		if err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to create trace: %w", err)
+		}
+		files = append(files, f)
+		if err := trace.Start(f); err != nil {
+			stop()
+			return nil, fmt.Errorf("failed to start trace: %w", err)
+		}
+		stops = append(stops, trace.Stop)
+	}
+
+	var once sync.Once
+	return func() { once.Do(stop) }, nil
+}
+
+// pprofHandler serves the pprof endpoints: heap, goroutine and other profiles
+// under PathPprof, a CPU profile at profile?seconds=N and a trace at trace
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathPprof, pprof.Index)
+	mux.HandleFunc(PathPprof+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(PathPprof+"profile", pprof.Profile)
+	mux.HandleFunc(PathPprof+"symbol", pprof.Symbol)
+	mux.HandleFunc(PathPprof+"trace", pprof.Trace)
+	return mux
+}
+
+// servePprof serves the pprof endpoints on their own address, so they are not
+// exposed with the report port
+func servePprof(addr string) {
+	server := &http.Server{Addr: addr, Handler: pprofHandler(), ReadHeaderTimeout: DefaultAPITimeout}
+	logrus.Infof("Serving pprof on %s%s", addr, PathPprof)
+	if err := server.ListenAndServe(); err != nil {
+		logrus.Errorf("pprof server failed: %v", err)
+	}
+}
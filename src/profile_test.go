@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStartProfiling(t *testing.T) {
+	dir := t.TempDir()
+	cpuPath, tracePath := filepath.Join(dir, "cpu.out"), filepath.Join(dir, "trace.out")
+	stop, err := startProfiling(cpuPath, tracePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop()
+	stop() // Idempotent: deferred and from the fatal exit handler
+	for _, path := range []string{cpuPath, tracePath} {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == 0 {
+			t.Errorf("%s not written: %v", path, err)
+		}
+	}
+
+	stop, err = startProfiling("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop()
+
+	if _, err := startProfiling(filepath.Join(dir, "missing", "cpu.out"), ""); err == nil {
+		t.Error("expected an error for an unwritable profile path")
+	}
+}
+
+func TestPprofHandler(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+	}{
+		{PathPprof, http.StatusOK},
+		{PathPprof + "heap", http.StatusOK},
+		{PathPprof + "goroutine?debug=1", http.StatusOK},
+		{PathPprof + "cmdline", http.StatusOK},
+		{"/report.xlsx", http.StatusNotFound},
+	}
+	handler := pprofHandler()
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Prometheus query defaults
+const (
+	DefaultPrometheusQuantiles = "0.95,0.99"
+	DefaultPrometheusDays      = 7
+	DefaultPrometheusTimeout   = 2 * time.Minute // Quantiles over days of samples are slow
+	PrometheusStep             = "5m"            // Rate window and subquery resolution
+	MaxPrometheusResponse      = 64 << 20        // Bytes
+)
+
+// prometheusSource queries historical container usage quantiles from the
+// cAdvisor metrics scraped by Prometheus
+type prometheusSource struct {
+	client    *http.Client
+	url       string // Base URL, e.g. http://prometheus.monitoring:9090
+	token     string // Optional bearer token (PROMETHEUS_TOKEN)
+	quantiles []float64
+	days      int // Lookback window
+}
+
+// newPrometheusSource validates the -prometheus-* flags; an empty URL yields
+// nil, disabling the quantile columns
+func newPrometheusSource(rawURL, quantiles string, days int) (*prometheusSource, error) {
+	if rawURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("'%s' is not an http(s) URL", rawURL)
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("lookback must be at least one day")
+	}
+	p := &prometheusSource{
+		client: &http.Client{Timeout: DefaultPrometheusTimeout},
+		url:    strings.TrimSuffix(rawURL, "/"),
+		token:  os.Getenv("PROMETHEUS_TOKEN"),
+		days:   days,
+	}
+	for _, field := range strings.Split(quantiles, ",") {
+		q, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || q <= 0 || q >= 1 {
+			return nil, fmt.Errorf("invalid quantile '%s', must be between 0 and 1 exclusive", field)
+		}
+		p.quantiles = append(p.quantiles, q)
+	}
+	return p, nil
+}
+
+// quantileLabel names a quantile as a percentile, e.g. 0.95 as P95
+func quantileLabel(q float64) string {
+	return "P" + strconv.FormatFloat(math.Round(q*1e4)/1e2, 'f', -1, 64)
+}
+
+// queries returns the PromQL of the CPU (cores) and memory (working set bytes)
+// quantiles per container over the lookback window
+func (p *prometheusSource) queries(q float64, namespace string) (cpu, mem string) {
+	selector := `container!="",container!="POD"`
+	if namespace != "" {
+		selector += fmt.Sprintf(",namespace=%q", namespace)
+	}
+	window := fmt.Sprintf("%dd", p.days)
+	cpu = fmt.Sprintf("max by (namespace, pod, container) (quantile_over_time(%g, rate(container_cpu_usage_seconds_total{%s}[%s])[%s:%s]))",
+		q, selector, PrometheusStep, window, PrometheusStep)
+	mem = fmt.Sprintf("max by (namespace, pod, container) (quantile_over_time(%g, container_memory_working_set_bytes{%s}[%s]))",
+		q, selector, window)
+	return cpu, mem
+}
+
+// promResponse is the part of a Prometheus instant query response that is read
+type promResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  [2]interface{}    `json:"value"` // [unix time, "value"]
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// parsePromVector reads an instant vector by "namespace/pod/container";
+// NaN samples are skipped
+func parsePromVector(data []byte) (map[string]float64, error) {
+	var resp promResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse Prometheus response: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: %s", resp.Error)
+	}
+	if resp.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("prometheus returned a %s, not a vector", resp.Data.ResultType)
+	}
+	values := make(map[string]float64, len(resp.Data.Result))
+	for _, sample := range resp.Data.Result {
+		text, ok := sample.Value[1].(string)
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(text, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		m := sample.Metric
+		values[m["namespace"]+"/"+m["pod"]+"/"+m["container"]] = v
+	}
+	return values, nil
+}
+
+// query runs an instant query
+func (p *prometheusSource) query(ctx context.Context, promQL string) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/api/v1/query?"+url.Values{"query": {promQL}}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query Prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxPrometheusResponse))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Prometheus response: %w", err)
+	}
+	// Query errors come with a 4xx/5xx status and a JSON error body
+	if resp.StatusCode != http.StatusOK && !json.Valid(data) {
+		return nil, fmt.Errorf("prometheus returned %s", resp.Status)
+	}
+	return parsePromVector(data)
+}
+
+// fetch queries the usage quantiles of the containers of namespace (all when
+// empty)
+func (p *prometheusSource) fetch(ctx context.Context, namespace string) (*usageQuantiles, error) {
+	u := &usageQuantiles{quantiles: p.quantiles, containers: make(map[string][]metricsSample)}
+	for i, q := range p.quantiles {
+		cpuQuery, memQuery := p.queries(q, namespace)
+		cpu, err := p.query(ctx, cpuQuery)
+		if err != nil {
+			return nil, err
+		}
+		mem, err := p.query(ctx, memQuery)
+		if err != nil {
+			return nil, err
+		}
+		sample := func(key string) *metricsSample {
+			if _, ok := u.containers[key]; !ok {
+				u.containers[key] = make([]metricsSample, len(p.quantiles))
+			}
+			return &u.containers[key][i]
+		}
+		for key, cores := range cpu {
+			sample(key).cpu = int64(math.Ceil(cores * 1000))
+		}
+		for key, bytes := range mem {
+			sample(key).mem = int64(bytes)
+		}
+	}
+	return u, nil
+}
+
+// usageQuantiles are historical usage quantiles per "namespace/pod/container",
+// one sample per quantile
+type usageQuantiles struct {
+	quantiles  []float64
+	containers map[string][]metricsSample
+}
+
+// headers returns the Resources sheet columns, CPU and memory per quantile
+func (u *usageQuantiles) headers() []string {
+	headers := make([]string, 0, 2*len(u.quantiles))
+	for _, q := range u.quantiles {
+		label := quantileLabel(q)
+		headers = append(headers, "CPU "+label+" (m)", "Memory "+label+" (Mi)")
+	}
+	return headers
+}
+
+// columns returns the quantile cells of a container row; cells stay empty for
+// containers without history
+func (u *usageQuantiles) columns(namespace, pod, container string) []interface{} {
+	cells := make([]interface{}, 2*len(u.quantiles))
+	samples, ok := u.containers[namespace+"/"+pod+"/"+container]
+	if !ok {
+		return cells
+	}
+	for i, s := range samples {
+		cells[2*i], cells[2*i+1] = s.cpu, bytesToWholeMi(s.mem)
+	}
+	return cells
+}
+
+// retain drops the quantiles of containers not in pods, such as deleted pods
+// or opted-out namespaces
+func (u *usageQuantiles) retain(pods []corev1.Pod) *usageQuantiles {
+	if u == nil {
+		return nil
+	}
+	kept := &usageQuantiles{quantiles: u.quantiles, containers: make(map[string][]metricsSample)}
+	for i := range pods {
+		pod := &pods[i]
+		for _, c := range podContainers(pod) {
+			key := pod.Namespace + "/" + pod.Name + "/" + c.Name
+			if samples, ok := u.containers[key]; ok {
+				kept.containers[key] = samples
+			}
+		}
+	}
+	return kept
+}
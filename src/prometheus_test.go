@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewPrometheusSource(t *testing.T) {
+	if p, err := newPrometheusSource("", DefaultPrometheusQuantiles, DefaultPrometheusDays); p != nil || err != nil {
+		t.Errorf("newPrometheusSource(\"\") = %+v, %v; want nil", p, err)
+	}
+	p, err := newPrometheusSource("http://prometheus:9090/", " 0.5, 0.999", 14)
+	if err != nil {
+		t.Fatalf("newPrometheusSource() error = %v", err)
+	}
+	if p.url != "http://prometheus:9090" || !reflect.DeepEqual(p.quantiles, []float64{0.5, 0.999}) || p.days != 14 {
+		t.Errorf("newPrometheusSource() = %+v", p)
+	}
+	for _, tt := range []struct {
+		url, quantiles string
+		days           int
+	}{
+		{"prometheus:9090", "0.95", 7},
+		{"ftp://prometheus", "0.95", 7},
+		{"http://prometheus", "0.95,1", 7},
+		{"http://prometheus", "p95", 7},
+		{"http://prometheus", "0.95", 0},
+	} {
+		if _, err := newPrometheusSource(tt.url, tt.quantiles, tt.days); err == nil {
+			t.Errorf("newPrometheusSource(%q, %q, %d) expected error", tt.url, tt.quantiles, tt.days)
+		}
+	}
+}
+
+func TestQuantileLabel(t *testing.T) {
+	for q, want := range map[float64]string{0.95: "P95", 0.99: "P99", 0.5: "P50", 0.999: "P99.9"} {
+		if got := quantileLabel(q); got != want {
+			t.Errorf("quantileLabel(%g) = %q, want %q", q, got, want)
+		}
+	}
+}
+
+func TestPrometheusQueries(t *testing.T) {
+	p := &prometheusSource{days: 7}
+	cpu, mem := p.queries(0.95, "shop")
+	wantCPU := `max by (namespace, pod, container) (quantile_over_time(0.95, rate(container_cpu_usage_seconds_total{container!="",container!="POD",namespace="shop"}[5m])[7d:5m]))`
+	wantMem := `max by (namespace, pod, container) (quantile_over_time(0.95, container_memory_working_set_bytes{container!="",container!="POD",namespace="shop"}[7d]))`
+	if cpu != wantCPU {
+		t.Errorf("cpu query = %s, want %s", cpu, wantCPU)
+	}
+	if mem != wantMem {
+		t.Errorf("memory query = %s, want %s", mem, wantMem)
+	}
+}
+
+func TestParsePromVector(t *testing.T) {
+	data := []byte(`{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"namespace":"shop","pod":"web-1","container":"app"},"value":[1700000000,"0.2504"]},
+		{"metric":{"namespace":"shop","pod":"web-1","container":"proxy"},"value":[1700000000,"NaN"]}]}}`)
+	got, err := parsePromVector(data)
+	if err != nil {
+		t.Fatalf("parsePromVector() error = %v", err)
+	}
+	if want := map[string]float64{"shop/web-1/app": 0.2504}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePromVector() = %v, want %v", got, want)
+	}
+	for _, data := range []string{
+		`{"status":"error","error":"parse error"}`,
+		`{"status":"success","data":{"resultType":"matrix","result":[]}}`,
+		`not json`,
+	} {
+		if _, err := parsePromVector([]byte(data)); err == nil {
+			t.Errorf("parsePromVector(%s) expected error", data)
+		}
+	}
+}
+
+func TestPrometheusFetch(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		value := "314572800" // 300 Mi
+		if strings.Contains(r.URL.Query().Get("query"), "container_cpu_usage_seconds_total") {
+			value = "0.1201"
+		}
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"namespace":"shop","pod":"web-1","container":"app"},"value":[1700000000,"` + value + `"]}]}}`))
+	}))
+	defer server.Close()
+
+	p, err := newPrometheusSource(server.URL, "0.95", 7)
+	if err != nil {
+		t.Fatalf("newPrometheusSource() error = %v", err)
+	}
+	p.token = "secret"
+	u, err := p.fetch(context.Background(), "")
+	if err != nil {
+		t.Fatalf("fetch() error = %v", err)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q", auth)
+	}
+	if want := map[string][]metricsSample{"shop/web-1/app": {{cpu: 121, mem: 300 << 20}}}; !reflect.DeepEqual(u.containers, want) {
+		t.Errorf("fetch() = %+v, want %+v", u.containers, want)
+	}
+}
+
+func TestUsageQuantilesColumns(t *testing.T) {
+	u := &usageQuantiles{quantiles: []float64{0.95, 0.99}, containers: map[string][]metricsSample{
+		"shop/web-1/app":   {{cpu: 120, mem: 256 << 20}, {cpu: 300, mem: 512 << 20}},
+		"shop/gone-1/app":  {{cpu: 1}, {cpu: 2}},
+		"other/job-1/main": {{cpu: 5}, {cpu: 6}},
+	}}
+	if got, want := u.headers(), []string{"CPU P95 (m)", "Memory P95 (Mi)", "CPU P99 (m)", "Memory P99 (Mi)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("headers() = %v, want %v", got, want)
+	}
+	want := []interface{}{int64(120), bytesToWholeMi(256 << 20), int64(300), bytesToWholeMi(512 << 20)}
+	if got := u.columns("shop", "web-1", "app"); !reflect.DeepEqual(got, want) {
+		t.Errorf("columns() = %v, want %v", got, want)
+	}
+	if got := u.columns("shop", "web-1", "proxy"); !reflect.DeepEqual(got, []interface{}{nil, nil, nil, nil}) {
+		t.Errorf("columns(no history) = %v", got)
+	}
+
+	pods := []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: "web-1"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	}}
+	kept := u.retain(pods)
+	if len(kept.containers) != 1 || kept.containers["shop/web-1/app"] == nil || !reflect.DeepEqual(kept.quantiles, u.quantiles) {
+		t.Errorf("retain() = %+v", kept)
+	}
+	if (*usageQuantiles)(nil).retain(pods) != nil {
+		t.Error("nil retain() should stay nil")
+	}
+}
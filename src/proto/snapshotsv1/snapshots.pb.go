@@ -0,0 +1,1076 @@
+// Server mode gRPC service (-grpc-listen), implemented in grpcapi.go. The list
+// methods mirror the REST API endpoints: the request holds the query
+// parameters and the response is one page of items. Regenerate the Go code in
+// snapshotsv1 with `make proto`.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: snapshots.proto
+
+package snapshotsv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Snapshot is every view of one cluster snapshot, unpaginated
+type Snapshot struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Generated       *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=generated,proto3" json:"generated,omitempty"`
+	Namespaces      []*NamespaceTotals     `protobuf:"bytes,2,rep,name=namespaces,proto3" json:"namespaces,omitempty"`
+	Nodes           []*NodeTotals          `protobuf:"bytes,3,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Recommendations []*Recommendation      `protobuf:"bytes,4,rep,name=recommendations,proto3" json:"recommendations,omitempty"` // Most severe first
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_snapshots_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Snapshot) GetGenerated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Generated
+	}
+	return nil
+}
+
+func (x *Snapshot) GetNamespaces() []*NamespaceTotals {
+	if x != nil {
+		return x.Namespaces
+	}
+	return nil
+}
+
+func (x *Snapshot) GetNodes() []*NodeTotals {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *Snapshot) GetRecommendations() []*Recommendation {
+	if x != nil {
+		return x.Recommendations
+	}
+	return nil
+}
+
+// NamespaceTotals is the request and limit total of a namespace
+type NamespaceTotals struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Name                 string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Phase                string                 `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"` // Active or Terminating, empty when namespaces could not be listed
+	RequestCpuMillicores int64                  `protobuf:"varint,3,opt,name=request_cpu_millicores,json=requestCpuMillicores,proto3" json:"request_cpu_millicores,omitempty"`
+	LimitCpuMillicores   int64                  `protobuf:"varint,4,opt,name=limit_cpu_millicores,json=limitCpuMillicores,proto3" json:"limit_cpu_millicores,omitempty"`
+	RequestMemoryBytes   int64                  `protobuf:"varint,5,opt,name=request_memory_bytes,json=requestMemoryBytes,proto3" json:"request_memory_bytes,omitempty"`
+	LimitMemoryBytes     int64                  `protobuf:"varint,6,opt,name=limit_memory_bytes,json=limitMemoryBytes,proto3" json:"limit_memory_bytes,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *NamespaceTotals) Reset() {
+	*x = NamespaceTotals{}
+	mi := &file_snapshots_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NamespaceTotals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NamespaceTotals) ProtoMessage() {}
+
+func (x *NamespaceTotals) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NamespaceTotals.ProtoReflect.Descriptor instead.
+func (*NamespaceTotals) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NamespaceTotals) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NamespaceTotals) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *NamespaceTotals) GetRequestCpuMillicores() int64 {
+	if x != nil {
+		return x.RequestCpuMillicores
+	}
+	return 0
+}
+
+func (x *NamespaceTotals) GetLimitCpuMillicores() int64 {
+	if x != nil {
+		return x.LimitCpuMillicores
+	}
+	return 0
+}
+
+func (x *NamespaceTotals) GetRequestMemoryBytes() int64 {
+	if x != nil {
+		return x.RequestMemoryBytes
+	}
+	return 0
+}
+
+func (x *NamespaceTotals) GetLimitMemoryBytes() int64 {
+	if x != nil {
+		return x.LimitMemoryBytes
+	}
+	return 0
+}
+
+// NodeTotals is the requests and capacity of a node; pods that are not
+// scheduled yet are grouped under the IP "Unknown"
+type NodeTotals struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Name                     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Ip                       string                 `protobuf:"bytes,2,opt,name=ip,proto3" json:"ip,omitempty"`
+	Pool                     string                 `protobuf:"bytes,3,opt,name=pool,proto3" json:"pool,omitempty"`
+	Pods                     int32                  `protobuf:"varint,4,opt,name=pods,proto3" json:"pods,omitempty"`
+	AllocatableCpuMillicores int64                  `protobuf:"varint,5,opt,name=allocatable_cpu_millicores,json=allocatableCpuMillicores,proto3" json:"allocatable_cpu_millicores,omitempty"`
+	RequestCpuMillicores     int64                  `protobuf:"varint,6,opt,name=request_cpu_millicores,json=requestCpuMillicores,proto3" json:"request_cpu_millicores,omitempty"`
+	LimitCpuMillicores       int64                  `protobuf:"varint,7,opt,name=limit_cpu_millicores,json=limitCpuMillicores,proto3" json:"limit_cpu_millicores,omitempty"`
+	AllocatableMemoryBytes   int64                  `protobuf:"varint,8,opt,name=allocatable_memory_bytes,json=allocatableMemoryBytes,proto3" json:"allocatable_memory_bytes,omitempty"`
+	RequestMemoryBytes       int64                  `protobuf:"varint,9,opt,name=request_memory_bytes,json=requestMemoryBytes,proto3" json:"request_memory_bytes,omitempty"`
+	LimitMemoryBytes         int64                  `protobuf:"varint,10,opt,name=limit_memory_bytes,json=limitMemoryBytes,proto3" json:"limit_memory_bytes,omitempty"`
+	CpuRequestRatio          *float64               `protobuf:"fixed64,11,opt,name=cpu_request_ratio,json=cpuRequestRatio,proto3,oneof" json:"cpu_request_ratio,omitempty"` // Requests / allocatable, unset without capacity
+	MemoryRequestRatio       *float64               `protobuf:"fixed64,12,opt,name=memory_request_ratio,json=memoryRequestRatio,proto3,oneof" json:"memory_request_ratio,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *NodeTotals) Reset() {
+	*x = NodeTotals{}
+	mi := &file_snapshots_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeTotals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeTotals) ProtoMessage() {}
+
+func (x *NodeTotals) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeTotals.ProtoReflect.Descriptor instead.
+func (*NodeTotals) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NodeTotals) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NodeTotals) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *NodeTotals) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *NodeTotals) GetPods() int32 {
+	if x != nil {
+		return x.Pods
+	}
+	return 0
+}
+
+func (x *NodeTotals) GetAllocatableCpuMillicores() int64 {
+	if x != nil {
+		return x.AllocatableCpuMillicores
+	}
+	return 0
+}
+
+func (x *NodeTotals) GetRequestCpuMillicores() int64 {
+	if x != nil {
+		return x.RequestCpuMillicores
+	}
+	return 0
+}
+
+func (x *NodeTotals) GetLimitCpuMillicores() int64 {
+	if x != nil {
+		return x.LimitCpuMillicores
+	}
+	return 0
+}
+
+func (x *NodeTotals) GetAllocatableMemoryBytes() int64 {
+	if x != nil {
+		return x.AllocatableMemoryBytes
+	}
+	return 0
+}
+
+func (x *NodeTotals) GetRequestMemoryBytes() int64 {
+	if x != nil {
+		return x.RequestMemoryBytes
+	}
+	return 0
+}
+
+func (x *NodeTotals) GetLimitMemoryBytes() int64 {
+	if x != nil {
+		return x.LimitMemoryBytes
+	}
+	return 0
+}
+
+func (x *NodeTotals) GetCpuRequestRatio() float64 {
+	if x != nil && x.CpuRequestRatio != nil {
+		return *x.CpuRequestRatio
+	}
+	return 0
+}
+
+func (x *NodeTotals) GetMemoryRequestRatio() float64 {
+	if x != nil && x.MemoryRequestRatio != nil {
+		return *x.MemoryRequestRatio
+	}
+	return 0
+}
+
+// Recommendation is a validation finding
+type Recommendation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rule          string                 `protobuf:"bytes,1,opt,name=rule,proto3" json:"rule,omitempty"`
+	Severity      string                 `protobuf:"bytes,2,opt,name=severity,proto3" json:"severity,omitempty"` // info, warn or error
+	Subject       string                 `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`   // e.g. "namespace/shop" or "cluster"
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	Action        string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"` // Review action from -review
+	Owner         string                 `protobuf:"bytes,6,opt,name=owner,proto3" json:"owner,omitempty"`   // Review owner from -review
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Recommendation) Reset() {
+	*x = Recommendation{}
+	mi := &file_snapshots_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recommendation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recommendation) ProtoMessage() {}
+
+func (x *Recommendation) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recommendation.ProtoReflect.Descriptor instead.
+func (*Recommendation) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Recommendation) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *Recommendation) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Recommendation) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *Recommendation) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Recommendation) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Recommendation) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type GetSnapshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSnapshotRequest) Reset() {
+	*x = GetSnapshotRequest{}
+	mi := &file_snapshots_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSnapshotRequest) ProtoMessage() {}
+
+func (x *GetSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*GetSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{4}
+}
+
+// Pages of the list methods: limit 0 selects the default of the REST API
+type ListNamespacesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`   // Only namespaces whose name contains this text
+	Phase         string                 `protobuf:"bytes,2,opt,name=phase,proto3" json:"phase,omitempty"` // Only namespaces in this phase (Active, Terminating)
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNamespacesRequest) Reset() {
+	*x = ListNamespacesRequest{}
+	mi := &file_snapshots_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNamespacesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNamespacesRequest) ProtoMessage() {}
+
+func (x *ListNamespacesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNamespacesRequest.ProtoReflect.Descriptor instead.
+func (*ListNamespacesRequest) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListNamespacesRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ListNamespacesRequest) GetPhase() string {
+	if x != nil {
+		return x.Phase
+	}
+	return ""
+}
+
+func (x *ListNamespacesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListNamespacesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListNamespacesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generated     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=generated,proto3" json:"generated,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"` // Items after filtering
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	NextOffset    *int32                 `protobuf:"varint,5,opt,name=next_offset,json=nextOffset,proto3,oneof" json:"next_offset,omitempty"` // Unset on the last page
+	Items         []*NamespaceTotals     `protobuf:"bytes,6,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNamespacesResponse) Reset() {
+	*x = ListNamespacesResponse{}
+	mi := &file_snapshots_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNamespacesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNamespacesResponse) ProtoMessage() {}
+
+func (x *ListNamespacesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNamespacesResponse.ProtoReflect.Descriptor instead.
+func (*ListNamespacesResponse) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListNamespacesResponse) GetGenerated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Generated
+	}
+	return nil
+}
+
+func (x *ListNamespacesResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListNamespacesResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListNamespacesResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListNamespacesResponse) GetNextOffset() int32 {
+	if x != nil && x.NextOffset != nil {
+		return *x.NextOffset
+	}
+	return 0
+}
+
+func (x *ListNamespacesResponse) GetItems() []*NamespaceTotals {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ListNodesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Only nodes whose name or IP contains this text
+	Pool          string                 `protobuf:"bytes,2,opt,name=pool,proto3" json:"pool,omitempty"` // Only nodes of this node pool
+	Limit         int32                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNodesRequest) Reset() {
+	*x = ListNodesRequest{}
+	mi := &file_snapshots_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNodesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNodesRequest) ProtoMessage() {}
+
+func (x *ListNodesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNodesRequest.ProtoReflect.Descriptor instead.
+func (*ListNodesRequest) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListNodesRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ListNodesRequest) GetPool() string {
+	if x != nil {
+		return x.Pool
+	}
+	return ""
+}
+
+func (x *ListNodesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListNodesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListNodesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generated     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=generated,proto3" json:"generated,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	NextOffset    *int32                 `protobuf:"varint,5,opt,name=next_offset,json=nextOffset,proto3,oneof" json:"next_offset,omitempty"`
+	Items         []*NodeTotals          `protobuf:"bytes,6,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNodesResponse) Reset() {
+	*x = ListNodesResponse{}
+	mi := &file_snapshots_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNodesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNodesResponse) ProtoMessage() {}
+
+func (x *ListNodesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNodesResponse.ProtoReflect.Descriptor instead.
+func (*ListNodesResponse) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListNodesResponse) GetGenerated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Generated
+	}
+	return nil
+}
+
+func (x *ListNodesResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListNodesResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListNodesResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListNodesResponse) GetNextOffset() int32 {
+	if x != nil && x.NextOffset != nil {
+		return *x.NextOffset
+	}
+	return 0
+}
+
+func (x *ListNodesResponse) GetItems() []*NodeTotals {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type ListRecommendationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Severity      string                 `protobuf:"bytes,1,opt,name=severity,proto3" json:"severity,omitempty"` // Minimum severity: info, warn or error
+	Rule          string                 `protobuf:"bytes,2,opt,name=rule,proto3" json:"rule,omitempty"`         // Only findings of this rule
+	Subject       string                 `protobuf:"bytes,3,opt,name=subject,proto3" json:"subject,omitempty"`   // Only findings whose subject contains this text, e.g. namespace/shop
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecommendationsRequest) Reset() {
+	*x = ListRecommendationsRequest{}
+	mi := &file_snapshots_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecommendationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecommendationsRequest) ProtoMessage() {}
+
+func (x *ListRecommendationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecommendationsRequest.ProtoReflect.Descriptor instead.
+func (*ListRecommendationsRequest) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListRecommendationsRequest) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *ListRecommendationsRequest) GetRule() string {
+	if x != nil {
+		return x.Rule
+	}
+	return ""
+}
+
+func (x *ListRecommendationsRequest) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *ListRecommendationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRecommendationsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListRecommendationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Generated     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=generated,proto3" json:"generated,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit         int32                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	NextOffset    *int32                 `protobuf:"varint,5,opt,name=next_offset,json=nextOffset,proto3,oneof" json:"next_offset,omitempty"`
+	Items         []*Recommendation      `protobuf:"bytes,6,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRecommendationsResponse) Reset() {
+	*x = ListRecommendationsResponse{}
+	mi := &file_snapshots_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRecommendationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRecommendationsResponse) ProtoMessage() {}
+
+func (x *ListRecommendationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_snapshots_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRecommendationsResponse.ProtoReflect.Descriptor instead.
+func (*ListRecommendationsResponse) Descriptor() ([]byte, []int) {
+	return file_snapshots_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListRecommendationsResponse) GetGenerated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Generated
+	}
+	return nil
+}
+
+func (x *ListRecommendationsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *ListRecommendationsResponse) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListRecommendationsResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListRecommendationsResponse) GetNextOffset() int32 {
+	if x != nil && x.NextOffset != nil {
+		return *x.NextOffset
+	}
+	return 0
+}
+
+func (x *ListRecommendationsResponse) GetItems() []*Recommendation {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+var File_snapshots_proto protoreflect.FileDescriptor
+
+const file_snapshots_proto_rawDesc = "" +
+	"\n" +
+	"\x0fsnapshots.proto\x12\x18podresourcecalculator.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9f\x02\n" +
+	"\bSnapshot\x128\n" +
+	"\tgenerated\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tgenerated\x12I\n" +
+	"\n" +
+	"namespaces\x18\x02 \x03(\v2).podresourcecalculator.v1.NamespaceTotalsR\n" +
+	"namespaces\x12:\n" +
+	"\x05nodes\x18\x03 \x03(\v2$.podresourcecalculator.v1.NodeTotalsR\x05nodes\x12R\n" +
+	"\x0frecommendations\x18\x04 \x03(\v2(.podresourcecalculator.v1.RecommendationR\x0frecommendations\"\x83\x02\n" +
+	"\x0fNamespaceTotals\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05phase\x18\x02 \x01(\tR\x05phase\x124\n" +
+	"\x16request_cpu_millicores\x18\x03 \x01(\x03R\x14requestCpuMillicores\x120\n" +
+	"\x14limit_cpu_millicores\x18\x04 \x01(\x03R\x12limitCpuMillicores\x120\n" +
+	"\x14request_memory_bytes\x18\x05 \x01(\x03R\x12requestMemoryBytes\x12,\n" +
+	"\x12limit_memory_bytes\x18\x06 \x01(\x03R\x10limitMemoryBytes\"\xaf\x04\n" +
+	"\n" +
+	"NodeTotals\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02ip\x18\x02 \x01(\tR\x02ip\x12\x12\n" +
+	"\x04pool\x18\x03 \x01(\tR\x04pool\x12\x12\n" +
+	"\x04pods\x18\x04 \x01(\x05R\x04pods\x12<\n" +
+	"\x1aallocatable_cpu_millicores\x18\x05 \x01(\x03R\x18allocatableCpuMillicores\x124\n" +
+	"\x16request_cpu_millicores\x18\x06 \x01(\x03R\x14requestCpuMillicores\x120\n" +
+	"\x14limit_cpu_millicores\x18\a \x01(\x03R\x12limitCpuMillicores\x128\n" +
+	"\x18allocatable_memory_bytes\x18\b \x01(\x03R\x16allocatableMemoryBytes\x120\n" +
+	"\x14request_memory_bytes\x18\t \x01(\x03R\x12requestMemoryBytes\x12,\n" +
+	"\x12limit_memory_bytes\x18\n" +
+	" \x01(\x03R\x10limitMemoryBytes\x12/\n" +
+	"\x11cpu_request_ratio\x18\v \x01(\x01H\x00R\x0fcpuRequestRatio\x88\x01\x01\x125\n" +
+	"\x14memory_request_ratio\x18\f \x01(\x01H\x01R\x12memoryRequestRatio\x88\x01\x01B\x14\n" +
+	"\x12_cpu_request_ratioB\x17\n" +
+	"\x15_memory_request_ratio\"\xa2\x01\n" +
+	"\x0eRecommendation\x12\x12\n" +
+	"\x04rule\x18\x01 \x01(\tR\x04rule\x12\x1a\n" +
+	"\bseverity\x18\x02 \x01(\tR\bseverity\x12\x18\n" +
+	"\asubject\x18\x03 \x01(\tR\asubject\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\x12\x16\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\x12\x14\n" +
+	"\x05owner\x18\x06 \x01(\tR\x05owner\"\x14\n" +
+	"\x12GetSnapshotRequest\"o\n" +
+	"\x15ListNamespacesRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05phase\x18\x02 \x01(\tR\x05phase\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"\x8d\x02\n" +
+	"\x16ListNamespacesResponse\x128\n" +
+	"\tgenerated\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tgenerated\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12$\n" +
+	"\vnext_offset\x18\x05 \x01(\x05H\x00R\n" +
+	"nextOffset\x88\x01\x01\x12?\n" +
+	"\x05items\x18\x06 \x03(\v2).podresourcecalculator.v1.NamespaceTotalsR\x05itemsB\x0e\n" +
+	"\f_next_offset\"h\n" +
+	"\x10ListNodesRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04pool\x18\x02 \x01(\tR\x04pool\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\"\x83\x02\n" +
+	"\x11ListNodesResponse\x128\n" +
+	"\tgenerated\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tgenerated\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12$\n" +
+	"\vnext_offset\x18\x05 \x01(\x05H\x00R\n" +
+	"nextOffset\x88\x01\x01\x12:\n" +
+	"\x05items\x18\x06 \x03(\v2$.podresourcecalculator.v1.NodeTotalsR\x05itemsB\x0e\n" +
+	"\f_next_offset\"\x94\x01\n" +
+	"\x1aListRecommendationsRequest\x12\x1a\n" +
+	"\bseverity\x18\x01 \x01(\tR\bseverity\x12\x12\n" +
+	"\x04rule\x18\x02 \x01(\tR\x04rule\x12\x18\n" +
+	"\asubject\x18\x03 \x01(\tR\asubject\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x05R\x06offset\"\x91\x02\n" +
+	"\x1bListRecommendationsResponse\x128\n" +
+	"\tgenerated\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\tgenerated\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\x12$\n" +
+	"\vnext_offset\x18\x05 \x01(\x05H\x00R\n" +
+	"nextOffset\x88\x01\x01\x12>\n" +
+	"\x05items\x18\x06 \x03(\v2(.podresourcecalculator.v1.RecommendationR\x05itemsB\x0e\n" +
+	"\f_next_offset2\xcc\x03\n" +
+	"\tSnapshots\x12_\n" +
+	"\vGetSnapshot\x12,.podresourcecalculator.v1.GetSnapshotRequest\x1a\".podresourcecalculator.v1.Snapshot\x12s\n" +
+	"\x0eListNamespaces\x12/.podresourcecalculator.v1.ListNamespacesRequest\x1a0.podresourcecalculator.v1.ListNamespacesResponse\x12d\n" +
+	"\tListNodes\x12*.podresourcecalculator.v1.ListNodesRequest\x1a+.podresourcecalculator.v1.ListNodesResponse\x12\x82\x01\n" +
+	"\x13ListRecommendations\x124.podresourcecalculator.v1.ListRecommendationsRequest\x1a5.podresourcecalculator.v1.ListRecommendationsResponseB;Z9github.com/ohauer/PodResourceCalculator/proto/snapshotsv1b\x06proto3"
+
+var (
+	file_snapshots_proto_rawDescOnce sync.Once
+	file_snapshots_proto_rawDescData []byte
+)
+
+func file_snapshots_proto_rawDescGZIP() []byte {
+	file_snapshots_proto_rawDescOnce.Do(func() {
+		file_snapshots_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_snapshots_proto_rawDesc), len(file_snapshots_proto_rawDesc)))
+	})
+	return file_snapshots_proto_rawDescData
+}
+
+var file_snapshots_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_snapshots_proto_goTypes = []any{
+	(*Snapshot)(nil),                    // 0: podresourcecalculator.v1.Snapshot
+	(*NamespaceTotals)(nil),             // 1: podresourcecalculator.v1.NamespaceTotals
+	(*NodeTotals)(nil),                  // 2: podresourcecalculator.v1.NodeTotals
+	(*Recommendation)(nil),              // 3: podresourcecalculator.v1.Recommendation
+	(*GetSnapshotRequest)(nil),          // 4: podresourcecalculator.v1.GetSnapshotRequest
+	(*ListNamespacesRequest)(nil),       // 5: podresourcecalculator.v1.ListNamespacesRequest
+	(*ListNamespacesResponse)(nil),      // 6: podresourcecalculator.v1.ListNamespacesResponse
+	(*ListNodesRequest)(nil),            // 7: podresourcecalculator.v1.ListNodesRequest
+	(*ListNodesResponse)(nil),           // 8: podresourcecalculator.v1.ListNodesResponse
+	(*ListRecommendationsRequest)(nil),  // 9: podresourcecalculator.v1.ListRecommendationsRequest
+	(*ListRecommendationsResponse)(nil), // 10: podresourcecalculator.v1.ListRecommendationsResponse
+	(*timestamppb.Timestamp)(nil),       // 11: google.protobuf.Timestamp
+}
+var file_snapshots_proto_depIdxs = []int32{
+	11, // 0: podresourcecalculator.v1.Snapshot.generated:type_name -> google.protobuf.Timestamp
+	1,  // 1: podresourcecalculator.v1.Snapshot.namespaces:type_name -> podresourcecalculator.v1.NamespaceTotals
+	2,  // 2: podresourcecalculator.v1.Snapshot.nodes:type_name -> podresourcecalculator.v1.NodeTotals
+	3,  // 3: podresourcecalculator.v1.Snapshot.recommendations:type_name -> podresourcecalculator.v1.Recommendation
+	11, // 4: podresourcecalculator.v1.ListNamespacesResponse.generated:type_name -> google.protobuf.Timestamp
+	1,  // 5: podresourcecalculator.v1.ListNamespacesResponse.items:type_name -> podresourcecalculator.v1.NamespaceTotals
+	11, // 6: podresourcecalculator.v1.ListNodesResponse.generated:type_name -> google.protobuf.Timestamp
+	2,  // 7: podresourcecalculator.v1.ListNodesResponse.items:type_name -> podresourcecalculator.v1.NodeTotals
+	11, // 8: podresourcecalculator.v1.ListRecommendationsResponse.generated:type_name -> google.protobuf.Timestamp
+	3,  // 9: podresourcecalculator.v1.ListRecommendationsResponse.items:type_name -> podresourcecalculator.v1.Recommendation
+	4,  // 10: podresourcecalculator.v1.Snapshots.GetSnapshot:input_type -> podresourcecalculator.v1.GetSnapshotRequest
+	5,  // 11: podresourcecalculator.v1.Snapshots.ListNamespaces:input_type -> podresourcecalculator.v1.ListNamespacesRequest
+	7,  // 12: podresourcecalculator.v1.Snapshots.ListNodes:input_type -> podresourcecalculator.v1.ListNodesRequest
+	9,  // 13: podresourcecalculator.v1.Snapshots.ListRecommendations:input_type -> podresourcecalculator.v1.ListRecommendationsRequest
+	0,  // 14: podresourcecalculator.v1.Snapshots.GetSnapshot:output_type -> podresourcecalculator.v1.Snapshot
+	6,  // 15: podresourcecalculator.v1.Snapshots.ListNamespaces:output_type -> podresourcecalculator.v1.ListNamespacesResponse
+	8,  // 16: podresourcecalculator.v1.Snapshots.ListNodes:output_type -> podresourcecalculator.v1.ListNodesResponse
+	10, // 17: podresourcecalculator.v1.Snapshots.ListRecommendations:output_type -> podresourcecalculator.v1.ListRecommendationsResponse
+	14, // [14:18] is the sub-list for method output_type
+	10, // [10:14] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_snapshots_proto_init() }
+func file_snapshots_proto_init() {
+	if File_snapshots_proto != nil {
+		return
+	}
+	file_snapshots_proto_msgTypes[2].OneofWrappers = []any{}
+	file_snapshots_proto_msgTypes[6].OneofWrappers = []any{}
+	file_snapshots_proto_msgTypes[8].OneofWrappers = []any{}
+	file_snapshots_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_snapshots_proto_rawDesc), len(file_snapshots_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_snapshots_proto_goTypes,
+		DependencyIndexes: file_snapshots_proto_depIdxs,
+		MessageInfos:      file_snapshots_proto_msgTypes,
+	}.Build()
+	File_snapshots_proto = out.File
+	file_snapshots_proto_goTypes = nil
+	file_snapshots_proto_depIdxs = nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// rawQuantityHeaders are the optional audit columns added by -raw-quantities
+var rawQuantityHeaders = []string{
+	"Request CPU Canonical", "Request CPU Units", "Request CPU Exact (cores)",
+	"Request Memory Canonical", "Request Memory Units", "Request Memory Exact (bytes)",
+	"Limit CPU Canonical", "Limit CPU Units", "Limit CPU Exact (cores)",
+	"Limit Memory Canonical", "Limit Memory Units", "Limit Memory Exact (bytes)",
+	"Exact in Report Units",
+}
+
+// quantityAudit describes a resource quantity for precision audits
+type quantityAudit struct {
+	canonical string // Canonical Kubernetes representation, e.g. "128M"
+	units     string // Unit system of the quantity suffix
+	exact     string // Exact value in base units (cores or bytes)
+	lossless  bool   // Whether the report column (millicores or whole Mi) shows the exact value
+}
+
+// auditQuantity inspects a quantity; cpu selects millicore instead of Mi conversion
+func auditQuantity(q *resource.Quantity, cpu bool) quantityAudit {
+	if q == nil || q.IsZero() {
+		return quantityAudit{canonical: "-", units: "-", exact: "-", lossless: true}
+	}
+
+	audit := quantityAudit{
+		canonical: q.String(),
+		units:     quantityUnits(q.Format),
+		exact:     q.AsDec().String(),
+	}
+
+	if cpu {
+		audit.lossless = resource.NewMilliQuantity(q.MilliValue(), q.Format).Cmp(*q) == 0
+	} else {
+		bytes := q.Value()
+		audit.lossless = resource.NewQuantity(bytes, q.Format).Cmp(*q) == 0 && bytes%BytesPerMi == 0
+	}
+
+	return audit
+}
+
+// quantityUnits names the unit system of a quantity format
+func quantityUnits(format resource.Format) string {
+	switch format {
+	case resource.BinarySI:
+		return "binary (Ki/Mi/Gi)"
+	case resource.DecimalSI:
+		return "decimal (k/M/G)"
+	case resource.DecimalExponent:
+		return "decimal exponent (e3/e6)"
+	default:
+		return string(format)
+	}
+}
+
+// rawQuantityColumns returns the audit column values for a container's requests and limits
+func rawQuantityColumns(reqCPU, reqMem, limCPU, limMem *resource.Quantity) []interface{} {
+	audits := []quantityAudit{
+		auditQuantity(reqCPU, true),
+		auditQuantity(reqMem, false),
+		auditQuantity(limCPU, true),
+		auditQuantity(limMem, false),
+	}
+
+	lossless := "Yes"
+	columns := make([]interface{}, 0, len(rawQuantityHeaders))
+	for _, audit := range audits {
+		columns = append(columns, audit.canonical, audit.units, audit.exact)
+		if !audit.lossless {
+			lossless = "No"
+		}
+	}
+	return append(columns, lossless)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestAuditQuantity(t *testing.T) {
+	tests := []struct {
+		name         string
+		quantity     string
+		cpu          bool
+		wantUnits    string
+		wantExact    string
+		wantLossless bool
+	}{
+		{"binary memory", "128Mi", false, "binary (Ki/Mi/Gi)", "134217728", true},
+		{"decimal memory", "128M", false, "decimal (k/M/G)", "128000000", false},
+		{"plain bytes whole Mi", "1048576", false, "decimal (k/M/G)", "1048576", true},
+		{"millicores", "250m", true, "decimal (k/M/G)", "0.250", true},
+		{"whole cores", "2", true, "decimal (k/M/G)", "2", true},
+		{"sub-millicore", "0.0005", true, "decimal (k/M/G)", "0.0005", false},
+		{"exponent", "1e3", true, "decimal exponent (e3/e6)", "1000", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := resource.MustParse(tt.quantity)
+			got := auditQuantity(&q, tt.cpu)
+			if got.units != tt.wantUnits || got.exact != tt.wantExact || got.lossless != tt.wantLossless {
+				t.Errorf("auditQuantity(%s) = %+v, want units %q exact %q lossless %v",
+					tt.quantity, got, tt.wantUnits, tt.wantExact, tt.wantLossless)
+			}
+		})
+	}
+}
+
+func TestAuditQuantityUnset(t *testing.T) {
+	if got := auditQuantity(nil, true); got.canonical != "-" || !got.lossless {
+		t.Errorf("auditQuantity(nil) = %+v", got)
+	}
+
+	columns := rawQuantityColumns(nil, nil, nil, nil)
+	if len(columns) != len(rawQuantityHeaders) {
+		t.Fatalf("rawQuantityColumns() returned %d values, want %d", len(columns), len(rawQuantityHeaders))
+	}
+	if columns[len(columns)-1] != "Yes" {
+		t.Errorf("rawQuantityColumns() lossless = %v, want Yes", columns[len(columns)-1])
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"math"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Recommended requests: the highest usage quantile of a workload container
+// plus headroom, rounded up and never below the minimum
+const (
+	RecommendCPUHeadroom    = 1.15
+	RecommendMemoryHeadroom = 1.25     // Memory is not compressible: more room than CPU
+	MinRecommendedCPU       = 10       // Millicores
+	MinRecommendedMemory    = 32 << 20 // Bytes
+	RecommendMinChange      = 0.10     // Relative change below which a request is left alone
+)
+
+// containerRecommendation are the recommended requests of a workload container
+type containerRecommendation struct {
+	workload       workloadKey
+	container      string
+	reqCPU, reqMem int64 // Current requests, the highest of the workload's pods
+	cpu, mem       int64 // Recommended requests in millicores and bytes
+}
+
+// changed reports whether a recommendation differs from the current request
+// by at least RecommendMinChange, for CPU or memory
+func (r containerRecommendation) changed() bool {
+	differs := func(current, recommended int64) bool {
+		if current == 0 {
+			return true
+		}
+		return math.Abs(float64(recommended-current))/float64(current) >= RecommendMinChange
+	}
+	return differs(r.reqCPU, r.cpu) || differs(r.reqMem, r.mem)
+}
+
+// recommendRequests derives the recommended requests of each workload
+// container from the highest quantile of usage, across the workload's active
+// pods. Containers without usage history are left out. Sorted by workload and
+// container.
+func recommendRequests(pods []corev1.Pod, quantiles *usageQuantiles) []containerRecommendation {
+	if quantiles == nil || len(quantiles.quantiles) == 0 {
+		return nil
+	}
+	highest := 0
+	for i, q := range quantiles.quantiles {
+		if q > quantiles.quantiles[highest] {
+			highest = i
+		}
+	}
+
+	type key struct {
+		workload  workloadKey
+		container string
+	}
+	recs := make(map[key]*containerRecommendation)
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		workload := workloadOf(pod)
+		for _, c := range podContainers(pod) {
+			samples, ok := quantiles.containers[pod.Namespace+"/"+pod.Name+"/"+c.Name]
+			if !ok {
+				continue
+			}
+			k := key{workload, c.Name}
+			r := recs[k]
+			if r == nil {
+				r = &containerRecommendation{workload: workload, container: c.Name}
+				recs[k] = r
+			}
+			r.reqCPU = max64(r.reqCPU, quantityMilli(c.Resources.Requests.Cpu()))
+			r.reqMem = max64(r.reqMem, quantityBytes(c.Resources.Requests.Memory()))
+			r.cpu = max64(r.cpu, samples[highest].cpu)
+			r.mem = max64(r.mem, samples[highest].mem)
+		}
+	}
+
+	result := make([]containerRecommendation, 0, len(recs))
+	for _, r := range recs {
+		r.cpu = max64(int64(math.Ceil(float64(r.cpu)*RecommendCPUHeadroom)), MinRecommendedCPU)
+		mem := max64(int64(math.Ceil(float64(r.mem)*RecommendMemoryHeadroom)), MinRecommendedMemory)
+		r.mem = (mem + BytesPerMi - 1) / BytesPerMi * BytesPerMi // Whole Mi
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if a, b := result[i].workload.String(), result[j].workload.String(); a != b {
+			return a < b
+		}
+		return result[i].container < result[j].container
+	})
+	return result
+}
+
+// savingsOpportunity returns the CPU requests of the active pods above their
+// recommendations in millicores; nil without usage quantiles
+func savingsOpportunity(pods []corev1.Pod, quantiles *usageQuantiles) *int64 {
+	if quantiles == nil {
+		return nil
+	}
+	type key struct {
+		workload  workloadKey
+		container string
+	}
+	recommended := make(map[key]int64)
+	for _, rec := range recommendRequests(pods, quantiles) {
+		recommended[key{rec.workload, rec.container}] = rec.cpu
+	}
+
+	var savings int64
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		workload := workloadOf(pod)
+		for _, c := range podContainers(pod) {
+			if cpu, ok := recommended[key{workload, c.Name}]; ok {
+				savings += max64(quantityMilli(c.Resources.Requests.Cpu())-cpu, 0)
+			}
+		}
+	}
+	return &savings
+}
+
+// max64 returns the larger of a and b
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRecommendRequests(t *testing.T) {
+	hash := map[string]string{"pod-template-hash": "abc"}
+	pods := []corev1.Pod{
+		testDeliveryPod("web-1", "web-abc", hash, "1"),
+		testDeliveryPod("web-2", "web-abc", hash, "500m"),
+		testDeliveryPod("idle-1", "idle-abc", hash, "100m"),
+		testDeliveryPod("new-1", "new-abc", hash, "100m"), // No usage history
+	}
+	quantiles := &usageQuantiles{quantiles: []float64{0.99, 0.95}, containers: map[string][]metricsSample{
+		"shop/web-1/app":  {{cpu: 200, mem: 150 << 20}, {cpu: 100, mem: 100 << 20}},
+		"shop/web-2/app":  {{cpu: 300, mem: 200 << 20}, {cpu: 900, mem: 900 << 20}},
+		"shop/idle-1/app": {{cpu: 1, mem: 1 << 20}, {cpu: 1, mem: 1 << 20}},
+	}}
+
+	recs := recommendRequests(pods, quantiles)
+	if len(recs) != 2 {
+		t.Fatalf("recommendRequests() = %+v", recs)
+	}
+	// The highest quantile (first here) of the busiest pod, plus headroom
+	if web := recs[1]; web.workload.name != "web" || web.reqCPU != 1000 || web.cpu != 345 || web.mem != 250<<20 {
+		t.Errorf("web = %+v", web)
+	}
+	if idle := recs[0]; idle.workload.name != "idle" || idle.cpu != MinRecommendedCPU || idle.mem != MinRecommendedMemory {
+		t.Errorf("idle = %+v", idle)
+	}
+
+	if recommendRequests(pods, nil) != nil {
+		t.Error("recommendations without quantiles")
+	}
+}
+
+func TestSavingsOpportunity(t *testing.T) {
+	hash := map[string]string{"pod-template-hash": "abc"}
+	pods := []corev1.Pod{
+		testDeliveryPod("web-1", "web-abc", hash, "1"),
+		testDeliveryPod("web-2", "web-abc", hash, "1"),
+		testDeliveryPod("tight-1", "tight-abc", hash, "100m"), // Below its recommendation: no negative savings
+		testDeliveryPod("new-1", "new-abc", hash, "2"),        // No usage history
+	}
+	quantiles := &usageQuantiles{quantiles: []float64{0.95}, containers: map[string][]metricsSample{
+		"shop/web-1/app":   {{cpu: 200, mem: 100 << 20}},
+		"shop/web-2/app":   {{cpu: 100, mem: 100 << 20}},
+		"shop/tight-1/app": {{cpu: 500, mem: 100 << 20}},
+	}}
+	// Both web pods request 1000m against the recommended 230m
+	if got := savingsOpportunity(pods, quantiles); got == nil || *got != 2*770 {
+		t.Errorf("savingsOpportunity() = %v, want %d", got, 2*770)
+	}
+	if got := savingsOpportunity(pods, nil); got != nil {
+		t.Errorf("savingsOpportunity(nil) = %d, want nil", *got)
+	}
+}
+
+func TestContainerRecommendationChanged(t *testing.T) {
+	tests := []struct {
+		name string
+		rec  containerRecommendation
+		want bool
+	}{
+		{"unchanged", containerRecommendation{reqCPU: 100, cpu: 105, reqMem: 100, mem: 95}, false},
+		{"cpu", containerRecommendation{reqCPU: 100, cpu: 50, reqMem: 100, mem: 100}, true},
+		{"memory", containerRecommendation{reqCPU: 100, cpu: 100, reqMem: 100, mem: 120}, true},
+		{"no request", containerRecommendation{cpu: 10, reqMem: 100, mem: 100}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rec.changed(); got != tt.want {
+				t.Errorf("changed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
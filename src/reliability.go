@@ -0,0 +1,264 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Probe settings below which a probe counts as aggressive, and the CPU limit
+// below which a container is likely throttled while it answers a probe
+const (
+	ProbeTightTimeout  = 1   // Seconds, the Kubernetes default
+	ProbeTightWindow   = 10  // Seconds from the first failed probe to the restart
+	ProbeTightCPULimit = 250 // Millicores
+)
+
+// Kubernetes defaults for probe fields left unset
+const (
+	ProbeDefaultTimeout   = 1
+	ProbeDefaultPeriod    = 10
+	ProbeDefaultThreshold = 3
+)
+
+// Probe risk levels, highest first
+const (
+	ProbeRiskHigh   = "High"
+	ProbeRiskMedium = "Medium"
+	ProbeRiskLow    = "Low"
+)
+
+var probeRiskOrder = map[string]int{ProbeRiskHigh: 0, ProbeRiskMedium: 1, ProbeRiskLow: 2}
+
+// probeRisk is an aggressive probe of a container with a CPU limit, one row per
+// workload, container and probe
+type probeRisk struct {
+	workload         workloadKey
+	container, probe string // probe is liveness, readiness or startup
+	handler          string
+	pods             int
+	timeout, period  int32 // Seconds
+	threshold        int32
+	reqCPU, limCPU   int64 // Millicores
+	risk             string
+	reasons          []string
+}
+
+// window is the time in seconds from the first failed probe until the
+// container is restarted or taken out of its Service
+func (r probeRisk) window() int32 {
+	return r.period * r.threshold
+}
+
+// probeHandler names the check a probe runs
+func probeHandler(p *corev1.Probe) string {
+	switch {
+	case p.Exec != nil:
+		return "exec"
+	case p.HTTPGet != nil:
+		return "httpGet"
+	case p.TCPSocket != nil:
+		return "tcpSocket"
+	case p.GRPC != nil:
+		return "grpc"
+	}
+	return "-"
+}
+
+// assessProbe returns the risk of a probe on a container with the CPU limit
+// limCPU in millicores, empty when the probe is not aggressive or the
+// container has no CPU limit to be throttled by
+func assessProbe(kind string, p *corev1.Probe, limCPU int64) (risk string, reasons []string, timeout, period, threshold int32) {
+	timeout, period, threshold = p.TimeoutSeconds, p.PeriodSeconds, p.FailureThreshold
+	if timeout == 0 {
+		timeout = ProbeDefaultTimeout
+	}
+	if period == 0 {
+		period = ProbeDefaultPeriod
+	}
+	if threshold == 0 {
+		threshold = ProbeDefaultThreshold
+	}
+	if limCPU == 0 {
+		return "", nil, timeout, period, threshold
+	}
+
+	if timeout <= ProbeTightTimeout {
+		reasons = append(reasons, fmt.Sprintf("timeout %ds", timeout))
+	}
+	if period*threshold <= ProbeTightWindow {
+		reasons = append(reasons, fmt.Sprintf("fails after %ds", period*threshold))
+	}
+	if p.Exec != nil {
+		reasons = append(reasons, "exec probe runs inside the CPU limit")
+	}
+	if len(reasons) == 0 {
+		return "", nil, timeout, period, threshold
+	}
+
+	tight := limCPU <= ProbeTightCPULimit
+	if tight {
+		reasons = append(reasons, fmt.Sprintf("CPU limit %dm", limCPU))
+	}
+	switch {
+	case kind == "readiness":
+		// Readiness failures only take the pod out of its Service
+		risk = ProbeRiskLow
+	case tight:
+		risk = ProbeRiskHigh
+	default:
+		risk = ProbeRiskMedium
+	}
+	return risk, reasons, timeout, period, threshold
+}
+
+// hasProbes reports whether a container of an active pod defines a probe
+func hasProbes(pods []corev1.Pod) bool {
+	for i := range pods {
+		if !isActivePod(&pods[i]) {
+			continue
+		}
+		for _, c := range podContainers(&pods[i]) {
+			if c.LivenessProbe != nil || c.ReadinessProbe != nil || c.StartupProbe != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// probeRisks finds the containers of active pods whose probes may fail while
+// the container is throttled at its CPU limit, highest risk first
+func probeRisks(pods []corev1.Pod) []probeRisk {
+	type riskKey struct {
+		workload         workloadKey
+		container, probe string
+	}
+	risks := make(map[riskKey]*probeRisk)
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		workload := workloadOf(pod)
+		for _, c := range podContainers(pod) {
+			limCPU := quantityMilli(c.Resources.Limits.Cpu())
+			for _, probe := range []struct {
+				kind  string
+				probe *corev1.Probe
+			}{
+				{"liveness", c.LivenessProbe},
+				{"readiness", c.ReadinessProbe},
+				{"startup", c.StartupProbe},
+			} {
+				if probe.probe == nil {
+					continue
+				}
+				risk, reasons, timeout, period, threshold := assessProbe(probe.kind, probe.probe, limCPU)
+				if risk == "" {
+					continue
+				}
+				key := riskKey{workload, c.Name, probe.kind}
+				if r := risks[key]; r != nil {
+					r.pods++
+					continue
+				}
+				risks[key] = &probeRisk{
+					workload:  workload,
+					container: c.Name,
+					probe:     probe.kind,
+					handler:   probeHandler(probe.probe),
+					pods:      1,
+					timeout:   timeout,
+					period:    period,
+					threshold: threshold,
+					reqCPU:    quantityMilli(c.Resources.Requests.Cpu()),
+					limCPU:    limCPU,
+					risk:      risk,
+					reasons:   reasons,
+				}
+			}
+		}
+	}
+
+	result := make([]probeRisk, 0, len(risks))
+	for _, r := range risks {
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.risk != b.risk {
+			return probeRiskOrder[a.risk] < probeRiskOrder[b.risk]
+		}
+		if a.workload != b.workload {
+			return a.workload.String() < b.workload.String()
+		}
+		if a.container != b.container {
+			return a.container < b.container
+		}
+		return a.probe < b.probe
+	})
+	return result
+}
+
+// createReliabilitySheet lists the probes at risk of failing under CPU
+// throttling with the settings and CPU resources behind the risk
+func createReliabilitySheet(f *excelize.File, risks []probeRisk, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create reliability sheet: %w", err)
+	}
+
+	headers := []string{
+		"Risk", "Namespace", "Workload Kind", "Workload", "Container", "Pods",
+		"Probe", "Handler", "Timeout (s)", "Period (s)", "Failure Threshold", "Failure Window (s)",
+		"CPU Request (m)", "CPU Limit (m)", "Reasons",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, r := range risks {
+		data := []interface{}{
+			r.risk,
+			r.workload.namespace,
+			r.workload.kind,
+			r.workload.name,
+			r.container,
+			r.pods,
+			r.probe,
+			r.handler,
+			r.timeout,
+			r.period,
+			r.threshold,
+			r.window(),
+			r.reqCPU,
+			r.limCPU,
+			strings.Join(r.reasons, ", "),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("container '%s'", r.container)); err != nil {
+			return err
+		}
+		if r.risk == ProbeRiskHigh {
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.BoldStyle(f))
+		}
+		row++
+	}
+
+	if row > 2 {
+		f.SetCellStyle(sheetName, "I2", fmt.Sprintf("N%d", row-1), export.IntegerStyle(f))
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 10)
+	f.SetColWidth(sheetName, "B", "E", 24)
+	f.SetColWidth(sheetName, "F", "H", 12)
+	f.SetColWidth(sheetName, "I", "N", 18)
+	f.SetColWidth(sheetName, "O", "O", 56)
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAssessProbe(t *testing.T) {
+	httpProbe := func(timeout, period, threshold int32) *corev1.Probe {
+		return &corev1.Probe{
+			ProbeHandler:   corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/healthz"}},
+			TimeoutSeconds: timeout, PeriodSeconds: period, FailureThreshold: threshold,
+		}
+	}
+	execProbe := &corev1.Probe{
+		ProbeHandler:   corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"pg_isready"}}},
+		TimeoutSeconds: 5, PeriodSeconds: 10, FailureThreshold: 3,
+	}
+	tests := []struct {
+		name    string
+		kind    string
+		probe   *corev1.Probe
+		limCPU  int64
+		want    string
+		reasons int
+	}{
+		{"defaults with tight limit", "liveness", &corev1.Probe{}, 200, ProbeRiskHigh, 2},
+		{"defaults with generous limit", "liveness", httpProbe(1, 10, 3), 2000, ProbeRiskMedium, 1},
+		{"no CPU limit", "liveness", httpProbe(1, 2, 1), 0, "", 0},
+		{"relaxed probe", "liveness", httpProbe(5, 10, 3), 100, "", 0},
+		{"short window", "startup", httpProbe(3, 2, 3), 100, ProbeRiskHigh, 2},
+		{"exec probe", "liveness", execProbe, 500, ProbeRiskMedium, 1},
+		{"readiness", "readiness", httpProbe(1, 5, 1), 100, ProbeRiskLow, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			risk, reasons, _, _, _ := assessProbe(tt.kind, tt.probe, tt.limCPU)
+			if risk != tt.want || len(reasons) != tt.reasons {
+				t.Errorf("assessProbe() = %q %v, want %q with %d reasons", risk, reasons, tt.want, tt.reasons)
+			}
+		})
+	}
+}
+
+func TestProbeRisks(t *testing.T) {
+	container := func(name, limit string, liveness *corev1.Probe) corev1.Container {
+		c := testSidecarContainer(name, "100m", "128Mi")
+		c.Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(limit)}
+		c.LivenessProbe = liveness
+		return c
+	}
+	pod := func(owner string, phase corev1.PodPhase, containers ...corev1.Container) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "shop",
+				Name:            owner + "-pod",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: owner}},
+			},
+			Spec:   corev1.PodSpec{Containers: containers},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	tight := &corev1.Probe{TimeoutSeconds: 1, PeriodSeconds: 10, FailureThreshold: 3}
+	relaxed := &corev1.Probe{TimeoutSeconds: 5, PeriodSeconds: 10, FailureThreshold: 3}
+
+	pods := []corev1.Pod{
+		pod("web", corev1.PodRunning, container("app", "2", tight), container("proxy", "100m", tight)),
+		pod("web", corev1.PodRunning, container("app", "2", tight), container("proxy", "100m", tight)),
+		pod("web", corev1.PodSucceeded, container("app", "100m", tight)),
+		pod("db", corev1.PodRunning, container("postgres", "100m", relaxed)),
+	}
+	if !hasProbes(pods) {
+		t.Fatal("hasProbes() = false")
+	}
+
+	got := probeRisks(pods)
+	if len(got) != 2 {
+		t.Fatalf("probeRisks() returned %d rows, want 2: %+v", len(got), got)
+	}
+	if got[0].container != "proxy" || got[0].risk != ProbeRiskHigh || got[0].pods != 2 {
+		t.Errorf("first row = %+v, want the proxy with high risk on 2 pods", got[0])
+	}
+	if got[1].container != "app" || got[1].risk != ProbeRiskMedium || got[1].window() != 30 {
+		t.Errorf("second row = %+v, want the app with medium risk", got[1])
+	}
+
+	if hasProbes([]corev1.Pod{pod("db", corev1.PodRunning, container("postgres", "1", nil))}) {
+		t.Error("hasProbes() = true for a pod without probes")
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/collector"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultConfigReload is how often server mode checks -config for changes
+const DefaultConfigReload = 30 * time.Second
+
+// configWatcher polls the config file of server mode for changed content.
+// Polling the content also catches ConfigMap mounts, which swap a symlink
+// instead of writing the file.
+type configWatcher struct {
+	path     string
+	interval time.Duration
+	sum      [sha256.Size]byte // Of the applied content
+	reload   func(job reportJob, now time.Time) (reportJob, error)
+}
+
+// newConfigWatcher remembers the current content of path, the config the job
+// was built from
+func newConfigWatcher(path string, interval time.Duration, reload func(job reportJob, now time.Time) (reportJob, error)) (*configWatcher, error) {
+	w := &configWatcher{path: path, interval: interval, reload: reload}
+	if _, err := w.changed(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// changed reports whether the content of the config file differs from the
+// last call and remembers the new content
+func (w *configWatcher) changed() (bool, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config %s: %w", w.path, err)
+	}
+	sum := sha256.Sum256(data)
+	if sum == w.sum {
+		return false, nil
+	}
+	w.sum = sum
+	return true, nil
+}
+
+// run applies each changed config to the server and rebuilds the report until
+// ctx ends. An invalid config is logged and the previous settings stay in use.
+func (w *configWatcher) run(ctx context.Context, s *reportServer, job reportJob) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := w.changed()
+		if err != nil {
+			logrus.Warnf("Config not reloaded: %v", err)
+			continue
+		}
+		if !changed {
+			continue
+		}
+		next, err := w.reload(job, s.now())
+		if err != nil {
+			logrus.Errorf("Changed config %s not applied, keeping the previous settings: %v", w.path, err)
+			continue
+		}
+		job = next
+		s.useJob(job)
+		logrus.Infof("Applied changed config %s", w.path)
+		if _, err := s.regenerate(true); err != nil {
+			logrus.Errorf("Failed to regenerate report: %v", err)
+		}
+	}
+}
+
+// reloadJob returns job with the settings of the config file at path: report
+// options such as thresholds and sheets, the namespace patterns, the opt-out
+// annotation and alerts. Flags still override the file. The time zone,
+// -namespace and collection flags keep their values until a restart: the pod
+// watch is opened in the -namespace scope, which may be all the RBAC allows.
+func reloadJob(job reportJob, path string, overrides configOverrides, settings renderSettings, now time.Time) (reportJob, error) {
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return job, err
+	}
+	overrides.apply(cfg)
+	excludeKey, err := collector.ParseExcludeAnnotation(cfg.ExcludeAnnotation)
+	if err != nil {
+		return job, fmt.Errorf("invalid exclude annotation: %w", err)
+	}
+	selector, err := parseNamespacePatterns(cfg.NamespacePattern)
+	if err != nil {
+		return job, fmt.Errorf("invalid namespace pattern: %w", err)
+	}
+	if selector != nil && job.namespace != "" {
+		return job, fmt.Errorf("invalid namespace pattern: the report is scoped to namespace '%s'", job.namespace)
+	}
+	settings.NamespacePattern = cfg.NamespacePattern
+	opts, err := settings.reportOptions(cfg, now)
+	if err != nil {
+		return job, fmt.Errorf("invalid settings: %w", err)
+	}
+	opts.findingsPath, opts.findingsFormat = job.opts.findingsPath, job.opts.findingsFormat
+	alerts, err := parseAlerts(cfg.Alerts, settings.Cluster)
+	if err != nil {
+		return job, fmt.Errorf("invalid config: %w", err)
+	}
+	job.excludeKey, job.selector, job.opts, job.alerts = excludeKey, selector, opts, alerts
+	return job, nil
+}
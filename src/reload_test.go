@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/collector"
+)
+
+func TestConfigWatcherChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("theme: dark\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	w, err := newConfigWatcher(path, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := w.changed(); changed || err != nil {
+		t.Errorf("changed() without a change = %v, %v", changed, err)
+	}
+
+	// ConfigMap volumes swap a symlink to the new content
+	next := filepath.Join(filepath.Dir(path), "next.yaml")
+	if err := os.WriteFile(next, []byte("theme: light\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(next, path); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := w.changed(); !changed || err != nil {
+		t.Errorf("changed() after a change = %v, %v", changed, err)
+	}
+	if changed, _ := w.changed(); changed {
+		t.Error("changed() reported the same change twice")
+	}
+
+	os.Remove(path)
+	if _, err := w.changed(); err == nil {
+		t.Error("changed() of a missing file expected error")
+	}
+}
+
+func TestReloadJob(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	job := reportJob{excludeKey: collector.DefaultExcludeAnnotation}
+	job.opts.findingsPath = "findings.json"
+	settings := renderSettings{Cluster: "prod", IdleDays: DefaultIdleDays}
+
+	write("sheets: [overview, cost]\npricing:\n  cpuCoreMonth: 20\nexcludeAnnotation: none\n")
+	got, err := reloadJob(job, path, configOverrides{theme: "dark"}, settings, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.excludeKey != "" || got.opts.pricing == nil || !got.opts.sheets.enabled(SheetCost) || got.opts.sheets.enabled(SheetNodes) {
+		t.Errorf("reloadJob() = %+v, want the new settings", got)
+	}
+	if got.opts.theme != themes["dark"] || got.opts.findingsPath != "findings.json" {
+		t.Errorf("reloadJob() lost flag settings: findings %q", got.opts.findingsPath)
+	}
+
+	write("pricing:\n  cpuCoreMonth: -1\n")
+	if got, err := reloadJob(job, path, configOverrides{}, settings, now); err == nil || got.excludeKey != collector.DefaultExcludeAnnotation {
+		t.Errorf("reloadJob() with an invalid config = %v, %v; want the previous job and an error", got.excludeKey, err)
+	}
+
+	// Namespace patterns change the scope, -namespace-pattern still wins
+	write("namespacePattern: team-*\n")
+	got, err = reloadJob(job, path, configOverrides{}, settings, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.selector.matches("team-a") || got.selector.matches("shop") || got.opts.metadata.pattern != "team-*" {
+		t.Errorf("reloadJob() selector = %+v, pattern %q", got.selector, got.opts.metadata.pattern)
+	}
+	if got, _ = reloadJob(job, path, configOverrides{namespacePattern: "shop"}, settings, now); !got.selector.matches("shop") {
+		t.Errorf("reloadJob() ignored -namespace-pattern: %+v", got.selector)
+	}
+	job.namespace = "shop"
+	if _, err := reloadJob(job, path, configOverrides{}, settings, now); err == nil {
+		t.Error("reloadJob() with patterns in a -namespace scope expected error")
+	}
+}
+
+func TestReportServerUseJob(t *testing.T) {
+	s := &reportServer{snapshots: &snapshotCache{ttl: time.Hour, snap: &clusterSnapshot{}}}
+	job := reportJob{}
+	job.opts.validation.failOn = severityWarn
+	job.selector, _ = parseNamespacePatterns("team-*")
+	s.useJob(job)
+	if s.snapshots.snap != nil || s.snapshots.collect == nil || s.render == nil || s.validation.failOn != severityWarn {
+		t.Errorf("useJob() did not switch the server settings: %+v", s)
+	}
+	if s.namespaceSelector() != job.selector {
+		t.Error("useJob() did not switch the namespace patterns")
+	}
+}
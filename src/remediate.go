@@ -0,0 +1,545 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"go.yaml.in/yaml/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Modes of -gitops-pr
+const (
+	GitOpsPRDryRun = "dry-run" // Print the manifest changes
+	GitOpsPRPush   = "push"    // Commit them to a branch and push it
+)
+
+// Branch and identity of remediation commits, the identity only when git has none
+const (
+	RemediationBranchPrefix = "resource-recommendations/"
+	RemediationAuthorName   = "PodResourceCalculator"
+	RemediationAuthorEmail  = "podresourcecalculator@localhost"
+)
+
+// remediationWorkloadKinds are the manifest kinds whose pod template is patched
+var remediationWorkloadKinds = map[string]bool{"Deployment": true, "StatefulSet": true, "DaemonSet": true}
+
+// validateGitOpsPR checks a -gitops-pr mode
+func validateGitOpsPR(mode string) error {
+	switch mode {
+	case "", GitOpsPRDryRun, GitOpsPRPush:
+		return nil
+	}
+	return fmt.Errorf("unknown mode %q, use %s or %s", mode, GitOpsPRDryRun, GitOpsPRPush)
+}
+
+// remediationRepo is a GitOps repository with the recommendations of the
+// workloads it manages
+type remediationRepo struct {
+	url  string
+	path string // Directory of the manifests within the repository, empty for all
+	recs []containerRecommendation
+}
+
+// splitRepoPath splits a repository formatted by repoWithPath into its URL and path
+func splitRepoPath(repo string) (string, string) {
+	if i := strings.LastIndex(repo, " ("); i > 0 && strings.HasSuffix(repo, ")") {
+		return repo[:i], repo[i+2 : len(repo)-1]
+	}
+	return repo, ""
+}
+
+// remediationRepos groups the changed recommendations by the GitOps repository
+// of their workload. Workloads without a known repository are skipped.
+func remediationRepos(pods []corev1.Pod, recs []containerRecommendation, gitops *gitopsIndex) []remediationRepo {
+	owners := make(map[workloadKey]string)
+	for i := range pods {
+		workload := workloadOf(&pods[i])
+		if _, ok := owners[workload]; ok {
+			continue
+		}
+		owner, _ := gitops.resolve(workload, pods[i].Labels, pods[i].Annotations)
+		owners[workload] = owner.repo
+	}
+
+	byRepo := make(map[string][]containerRecommendation)
+	for _, rec := range recs {
+		if repo := owners[rec.workload]; repo != "" && rec.changed() {
+			byRepo[repo] = append(byRepo[repo], rec)
+		}
+	}
+	repos := make([]remediationRepo, 0, len(byRepo))
+	for repo, recs := range byRepo {
+		u, path := splitRepoPath(repo)
+		repos = append(repos, remediationRepo{url: u, path: path, recs: recs})
+	}
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].url != repos[j].url {
+			return repos[i].url < repos[j].url
+		}
+		return repos[i].path < repos[j].path
+	})
+	return repos
+}
+
+// openRemediations writes the recommended requests of a snapshot back to the
+// GitOps repositories of the workloads. A failing repository is logged and
+// skipped. Dry-run diffs are written to out.
+func openRemediations(ctx context.Context, snap *clusterSnapshot, mode string, out io.Writer) {
+	recs := recommendRequests(snap.pods, snap.quantiles)
+	repos := remediationRepos(snap.pods, recs, snap.gitops)
+	if len(repos) == 0 {
+		logrus.Info("No resource recommendations for GitOps managed workloads")
+		return
+	}
+	branch := RemediationBranchPrefix + snap.collected.Format("2006-01-02")
+	for _, repo := range repos {
+		if err := remediateRepo(ctx, repo, mode, branch, out); err != nil {
+			logrus.Warnf("Failed to remediate %s: %v", repoWithPath(repo.url, repo.path), err)
+		}
+	}
+}
+
+// remediateRepo clones a repository into a temporary directory, patches the
+// manifests of its workloads and prints the diff or pushes a branch
+func remediateRepo(ctx context.Context, repo remediationRepo, mode, branch string, out io.Writer) error {
+	dir, err := os.MkdirTemp("", "podresourcecalculator-remediation-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	if _, err := runGit(ctx, "", "clone", "--quiet", "--depth", "1", repo.url, dir); err != nil {
+		return err
+	}
+	root := filepath.Join(dir, filepath.FromSlash(repo.path))
+	if sep := string(filepath.Separator); !strings.HasPrefix(root+sep, filepath.Clean(dir)+sep) {
+		return fmt.Errorf("path %q leaves the repository", repo.path)
+	}
+	patched, err := patchManifests(root, repo.recs)
+	if err != nil {
+		return err
+	}
+	if patched == 0 {
+		logrus.Infof("No manifests of %s found in %s", pluralize(len(repo.recs), "recommended container"), repoWithPath(repo.url, repo.path))
+		return nil
+	}
+
+	if mode != GitOpsPRPush {
+		diff, err := runGit(ctx, dir, "diff")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(out, diff)
+		return err
+	}
+
+	if _, err := runGit(ctx, dir, "checkout", "--quiet", "-b", branch); err != nil {
+		return err
+	}
+	var identity []string
+	if name, _ := runGit(ctx, dir, "config", "user.email"); strings.TrimSpace(name) == "" {
+		identity = []string{"-c", "user.name=" + RemediationAuthorName, "-c", "user.email=" + RemediationAuthorEmail}
+	}
+	message := fmt.Sprintf("Right-size resource requests of %s\n\nRecommended from the usage quantiles by PodResourceCalculator.", pluralize(patched, "container"))
+	if _, err := runGit(ctx, dir, append(identity, "commit", "--quiet", "-am", message)...); err != nil {
+		return err
+	}
+	if _, err := runGit(ctx, dir, "push", "--quiet", "origin", branch); err != nil {
+		return err
+	}
+	logrus.Infof("Pushed %s to %s, open the pull request at %s", branch, repo.url, compareURL(repo.url, branch))
+	return nil
+}
+
+// runGit runs git in dir with the caller's credentials and returns its output
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// compareURL returns the web page opening a pull request of branch: a GitLab
+// merge request, else the compare view of GitHub and Gitea
+func compareURL(repoURL, branch string) string {
+	web := strings.TrimSuffix(repoURL, ".git")
+	if rest, ok := strings.CutPrefix(web, "git@"); ok {
+		host, path, _ := strings.Cut(rest, ":")
+		web = "https://" + host + "/" + path
+	} else if u, err := url.Parse(web); err == nil && u.Host != "" {
+		u.Scheme, u.User = "https", nil
+		web = u.String()
+	}
+	if strings.Contains(web, "gitlab") {
+		return web + "/-/merge_requests/new?merge_request[source_branch]=" + url.QueryEscape(branch)
+	}
+	return web + "/compare/" + branch + "?expand=1"
+}
+
+// patchManifests sets the recommended requests in the workload manifests
+// below root and returns the number of patched containers
+func patchManifests(root string, recs []containerRecommendation) (int, error) {
+	patched := 0
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		n, err := patchManifestFile(path, recs)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		patched += n
+		return nil
+	})
+	return patched, err
+}
+
+// patchManifestFile patches the workload documents of one YAML file and
+// rewrites it when a container changed. Only the changed documents are
+// touched: changed request and limit values are replaced in place, keeping the
+// quoting, comments and indentation of the file; a document that needed new
+// keys is encoded again with its own indentation. The other documents stay
+// byte for byte.
+func patchManifestFile(path string, recs []containerRecommendation) (int, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // Path within the cloned repository
+	if err != nil {
+		return 0, err
+	}
+	chunks := splitDocuments(data)
+	docs := make([]*yaml.Node, len(chunks))
+	for i, chunk := range chunks {
+		var doc yaml.Node
+		if err := yaml.Unmarshal(chunk, &doc); err != nil {
+			return 0, nil // Templates and other non-YAML files are not manifests
+		}
+		docs[i] = &doc
+	}
+
+	patched := 0
+	var out bytes.Buffer
+	for i, doc := range docs {
+		original := scalarValues(doc)
+		n := patchWorkloadDocument(doc, recs)
+		if n == 0 {
+			out.Write(chunks[i])
+			continue
+		}
+		patched += n
+		chunk, ok := patchScalars(chunks[i], doc, original)
+		if !ok {
+			if chunk, err = encodeDocument(chunks[i], doc); err != nil {
+				return 0, err
+			}
+		}
+		out.Write(chunk)
+	}
+	if patched == 0 {
+		return 0, nil
+	}
+	return patched, os.WriteFile(path, out.Bytes(), 0o644) //nolint:gosec // Keeps the manifest readable
+}
+
+// splitDocuments splits a YAML stream before each "---" line; each part is one
+// document with its separator and the parts join to the original bytes
+func splitDocuments(data []byte) [][]byte {
+	var chunks [][]byte
+	start := 0
+	for offset := 0; offset < len(data); {
+		end := bytes.IndexByte(data[offset:], '\n') + 1
+		if end == 0 {
+			end = len(data) - offset
+		}
+		line := data[offset : offset+end]
+		if offset > start && bytes.HasPrefix(line, []byte("---")) && (len(line) == 3 || line[3] == ' ' || line[3] == '\n' || line[3] == '\r') {
+			chunks = append(chunks, data[start:offset])
+			start = offset
+		}
+		offset += end
+	}
+	return append(chunks, data[start:])
+}
+
+// scalarValue is the value of a document node as it was decoded
+type scalarValue struct {
+	value string
+	style yaml.Style
+}
+
+// scalarValues returns the nodes of a document before patching
+func scalarValues(node *yaml.Node) map[*yaml.Node]scalarValue {
+	values := make(map[*yaml.Node]scalarValue)
+	var walk func(*yaml.Node)
+	walk = func(n *yaml.Node) {
+		values[n] = scalarValue{n.Value, n.Style}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(node)
+	return values
+}
+
+// patchScalars replaces the changed scalars of a patched document in its
+// source chunk. ok is false when the patch added nodes, which have no source
+// position, or a changed scalar is not found where it was decoded.
+func patchScalars(chunk []byte, doc *yaml.Node, original map[*yaml.Node]scalarValue) (_ []byte, ok bool) {
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var lineStarts []int
+	for i := 0; i < len(chunk); i++ {
+		if i == 0 || chunk[i-1] == '\n' {
+			lineStarts = append(lineStarts, i)
+		}
+	}
+
+	var edits []edit
+	ok = true
+	var walk func(*yaml.Node)
+	walk = func(n *yaml.Node) {
+		was, known := original[n]
+		switch {
+		case !known:
+			ok = false // Added by the patch
+			return
+		case n.Kind == yaml.ScalarNode && n.Value != was.value:
+			start, end, found := scalarRange(chunk, lineStarts, n.Line, n.Column, was)
+			if !found {
+				ok = false
+				return
+			}
+			// Quantities need no quotes unless they would read as numbers;
+			// those keep the quotes of the source
+			text := n.Value
+			if _, err := strconv.ParseFloat(text, 64); err == nil {
+				switch was.style {
+				case yaml.DoubleQuotedStyle:
+					text = `"` + text + `"`
+				case yaml.SingleQuotedStyle:
+					text = "'" + text + "'"
+				}
+			}
+			edits = append(edits, edit{start, end, text})
+		}
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if !ok {
+		return nil, false
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	patched := append([]byte(nil), chunk...)
+	for _, e := range edits {
+		patched = append(patched[:e.start], append([]byte(e.text), patched[e.end:]...)...)
+	}
+	return patched, true
+}
+
+// scalarRange returns the byte range of a plain or quoted scalar decoded at
+// a 1-based line and column of chunk; found is false when the source there
+// is not the scalar
+func scalarRange(chunk []byte, lineStarts []int, line, column int, was scalarValue) (start, end int, found bool) {
+	if line < 1 || line > len(lineStarts) || column < 1 {
+		return 0, 0, false
+	}
+	start = lineStarts[line-1]
+	for i := 1; i < column && start < len(chunk); i++ { // Columns count characters
+		_, size := utf8.DecodeRune(chunk[start:])
+		start += size
+	}
+	rest := chunk[start:]
+	switch was.style {
+	case 0:
+		if !bytes.HasPrefix(rest, []byte(was.value)) {
+			return 0, 0, false
+		}
+		end = start + len(was.value)
+		if end < len(chunk) && !strings.ContainsRune(" \t\r\n", rune(chunk[end])) {
+			return 0, 0, false
+		}
+		return start, end, true
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		quote := byte('"')
+		if was.style == yaml.SingleQuotedStyle {
+			quote = '\''
+		}
+		// Quantities need no escapes: the source must be the quoted value
+		token := string(quote) + was.value + string(quote)
+		if !bytes.HasPrefix(rest, []byte(token)) {
+			return 0, 0, false
+		}
+		return start, start + len(token), true
+	}
+	return 0, 0, false
+}
+
+// encodeDocument encodes a patched document with the indentation of its
+// source chunk, keeping the document separator
+func encodeDocument(chunk []byte, doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	if bytes.HasPrefix(chunk, []byte("---")) {
+		end := bytes.IndexByte(chunk, '\n')
+		if end < 0 {
+			end = len(chunk) - 1
+		}
+		buf.Write(chunk[:end+1])
+	}
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(documentIndent(chunk))
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// documentIndent returns the indentation of the first nested mapping key of a
+// document, 2 when it has none
+func documentIndent(chunk []byte) int {
+	for _, line := range strings.Split(string(chunk), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if indent >= 2 && trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "-") {
+			return indent
+		}
+	}
+	return 2
+}
+
+// patchWorkloadDocument sets the recommended requests of the containers of a
+// Deployment, StatefulSet or DaemonSet document. Manifests without a
+// namespace match any namespace.
+func patchWorkloadDocument(doc *yaml.Node, recs []containerRecommendation) int {
+	root := doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+	kind := yamlValue(root, "kind")
+	if kind == nil || !remediationWorkloadKinds[kind.Value] {
+		return 0
+	}
+	metadata := yamlValue(root, "metadata")
+	name, namespace := yamlValue(metadata, "name"), yamlValue(metadata, "namespace")
+	if name == nil {
+		return 0
+	}
+	podSpec := yamlValue(yamlValue(yamlValue(root, "spec"), "template"), "spec")
+
+	patched := 0
+	for _, rec := range recs {
+		if rec.workload.kind != kind.Value || rec.workload.name != name.Value ||
+			(namespace != nil && namespace.Value != rec.workload.namespace) {
+			continue
+		}
+		for _, list := range []string{"containers", "initContainers"} {
+			containers := yamlValue(podSpec, list)
+			if containers == nil || containers.Kind != yaml.SequenceNode {
+				continue
+			}
+			for _, c := range containers.Content {
+				if n := yamlValue(c, "name"); n != nil && n.Value == rec.container {
+					patchContainerResources(c, rec)
+					patched++
+				}
+			}
+		}
+	}
+	return patched
+}
+
+// patchContainerResources sets the requests of a container node and raises
+// limits below them
+func patchContainerResources(container *yaml.Node, rec containerRecommendation) {
+	resources := yamlMapping(container, "resources")
+	requests := yamlMapping(resources, "requests")
+	cpu := resource.NewMilliQuantity(rec.cpu, resource.DecimalSI)
+	mem := resource.NewQuantity(rec.mem, resource.BinarySI)
+	yamlSetScalar(requests, "cpu", cpu.String())
+	yamlSetScalar(requests, "memory", mem.String())
+
+	limits := yamlValue(resources, "limits")
+	for key, request := range map[string]*resource.Quantity{"cpu": cpu, "memory": mem} {
+		if limit := yamlValue(limits, key); limit != nil {
+			if q, err := resource.ParseQuantity(limit.Value); err == nil && q.Cmp(*request) < 0 {
+				yamlSetScalar(limits, key, request.String())
+			}
+		}
+	}
+}
+
+// yamlValue returns the value of key in a mapping node, nil when missing. Nil-safe.
+func yamlValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlMapping returns the mapping of key in a mapping node, added when missing
+func yamlMapping(node *yaml.Node, key string) *yaml.Node {
+	if value := yamlValue(node, key); value != nil && value.Kind == yaml.MappingNode {
+		return value
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	yamlSet(node, key, value)
+	return value
+}
+
+// yamlSetScalar sets key in a mapping node to a string scalar
+func yamlSetScalar(node *yaml.Node, key, value string) {
+	if existing := yamlValue(node, key); existing != nil && existing.Kind == yaml.ScalarNode {
+		existing.Value, existing.Tag, existing.Style = value, "!!str", 0
+		return
+	}
+	yamlSet(node, key, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+}
+
+// yamlSet sets key in a mapping node, replacing its value
+func yamlSet(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSplitRepoPath(t *testing.T) {
+	tests := []struct {
+		repo, wantURL, wantPath string
+	}{
+		{"https://git.example.com/shop.git (deploy/prod)", "https://git.example.com/shop.git", "deploy/prod"},
+		{"https://git.example.com/shop.git", "https://git.example.com/shop.git", ""},
+		{repoWithPath("git@example.com:org/shop.git", "./apps"), "git@example.com:org/shop.git", "apps"},
+	}
+	for _, tt := range tests {
+		if u, path := splitRepoPath(tt.repo); u != tt.wantURL || path != tt.wantPath {
+			t.Errorf("splitRepoPath(%q) = %q, %q", tt.repo, u, path)
+		}
+	}
+}
+
+func TestCompareURL(t *testing.T) {
+	tests := []struct {
+		repo, want string
+	}{
+		{"https://github.com/org/shop.git", "https://github.com/org/shop/compare/resource-recommendations/2024-05-10?expand=1"},
+		{"git@github.com:org/shop.git", "https://github.com/org/shop/compare/resource-recommendations/2024-05-10?expand=1"},
+		{"https://token@gitlab.example.com/org/shop", "https://gitlab.example.com/org/shop/-/merge_requests/new?merge_request[source_branch]=resource-recommendations%2F2024-05-10"},
+	}
+	for _, tt := range tests {
+		if got := compareURL(tt.repo, "resource-recommendations/2024-05-10"); got != tt.want {
+			t.Errorf("compareURL(%q) = %q, want %q", tt.repo, got, tt.want)
+		}
+	}
+}
+
+const testWebManifest = `# Web frontend
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          resources:
+            requests:
+              cpu: "1" # Sized for launch day
+              memory: 256Mi
+            limits:
+              cpu: 200m
+              memory: 1Gi
+        - name: proxy
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+func TestPatchWorkloadDocument(t *testing.T) {
+	web := workloadKey{namespace: "shop", kind: "Deployment", name: "web"}
+	recs := []containerRecommendation{
+		{workload: web, container: "app", cpu: 345, mem: 250 << 20},
+		{workload: web, container: "proxy", cpu: 50, mem: 64 << 20},
+		{workload: workloadKey{namespace: "shop", kind: "StatefulSet", name: "web"}, container: "app", cpu: 1, mem: 1},
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(testWebManifest))
+	var deployment, service yaml.Node
+	if err := decoder.Decode(&deployment); err != nil {
+		t.Fatal(err)
+	}
+	if err := decoder.Decode(&service); err != nil {
+		t.Fatal(err)
+	}
+	if n := patchWorkloadDocument(&service, recs); n != 0 {
+		t.Errorf("patched %d containers of a Service", n)
+	}
+	if n := patchWorkloadDocument(&deployment, recs); n != 2 {
+		t.Fatalf("patched %d containers, want 2", n)
+	}
+
+	out, err := yaml.Marshal(&deployment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"cpu: 345m # Sized for launch day",
+		"memory: 250Mi",
+		"cpu: 345m\n", // Limit raised to the request
+		"memory: 1Gi", // Limit above the request kept
+		"memory: 64Mi",
+		"# Web frontend",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("patched manifest lacks %q:\n%s", want, out)
+		}
+	}
+
+	// Manifests of another namespace are not touched
+	other := yaml.Node{}
+	if err := yaml.Unmarshal([]byte("kind: Deployment\nmetadata:\n  name: web\n  namespace: staging\n"), &other); err != nil {
+		t.Fatal(err)
+	}
+	if n := patchWorkloadDocument(&other, recs); n != 0 {
+		t.Errorf("patched %d containers of another namespace", n)
+	}
+}
+
+func TestOpenRemediations(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		out, err := runGit(ctx, dir, append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// A repository with the manifest in deploy/, served as a bare origin
+	work := filepath.Join(dir, "work")
+	if err := os.MkdirAll(filepath.Join(work, "deploy"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(work, "deploy", "web.yaml"), []byte(testWebManifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	git(work, "init", "--quiet")
+	git(work, "add", "-A")
+	git(work, "commit", "--quiet", "-m", "Initial")
+	origin := filepath.Join(dir, "origin.git")
+	git(dir, "clone", "--quiet", "--bare", work, origin)
+	repo := "file://" + origin
+
+	hash := map[string]string{"pod-template-hash": "abc"}
+	snap := &clusterSnapshot{
+		collected: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC),
+		pods:      []corev1.Pod{testDeliveryPod("web-1", "web-abc", hash, "1")},
+		quantiles: &usageQuantiles{quantiles: []float64{0.95}, containers: map[string][]metricsSample{
+			"shop/web-1/app": {{cpu: 300, mem: 200 << 20}},
+		}},
+		gitops: &gitopsIndex{
+			workloads: map[workloadKey]gitopsOwner{{namespace: "shop", kind: "Deployment", name: "web"}: {tool: GitOpsToolArgoCD, app: "shop"}},
+			repos:     map[string]string{GitOpsToolArgoCD + "|shop": repoWithPath(repo, "deploy")},
+		},
+	}
+
+	var diff bytes.Buffer
+	openRemediations(ctx, snap, GitOpsPRDryRun, &diff)
+	if !strings.Contains(diff.String(), "+              cpu: 345m # Sized for launch day") {
+		t.Errorf("dry-run diff:\n%s", diff.String())
+	}
+	if branches := git(origin, "branch", "--list"); strings.Contains(branches, RemediationBranchPrefix) {
+		t.Errorf("dry-run pushed a branch: %s", branches)
+	}
+
+	openRemediations(ctx, snap, GitOpsPRPush, &diff)
+	pushed := git(origin, "show", RemediationBranchPrefix+"2024-05-10:deploy/web.yaml")
+	if !strings.Contains(pushed, "memory: 250Mi") || !strings.Contains(pushed, "kind: Service") {
+		t.Errorf("pushed manifest:\n%s", pushed)
+	}
+}
+
+func TestPatchManifestFile(t *testing.T) {
+	const service = `---
+# Untouched: flow style, quoting and indentation stay as written
+apiVersion: v1
+kind: Service
+metadata:
+    name: web
+    labels: {app: web, tier: 'frontend'}
+spec:
+    ports:
+        -   port: 80
+`
+	const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+    name: web
+spec:
+    template:
+        spec:
+            containers:
+                -   name: app
+                    resources:
+                        requests:
+                            cpu: '1'  # Sized for launch day
+                            memory: "256Mi"
+`
+	web := workloadKey{namespace: "shop", kind: "Deployment", name: "web"}
+
+	t.Run("values replaced in place", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "web.yaml")
+		if err := os.WriteFile(path, []byte(deployment+service), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		n, err := patchManifestFile(path, []containerRecommendation{{workload: web, container: "app", cpu: 2000, mem: 250 << 20}})
+		if err != nil || n != 1 {
+			t.Fatalf("patched %d, %v", n, err)
+		}
+		got, _ := os.ReadFile(path)
+		want := strings.Replace(strings.Replace(deployment, `cpu: '1'`, `cpu: '2'`, 1), `"256Mi"`, "250Mi", 1) + service
+		if string(got) != want {
+			t.Errorf("patched file:\n%s\nwant:\n%s", got, want)
+		}
+	})
+
+	t.Run("new keys re-encode only their document", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "web.yaml")
+		sidecar := deployment + "                -   name: proxy\n"
+		if err := os.WriteFile(path, []byte(sidecar+service), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		n, err := patchManifestFile(path, []containerRecommendation{{workload: web, container: "proxy", cpu: 50, mem: 64 << 20}})
+		if err != nil || n != 1 {
+			t.Fatalf("patched %d, %v", n, err)
+		}
+		got, _ := os.ReadFile(path)
+		patched, rest, found := strings.Cut(string(got), "---\n")
+		if !found || "---\n"+rest != service {
+			t.Errorf("untouched document changed:\n%s", got)
+		}
+		if !strings.Contains(patched, "\n    name: web\n") || !strings.Contains(patched, "memory: 64Mi") || !strings.Contains(patched, "# Sized for launch day") {
+			t.Errorf("patched document lost its indentation or comments:\n%s", patched)
+		}
+	})
+
+	t.Run("unmatched file untouched", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "web.yaml")
+		if err := os.WriteFile(path, []byte(service), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := patchManifestFile(path, []containerRecommendation{{workload: web, container: "app", cpu: 1, mem: 1}}); err != nil || n != 0 {
+			t.Errorf("patched %d, %v", n, err)
+		}
+	})
+}
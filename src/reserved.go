@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// nodeReservation is the capacity a node withholds from pods: kube-reserved,
+// system-reserved and, for memory, the hard eviction threshold. The API only
+// exposes their sum as capacity minus allocatable. With -kubelet-config, the
+// eviction thresholds read from the kubelet are added.
+type nodeReservation struct {
+	name, pool       string
+	nodes            int   // 1 for a node, the node count for pool and cluster totals
+	capCPU, allocCPU int64 // millicores
+	capMem, allocMem int64 // bytes
+	configs          int   // Nodes whose kubelet config was read
+	hardEvict        int64 // Hard memory eviction threshold in bytes
+	softEvict        int64 // Soft memory eviction threshold in bytes
+	evictMargin      int64 // Allocatable memory above the soft threshold, see kubeletEviction.margin
+	reqMem           int64 // Memory requests of the pods on the node
+}
+
+func (r nodeReservation) reservedCPU() int64 { return r.capCPU - r.allocCPU }
+func (r nodeReservation) reservedMem() int64 { return r.capMem - r.allocMem }
+
+// usableMem returns the allocatable memory pods can request before the kubelet
+// starts evicting them at the soft threshold
+func (r nodeReservation) usableMem() int64 { return r.allocMem - r.evictMargin }
+
+// applyKubeletEvictions adds the eviction thresholds of the kubelet configs and
+// the memory requests of the pods on each node
+func applyKubeletEvictions(reservations []nodeReservation, evictions kubeletEvictions, pods []corev1.Pod) {
+	requested := make(map[string]int64)
+	for i := range pods {
+		if pods[i].Spec.NodeName != "" && isActivePod(&pods[i]) {
+			_, mem := podRequests(&pods[i])
+			requested[pods[i].Spec.NodeName] += mem
+		}
+	}
+	for i := range reservations {
+		r := &reservations[i]
+		r.reqMem = requested[r.name]
+		if e, ok := evictions[r.name]; ok {
+			r.configs, r.hardEvict, r.softEvict, r.evictMargin = 1, e.hardMem, e.softMem, e.margin()
+		}
+	}
+}
+
+// nodeReservations returns the reservation of each node by name
+func nodeReservations(nodes *corev1.NodeList) []nodeReservation {
+	if nodes == nil {
+		return nil
+	}
+	result := make([]nodeReservation, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		result = append(result, nodeReservation{
+			name:     node.Name,
+			pool:     nodePool(node),
+			nodes:    1,
+			capCPU:   node.Status.Capacity.Cpu().MilliValue(),
+			allocCPU: node.Status.Allocatable.Cpu().MilliValue(),
+			capMem:   node.Status.Capacity.Memory().Value(),
+			allocMem: node.Status.Allocatable.Memory().Value(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+// reservationsByPool sums node reservations per node pool, preceded by a
+// "Cluster" total
+func reservationsByPool(reservations []nodeReservation) []nodeReservation {
+	if len(reservations) == 0 {
+		return nil
+	}
+	cluster := nodeReservation{name: "Cluster"}
+	pools := make(map[string]nodeReservation)
+	for _, r := range reservations {
+		pool := pools[r.pool]
+		pool.name = r.pool
+		for _, p := range []*nodeReservation{&pool, &cluster} {
+			p.nodes++
+			p.capCPU += r.capCPU
+			p.allocCPU += r.allocCPU
+			p.capMem += r.capMem
+			p.allocMem += r.allocMem
+			p.configs += r.configs
+			p.hardEvict += r.hardEvict
+			p.softEvict += r.softEvict
+			p.evictMargin += r.evictMargin
+			p.reqMem += r.reqMem
+		}
+		pools[r.pool] = pool
+	}
+
+	result := []nodeReservation{cluster}
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, pools[name])
+	}
+	return result
+}
+
+// createReservedSheet lists the capacity each node withholds from pods,
+// followed by the totals per node pool and for the cluster. Eviction columns are
+// added when kubelet configs were read.
+func createReservedSheet(f *excelize.File, reservations []nodeReservation, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create kubelet reserved sheet: %w", err)
+	}
+
+	headers := []interface{}{
+		"Node", "Node Pool", "Capacity CPU (cores)", "Allocatable CPU (cores)", "Reserved CPU (cores)", "Reserved CPU %",
+		"Capacity Memory (Gi)", "Allocatable Memory (Gi)", "Reserved Memory (Gi)", "Reserved Memory %",
+	}
+	evictions := false
+	for _, r := range reservations {
+		evictions = evictions || r.configs > 0
+	}
+	if evictions {
+		headers = append(headers, "Hard Eviction Memory (Gi)", "Soft Eviction Memory (Gi)", "Usable Memory before Eviction (Gi)",
+			"Request Memory (Gi)", "Memory Headroom before Eviction (Gi)")
+	}
+	if err := export.SetRow(f, sheetName, 1, headers, "kubelet reserved headers"); err != nil {
+		return err
+	}
+
+	writeRow := func(row int, first interface{}, second interface{}, r nodeReservation) error {
+		data := []interface{}{
+			first,
+			second,
+			milliToCores(r.capCPU),
+			milliToCores(r.allocCPU),
+			milliToCores(r.reservedCPU()),
+			ratio(r.reservedCPU(), r.capCPU),
+			bytesToGi(r.capMem),
+			bytesToGi(r.allocMem),
+			bytesToGi(r.reservedMem()),
+			ratio(r.reservedMem(), r.capMem),
+		}
+		if evictions && r.configs > 0 {
+			data = append(data, bytesToGi(r.hardEvict), bytesToGi(r.softEvict), bytesToGi(r.usableMem()),
+				bytesToGi(r.reqMem), bytesToGi(r.usableMem()-r.reqMem))
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("'%s'", r.name)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("E%d", row), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, fmt.Sprintf("F%d", row), fmt.Sprintf("F%d", row), export.PercentStyle(f, "0.0%"))
+		f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("I%d", row), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, fmt.Sprintf("J%d", row), fmt.Sprintf("J%d", row), export.PercentStyle(f, "0.0%"))
+		if evictions {
+			f.SetCellStyle(sheetName, fmt.Sprintf("K%d", row), fmt.Sprintf("O%d", row), export.DecimalStyle(f, false))
+		}
+		return nil
+	}
+
+	row := 2
+	for _, r := range reservations {
+		if err := writeRow(row, r.name, r.pool, r); err != nil {
+			return err
+		}
+		row++
+	}
+
+	// Totals per node pool, cluster first
+	row++
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Reserved by Node Pool")
+	f.SetCellValue(sheetName, fmt.Sprintf("B%d", row), "Nodes")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), export.BoldStyle(f))
+	row++
+	for i, r := range reservationsByPool(reservations) {
+		if err := writeRow(row, r.name, r.nodes, r); err != nil {
+			return err
+		}
+		if i == 0 {
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), export.BoldStyle(f))
+		}
+		row++
+	}
+
+	row++
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Reserved = capacity - allocatable: kube-reserved, system-reserved and, for memory, the hard eviction threshold of the kubelet.")
+	if evictions {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row+1), "Usable before eviction = allocatable - (soft - hard eviction threshold): the kubelet evicts pods once free memory falls below the soft threshold. Pool totals count nodes without a readable kubelet config at their allocatable.")
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 32)
+	f.SetColWidth(sheetName, "B", "B", 20)
+	f.SetColWidth(sheetName, "C", "O", 22)
+
+	return nil
+}
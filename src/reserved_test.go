@@ -0,0 +1,101 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNodeReservations(t *testing.T) {
+	node := func(name, pool, capCPU, allocCPU, capMem, allocMem string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": pool}},
+			Status: corev1.NodeStatus{
+				Capacity:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(capCPU), corev1.ResourceMemory: resource.MustParse(capMem)},
+				Allocatable: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(allocCPU), corev1.ResourceMemory: resource.MustParse(allocMem)},
+			},
+		}
+	}
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		node("worker-b", "general", "4", "3920m", "16Gi", "15Gi"),
+		node("worker-a", "general", "4", "3920m", "16Gi", "15Gi"),
+		node("gpu-a", "gpu", "8", "7800m", "64Gi", "60Gi"),
+	}}
+
+	got := nodeReservations(nodes)
+	if len(got) != 3 || got[0].name != "gpu-a" || got[0].reservedCPU() != 200 || got[0].reservedMem() != 4<<30 {
+		t.Fatalf("nodeReservations() = %+v", got)
+	}
+
+	pools := reservationsByPool(got)
+	want := []struct {
+		name        string
+		nodes       int
+		cpu, memory int64
+	}{
+		{"Cluster", 3, 360, 6 << 30},
+		{"general", 2, 160, 2 << 30},
+		{"gpu", 1, 200, 4 << 30},
+	}
+	if len(pools) != len(want) {
+		t.Fatalf("reservationsByPool() returned %d rows, want %d", len(pools), len(want))
+	}
+	for i, w := range want {
+		p := pools[i]
+		if p.name != w.name || p.nodes != w.nodes || p.reservedCPU() != w.cpu || p.reservedMem() != w.memory {
+			t.Errorf("pool %d = %s with %d nodes, %dm, %d bytes; want %+v", i, p.name, p.nodes, p.reservedCPU(), p.reservedMem(), w)
+		}
+	}
+
+	if nodeReservations(nil) != nil || reservationsByPool(nil) != nil {
+		t.Error("missing nodes must return no reservations")
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createReservedSheet(f, got, "Kubelet Reserved"); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := f.GetCellValue("Kubelet Reserved", "A7"); v != "Cluster" {
+		t.Errorf("A7 = %q, want the cluster total", v)
+	}
+}
+
+func TestApplyKubeletEvictions(t *testing.T) {
+	reservations := []nodeReservation{
+		{name: "worker-a", pool: "general", nodes: 1, capMem: 16 << 30, allocMem: 15 << 30},
+		{name: "worker-b", pool: "general", nodes: 1, capMem: 16 << 30, allocMem: 15 << 30},
+	}
+	pod := func(node string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{Spec: corev1.PodSpec{NodeName: node, Containers: []corev1.Container{{
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")}},
+		}}}, Status: corev1.PodStatus{Phase: phase}}
+	}
+	pods := []corev1.Pod{pod("worker-a", corev1.PodRunning), pod("worker-a", corev1.PodSucceeded), pod("worker-b", corev1.PodRunning)}
+	applyKubeletEvictions(reservations, kubeletEvictions{"worker-a": {hardMem: 1 << 30, softMem: 2 << 30}}, pods)
+
+	a, b := reservations[0], reservations[1]
+	if a.configs != 1 || a.usableMem() != 14<<30 || a.reqMem != 4<<30 {
+		t.Errorf("worker-a = %+v, want 14Gi usable and 4Gi requested", a)
+	}
+	if b.configs != 0 || b.usableMem() != 15<<30 || b.reqMem != 4<<30 {
+		t.Errorf("worker-b = %+v, want allocatable without a kubelet config", b)
+	}
+	if cluster := reservationsByPool(reservations)[0]; cluster.configs != 1 || cluster.usableMem() != 29<<30 || cluster.reqMem != 8<<30 {
+		t.Errorf("cluster = %+v", cluster)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createReservedSheet(f, reservations, "Kubelet Reserved"); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"M1": "Usable Memory before Eviction (Gi)", "M2": "14.00", "O2": "10.00", "M3": ""} {
+		if got, _ := f.GetCellValue("Kubelet Reserved", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
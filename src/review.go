@@ -0,0 +1,244 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	"sigs.k8s.io/yaml"
+)
+
+// WarningsSheetName is the sheet of the validation findings, read back by the
+// ingest-review subcommand
+const WarningsSheetName = "Warnings"
+
+// Review actions of the Action dropdown on the Warnings sheet
+const (
+	ReviewAccept      = "Accept"
+	ReviewReject      = "Reject"
+	ReviewInvestigate = "Investigate"
+)
+
+// reviewActions are the choices of the Action dropdown
+var reviewActions = []string{ReviewAccept, ReviewReject, ReviewInvestigate}
+
+// reviewDecision is the review of one finding, matched by rule and subject
+type reviewDecision struct {
+	Rule    string `json:"rule"`
+	Subject string `json:"subject"`
+	Action  string `json:"action"`
+	Owner   string `json:"owner,omitempty"`
+	Message string `json:"message,omitempty"` // The finding at review time, for the reader
+}
+
+// reviewFile is the document written by ingest-review and read by -review.
+// Accepted and rejected findings are acknowledged in later reports.
+//
+// Example file (YAML or JSON):
+//
+//	hideAcknowledged: false
+//	decisions:
+//	  - rule: namespace-without-limits
+//	    subject: namespace/batch
+//	    action: Accept
+//	    owner: data-team
+type reviewFile struct {
+	HideAcknowledged bool             `json:"hideAcknowledged,omitempty"` // Drop acknowledged findings instead of marking them
+	Decisions        []reviewDecision `json:"decisions"`
+}
+
+// loadReview reads a review file
+func loadReview(path string) (*reviewFile, error) {
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid review path: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review %s: %w", path, err)
+	}
+	return parseReview(data)
+}
+
+// parseReview parses a review document and normalizes its actions
+func parseReview(data []byte) (*reviewFile, error) {
+	var r reviewFile
+	if err := yaml.UnmarshalStrict(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse review: %w", err)
+	}
+	for i := range r.Decisions {
+		d := &r.Decisions[i]
+		if d.Rule == "" || d.Subject == "" {
+			return nil, fmt.Errorf("decision %d: rule and subject are required", i+1)
+		}
+		action, err := parseReviewAction(d.Action)
+		if err != nil {
+			return nil, fmt.Errorf("decision %d: %w", i+1, err)
+		}
+		d.Action = action
+	}
+	return &r, nil
+}
+
+// apply copies the action and owner of the decisions to the findings with the
+// same rule and subject, and drops acknowledged findings with HideAcknowledged
+func (r *reviewFile) apply(findings []validationFinding) []validationFinding {
+	if r == nil || len(r.Decisions) == 0 {
+		return findings
+	}
+	type key struct{ rule, subject string }
+	decisions := make(map[key]reviewDecision, len(r.Decisions))
+	for _, d := range r.Decisions {
+		decisions[key{d.Rule, d.Subject}] = d
+	}
+	result := findings[:0]
+	for _, finding := range findings {
+		if d, ok := decisions[key{finding.rule, finding.subject}]; ok {
+			finding.action, finding.owner = d.Action, d.Owner
+		}
+		if r.HideAcknowledged && finding.acknowledged() {
+			continue
+		}
+		result = append(result, finding)
+	}
+	return result
+}
+
+// parseReviewAction returns the dropdown spelling of an action, case-insensitive
+func parseReviewAction(action string) (string, error) {
+	for _, a := range reviewActions {
+		if strings.EqualFold(strings.TrimSpace(action), a) {
+			return a, nil
+		}
+	}
+	return "", fmt.Errorf("unknown review action '%s' (valid: %s)", action, strings.Join(reviewActions, ", "))
+}
+
+// addReviewDropdown restricts the Action cells of rows first to last to the
+// review actions
+func addReviewDropdown(f *excelize.File, sheetName, column string, first, last int) error {
+	dv := excelize.NewDataValidation(true)
+	dv.Sqref = fmt.Sprintf("%s%d:%s%d", column, first, column, last)
+	if err := dv.SetDropList(reviewActions); err != nil {
+		return fmt.Errorf("failed to create review dropdown: %w", err)
+	}
+	dv.SetError(excelize.DataValidationErrorStyleStop, "Action", "Choose "+strings.Join(reviewActions, ", "))
+	if err := f.AddDataValidation(sheetName, dv); err != nil {
+		return fmt.Errorf("failed to add review dropdown: %w", err)
+	}
+	return nil
+}
+
+// readReviewDecisions returns the findings of the Warnings sheet with an
+// action. Columns are found by their headers, so reordered or added columns
+// are fine.
+func readReviewDecisions(f *excelize.File) ([]reviewDecision, error) {
+	rows, err := f.GetRows(WarningsSheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet %s: %w", WarningsSheetName, err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("sheet %s is empty", WarningsSheetName)
+	}
+
+	columns := make(map[string]int)
+	for i, header := range rows[0] {
+		columns[header] = i
+	}
+	for _, header := range []string{"Rule", "Subject", "Message", "Action", "Owner"} {
+		if _, ok := columns[header]; !ok {
+			return nil, fmt.Errorf("sheet %s has no %s column; was it written before review columns existed?", WarningsSheetName, header)
+		}
+	}
+	cell := func(row []string, header string) string {
+		if i := columns[header]; i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var decisions []reviewDecision
+	for i, row := range rows[1:] {
+		action := cell(row, "Action")
+		if action == "" || cell(row, "Rule") == "" {
+			continue
+		}
+		d := reviewDecision{Rule: cell(row, "Rule"), Subject: cell(row, "Subject"), Owner: cell(row, "Owner"), Message: cell(row, "Message")}
+		if d.Action, err = parseReviewAction(action); err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+2, err)
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, nil
+}
+
+// ingestReviewArgs are the flags of the ingest-review subcommand
+type ingestReviewArgs struct {
+	workbook, output *string
+	verbose, quiet   *bool
+}
+
+// ingestReviewFlags defines the flags of the ingest-review subcommand
+func ingestReviewFlags() (*flag.FlagSet, *ingestReviewArgs) {
+	fs := flag.NewFlagSet("ingest-review", flag.ExitOnError)
+	return fs, &ingestReviewArgs{
+		workbook: fs.String("workbook", "", "Reviewed report workbook"),
+		output:   fs.String("output", StdoutPath, "Review file to write (YAML), - for stdout"),
+		verbose:  fs.Bool("verbose", false, "Enable verbose logging"),
+		quiet:    fs.Bool("quiet", false, "Only log errors (logs always go to stderr)"),
+	}
+}
+
+// runIngestReview implements the ingest-review subcommand: it reads the review
+// decisions of a workbook's Warnings sheet into a review file
+func runIngestReview(args []string) (err error) {
+	fs, a := ingestReviewFlags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := configureLogging(*a.verbose, *a.quiet); err != nil {
+		return err
+	}
+	if *a.workbook == "" {
+		return fmt.Errorf("-workbook is required")
+	}
+	if err := validatePath(*a.workbook); err != nil {
+		return fmt.Errorf("invalid workbook path: %w", err)
+	}
+
+	f, err := excelize.OpenFile(*a.workbook)
+	if err != nil {
+		return fmt.Errorf("failed to open workbook %s: %w", *a.workbook, err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to close workbook %s: %w", *a.workbook, cerr)
+		}
+	}()
+	decisions, err := readReviewDecisions(f)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(reviewFile{Decisions: decisions})
+	if err != nil {
+		return fmt.Errorf("failed to encode review: %w", err)
+	}
+	if *a.output == StdoutPath {
+		if _, err := stdout.Write(data); err != nil {
+			return fmt.Errorf("failed to write review to stdout: %w", err)
+		}
+		return nil
+	}
+	if err := validatePath(*a.output); err != nil {
+		return fmt.Errorf("invalid output path: %w", err)
+	}
+	if err := os.WriteFile(*a.output, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write review %s: %w", *a.output, err)
+	}
+	logrus.Infof("%s written: %s", pluralize(len(decisions), "review decision"), *a.output)
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestReadReviewDecisions(t *testing.T) {
+	findings := []validationFinding{
+		{rule: RuleCapacitySaturation, severity: severityError, subject: "cluster", message: "CPU requests at 95%"},
+		{rule: RuleNamespaceWithoutLimits, severity: severityWarn, subject: "namespace/batch", message: "Namespace 'batch' has no resource limits"},
+		{rule: RuleNamespaceWithoutLimits, severity: severityWarn, subject: "namespace/shop", message: "Namespace 'shop' has no resource limits"},
+	}
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createWarningsSheet(f, findings, WarningsSheetName); err != nil {
+		t.Fatal(err)
+	}
+	dvs, err := f.GetDataValidations(WarningsSheetName)
+	if err != nil || len(dvs) != 1 || dvs[0].Sqref != "E2:E4" {
+		t.Fatalf("data validations = %+v, %v; want a dropdown on E2:E4", dvs, err)
+	}
+
+	// The reviewer accepts batch and investigates the cluster, shop stays open
+	f.SetCellValue(WarningsSheetName, "E2", "investigate")
+	f.SetCellValue(WarningsSheetName, "F2", "platform")
+	f.SetCellValue(WarningsSheetName, "E3", ReviewAccept)
+	decisions, err := readReviewDecisions(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []reviewDecision{
+		{Rule: RuleCapacitySaturation, Subject: "cluster", Action: ReviewInvestigate, Owner: "platform", Message: "CPU requests at 95%"},
+		{Rule: RuleNamespaceWithoutLimits, Subject: "namespace/batch", Action: ReviewAccept, Message: "Namespace 'batch' has no resource limits"},
+	}
+	if len(decisions) != len(want) {
+		t.Fatalf("readReviewDecisions() = %+v, want %+v", decisions, want)
+	}
+	for i := range want {
+		if decisions[i] != want[i] {
+			t.Errorf("decision %d = %+v, want %+v", i, decisions[i], want[i])
+		}
+	}
+
+	f.SetCellValue(WarningsSheetName, "E4", "Ignore")
+	if _, err := readReviewDecisions(f); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+}
+
+func TestReviewApply(t *testing.T) {
+	review, err := parseReview([]byte(`
+decisions:
+  - {rule: namespace-without-limits, subject: namespace/batch, action: accept, owner: data-team}
+  - {rule: capacity-saturation, subject: cluster, action: Investigate}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if review.Decisions[0].Action != ReviewAccept {
+		t.Errorf("action = %q, want normalized %q", review.Decisions[0].Action, ReviewAccept)
+	}
+	findings := func() []validationFinding {
+		return []validationFinding{
+			{rule: RuleCapacitySaturation, severity: severityError, subject: "cluster"},
+			{rule: RuleNamespaceWithoutLimits, severity: severityError, subject: "namespace/batch"},
+		}
+	}
+
+	got := review.apply(findings())
+	if len(got) != 2 || got[0].action != ReviewInvestigate || got[0].acknowledged() || !got[1].acknowledged() || got[1].owner != "data-team" {
+		t.Fatalf("apply() = %+v", got)
+	}
+	rules := validationRules{failOn: severityError}
+	if err := rules.failingFindings(got); err == nil || err.(*findingsError).count != 1 {
+		t.Errorf("failingFindings() = %v, want only the unacknowledged finding", err)
+	}
+	sarif := buildSARIF(got, reportMetadata{})
+	if s := sarif.Runs[0].Results[1].Suppressions; len(s) != 1 || s[0].Justification != "Accepted in review by data-team" {
+		t.Errorf("suppressions = %+v", s)
+	}
+
+	review.HideAcknowledged = true
+	if got := review.apply(findings()); len(got) != 1 || got[0].subject != "cluster" {
+		t.Errorf("apply() with hideAcknowledged = %+v", got)
+	}
+
+	for _, doc := range []string{
+		"decisions: [{rule: x, subject: y, action: ignore}]",
+		"decisions: [{rule: x, action: Accept}]",
+		"unknown: true",
+	} {
+		if _, err := parseReview([]byte(doc)); err == nil {
+			t.Errorf("parseReview(%q) succeeded, want an error", doc)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// sortFields maps -sort-by keys to Resources sheet columns
+var sortFields = map[string]string{
+	"namespace":            "A",
+	"pod":                  "B",
+	"container":            "C",
+	"request_cpu":          "D",
+	"request_memory":       "F",
+	"limit_cpu":            "H",
+	"limit_memory":         "J",
+	"restart_count":        "M",
+	"request_storage":      "O",
+	"limit_storage":        "Q",
+	"request_gpu":          "S",
+	"limit_gpu":            "U",
+	"status":               "W",
+	"qos_class":            "X",
+	"node":                 "Y",
+	"cpu_efficiency":       "Z",
+	"memory_efficiency":    "AA",
+	"cpu_cluster_share":    "AB",
+	"memory_cluster_share": "AC",
+	"tshirt_size":          "AD",
+}
+
+// sortKey is one -sort-by key with its column index into the row data
+type sortKey struct {
+	field  string
+	column int // 0-based index into the row data
+	desc   bool
+}
+
+// parseSortKeys parses a -sort-by value like "namespace,request_cpu:desc"
+func parseSortKeys(spec string) ([]sortKey, error) {
+	var keys []sortKey
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		if part == "" {
+			continue
+		}
+
+		field, order, _ := strings.Cut(part, ":")
+		col, ok := sortFields[field]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field '%s'", field)
+		}
+
+		key := sortKey{field: field}
+		switch order {
+		case "", "asc":
+		case "desc":
+			key.desc = true
+		default:
+			return nil, fmt.Errorf("invalid sort order '%s' for field '%s' (use asc or desc)", order, field)
+		}
+
+		num, err := excelize.ColumnNameToNumber(col)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column for sort field '%s': %w", field, err)
+		}
+		key.column = num - 1
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// sortRows orders rows by the sort keys; rows with equal keys keep their order.
+// Empty cells sort last regardless of direction.
+func sortRows(rows []resourceRow, keys []sortKey) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, key := range keys {
+			a, b := rows[i].data[key.column], rows[j].data[key.column]
+			aEmpty, bEmpty := isEmptyCell(a), isEmptyCell(b)
+			if aEmpty || bEmpty {
+				if aEmpty == bEmpty {
+					continue
+				}
+				return bEmpty
+			}
+
+			cmp := compareCells(a, b)
+			if cmp == 0 {
+				continue
+			}
+			if key.desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+}
+
+// isEmptyCell reports whether a row value renders as an empty or "-" cell
+func isEmptyCell(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case string:
+		return val == "" || val == "-"
+	}
+	return false
+}
+
+// compareCells compares two row values numerically when both are numbers, else as strings
+func compareCells(a, b interface{}) int {
+	af, aNum := cellNumber(a)
+	bf, bNum := cellNumber(b)
+	if aNum && bNum {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// cellNumber converts numeric row values to float64
+func cellNumber(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case int:
+		return float64(val), true
+	case int32:
+		return float64(val), true
+	case int64:
+		return float64(val), true
+	case float64:
+		return val, true
+	}
+	return 0, false
+}
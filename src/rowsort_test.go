@@ -0,0 +1,83 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSortKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []sortKey
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"single ascending", "namespace", []sortKey{{field: "namespace", column: 0}}, false},
+		{"multi key", "request_cpu:desc, pod:asc", []sortKey{
+			{field: "request_cpu", column: 3, desc: true},
+			{field: "pod", column: 1},
+		}, false},
+		{"case insensitive", "Memory_Efficiency:DESC", []sortKey{{field: "memory_efficiency", column: 26, desc: true}}, false},
+		{"unknown field", "cost", nil, true},
+		{"invalid order", "pod:up", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSortKeys(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSortKeys(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseSortKeys(%q) = %+v, want %+v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortRows(t *testing.T) {
+	row := func(name string, ns string, cpu int64, eff interface{}) resourceRow {
+		data := make([]interface{}, 30)
+		data[0], data[2], data[3], data[25] = ns, name, cpu, eff
+		return resourceRow{data: data, context: name}
+	}
+	order := func(rows []resourceRow) []string {
+		var names []string
+		for _, r := range rows {
+			names = append(names, r.context)
+		}
+		return names
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		want []string
+	}{
+		{"no keys keeps order", "", []string{"a", "b", "c", "d"}},
+		{"numeric descending", "request_cpu:desc", []string{"c", "a", "d", "b"}},
+		{"multi key", "namespace,request_cpu:desc", []string{"c", "b", "a", "d"}},
+		{"empty cells last ascending", "cpu_efficiency", []string{"c", "a", "b", "d"}},
+		{"empty cells last descending", "cpu_efficiency:desc", []string{"a", "c", "b", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := []resourceRow{
+				row("a", "shop", 500, 0.8),
+				row("b", "search", 100, nil),
+				row("c", "search", 1000, 0.25),
+				row("d", "shop", 250, nil),
+			}
+			keys, err := parseSortKeys(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sortRows(rows, keys)
+			if got := order(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortRows(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
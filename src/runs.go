@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+)
+
+// Sheets of the multi-run workbook written with -append
+const (
+	TrendSheetName = "Trend" // One row per run
+	RunSheetPrefix = "Run "  // Dated namespace summary sheet of each run
+)
+
+// trendHeaders are the columns of the Trend sheet; the delta columns compare
+// a run with the previous row
+var trendHeaders = []string{
+	"Run", "Generated", "Pods", "Namespaces",
+	"Request CPU (cores)", "Limit CPU (cores)", "Request Memory (Gi)", "Limit Memory (Gi)",
+	"Allocatable CPU (cores)", "Allocatable Memory (Gi)", "CPU Requested %", "Memory Requested %",
+	"Δ Request CPU (cores)", "Δ Request Memory (Gi)",
+	"CPU Request Gini", "Memory Request Gini", "CPU Jain's Index", "Memory Jain's Index",
+}
+
+// TrendChartCell anchors the tenant fairness chart of the Trend sheet
+const TrendChartCell = "T2"
+
+// trendChartRange matches the series ranges of the Trend chart in its chart
+// part, the row after "$" is the last run
+var trendChartRange = regexp.MustCompile(`(<f>` + TrendSheetName + `!\$[A-Z]+\$2:\$[A-Z]+\$)\d+(</f>)`)
+
+// runTotals are the cluster totals of one run on the Trend sheet
+type runTotals struct {
+	pods, namespaces   int
+	reqCPU, limCPU     int64
+	reqMem, limMem     int64
+	allocCPU, allocMem int64
+	cpuGini, memGini   float64 // Request distribution across namespaces, see fairness.go
+	cpuJain, memJain   float64
+}
+
+// summarizeRun sums the namespace and node totals of a run
+func summarizeRun(pods int, namespaceTotals map[string]namespaceTotal, nodeTotals map[string]nodeTotal) runTotals {
+	totals := runTotals{pods: pods, namespaces: len(namespaceTotals)}
+	cpu, mem := tenantRequests(namespaceTotals)
+	totals.cpuGini, totals.memGini = giniCoefficient(cpu), giniCoefficient(mem)
+	totals.cpuJain, totals.memJain = jainsIndex(cpu), jainsIndex(mem)
+	for _, ns := range namespaceTotals {
+		totals.reqCPU += ns.reqCPU
+		totals.limCPU += ns.limCPU
+		totals.reqMem += ns.reqMem
+		totals.limMem += ns.limMem
+	}
+	for _, node := range nodeTotals {
+		totals.allocCPU += node.allocCPU
+		totals.allocMem += node.allocMem
+	}
+	return totals
+}
+
+// runSheetName returns the sheet name of a run at generated, numbered when a
+// run of the same minute is already in the workbook. Sheet names must not
+// contain ':' and are limited to 31 characters.
+func runSheetName(existing []string, generated time.Time) string {
+	taken := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		taken[strings.ToLower(name)] = true
+	}
+	base := RunSheetPrefix + generated.Format("2006-01-02 1504")
+	name := base
+	for i := 2; taken[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s (%d)", base, i)
+	}
+	return name
+}
+
+// appendRun adds the namespace summary of snap as a dated sheet to the
+// multi-run workbook at path and appends the run to its Trend sheet. The
+// workbook is created when it does not exist yet.
+func appendRun(path string, snap *clusterSnapshot) (err error) {
+	f, err := excelize.OpenFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if f, err = export.New(TrendSheetName); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to open workbook %s: %w", path, err)
+	}
+	defer func() {
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = fmt.Errorf("failed to close workbook %s: %w", path, cerr)
+		}
+	}()
+
+	if idx, _ := f.GetSheetIndex(TrendSheetName); idx < 0 {
+		if _, err := f.NewSheet(TrendSheetName); err != nil {
+			return fmt.Errorf("failed to create trend sheet: %w", err)
+		}
+	}
+	rows, err := f.GetRows(TrendSheetName)
+	if err != nil {
+		return fmt.Errorf("failed to read trend sheet: %w", err)
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(trendHeaders))
+	if len(rows) == 0 || len(rows[0]) < len(trendHeaders) {
+		// New workbook, or one written before the fairness columns were added
+		if err := f.SetSheetRow(TrendSheetName, "A1", &trendHeaders); err != nil {
+			return fmt.Errorf("failed to set headers: %w", err)
+		}
+		f.SetCellStyle(TrendSheetName, "A1", lastCol+"1", export.BoldStyle(f))
+		for col, width := range map[string]float64{"A": 24, "B": 20} {
+			if err := f.SetColWidth(TrendSheetName, col, col, width); err != nil {
+				return fmt.Errorf("failed to set column width: %w", err)
+			}
+		}
+		if err := f.SetColWidth(TrendSheetName, "C", lastCol, 16); err != nil {
+			return fmt.Errorf("failed to set column width: %w", err)
+		}
+		if len(rows) == 0 {
+			rows = [][]string{trendHeaders}
+		}
+	}
+
+	// Dated copy of the Namespaces sheet
+	sheetName := runSheetName(f.GetSheetList(), snap.collected)
+	namespaceTotals, nodeTotals := aggregateTotals(snap.pods, snap.nodes)
+	if err := createSummarySheetFromData(f, namespaceTotals, namespaceLifecycles(snap.namespaces), snap.collected, sheetName); err != nil {
+		return err
+	}
+
+	active := 0
+	for i := range snap.pods {
+		if isActivePod(&snap.pods[i]) {
+			active++
+		}
+	}
+	totals := summarizeRun(active, namespaceTotals, nodeTotals)
+	row := len(rows) + 1
+	data := []interface{}{
+		sheetName,
+		snap.collected.Format("2006-01-02 15:04"),
+		totals.pods,
+		totals.namespaces,
+		milliToCores(totals.reqCPU),
+		milliToCores(totals.limCPU),
+		bytesToGi(totals.reqMem),
+		bytesToGi(totals.limMem),
+		milliToCores(totals.allocCPU),
+		bytesToGi(totals.allocMem),
+		nil, nil, nil, nil, // Formulas below
+		totals.cpuGini,
+		totals.memGini,
+		totals.cpuJain,
+		totals.memJain,
+	}
+	if err := export.SetRow(f, TrendSheetName, row, data, fmt.Sprintf("run '%s'", sheetName)); err != nil {
+		return err
+	}
+	if err := f.SetCellHyperLink(TrendSheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("'%s'!A1", sheetName), "Location"); err != nil {
+		return fmt.Errorf("failed to link run sheet: %w", err)
+	}
+
+	// Requested share of allocatable and the change against the previous run
+	f.SetCellFormula(TrendSheetName, fmt.Sprintf("K%d", row), fmt.Sprintf("IF(I%[1]d>0,E%[1]d/I%[1]d,\"\")", row))
+	f.SetCellFormula(TrendSheetName, fmt.Sprintf("L%d", row), fmt.Sprintf("IF(J%[1]d>0,G%[1]d/J%[1]d,\"\")", row))
+	if row > 2 {
+		f.SetCellFormula(TrendSheetName, fmt.Sprintf("M%d", row), fmt.Sprintf("E%d-E%d", row, row-1))
+		f.SetCellFormula(TrendSheetName, fmt.Sprintf("N%d", row), fmt.Sprintf("G%d-G%d", row, row-1))
+	}
+	f.SetCellStyle(TrendSheetName, fmt.Sprintf("E%d", row), fmt.Sprintf("J%d", row), export.DecimalStyle(f, false))
+	f.SetCellStyle(TrendSheetName, fmt.Sprintf("K%d", row), fmt.Sprintf("L%d", row), export.PercentStyle(f, "0.0%"))
+	f.SetCellStyle(TrendSheetName, fmt.Sprintf("M%d", row), fmt.Sprintf("N%d", row), export.DecimalStyle(f, false))
+	f.SetCellStyle(TrendSheetName, fmt.Sprintf("O%d", row), fmt.Sprintf("R%d", row), export.DecimalStyle(f, false))
+
+	if !extendTrendChart(f, row) {
+		if err := addTrendChart(f, row); err != nil {
+			return err
+		}
+	}
+
+	if idx, err := f.GetSheetIndex(TrendSheetName); err == nil && idx >= 0 {
+		f.SetActiveSheet(idx)
+	}
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("failed to save workbook %s: %w", path, err)
+	}
+	return nil
+}
+
+// addTrendChart draws the tenant fairness columns of the Trend sheet over the
+// runs up to lastRow as a line chart. Runs appended before the fairness
+// columns existed are gaps.
+func addTrendChart(f *excelize.File, lastRow int) error {
+	var series []excelize.ChartSeries
+	for _, col := range []string{"O", "P", "Q", "R"} {
+		series = append(series, excelize.ChartSeries{
+			Name:       fmt.Sprintf("%s!$%s$1", TrendSheetName, col),
+			Categories: fmt.Sprintf("%s!$B$2:$B$%d", TrendSheetName, lastRow),
+			Values:     fmt.Sprintf("%s!$%s$2:$%s$%d", TrendSheetName, col, col, lastRow),
+			Marker:     excelize.ChartMarker{Symbol: "circle", Size: 5},
+		})
+	}
+	if err := f.AddChart(TrendSheetName, TrendChartCell, &excelize.Chart{
+		Type:         excelize.Line,
+		Series:       series,
+		Title:        []excelize.RichTextRun{{Text: "Tenant Fairness (namespaces)"}},
+		Legend:       excelize.ChartLegend{Position: "top"},
+		ShowBlanksAs: "gap",
+		XAxis:        excelize.ChartAxis{Title: []excelize.RichTextRun{{Text: "Generated"}}},
+		YAxis:        excelize.ChartAxis{Title: []excelize.RichTextRun{{Text: "0 = even (Gini), 1 = even (Jain's)"}}, MajorGridLines: true},
+		Dimension:    excelize.ChartDimension{Width: ChartBaseWidth, Height: ChartBaseHeight},
+	}); err != nil {
+		return fmt.Errorf("failed to add trend chart: %w", err)
+	}
+	return nil
+}
+
+// extendTrendChart extends the series of the Trend chart to lastRow and
+// reports whether the workbook has the chart. The chart part is patched in
+// place: excelize cannot update a chart, and deleting it leaves the old part
+// in the package on every append.
+func extendTrendChart(f *excelize.File, lastRow int) bool {
+	found := false
+	f.Pkg.Range(func(key, value interface{}) bool {
+		name, _ := key.(string)
+		data, ok := value.([]byte)
+		if !ok || !strings.HasPrefix(name, "xl/charts/chart") || !trendChartRange.Match(data) {
+			return true
+		}
+		f.Pkg.Store(name, trendChartRange.ReplaceAll(data, []byte(fmt.Sprintf("${1}%d${2}", lastRow))))
+		found = true
+		return true
+	})
+	return found
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestRunSheetName(t *testing.T) {
+	generated := time.Date(2024, 5, 10, 9, 5, 0, 0, time.UTC)
+	tests := []struct {
+		existing []string
+		want     string
+	}{
+		{nil, "Run 2024-05-10 0905"},
+		{[]string{"Trend", "Run 2024-05-10 0905"}, "Run 2024-05-10 0905 (2)"},
+		{[]string{"run 2024-05-10 0905", "Run 2024-05-10 0905 (2)"}, "Run 2024-05-10 0905 (3)"},
+	}
+	for _, tt := range tests {
+		got := runSheetName(tt.existing, generated)
+		if got != tt.want {
+			t.Errorf("runSheetName(%v) = %q, want %q", tt.existing, got, tt.want)
+		}
+		if len(got) > 31 {
+			t.Errorf("runSheetName(%v) = %q exceeds the sheet name limit", tt.existing, got)
+		}
+	}
+}
+
+func TestSummarizeRun(t *testing.T) {
+	got := summarizeRun(3,
+		map[string]namespaceTotal{"a": {reqCPU: 100, limCPU: 200, reqMem: 10, limMem: 20}, "b": {reqCPU: 50, reqMem: 5}},
+		map[string]nodeTotal{"10.0.0.1": {allocCPU: 4000, allocMem: 1000}, "10.0.0.2": {allocCPU: 2000, allocMem: 500}},
+	)
+	want := runTotals{pods: 3, namespaces: 2, reqCPU: 150, limCPU: 200, reqMem: 15, limMem: 20, allocCPU: 6000, allocMem: 1500,
+		cpuGini: giniCoefficient([]float64{100, 50}), memGini: giniCoefficient([]float64{10, 5}),
+		cpuJain: 0.9, memJain: 0.9}
+	if got != want {
+		t.Errorf("summarizeRun() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAppendRun(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.xlsx")
+	first := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{first, first.AddDate(0, 0, 1)} {
+		if err := appendRun(path, testAPISnapshot(at)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	wantSheets := []string{TrendSheetName, "Run 2024-05-10 1200", "Run 2024-05-11 1200"}
+	if got := f.GetSheetList(); !reflect.DeepEqual(got, wantSheets) {
+		t.Errorf("sheets = %v, want %v", got, wantSheets)
+	}
+	rows, _ := f.GetRows(TrendSheetName)
+	if len(rows) != 3 || rows[2][0] != "Run 2024-05-11 1200" || rows[2][2] != "3" {
+		t.Errorf("trend rows = %v", rows)
+	}
+	if link, target, _ := f.GetCellHyperLink(TrendSheetName, "A3"); !link || target != "'Run 2024-05-11 1200'!A1" {
+		t.Errorf("A3 links to %q, want the run sheet", target)
+	}
+	if formula, _ := f.GetCellFormula(TrendSheetName, "M3"); formula != "E3-E2" {
+		t.Errorf("M3 formula = %q, want the change against the previous run", formula)
+	}
+	if formula, _ := f.GetCellFormula(TrendSheetName, "M2"); formula != "" {
+		t.Errorf("M2 formula = %q, want none for the first run", formula)
+	}
+	if rows[0][14] != "CPU Request Gini" || rows[2][16] == "" {
+		t.Errorf("fairness columns = %v, %v", rows[0][14:], rows[2][14:])
+	}
+	// One fairness chart, extended to each appended run
+	var charts []string
+	f.Pkg.Range(func(name, data interface{}) bool {
+		if strings.HasPrefix(name.(string), "xl/charts/chart") {
+			charts = append(charts, string(data.([]byte)))
+		}
+		return true
+	})
+	if len(charts) != 1 || !strings.Contains(charts[0], "<f>Trend!$R$2:$R$3</f>") || !strings.Contains(charts[0], "<f>Trend!$B$2:$B$3</f>") {
+		t.Errorf("trend charts = %q", charts)
+	}
+}
+
+func TestAppendRunUpgradesHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs.xlsx")
+	f := excelize.NewFile()
+	_ = f.SetSheetName("Sheet1", TrendSheetName)
+	old := trendHeaders[:14]
+	_ = f.SetSheetRow(TrendSheetName, "A1", &old)
+	_ = f.SetSheetRow(TrendSheetName, "A2", &[]interface{}{"Run 2024-05-09 1200"})
+	if err := f.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := appendRun(path, testAPISnapshot(time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatal(err)
+	}
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, _ := f.GetRows(TrendSheetName)
+	if len(rows) != 3 || !reflect.DeepEqual(rows[0], trendHeaders) || rows[1][0] != "Run 2024-05-09 1200" {
+		t.Errorf("trend rows = %v", rows)
+	}
+}
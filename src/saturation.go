@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// poolSaturation sums scheduled requests and allocatable capacity of a node pool
+type poolSaturation struct {
+	pool             string
+	nodes            int
+	reqCPU, allocCPU int64 // millicores
+	reqMem, allocMem int64 // bytes
+}
+
+// nodePool returns the node pool of a node from well-known labels
+func nodePool(node *corev1.Node) string {
+	return report.NodePool(node)
+}
+
+// saturationByPool returns requests vs allocatable per node pool, preceded by a
+// "Cluster" total. Nodes without scheduled pods still count with their capacity.
+func saturationByPool(nodes *corev1.NodeList, nodeTotals map[string]nodeTotal) []poolSaturation {
+	if nodes == nil || len(nodes.Items) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]nodeTotal, len(nodeTotals))
+	for key, totals := range nodeTotals {
+		byName[key] = totals
+		if totals.nodeName != "" {
+			byName[totals.nodeName] = totals
+		}
+	}
+
+	cluster := poolSaturation{pool: "Cluster"}
+	pools := make(map[string]poolSaturation)
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		totals, ok := byName[node.Name]
+		if !ok {
+			totals = byName[getNodeIP(node)]
+		}
+
+		pool := pools[nodePool(node)]
+		pool.pool = nodePool(node)
+		for _, p := range []*poolSaturation{&pool, &cluster} {
+			p.nodes++
+			p.reqCPU += totals.reqCPU
+			p.reqMem += totals.reqMem
+			p.allocCPU += node.Status.Allocatable.Cpu().MilliValue()
+			p.allocMem += node.Status.Allocatable.Memory().Value()
+		}
+		pools[pool.pool] = pool
+	}
+
+	result := []poolSaturation{cluster}
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result = append(result, pools[name])
+	}
+	return result
+}
+
+// ratio returns part/total or nil when total is zero, leaving the cell empty
+func ratio(part, total int64) interface{} {
+	if total == 0 {
+		return nil
+	}
+	return float64(part) / float64(total)
+}
+
+// writeSaturationTable writes the requests vs allocatable table starting at row
+// with the theme's heatmap color scale on the percentage columns
+func writeSaturationTable(f *excelize.File, sheetName string, row int, saturation []poolSaturation, t theme) error {
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Capacity Saturation (requests / allocatable)")
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.HeaderStyle(f))
+	row += 2
+
+	headers := []interface{}{
+		"Node Pool", "Nodes", "CPU Requests %", "Memory Requests %",
+		"CPU Requested (cores)", "CPU Allocatable (cores)", "Memory Requested (Gi)", "Memory Allocatable (Gi)",
+	}
+	if err := export.SetRow(f, sheetName, row, headers, "saturation headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("H%d", row), export.BoldStyle(f))
+	row++
+
+	first := row
+	for _, s := range saturation {
+		data := []interface{}{
+			s.pool,
+			s.nodes,
+			ratio(s.reqCPU, s.allocCPU),
+			ratio(s.reqMem, s.allocMem),
+			milliToCores(s.reqCPU),
+			milliToCores(s.allocCPU),
+			bytesToGi(s.reqMem),
+			bytesToGi(s.allocMem),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("node pool '%s'", s.pool)); err != nil {
+			return err
+		}
+		row++
+	}
+	last := row - 1
+
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", first), fmt.Sprintf("B%d", first), export.BoldStyle(f)) // Cluster total
+	f.SetCellStyle(sheetName, fmt.Sprintf("C%d", first), fmt.Sprintf("D%d", last), export.PercentStyle(f, "0.0%"))
+	f.SetCellStyle(sheetName, fmt.Sprintf("E%d", first), fmt.Sprintf("H%d", last), export.DecimalStyle(f, false))
+
+	if err := f.SetConditionalFormat(sheetName, fmt.Sprintf("C%d:D%d", first, last), []excelize.ConditionalFormatOptions{{
+		Type:     "3_color_scale",
+		Criteria: "=",
+		MinType:  "num",
+		MidType:  "num",
+		MaxType:  "num",
+		MinValue: "0",
+		MidValue: HeatmapMidValue,
+		MaxValue: HeatmapMaxValue,
+		MinColor: t.heatmap[0],
+		MidColor: t.heatmap[1],
+		MaxColor: t.heatmap[2],
+	}}); err != nil {
+		return fmt.Errorf("failed to set saturation color scale: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testPoolNode(name string, labels map[string]string, cpu, memory string) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		}},
+	}
+}
+
+func TestNodePool(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{"gke", map[string]string{"cloud.google.com/gke-nodepool": "highmem"}, "highmem"},
+		{"karpenter wins", map[string]string{"karpenter.sh/nodepool": "spot", "eks.amazonaws.com/nodegroup": "base"}, "spot"},
+		{"aks", map[string]string{"kubernetes.azure.com/agentpool": "system"}, "system"},
+		{"unlabeled", nil, report.DefaultNodePool},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := testPoolNode("n", tt.labels, "1", "1Gi")
+			if got := nodePool(&node); got != tt.want {
+				t.Errorf("nodePool() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaturationByPool(t *testing.T) {
+	pool := func(name string) map[string]string { return map[string]string{"eks.amazonaws.com/nodegroup": name} }
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		testPoolNode("a-1", pool("general"), "4", "16Gi"),
+		testPoolNode("a-2", pool("general"), "4", "16Gi"), // No pods scheduled
+		testPoolNode("b-1", pool("batch"), "8", "32Gi"),
+	}}
+	nodeTotals := map[string]nodeTotal{
+		"10.0.0.1": {nodeName: "a-1", reqCPU: 3000, reqMem: 8 * BytesPerGi},
+		"10.0.0.3": {nodeName: "b-1", reqCPU: 2000, reqMem: 8 * BytesPerGi},
+		"Unknown":  {reqCPU: 500}, // Pending pods are not on any node
+	}
+
+	got := saturationByPool(nodes, nodeTotals)
+
+	want := []poolSaturation{
+		{pool: "Cluster", nodes: 3, reqCPU: 5000, allocCPU: 16000, reqMem: 16 * BytesPerGi, allocMem: 64 * BytesPerGi},
+		{pool: "batch", nodes: 1, reqCPU: 2000, allocCPU: 8000, reqMem: 8 * BytesPerGi, allocMem: 32 * BytesPerGi},
+		{pool: "general", nodes: 2, reqCPU: 3000, allocCPU: 8000, reqMem: 8 * BytesPerGi, allocMem: 32 * BytesPerGi},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("saturationByPool() returned %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("saturationByPool()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := saturationByPool(nil, nodeTotals); got != nil {
+		t.Errorf("saturationByPool(nil) = %+v, want nil", got)
+	}
+}
+
+func TestRatio(t *testing.T) {
+	if got := ratio(1, 4); got != 0.25 {
+		t.Errorf("ratio(1, 4) = %v, want 0.25", got)
+	}
+	if got := ratio(1, 0); got != nil {
+		t.Errorf("ratio(1, 0) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+)
+
+// HPA scaling states, anomalies first
+const (
+	ScalingAboveMax = "Above max"
+	ScalingAtMax    = "At max"
+	ScalingBelowMin = "Below min"
+	ScalingOK       = "OK"
+)
+
+var scalingOrder = map[string]int{ScalingAboveMax: 0, ScalingAtMax: 1, ScalingBelowMin: 2, ScalingOK: 3}
+
+// hpaScaling is the replica state of a workload targeted by a HorizontalPodAutoscaler
+type hpaScaling struct {
+	workload         workloadKey
+	hpa              string
+	min, max         int32
+	current, desired int32
+	state            string // One of the Scaling* states
+}
+
+// anomaly reports whether the workload is pinned at or outside its HPA bounds
+func (s hpaScaling) anomaly() bool {
+	return s.state != ScalingOK
+}
+
+// hpaScalingStates classifies the current replicas of every HPA target against its
+// min/max bounds, anomalies first. Workloads pinned at max are the usual source of
+// sudden request growth.
+func hpaScalingStates(hpas []autoscalingv2.HorizontalPodAutoscaler) []hpaScaling {
+	states := make([]hpaScaling, 0, len(hpas)) // Non-nil: no HPAs still creates the sheet
+	for _, hpa := range hpas {
+		s := hpaScaling{
+			workload: workloadKey{namespace: hpa.Namespace, kind: hpa.Spec.ScaleTargetRef.Kind, name: hpa.Spec.ScaleTargetRef.Name},
+			hpa:      hpa.Name,
+			min:      1, // API default
+			max:      hpa.Spec.MaxReplicas,
+			current:  hpa.Status.CurrentReplicas,
+			desired:  hpa.Status.DesiredReplicas,
+		}
+		if hpa.Spec.MinReplicas != nil {
+			s.min = *hpa.Spec.MinReplicas
+		}
+
+		switch {
+		case s.current > s.max:
+			s.state = ScalingAboveMax
+		case s.current == s.max || s.desired >= s.max:
+			s.state = ScalingAtMax
+		case s.current < s.min:
+			s.state = ScalingBelowMin
+		default:
+			s.state = ScalingOK
+		}
+		states = append(states, s)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		if states[i].state != states[j].state {
+			return scalingOrder[states[i].state] < scalingOrder[states[j].state]
+		}
+		return states[i].workload.String() < states[j].workload.String()
+	})
+	return states
+}
+
+// createScalingSheet lists HPA targets with their replica bounds and the requests
+// added by replicas above the HPA minimum. Only workloads present in the report are listed.
+func createScalingSheet(f *excelize.File, states []hpaScaling, workloadTotals map[workloadKey]workloadTotal, sheetName string, t theme) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create HPA scaling sheet: %w", err)
+	}
+
+	headers := []string{
+		"Workload", "HPA", "Min Replicas", "Max Replicas", "Current Replicas", "Desired Replicas", "State",
+		"Request CPU (cores)", "Request Memory (Gi)", "CPU Above Min (cores)", "Memory Above Min (Gi)",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	colors := t.efficiency[0]
+	anomalyStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: colors.font},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{colors.fill}, Pattern: 1},
+	})
+	decimalStyle := export.DecimalStyle(f, false)
+
+	row := 2
+	for _, s := range states {
+		totals, ok := workloadTotals[s.workload]
+		if !ok {
+			continue
+		}
+
+		// Requests of the replicas running above the HPA minimum
+		var extraCPU, extraMem float64
+		if totals.pods > 0 && s.current > s.min {
+			extra := float64(s.current-s.min) / float64(totals.pods)
+			extraCPU = milliToCores(totals.reqCPU) * extra
+			extraMem = bytesToGi(totals.reqMem) * extra
+		}
+
+		data := []interface{}{
+			s.workload.String(),
+			s.hpa,
+			s.min,
+			s.max,
+			s.current,
+			s.desired,
+			s.state,
+			milliToCores(totals.reqCPU),
+			bytesToGi(totals.reqMem),
+			extraCPU,
+			extraMem,
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("HPA '%s'", s.hpa)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("H%d", row), fmt.Sprintf("K%d", row), decimalStyle)
+		if s.anomaly() {
+			f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("G%d", row), anomalyStyle)
+		}
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", "B", 25)
+	f.SetColWidth(sheetName, "C", "G", 14)
+	f.SetColWidth(sheetName, "H", "K", 20)
+
+	return nil
+}
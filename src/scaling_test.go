@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testHPA(name string, min *int32, max, current, desired int32) autoscalingv2.HorizontalPodAutoscaler {
+	return autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: name},
+			MinReplicas:    min,
+			MaxReplicas:    max,
+		},
+		Status: autoscalingv2.HorizontalPodAutoscalerStatus{CurrentReplicas: current, DesiredReplicas: desired},
+	}
+}
+
+func TestHPAScalingStates(t *testing.T) {
+	two := int32(2)
+	states := hpaScalingStates([]autoscalingv2.HorizontalPodAutoscaler{
+		testHPA("web", &two, 10, 4, 4),
+		testHPA("api", &two, 10, 10, 10),
+		testHPA("cart", &two, 5, 8, 5),
+		testHPA("search", &two, 10, 1, 2),
+		testHPA("worker", nil, 6, 5, 6), // Scaling up to max
+		testHPA("batch", nil, 3, 1, 1),  // Default min of 1
+	})
+
+	want := []struct {
+		name, state string
+		min         int32
+	}{
+		{"cart", ScalingAboveMax, 2},
+		{"api", ScalingAtMax, 2},
+		{"worker", ScalingAtMax, 1},
+		{"search", ScalingBelowMin, 2},
+		{"batch", ScalingOK, 1},
+		{"web", ScalingOK, 2},
+	}
+	if len(states) != len(want) {
+		t.Fatalf("hpaScalingStates() returned %d states, want %d", len(states), len(want))
+	}
+	for i, w := range want {
+		s := states[i]
+		if s.workload != (workloadKey{namespace: "shop", kind: "Deployment", name: w.name}) || s.state != w.state || s.min != w.min {
+			t.Errorf("hpaScalingStates()[%d] = %+v, want %s %s min %d", i, s, w.name, w.state, w.min)
+		}
+		if s.anomaly() != (w.state != ScalingOK) {
+			t.Errorf("%s anomaly() = %v", w.name, s.anomaly())
+		}
+	}
+
+	if states := hpaScalingStates(nil); states == nil {
+		t.Error("hpaScalingStates(nil) = nil, want empty slice")
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+)
+
+// SchemaVersion is the version of the workbook layout. Bump the minor version
+// when columns or sheets are added and the major version when columns are
+// removed, renamed or reordered, and record the change in schemaChanges.
+const SchemaVersion = "1.21"
+
+// Schema names for parsers of the workbook
+const (
+	NameSchemaVersion     = "schema_version" // Defined name with the schema version
+	ColumnNamePrefix      = "col_"           // Prefix of the per-column defined names
+	PropertySchemaVersion = "SchemaVersion"  // Custom document property
+)
+
+// schemaChange is one changelog entry of the Schema sheet
+type schemaChange struct {
+	version string
+	change  string
+}
+
+// schemaChanges is the changelog of the workbook layout, oldest first
+var schemaChanges = []schemaChange{
+	{"1.0", "First versioned layout. Resources columns have stable IDs and defined names (col_<id>) covering their data rows."},
+	{"1.1", "Container Groups sheet: requests per container name across all pods."},
+	{"1.2", "Reliability sheet: probes at risk of failing while the container is throttled at its CPU limit."},
+	{"1.3", "Startup Spikes sheet: containers whose startup usage far exceeds the steady state, with -usage-history."},
+	{"1.4", "JVM Memory sheet: heap settings of likely JVM containers against their memory limits."},
+	{"1.5", "Kubelet Reserved sheet: capacity minus allocatable per node, node pool and cluster."},
+	{"1.6", "Autoscaler Headroom sheet: overprovisioning pause pod size and replicas per node pool."},
+	{"1.7", "Baseline sheet: namespace and workload requests against a stored baseline, with -baseline."},
+	{"1.8", "Warnings sheet: Action dropdown (Accept, Reject, Investigate) and Owner columns, read back by ingest-review."},
+	{"1.9", "Pending Pods sheet: Pending pods per namespace and pod with their reason; Overview Pending Pods row."},
+	{"1.10", "Job Audit sheet: runs, concurrency and request-hours per CronJob and Job, with -job-audit."},
+	{"1.11", "CronJob Forecast sheet: peak windows of overlapping CronJob runs and their share of allocatable, with -cronjob-forecast."},
+	{"1.12", "StatefulSet Footprint sheet: per-replica and total compute and claim template storage per StatefulSet, with cost when priced."},
+	{"1.13", "Security Anomalies sheet: containers with tiny requests but high CPU limits, or without limits in sensitive namespaces."},
+	{"1.14", "Namespaces sheet: Created By column naming the provisioning system (HNC, Rancher, Argo CD, Flux, Helm) of each namespace."},
+	{"1.15", "Kubelet Reserved sheet: memory eviction thresholds, usable memory before eviction, requests and headroom per node, with -kubelet-config."},
+	{"1.16", "Topology sheet: capacity, requests and workload spread per failure domain of each -topology-keys node label."},
+	{"1.17", "Extended Resources sheet: GPU Sharing section with MIG slices and time-sliced replicas as physical GPU equivalents."},
+	{"1.18", "Compute Units sheet: namespace and workload requests normalized to the configured compute unit, with charts."},
+	{"1.19", "Resources sheet: actual CPU and memory usage from metrics-server and usage % of request after T-Shirt Size with -with-usage."},
+	{"1.20", "Resources sheet: CPU and memory usage quantile columns (e.g. P95, P99) from Prometheus with -prometheus-url, after the usage columns."},
+	{"1.21", "Events sheet: Warning events of the last -event-hours per namespace and reason, next to the namespace's pods and requests."},
+}
+
+// columnAliases maps retired column IDs to the ID of the current column, so
+// defined names used by existing parsers keep resolving after a rename. Add an
+// entry whenever a Resources header changes.
+var columnAliases = map[string]string{}
+
+// columnID returns the stable ID of a column header: lower case words joined
+// by underscores, with '%' spelled out as pct
+func columnID(header string) string {
+	var b strings.Builder
+	pending := false
+	for _, r := range strings.ReplaceAll(header, "%", " pct ") {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pending = b.Len() > 0
+			continue
+		}
+		if pending {
+			b.WriteByte('_')
+			pending = false
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// schemaColumn is a Resources column with its stable ID and defined name
+type schemaColumn struct {
+	column string // Column letter
+	header string
+	id     string
+	name   string // Defined name
+}
+
+// schemaColumns returns the columns of the Resources headers. Duplicate IDs,
+// e.g. of custom columns, are numbered in column order.
+func schemaColumns(headers []string) []schemaColumn {
+	seen := make(map[string]int, len(headers))
+	columns := make([]schemaColumn, 0, len(headers))
+	for i, header := range headers {
+		column, _ := excelize.ColumnNumberToName(i + 1)
+		id := columnID(header)
+		if id == "" {
+			id = strings.ToLower(column)
+		}
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id += "_" + strconv.Itoa(n)
+		}
+		columns = append(columns, schemaColumn{column: column, header: header, id: id, name: ColumnNamePrefix + id})
+	}
+	return columns
+}
+
+// setSchemaNames adds a defined name per Resources column covering its data
+// rows and the retired aliases of renamed columns. lastRow is the last data
+// row; an empty sheet refers to the first data row.
+func setSchemaNames(f *excelize.File, sheetName string, columns []schemaColumn, lastRow int) error {
+	if lastRow < 3 {
+		lastRow = 3
+	}
+	sheet := strings.ReplaceAll(sheetName, "'", "''")
+	refersTo := make(map[string]string, len(columns))
+	for _, c := range columns {
+		ref := fmt.Sprintf("'%s'!$%s$3:$%s$%d", sheet, c.column, c.column, lastRow)
+		refersTo[c.id] = ref
+		if err := f.SetDefinedName(&excelize.DefinedName{Name: c.name, RefersTo: ref}); err != nil {
+			return fmt.Errorf("failed to set defined name %s: %w", c.name, err)
+		}
+	}
+
+	for _, alias := range sortedAliases() {
+		ref, ok := refersTo[columnAliases[alias]]
+		if !ok {
+			continue // The column is not part of this report
+		}
+		name := ColumnNamePrefix + alias
+		if err := f.SetDefinedName(&excelize.DefinedName{Name: name, RefersTo: ref}); err != nil {
+			return fmt.Errorf("failed to set defined name %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setSchemaVersion writes the schema version as a defined name and a custom
+// document property
+func setSchemaVersion(f *excelize.File) error {
+	if err := f.SetDefinedName(&excelize.DefinedName{Name: NameSchemaVersion, RefersTo: strconv.Quote(SchemaVersion)}); err != nil {
+		return fmt.Errorf("failed to set defined name %s: %w", NameSchemaVersion, err)
+	}
+	if err := f.SetCustomProps(excelize.CustomProperty{Name: PropertySchemaVersion, Value: SchemaVersion}); err != nil {
+		return fmt.Errorf("failed to set document property %s: %w", PropertySchemaVersion, err)
+	}
+	return nil
+}
+
+// sortedAliases returns the retired column IDs in a stable order
+func sortedAliases() []string {
+	aliases := make([]string, 0, len(columnAliases))
+	for alias := range columnAliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// createSchemaSheet lists the schema version, its changelog, the Resources
+// columns with their defined names and the aliases of renamed columns
+func createSchemaSheet(f *excelize.File, columns []schemaColumn, sheetName string) error {
+	if _, err := f.NewSheet(sheetName); err != nil {
+		return fmt.Errorf("failed to create schema sheet: %w", err)
+	}
+	bold := export.BoldStyle(f)
+
+	f.SetCellValue(sheetName, "A1", "Schema Version")
+	f.SetCellValue(sheetName, "B1", SchemaVersion)
+	f.SetCellStyle(sheetName, "A1", "A1", bold)
+
+	row := 3
+	changeHeaders := []string{"Version", "Change"}
+	if err := f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &changeHeaders); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), bold)
+	row++
+	for _, c := range schemaChanges {
+		if err := export.SetRow(f, sheetName, row, []interface{}{c.version, c.change}, fmt.Sprintf("schema change %s", c.version)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	row++
+	columnHeaders := []string{"Column", "Header", "ID", "Defined Name"}
+	if err := f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &columnHeaders); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("D%d", row), bold)
+	row++
+	ids := make(map[string]bool, len(columns))
+	for _, c := range columns {
+		ids[c.id] = true
+		if err := export.SetRow(f, sheetName, row, []interface{}{c.column, c.header, c.id, c.name}, fmt.Sprintf("schema column '%s'", c.header)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	var aliasRows [][]interface{}
+	for _, alias := range sortedAliases() {
+		if ids[columnAliases[alias]] {
+			aliasRows = append(aliasRows, []interface{}{alias, ColumnNamePrefix + alias, columnAliases[alias]})
+		}
+	}
+	if len(aliasRows) > 0 {
+		row++
+		aliasHeaders := []string{"Retired ID", "Defined Name", "Current ID"}
+		if err := f.SetSheetRow(sheetName, fmt.Sprintf("A%d", row), &aliasHeaders); err != nil {
+			return fmt.Errorf("failed to set headers: %w", err)
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row), bold)
+		row++
+		for _, data := range aliasRows {
+			if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("schema alias '%s'", data[0])); err != nil {
+				return err
+			}
+			row++
+		}
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 16)
+	f.SetColWidth(sheetName, "B", "B", 60)
+	f.SetColWidth(sheetName, "C", "D", 32)
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestColumnID(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"Namespace", "namespace"},
+		{"Request CPU (m)", "request_cpu_m"},
+		{"Request GPU (str)", "request_gpu_str"},
+		{"CPU Efficiency %", "cpu_efficiency_pct"},
+		{"T-Shirt Size", "t_shirt_size"},
+		{"  Owner Email ", "owner_email"},
+		{"---", ""},
+	}
+	for _, tt := range tests {
+		if got := columnID(tt.header); got != tt.want {
+			t.Errorf("columnID(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSchemaColumns(t *testing.T) {
+	got := schemaColumns([]string{"Namespace", "Cost", "cost", "%%"})
+	want := []schemaColumn{
+		{"A", "Namespace", "namespace", "col_namespace"},
+		{"B", "Cost", "cost", "col_cost"},
+		{"C", "cost", "cost_2", "col_cost_2"},
+		{"D", "%%", "pct_pct", "col_pct_pct"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("schemaColumns() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("column %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSetSchemaNames(t *testing.T) {
+	saved := columnAliases
+	defer func() { columnAliases = saved }()
+	columnAliases = map[string]string{"req_cpu": "request_cpu_m", "gone": "missing"}
+
+	tests := []struct {
+		name    string
+		lastRow int
+		want    map[string]string
+	}{
+		{"rows", 10, map[string]string{
+			"col_namespace":     "'Resources'!$A$3:$A$10",
+			"col_request_cpu_m": "'Resources'!$B$3:$B$10",
+			"col_req_cpu":       "'Resources'!$B$3:$B$10",
+			NameSchemaVersion:   `"` + SchemaVersion + `"`,
+		}},
+		{"empty sheet", 2, map[string]string{
+			"col_namespace": "'Resources'!$A$3:$A$3",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := excelize.NewFile()
+			defer f.Close()
+			if err := setSchemaNames(f, "Resources", schemaColumns([]string{"Namespace", "Request CPU (m)"}), tt.lastRow); err != nil {
+				t.Fatal(err)
+			}
+			if err := setSchemaVersion(f); err != nil {
+				t.Fatal(err)
+			}
+
+			got := map[string]string{}
+			for _, dn := range f.GetDefinedName() {
+				got[dn.Name] = dn.RefersTo
+			}
+			for name, want := range tt.want {
+				if got[name] != want {
+					t.Errorf("%s refers to %q, want %q", name, got[name], want)
+				}
+			}
+			if _, ok := got["col_gone"]; ok {
+				t.Error("alias of a column missing from the report was defined")
+			}
+		})
+	}
+}
+
+func TestCreateSchemaSheet(t *testing.T) {
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createSchemaSheet(f, schemaColumns([]string{"Namespace", "Pod"}), "Schema"); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := f.GetRows("Schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows[0][1] != SchemaVersion {
+		t.Errorf("schema version = %q, want %q", rows[0][1], SchemaVersion)
+	}
+	last := rows[len(rows)-1]
+	if len(last) != 4 || last[0] != "B" || last[3] != "col_pod" {
+		t.Errorf("last column row = %v, want the Pod column", last)
+	}
+}
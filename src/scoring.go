@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+)
+
+// DefaultScoringModel is the scoring model used when the config selects none
+const DefaultScoringModel = report.DefaultScoringModel
+
+// scoreCluster sums the namespace requests and limits and classifies the
+// namespaces with model
+func scoreCluster(namespaceTotals map[string]namespaceTotal, nodeTotals map[string]nodeTotal, model scoringModel) report.Score {
+	totals := make(map[string]report.Totals, len(namespaceTotals))
+	for ns, t := range namespaceTotals {
+		totals[ns] = report.Totals{RequestCPU: t.reqCPU, LimitCPU: t.limCPU, RequestMemory: t.reqMem, LimitMemory: t.limMem}
+	}
+	var podCounts []int
+	for _, t := range nodeTotals {
+		podCounts = append(podCounts, t.podCount)
+	}
+	return report.ScoreCluster(totals, podCounts, model)
+}
+
+// scoringModel rates request/limit efficiency and words the recommendations of
+// the Insights sheet. Ratings and recommendations are English message IDs that
+// the sheet translates (see i18n.go).
+type scoringModel interface {
+	report.Scoring
+	// describe explains a namespace class, e.g. "< 50% efficiency"
+	describe(class report.NamespaceClass, msgs messageCatalog) string
+	// efficiencyClass names the rating of an efficiency for the webhook
+	// annotations, e.g. EfficiencyBalanced
+	efficiencyClass(eff float64) string
+}
+
+// scoringSpec is the scoring section of the config file: a model and optional
+// thresholds replacing the model's, all request/limit in percent
+//
+//	scoring:
+//	  model: strict
+//	  wellBalanced: 70
+type scoringSpec struct {
+	Model            string  `json:"model,omitempty"`            // default, strict or lenient
+	UnderProvisioned float64 `json:"underProvisioned,omitempty"` // Ratings: from here limits are too tight
+	WellBalanced     float64 `json:"wellBalanced,omitempty"`     // Ratings: from here limits fit the requests
+	OverProvisioned  float64 `json:"overProvisioned,omitempty"`  // Ratings: from here limits are generous, below severely
+	NamespaceOver    float64 `json:"namespaceOver,omitempty"`    // Namespaces below are over-provisioned
+	NamespaceUnder   float64 `json:"namespaceUnder,omitempty"`   // Namespaces above are under-provisioned
+	MinBalance       float64 `json:"minBalance,omitempty"`       // Load Balance Score below which spreading is advised
+}
+
+// thresholdScoring is the built-in scoring model: fixed efficiency bands
+type thresholdScoring struct {
+	report.Thresholds
+}
+
+// parseScoring selects the scoring model of the config file and applies its
+// threshold overrides
+func parseScoring(spec *scoringSpec) (scoringModel, error) {
+	if spec == nil {
+		spec = &scoringSpec{}
+	}
+	name := strings.TrimSpace(strings.ToLower(spec.Model))
+	if name == "" {
+		name = DefaultScoringModel
+	}
+	thresholds, ok := report.ScoringModels[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown scoring model '%s' (valid: %s)", name, strings.Join(scoringModelNames(), ", "))
+	}
+	model := thresholdScoring{thresholds}
+	for _, o := range []struct {
+		value  float64
+		target *float64
+		name   string
+	}{
+		{spec.UnderProvisioned, &model.UnderProvisioned, "underProvisioned"},
+		{spec.WellBalanced, &model.WellBalanced, "wellBalanced"},
+		{spec.OverProvisioned, &model.OverProvisioned, "overProvisioned"},
+		{spec.NamespaceOver, &model.NamespaceOver, "namespaceOver"},
+		{spec.NamespaceUnder, &model.NamespaceUnder, "namespaceUnder"},
+		{spec.MinBalance, &model.MinBalance, "minBalance"},
+	} {
+		if o.value < 0 || o.value > 100 {
+			return nil, fmt.Errorf("scoring %s must be between 0 and 100", o.name)
+		}
+		if o.value > 0 {
+			*o.target = o.value
+		}
+	}
+	if !(model.UnderProvisioned > model.WellBalanced && model.WellBalanced > model.OverProvisioned) {
+		return nil, fmt.Errorf("scoring thresholds must descend: underProvisioned %g > wellBalanced %g > overProvisioned %g", model.UnderProvisioned, model.WellBalanced, model.OverProvisioned)
+	}
+	if model.NamespaceOver >= model.NamespaceUnder {
+		return nil, fmt.Errorf("scoring namespaceOver %g must be below namespaceUnder %g", model.NamespaceOver, model.NamespaceUnder)
+	}
+	return model, nil
+}
+
+// scoringModelNames returns the sorted scoring model names
+func scoringModelNames() []string {
+	names := make([]string, 0, len(report.ScoringModels))
+	for name := range report.ScoringModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m thresholdScoring) describe(class report.NamespaceClass, msgs messageCatalog) string {
+	switch class {
+	case report.NamespaceOverProvisioned:
+		return msgs.sprintf("< %g%% efficiency", m.NamespaceOver)
+	case report.NamespaceUnderProvisioned:
+		return msgs.sprintf("> %g%% efficiency", m.NamespaceUnder)
+	default:
+		return msgs.sprintf("%g-%g%% efficiency", m.NamespaceOver, m.NamespaceUnder)
+	}
+}
+
+func (m thresholdScoring) efficiencyClass(eff float64) string {
+	switch {
+	case eff >= m.UnderProvisioned:
+		return EfficiencyUnderProvisioned
+	case eff >= m.WellBalanced:
+		return EfficiencyBalanced
+	case eff >= m.OverProvisioned:
+		return EfficiencyOverProvisioned
+	default:
+		return EfficiencySeverelyOver
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+)
+
+func TestParseScoring(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    *scoringSpec
+		want    thresholdScoring
+		wantErr bool
+	}{
+		{"default", nil, thresholdScoring{report.ScoringModels[DefaultScoringModel]}, false},
+		{"named model", &scoringSpec{Model: " Strict "}, thresholdScoring{report.ScoringModels["strict"]}, false},
+		{"threshold override", &scoringSpec{WellBalanced: 70, MinBalance: 50}, thresholdScoring{report.Thresholds{
+			UnderProvisioned: 80, WellBalanced: 70, OverProvisioned: 40, NamespaceOver: 50, NamespaceUnder: 80, MinBalance: 50,
+		}}, false},
+		{"unknown model", &scoringSpec{Model: "magic"}, thresholdScoring{}, true},
+		{"out of range", &scoringSpec{UnderProvisioned: 120}, thresholdScoring{}, true},
+		{"negative", &scoringSpec{MinBalance: -1}, thresholdScoring{}, true},
+		{"bands not descending", &scoringSpec{WellBalanced: 85}, thresholdScoring{}, true},
+		{"namespace bands crossed", &scoringSpec{NamespaceOver: 90}, thresholdScoring{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseScoring(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseScoring() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != scoringModel(tt.want) {
+				t.Errorf("parseScoring() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThresholdScoringDescribe(t *testing.T) {
+	m := thresholdScoring{report.ScoringModels[DefaultScoringModel]}
+	descriptions := map[report.NamespaceClass]string{
+		report.NamespaceOverProvisioned:  "< 50% efficiency",
+		report.NamespaceBalanced:         "50-80% efficiency",
+		report.NamespaceUnderProvisioned: "> 80% efficiency",
+	}
+	for class, want := range descriptions {
+		if got := m.describe(class, nil); got != want {
+			t.Errorf("describe(%v) = %q, want %q", class, got, want)
+		}
+	}
+}
+
+func TestScoreCluster(t *testing.T) {
+	namespaceTotals := map[string]namespaceTotal{
+		"shop":  {reqCPU: 500, limCPU: 2000, reqMem: 1 << 30, limMem: 2 << 30},
+		"batch": {reqCPU: 900, limCPU: 1000, reqMem: 1 << 30, limMem: 1 << 30},
+	}
+	nodeTotals := map[string]nodeTotal{"10.0.0.1": {podCount: 3}, "10.0.0.2": {podCount: 3}}
+	score := scoreCluster(namespaceTotals, nodeTotals, thresholdScoring{report.ScoringModels[DefaultScoringModel]})
+	if score.RequestCPU != 1400 || score.LimitCPU != 3000 || score.OverProvisioned != 1 || score.UnderProvisioned != 1 || score.Balance != 100 {
+		t.Errorf("scoreCluster() = %+v", score)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Indicators on the Security Anomalies sheet
+const (
+	AnomalyTinyRequest = "Tiny request, high CPU limit"
+	AnomalyUnlimited   = "Unlimited in sensitive namespace"
+)
+
+// Defaults of the security section of the config file
+const (
+	DefaultLimitRequestRatio = 20
+	DefaultMinCPULimit       = "2"
+)
+
+// defaultSensitiveNamespaces are used when the config file names none
+var defaultSensitiveNamespaces = []string{"default", "kube-node-lease", "kube-public", "kube-system"}
+
+// securitySpec is the security section of the config file
+type securitySpec struct {
+	SensitiveNamespaces []string `json:"sensitiveNamespaces,omitempty"` // Replace the defaults
+	LimitRequestRatio   float64  `json:"limitRequestRatio,omitempty"`   // CPU limit per request from which requests count as tiny
+	MinCPULimit         string   `json:"minCPULimit,omitempty"`         // Smallest CPU limit that counts as high
+}
+
+// securityRules are the parsed anomaly thresholds
+type securityRules struct {
+	sensitive   map[string]bool
+	ratio       float64
+	minCPULimit int64 // Millicores
+}
+
+// parseSecurityRules applies the config file settings to the defaults
+func parseSecurityRules(spec *securitySpec) (securityRules, error) {
+	if spec == nil {
+		spec = &securitySpec{}
+	}
+	rules := securityRules{sensitive: make(map[string]bool), ratio: DefaultLimitRequestRatio}
+	namespaces := spec.SensitiveNamespaces
+	if len(namespaces) == 0 {
+		namespaces = defaultSensitiveNamespaces
+	}
+	for _, ns := range namespaces {
+		rules.sensitive[ns] = true
+	}
+	if spec.LimitRequestRatio < 0 {
+		return rules, fmt.Errorf("security limitRequestRatio must not be negative")
+	}
+	if spec.LimitRequestRatio > 0 {
+		rules.ratio = spec.LimitRequestRatio
+	}
+	minCPU := spec.MinCPULimit
+	if minCPU == "" {
+		minCPU = DefaultMinCPULimit
+	}
+	q, err := resource.ParseQuantity(minCPU)
+	if err != nil || q.Sign() < 0 {
+		return rules, fmt.Errorf("invalid security minCPULimit '%s'", minCPU)
+	}
+	rules.minCPULimit = q.MilliValue()
+	return rules, nil
+}
+
+// securityAnomaly is a container whose resources look like abuse, e.g. a
+// cryptominer slipped in with a token request and room to burn a whole node
+type securityAnomaly struct {
+	namespace, pod, container string
+	workload                  workloadKey
+	node                      string
+	indicator                 string // AnomalyTinyRequest or AnomalyUnlimited
+	detail                    string
+	reqCPU, limCPU            int64 // Millicores
+	reqMem, limMem            int64 // Bytes
+	image                     string
+}
+
+// securityAnomalies flags containers, init containers included, whose CPU limit
+// reaches the minimum and the ratio times their request, and containers without
+// a CPU or memory limit in sensitive namespaces
+func securityAnomalies(pods []corev1.Pod, rules securityRules) []securityAnomaly {
+	var anomalies []securityAnomaly
+	for i := range pods {
+		pod := &pods[i]
+		containers := append(append([]corev1.Container(nil), pod.Spec.InitContainers...), pod.Spec.Containers...)
+		for _, c := range containers {
+			a := securityAnomaly{
+				namespace: pod.Namespace, pod: pod.Name, container: c.Name,
+				workload: workloadOf(pod), node: pod.Spec.NodeName, image: c.Image,
+				reqCPU: c.Resources.Requests.Cpu().MilliValue(), limCPU: c.Resources.Limits.Cpu().MilliValue(),
+				reqMem: quantityBytes(c.Resources.Requests.Memory()), limMem: quantityBytes(c.Resources.Limits.Memory()),
+			}
+			if a.limCPU > 0 && a.limCPU >= rules.minCPULimit && float64(a.limCPU) >= rules.ratio*float64(a.reqCPU) {
+				tiny := a
+				tiny.indicator = AnomalyTinyRequest
+				if a.reqCPU == 0 {
+					tiny.detail = "No CPU request"
+				} else {
+					tiny.detail = fmt.Sprintf("CPU limit is %.0fx the request", float64(a.limCPU)/float64(a.reqCPU))
+				}
+				anomalies = append(anomalies, tiny)
+			}
+			if rules.sensitive[pod.Namespace] {
+				var missing []string
+				if a.limCPU == 0 {
+					missing = append(missing, "CPU")
+				}
+				if a.limMem == 0 {
+					missing = append(missing, "memory")
+				}
+				if len(missing) > 0 {
+					unlimited := a
+					unlimited.indicator = AnomalyUnlimited
+					unlimited.detail = "No " + strings.Join(missing, " or ") + " limit"
+					anomalies = append(anomalies, unlimited)
+				}
+			}
+		}
+	}
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		if anomalies[i].indicator != anomalies[j].indicator {
+			return anomalies[i].indicator < anomalies[j].indicator // Tiny requests first
+		}
+		if anomalies[i].namespace != anomalies[j].namespace {
+			return anomalies[i].namespace < anomalies[j].namespace
+		}
+		return anomalies[i].pod < anomalies[j].pod
+	})
+	return anomalies
+}
+
+// createSecurityAnomaliesSheet counts the anomalies per indicator and lists the
+// flagged containers for security reviewers. The flags are indicators only:
+// batch jobs and cluster add-ons may match them legitimately.
+func createSecurityAnomaliesSheet(f *excelize.File, anomalies []securityAnomaly, rules securityRules, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create security anomalies sheet: %w", err)
+	}
+
+	namespaces := make([]string, 0, len(rules.sensitive))
+	for ns := range rules.sensitive {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	f.SetCellValue(sheetName, "A1", fmt.Sprintf("Potential abuse indicators: CPU limit of at least %g cores and %gx the request; no CPU or memory limit in %s",
+		milliToCores(rules.minCPULimit), rules.ratio, strings.Join(namespaces, ", ")))
+
+	if err := export.SetRow(f, sheetName, 3, []interface{}{"Indicator", "Containers", "Namespaces"}, "security summary headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, "A3", "C3", export.BoldStyle(f))
+	row := 4
+	for _, indicator := range []string{AnomalyTinyRequest, AnomalyUnlimited} {
+		containers, seen := 0, make(map[string]bool)
+		for _, a := range anomalies {
+			if a.indicator == indicator {
+				containers++
+				seen[a.namespace] = true
+			}
+		}
+		if err := export.SetRow(f, sheetName, row, []interface{}{indicator, containers, len(seen)}, "security summary"); err != nil {
+			return err
+		}
+		row++
+	}
+
+	row++
+	headers := []interface{}{
+		"Indicator", "Detail", "Namespace", "Pod", "Container", "Workload", "Node",
+		"Request CPU (cores)", "Limit CPU (cores)", "Request Memory (Gi)", "Limit Memory (Gi)", "CPU Limit / Request", "Image",
+	}
+	if err := export.SetRow(f, sheetName, row, headers, "security anomaly headers"); err != nil {
+		return err
+	}
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("M%d", row), export.BoldStyle(f))
+	row++
+	if len(anomalies) == 0 {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "No anomalies found")
+	}
+	decimalStyle := export.DecimalStyle(f, false)
+	for _, a := range anomalies {
+		data := []interface{}{
+			a.indicator, a.detail, a.namespace, a.pod, a.container, a.workload.String(), valueOrDash(a.node),
+			milliToCores(a.reqCPU), milliToCores(a.limCPU), bytesToGi(a.reqMem), bytesToGi(a.limMem), ratio(a.limCPU, a.reqCPU), a.image,
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("container '%s/%s/%s'", a.namespace, a.pod, a.container)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("H%d", row), fmt.Sprintf("L%d", row), decimalStyle)
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "B", 32)
+	f.SetColWidth(sheetName, "C", "G", 22)
+	f.SetColWidth(sheetName, "H", "L", 18)
+	f.SetColWidth(sheetName, "M", "M", 50)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseSecurityRules(t *testing.T) {
+	rules, err := parseSecurityRules(nil)
+	if err != nil || !rules.sensitive["kube-system"] || rules.ratio != DefaultLimitRequestRatio || rules.minCPULimit != 2000 {
+		t.Errorf("parseSecurityRules(nil) = %+v, %v, want defaults", rules, err)
+	}
+	rules, err = parseSecurityRules(&securitySpec{SensitiveNamespaces: []string{"vault"}, LimitRequestRatio: 10, MinCPULimit: "500m"})
+	if err != nil || rules.sensitive["kube-system"] || !rules.sensitive["vault"] || rules.ratio != 10 || rules.minCPULimit != 500 {
+		t.Errorf("parseSecurityRules() = %+v, %v", rules, err)
+	}
+
+	for _, spec := range []securitySpec{{LimitRequestRatio: -1}, {MinCPULimit: "lots"}, {MinCPULimit: "-1"}} {
+		if _, err := parseSecurityRules(&spec); err == nil {
+			t.Errorf("parseSecurityRules(%+v) expected error", spec)
+		}
+	}
+}
+
+func TestSecurityAnomalies(t *testing.T) {
+	container := func(name, reqCPU, limCPU, limMem string) corev1.Container {
+		c := corev1.Container{Name: name, Image: "example.com/" + name, Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{}, Limits: corev1.ResourceList{},
+		}}
+		if reqCPU != "" {
+			c.Resources.Requests[corev1.ResourceCPU] = resource.MustParse(reqCPU)
+		}
+		if limCPU != "" {
+			c.Resources.Limits[corev1.ResourceCPU] = resource.MustParse(limCPU)
+		}
+		if limMem != "" {
+			c.Resources.Limits[corev1.ResourceMemory] = resource.MustParse(limMem)
+		}
+		return c
+	}
+	pod := func(namespace, name string, containers ...corev1.Container) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}, Spec: corev1.PodSpec{Containers: containers}}
+	}
+	pods := []corev1.Pod{
+		pod("shop", "web", container("app", "500m", "2", "1Gi")),                                             // 4x, fine
+		pod("shop", "miner", container("xmr", "10m", "8", "1Gi")),                                            // 800x
+		pod("shop", "tiny", container("sidecar", "10m", "200m", "64Mi")),                                     // 20x but a small limit
+		pod("kube-system", "agent", container("agent", "100m", "", "")),                                      // No limits
+		pod("kube-system", "dns", container("coredns", "100m", "", "170Mi"), container("x", "", "4", "1Gi")), // No CPU limit; no request at all
+	}
+	rules, _ := parseSecurityRules(nil)
+	anomalies := securityAnomalies(pods, rules)
+	if len(anomalies) != 4 {
+		t.Fatalf("securityAnomalies() = %+v, want 4", anomalies)
+	}
+	want := []struct{ indicator, pod, detail string }{
+		{AnomalyTinyRequest, "dns", "No CPU request"},
+		{AnomalyTinyRequest, "miner", "CPU limit is 800x the request"},
+		{AnomalyUnlimited, "agent", "No CPU or memory limit"},
+		{AnomalyUnlimited, "dns", "No CPU limit"},
+	}
+	for i, w := range want {
+		if a := anomalies[i]; a.indicator != w.indicator || a.pod != w.pod || a.detail != w.detail {
+			t.Errorf("anomaly %d = %+v, want %+v", i, a, w)
+		}
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createSecurityAnomaliesSheet(f, anomalies, rules, "Security Anomalies"); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"B4": "2", "C4": "2", "B5": "2", "C5": "1", "D8": "dns", "L9": "800.00", "M9": "example.com/xmr"} {
+		if got, _ := f.GetCellValue("Security Anomalies", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
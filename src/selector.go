@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// namespaceSelector selects namespaces by name with the patterns of
+// -namespace-pattern: shell globs like team-*-prod, or regular expressions
+// written as /team-[a-z]+-prod/. A pattern matches the whole name. A nil
+// selector selects all namespaces.
+type namespaceSelector struct {
+	patterns string // As given, for the report scope
+	globs    []string
+	regexps  []*regexp.Regexp
+}
+
+// parseNamespacePatterns parses a comma separated list of patterns; empty
+// returns nil
+func parseNamespacePatterns(s string) (*namespaceSelector, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	sel := &namespaceSelector{patterns: s}
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			return nil, fmt.Errorf("empty pattern in '%s'", s)
+		}
+		if len(p) > 2 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			re, err := regexp.Compile("^(?:" + p[1:len(p)-1] + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression '%s': %w", p, err)
+			}
+			sel.regexps = append(sel.regexps, re)
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob '%s': %w", p, err)
+		}
+		sel.globs = append(sel.globs, p)
+	}
+	return sel, nil
+}
+
+// matches reports whether namespace matches any pattern
+func (s *namespaceSelector) matches(namespace string) bool {
+	if s == nil {
+		return true
+	}
+	for _, g := range s.globs {
+		if ok, _ := path.Match(g, namespace); ok {
+			return true
+		}
+	}
+	for _, re := range s.regexps {
+		if re.MatchString(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// pods returns the pods in selected namespaces
+func (s *namespaceSelector) pods(pods []corev1.Pod) []corev1.Pod {
+	if s == nil {
+		return pods
+	}
+	selected := pods[:0]
+	for _, pod := range pods {
+		if s.matches(pod.Namespace) {
+			selected = append(selected, pod)
+		}
+	}
+	return selected
+}
+
+// namespaces returns a copy of the list with the selected namespaces; nil
+// stays nil
+func (s *namespaceSelector) namespaces(list *corev1.NamespaceList) *corev1.NamespaceList {
+	if s == nil || list == nil {
+		return list
+	}
+	selected := &corev1.NamespaceList{ListMeta: list.ListMeta}
+	for _, ns := range list.Items {
+		if s.matches(ns.Name) {
+			selected.Items = append(selected.Items, ns)
+		}
+	}
+	return selected
+}
+
+// resourceChanges returns the changes of workloads in selected namespaces
+func (s *namespaceSelector) resourceChanges(changes []resourceChange) []resourceChange {
+	if s == nil {
+		return changes
+	}
+	var selected []resourceChange
+	for _, c := range changes {
+		if s.matches(c.workload.namespace) {
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
+
+// jobRuns returns the Job runs in selected namespaces
+func (s *namespaceSelector) jobRuns(runs []jobRun) []jobRun {
+	if s == nil {
+		return runs
+	}
+	var selected []jobRun
+	for _, r := range runs {
+		if s.matches(r.job.namespace) {
+			selected = append(selected, r)
+		}
+	}
+	return selected
+}
+
+// cronJobs returns the CronJobs in selected namespaces
+func (s *namespaceSelector) cronJobs(specs []cronJobSpec) []cronJobSpec {
+	if s == nil {
+		return specs
+	}
+	var selected []cronJobSpec
+	for _, spec := range specs {
+		if s.matches(spec.cronJob.namespace) {
+			selected = append(selected, spec)
+		}
+	}
+	return selected
+}
+
+// statefulSets returns the StatefulSets in selected namespaces
+func (s *namespaceSelector) statefulSets(footprints []statefulSetFootprint) []statefulSetFootprint {
+	if s == nil {
+		return footprints
+	}
+	var selected []statefulSetFootprint
+	for _, fp := range footprints {
+		if s.matches(fp.workload.namespace) {
+			selected = append(selected, fp)
+		}
+	}
+	return selected
+}
+
+// hpaScaling returns the HPA states of workloads in selected namespaces
+func (s *namespaceSelector) hpaScaling(states []hpaScaling) []hpaScaling {
+	if s == nil {
+		return states
+	}
+	var selected []hpaScaling
+	for _, st := range states {
+		if s.matches(st.workload.namespace) {
+			selected = append(selected, st)
+		}
+	}
+	return selected
+}
+
+// events returns the Warning events of selected namespaces and of
+// cluster-scoped objects
+func (s *namespaceSelector) events(summaries []warningEvents) []warningEvents {
+	if s == nil {
+		return summaries
+	}
+	selected := make([]warningEvents, 0, len(summaries))
+	for _, e := range summaries {
+		if e.namespace == "" || s.matches(e.namespace) {
+			selected = append(selected, e)
+		}
+	}
+	return selected
+}
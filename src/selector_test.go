@@ -0,0 +1,113 @@
+package main
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseNamespacePatterns(t *testing.T) {
+	tests := []struct {
+		patterns string
+		wantErr  bool
+	}{
+		{"team-*-prod", false},
+		{"team-*-prod, /shop-(eu|us)/", false},
+		{"", false},
+		{"team-[a-", true},
+		{"/shop-(eu/", true},
+		{"team-*,,shop", true},
+	}
+	for _, tt := range tests {
+		_, err := parseNamespacePatterns(tt.patterns)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseNamespacePatterns(%q) error = %v, wantErr %v", tt.patterns, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNamespaceSelectorMatches(t *testing.T) {
+	sel, err := parseNamespacePatterns("team-*-prod,/shop-(eu|us)/,kube-?ystem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		namespace string
+		want      bool
+	}{
+		{"team-search-prod", true},
+		{"team-search-staging", false},
+		{"shop-eu", true},
+		{"shop-eu-canary", false}, // Regular expressions match the whole name
+		{"my-shop-us", false},
+		{"kube-system", true},
+		{"default", false},
+	}
+	for _, tt := range tests {
+		if got := sel.matches(tt.namespace); got != tt.want {
+			t.Errorf("matches(%q) = %v, want %v", tt.namespace, got, tt.want)
+		}
+	}
+
+	var all *namespaceSelector
+	if !all.matches("default") {
+		t.Error("a nil selector must select all namespaces")
+	}
+}
+
+func TestNamespaceSelectorFilters(t *testing.T) {
+	sel, err := parseNamespacePatterns("team-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pod := func(namespace, name string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	pods := sel.pods([]corev1.Pod{pod("team-a", "web"), pod("default", "tool"), pod("team-b", "api")})
+	var names []string
+	for _, p := range pods {
+		names = append(names, p.Name)
+	}
+	if want := []string{"web", "api"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("pods = %v, want %v", names, want)
+	}
+
+	namespaces := &corev1.NamespaceList{Items: []corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+	}}
+	if got := sel.namespaces(namespaces); len(got.Items) != 1 || got.Items[0].Name != "team-a" || len(namespaces.Items) != 2 {
+		t.Errorf("namespaces = %+v, the input list must be kept", got.Items)
+	}
+	if sel.namespaces(nil) != nil {
+		t.Error("missing namespaces must stay nil")
+	}
+
+	changes := sel.resourceChanges([]resourceChange{
+		{workload: workloadKey{namespace: "team-a", kind: "Deployment", name: "web"}},
+		{workload: workloadKey{namespace: "default", kind: "Deployment", name: "tool"}},
+	})
+	scaling := sel.hpaScaling([]hpaScaling{
+		{workload: workloadKey{namespace: "default", kind: "Deployment", name: "tool"}},
+	})
+	if len(changes) != 1 || changes[0].workload.namespace != "team-a" || len(scaling) != 0 {
+		t.Errorf("got %d resource changes and %d HPA states", len(changes), len(scaling))
+	}
+}
+
+func TestNamespaceParamSelector(t *testing.T) {
+	sel, err := parseNamespacePatterns("team-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &reportServer{selector: sel}
+	if ns, err := s.namespaceParam(httptest.NewRequest("POST", "/regenerate?namespace=team-a", nil)); err != nil || ns != "team-a" {
+		t.Errorf("namespaceParam(team-a) = %q, %v", ns, err)
+	}
+	if _, err := s.namespaceParam(httptest.NewRequest("POST", "/regenerate?namespace=default", nil)); err == nil {
+		t.Error("expected an error for a namespace outside the patterns")
+	}
+}
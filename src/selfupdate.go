@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// version is the release of the binary, set with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// releasePublicKey verifies the signature of release checksums: a base64 DER
+// (PKIX) ed25519 public key, set with -ldflags "-X main.releasePublicKey=..."
+var releasePublicKey = ""
+
+// Release layout of self-update
+const (
+	DefaultReleaseRepo    = "ohauer/PodResourceCalculator"
+	GitHubAPI             = "https://api.github.com"
+	ChecksumsAsset        = "checksums.txt"     // sha256sum output of all release binaries
+	ChecksumsSigAsset     = "checksums.txt.sig" // Raw ed25519 signature of checksums.txt
+	DefaultUpdateTimeout  = 5 * time.Minute
+	MaxReleaseMetadataLen = 10 << 20 // Release JSON, checksums and signature
+)
+
+// githubRelease is the part of the GitHub release API self-update uses
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// assetURL returns the download URL of a release asset
+func (r *githubRelease) assetURL(name string) (string, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL, true
+		}
+	}
+	return "", false
+}
+
+// releaseAssetName is the binary of a platform, named like the Makefile builds
+func releaseAssetName(goos, goarch string) string {
+	name := fmt.Sprintf("PodResourceCalculator-%s-%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// parsePublicKey decodes a base64 DER ed25519 public key
+func parsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	der, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key encoding: %w", err)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key is %T, want ed25519", key)
+	}
+	return pub, nil
+}
+
+// checksumOf returns the SHA-256 of a file in sha256sum output
+func checksumOf(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// "<hex>  <name>" or "<hex> *<name>" for binary mode
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("no checksum for %s in %s", name, ChecksumsAsset)
+}
+
+// updater downloads and verifies releases
+type updater struct {
+	client    *http.Client
+	api       string // GitHub API base URL
+	repo      string
+	publicKey ed25519.PublicKey // nil skips the signature check
+	token     string            // Optional GitHub token against rate limits
+}
+
+// get downloads url, reading at most limit bytes
+func (u *updater) get(ctx context.Context, url string, limit int64) ([]byte, error) {
+	resp, err := u.open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", url, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%s exceeds %d bytes", url, limit)
+	}
+	return data, nil
+}
+
+// open requests url and checks the status
+func (u *updater) open(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if u.token != "" && strings.HasPrefix(url, u.api) {
+		req.Header.Set("Authorization", "Bearer "+u.token)
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status for %s: %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+// release returns the latest release, or the release of tag
+func (u *updater) release(ctx context.Context, tag string) (*githubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", u.api, u.repo)
+	if tag != "" {
+		url = fmt.Sprintf("%s/repos/%s/releases/tags/%s", u.api, u.repo, tag)
+	}
+	data, err := u.get(ctx, url, MaxReleaseMetadataLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query release: %w", err)
+	}
+	var r githubRelease
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+	return &r, nil
+}
+
+// expectedChecksum downloads the checksums of a release, verifies their
+// signature and returns the checksum of asset
+func (u *updater) expectedChecksum(ctx context.Context, r *githubRelease, asset string) (string, error) {
+	url, ok := r.assetURL(ChecksumsAsset)
+	if !ok {
+		return "", fmt.Errorf("release %s has no %s", r.TagName, ChecksumsAsset)
+	}
+	checksums, err := u.get(ctx, url, MaxReleaseMetadataLen)
+	if err != nil {
+		return "", err
+	}
+	if u.publicKey != nil {
+		sigURL, ok := r.assetURL(ChecksumsSigAsset)
+		if !ok {
+			return "", fmt.Errorf("release %s has no %s", r.TagName, ChecksumsSigAsset)
+		}
+		sig, err := u.get(ctx, sigURL, MaxReleaseMetadataLen)
+		if err != nil {
+			return "", err
+		}
+		if !ed25519.Verify(u.publicKey, checksums, sig) {
+			return "", fmt.Errorf("signature of %s does not match the release key", ChecksumsAsset)
+		}
+	}
+	return checksumOf(checksums, asset)
+}
+
+// install downloads asset of release r into target, replacing it only when
+// the download matches its checksum
+func (u *updater) install(ctx context.Context, r *githubRelease, asset, target string) error {
+	want, err := u.expectedChecksum(ctx, r, asset)
+	if err != nil {
+		return err
+	}
+	url, ok := r.assetURL(asset)
+	if !ok {
+		return fmt.Errorf("release %s has no binary for this platform (%s)", r.TagName, asset)
+	}
+	resp, err := u.open(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Next to the target so the final rename stays on one file system
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".self-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to download %s: %w", asset, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp.Name(), err)
+	}
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch for %s: got %s, want %s", asset, got, want)
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil { //nolint:gosec // Executable binary
+		return fmt.Errorf("failed to make %s executable: %w", tmp.Name(), err)
+	}
+
+	// Windows cannot replace a running executable, but can rename it
+	if runtime.GOOS == "windows" {
+		old := target + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(target, old); err != nil {
+			return fmt.Errorf("failed to move %s aside: %w", target, err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	return nil
+}
+
+// selfUpdateArgs are the flags of the self-update subcommand
+type selfUpdateArgs struct {
+	check, force, skipSignature *bool
+	version, repo, publicKey    *string
+}
+
+// selfUpdateFlags defines the flags of the self-update subcommand
+func selfUpdateFlags() (*flag.FlagSet, *selfUpdateArgs) {
+	fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+	return fs, &selfUpdateArgs{
+		check:         fs.Bool("check", false, "Only report whether a newer release exists"),
+		force:         fs.Bool("force", false, "Install even when the release is the running version"),
+		skipSignature: fs.Bool("skip-signature", false, "Only verify the checksum when no release key is known (not recommended)"),
+		version:       fs.String("version", "", "Release tag to install (default: latest)"),
+		repo:          fs.String("repo", DefaultReleaseRepo, "GitHub repository of the releases"),
+		publicKey:     fs.String("public-key", "", "Release signing key, base64 DER ed25519 (default: built in)"),
+	}
+}
+
+// runSelfUpdate implements the self-update subcommand: it replaces the running
+// binary with a verified release binary
+func runSelfUpdate(args []string) error {
+	fs, a := selfUpdateFlags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	u := &updater{client: &http.Client{Timeout: DefaultUpdateTimeout}, api: GitHubAPI, repo: *a.repo, token: os.Getenv("GITHUB_TOKEN")}
+	key := *a.publicKey
+	if key == "" {
+		key = releasePublicKey
+	}
+	if key != "" {
+		pub, err := parsePublicKey(key)
+		if err != nil {
+			return err
+		}
+		u.publicKey = pub
+	} else if !*a.skipSignature && !*a.check {
+		return fmt.Errorf("no release signing key built in; pass -public-key, or -skip-signature to rely on the checksum alone")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultUpdateTimeout)
+	defer cancel()
+	r, err := u.release(ctx, *a.version)
+	if err != nil {
+		return err
+	}
+	if r.TagName == version && !*a.force {
+		logrus.Infof("Already at %s", version)
+		return nil
+	}
+	if *a.check {
+		logrus.Infof("Release %s is available (running %s)", r.TagName, version)
+		return nil
+	}
+
+	target, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	if target, err = filepath.EvalSymlinks(target); err != nil {
+		return fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	if u.publicKey == nil {
+		logrus.Warnf("Verifying the checksum only, the release signature is not checked")
+	}
+	if err := u.install(ctx, r, releaseAssetName(runtime.GOOS, runtime.GOARCH), target); err != nil {
+		return err
+	}
+	logrus.Infof("Updated %s from %s to %s", target, version, r.TagName)
+	return nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReleaseAssetName(t *testing.T) {
+	tests := []struct {
+		goos, goarch, want string
+	}{
+		{"linux", "amd64", "PodResourceCalculator-linux-amd64"},
+		{"darwin", "arm64", "PodResourceCalculator-darwin-arm64"},
+		{"windows", "amd64", "PodResourceCalculator-windows-amd64.exe"},
+	}
+	for _, tt := range tests {
+		if got := releaseAssetName(tt.goos, tt.goarch); got != tt.want {
+			t.Errorf("releaseAssetName(%q, %q) = %q, want %q", tt.goos, tt.goarch, got, tt.want)
+		}
+	}
+}
+
+func TestChecksumOf(t *testing.T) {
+	checksums := []byte("AB12  PodResourceCalculator-linux-amd64\ncd34 *PodResourceCalculator-windows-amd64.exe\n")
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"PodResourceCalculator-linux-amd64", "ab12", false},
+		{"PodResourceCalculator-windows-amd64.exe", "cd34", false},
+		{"PodResourceCalculator-linux", "", true},
+	}
+	for _, tt := range tests {
+		got, err := checksumOf(checksums, tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("checksumOf(%q) = %q, %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := parsePublicKey(base64.StdEncoding.EncodeToString(der) + "\n")
+	if err != nil || !got.Equal(pub) {
+		t.Errorf("parsePublicKey = %v, %v", got, err)
+	}
+	for _, invalid := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("garbage"))} {
+		if _, err := parsePublicKey(invalid); err == nil {
+			t.Errorf("parsePublicKey(%q) accepted an invalid key", invalid)
+		}
+	}
+}
+
+// releaseServer serves a GitHub release of binary for linux/amd64 with signed
+// checksums; tamper modifies the served files
+func releaseServer(t *testing.T, priv ed25519.PrivateKey, binary []byte, tamper func(files map[string][]byte)) *httptest.Server {
+	t.Helper()
+	asset := releaseAssetName("linux", "amd64")
+	sum := sha256.Sum256(binary)
+	checksums := []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), asset))
+	files := map[string][]byte{
+		asset:             binary,
+		ChecksumsAsset:    checksums,
+		ChecksumsSigAsset: ed25519.Sign(priv, checksums),
+	}
+	if tamper != nil {
+		tamper(files)
+	}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/ohauer/PodResourceCalculator/releases/latest" {
+			var assets []string
+			for name := range files {
+				assets = append(assets, fmt.Sprintf(`{"name":%q,"browser_download_url":%q}`, name, srv.URL+"/download/"+name))
+			}
+			fmt.Fprintf(w, `{"tag_name":"v1.2.0","assets":[%s]}`, strings.Join(assets, ","))
+			return
+		}
+		data, ok := files[strings.TrimPrefix(r.URL.Path, "/download/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUpdaterInstall(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := []byte("new release binary")
+	asset := releaseAssetName("linux", "amd64")
+
+	tests := []struct {
+		name    string
+		key     ed25519.PublicKey
+		tamper  func(files map[string][]byte)
+		wantErr string
+	}{
+		{"signed and matching", pub, nil, ""},
+		{"checksum only", nil, nil, ""},
+		{"wrong key", otherPub, nil, "signature"},
+		{"tampered binary", pub, func(f map[string][]byte) { f[asset] = []byte("malicious") }, "checksum mismatch"},
+		{"tampered checksums", pub, func(f map[string][]byte) { f[ChecksumsAsset] = append(f[ChecksumsAsset], '\n') }, "signature"},
+		{"missing signature", pub, func(f map[string][]byte) { delete(f, ChecksumsSigAsset) }, ChecksumsSigAsset},
+		{"missing binary", pub, func(f map[string][]byte) { delete(f, asset) }, "no binary for this platform"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := releaseServer(t, priv, binary, tt.tamper)
+			target := filepath.Join(t.TempDir(), "PodResourceCalculator")
+			if err := os.WriteFile(target, []byte("old binary"), 0o755); err != nil { //nolint:gosec // Executable binary
+				t.Fatal(err)
+			}
+
+			u := &updater{client: srv.Client(), api: srv.URL, repo: DefaultReleaseRepo, publicKey: tt.key}
+			r, err := u.release(context.Background(), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.TagName != "v1.2.0" {
+				t.Errorf("tag = %q, want v1.2.0", r.TagName)
+			}
+			err = u.install(context.Background(), r, asset, target)
+
+			got, readErr := os.ReadFile(target)
+			if readErr != nil {
+				t.Fatal(readErr)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("install: %v", err)
+				}
+				if string(got) != string(binary) {
+					t.Errorf("target = %q, want the release binary", got)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("install error = %v, want %q", err, tt.wantErr)
+			}
+			if string(got) != "old binary" {
+				t.Errorf("target was replaced despite the error: %q", got)
+			}
+			if entries, _ := os.ReadDir(filepath.Dir(target)); len(entries) != 1 {
+				t.Errorf("temporary files left behind: %v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,598 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Server mode endpoints
+const (
+	PathReport     = "/report.xlsx" // Latest workbook
+	PathFreshness  = "/freshness"   // Data timestamp and pending changes as JSON
+	PathRegenerate = "/regenerate"  // POST: rebuild when pods changed, ?force=true always, ?namespace= lists one namespace again
+	PathHealth     = "/healthz"
+)
+
+// ServerShutdownTimeout bounds the graceful shutdown of server mode
+const ServerShutdownTimeout = 10 * time.Second
+
+// DefaultSnapshotTTL is how long server mode reuses a cluster snapshot
+const DefaultSnapshotTTL = 30 * time.Second
+
+// materialPodChange reports whether a pod update changes report data: whether
+// the pod counts as active, its node, or the container requests and limits.
+// Status-only updates such as readiness or restart counts are ignored.
+func materialPodChange(oldPod, newPod *corev1.Pod) bool {
+	if isActivePod(oldPod) != isActivePod(newPod) || oldPod.Spec.NodeName != newPod.Spec.NodeName {
+		return true
+	}
+	oldContainers, newContainers := podContainers(oldPod), podContainers(newPod)
+	if len(oldContainers) != len(newContainers) {
+		return true
+	}
+	for i := range oldContainers {
+		if !equality.Semantic.DeepEqual(oldContainers[i].Resources, newContainers[i].Resources) {
+			return true
+		}
+	}
+	return false
+}
+
+// freshnessStatus is the JSON body of the freshness endpoint. With a namespace
+// it covers the pods of that namespace only.
+type freshnessStatus struct {
+	Namespace       string     `json:"namespace,omitempty"`
+	Generated       *time.Time `json:"generated,omitempty"`       // Data timestamp of the served report
+	Listed          *time.Time `json:"listed,omitempty"`          // When the pods of the namespace were listed
+	ResourceVersion string     `json:"resourceVersion,omitempty"` // Of that pod list
+	LastChange      *time.Time `json:"lastChange,omitempty"`      // Latest material pod change since then
+	PendingChanges  int        `json:"pendingChanges"`
+	Stale           bool       `json:"stale"`
+	LastError       string     `json:"lastError,omitempty"`    // Error of the last failed regeneration
+	CacheExpires    *time.Time `json:"cacheExpires,omitempty"` // Until then rebuilds reuse the cached snapshot
+}
+
+// pendingCount is the number of pending changes taken before a build, in total
+// and per namespace
+type pendingCount struct {
+	total      int
+	namespaces map[string]int
+}
+
+// freshness tracks the served report against material pod changes
+type freshness struct {
+	mu         sync.Mutex
+	generated  time.Time
+	lastChange time.Time
+	pending    int
+	namespaces map[string]int // Pending changes per namespace
+	lastError  string
+}
+
+// changed records a material pod change in namespace
+func (f *freshness) changed(at time.Time, namespace string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending++
+	f.lastChange = at
+	if namespace != "" {
+		if f.namespaces == nil {
+			f.namespaces = map[string]int{}
+		}
+		f.namespaces[namespace]++
+	}
+}
+
+// stale reports whether the report is missing or pods changed since it was
+// built, in namespace only when set
+func (f *freshness) stale(namespace string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if namespace != "" {
+		return f.generated.IsZero() || f.namespaces[namespace] > 0
+	}
+	return f.generated.IsZero() || f.pending > 0
+}
+
+// pendingChanges returns the pending changes, of namespace only when set
+func (f *freshness) pendingChanges(namespace string) pendingCount {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if namespace != "" {
+		n := f.namespaces[namespace]
+		return pendingCount{total: n, namespaces: map[string]int{namespace: n}}
+	}
+	count := pendingCount{total: f.pending, namespaces: make(map[string]int, len(f.namespaces))}
+	for ns, n := range f.namespaces {
+		count.namespaces[ns] = n
+	}
+	return count
+}
+
+// settle removes the changes taken before a successful build, changes that
+// arrived during the build stay pending. f.mu must be held.
+func (f *freshness) settle(built pendingCount) {
+	f.pending -= built.total
+	for ns, n := range built.namespaces {
+		if f.namespaces[ns] <= n {
+			delete(f.namespaces, ns)
+			continue
+		}
+		f.namespaces[ns] -= n
+	}
+}
+
+// status returns a snapshot for the freshness endpoint, of namespace only when set
+func (f *freshness) status(namespace string) freshnessStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s := freshnessStatus{PendingChanges: f.pending, Stale: f.generated.IsZero() || f.pending > 0, LastError: f.lastError}
+	if namespace != "" {
+		s.Namespace = namespace
+		s.PendingChanges = f.namespaces[namespace]
+		s.Stale = f.generated.IsZero() || s.PendingChanges > 0
+	}
+	if !f.generated.IsZero() {
+		generated := f.generated
+		s.Generated = &generated
+	}
+	if !f.lastChange.IsZero() && namespace == "" {
+		lastChange := f.lastChange
+		s.LastChange = &lastChange
+	}
+	return s
+}
+
+// namespaceRefresh records when the pods of a namespace were listed again
+type namespaceRefresh struct {
+	listed          time.Time
+	resourceVersion string // Of the namespace pod list
+}
+
+// withNamespacePods returns a copy of snap with the pods of namespace replaced
+// by pods, listed at resourceVersion. Nodes, namespaces and the other pods keep
+// the data of the full collection; a namespace opted out by annotation stays
+// without pods.
+func (snap *clusterSnapshot) withNamespacePods(namespace string, pods []corev1.Pod, resourceVersion string, now time.Time) *clusterSnapshot {
+	refreshed := *snap
+	refreshed.pods = make([]corev1.Pod, 0, len(snap.pods)+len(pods))
+	for _, pod := range snap.pods {
+		if pod.Namespace != namespace {
+			refreshed.pods = append(refreshed.pods, pod)
+		}
+	}
+	if !snap.excluded[namespace] {
+		refreshed.pods = append(refreshed.pods, pods...)
+	}
+	refreshed.pending = nil
+	for _, pod := range snap.pending {
+		if pod.Namespace != namespace {
+			refreshed.pending = append(refreshed.pending, pod)
+		}
+	}
+	separatePending(&refreshed, snap.pendingMode)
+	refreshed.refreshed = make(map[string]namespaceRefresh, len(snap.refreshed)+1)
+	for ns, r := range snap.refreshed {
+		refreshed.refreshed[ns] = r
+	}
+	refreshed.refreshed[namespace] = namespaceRefresh{listed: now, resourceVersion: resourceVersion}
+	return &refreshed
+}
+
+// namespaceVersion returns when and at which resourceVersion the pods of
+// namespace were last listed, by the full collection or a partial refresh
+func (snap *clusterSnapshot) namespaceVersion(namespace string) namespaceRefresh {
+	if r, ok := snap.refreshed[namespace]; ok {
+		return r
+	}
+	return namespaceRefresh{listed: snap.collected, resourceVersion: snap.resourceVersion}
+}
+
+// snapshotCache reuses the last collected snapshot while it is younger than
+// ttl, so repeated rebuilds and all views of the same moment share one cluster
+// scan. A zero ttl collects on every request.
+type snapshotCache struct {
+	mu            sync.Mutex
+	ttl           time.Duration
+	collect       func(now time.Time) (*clusterSnapshot, error)
+	listNamespace func(namespace string) (*corev1.PodList, error)
+	snap          *clusterSnapshot
+}
+
+// get returns the cached snapshot or collects a new one at now and reports
+// whether it was collected
+func (c *snapshotCache) get(now time.Time) (*clusterSnapshot, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snap != nil && now.Sub(c.snap.collected) < c.ttl {
+		return c.snap, false, nil
+	}
+	snap, err := c.collect(now)
+	if err != nil {
+		return nil, false, err
+	}
+	c.snap = snap
+	return snap, true, nil
+}
+
+// refreshNamespace lists the pods of namespace again and replaces them in the
+// cached snapshot, which keeps its collection time and TTL. Without a cached
+// snapshot it collects a full one and reports that.
+func (c *snapshotCache) refreshNamespace(namespace string, now time.Time) (*clusterSnapshot, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snap == nil {
+		snap, err := c.collect(now)
+		if err != nil {
+			return nil, false, err
+		}
+		c.snap = snap
+		return snap, true, nil
+	}
+	pods, err := c.listNamespace(namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	c.snap = c.snap.withNamespacePods(namespace, pods.Items, pods.ResourceVersion, now)
+	return c.snap, false, nil
+}
+
+// namespaceVersion returns the pod list version of namespace in the cached
+// snapshot, false when nothing is cached
+func (c *snapshotCache) namespaceVersion(namespace string) (namespaceRefresh, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snap == nil {
+		return namespaceRefresh{}, false
+	}
+	return c.snap.namespaceVersion(namespace), true
+}
+
+// expires returns when the cached snapshot will be collected again, zero when
+// nothing is cached or caching is off
+func (c *snapshotCache) expires() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snap == nil || c.ttl <= 0 {
+		return time.Time{}
+	}
+	return c.snap.collected.Add(c.ttl)
+}
+
+// reportServer serves the latest workbook and REST API views of its snapshot
+// and regenerates both on request when the watched pods changed materially
+type reportServer struct {
+	filename   string
+	scope      string // Namespace of the report, empty for all namespaces
+	now        func() time.Time
+	snapshots  *snapshotCache
+	render     func(snap *clusterSnapshot) error
+	validation validationRules // Rules of the recommendations endpoint
+	alerts     *alerter        // Notified of failed and slow regenerations, nil without alerts config
+	metadata   reportMetadata
+	freshness  freshness
+	buildMu    sync.Mutex // Serializes regenerations
+
+	selectorMu sync.RWMutex
+	selector   *namespaceSelector // Namespace patterns of the report, nil for all namespaces; replaced by config reloads
+
+	viewsMu sync.RWMutex
+	views   *apiViews // REST API items of the served snapshot, nil before the first build
+}
+
+// namespaceSelector returns the namespace patterns of the report
+func (s *reportServer) namespaceSelector() *namespaceSelector {
+	s.selectorMu.RLock()
+	defer s.selectorMu.RUnlock()
+	return s.selector
+}
+
+// apiViews returns the REST API items of the served snapshot
+func (s *reportServer) apiViews() *apiViews {
+	s.viewsMu.RLock()
+	defer s.viewsMu.RUnlock()
+	return s.views
+}
+
+// status returns the freshness of the served report and the snapshot cache,
+// of namespace only when set
+func (s *reportServer) status(namespace string) freshnessStatus {
+	status := s.freshness.status(namespace)
+	if expires := s.snapshots.expires(); !expires.IsZero() && s.now().Before(expires) {
+		status.CacheExpires = &expires
+	}
+	if namespace != "" {
+		if version, ok := s.snapshots.namespaceVersion(namespace); ok {
+			status.Listed = &version.listed
+			status.ResourceVersion = version.resourceVersion
+		}
+	}
+	return status
+}
+
+// regenerate rebuilds the report when it is stale or force is set and reports
+// whether a build ran. Within the cache TTL a stale report is not rebuilt and a
+// forced rebuild renders the cached snapshot again. Changes arriving during the
+// build keep the report stale.
+func (s *reportServer) regenerate(force bool) (bool, error) {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	if !force && !s.freshness.stale("") {
+		return false, nil
+	}
+
+	started := time.Now()
+	taken := s.freshness.pendingChanges("")
+	snap, collected, err := s.snapshots.get(s.now())
+	if err == nil && !collected && !force {
+		// The served report already shows the cached snapshot
+		return false, nil
+	}
+	if !collected {
+		taken = pendingCount{}
+	}
+	err = s.publish(snap, err, collected, taken)
+	s.alerts.observe(err, time.Since(started))
+	return true, err
+}
+
+// regenerateNamespace lists the pods of namespace again and rebuilds the report
+// from the cached snapshot when they changed or force is set. It ignores the
+// cache TTL and reports whether a build ran.
+func (s *reportServer) regenerateNamespace(namespace string, force bool) (bool, error) {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	if !force && !s.freshness.stale(namespace) {
+		return false, nil
+	}
+
+	started := time.Now()
+	taken := s.freshness.pendingChanges(namespace)
+	all := s.freshness.pendingChanges("")
+	snap, collected, err := s.snapshots.refreshNamespace(namespace, s.now())
+	if collected {
+		taken = all
+	}
+	err = s.publish(snap, err, collected, taken)
+	s.alerts.observe(err, time.Since(started))
+	return true, err
+}
+
+// publish renders snap and serves its API views. On success the taken changes
+// are settled and a newly collected snapshot becomes the report time.
+func (s *reportServer) publish(snap *clusterSnapshot, err error, collected bool, taken pendingCount) error {
+	if err == nil {
+		err = s.render(snap)
+		if isFindingsError(err) {
+			logrus.Warnf("Validation failed: %v", err)
+			err = nil
+		}
+	}
+	if err == nil {
+		views := buildAPIViews(snap, s.validation, s.metadata)
+		s.viewsMu.Lock()
+		s.views = views
+		s.viewsMu.Unlock()
+	}
+
+	s.freshness.mu.Lock()
+	defer s.freshness.mu.Unlock()
+	if err != nil {
+		s.freshness.lastError = err.Error()
+		return err
+	}
+	if collected {
+		s.freshness.generated = snap.collected
+	}
+	s.freshness.settle(taken)
+	s.freshness.lastError = ""
+	return nil
+}
+
+// namespaceParam returns the namespace query parameter, which must be valid
+// and inside the report scope
+func (s *reportServer) namespaceParam(r *http.Request) (string, error) {
+	namespace := r.URL.Query().Get("namespace")
+	if namespace == "" {
+		return "", nil
+	}
+	if err := validateNamespace(namespace); err != nil {
+		return "", err
+	}
+	if s.scope != "" && namespace != s.scope {
+		return "", fmt.Errorf("namespace '%s' is outside the report scope '%s'", namespace, s.scope)
+	}
+	if selector := s.namespaceSelector(); !selector.matches(namespace) {
+		return "", fmt.Errorf("namespace '%s' does not match the report patterns '%s'", namespace, selector.patterns)
+	}
+	return namespace, nil
+}
+
+// handler returns the HTTP routes of server mode
+func (s *reportServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathHealth, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc(PathFreshness, func(w http.ResponseWriter, r *http.Request) {
+		namespace, err := s.namespaceParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.status(namespace))
+	})
+	mux.HandleFunc(PathRegenerate, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		namespace, err := s.namespaceParam(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		force := r.URL.Query().Get("force") == "true"
+		if namespace != "" {
+			_, err = s.regenerateNamespace(namespace, force)
+		} else {
+			_, err = s.regenerate(force)
+		}
+		if err != nil {
+			logrus.Errorf("Failed to regenerate report: %v", err)
+			writeJSON(w, http.StatusInternalServerError, s.status(namespace))
+			return
+		}
+		writeJSON(w, http.StatusOK, s.status(namespace))
+	})
+	s.handleAPI(mux)
+	mux.HandleFunc(PathReport, func(w http.ResponseWriter, r *http.Request) {
+		if _, err := os.Stat(s.filename); err != nil {
+			http.Error(w, "report not generated yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(s.filename)))
+		http.ServeFile(w, r, s.filename)
+	})
+	return mux
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logrus.Warnf("Failed to write response: %v", err)
+	}
+}
+
+// watchPods marks the report stale on material pod changes until ctx ends
+func (s *reportServer) watchPods(ctx context.Context, job reportJob) error {
+	factory := informers.NewSharedInformerFactoryWithOptions(job.clientSet, 0, informers.WithNamespace(job.namespace))
+	informer := factory.Core().V1().Pods().Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerDetailedFuncs{
+		AddFunc: func(obj interface{}, isInInitialList bool) {
+			if !isInInitialList && s.namespaceSelector().matches(podNamespace(obj)) {
+				s.freshness.changed(s.now(), podNamespace(obj))
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldPod, ok1 := oldObj.(*corev1.Pod)
+			newPod, ok2 := newObj.(*corev1.Pod)
+			if ok1 && ok2 && s.namespaceSelector().matches(newPod.Namespace) && materialPodChange(oldPod, newPod) {
+				s.freshness.changed(s.now(), newPod.Namespace)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if s.namespaceSelector().matches(podNamespace(obj)) {
+				s.freshness.changed(s.now(), podNamespace(obj))
+			}
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch pods: %w", err)
+	}
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return fmt.Errorf("failed to sync pod watch")
+	}
+	logrus.Infof("Watching pods in %s", getNamespaceDisplay(job.namespace))
+	return nil
+}
+
+// podNamespace returns the namespace of a watched pod, also of a deleted pod
+// whose final state is unknown
+func podNamespace(obj interface{}) string {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if pod, ok := obj.(*corev1.Pod); ok {
+		return pod.Namespace
+	}
+	return ""
+}
+
+// useJob switches regenerations to the settings of job and drops the cached
+// snapshot, whose collection depends on them
+func (s *reportServer) useJob(job reportJob) {
+	s.buildMu.Lock()
+	defer s.buildMu.Unlock()
+	s.render = func(snap *clusterSnapshot) error { return job.render(context.Background(), snap) }
+	s.validation = job.opts.validation
+	s.alerts = job.alerts
+	s.metadata = job.opts.metadata
+
+	s.selectorMu.Lock()
+	s.selector = job.selector
+	s.selectorMu.Unlock()
+
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+	s.snapshots.collect = func(now time.Time) (*clusterSnapshot, error) { return job.collect(context.Background(), now) }
+	s.snapshots.listNamespace = job.listNamespacePods
+	s.snapshots.snap = nil
+}
+
+// serveReports runs server mode: generate the report, watch pods and serve the
+// workbook until SIGINT or SIGTERM. Snapshots are reused for ttl. A non-nil
+// config watcher applies config file changes without a restart.
+func serveReports(addr, grpcAddr string, job reportJob, location *time.Location, ttl time.Duration, config *configWatcher) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	s := &reportServer{
+		filename:  job.filename,
+		scope:     job.namespace,
+		now:       func() time.Time { return time.Now().In(location) },
+		snapshots: &snapshotCache{ttl: ttl},
+	}
+	s.useJob(job)
+	if err := s.watchPods(ctx, job); err != nil {
+		return err
+	}
+	if config != nil {
+		go config.run(ctx, s, job)
+		logrus.Infof("Watching config %s for changes", config.path)
+	}
+	if _, err := s.regenerate(true); err != nil {
+		return err
+	}
+
+	if grpcAddr != "" {
+		if err := serveGRPC(ctx, grpcAddr, s); err != nil {
+			return err
+		}
+	}
+
+	server := &http.Server{Addr: addr, Handler: s.handler(), ReadHeaderTimeout: DefaultAPITimeout}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ServerShutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			logrus.Warnf("Failed to shut down server: %v", err)
+		}
+	}()
+
+	logrus.Infof("Serving report on %s", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaterialPodChange(t *testing.T) {
+	base := func() *corev1.Pod {
+		return &corev1.Pod{
+			Spec: corev1.PodSpec{
+				NodeName: "n1",
+				Containers: []corev1.Container{{Name: "app", Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")},
+				}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	tests := []struct {
+		name   string
+		change func(p *corev1.Pod)
+		want   bool
+	}{
+		{"no change", func(p *corev1.Pod) {}, false},
+		{"restart count", func(p *corev1.Pod) {
+			p.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "app", RestartCount: 3}}
+		}, false},
+		{"same quantity other notation", func(p *corev1.Pod) {
+			p.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("0.1")
+		}, false},
+		{"resized", func(p *corev1.Pod) {
+			p.Spec.Containers[0].Resources.Requests[corev1.ResourceCPU] = resource.MustParse("200m")
+		}, true},
+		{"rescheduled", func(p *corev1.Pod) { p.Spec.NodeName = "n2" }, true},
+		{"succeeded", func(p *corev1.Pod) { p.Status.Phase = corev1.PodSucceeded }, true},
+		{"container added", func(p *corev1.Pod) {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: "sidecar"})
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldPod, newPod := base(), base()
+			tt.change(newPod)
+			if got := materialPodChange(oldPod, newPod); got != tt.want {
+				t.Errorf("materialPodChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportServerRegenerate(t *testing.T) {
+	clock := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	builds := 0
+	var buildErr error
+	s := &reportServer{
+		filename: t.TempDir() + "/report.xlsx",
+		now:      func() time.Time { return clock },
+		snapshots: &snapshotCache{collect: func(now time.Time) (*clusterSnapshot, error) {
+			builds++
+			return &clusterSnapshot{collected: now}, buildErr
+		}},
+		render: func(*clusterSnapshot) error { return nil },
+	}
+
+	post := func(query string) freshnessStatus {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, PathRegenerate+query, nil))
+		var status freshnessStatus
+		if err := json.NewDecoder(rec.Body).Decode(&status); err != nil {
+			t.Fatal(err)
+		}
+		return status
+	}
+
+	if status := post(""); builds != 1 || status.Stale || status.Generated == nil || !status.Generated.Equal(clock) {
+		t.Errorf("first regenerate: builds = %d, status = %+v", builds, status)
+	}
+	if post(""); builds != 1 {
+		t.Errorf("regenerate without changes built again, builds = %d", builds)
+	}
+	if post("?force=true"); builds != 2 {
+		t.Errorf("forced regenerate did not build, builds = %d", builds)
+	}
+
+	s.freshness.changed(clock.Add(time.Minute), "shop")
+	s.freshness.changed(clock.Add(2*time.Minute), "search")
+	if status := s.freshness.status(""); !status.Stale || status.PendingChanges != 2 || !status.LastChange.Equal(clock.Add(2*time.Minute)) {
+		t.Errorf("status after changes = %+v", status)
+	}
+
+	buildErr = fmt.Errorf("failed to list pods")
+	if status := post(""); builds != 3 || !status.Stale || status.LastError == "" {
+		t.Errorf("failed regenerate: builds = %d, status = %+v", builds, status)
+	}
+	buildErr = nil
+	clock = clock.Add(time.Hour)
+	if status := post(""); builds != 4 || status.Stale || status.PendingChanges != 0 || status.LastError != "" || !status.Generated.Equal(clock) {
+		t.Errorf("regenerate after changes: builds = %d, status = %+v", builds, status)
+	}
+
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathRegenerate, nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET %s = %d, want 405", PathRegenerate, rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, PathReport, nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET %s without a workbook = %d, want 503", PathReport, rec.Code)
+	}
+}
+
+func TestReportServerSnapshotCache(t *testing.T) {
+	clock := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	scans, renders := 0, 0
+	s := &reportServer{
+		now: func() time.Time { return clock },
+		snapshots: &snapshotCache{ttl: time.Minute, collect: func(now time.Time) (*clusterSnapshot, error) {
+			scans++
+			return &clusterSnapshot{collected: now}, nil
+		}},
+		render: func(*clusterSnapshot) error { renders++; return nil },
+	}
+
+	if built, err := s.regenerate(false); !built || err != nil || scans != 1 {
+		t.Fatalf("first regenerate = %v, %v, scans = %d", built, err, scans)
+	}
+	if expires := s.status("").CacheExpires; expires == nil || !expires.Equal(clock.Add(time.Minute)) {
+		t.Errorf("cacheExpires = %v, want one minute after the scan", expires)
+	}
+
+	// Within the TTL a stale report keeps the cached snapshot, a forced rebuild renders it again
+	clock = clock.Add(30 * time.Second)
+	s.freshness.changed(clock, "shop")
+	if built, _ := s.regenerate(false); built || scans != 1 || !s.status("").Stale {
+		t.Errorf("regenerate within TTL: built = %v, scans = %d, status = %+v", built, scans, s.status(""))
+	}
+	if built, _ := s.regenerate(true); !built || scans != 1 || renders != 2 || !s.status("").Stale {
+		t.Errorf("forced regenerate within TTL: built = %v, scans = %d, renders = %d", built, scans, renders)
+	}
+
+	clock = clock.Add(time.Minute)
+	if built, _ := s.regenerate(false); !built || scans != 2 || s.status("").Stale || s.status("").CacheExpires == nil {
+		t.Errorf("regenerate after TTL: built = %v, scans = %d, status = %+v", built, scans, s.status(""))
+	}
+	clock = clock.Add(2 * time.Minute)
+	if s.status("").CacheExpires != nil {
+		t.Error("cacheExpires set for an expired snapshot")
+	}
+}
+
+func TestWithNamespacePods(t *testing.T) {
+	collected := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	pod := func(namespace, name string) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	snap := &clusterSnapshot{collected: collected, resourceVersion: "100", pods: []corev1.Pod{pod("shop", "web-1"), pod("search", "idx-1"), pod("shop", "web-2")}}
+
+	refreshed := snap.withNamespacePods("shop", []corev1.Pod{pod("shop", "web-3")}, "140", collected.Add(time.Minute))
+	var names []string
+	for _, p := range refreshed.pods {
+		names = append(names, p.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"idx-1", "web-3"}) {
+		t.Errorf("pods = %v, want idx-1 and web-3", names)
+	}
+	if len(snap.pods) != 3 || snap.refreshed != nil {
+		t.Error("withNamespacePods modified the cached snapshot")
+	}
+	if v := refreshed.namespaceVersion("shop"); v.resourceVersion != "140" || !v.listed.Equal(collected.Add(time.Minute)) {
+		t.Errorf("namespaceVersion(shop) = %+v", v)
+	}
+	if v := refreshed.namespaceVersion("search"); v.resourceVersion != "100" || !v.listed.Equal(collected) {
+		t.Errorf("namespaceVersion(search) = %+v, want the full collection", v)
+	}
+	if !refreshed.collected.Equal(collected) {
+		t.Errorf("collected = %v, want the full collection time", refreshed.collected)
+	}
+}
+
+func TestReportServerRegenerateNamespace(t *testing.T) {
+	clock := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	scans, lists := 0, 0
+	s := &reportServer{
+		now: func() time.Time { return clock },
+		snapshots: &snapshotCache{
+			ttl: time.Hour,
+			collect: func(now time.Time) (*clusterSnapshot, error) {
+				scans++
+				return &clusterSnapshot{collected: now, resourceVersion: "100"}, nil
+			},
+			listNamespace: func(namespace string) (*corev1.PodList, error) {
+				lists++
+				return &corev1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "150"}}, nil
+			},
+		},
+		render: func(*clusterSnapshot) error { return nil },
+	}
+
+	// Without a snapshot a namespace refresh collects the full cluster
+	if built, err := s.regenerateNamespace("shop", false); !built || err != nil || scans != 1 || lists != 0 {
+		t.Fatalf("first regenerateNamespace = %v, %v, scans = %d, lists = %d", built, err, scans, lists)
+	}
+
+	clock = clock.Add(time.Minute)
+	s.freshness.changed(clock, "shop")
+	s.freshness.changed(clock, "search")
+	if built, _ := s.regenerateNamespace("search", false); !built || lists != 1 || scans != 1 {
+		t.Errorf("regenerateNamespace(search): built = %v, lists = %d, scans = %d", built, lists, scans)
+	}
+	if built, _ := s.regenerateNamespace("search", false); built || lists != 1 {
+		t.Errorf("regenerateNamespace without changes listed again, lists = %d", lists)
+	}
+
+	search := s.status("search")
+	if search.Stale || search.ResourceVersion != "150" || search.Listed == nil || !search.Listed.Equal(clock) {
+		t.Errorf("status(search) = %+v", search)
+	}
+	if shop := s.status("shop"); !shop.Stale || shop.PendingChanges != 1 || shop.ResourceVersion != "100" {
+		t.Errorf("status(shop) = %+v", shop)
+	}
+	if all := s.status(""); !all.Stale || all.PendingChanges != 1 || !all.Generated.Equal(clock.Add(-time.Minute)) {
+		t.Errorf("status() = %+v, want the shop change pending and the full collection time", all)
+	}
+
+	s.scope = "shop"
+	for _, query := range []string{"?namespace=search", "?namespace=Not_Valid"} {
+		rec := httptest.NewRecorder()
+		s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, PathRegenerate+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("POST %s%s = %d, want 400", PathRegenerate, query, rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	s.handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, PathRegenerate+"?namespace=shop", nil))
+	if rec.Code != http.StatusOK || lists != 2 || s.status("").Stale {
+		t.Errorf("POST %s?namespace=shop = %d, lists = %d, status = %+v", PathRegenerate, rec.Code, lists, s.status(""))
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sheet keys accepted by -sheets and the config file
+const (
+	SheetOverview     = "overview"
+	SheetResources    = "resources"
+	SheetNamespaces   = "namespaces"
+	SheetNodes        = "nodes"
+	SheetReserved     = "reserved"
+	SheetHeatmap      = "heatmap"
+	SheetArch         = "arch"
+	SheetTopology     = "topology"
+	SheetChart        = "chart"
+	SheetRequestLimit = "request-limit"
+	SheetChanges      = "changes"
+	SheetBaseline     = "baseline"
+	SheetScaling      = "scaling"
+	SheetDelivery     = "delivery"
+	SheetSidecars     = "sidecars"
+	SheetCost         = "cost"
+	SheetComputeUnits = "compute-units"
+	SheetStatefulSets = "statefulsets"
+	SheetExtended     = "extended"
+	SheetPlatform     = "platform"
+	SheetHeadroom     = "headroom"
+	SheetVendors      = "vendors"
+	SheetContainers   = "containers"
+	SheetDistribution = "distribution"
+	SheetTShirt       = "tshirt"
+	SheetInsights     = "insights"
+	SheetCleanup      = "cleanup"
+	SheetPending      = "pending"
+	SheetJobAudit     = "job-audit"
+	SheetForecast     = "forecast"
+	SheetReliability  = "reliability"
+	SheetStartup      = "startup"
+	SheetJVM          = "jvm"
+	SheetEvents       = "events"
+	SheetWarnings     = "warnings"
+	SheetPodSecurity  = "pod-security"
+	SheetSecurity     = "security"
+	SheetSchema       = "schema"
+)
+
+// allSheets lists every sheet key in workbook order
+var allSheets = []string{
+	SheetOverview, SheetResources, SheetNamespaces, SheetNodes, SheetReserved, SheetHeatmap, SheetArch, SheetTopology,
+	SheetChart, SheetRequestLimit, SheetChanges, SheetBaseline, SheetScaling, SheetDelivery, SheetSidecars,
+	SheetCost, SheetComputeUnits, SheetStatefulSets, SheetExtended, SheetPlatform, SheetHeadroom, SheetVendors, SheetContainers, SheetDistribution, SheetTShirt,
+	SheetInsights, SheetCleanup, SheetPending, SheetJobAudit, SheetForecast, SheetReliability, SheetStartup, SheetJVM, SheetEvents, SheetWarnings, SheetPodSecurity, SheetSecurity, SheetSchema,
+}
+
+// sheetSelection is the set of enabled sheets; nil enables all sheets
+type sheetSelection map[string]bool
+
+// parseSheetSelection parses a comma-separated list of sheet keys
+func parseSheetSelection(keys []string) (sheetSelection, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	known := make(map[string]bool, len(allSheets))
+	for _, key := range allSheets {
+		known[key] = true
+	}
+
+	selection := make(sheetSelection)
+	for _, key := range keys {
+		key = strings.TrimSpace(strings.ToLower(key))
+		if key == "" {
+			continue
+		}
+		if !known[key] {
+			return nil, fmt.Errorf("unknown sheet '%s' (valid: %s)", key, strings.Join(allSheets, ", "))
+		}
+		selection[key] = true
+	}
+
+	if len(selection) == 0 {
+		return nil, fmt.Errorf("no sheets selected")
+	}
+	// The chart sheet plots data from the Namespaces sheet
+	if selection[SheetChart] && !selection[SheetNamespaces] {
+		return nil, fmt.Errorf("sheet '%s' requires sheet '%s'", SheetChart, SheetNamespaces)
+	}
+
+	return selection, nil
+}
+
+// enabled reports whether a sheet should be generated
+func (s sheetSelection) enabled(key string) bool {
+	return s == nil || s[key]
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseSheetSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		want    []string
+		wantErr bool
+	}{
+		{"nil enables all", nil, allSheets, false},
+		{"subset", []string{"resources", " Nodes ", "insights"}, []string{SheetResources, SheetNodes, SheetInsights}, false},
+		{"chart with namespaces", []string{"chart", "namespaces"}, []string{SheetChart, SheetNamespaces}, false},
+		{"chart without namespaces", []string{"chart"}, nil, true},
+		{"unknown sheet", []string{"resources", "pods"}, nil, true},
+		{"only blanks", []string{"", " "}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSheetSelection(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSheetSelection() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			enabled := 0
+			for _, key := range allSheets {
+				if got.enabled(key) {
+					enabled++
+				}
+			}
+			if enabled != len(tt.want) {
+				t.Errorf("parseSheetSelection() enabled %d sheets, want %d", enabled, len(tt.want))
+			}
+			for _, key := range tt.want {
+				if !got.enabled(key) {
+					t.Errorf("sheet %s should be enabled", key)
+				}
+			}
+		})
+	}
+}
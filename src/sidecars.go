@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// sidecarMeshes maps injected proxy container names to their service mesh
+var sidecarMeshes = map[string]string{
+	"istio-proxy":      "Istio",
+	"linkerd-proxy":    "Linkerd",
+	"envoy":            "Envoy",
+	"envoy-sidecar":    "Consul",
+	"consul-dataplane": "Consul",
+	"kuma-sidecar":     "Kuma",
+}
+
+// sidecarOverhead sums the requests of mesh sidecars in a namespace next to the
+// requests of all containers in that namespace
+type sidecarOverhead struct {
+	namespace, mesh string
+	sidecars        int
+	reqCPU, reqMem  int64 // Sidecar requests in millicores and bytes
+	limCPU, limMem  int64 // Sidecar limits in millicores and bytes
+	nsCPU, nsMem    int64 // Requests of all containers in the namespace
+}
+
+// podContainers returns the regular containers and native sidecars (init
+// containers with restartPolicy Always) that run for the pod's lifetime
+func podContainers(pod *corev1.Pod) []corev1.Container {
+	containers := pod.Spec.Containers
+	for _, c := range pod.Spec.InitContainers {
+		if c.RestartPolicy != nil && *c.RestartPolicy == corev1.ContainerRestartPolicyAlways {
+			containers = append(containers[:len(containers):len(containers)], c)
+		}
+	}
+	return containers
+}
+
+// sidecarOverheads returns mesh sidecar requests per namespace and mesh, largest
+// CPU overhead first
+func sidecarOverheads(pods []corev1.Pod) []sidecarOverhead {
+	type key struct{ namespace, mesh string }
+	overheads := make(map[key]*sidecarOverhead)
+	nsCPU := make(map[string]int64)
+	nsMem := make(map[string]int64)
+
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		for _, c := range podContainers(pod) {
+			cpu := quantityMilli(c.Resources.Requests.Cpu())
+			mem := quantityBytes(c.Resources.Requests.Memory())
+			nsCPU[pod.Namespace] += cpu
+			nsMem[pod.Namespace] += mem
+
+			mesh, ok := sidecarMeshes[c.Name]
+			if !ok {
+				continue
+			}
+			k := key{pod.Namespace, mesh}
+			o := overheads[k]
+			if o == nil {
+				o = &sidecarOverhead{namespace: pod.Namespace, mesh: mesh}
+				overheads[k] = o
+			}
+			o.sidecars++
+			o.reqCPU += cpu
+			o.reqMem += mem
+			o.limCPU += quantityMilli(c.Resources.Limits.Cpu())
+			o.limMem += quantityBytes(c.Resources.Limits.Memory())
+		}
+	}
+
+	result := make([]sidecarOverhead, 0, len(overheads))
+	for _, o := range overheads {
+		o.nsCPU, o.nsMem = nsCPU[o.namespace], nsMem[o.namespace]
+		result = append(result, *o)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].reqCPU != result[j].reqCPU {
+			return result[i].reqCPU > result[j].reqCPU
+		}
+		if result[i].namespace != result[j].namespace {
+			return result[i].namespace < result[j].namespace
+		}
+		return result[i].mesh < result[j].mesh
+	})
+	return result
+}
+
+// createSidecarSheet lists mesh sidecar requests per namespace and their share of
+// the namespace's requests, with a cluster total
+func createSidecarSheet(f *excelize.File, overheads []sidecarOverhead, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create sidecar overhead sheet: %w", err)
+	}
+
+	headers := []string{
+		"Namespace", "Mesh", "Sidecars",
+		"Request CPU (cores)", "Request Memory (Gi)", "Limit CPU (cores)", "Limit Memory (Gi)",
+		"CPU % of Namespace", "Memory % of Namespace",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, o := range overheads {
+		data := []interface{}{
+			o.namespace,
+			o.mesh,
+			o.sidecars,
+			milliToCores(o.reqCPU),
+			bytesToGi(o.reqMem),
+			milliToCores(o.limCPU),
+			bytesToGi(o.limMem),
+			ratio(o.reqCPU, o.nsCPU),
+			ratio(o.reqMem, o.nsMem),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("namespace '%s' %s sidecars", o.namespace, o.mesh)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	if row > 2 {
+		last := row - 1
+		f.SetCellStyle(sheetName, "D2", fmt.Sprintf("G%d", last), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "H2", fmt.Sprintf("I%d", last), export.PercentStyle(f, "0.0%"))
+
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Total")
+		for _, col := range []string{"C", "D", "E", "F", "G"} {
+			f.SetCellFormula(sheetName, fmt.Sprintf("%s%d", col, row), fmt.Sprintf("SUM(%s2:%s%d)", col, col, last))
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("C%d", row), export.BoldStyle(f))
+		f.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("G%d", row), export.DecimalStyle(f, true))
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 30)
+	f.SetColWidth(sheetName, "B", "C", 12)
+	f.SetColWidth(sheetName, "D", "I", 20)
+
+	return nil
+}
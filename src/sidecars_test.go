@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testSidecarContainer(name, cpu, mem string) corev1.Container {
+	return corev1.Container{
+		Name: name,
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(mem),
+		}},
+	}
+}
+
+func TestPodContainers(t *testing.T) {
+	always := corev1.ContainerRestartPolicyAlways
+	native := testSidecarContainer("istio-proxy", "100m", "128Mi")
+	native.RestartPolicy = &always
+	pod := corev1.Pod{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{testSidecarContainer("migrate", "1", "1Gi"), native},
+		Containers:     []corev1.Container{testSidecarContainer("app", "1", "1Gi")},
+	}}
+
+	got := podContainers(&pod)
+	if len(got) != 2 || got[0].Name != "app" || got[1].Name != "istio-proxy" {
+		t.Errorf("podContainers() = %v, want app and the native sidecar", got)
+	}
+	if len(pod.Spec.Containers) != 1 {
+		t.Error("podContainers() modified the pod spec")
+	}
+}
+
+func TestSidecarOverheads(t *testing.T) {
+	pod := func(namespace string, containers ...corev1.Container) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+			Spec:       corev1.PodSpec{Containers: containers},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("shop", testSidecarContainer("app", "900m", "1Gi"), testSidecarContainer("istio-proxy", "100m", "128Mi")),
+		pod("shop", testSidecarContainer("app", "900m", "1Gi"), testSidecarContainer("istio-proxy", "100m", "128Mi")),
+		pod("search", testSidecarContainer("app", "500m", "512Mi"), testSidecarContainer("linkerd-proxy", "500m", "64Mi")),
+		pod("batch", testSidecarContainer("app", "1", "1Gi")),
+	}
+
+	got := sidecarOverheads(pods)
+	if len(got) != 2 {
+		t.Fatalf("sidecarOverheads() returned %d rows, want 2: %+v", len(got), got)
+	}
+
+	search := got[0]
+	if search.namespace != "search" || search.mesh != "Linkerd" || search.reqCPU != 500 || search.nsCPU != 1000 {
+		t.Errorf("got[0] = %+v, want search Linkerd 500m of 1000m", search)
+	}
+
+	shop := got[1]
+	if shop.namespace != "shop" || shop.mesh != "Istio" || shop.sidecars != 2 {
+		t.Errorf("got[1] = %+v, want shop Istio with 2 sidecars", shop)
+	}
+	if shop.reqCPU != 200 || shop.reqMem != 256*BytesPerMi || shop.nsCPU != 2000 {
+		t.Errorf("shop overhead = %dm, %d bytes of %dm", shop.reqCPU, shop.reqMem, shop.nsCPU)
+	}
+}
@@ -0,0 +1,430 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Scenario actions of the simulate subcommand
+const (
+	ActionScale  = "scale"  // Multiply replicas by factor
+	ActionResize = "resize" // Multiply container requests by cpuFactor/memoryFactor
+	ActionRemove = "remove" // Remove matching pods
+	ActionAdd    = "add"    // Add replicas of a new workload
+	ActionApply  = "apply"  // Set container requests to the recommendations from the usage quantiles
+)
+
+// UnscheduledPool holds pending pods without a node; it has no capacity
+const UnscheduledPool = "unscheduled"
+
+// scenario is a what-if file for the simulate subcommand (YAML or JSON)
+type scenario struct {
+	MaxUtilization float64          `json:"maxUtilization,omitempty"` // Target node utilization for node counts, default 1
+	Changes        []scenarioChange `json:"changes"`
+}
+
+// scenarioChange is one hypothetical change, applied in file order
+type scenarioChange struct {
+	Action       string  `json:"action"`
+	Namespace    string  `json:"namespace"`
+	Workload     string  `json:"workload,omitempty"` // "<Kind>/<name>" or name, all workloads when empty
+	Factor       float64 `json:"factor,omitempty"`
+	CPUFactor    float64 `json:"cpuFactor,omitempty"`
+	MemoryFactor float64 `json:"memoryFactor,omitempty"`
+	Pool         string  `json:"pool,omitempty"` // Node pool of added replicas, default pool when empty
+	Replicas     int     `json:"replicas,omitempty"`
+	CPU          string  `json:"cpu,omitempty"`    // Requests per added replica
+	Memory       string  `json:"memory,omitempty"` // Requests per added replica
+}
+
+// simPod is a pod's requests in the simulated cluster; scaling changes the weight
+type simPod struct {
+	workload       workloadKey
+	pool           string
+	reqCPU, reqMem float64 // millicores and bytes
+	recCPU, recMem float64 // Recommended requests, the requests of containers without usage history
+}
+
+// poolProjection compares current and projected requests of a node pool
+type poolProjection struct {
+	pool               string
+	nodes              int
+	allocCPU, allocMem float64
+	baseCPU, baseMem   float64
+	projCPU, projMem   float64
+	nodesNeeded        int // -1 when the pool has no capacity to size from
+}
+
+// loadScenario reads and validates a scenario file
+func loadScenario(path string) (*scenario, error) {
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid scenario path: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario %s: %w", path, err)
+	}
+	s := &scenario{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// validate checks actions and their parameters and applies defaults
+func (s *scenario) validate() error {
+	if s.MaxUtilization == 0 {
+		s.MaxUtilization = 1
+	}
+	if s.MaxUtilization < 0 || s.MaxUtilization > 1 {
+		return fmt.Errorf("maxUtilization must be between 0 and 1, got %g", s.MaxUtilization)
+	}
+	if len(s.Changes) == 0 {
+		return fmt.Errorf("no changes")
+	}
+
+	for i := range s.Changes {
+		c := &s.Changes[i]
+		if c.Namespace == "" {
+			return fmt.Errorf("change %d: namespace is required", i+1)
+		}
+		switch c.Action {
+		case ActionScale:
+			if c.Factor <= 0 {
+				return fmt.Errorf("change %d: factor must be positive (remove drops a workload)", i+1)
+			}
+		case ActionResize:
+			if c.CPUFactor == 0 {
+				c.CPUFactor = 1
+			}
+			if c.MemoryFactor == 0 {
+				c.MemoryFactor = 1
+			}
+			if c.CPUFactor < 0 || c.MemoryFactor < 0 {
+				return fmt.Errorf("change %d: factors must not be negative", i+1)
+			}
+		case ActionRemove, ActionApply:
+		case ActionAdd:
+			if c.Workload == "" || c.Replicas <= 0 {
+				return fmt.Errorf("change %d: add requires workload and replicas", i+1)
+			}
+			for _, q := range []string{c.CPU, c.Memory} {
+				if q == "" {
+					continue
+				}
+				if _, err := resource.ParseQuantity(q); err != nil {
+					return fmt.Errorf("change %d: invalid quantity '%s': %w", i+1, q, err)
+				}
+			}
+		default:
+			return fmt.Errorf("change %d: unknown action '%s' (valid: %s, %s, %s, %s, %s)", i+1, c.Action, ActionScale, ActionResize, ActionRemove, ActionAdd, ActionApply)
+		}
+	}
+	return nil
+}
+
+// appliesRecommendations reports whether a scenario needs the usage quantiles
+func (s *scenario) appliesRecommendations() bool {
+	for _, c := range s.Changes {
+		if c.Action == ActionApply {
+			return true
+		}
+	}
+	return false
+}
+
+// matches reports whether a change applies to a workload
+func (c scenarioChange) matches(w workloadKey) bool {
+	if w.namespace != c.Namespace {
+		return false
+	}
+	if c.Workload == "" {
+		return true
+	}
+	if kind, name, ok := strings.Cut(c.Workload, "/"); ok {
+		return strings.EqualFold(kind, w.kind) && name == w.name
+	}
+	return c.Workload == w.name
+}
+
+// snapshotPods converts active pods to simulation pods placed in their node's
+// pool, with the recommended requests of their workload's containers
+func snapshotPods(pods []corev1.Pod, nodes *corev1.NodeList, recs []containerRecommendation) []simPod {
+	type key struct {
+		workload  workloadKey
+		container string
+	}
+	recommended := make(map[key]containerRecommendation, len(recs))
+	for _, rec := range recs {
+		recommended[key{rec.workload, rec.container}] = rec
+	}
+
+	pools := make(map[string]string)
+	if nodes != nil {
+		for i := range nodes.Items {
+			pools[nodes.Items[i].Name] = nodePool(&nodes.Items[i])
+		}
+	}
+
+	var result []simPod
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		p := simPod{workload: workloadOf(pod), pool: UnscheduledPool}
+		if pool, ok := pools[pod.Spec.NodeName]; ok {
+			p.pool = pool
+		}
+		for _, container := range pod.Spec.Containers {
+			cpu := float64(quantityMilli(container.Resources.Requests.Cpu()))
+			mem := float64(quantityBytes(container.Resources.Requests.Memory()))
+			p.reqCPU += cpu
+			p.reqMem += mem
+			if rec, ok := recommended[key{p.workload, container.Name}]; ok {
+				cpu, mem = float64(rec.cpu), float64(rec.mem)
+			}
+			p.recCPU += cpu
+			p.recMem += mem
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// applyScenario returns the pods after applying all changes in order
+func applyScenario(pods []simPod, s *scenario) []simPod {
+	result := append([]simPod(nil), pods...)
+	for _, c := range s.Changes {
+		if c.Action == ActionAdd {
+			p := simPod{workload: workloadKey{namespace: c.Namespace, kind: "Simulated", name: c.Workload}, pool: c.Pool}
+			if p.pool == "" {
+				p.pool = report.DefaultNodePool
+			}
+			if c.CPU != "" {
+				cpu := resource.MustParse(c.CPU) // Validated by loadScenario
+				p.reqCPU = float64(cpu.MilliValue())
+			}
+			if c.Memory != "" {
+				memory := resource.MustParse(c.Memory)
+				p.reqMem = float64(memory.Value())
+			}
+			for i := 0; i < c.Replicas; i++ {
+				result = append(result, p)
+			}
+			continue
+		}
+
+		kept := result[:0]
+		for _, p := range result {
+			if !c.matches(p.workload) {
+				kept = append(kept, p)
+				continue
+			}
+			switch c.Action {
+			case ActionScale:
+				p.reqCPU *= c.Factor
+				p.reqMem *= c.Factor
+				p.recCPU *= c.Factor
+				p.recMem *= c.Factor
+			case ActionResize:
+				p.reqCPU *= c.CPUFactor
+				p.reqMem *= c.MemoryFactor
+			case ActionApply:
+				p.reqCPU, p.reqMem = p.recCPU, p.recMem
+			case ActionRemove:
+				continue
+			}
+			kept = append(kept, p)
+		}
+		result = kept
+	}
+	return result
+}
+
+// projectPools sums current and projected requests per node pool and sizes each
+// pool for the projected requests at maxUtilization of its average node
+func projectPools(nodes *corev1.NodeList, baseline, projected []simPod, maxUtilization float64) []poolProjection {
+	pools := make(map[string]*poolProjection)
+	get := func(name string) *poolProjection {
+		if pools[name] == nil {
+			pools[name] = &poolProjection{pool: name}
+		}
+		return pools[name]
+	}
+
+	if nodes != nil {
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			p := get(nodePool(node))
+			p.nodes++
+			p.allocCPU += float64(node.Status.Allocatable.Cpu().MilliValue())
+			p.allocMem += float64(node.Status.Allocatable.Memory().Value())
+		}
+	}
+	for _, pod := range baseline {
+		p := get(pod.pool)
+		p.baseCPU += pod.reqCPU
+		p.baseMem += pod.reqMem
+	}
+	for _, pod := range projected {
+		p := get(pod.pool)
+		p.projCPU += pod.reqCPU
+		p.projMem += pod.reqMem
+	}
+
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]poolProjection, 0, len(names))
+	for _, name := range names {
+		p := pools[name]
+		p.nodesNeeded = -1
+		if p.nodes > 0 && p.allocCPU > 0 && p.allocMem > 0 {
+			perNodeCPU := p.allocCPU / float64(p.nodes) * maxUtilization
+			perNodeMem := p.allocMem / float64(p.nodes) * maxUtilization
+			p.nodesNeeded = int(math.Max(math.Ceil(p.projCPU/perNodeCPU), math.Ceil(p.projMem/perNodeMem)))
+		}
+		result = append(result, *p)
+	}
+	return result
+}
+
+// printProjection writes the per-pool projection as an aligned text table
+func printProjection(w io.Writer, projection []poolProjection) error {
+	percent := func(req, alloc float64) string {
+		if alloc == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", req/alloc*100)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Node Pool\tNodes\tCPU Now\tCPU Projected\tMemory Now\tMemory Projected\tCPU Δ (cores)\tMemory Δ (Gi)\tNodes Needed\t")
+	for _, p := range projection {
+		needed := "-"
+		if p.nodesNeeded >= 0 {
+			needed = fmt.Sprintf("%d", p.nodesNeeded)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%s\t%+.2f\t%+.2f\t%s\t\n",
+			p.pool, p.nodes,
+			percent(p.baseCPU, p.allocCPU), percent(p.projCPU, p.allocCPU),
+			percent(p.baseMem, p.allocMem), percent(p.projMem, p.allocMem),
+			(p.projCPU-p.baseCPU)/MilliCoresPerCore, (p.projMem-p.baseMem)/BytesPerGi,
+			needed)
+	}
+	return tw.Flush()
+}
+
+// simulateArgs are the flags of the simulate subcommand
+type simulateArgs struct {
+	scenario, namespace   *string
+	promURL, promQuantile *string
+	promDays              *int
+	kubeconfig            *kubeconfigPaths
+	verbose, quiet        *bool
+}
+
+// simulateFlags defines the flags of the simulate subcommand
+func simulateFlags() (*flag.FlagSet, *simulateArgs) {
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	return fs, &simulateArgs{
+		scenario:     fs.String("scenario", "", "Path to the scenario file (YAML/JSON) with hypothetical changes"),
+		namespace:    fs.String("namespace", os.Getenv("K8S_NAMESPACE"), "Kubernetes namespace (default: all namespaces)"),
+		promURL:      fs.String("prometheus-url", "", "Prometheus base URL of the usage quantiles behind apply changes (bearer token from PROMETHEUS_TOKEN)"),
+		promQuantile: fs.String("prometheus-quantiles", DefaultPrometheusQuantiles, "Comma-separated usage quantiles, the highest one is recommended"),
+		promDays:     fs.Int("prometheus-days", DefaultPrometheusDays, "Lookback window of -prometheus-url in days"),
+		kubeconfig:   kubeconfigFlag(fs),
+		verbose:      fs.Bool("verbose", false, "Enable verbose logging"),
+		quiet:        fs.Bool("quiet", false, "Only log errors (logs always go to stderr)"),
+	}
+}
+
+// runSimulate implements the simulate subcommand: it applies a scenario file to
+// the current cluster and prints projected allocation and node counts per pool
+func runSimulate(args []string) error {
+	fs, a := simulateFlags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := configureLogging(*a.verbose, *a.quiet); err != nil {
+		return err
+	}
+	if *a.scenario == "" {
+		return fmt.Errorf("-scenario is required")
+	}
+	if *a.namespace != "" {
+		if err := validateNamespace(*a.namespace); err != nil {
+			return fmt.Errorf("invalid namespace: %w", err)
+		}
+	}
+	if err := a.kubeconfig.validate(); err != nil {
+		return fmt.Errorf("invalid kubeconfig path: %w", err)
+	}
+
+	s, err := loadScenario(*a.scenario)
+	if err != nil {
+		return err
+	}
+	prometheus, err := newPrometheusSource(*a.promURL, *a.promQuantile, *a.promDays)
+	if err != nil {
+		return fmt.Errorf("invalid prometheus flags: %w", err)
+	}
+	if s.appliesRecommendations() && prometheus == nil {
+		return fmt.Errorf("the %s action requires -prometheus-url", ActionApply)
+	}
+
+	clientSet, err := getK8sClient(a.kubeconfig.String(), APIEncodingProtobuf)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Kubernetes: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultAPITimeout)
+	defer cancel()
+
+	pods, err := clientSet.CoreV1().Pods(*a.namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	nodes, err := clientSet.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes: %w", err)
+	}
+
+	var recs []containerRecommendation
+	if s.appliesRecommendations() {
+		quantiles, err := prometheus.fetch(ctx, *a.namespace)
+		if err != nil {
+			return fmt.Errorf("failed to read usage quantiles: %w", err)
+		}
+		recs = recommendRequests(pods.Items, quantiles)
+		logrus.Infof("Recommended requests of %s", pluralize(len(recs), "container"))
+	}
+
+	baseline := snapshotPods(pods.Items, nodes, recs)
+	projected := applyScenario(baseline, s)
+	logrus.Infof("Applied %d changes to %d pods on %d nodes", len(s.Changes), len(baseline), len(nodes.Items))
+
+	return printProjection(os.Stdout, projectPools(nodes, baseline, projected, s.MaxUtilization))
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestScenarioValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       scenario
+		wantErr bool
+	}{
+		{"scale", scenario{Changes: []scenarioChange{{Action: ActionScale, Namespace: "shop", Factor: 2}}}, false},
+		{"remove", scenario{Changes: []scenarioChange{{Action: ActionRemove, Namespace: "legacy"}}}, false},
+		{"apply", scenario{Changes: []scenarioChange{{Action: ActionApply, Namespace: "shop"}}}, false},
+		{"add", scenario{Changes: []scenarioChange{{Action: ActionAdd, Namespace: "new", Workload: "api", Replicas: 3, CPU: "500m", Memory: "1Gi"}}}, false},
+		{"no changes", scenario{}, true},
+		{"missing namespace", scenario{Changes: []scenarioChange{{Action: ActionRemove}}}, true},
+		{"unknown action", scenario{Changes: []scenarioChange{{Action: "recommend", Namespace: "shop"}}}, true},
+		{"negative factor", scenario{Changes: []scenarioChange{{Action: ActionScale, Namespace: "shop", Factor: -1}}}, true},
+		{"missing factor", scenario{Changes: []scenarioChange{{Action: ActionScale, Namespace: "shop"}}}, true},
+		{"add without replicas", scenario{Changes: []scenarioChange{{Action: ActionAdd, Namespace: "new", Workload: "api"}}}, true},
+		{"add invalid quantity", scenario{Changes: []scenarioChange{{Action: ActionAdd, Namespace: "new", Workload: "api", Replicas: 1, CPU: "lots"}}}, true},
+		{"utilization above 1", scenario{MaxUtilization: 1.5, Changes: []scenarioChange{{Action: ActionRemove, Namespace: "shop"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.s.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	s := scenario{Changes: []scenarioChange{{Action: ActionResize, Namespace: "shop", CPUFactor: 0.5}}}
+	if err := s.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if s.MaxUtilization != 1 || s.Changes[0].MemoryFactor != 1 {
+		t.Errorf("validate() defaults = %g, %g, want 1, 1", s.MaxUtilization, s.Changes[0].MemoryFactor)
+	}
+}
+
+func TestScenarioChangeMatches(t *testing.T) {
+	web := workloadKey{namespace: "shop", kind: "Deployment", name: "web"}
+	tests := []struct {
+		change scenarioChange
+		want   bool
+	}{
+		{scenarioChange{Namespace: "shop"}, true},
+		{scenarioChange{Namespace: "shop", Workload: "web"}, true},
+		{scenarioChange{Namespace: "shop", Workload: "deployment/web"}, true},
+		{scenarioChange{Namespace: "shop", Workload: "StatefulSet/web"}, false},
+		{scenarioChange{Namespace: "shop", Workload: "api"}, false},
+		{scenarioChange{Namespace: "search"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.change.matches(web); got != tt.want {
+			t.Errorf("%+v.matches(web) = %v, want %v", tt.change, got, tt.want)
+		}
+	}
+}
+
+func TestApplyScenarioAndProject(t *testing.T) {
+	pool := func(name string) map[string]string { return map[string]string{"eks.amazonaws.com/nodegroup": name} }
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		testPoolNode("a-1", pool("general"), "4", "16Gi"),
+		testPoolNode("a-2", pool("general"), "4", "16Gi"),
+	}}
+	web := workloadKey{namespace: "shop", kind: "Deployment", name: "web"}
+	legacy := workloadKey{namespace: "legacy", kind: "Deployment", name: "old"}
+	baseline := []simPod{
+		{workload: web, pool: "general", reqCPU: 1000, reqMem: 2 * BytesPerGi},
+		{workload: web, pool: "general", reqCPU: 1000, reqMem: 2 * BytesPerGi},
+		{workload: legacy, pool: "general", reqCPU: 2000, reqMem: 4 * BytesPerGi},
+	}
+
+	s := &scenario{MaxUtilization: 0.5, Changes: []scenarioChange{
+		{Action: ActionScale, Namespace: "shop", Factor: 2},
+		{Action: ActionRemove, Namespace: "legacy"},
+		{Action: ActionAdd, Namespace: "new", Workload: "api", Replicas: 2, Pool: "general", CPU: "500m", Memory: "1Gi"},
+	}}
+	if err := s.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	projected := applyScenario(baseline, s)
+	if len(projected) != 4 {
+		t.Fatalf("applyScenario() returned %d pods, want 4", len(projected))
+	}
+	if baseline[0].reqCPU != 1000 {
+		t.Error("applyScenario() modified the baseline")
+	}
+
+	projection := projectPools(nodes, baseline, projected, s.MaxUtilization)
+	if len(projection) != 1 {
+		t.Fatalf("projectPools() returned %d pools, want 1", len(projection))
+	}
+	p := projection[0]
+	if p.baseCPU != 4000 || p.projCPU != 5000 || p.projMem != 10*BytesPerGi {
+		t.Errorf("projection = %+v", p)
+	}
+	// 5 cores at 2 usable cores per node (4 * 0.5) -> 3 nodes; memory needs 2
+	if p.nodesNeeded != 3 {
+		t.Errorf("nodesNeeded = %d, want 3", p.nodesNeeded)
+	}
+
+	var out bytes.Buffer
+	if err := printProjection(&out, projection); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"general", "50.0%", "62.5%", "+1.00"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("printProjection() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestProjectPoolsUnscheduled(t *testing.T) {
+	pending := []simPod{{pool: UnscheduledPool, reqCPU: 500}}
+	projection := projectPools(nil, pending, pending, 1)
+	if len(projection) != 1 || projection[0].nodesNeeded != -1 {
+		t.Errorf("projectPools() = %+v, want unscheduled pool without node count", projection)
+	}
+}
+
+func TestApplyRecommendations(t *testing.T) {
+	hash := map[string]string{"pod-template-hash": "abc"}
+	pods := []corev1.Pod{
+		testDeliveryPod("web-1", "web-abc", hash, "1"),
+		testDeliveryPod("web-2", "web-abc", hash, "1"),
+		testDeliveryPod("api-1", "api-abc", hash, "500m"), // No usage history
+	}
+	web := workloadKey{namespace: "shop", kind: "Deployment", name: "web"}
+	recs := []containerRecommendation{{workload: web, container: "app", reqCPU: 1000, reqMem: 256 << 20, cpu: 250, mem: 128 << 20}}
+	baseline := snapshotPods(pods, nil, recs)
+
+	s := &scenario{Changes: []scenarioChange{
+		{Action: ActionScale, Namespace: "shop", Workload: "web", Factor: 2},
+		{Action: ActionApply, Namespace: "shop"},
+	}}
+	if err := s.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.appliesRecommendations() {
+		t.Error("appliesRecommendations() = false")
+	}
+
+	projection := projectPools(nil, baseline, applyScenario(baseline, s), s.MaxUtilization)
+	if len(projection) != 1 {
+		t.Fatalf("projectPools() = %+v", projection)
+	}
+	// Twice the recommended 250m per web pod, the api pod keeps its request
+	if p := projection[0]; p.baseCPU != 2500 || p.projCPU != 1500 || p.projMem != 2*2*(128<<20)+256<<20 {
+		t.Errorf("projection = %+v", p)
+	}
+}
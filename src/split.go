@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// UnassignedGroup collects pods that do not belong to any split group
+const UnassignedGroup = "unassigned"
+
+// splitSpec describes how pods are partitioned into per-group workbooks
+type splitSpec struct {
+	kind string // "label", "team" or "namespace"
+	key  string // Label key for kind "label"
+}
+
+// parseSplitBy parses the -split-by flag (label:<key>, team or namespace)
+func parseSplitBy(value string) (*splitSpec, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	kind, key, _ := strings.Cut(value, ":")
+	switch kind {
+	case "label":
+		if key == "" {
+			return nil, fmt.Errorf("missing label key in '%s' (expected label:<key>)", value)
+		}
+		return &splitSpec{kind: kind, key: key}, nil
+	case "team", "namespace":
+		if key != "" {
+			return nil, fmt.Errorf("'%s' does not take a key", kind)
+		}
+		return &splitSpec{kind: kind}, nil
+	default:
+		return nil, fmt.Errorf("unknown split mode '%s' (expected label:<key>, team or namespace)", kind)
+	}
+}
+
+// groupOf returns the group a pod belongs to; pod labels win over namespace labels
+func (s *splitSpec) groupOf(pod *corev1.Pod, nsLabels map[string]string, teams *teamMapping) string {
+	var group string
+	switch s.kind {
+	case "label":
+		if group = pod.Labels[s.key]; group == "" {
+			group = nsLabels[s.key]
+		}
+	case "team":
+		if team, ok := teams.resolve(pod.Labels, nsLabels, pod.Namespace); ok {
+			group = team.Name
+		}
+	case "namespace":
+		group = pod.Namespace
+	}
+
+	if group == "" {
+		return UnassignedGroup
+	}
+	return group
+}
+
+// splitPods partitions running and pending pods into groups
+func splitPods(pods []corev1.Pod, spec *splitSpec, namespaceLabels map[string]map[string]string, teams *teamMapping) map[string][]corev1.Pod {
+	groups := make(map[string][]corev1.Pod)
+	for i := range pods {
+		if !isActivePod(&pods[i]) {
+			continue
+		}
+		group := spec.groupOf(&pods[i], namespaceLabels[pods[i].Namespace], teams)
+		groups[group] = append(groups[group], pods[i])
+	}
+	return groups
+}
+
+// sortedGroups returns group names in alphabetical order
+func sortedGroups(groups map[string][]corev1.Pod) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filterNamespaces keeps only the namespaces referenced by the given pods
+func filterNamespaces(namespaces *corev1.NamespaceList, pods []corev1.Pod) *corev1.NamespaceList {
+	if namespaces == nil {
+		return nil
+	}
+	used := make(map[string]bool)
+	for _, pod := range pods {
+		used[pod.Namespace] = true
+	}
+	filtered := &corev1.NamespaceList{}
+	for _, ns := range namespaces.Items {
+		if used[ns.Name] {
+			filtered.Items = append(filtered.Items, ns)
+		}
+	}
+	return filtered
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// safeFilenamePart replaces characters that are unsafe in filenames with dashes
+func safeFilenamePart(s string) string {
+	return strings.Trim(unsafeFilenameChars.ReplaceAllString(s, "-"), "-.")
+}
+
+// splitFilename derives a per-group filename, e.g. resource_2024-05-01_payments.xlsx
+func splitFilename(filename, group string) string {
+	ext := filepath.Ext(filename)
+	base := strings.TrimSuffix(filename, ext)
+	safeGroup := safeFilenamePart(group)
+	if safeGroup == "" {
+		safeGroup = UnassignedGroup
+	}
+	return fmt.Sprintf("%s_%s%s", base, safeGroup, ext)
+}
+
+// splitFilenames derives the filenames of the groups in order. Group names that
+// differ only in unsafe characters or case would share a file, so later groups
+// get a numeric suffix, e.g. resource_team-a-2.xlsx.
+func splitFilenames(filename string, groups []string) map[string]string {
+	files := make(map[string]string, len(groups))
+	owners := map[string]string{strings.ToLower(filename): ""}
+	for _, group := range groups {
+		name := splitFilename(filename, group)
+		if owner, taken := owners[strings.ToLower(name)]; taken {
+			for n := 2; taken; n++ {
+				name = splitFilename(filename, fmt.Sprintf("%s-%d", safeFilenamePart(group), n))
+				_, taken = owners[strings.ToLower(name)]
+			}
+			logrus.Warnf("Group '%s' has the same filename as group '%s', writing it to %s", group, owner, name)
+		}
+		owners[strings.ToLower(name)] = group
+		files[group] = name
+	}
+	return files
+}
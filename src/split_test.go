@@ -0,0 +1,108 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseSplitBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		wantKind string
+		wantKey  string
+		wantErr  bool
+	}{
+		{"label with key", "label:team", "label", "team", false},
+		{"team", "team", "team", "", false},
+		{"namespace", "namespace", "namespace", "", false},
+		{"label without key", "label:", "", "", true},
+		{"team with key", "team:x", "", "", true},
+		{"unknown mode", "node", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSplitBy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSplitBy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (got.kind != tt.wantKind || got.key != tt.wantKey) {
+				t.Errorf("parseSplitBy() = %+v, want kind %v key %v", got, tt.wantKind, tt.wantKey)
+			}
+		})
+	}
+
+	if got, err := parseSplitBy(""); got != nil || err != nil {
+		t.Errorf("parseSplitBy(\"\") = %v, %v, want nil, nil", got, err)
+	}
+}
+
+func TestSplitPods(t *testing.T) {
+	pod := func(name, ns string, labels map[string]string, phase corev1.PodPhase) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: ns, Labels: labels},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("a", "shop", map[string]string{"team": "payments"}, corev1.PodRunning),
+		pod("b", "shop", nil, corev1.PodPending),
+		pod("c", "search", nil, corev1.PodRunning),
+		pod("d", "shop", map[string]string{"team": "payments"}, corev1.PodSucceeded),
+	}
+	nsLabels := map[string]map[string]string{"shop": {"team": "checkout"}}
+
+	groups := splitPods(pods, &splitSpec{kind: "label", key: "team"}, nsLabels, nil)
+
+	want := map[string]int{"payments": 1, "checkout": 1, UnassignedGroup: 1}
+	if len(groups) != len(want) {
+		t.Fatalf("splitPods() returned %d groups, want %d", len(groups), len(want))
+	}
+	for group, count := range want {
+		if len(groups[group]) != count {
+			t.Errorf("group %s has %d pods, want %d", group, len(groups[group]), count)
+		}
+	}
+}
+
+func TestSplitFilename(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		group    string
+		want     string
+	}{
+		{"simple group", "resource_2024-05-01.xlsx", "payments", "resource_2024-05-01_payments.xlsx"},
+		{"group with unsafe chars", "out/report.xlsx", "team/a b", "out/report_team-a-b.xlsx"},
+		{"traversal in group", "report.xlsx", "../..", "report_unassigned.xlsx"},
+		{"no extension", "report", "x", "report_x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitFilename(tt.filename, tt.group); got != tt.want {
+				t.Errorf("splitFilename() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitFilenames(t *testing.T) {
+	groups := []string{"..", "Team A", "team a", "team-a", "team-a-2", "unassigned"}
+	want := map[string]string{
+		"..":         "report_unassigned.xlsx",
+		"Team A":     "report_Team-A.xlsx",
+		"team a":     "report_team-a-2.xlsx", // Same file as "Team A" on case-insensitive filesystems
+		"team-a":     "report_team-a-3.xlsx",
+		"team-a-2":   "report_team-a-2-2.xlsx",
+		"unassigned": "report_unassigned-2.xlsx",
+	}
+	got := splitFilenames("report.xlsx", groups)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitFilenames() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultStartupWindow is the time after a container start whose usage counts
+// as startup usage
+const DefaultStartupWindow = 5 * time.Minute
+
+// Startup spike detection: the startup peak must exceed the steady-state p95
+// by StartupSpikeRatio, measured over at least StartupMinSteadySamples
+const (
+	StartupSpikeRatio       = 2.0
+	StartupMinSteadySamples = 3
+	StartupLimitShare       = 0.9 // Startup peak share of a limit that risks an OOM kill or throttling
+	StartupHeadroom         = 1.2 // Headroom on top of measured usage in recommendations
+)
+
+// usageSample is one usage measurement of a container
+type usageSample struct {
+	Time   time.Time         `json:"time"`
+	CPU    resource.Quantity `json:"cpu"`
+	Memory resource.Quantity `json:"memory"`
+}
+
+// containerUsage is the usage history of one container run
+type containerUsage struct {
+	Namespace string        `json:"namespace"`
+	Pod       string        `json:"pod"`
+	Container string        `json:"container"`
+	Started   *time.Time    `json:"started,omitempty"` // Default: from the pod status, else the first sample
+	Samples   []usageSample `json:"samples"`
+}
+
+// usageHistory is the container usage given with -usage-history, e.g. exported
+// from Prometheus
+//
+// Example file (YAML or JSON):
+//
+//	startupSeconds: 300
+//	containers:
+//	  - namespace: shop
+//	    pod: web-7d9f8c-x2k4p
+//	    container: app
+//	    samples:
+//	      - {time: "2026-10-14T08:00:30Z", cpu: 1800m, memory: 1400Mi}
+//	      - {time: "2026-10-14T08:10:30Z", cpu: 150m, memory: 600Mi}
+type usageHistory struct {
+	StartupSeconds int              `json:"startupSeconds,omitempty"` // Default: DefaultStartupWindow
+	Containers     []containerUsage `json:"containers"`
+}
+
+// loadUsageHistory reads a usage history file
+func loadUsageHistory(path string) (*usageHistory, error) {
+	if err := validatePath(path); err != nil {
+		return nil, fmt.Errorf("invalid usage history path: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage history %s: %w", path, err)
+	}
+	return parseUsageHistory(data)
+}
+
+// parseUsageHistory parses and validates a usage history document
+func parseUsageHistory(data []byte) (*usageHistory, error) {
+	var h usageHistory
+	if err := yaml.UnmarshalStrict(data, &h); err != nil {
+		return nil, fmt.Errorf("failed to parse usage history: %w", err)
+	}
+	if h.StartupSeconds < 0 {
+		return nil, fmt.Errorf("startupSeconds must not be negative")
+	}
+	for i, c := range h.Containers {
+		if c.Namespace == "" || c.Pod == "" || c.Container == "" {
+			return nil, fmt.Errorf("container %d: namespace, pod and container are required", i+1)
+		}
+	}
+	return &h, nil
+}
+
+// startupWindow returns the configured startup window
+func (h *usageHistory) startupWindow() time.Duration {
+	if h.StartupSeconds > 0 {
+		return time.Duration(h.StartupSeconds) * time.Second
+	}
+	return DefaultStartupWindow
+}
+
+// startupSpike is a container whose startup usage far exceeds its steady state
+type startupSpike struct {
+	workload              workloadKey
+	pod, container        string
+	startupCPU, steadyCPU int64 // Startup peak and steady-state p95 in millicores
+	startupMem, steadyMem int64 // Startup peak and steady-state p95 in bytes
+	reqCPU, limCPU        int64
+	reqMem, limMem        int64
+	recommendations       []string
+}
+
+// cpuSpike and memSpike report whether the startup peak exceeds the steady
+// state by StartupSpikeRatio
+func (s startupSpike) cpuSpike() bool {
+	return s.steadyCPU > 0 && float64(s.startupCPU) >= StartupSpikeRatio*float64(s.steadyCPU)
+}
+
+func (s startupSpike) memSpike() bool {
+	return s.steadyMem > 0 && float64(s.startupMem) >= StartupSpikeRatio*float64(s.steadyMem)
+}
+
+// containerStarted returns when the current run of a container started, zero
+// when the pod status does not tell
+func containerStarted(pod *corev1.Pod, name string) time.Time {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses} {
+		for _, st := range statuses {
+			if st.Name == name && st.State.Running != nil {
+				return st.State.Running.StartedAt.Time
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// startupSpikes compares the startup peak of each container in history with
+// its steady-state p95 and returns the containers of current pods that spike,
+// with startup-aware recommendations
+func startupSpikes(pods []corev1.Pod, history *usageHistory) []startupSpike {
+	type containerRef struct{ namespace, pod, container string }
+	containers := make(map[containerRef]*corev1.Container)
+	podsByRef := make(map[containerRef]*corev1.Pod)
+	for i := range pods {
+		pod := &pods[i]
+		for _, c := range podContainers(pod) {
+			ref := containerRef{pod.Namespace, pod.Name, c.Name}
+			c := c
+			containers[ref], podsByRef[ref] = &c, pod
+		}
+	}
+
+	window := history.startupWindow()
+	var spikes []startupSpike
+	for _, u := range history.Containers {
+		ref := containerRef{u.Namespace, u.Pod, u.Container}
+		c, pod := containers[ref], podsByRef[ref]
+		if c == nil || len(u.Samples) == 0 {
+			continue
+		}
+		samples := append([]usageSample(nil), u.Samples...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i].Time.Before(samples[j].Time) })
+
+		started := containerStarted(pod, u.Container)
+		if u.Started != nil {
+			started = *u.Started
+		}
+		if started.IsZero() {
+			started = samples[0].Time
+		}
+
+		s := startupSpike{
+			workload:  workloadOf(pod),
+			pod:       u.Pod,
+			container: u.Container,
+			reqCPU:    quantityMilli(c.Resources.Requests.Cpu()),
+			limCPU:    quantityMilli(c.Resources.Limits.Cpu()),
+			reqMem:    quantityBytes(c.Resources.Requests.Memory()),
+			limMem:    quantityBytes(c.Resources.Limits.Memory()),
+		}
+		var steadyCPU, steadyMem []int64
+		startupSamples := 0
+		for _, sample := range samples {
+			cpu, mem := sample.CPU.MilliValue(), sample.Memory.Value()
+			if sample.Time.Before(started) {
+				continue
+			}
+			if sample.Time.Sub(started) < window {
+				startupSamples++
+				if cpu > s.startupCPU {
+					s.startupCPU = cpu
+				}
+				if mem > s.startupMem {
+					s.startupMem = mem
+				}
+				continue
+			}
+			steadyCPU = append(steadyCPU, cpu)
+			steadyMem = append(steadyMem, mem)
+		}
+		if startupSamples == 0 || len(steadyCPU) < StartupMinSteadySamples {
+			continue
+		}
+		sort.Slice(steadyCPU, func(i, j int) bool { return steadyCPU[i] < steadyCPU[j] })
+		sort.Slice(steadyMem, func(i, j int) bool { return steadyMem[i] < steadyMem[j] })
+		s.steadyCPU, s.steadyMem = percentileOf(steadyCPU, 95), percentileOf(steadyMem, 95)
+
+		if !s.cpuSpike() && !s.memSpike() {
+			continue
+		}
+		s.recommendations = startupRecommendations(s, c)
+		spikes = append(spikes, s)
+	}
+
+	sort.Slice(spikes, func(i, j int) bool {
+		a, b := spikes[i], spikes[j]
+		if a.workload != b.workload {
+			return a.workload.String() < b.workload.String()
+		}
+		if a.pod != b.pod {
+			return a.pod < b.pod
+		}
+		return a.container < b.container
+	})
+	return spikes
+}
+
+// startupRecommendations suggests requests sized for the steady state with
+// limits that cover the startup peak, and a startupProbe where liveness checks
+// hit a slow, throttled start
+func startupRecommendations(s startupSpike, c *corev1.Container) []string {
+	withHeadroom := func(v int64) int64 { return int64(float64(v) * StartupHeadroom) }
+	var recs []string
+
+	if s.memSpike() {
+		if s.limMem > 0 && float64(s.startupMem) >= StartupLimitShare*float64(s.limMem) {
+			recs = append(recs, fmt.Sprintf("Raise the memory limit to %dMi: startup peaks at %.0f%% of the limit (OOM kill risk at deploy time)",
+				bytesToWholeMi(withHeadroom(s.startupMem)), 100*float64(s.startupMem)/float64(s.limMem)))
+		}
+		if float64(s.reqMem) > StartupSpikeRatio*float64(s.steadyMem) {
+			recs = append(recs, fmt.Sprintf("Lower the memory request to %dMi, the steady state; the limit covers the startup peak",
+				bytesToWholeMi(withHeadroom(s.steadyMem))))
+		}
+	}
+	if s.cpuSpike() {
+		throttled := s.limCPU > 0 && float64(s.startupCPU) >= StartupLimitShare*float64(s.limCPU)
+		if throttled {
+			recs = append(recs, fmt.Sprintf("Raise the CPU limit to %dm or remove it: startup is throttled", withHeadroom(s.startupCPU)))
+		}
+		if float64(s.reqCPU) > StartupSpikeRatio*float64(s.steadyCPU) {
+			recs = append(recs, fmt.Sprintf("Lower the CPU request to %dm, the steady state; the limit covers the startup peak", withHeadroom(s.steadyCPU)))
+		}
+		if throttled && c.LivenessProbe != nil && c.StartupProbe == nil {
+			recs = append(recs, "Add a startupProbe so liveness checks wait for the slow start")
+		}
+	}
+	if len(recs) == 0 {
+		recs = append(recs, "Current requests and limits fit the startup peak")
+	}
+	return recs
+}
+
+// createStartupSheet lists the containers with startup usage spikes next to
+// their requests and limits
+func createStartupSheet(f *excelize.File, spikes []startupSpike, window time.Duration, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create startup spikes sheet: %w", err)
+	}
+
+	headers := []string{
+		"Namespace", "Workload Kind", "Workload", "Pod", "Container",
+		"Startup CPU Peak (m)", "Steady CPU p95 (m)", "CPU Startup Ratio", "Request CPU (m)", "Limit CPU (m)",
+		"Startup Memory Peak (Mi)", "Steady Memory p95 (Mi)", "Memory Startup Ratio", "Request Memory (Mi)", "Limit Memory (Mi)",
+		"Recommendation",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, s := range spikes {
+		data := []interface{}{
+			s.workload.namespace,
+			s.workload.kind,
+			s.workload.name,
+			s.pod,
+			s.container,
+			s.startupCPU,
+			s.steadyCPU,
+			ratio(s.startupCPU, s.steadyCPU),
+			s.reqCPU,
+			s.limCPU,
+			bytesToWholeMi(s.startupMem),
+			bytesToWholeMi(s.steadyMem),
+			ratio(s.startupMem, s.steadyMem),
+			bytesToWholeMi(s.reqMem),
+			bytesToWholeMi(s.limMem),
+			strings.Join(s.recommendations, "; "),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("container '%s'", s.container)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	if row > 2 {
+		last := row - 1
+		f.SetCellStyle(sheetName, "F2", fmt.Sprintf("G%d", last), export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, "H2", fmt.Sprintf("H%d", last), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "I2", fmt.Sprintf("L%d", last), export.IntegerStyle(f))
+		f.SetCellStyle(sheetName, "M2", fmt.Sprintf("M%d", last), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "N2", fmt.Sprintf("O%d", last), export.IntegerStyle(f))
+	}
+	row++
+	f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("Startup: the first %s after a container start; steady state: the p95 of later samples. Listed when the startup peak is at least %.0fx the steady state.",
+		window, StartupSpikeRatio))
+
+	f.SetColWidth(sheetName, "A", "E", 22)
+	f.SetColWidth(sheetName, "F", "O", 16)
+	f.SetColWidth(sheetName, "P", "P", 80)
+
+	return nil
+}
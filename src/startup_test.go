@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseUsageHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		window  time.Duration
+		wantErr bool
+	}{
+		{"default window", `containers: [{namespace: shop, pod: web-1, container: app, samples: [{time: "2026-10-14T08:00:00Z", cpu: 1, memory: 1Gi}]}]`, DefaultStartupWindow, false},
+		{"custom window", `{startupSeconds: 120, containers: []}`, 2 * time.Minute, false},
+		{"missing pod", `containers: [{namespace: shop, container: app}]`, 0, true},
+		{"unknown field", `{containers: [], window: 5m}`, 0, true},
+		{"invalid quantity", `containers: [{namespace: shop, pod: web-1, container: app, samples: [{cpu: lots}]}]`, 0, true},
+		{"negative window", `{startupSeconds: -1}`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, err := parseUsageHistory([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseUsageHistory() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && h.startupWindow() != tt.window {
+				t.Errorf("startupWindow() = %s, want %s", h.startupWindow(), tt.window)
+			}
+		})
+	}
+}
+
+func TestStartupSpikes(t *testing.T) {
+	started := time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC)
+	container := func(name, reqCPU, limCPU, reqMem, limMem string) corev1.Container {
+		return corev1.Container{
+			Name: name,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(reqCPU), corev1.ResourceMemory: resource.MustParse(reqMem)},
+				Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(limCPU), corev1.ResourceMemory: resource.MustParse(limMem)},
+			},
+			LivenessProbe: &corev1.Probe{},
+		}
+	}
+	pod := func(name string, containers ...corev1.Container) corev1.Pod {
+		p := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "shop", Name: name},
+			Spec:       corev1.PodSpec{Containers: containers},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+		for _, c := range containers {
+			p.Status.ContainerStatuses = append(p.Status.ContainerStatuses, corev1.ContainerStatus{
+				Name:  c.Name,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(started)}},
+			})
+		}
+		return p
+	}
+	usage := func(podName, name string, startupCPU, startupMem string, steadyCPU, steadyMem string) containerUsage {
+		u := containerUsage{Namespace: "shop", Pod: podName, Container: name}
+		u.Samples = append(u.Samples, usageSample{Time: started.Add(time.Minute), CPU: resource.MustParse(startupCPU), Memory: resource.MustParse(startupMem)})
+		for i := 1; i <= 4; i++ {
+			u.Samples = append(u.Samples, usageSample{Time: started.Add(time.Duration(i) * 10 * time.Minute), CPU: resource.MustParse(steadyCPU), Memory: resource.MustParse(steadyMem)})
+		}
+		return u
+	}
+
+	pods := []corev1.Pod{
+		pod("java-1", container("app", "1", "1", "1Gi", "1Gi")),
+		pod("steady-1", container("app", "100m", "200m", "128Mi", "256Mi")),
+	}
+	history := &usageHistory{Containers: []containerUsage{
+		usage("java-1", "app", "1", "980Mi", "200m", "400Mi"),
+		usage("steady-1", "app", "120m", "130Mi", "100m", "120Mi"),
+		usage("gone-1", "app", "2", "2Gi", "100m", "100Mi"),
+	}}
+
+	got := startupSpikes(pods, history)
+	if len(got) != 1 {
+		t.Fatalf("startupSpikes() returned %d containers, want 1: %+v", len(got), got)
+	}
+	s := got[0]
+	if s.pod != "java-1" || s.startupCPU != 1000 || s.steadyCPU != 200 || s.startupMem != 980<<20 || s.steadyMem != 400<<20 {
+		t.Errorf("spike = %+v", s)
+	}
+	recs := strings.Join(s.recommendations, "; ")
+	for _, want := range []string{"Raise the memory limit to 1176Mi", "Lower the memory request to 480Mi", "Raise the CPU limit to 1200m", "Lower the CPU request to 240m", "Add a startupProbe"} {
+		if !strings.Contains(recs, want) {
+			t.Errorf("recommendations %q lack %q", recs, want)
+		}
+	}
+}
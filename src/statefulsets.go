@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// StorageClassDefault names claims without a storageClassName, which get the
+// cluster's default class
+const StorageClassDefault = "(default)"
+
+// claimTemplate is one volumeClaimTemplate of a StatefulSet
+type claimTemplate struct {
+	name         string
+	storageClass string // StorageClassDefault when unset
+	bytes        int64  // Requested storage of one replica's claim
+}
+
+// statefulSetFootprint is the compute and storage a StatefulSet requests
+type statefulSetFootprint struct {
+	workload       workloadKey // namespace/StatefulSet/name
+	replicas       int32
+	reqCPU, reqMem int64 // Requests of one replica's pod
+	claims         []claimTemplate
+}
+
+// storage returns the storage requested per replica
+func (s statefulSetFootprint) storage() int64 {
+	var total int64
+	for _, c := range s.claims {
+		total += c.bytes
+	}
+	return total
+}
+
+// storageClasses returns the distinct storage classes of the claims
+func (s statefulSetFootprint) storageClasses() string {
+	seen := make(map[string]bool)
+	var classes []string
+	for _, c := range s.claims {
+		if !seen[c.storageClass] {
+			seen[c.storageClass] = true
+			classes = append(classes, c.storageClass)
+		}
+	}
+	sort.Strings(classes)
+	return strings.Join(classes, ", ")
+}
+
+// computeCost returns the monthly cost of the compute requests of all replicas
+func (s statefulSetFootprint) computeCost(p *pricingSpec) float64 {
+	return p.flatCost(s.reqCPU*int64(s.replicas), s.reqMem*int64(s.replicas))
+}
+
+// storageCost returns the monthly cost of the claims of all replicas
+func (s statefulSetFootprint) storageCost(p *pricingSpec) float64 {
+	var cost float64
+	for _, c := range s.claims {
+		cost += float64(c.bytes) / float64(BytesPerGi) * p.storagePrice(c.storageClass) * float64(s.replicas)
+	}
+	return cost
+}
+
+// statefulSetFootprints combines the pod template requests of each StatefulSet
+// with its volumeClaimTemplates. Replicas are the desired count: claims outlive
+// scaled-down pods unless their retention policy deletes them.
+func statefulSetFootprints(statefulSets []appsv1.StatefulSet) []statefulSetFootprint {
+	footprints := make([]statefulSetFootprint, 0, len(statefulSets)) // Non-nil: no StatefulSets still creates the sheet
+	for i := range statefulSets {
+		sts := &statefulSets[i]
+		fp := statefulSetFootprint{
+			workload: workloadKey{namespace: sts.Namespace, kind: "StatefulSet", name: sts.Name},
+			replicas: 1, // API default
+		}
+		if sts.Spec.Replicas != nil {
+			fp.replicas = *sts.Spec.Replicas
+		}
+		fp.reqCPU, fp.reqMem = podRequests(&corev1.Pod{Spec: sts.Spec.Template.Spec})
+		for _, vct := range sts.Spec.VolumeClaimTemplates {
+			claim := claimTemplate{name: vct.Name, storageClass: StorageClassDefault}
+			if vct.Spec.StorageClassName != nil && *vct.Spec.StorageClassName != "" {
+				claim.storageClass = *vct.Spec.StorageClassName
+			}
+			claim.bytes = quantityBytes(vct.Spec.Resources.Requests.Storage())
+			fp.claims = append(fp.claims, claim)
+		}
+		footprints = append(footprints, fp)
+	}
+	sort.Slice(footprints, func(i, j int) bool {
+		return footprints[i].workload.String() < footprints[j].workload.String()
+	})
+	return footprints
+}
+
+// createStatefulSetSheet lists the per-replica and total compute and storage
+// requests of each StatefulSet, largest storage first. With pricing, the
+// monthly compute and storage cost are added side by side.
+func createStatefulSetSheet(f *excelize.File, footprints []statefulSetFootprint, p *pricingSpec, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create statefulset sheet: %w", err)
+	}
+
+	headers := []interface{}{
+		"StatefulSet", "Replicas", "CPU per Replica (cores)", "Memory per Replica (Gi)", "Storage per Replica (Gi)", "Storage Classes",
+		"Total CPU (cores)", "Total Memory (Gi)", "Total Storage (Gi)",
+	}
+	if p != nil {
+		headers = append(headers,
+			fmt.Sprintf("Compute Cost (%s)", p.Currency), fmt.Sprintf("Storage Cost (%s)", p.Currency),
+			fmt.Sprintf("Total Cost (%s)", p.Currency), "Storage Share of Cost")
+	}
+	if err := export.SetRow(f, sheetName, 1, headers, "statefulset headers"); err != nil {
+		return err
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(headers))
+	f.SetCellStyle(sheetName, "A1", lastCol+"1", export.BoldStyle(f))
+
+	sorted := append([]statefulSetFootprint(nil), footprints...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].storage()*int64(sorted[i].replicas) > sorted[j].storage()*int64(sorted[j].replicas)
+	})
+
+	decimalStyle := export.DecimalStyle(f, false)
+	row := 2
+	for _, fp := range sorted {
+		replicas := int64(fp.replicas)
+		data := []interface{}{
+			fp.workload.namespace + "/" + fp.workload.name, fp.replicas,
+			milliToCores(fp.reqCPU), bytesToGi(fp.reqMem), bytesToGi(fp.storage()), valueOrDash(fp.storageClasses()),
+			milliToCores(fp.reqCPU * replicas), bytesToGi(fp.reqMem * replicas), bytesToGi(fp.storage() * replicas),
+		}
+		if p != nil {
+			compute, storage := fp.computeCost(p), fp.storageCost(p)
+			var share interface{}
+			if total := compute + storage; total > 0 {
+				share = storage / total
+			}
+			data = append(data, compute, storage, compute+storage, share)
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("statefulset '%s'", fp.workload)); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("C%d", row), fmt.Sprintf("E%d", row), decimalStyle)
+		f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("I%d", row), decimalStyle)
+		if p != nil {
+			f.SetCellStyle(sheetName, fmt.Sprintf("J%d", row), fmt.Sprintf("L%d", row), decimalStyle)
+			f.SetCellStyle(sheetName, fmt.Sprintf("M%d", row), fmt.Sprintf("M%d", row), export.PercentStyle(f, "0.0%"))
+		}
+		row++
+	}
+
+	if row > 2 {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Total")
+		cols := []string{"B", "G", "H", "I"}
+		if p != nil {
+			cols = append(cols, "J", "K", "L")
+		}
+		lastTotal := cols[len(cols)-1]
+		for _, col := range cols {
+			f.SetCellFormula(sheetName, fmt.Sprintf("%s%d", col, row), fmt.Sprintf("SUM(%s2:%s%d)", col, col, row-1))
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("B%d", row), export.BoldStyle(f))
+		f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("%s%d", lastTotal, row), export.DecimalStyle(f, true))
+	} else {
+		f.SetCellValue(sheetName, "A2", "No StatefulSets found")
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", lastCol, 20)
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStatefulSetFootprints(t *testing.T) {
+	int32p := func(v int32) *int32 { return &v }
+	fast := "fast"
+	claim := func(name string, class *string, size string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: class,
+				Resources: corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(size),
+				}},
+			},
+		}
+	}
+	sts := func(name string, replicas *int32, claims ...corev1.PersistentVolumeClaim) appsv1.StatefulSet {
+		return appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "data", Name: name},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas:             replicas,
+				VolumeClaimTemplates: claims,
+				Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "db",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("2"),
+						corev1.ResourceMemory: resource.MustParse("8Gi"),
+					}},
+				}}}},
+			},
+		}
+	}
+
+	footprints := statefulSetFootprints([]appsv1.StatefulSet{
+		sts("postgres", int32p(3), claim("data", &fast, "100Gi"), claim("wal", nil, "20Gi")),
+		sts("cache", nil),
+	})
+	if len(footprints) != 2 || footprints[0].workload.name != "cache" {
+		t.Fatalf("statefulSetFootprints() = %+v", footprints)
+	}
+	cache, postgres := footprints[0], footprints[1]
+	if cache.replicas != 1 || cache.storage() != 0 || cache.storageClasses() != "" {
+		t.Errorf("cache = %+v, want 1 replica without claims", cache)
+	}
+	if postgres.replicas != 3 || postgres.reqCPU != 2000 || postgres.storage() != 120<<30 {
+		t.Errorf("postgres = %+v", postgres)
+	}
+	if got := postgres.storageClasses(); got != StorageClassDefault+", fast" {
+		t.Errorf("storageClasses() = %q", got)
+	}
+
+	// 3 replicas of 2 cores * 10 + 8 GiB * 1; 100 GiB at 0.2 and 20 GiB at 0.1
+	p := &pricingSpec{Currency: "EUR", CPUCoreMonth: 10, MemoryGiMonth: 1, StorageGiMonth: 0.1, StorageClassGiMonth: map[string]float64{"fast": 0.2}}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if compute, storage := postgres.computeCost(p), postgres.storageCost(p); !near(compute, 84) || !near(storage, 66) {
+		t.Errorf("costs = %v, %v; want 84 and 66", compute, storage)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createStatefulSetSheet(f, footprints, p, "StatefulSet Footprint"); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A2": "data/postgres", "I2": "360.00", "L2": "150.00", "A3": "data/cache", "A4": "Total"} {
+		if got, _ := f.GetCellValue("StatefulSet Footprint", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
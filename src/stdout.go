@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StdoutPath as -output or -findings writes the text output to stdout, so the
+// tool composes with shell pipelines. Logs go to stderr and stay separate.
+const StdoutPath = "-"
+
+// stdout is the writer of StdoutPath outputs; tests replace it
+var stdout io.Writer = os.Stdout
+
+// validateStdout checks the outputs written to stdout: only text formats of a
+// single report, and only one output at a time
+func validateStdout(filename, format, findingsPath string, split, serve bool) error {
+	if filename == StdoutPath {
+		if format != FormatBI && format != FormatJSON && format != FormatMarkdown {
+			return fmt.Errorf("-output - requires a text format (-format bi writes CSV, -format json JSON, -format markdown Markdown)")
+		}
+		if split {
+			return fmt.Errorf("-output - cannot be combined with -split-by")
+		}
+	}
+	if findingsPath == StdoutPath {
+		if filename == StdoutPath {
+			return fmt.Errorf("-output and -findings cannot both write to stdout")
+		}
+		if serve {
+			return fmt.Errorf("-findings - cannot be combined with -serve")
+		}
+	}
+	return nil
+}
+
+// outputName names an output path in log messages
+func outputName(path string) string {
+	if path == StdoutPath {
+		return "stdout"
+	}
+	return path
+}
+
+// configureLogging sends the logs to stderr, keeping stdout for data, at debug
+// level with -verbose or errors only with -quiet
+func configureLogging(verbose, quiet bool) error {
+	if verbose && quiet {
+		return fmt.Errorf("-verbose and -quiet are mutually exclusive")
+	}
+	logrus.SetOutput(os.Stderr)
+	switch {
+	case verbose:
+		logrus.SetLevel(logrus.DebugLevel)
+	case quiet:
+		logrus.SetLevel(logrus.ErrorLevel)
+	}
+	return nil
+}
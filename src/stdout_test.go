@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestValidateStdout(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		format   string
+		findings string
+		split    bool
+		serve    bool
+		wantErr  bool
+	}{
+		{"files", "out.xlsx", FormatXLSX, "findings.json", true, true, false},
+		{"bi csv", StdoutPath, FormatBI, "", false, false, false},
+		{"json", StdoutPath, FormatJSON, "", false, false, false},
+		{"markdown", StdoutPath, FormatMarkdown, "", false, false, false},
+		{"workbook", StdoutPath, FormatXLSX, "", false, false, true},
+		{"split", StdoutPath, FormatBI, "", true, false, true},
+		{"findings", "out.xlsx", FormatXLSX, StdoutPath, true, false, false},
+		{"both", StdoutPath, FormatBI, StdoutPath, false, false, true},
+		{"findings in server mode", "out.xlsx", FormatXLSX, StdoutPath, false, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStdout(tt.filename, tt.format, tt.findings, tt.split, tt.serve)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateStdout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteToStdout(t *testing.T) {
+	var buf bytes.Buffer
+	saved := stdout
+	stdout = &buf
+	defer func() { stdout = saved }()
+
+	rows := [][]interface{}{make([]interface{}, len(biColumns))}
+	rows[0][2] = "shop"
+	if err := writeBIExport(StdoutPath, rows, csvDialect{delimiter: ';'}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "report_date;cluster;namespace;") || !strings.HasPrefix(lines[1], ";;shop;") {
+		t.Errorf("csv output = %q", buf.String())
+	}
+
+	buf.Reset()
+	meta := reportMetadata{generated: time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)}
+	if err := writeFindings(StdoutPath, FindingsFormatJSON, nil, meta); err != nil {
+		t.Fatal(err)
+	}
+	var report findingsReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Errorf("findings output is not JSON: %v", err)
+	}
+}
+
+func TestConfigureLogging(t *testing.T) {
+	saved := logrus.GetLevel()
+	defer logrus.SetLevel(saved)
+
+	tests := []struct {
+		name           string
+		verbose, quiet bool
+		want           logrus.Level
+		wantErr        bool
+	}{
+		{"default", false, false, logrus.InfoLevel, false},
+		{"verbose", true, false, logrus.DebugLevel, false},
+		{"quiet", false, true, logrus.ErrorLevel, false},
+		{"both", true, true, logrus.InfoLevel, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logrus.SetLevel(logrus.InfoLevel)
+			err := configureLogging(tt.verbose, tt.quiet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configureLogging() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := logrus.GetLevel(); got != tt.want {
+				t.Errorf("level = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
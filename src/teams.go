@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultTeamLabel is the label consulted when the mapping file does not set labelKey
+const DefaultTeamLabel = "team"
+
+// teamInfo holds ownership details for a single team
+type teamInfo struct {
+	Name       string   `json:"-"`
+	Owner      string   `json:"owner,omitempty"`
+	Email      string   `json:"email,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"` // Namespaces assigned to the team without labels
+}
+
+// teamMapping resolves pods to teams using labels and explicit namespace assignments
+//
+// Example file (YAML or JSON):
+//
+//	labelKey: team
+//	labels:
+//	  pay: payments        # label value -> team name
+//	teams:
+//	  payments:
+//	    owner: Jane Doe
+//	    email: payments@example.com
+//	    namespaces: [billing]
+type teamMapping struct {
+	LabelKey string              `json:"labelKey,omitempty"`
+	Labels   map[string]string   `json:"labels,omitempty"`
+	Teams    map[string]teamInfo `json:"teams,omitempty"`
+
+	namespaceTeams map[string]string // Reverse index built from Teams[*].Namespaces
+}
+
+// loadTeamMapping reads a team mapping from a local file or an HTTP(S) endpoint
+func loadTeamMapping(ctx context.Context, source string) (*teamMapping, error) {
+	var data []byte
+	var err error
+
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		data, err = fetchTeamMapping(ctx, source)
+	} else {
+		if err := validatePath(source); err != nil {
+			return nil, fmt.Errorf("invalid team mapping path: %w", err)
+		}
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read team mapping %s: %w", source, err)
+	}
+
+	return parseTeamMapping(data)
+}
+
+// fetchTeamMapping downloads a mapping document, e.g. from an LDAP/CMDB export service
+func fetchTeamMapping(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// parseTeamMapping decodes a YAML or JSON mapping document
+func parseTeamMapping(data []byte) (*teamMapping, error) {
+	var m teamMapping
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse team mapping: %w", err)
+	}
+	if m.LabelKey == "" {
+		m.LabelKey = DefaultTeamLabel
+	}
+
+	m.namespaceTeams = make(map[string]string)
+	for name, info := range m.Teams {
+		for _, ns := range info.Namespaces {
+			if other, ok := m.namespaceTeams[ns]; ok && other != name {
+				return nil, fmt.Errorf("namespace '%s' assigned to teams '%s' and '%s'", ns, other, name)
+			}
+			m.namespaceTeams[ns] = name
+		}
+	}
+
+	return &m, nil
+}
+
+// resolve returns the team owning a pod, checking the pod label first,
+// then the namespace label and finally explicit namespace assignments
+func (m *teamMapping) resolve(podLabels, nsLabels map[string]string, namespace string) (teamInfo, bool) {
+	if m == nil {
+		return teamInfo{}, false
+	}
+
+	for _, labels := range []map[string]string{podLabels, nsLabels} {
+		if value, ok := labels[m.LabelKey]; ok && value != "" {
+			return m.team(m.teamName(value)), true
+		}
+	}
+
+	if name, ok := m.namespaceTeams[namespace]; ok {
+		return m.team(name), true
+	}
+
+	return teamInfo{}, false
+}
+
+// teamName translates a label value to a team name, falling back to the value itself
+func (m *teamMapping) teamName(labelValue string) string {
+	if name, ok := m.Labels[labelValue]; ok && name != "" {
+		return name
+	}
+	return labelValue
+}
+
+func (m *teamMapping) team(name string) teamInfo {
+	info := m.Teams[name]
+	info.Name = name
+	return info
+}
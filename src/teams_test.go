@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseTeamMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantKey string
+		wantErr bool
+	}{
+		{"default label key", "teams:\n  payments:\n    owner: Jane\n", DefaultTeamLabel, false},
+		{"custom label key", "labelKey: owner\n", "owner", false},
+		{"json input", `{"labelKey": "squad"}`, "squad", false},
+		{"invalid yaml", "teams: [", "", true},
+		{"namespace in two teams", "teams:\n  a:\n    namespaces: [x]\n  b:\n    namespaces: [x]\n", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := parseTeamMapping([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTeamMapping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && m.LabelKey != tt.wantKey {
+				t.Errorf("parseTeamMapping() labelKey = %v, want %v", m.LabelKey, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestTeamMappingResolve(t *testing.T) {
+	m, err := parseTeamMapping([]byte(`
+labels:
+  pay: payments
+teams:
+  payments:
+    owner: Jane Doe
+    email: payments@example.com
+  platform:
+    email: platform@example.com
+    namespaces: [kube-system]
+`))
+	if err != nil {
+		t.Fatalf("parseTeamMapping() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		podLabels map[string]string
+		nsLabels  map[string]string
+		namespace string
+		wantTeam  string
+		wantEmail string
+		wantOK    bool
+	}{
+		{"pod label mapped", map[string]string{"team": "pay"}, nil, "shop", "payments", "payments@example.com", true},
+		{"pod label wins over namespace", map[string]string{"team": "pay"}, map[string]string{"team": "other"}, "shop", "payments", "payments@example.com", true},
+		{"namespace label fallback", nil, map[string]string{"team": "payments"}, "shop", "payments", "payments@example.com", true},
+		{"unmapped label value", map[string]string{"team": "search"}, nil, "shop", "search", "", true},
+		{"explicit namespace assignment", nil, nil, "kube-system", "platform", "platform@example.com", true},
+		{"no match", nil, nil, "shop", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := m.resolve(tt.podLabels, tt.nsLabels, tt.namespace)
+			if ok != tt.wantOK || got.Name != tt.wantTeam || got.Email != tt.wantEmail {
+				t.Errorf("resolve() = (%+v, %v), want (%s, %s, %v)", got, ok, tt.wantTeam, tt.wantEmail, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTeamMappingResolveNil(t *testing.T) {
+	var m *teamMapping
+	if _, ok := m.resolve(map[string]string{"team": "a"}, nil, "default"); ok {
+		t.Error("resolve() on nil mapping should not match")
+	}
+}
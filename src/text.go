@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// asciiReplacements maps decorative symbols to plain-ASCII markers for -ascii
+var asciiReplacements = strings.NewReplacer(
+	"⚠️", "[!]",
+	"⚠", "[!]",
+	"✅", "[OK]",
+	"⚡", "[!]",
+	"🔴", "[!!]",
+	"•", "-",
+	"≥", ">=",
+	"≤", "<=",
+	"ä", "ae", "ö", "oe", "ü", "ue",
+	"Ä", "Ae", "Ö", "Oe", "Ü", "Ue",
+	"ß", "ss",
+)
+
+// plainText replaces known decorations with ASCII markers and drops any other
+// non-ASCII runes such as emoji, for consumers that mangle unicode
+func plainText(s string) string {
+	s = asciiReplacements.Replace(s)
+	s = strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"📊 KUBERNETES RESOURCE INSIGHTS", "KUBERNETES RESOURCE INSIGHTS"},
+		{"🏗️ NODE DISTRIBUTION ANALYSIS", "NODE DISTRIBUTION ANALYSIS"},
+		{"⚠️ CPU limits too tight - risk of throttling", "[!] CPU limits too tight - risk of throttling"},
+		{"✅ Well-balanced", "[OK] Well-balanced"},
+		{"🔴 Severely over-provisioned", "[!!] Severely over-provisioned"},
+		{"•", "-"},
+		{"Red (≥80%)", "Red (>=80%)"},
+		{"plain text", "plain text"},
+	}
+	for _, tt := range tests {
+		if got := plainText(tt.in); got != tt.want {
+			t.Errorf("plainText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
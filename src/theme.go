@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultTheme is the color theme used when none is selected
+const DefaultTheme = "default"
+
+// cellColors is a fill color with a matching font color ("" keeps the default black)
+type cellColors struct {
+	fill, font string
+}
+
+// theme holds the colors of all color-coded cells in the workbook
+type theme struct {
+	efficiency [4]cellColors // High, medium, low and very low efficiency
+	heatmap    [3]string     // Color scale for 0%, HeatmapMidValue and HeatmapMaxValue
+	subtotal   cellColors    // Namespace subtotal rows
+}
+
+// themes are the selectable workbook color themes
+var themes = map[string]theme{
+	DefaultTheme: {
+		efficiency: [4]cellColors{{fill: "FF6B6B"}, {fill: "FFE66D"}, {fill: "4ECDC4"}, {fill: "95E1D3"}},
+		heatmap:    [3]string{"#63BE7B", "#FFEB84", "#F8696B"},
+		subtotal:   cellColors{fill: "D9E1F2"},
+	},
+	"light": {
+		efficiency: [4]cellColors{{fill: "F4CCCC"}, {fill: "FFF2CC"}, {fill: "D0E0E3"}, {fill: "D9EAD3"}},
+		heatmap:    [3]string{"#D9EAD3", "#FFF2CC", "#F4CCCC"},
+		subtotal:   cellColors{fill: "EEF3FA"},
+	},
+	"dark": {
+		efficiency: [4]cellColors{
+			{fill: "922B21", font: "FFFFFF"}, {fill: "9A7D0A", font: "FFFFFF"},
+			{fill: "0E6655", font: "FFFFFF"}, {fill: "1D8348", font: "FFFFFF"},
+		},
+		heatmap:  [3]string{"#1D8348", "#9A7D0A", "#922B21"},
+		subtotal: cellColors{fill: "2E4057", font: "FFFFFF"},
+	},
+	// Blue-orange palette distinguishable with red-green color vision deficiencies
+	"cvd": {
+		efficiency: [4]cellColors{
+			{fill: "D55E00", font: "FFFFFF"}, {fill: "E69F00"},
+			{fill: "56B4E9"}, {fill: "0072B2", font: "FFFFFF"},
+		},
+		heatmap:  [3]string{"#4575B4", "#FFFFBF", "#D73027"},
+		subtotal: cellColors{fill: "D9E1F2"},
+	},
+}
+
+// parseTheme looks up a theme by name; an empty name selects the default theme
+func parseTheme(name string) (theme, error) {
+	name = strings.TrimSpace(strings.ToLower(name))
+	if name == "" {
+		name = DefaultTheme
+	}
+	t, ok := themes[name]
+	if !ok {
+		return theme{}, fmt.Errorf("unknown theme '%s' (valid: %s)", name, strings.Join(themeNames(), ", "))
+	}
+	return t, nil
+}
+
+// themeNames returns the sorted theme names
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// efficiencyColors picks the theme colors for an efficiency ratio (request/limit)
+func (t theme) efficiencyColors(efficiency float64) cellColors {
+	pct := efficiency * 100
+	switch {
+	case pct >= HighEfficiency:
+		return t.efficiency[0] // High usage
+	case pct >= MediumEfficiency:
+		return t.efficiency[1] // Medium usage
+	case pct >= LowEfficiency:
+		return t.efficiency[2] // Low usage
+	default:
+		return t.efficiency[3] // Very low usage
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import "testing"
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    theme
+		wantErr bool
+	}{
+		{"", themes[DefaultTheme], false},
+		{"default", themes[DefaultTheme], false},
+		{" CVD ", themes["cvd"], false},
+		{"dark", themes["dark"], false},
+		{"neon", theme{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTheme(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTheme(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseTheme(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEfficiencyColors(t *testing.T) {
+	th := themes[DefaultTheme]
+	tests := []struct {
+		efficiency float64
+		want       cellColors
+	}{
+		{1.0, th.efficiency[0]},
+		{0.8, th.efficiency[0]},
+		{0.6, th.efficiency[1]},
+		{0.45, th.efficiency[2]},
+		{0.1, th.efficiency[3]},
+	}
+	for _, tt := range tests {
+		if got := th.efficiencyColors(tt.efficiency); got != tt.want {
+			t.Errorf("efficiencyColors(%v) = %+v, want %+v", tt.efficiency, got, tt.want)
+		}
+	}
+}
+
+func TestThemesComplete(t *testing.T) {
+	for name, th := range themes {
+		for i, c := range th.efficiency {
+			if c.fill == "" {
+				t.Errorf("theme %s: efficiency color %d has no fill", name, i)
+			}
+		}
+		for i, c := range th.heatmap {
+			if c == "" {
+				t.Errorf("theme %s: heatmap color %d is empty", name, i)
+			}
+		}
+		if th.subtotal.fill == "" {
+			t.Errorf("theme %s: subtotal has no fill", name)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // Zone database for minimal container images without /usr/share/zoneinfo
+)
+
+// loadTimezone resolves an IANA time zone name; an empty name selects local time
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone '%s': %w", name, err)
+	}
+	return location, nil
+}
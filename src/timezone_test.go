@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadTimezone(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"", time.Local.String(), false},
+		{"UTC", "UTC", false},
+		{"Europe/Berlin", "Europe/Berlin", false},
+		{"Mars/Olympus", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadTimezone(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadTimezone(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("loadTimezone(%q) = %s, want %s", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutputFilenameUsesTimezoneDay(t *testing.T) {
+	// 23:30 UTC is already the next calendar day in Berlin
+	utc := time.Date(2024, 4, 30, 23, 30, 0, 0, time.UTC)
+	berlin, err := loadTimezone("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := getOutputFilename("", "", utc), "resource_2024-04-30.xlsx"; got != want {
+		t.Errorf("getOutputFilename(UTC) = %s, want %s", got, want)
+	}
+	if got, want := getOutputFilename("", "", utc.In(berlin)), "resource_2024-05-01.xlsx"; got != want {
+		t.Errorf("getOutputFilename(Berlin) = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultTopologyKeys are the node labels of the Topology sheet without -topology-keys
+const DefaultTopologyKeys = "topology.kubernetes.io/zone"
+
+// TopologyNone is the domain of nodes without the topology label
+const TopologyNone = "(none)"
+
+// NoteSingleDomain marks workloads whose replicas all run in one domain
+const NoteSingleDomain = "All replicas in one domain"
+
+// parseTopologyKeys parses a comma separated list of node label keys; empty
+// selects DefaultTopologyKeys
+func parseTopologyKeys(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = DefaultTopologyKeys
+	}
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range strings.Split(spec, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || seen[key] {
+			continue
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key '%s': %s", key, strings.Join(errs, "; "))
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// topologyDomain is the capacity and requests of the nodes sharing one value
+// of a topology label, e.g. a zone or a rack
+type topologyDomain struct {
+	value              string
+	nodes, pods        int
+	allocCPU, allocMem int64
+	reqCPU, reqMem     int64
+}
+
+// workloadSpread is how the replicas of a workload are spread over the domains
+// of a topology key
+type workloadSpread struct {
+	workload  workloadKey
+	replicas  int
+	domains   int    // Domains with at least one replica
+	largest   string // Domain with the most replicas
+	inLargest int
+}
+
+// share returns the share of the replicas in the largest domain
+func (s workloadSpread) share() float64 {
+	return float64(s.inLargest) / float64(s.replicas)
+}
+
+// topologyView is the domains and workload spread of one topology key
+type topologyView struct {
+	key     string
+	domains []topologyDomain // By value, TopologyNone last
+	spreads []workloadSpread // Workloads with at least two scheduled replicas, least spread first
+}
+
+// newTopologyView groups the nodes by the value of key and places the active
+// scheduled pods in the domain of their node
+func newTopologyView(pods []corev1.Pod, nodes *corev1.NodeList, key string) topologyView {
+	view := topologyView{key: key}
+	domainOf := make(map[string]string)
+	byValue := make(map[string]*topologyDomain)
+	domain := func(value string) *topologyDomain {
+		d, ok := byValue[value]
+		if !ok {
+			d = &topologyDomain{value: value}
+			byValue[value] = d
+		}
+		return d
+	}
+	if nodes != nil {
+		for i := range nodes.Items {
+			node := &nodes.Items[i]
+			value := node.Labels[key]
+			if value == "" {
+				value = TopologyNone
+			}
+			domainOf[node.Name] = value
+			d := domain(value)
+			d.nodes++
+			d.allocCPU += node.Status.Allocatable.Cpu().MilliValue()
+			d.allocMem += node.Status.Allocatable.Memory().Value()
+		}
+	}
+
+	replicas := make(map[workloadKey]map[string]int)
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" || !isActivePod(pod) {
+			continue
+		}
+		value, ok := domainOf[pod.Spec.NodeName]
+		if !ok {
+			value = TopologyNone
+		}
+		d := domain(value)
+		cpu, mem := podRequests(pod)
+		d.pods++
+		d.reqCPU += cpu
+		d.reqMem += mem
+		w := workloadOf(pod)
+		if replicas[w] == nil {
+			replicas[w] = make(map[string]int)
+		}
+		replicas[w][value]++
+	}
+
+	for _, d := range byValue {
+		view.domains = append(view.domains, *d)
+	}
+	sort.Slice(view.domains, func(i, j int) bool {
+		if (view.domains[i].value == TopologyNone) != (view.domains[j].value == TopologyNone) {
+			return view.domains[j].value == TopologyNone
+		}
+		return view.domains[i].value < view.domains[j].value
+	})
+
+	for w, perDomain := range replicas {
+		s := workloadSpread{workload: w, domains: len(perDomain)}
+		for value, n := range perDomain {
+			s.replicas += n
+			if n > s.inLargest || (n == s.inLargest && value < s.largest) {
+				s.largest, s.inLargest = value, n
+			}
+		}
+		if s.replicas >= 2 {
+			view.spreads = append(view.spreads, s)
+		}
+	}
+	sort.Slice(view.spreads, func(i, j int) bool {
+		a, b := view.spreads[i], view.spreads[j]
+		if a.share() != b.share() {
+			return a.share() > b.share()
+		}
+		if a.replicas != b.replicas {
+			return a.replicas > b.replicas
+		}
+		return a.workload.String() < b.workload.String()
+	})
+	return view
+}
+
+// createTopologySheet writes, for each topology key, the capacity and requests
+// per domain followed by the spread of multi-replica workloads. Workloads with
+// all replicas in one domain are highlighted when the key has several domains.
+func createTopologySheet(f *excelize.File, views []topologyView, sheetName string, t theme) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create topology sheet: %w", err)
+	}
+
+	colors := t.efficiency[0]
+	riskStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: colors.font},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{colors.fill}, Pattern: 1},
+	})
+	decimalStyle := export.DecimalStyle(f, false)
+	percentStyle := export.PercentStyle(f, "0.0%")
+
+	row := 1
+	for _, view := range views {
+		f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "Topology Key: "+view.key)
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.BoldStyle(f))
+		row++
+		headers := []interface{}{
+			"Domain", "Nodes", "Pods", "Allocatable CPU (cores)", "Allocatable Memory (Gi)",
+			"Request CPU (cores)", "Request Memory (Gi)", "CPU Requested %", "Memory Requested %", "Share of CPU Requests",
+		}
+		if err := export.SetRow(f, sheetName, row, headers, "topology headers"); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("J%d", row), export.BoldStyle(f))
+		row++
+
+		var totalCPU int64
+		for _, d := range view.domains {
+			totalCPU += d.reqCPU
+		}
+		for _, d := range view.domains {
+			data := []interface{}{
+				d.value, d.nodes, d.pods, milliToCores(d.allocCPU), bytesToGi(d.allocMem),
+				milliToCores(d.reqCPU), bytesToGi(d.reqMem), ratio(d.reqCPU, d.allocCPU), ratio(d.reqMem, d.allocMem), ratio(d.reqCPU, totalCPU),
+			}
+			if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("domain '%s=%s'", view.key, d.value)); err != nil {
+				return err
+			}
+			f.SetCellStyle(sheetName, fmt.Sprintf("D%d", row), fmt.Sprintf("G%d", row), decimalStyle)
+			f.SetCellStyle(sheetName, fmt.Sprintf("H%d", row), fmt.Sprintf("J%d", row), percentStyle)
+			row++
+		}
+
+		row++
+		spreadHeaders := []interface{}{"Workload", "Replicas", "Domains", "Largest Domain", "Replicas in Largest Domain", "Share in Largest Domain", "Note"}
+		if err := export.SetRow(f, sheetName, row, spreadHeaders, "workload spread headers"); err != nil {
+			return err
+		}
+		f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("G%d", row), export.BoldStyle(f))
+		row++
+		if len(view.spreads) == 0 {
+			f.SetCellValue(sheetName, fmt.Sprintf("A%d", row), "No workloads with several scheduled replicas")
+			row++
+		}
+		for _, s := range view.spreads {
+			note := ""
+			if s.domains == 1 && len(view.domains) > 1 {
+				note = NoteSingleDomain
+			}
+			data := []interface{}{s.workload.String(), s.replicas, s.domains, s.largest, s.inLargest, s.share(), note}
+			if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("workload '%s'", s.workload)); err != nil {
+				return err
+			}
+			f.SetCellStyle(sheetName, fmt.Sprintf("F%d", row), fmt.Sprintf("F%d", row), percentStyle)
+			if note != "" {
+				f.SetCellStyle(sheetName, fmt.Sprintf("G%d", row), fmt.Sprintf("G%d", row), riskStyle)
+			}
+			row++
+		}
+		row++
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 45)
+	f.SetColWidth(sheetName, "B", "J", 20)
+	f.SetColWidth(sheetName, "G", "G", 28)
+
+	return nil
+}
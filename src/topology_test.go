@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseTopologyKeys(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{"", []string{"topology.kubernetes.io/zone"}, false},
+		{"topology.kubernetes.io/zone, rack,rack", []string{"topology.kubernetes.io/zone", "rack"}, false},
+		{"example.com/row,", []string{"example.com/row"}, false},
+		{"not a label", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTopologyKeys(tt.spec)
+		if (err != nil) != tt.wantErr || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseTopologyKeys(%q) = %v, %v; want %v", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestNewTopologyView(t *testing.T) {
+	node := func(name, rack string) corev1.Node {
+		n := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("16Gi"),
+			}},
+		}
+		if rack != "" {
+			n.Labels["rack"] = rack
+		}
+		return n
+	}
+	nodes := &corev1.NodeList{Items: []corev1.Node{node("n1", "r1"), node("n2", "r1"), node("n3", "r2"), node("n4", "")}}
+	pod := func(name, owner, nodeName string) corev1.Pod {
+		controller := true
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "db", Name: name, OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: owner, Controller: &controller}}},
+			Spec: corev1.PodSpec{NodeName: nodeName, Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		}
+	}
+	pods := []corev1.Pod{
+		pod("pg-0", "pg", "n1"), pod("pg-1", "pg", "n2"), // Both in rack r1
+		pod("redis-0", "redis", "n1"), pod("redis-1", "redis", "n3"), pod("redis-2", "redis", "n4"),
+		pod("solo-0", "solo", "n3"),
+		pod("pending-0", "pg", ""),
+	}
+
+	view := newTopologyView(pods, nodes, "rack")
+	values := make([]string, 0, len(view.domains))
+	for _, d := range view.domains {
+		values = append(values, d.value)
+	}
+	if !reflect.DeepEqual(values, []string{"r1", "r2", TopologyNone}) {
+		t.Fatalf("domains = %v, want r1, r2 and none last", values)
+	}
+	if r1 := view.domains[0]; r1.nodes != 2 || r1.pods != 3 || r1.allocCPU != 8000 || r1.reqCPU != 3000 {
+		t.Errorf("r1 = %+v", r1)
+	}
+
+	if len(view.spreads) != 2 {
+		t.Fatalf("spreads = %+v, want pg and redis", view.spreads)
+	}
+	pg, redis := view.spreads[0], view.spreads[1]
+	if pg.workload.name != "pg" || pg.replicas != 2 || pg.domains != 1 || pg.largest != "r1" || pg.share() != 1 {
+		t.Errorf("pg = %+v, want both replicas in r1", pg)
+	}
+	if redis.domains != 3 || redis.inLargest != 1 || redis.largest != TopologyNone {
+		t.Errorf("redis = %+v, want one replica per domain", redis)
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	if err := createTopologySheet(f, []topologyView{view}, "Topology", themes[DefaultTheme]); err != nil {
+		t.Fatal(err)
+	}
+	for cell, want := range map[string]string{"A1": "Topology Key: rack", "A3": "r1", "A8": "db/StatefulSet/pg", "G8": NoteSingleDomain, "G9": ""} {
+		if got, _ := f.GetCellValue("Topology", cell); got != want {
+			t.Errorf("%s = %q, want %q", cell, got, want)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.40.0"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/rest"
+)
+
+// TracerName is the instrumentation scope of the report spans
+const TracerName = "github.com/ohauer/PodResourceCalculator"
+
+// ServiceName is the service.name of exported spans unless OTEL_SERVICE_NAME is set
+const ServiceName = "pod-resource-calculator"
+
+// OTLPTracesPath is appended to an -otel-endpoint without a path
+const OTLPTracesPath = "/v1/traces"
+
+// TracingShutdownTimeout bounds the export of the remaining spans at exit
+const TracingShutdownTimeout = 5 * time.Second
+
+// otlpEndpointEnv are the standard variables that enable tracing without -otel-endpoint
+var otlpEndpointEnv = []string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"}
+
+// tracer creates the report spans. Without tracing the global provider is a
+// no-op and spans cost next to nothing; the tracer follows a provider set later.
+var tracer = otel.Tracer(TracerName)
+
+// otlpTracesURL returns the OTLP/HTTP traces URL of -otel-endpoint: a collector
+// base URL like http://otel-collector:4318, or a full traces URL
+func otlpTracesURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid OTLP endpoint '%s': %w", endpoint, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("invalid OTLP endpoint '%s': must be an http or https URL", endpoint)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = OTLPTracesPath
+	}
+	return u.String(), nil
+}
+
+// startTracing exports spans over OTLP/HTTP to endpoint, or to the endpoint of
+// the OTEL_EXPORTER_OTLP_* variables when empty. Without either tracing stays
+// off. The returned stop function flushes the remaining spans; it is safe to
+// call more than once, also from a fatal log exit.
+func startTracing(endpoint string) (func(), error) {
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		tracesURL, err := otlpTracesURL(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, otlptracehttp.WithEndpointURL(tracesURL))
+	} else if !tracingConfigured(os.Getenv) {
+		return func() {}, nil
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	// Attributes of the environment (OTEL_SERVICE_NAME, OTEL_RESOURCE_ATTRIBUTES) win
+	res, err := resource.New(context.Background(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName), semconv.ServiceVersion(version)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logrus.Debugf("Tracing: %v", err)
+	}))
+	logrus.Debugf("Exporting traces over OTLP/HTTP")
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), TracingShutdownTimeout)
+			defer cancel()
+			if err := provider.Shutdown(ctx); err != nil {
+				logrus.Warnf("Failed to export traces: %v", err)
+			}
+		})
+	}, nil
+}
+
+// tracingConfigured reports whether an OTEL_EXPORTER_OTLP_* endpoint is set
+func tracingConfigured(getenv func(string) string) bool {
+	for _, name := range otlpEndpointEnv {
+		if getenv(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// endSpan ends a span, marking it failed when err is set
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// contextOrBackground returns ctx, or the background context when nil
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// tracingTransport records a client span for each API request, a child of the
+// phase span in the request context, and passes the trace to the API server
+// through the traceparent header for clusters with API server tracing
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+// traceRequests adds the tracingTransport to the clients of config
+func traceRequests(config *rest.Config) {
+	config.Wrap(func(rt http.RoundTripper) http.RoundTripper { return tracingTransport{next: rt} })
+}
+
+func (t tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := tracer.Start(req.Context(), req.Method+" "+apiResource(req.URL.Path),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLPath(req.URL.Path),
+			semconv.ServerAddress(req.URL.Hostname()),
+		))
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		endSpan(span, err)
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	span.End()
+	return resp, nil
+}
+
+// apiResource names the resource of an API path for span names, without
+// namespace and object names: /api/v1/namespaces/shop/pods gives pods
+func apiResource(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return path
+	}
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	if len(parts) == 0 {
+		return path
+	}
+	if len(parts) >= 3 {
+		return parts[0] + "/" + parts[2] // Subresource like pods/log
+	}
+	return parts[0]
+}
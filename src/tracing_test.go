@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/collector"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestOTLPTracesURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{"http://otel-collector:4318", "http://otel-collector:4318/v1/traces", false},
+		{"https://otel.example.com/", "https://otel.example.com/v1/traces", false},
+		{"http://otel-collector:4318/custom/traces", "http://otel-collector:4318/custom/traces", false},
+		{"otel-collector:4318", "", true},
+		{"grpc://otel-collector:4317", "", true},
+		{"http://", "", true},
+	}
+	for _, tt := range tests {
+		got, err := otlpTracesURL(tt.endpoint)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("otlpTracesURL(%q) = %q, %v", tt.endpoint, got, err)
+		}
+	}
+}
+
+func TestTracingConfigured(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(name string) string { return env[name] }
+	if tracingConfigured(getenv) {
+		t.Error("tracing must stay off without an endpoint")
+	}
+	env["OTEL_EXPORTER_OTLP_ENDPOINT"] = "http://otel-collector:4318"
+	if !tracingConfigured(getenv) {
+		t.Error("OTEL_EXPORTER_OTLP_ENDPOINT must enable tracing")
+	}
+}
+
+func TestAPIResource(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/v1/pods", "pods"},
+		{"/api/v1/namespaces/shop/pods", "pods"},
+		{"/api/v1/namespaces/shop/pods/web-1", "pods"},
+		{"/api/v1/namespaces/shop/pods/web-1/log", "pods/log"},
+		{"/api/v1/namespaces", "namespaces"},
+		{"/api/v1/namespaces/shop", "namespaces"},
+		{"/apis/apps/v1/namespaces/shop/replicasets", "replicasets"},
+		{"/apis/batch/v1/jobs", "jobs"},
+		{"/version", "/version"},
+		{"/api", "/api"},
+	}
+	for _, tt := range tests {
+		if got := apiResource(tt.path); got != tt.want {
+			t.Errorf("apiResource(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestTracingTransport(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[]}`))
+	}))
+	defer srv.Close()
+
+	config := &rest.Config{Host: srv.URL}
+	traceRequests(config)
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, parent := tracer.Start(context.Background(), "collect")
+	if _, err := collector.NewPodLister(collector.ListModeConsistent, 0).List(ctx, clientSet, "shop"); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want the request and its parent", len(spans))
+	}
+	request := spans[0]
+	if request.Name() != "GET pods" || request.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("request span %q with parent %s", request.Name(), request.Parent().SpanID())
+	}
+	if want := "00-" + parent.SpanContext().TraceID().String() + "-" + request.SpanContext().SpanID().String() + "-01"; traceparent != want {
+		t.Errorf("traceparent = %q, want %q", traceparent, want)
+	}
+}
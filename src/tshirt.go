@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// tshirtSizeSpec is a T-shirt size as written in the config file.
+// Empty maximums mean unbounded; the last size usually has none.
+type tshirtSizeSpec struct {
+	Name      string `json:"name"`
+	MaxCPU    string `json:"maxCPU,omitempty"`    // e.g. "250m"
+	MaxMemory string `json:"maxMemory,omitempty"` // e.g. "512Mi"
+}
+
+// defaultTShirtSizes is used when the config file defines no sizes
+var defaultTShirtSizes = []tshirtSizeSpec{
+	{Name: "S", MaxCPU: "250m", MaxMemory: "512Mi"},
+	{Name: "M", MaxCPU: "1", MaxMemory: "2Gi"},
+	{Name: "L", MaxCPU: "2", MaxMemory: "4Gi"},
+	{Name: "XL"},
+}
+
+// tshirtSize is a parsed size; zero maximums mean unbounded
+type tshirtSize struct {
+	name   string
+	maxCPU int64 // millicores
+	maxMem int64 // bytes
+}
+
+// parseTShirtSizes converts config specs into sizes ordered from small to large
+func parseTShirtSizes(specs []tshirtSizeSpec) ([]tshirtSize, error) {
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no T-shirt sizes defined")
+	}
+
+	sizes := make([]tshirtSize, 0, len(specs))
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("T-shirt size without name")
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("duplicate T-shirt size '%s'", spec.Name)
+		}
+		seen[spec.Name] = true
+
+		size := tshirtSize{name: spec.Name}
+		if spec.MaxCPU != "" {
+			q, err := resource.ParseQuantity(spec.MaxCPU)
+			if err != nil {
+				return nil, fmt.Errorf("invalid maxCPU for size '%s': %w", spec.Name, err)
+			}
+			size.maxCPU = q.MilliValue()
+		}
+		if spec.MaxMemory != "" {
+			q, err := resource.ParseQuantity(spec.MaxMemory)
+			if err != nil {
+				return nil, fmt.Errorf("invalid maxMemory for size '%s': %w", spec.Name, err)
+			}
+			size.maxMem = q.Value()
+		}
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
+
+// classifyTShirt returns the smallest size fitting both requests.
+// Containers exceeding every size get the last size name with a "+" suffix.
+func classifyTShirt(sizes []tshirtSize, reqCPU, reqMem int64) string {
+	for _, size := range sizes {
+		if (size.maxCPU == 0 || reqCPU <= size.maxCPU) && (size.maxMem == 0 || reqMem <= size.maxMem) {
+			return size.name
+		}
+	}
+	return sizes[len(sizes)-1].name + "+"
+}
+
+// tshirtSizeNames returns all size names in order, including the overflow class
+func tshirtSizeNames(sizes []tshirtSize) []string {
+	names := make([]string, 0, len(sizes)+1)
+	for _, size := range sizes {
+		names = append(names, size.name)
+	}
+	last := sizes[len(sizes)-1]
+	if last.maxCPU != 0 || last.maxMem != 0 {
+		names = append(names, last.name+"+")
+	}
+	return names
+}
+
+// createTShirtSheet writes the T-shirt size distribution per namespace
+func createTShirtSheet(f *excelize.File, counts map[string]map[string]int, sizeNames []string, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create T-shirt size sheet: %w", err)
+	}
+
+	headers := append([]string{"Namespace"}, sizeNames...)
+	headers = append(headers, "Total")
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	var sortedNamespaces []string
+	for ns := range counts {
+		sortedNamespaces = append(sortedNamespaces, ns)
+	}
+	sort.Strings(sortedNamespaces)
+
+	clusterCounts := make(map[string]int)
+	row := 2
+	for _, ns := range sortedNamespaces {
+		data := []interface{}{ns}
+		total := 0
+		for _, name := range sizeNames {
+			data = append(data, counts[ns][name])
+			total += counts[ns][name]
+			clusterCounts[name] += counts[ns][name]
+		}
+		data = append(data, total)
+
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("namespace '%s'", ns)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	totalData := []interface{}{"CLUSTER TOTAL"}
+	clusterTotal := 0
+	for _, name := range sizeNames {
+		totalData = append(totalData, clusterCounts[name])
+		clusterTotal += clusterCounts[name]
+	}
+	totalData = append(totalData, clusterTotal)
+	if err := export.SetRow(f, sheetName, row, totalData, "cluster totals"); err != nil {
+		return err
+	}
+	lastCell, _ := excelize.CoordinatesToCellName(len(headers), row)
+	f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), lastCell, export.BoldStyle(f))
+
+	lastCol, _ := excelize.ColumnNumberToName(len(headers))
+	f.SetColWidth(sheetName, "A", "A", 20)
+	f.SetColWidth(sheetName, "B", lastCol, 10)
+
+	return nil
+}
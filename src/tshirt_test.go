@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestClassifyTShirt(t *testing.T) {
+	sizes, err := parseTShirtSizes(defaultTShirtSizes)
+	if err != nil {
+		t.Fatalf("parseTShirtSizes() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		reqCPU int64
+		reqMem int64
+		want   string
+	}{
+		{"no requests", 0, 0, "S"},
+		{"small", 250, 512 * 1024 * 1024, "S"},
+		{"cpu pushes to medium", 251, 0, "M"},
+		{"memory pushes to large", 100, 3 * 1024 * 1024 * 1024, "L"},
+		{"unbounded largest size", 64000, 256 * 1024 * 1024 * 1024, "XL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyTShirt(sizes, tt.reqCPU, tt.reqMem); got != tt.want {
+				t.Errorf("classifyTShirt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyTShirtOverflow(t *testing.T) {
+	sizes, err := parseTShirtSizes([]tshirtSizeSpec{{Name: "small", MaxCPU: "500m"}, {Name: "big", MaxCPU: "2"}})
+	if err != nil {
+		t.Fatalf("parseTShirtSizes() error = %v", err)
+	}
+
+	if got := classifyTShirt(sizes, 3000, 0); got != "big+" {
+		t.Errorf("classifyTShirt() = %v, want big+", got)
+	}
+	if got, want := tshirtSizeNames(sizes), []string{"small", "big", "big+"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("tshirtSizeNames() = %v, want %v", got, want)
+	}
+}
+
+func TestParseTShirtSizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		specs   []tshirtSizeSpec
+		wantErr bool
+	}{
+		{"defaults", defaultTShirtSizes, false},
+		{"empty", nil, true},
+		{"missing name", []tshirtSizeSpec{{MaxCPU: "1"}}, true},
+		{"duplicate name", []tshirtSizeSpec{{Name: "S"}, {Name: "S"}}, true},
+		{"invalid cpu", []tshirtSizeSpec{{Name: "S", MaxCPU: "lots"}}, true},
+		{"invalid memory", []tshirtSizeSpec{{Name: "S", MaxMemory: "1XB"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTShirtSizes(tt.specs); (err != nil) != tt.wantErr {
+				t.Errorf("parseTShirtSizes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := loadConfig("")
+	if err != nil {
+		t.Fatalf("loadConfig(\"\") error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg.TShirtSizes, defaultTShirtSizes) {
+		t.Errorf("loadConfig(\"\") sizes = %v, want defaults", cfg.TShirtSizes)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("tshirtSizes:\n  - name: tiny\n    maxCPU: 100m\n  - name: huge\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig() error = %v", err)
+	}
+	if len(cfg.TShirtSizes) != 2 || cfg.TShirtSizes[0].Name != "tiny" {
+		t.Errorf("loadConfig() sizes = %v", cfg.TShirtSizes)
+	}
+
+	badPath := filepath.Join(dir, "bad.yaml")
+	if err := os.WriteFile(badPath, []byte("unknownKey: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfig(badPath); err == nil {
+		t.Error("loadConfig() should reject unknown keys")
+	}
+}
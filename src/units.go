@@ -0,0 +1,34 @@
+package main
+
+import (
+	"math"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+)
+
+// Unit conversion factors, shared with the report package
+const (
+	BytesPerKi        = report.BytesPerKi
+	BytesPerMi        = report.BytesPerMi
+	BytesPerGi        = report.BytesPerGi
+	MilliCoresPerCore = report.MilliCoresPerCore
+)
+
+// bytesToGi converts bytes to gibibytes
+func bytesToGi(bytes int64) float64 {
+	return float64(bytes) / BytesPerGi
+}
+
+// bytesToWholeMi converts bytes to mebibytes, rounding partial Mi up
+func bytesToWholeMi(bytes int64) int64 {
+	return int64(math.Ceil(bytesToMi(bytes)))
+}
+
+// Quantities in millicores and bytes and their conversions, shared with the
+// report package
+var (
+	quantityMilli = report.QuantityMilli
+	quantityBytes = report.QuantityBytes
+	milliToCores  = report.MilliToCores
+	bytesToMi     = report.BytesToMi
+)
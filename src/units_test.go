@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestMemoryConversions(t *testing.T) {
+	tests := []struct {
+		name      string
+		quantity  string
+		wantBytes int64
+		wantMi    float64
+		wantWhole int64
+	}{
+		{"binary Ki", "512Ki", 512 * 1024, 0.5, 1},
+		{"binary Mi", "128Mi", 128 * 1024 * 1024, 128, 128},
+		{"binary Gi", "2Gi", 2 * 1024 * 1024 * 1024, 2048, 2048},
+		{"decimal k", "1k", 1000, 1000.0 / 1048576, 1},
+		{"decimal M", "128M", 128000000, 128000000.0 / 1048576, 123},
+		{"decimal G", "1G", 1000000000, 1000000000.0 / 1048576, 954},
+		{"plain bytes", "1048576", 1048576, 1, 1},
+		{"exponent", "1e6", 1000000, 1000000.0 / 1048576, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := resource.MustParse(tt.quantity)
+			bytes := quantityBytes(&q)
+			if bytes != tt.wantBytes {
+				t.Errorf("quantityBytes(%s) = %d, want %d", tt.quantity, bytes, tt.wantBytes)
+			}
+			if got := bytesToMi(bytes); got != tt.wantMi {
+				t.Errorf("bytesToMi(%d) = %v, want %v", bytes, got, tt.wantMi)
+			}
+			if got := bytesToWholeMi(bytes); got != tt.wantWhole {
+				t.Errorf("bytesToWholeMi(%d) = %v, want %v", bytes, got, tt.wantWhole)
+			}
+		})
+	}
+}
+
+func TestGiConversion(t *testing.T) {
+	decimal := resource.MustParse("10G")
+	binary := resource.MustParse("10Gi")
+
+	if got := bytesToGi(quantityBytes(&binary)); got != 10 {
+		t.Errorf("bytesToGi(10Gi) = %v, want 10", got)
+	}
+	if got := bytesToGi(quantityBytes(&decimal)); got >= 10 || got < 9.31 {
+		t.Errorf("bytesToGi(10G) = %v, want ~9.31", got)
+	}
+}
+
+func TestCPUConversions(t *testing.T) {
+	tests := []struct {
+		name      string
+		quantity  string
+		wantMilli int64
+		wantCores float64
+	}{
+		{"millicores", "250m", 250, 0.25},
+		{"whole cores", "2", 2000, 2},
+		{"fractional cores", "0.5", 500, 0.5},
+		{"sub-millicore rounds up", "0.0001", 1, 0.001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := resource.MustParse(tt.quantity)
+			milli := quantityMilli(&q)
+			if milli != tt.wantMilli {
+				t.Errorf("quantityMilli(%s) = %d, want %d", tt.quantity, milli, tt.wantMilli)
+			}
+			if got := milliToCores(milli); got != tt.wantCores {
+				t.Errorf("milliToCores(%d) = %v, want %v", milli, got, tt.wantCores)
+			}
+		})
+	}
+}
@@ -0,0 +1,470 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/sirupsen/logrus"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Validation rule names used in the config file
+const (
+	RuleNamespaceWithoutLimits = "namespace-without-limits"
+	RuleNodePodImbalance       = "node-pod-imbalance"
+	RuleCapacitySaturation     = "capacity-saturation"
+	RuleNewNamespaceNoLimits   = "new-namespace-without-limits"
+	RuleWorkloadSpecDrift      = "workload-spec-drift"
+	RuleCronJobBurst           = "cronjob-burst"
+	RuleSavingsOpportunity     = "savings-opportunity"
+)
+
+// validationRuleDescriptions explain each rule in findings exports
+var validationRuleDescriptions = map[string]string{
+	RuleNamespaceWithoutLimits: "Namespace without any CPU or memory limit",
+	RuleNodePodImbalance:       "Pod count of the busiest node exceeds the threshold times the least busy node",
+	RuleCapacitySaturation:     "CPU or memory requests exceed the threshold share of allocatable capacity",
+	RuleNewNamespaceNoLimits:   "Recently created namespace without any CPU or memory limit",
+	RuleWorkloadSpecDrift:      "Pods of one workload run more than the threshold number of different resource specs",
+	RuleCronJobBurst:           "Forecast CronJob runs take requests above the threshold share of allocatable capacity",
+	RuleSavingsOpportunity:     "CPU requests exceed the usage based recommendations by more than the threshold in cores",
+}
+
+// ExitFindings is the exit code when validation findings reach the -fail-on severity
+const ExitFindings = 2
+
+// MaxLoggedFindings is the number of findings logged per rule; the Warnings sheet lists all
+const MaxLoggedFindings = 3
+
+// severity orders validation findings; severityOff disables a rule or failing
+type severity int
+
+const (
+	severityOff severity = iota
+	severityInfo
+	severityWarn
+	severityError
+)
+
+var severityNames = map[severity]string{severityOff: "off", severityInfo: "info", severityWarn: "warn", severityError: "error"}
+
+func (s severity) String() string {
+	return severityNames[s]
+}
+
+// parseSeverity parses info, warn, error or off
+func parseSeverity(name string) (severity, error) {
+	for s, n := range severityNames {
+		if strings.EqualFold(name, n) {
+			return s, nil
+		}
+	}
+	return severityOff, fmt.Errorf("unknown severity '%s' (valid: info, warn, error, off)", name)
+}
+
+// validationRuleSpec overrides a rule's defaults in the config file
+type validationRuleSpec struct {
+	Severity  string   `json:"severity,omitempty"`  // info, warn, error or off
+	Threshold *float64 `json:"threshold,omitempty"` // Rule specific, see defaultValidationRules
+}
+
+// validationSpec is the validation section of the config file
+type validationSpec struct {
+	FailOn string                        `json:"failOn,omitempty"` // Overridden by -fail-on
+	Rules  map[string]validationRuleSpec `json:"rules,omitempty"`
+}
+
+// validationRule is a parsed rule setting
+type validationRule struct {
+	severity  severity
+	threshold float64
+}
+
+// defaultValidationRules are the built-in rules and their defaults:
+// namespace-without-limits tolerates threshold namespaces without any limit,
+// node-pod-imbalance fires when the busiest node runs more than threshold times
+// the pods of the least busy node, capacity-saturation when requests exceed the
+// threshold ratio of allocatable in the cluster or a node pool, and
+// new-namespace-without-limits for namespaces younger than threshold days
+// without limits. The last two are meant as -fail-on triggers for scheduled runs.
+// workload-spec-drift fires when the pods of one controller run more than
+// threshold different resource specs. cronjob-burst fires when overlapping
+// CronJob runs of the -cronjob-forecast take the requests above the threshold
+// ratio of allocatable. savings-opportunity fires when the CPU requests exceed
+// the recommendations from the -prometheus-url usage quantiles by more than
+// threshold cores.
+var defaultValidationRules = map[string]validationRule{
+	RuleNamespaceWithoutLimits: {severity: severityWarn, threshold: 0},
+	RuleNodePodImbalance:       {severity: severityWarn, threshold: 2},
+	RuleCapacitySaturation:     {severity: severityOff, threshold: 0.8},
+	RuleNewNamespaceNoLimits:   {severity: severityOff, threshold: 7},
+	RuleWorkloadSpecDrift:      {severity: severityWarn, threshold: 1},
+	RuleCronJobBurst:           {severity: severityWarn, threshold: 0.9},
+	RuleSavingsOpportunity:     {severity: severityOff, threshold: 4},
+}
+
+// validationRules are the effective rule settings; failOn is severityOff when
+// findings never fail the run
+type validationRules struct {
+	rules  map[string]validationRule
+	failOn severity
+	review *reviewFile // Decisions of earlier reviews, nil without -review
+}
+
+// parseValidationRules applies config overrides to the default rules
+func parseValidationRules(spec validationSpec) (validationRules, error) {
+	result := validationRules{rules: make(map[string]validationRule, len(defaultValidationRules))}
+	for name, rule := range defaultValidationRules {
+		result.rules[name] = rule
+	}
+
+	for name, override := range spec.Rules {
+		rule, ok := result.rules[name]
+		if !ok {
+			return result, fmt.Errorf("unknown validation rule '%s'", name)
+		}
+		if override.Severity != "" {
+			s, err := parseSeverity(override.Severity)
+			if err != nil {
+				return result, fmt.Errorf("rule '%s': %w", name, err)
+			}
+			rule.severity = s
+		}
+		if override.Threshold != nil {
+			if *override.Threshold < 0 {
+				return result, fmt.Errorf("rule '%s': negative threshold", name)
+			}
+			rule.threshold = *override.Threshold
+		}
+		result.rules[name] = rule
+	}
+
+	if spec.FailOn != "" {
+		s, err := parseSeverity(spec.FailOn)
+		if err != nil {
+			return result, fmt.Errorf("failOn: %w", err)
+		}
+		result.failOn = s
+	}
+	return result, nil
+}
+
+// validationFinding is a single rule violation
+type validationFinding struct {
+	rule     string
+	severity severity
+	subject  string // e.g. "namespace/shop" or "cluster"
+	message  string
+	action   string // Review action of an earlier review, empty when not reviewed
+	owner    string // Owner of an earlier review
+}
+
+// acknowledged reports whether an earlier review accepted or rejected the
+// finding; acknowledged findings do not fail -fail-on
+func (f validationFinding) acknowledged() bool {
+	return f.action == ReviewAccept || f.action == ReviewReject
+}
+
+// findingsError is returned by generateExcel after the report was saved when
+// findings reach the configured fail-on severity
+type findingsError struct {
+	count  int
+	failOn severity
+}
+
+func (e *findingsError) Error() string {
+	return fmt.Sprintf("%d validation findings at or above severity '%s'", e.count, e.failOn)
+}
+
+// isFindingsError reports whether err only signals findings above the fail-on severity
+func isFindingsError(err error) bool {
+	var fe *findingsError
+	return errors.As(err, &fe)
+}
+
+// failingFindings returns a findingsError when findings reach the fail-on severity
+func (r validationRules) failingFindings(findings []validationFinding) error {
+	if r.failOn == severityOff {
+		return nil
+	}
+	count := 0
+	for _, finding := range findings {
+		if finding.severity >= r.failOn && !finding.acknowledged() {
+			count++
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	return &findingsError{count: count, failOn: r.failOn}
+}
+
+// validationInput is the report data the rules are evaluated on
+type validationInput struct {
+	namespaceTotals  map[string]namespaceTotal
+	nodeTotals       map[string]nodeTotal
+	saturation       []poolSaturation     // Cluster row first, see saturationByPool
+	namespaceCreated map[string]time.Time // Namespace creation times, nil when unknown
+	specDrift        []workloadDrift      // Workloads with mixed pod resource specs
+	forecast         *cronJobForecast     // CronJob peaks, nil without -cronjob-forecast
+	savings          *int64               // CPU requests above the recommendations in millicores, nil without usage quantiles
+	now              time.Time
+}
+
+// snapshotValidationInput is the validation input of a snapshot, the same
+// input the report workbook uses
+func snapshotValidationInput(snap *clusterSnapshot, horizon time.Duration) validationInput {
+	namespaceTotals, nodeTotals := aggregateTotals(snap.pods, snap.nodes)
+	return validationInput{
+		namespaceTotals:  namespaceTotals,
+		nodeTotals:       nodeTotals,
+		saturation:       saturationByPool(snap.nodes, nodeTotals),
+		namespaceCreated: namespaceCreation(snap.namespaces),
+		specDrift:        workloadSpecDrift(snap.pods),
+		forecast:         snap.forecast(horizon),
+		savings:          savingsOpportunity(snap.pods, snap.quantiles),
+		now:              snap.collected,
+	}
+}
+
+// snapshotFindings evaluates the validation rules on the containers of the
+// active pods of a snapshot
+func snapshotFindings(snap *clusterSnapshot, r validationRules, horizon time.Duration) []validationFinding {
+	containers := 0
+	for i := range snap.pods {
+		if isActivePod(&snap.pods[i]) {
+			containers += len(snap.pods[i].Spec.Containers)
+		}
+	}
+	return validateAndWarnResources(snapshotValidationInput(snap, horizon), containers, r)
+}
+
+// namespaceCreation indexes namespace creation timestamps by name
+func namespaceCreation(namespaces *corev1.NamespaceList) map[string]time.Time {
+	created := make(map[string]time.Time)
+	if namespaces != nil {
+		for _, ns := range namespaces.Items {
+			created[ns.Name] = ns.CreationTimestamp.Time
+		}
+	}
+	return created
+}
+
+// validateResources evaluates the enabled rules, most severe findings first
+func validateResources(in validationInput, r validationRules) []validationFinding {
+	var findings []validationFinding
+
+	// Check for namespaces without limits
+	if rule := r.rules[RuleNamespaceWithoutLimits]; rule.severity != severityOff {
+		var noLimits []string
+		for ns, totals := range in.namespaceTotals {
+			if totals.limCPU == 0 && totals.limMem == 0 {
+				noLimits = append(noLimits, ns)
+			}
+		}
+		if float64(len(noLimits)) > rule.threshold {
+			sort.Strings(noLimits)
+			for _, ns := range noLimits {
+				findings = append(findings, validationFinding{
+					rule: RuleNamespaceWithoutLimits, severity: rule.severity, subject: "namespace/" + ns,
+					message: fmt.Sprintf("Namespace '%s' has no resource limits", ns),
+				})
+			}
+		}
+	}
+
+	// Check for unbalanced nodes
+	if rule := r.rules[RuleNodePodImbalance]; rule.severity != severityOff && len(in.nodeTotals) > 1 {
+		minPods, maxPods := -1, 0
+		for _, totals := range in.nodeTotals {
+			if minPods < 0 || totals.podCount < minPods {
+				minPods = totals.podCount
+			}
+			if totals.podCount > maxPods {
+				maxPods = totals.podCount
+			}
+		}
+		if float64(maxPods) > float64(minPods)*rule.threshold {
+			findings = append(findings, validationFinding{
+				rule: RuleNodePodImbalance, severity: rule.severity, subject: "cluster",
+				message: fmt.Sprintf("Pod distribution imbalanced: %d-%d pods per node", minPods, maxPods),
+			})
+		}
+	}
+
+	// Check for saturated capacity; pools are skipped when there is only one
+	if rule := r.rules[RuleCapacitySaturation]; rule.severity != severityOff {
+		for i, pool := range in.saturation {
+			if i > 0 && len(in.saturation) == 2 {
+				break
+			}
+			subject := "cluster"
+			if i > 0 {
+				subject = "pool/" + pool.pool
+			}
+			for _, res := range []struct {
+				name       string
+				req, alloc int64
+			}{{"CPU", pool.reqCPU, pool.allocCPU}, {"Memory", pool.reqMem, pool.allocMem}} {
+				if res.alloc > 0 && float64(res.req) > float64(res.alloc)*rule.threshold {
+					findings = append(findings, validationFinding{
+						rule: RuleCapacitySaturation, severity: rule.severity, subject: subject,
+						message: fmt.Sprintf("%s requests at %.0f%% of allocatable in %s (threshold %.0f%%)",
+							res.name, float64(res.req)/float64(res.alloc)*100, subject, rule.threshold*100),
+					})
+				}
+			}
+		}
+	}
+
+	// Check for recently created namespaces without limits
+	if rule := r.rules[RuleNewNamespaceNoLimits]; rule.severity != severityOff {
+		maxAge := time.Duration(rule.threshold * float64(24*time.Hour))
+		var young []string
+		for ns, totals := range in.namespaceTotals {
+			created, ok := in.namespaceCreated[ns]
+			if ok && !created.IsZero() && in.now.Sub(created) < maxAge && totals.limCPU == 0 && totals.limMem == 0 {
+				young = append(young, ns)
+			}
+		}
+		sort.Strings(young)
+		for _, ns := range young {
+			findings = append(findings, validationFinding{
+				rule: RuleNewNamespaceNoLimits, severity: rule.severity, subject: "namespace/" + ns,
+				message: fmt.Sprintf("Namespace '%s' created %s ago has no resource limits", ns, pluralize(int(in.now.Sub(in.namespaceCreated[ns]).Hours()/24), "day")),
+			})
+		}
+	}
+
+	// Check for workloads whose pods run different resource specs
+	if rule := r.rules[RuleWorkloadSpecDrift]; rule.severity != severityOff {
+		for _, d := range in.specDrift {
+			if float64(len(d.pods)) > rule.threshold {
+				findings = append(findings, validationFinding{
+					rule: RuleWorkloadSpecDrift, severity: rule.severity, subject: "workload/" + d.workload.String(),
+					message: d.message(),
+				})
+			}
+		}
+	}
+
+	// Check for CronJob bursts near the allocatable capacity, the worst window per resource
+	if rule := r.rules[RuleCronJobBurst]; rule.severity != severityOff && in.forecast != nil {
+		for _, res := range []struct {
+			name  string
+			share func(burstWindow) interface{}
+		}{{"CPU", burstWindow.cpuShare}, {"Memory", burstWindow.memShare}} {
+			var worst *burstWindow
+			var worstShare float64
+			for i, w := range in.forecast.peaks {
+				if s, ok := res.share(w).(float64); ok && s > rule.threshold && s > worstShare {
+					worst, worstShare = &in.forecast.peaks[i], s
+				}
+			}
+			if worst != nil {
+				findings = append(findings, validationFinding{
+					rule: RuleCronJobBurst, severity: rule.severity, subject: "cluster",
+					message: fmt.Sprintf("%s requests forecast at %.0f%% of allocatable at %s while %s run (threshold %.0f%%)",
+						res.name, worstShare*100, worst.start.Format("2006-01-02 15:04"), strings.Join(worst.cronJobs, ", "), rule.threshold*100),
+				})
+			}
+		}
+	}
+
+	// Check for CPU requests well above the measured usage
+	if rule := r.rules[RuleSavingsOpportunity]; rule.severity != severityOff && in.savings != nil {
+		if cores := milliToCores(*in.savings); cores > rule.threshold {
+			findings = append(findings, validationFinding{
+				rule: RuleSavingsOpportunity, severity: rule.severity, subject: "cluster",
+				message: fmt.Sprintf("CPU requests exceed the usage based recommendations by %.1f cores (threshold %g)", cores, rule.threshold),
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].severity > findings[j].severity })
+	return r.review.apply(findings)
+}
+
+// validateAndWarnResources evaluates the validation rules and logs the findings
+// not acknowledged by a review, at most MaxLoggedFindings per rule
+func validateAndWarnResources(in validationInput, containerCount int, r validationRules) []validationFinding {
+	findings := validateResources(in, r)
+
+	var open []validationFinding
+	for _, finding := range findings {
+		if !finding.acknowledged() {
+			open = append(open, finding)
+		}
+	}
+	if acknowledged := len(findings) - len(open); acknowledged > 0 {
+		logrus.Infof("%s acknowledged in an earlier review", pluralize(acknowledged, "finding"))
+	}
+
+	if len(open) > 0 {
+		logrus.Warn("Resource validation warnings:")
+		logged := make(map[string]int)
+		var rules []string
+		for _, finding := range open {
+			if logged[finding.rule] == 0 {
+				rules = append(rules, finding.rule)
+			}
+			logged[finding.rule]++
+			if logged[finding.rule] <= MaxLoggedFindings {
+				logrus.Warnf("  - [%s] %s", finding.severity, finding.message)
+			}
+		}
+		for _, rule := range rules {
+			if more := logged[rule] - MaxLoggedFindings; more > 0 {
+				logrus.Warnf("  - ... and %d more '%s' findings", more, rule)
+			}
+		}
+	}
+
+	logrus.Infof("Validation complete: %d namespaces, %d nodes, %d containers",
+		len(in.namespaceTotals), len(in.nodeTotals), containerCount)
+	return findings
+}
+
+// createWarningsSheet lists all validation findings, most severe first, with
+// an Action dropdown and an Owner column for the review workflow
+func createWarningsSheet(f *excelize.File, findings []validationFinding, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create warnings sheet: %w", err)
+	}
+
+	headers := []string{"Severity", "Rule", "Subject", "Message", "Action", "Owner"}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, finding := range findings {
+		data := []interface{}{finding.severity.String(), finding.rule, finding.subject, finding.message, finding.action, finding.owner}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("finding '%s'", finding.subject)); err != nil {
+			return err
+		}
+		if finding.severity == severityError {
+			f.SetCellStyle(sheetName, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), export.BoldStyle(f))
+		}
+		row++
+	}
+	if row == 2 {
+		f.SetCellValue(sheetName, "A2", "No findings")
+	} else if err := addReviewDropdown(f, sheetName, "E", 2, row-1); err != nil {
+		return err
+	}
+
+	f.SetColWidth(sheetName, "A", "A", 10)
+	f.SetColWidth(sheetName, "B", "B", 26)
+	f.SetColWidth(sheetName, "C", "C", 30)
+	f.SetColWidth(sheetName, "D", "D", 60)
+	f.SetColWidth(sheetName, "E", "F", 16)
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseValidationRules(t *testing.T) {
+	threshold := func(v float64) *float64 { return &v }
+
+	r, err := parseValidationRules(validationSpec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.failOn != severityOff || r.rules[RuleNodePodImbalance] != defaultValidationRules[RuleNodePodImbalance] {
+		t.Errorf("parseValidationRules(empty) = %+v, want defaults", r)
+	}
+
+	r, err = parseValidationRules(validationSpec{
+		FailOn: "Error",
+		Rules: map[string]validationRuleSpec{
+			RuleNamespaceWithoutLimits: {Severity: "error", Threshold: threshold(2)},
+			RuleNodePodImbalance:       {Severity: "off"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.failOn != severityError {
+		t.Errorf("failOn = %s, want error", r.failOn)
+	}
+	if got := r.rules[RuleNamespaceWithoutLimits]; got.severity != severityError || got.threshold != 2 {
+		t.Errorf("namespace rule = %+v", got)
+	}
+	if got := r.rules[RuleNodePodImbalance]; got.severity != severityOff || got.threshold != 2 {
+		t.Errorf("imbalance rule = %+v, want disabled with default threshold", got)
+	}
+	if defaultValidationRules[RuleNamespaceWithoutLimits].severity != severityWarn {
+		t.Error("parseValidationRules() modified the defaults")
+	}
+
+	invalid := []validationSpec{
+		{FailOn: "fatal"},
+		{Rules: map[string]validationRuleSpec{"no-such-rule": {Severity: "warn"}}},
+		{Rules: map[string]validationRuleSpec{RuleNodePodImbalance: {Severity: "critical"}}},
+		{Rules: map[string]validationRuleSpec{RuleNodePodImbalance: {Threshold: threshold(-1)}}},
+	}
+	for _, spec := range invalid {
+		if _, err := parseValidationRules(spec); err == nil {
+			t.Errorf("parseValidationRules(%+v) expected error", spec)
+		}
+	}
+}
+
+func TestValidateResources(t *testing.T) {
+	namespaces := map[string]namespaceTotal{
+		"shop":   {limCPU: 1000},
+		"search": {},
+		"batch":  {},
+	}
+	nodes := map[string]nodeTotal{"a": {podCount: 3}, "b": {podCount: 7}}
+
+	rules := func(noLimits validationRule, imbalance validationRule) validationRules {
+		return validationRules{rules: map[string]validationRule{
+			RuleNamespaceWithoutLimits: noLimits,
+			RuleNodePodImbalance:       imbalance,
+			RuleCapacitySaturation:     {severityError, 0.5}, // No saturation input
+		}}
+	}
+
+	tests := []struct {
+		name  string
+		rules validationRules
+		want  []string // Subjects in order
+	}{
+		{"defaults", rules(defaultValidationRules[RuleNamespaceWithoutLimits], defaultValidationRules[RuleNodePodImbalance]),
+			[]string{"namespace/batch", "namespace/search", "cluster"}},
+		{"tolerate two namespaces", rules(validationRule{severityWarn, 2}, validationRule{severityWarn, 2}),
+			[]string{"cluster"}},
+		{"imbalance ratio 3", rules(validationRule{severityWarn, 1}, validationRule{severityWarn, 3}),
+			[]string{"namespace/batch", "namespace/search"}},
+		{"errors first", rules(validationRule{severityInfo, 0}, validationRule{severityError, 2}),
+			[]string{"cluster", "namespace/batch", "namespace/search"}},
+		{"all off", rules(validationRule{severityOff, 0}, validationRule{severityOff, 2}), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := validateResources(validationInput{namespaceTotals: namespaces, nodeTotals: nodes}, tt.rules)
+			if len(findings) != len(tt.want) {
+				t.Fatalf("validateResources() returned %d findings, want %d: %+v", len(findings), len(tt.want), findings)
+			}
+			for i, finding := range findings {
+				if finding.subject != tt.want[i] {
+					t.Errorf("findings[%d].subject = %q, want %q", i, finding.subject, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSchedulingRules(t *testing.T) {
+	now := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	in := validationInput{
+		namespaceTotals: map[string]namespaceTotal{"old": {}, "new": {}, "limited": {limMem: 1}},
+		namespaceCreated: map[string]time.Time{
+			"old":     now.Add(-30 * 24 * time.Hour),
+			"new":     now.Add(-2 * 24 * time.Hour),
+			"limited": now.Add(-time.Hour),
+		},
+		saturation: []poolSaturation{
+			{pool: "Cluster", reqCPU: 7000, allocCPU: 10000, reqMem: 10, allocMem: 100},
+			{pool: "general", reqCPU: 2000, allocCPU: 6000, reqMem: 5, allocMem: 50},
+			{pool: "gpu", reqCPU: 5000, allocCPU: 4000, reqMem: 5, allocMem: 50},
+		},
+		now: now,
+	}
+	r := validationRules{rules: map[string]validationRule{
+		RuleCapacitySaturation:   {severityError, 0.6},
+		RuleNewNamespaceNoLimits: {severityWarn, 7},
+	}}
+
+	findings := validateResources(in, r)
+	want := []string{"cluster", "pool/gpu", "namespace/new"}
+	if len(findings) != len(want) {
+		t.Fatalf("validateResources() returned %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for i, finding := range findings {
+		if finding.subject != want[i] {
+			t.Errorf("findings[%d].subject = %q, want %q", i, finding.subject, want[i])
+		}
+	}
+	if msg := findings[2].message; msg != "Namespace 'new' created 2 days ago has no resource limits" {
+		t.Errorf("message = %q", msg)
+	}
+
+	// A single pool duplicates the cluster row and is skipped
+	in.saturation = in.saturation[:2]
+	in.saturation[1].reqCPU = 6000
+	if findings := validateResources(in, r); len(findings) != 2 {
+		t.Errorf("single pool: got %d findings, want 2: %+v", len(findings), findings)
+	}
+}
+
+func TestValidateSavingsOpportunity(t *testing.T) {
+	r := validationRules{rules: map[string]validationRule{RuleSavingsOpportunity: {severityWarn, 4}}}
+	tests := []struct {
+		name    string
+		savings *int64
+		want    int
+	}{
+		{"no usage data", nil, 0},
+		{"below threshold", func() *int64 { s := int64(4000); return &s }(), 0},
+		{"above threshold", func() *int64 { s := int64(6500); return &s }(), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := validateResources(validationInput{savings: tt.savings}, r)
+			if len(findings) != tt.want {
+				t.Fatalf("validateResources() = %+v, want %d findings", findings, tt.want)
+			}
+			if tt.want > 0 && findings[0].message != "CPU requests exceed the usage based recommendations by 6.5 cores (threshold 4)" {
+				t.Errorf("message = %q", findings[0].message)
+			}
+		})
+	}
+}
+
+func TestFailingFindings(t *testing.T) {
+	findings := []validationFinding{{severity: severityWarn}, {severity: severityInfo}}
+	tests := []struct {
+		failOn severity
+		want   bool
+	}{
+		{severityOff, false},
+		{severityInfo, true},
+		{severityWarn, true},
+		{severityError, false},
+	}
+	for _, tt := range tests {
+		err := validationRules{failOn: tt.failOn}.failingFindings(findings)
+		if isFindingsError(err) != tt.want {
+			t.Errorf("failingFindings() with failOn %s = %v, want findings error %v", tt.failOn, err, tt.want)
+		}
+	}
+}
+
+func TestSnapshotFindings(t *testing.T) {
+	snap := testAPISnapshot(time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+	rules, err := parseValidationRules(validationSpec{FailOn: DefaultCheckFailOn})
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings := snapshotFindings(snap, rules, 0)
+	subjects := make(map[string]bool)
+	for _, finding := range findings {
+		if finding.rule == RuleNamespaceWithoutLimits {
+			subjects[finding.subject] = true
+		}
+	}
+	if !subjects["namespace/shop"] || !subjects["namespace/search"] {
+		t.Errorf("findings = %+v, want both namespaces without limits", findings)
+	}
+	// Warnings do not fail the default check
+	if err := rules.failingFindings(findings); err != nil {
+		t.Errorf("failingFindings() = %v, want nil below error severity", err)
+	}
+}
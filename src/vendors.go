@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/export"
+	"github.com/xuri/excelize/v2"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Docker Hub defaults for image references without registry or organization
+const (
+	DefaultRegistry     = "docker.io"
+	DefaultOrganization = "library"
+)
+
+// imageVendor splits an image reference into its registry and organization (the
+// first path component), following the Docker reference rules: the first
+// component is a registry only if it contains "." or ":" or is "localhost"
+func imageVendor(image string) (registry, organization string) {
+	parts := strings.Split(imageRepository(image), "/")
+	registry = DefaultRegistry
+	if len(parts) > 1 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		registry, parts = parts[0], parts[1:]
+	}
+	if registry == "index.docker.io" {
+		registry = DefaultRegistry
+	}
+	if len(parts) == 1 {
+		if registry == DefaultRegistry {
+			return registry, DefaultOrganization
+		}
+		return registry, ""
+	}
+	return registry, parts[0]
+}
+
+// vendorTotal sums the requests of all containers from one registry organization
+type vendorTotal struct {
+	registry, organization string
+	images                 map[string]bool // Distinct repositories
+	containers             int
+	reqCPU, reqMem         int64
+}
+
+// vendorTotals groups container requests by image registry and organization,
+// largest CPU first. Also returns the cluster-wide requests.
+func vendorTotals(pods []corev1.Pod) (vendors []vendorTotal, clusterCPU, clusterMem int64) {
+	type key struct{ registry, organization string }
+	totals := make(map[key]*vendorTotal)
+
+	for i := range pods {
+		pod := &pods[i]
+		if !isActivePod(pod) {
+			continue
+		}
+		for _, c := range podContainers(pod) {
+			cpu := quantityMilli(c.Resources.Requests.Cpu())
+			mem := quantityBytes(c.Resources.Requests.Memory())
+			clusterCPU += cpu
+			clusterMem += mem
+
+			registry, organization := imageVendor(c.Image)
+			k := key{registry, organization}
+			v := totals[k]
+			if v == nil {
+				v = &vendorTotal{registry: registry, organization: organization, images: make(map[string]bool)}
+				totals[k] = v
+			}
+			v.images[imageRepository(c.Image)] = true
+			v.containers++
+			v.reqCPU += cpu
+			v.reqMem += mem
+		}
+	}
+
+	vendors = make([]vendorTotal, 0, len(totals))
+	for _, v := range totals {
+		vendors = append(vendors, *v)
+	}
+	sort.Slice(vendors, func(i, j int) bool {
+		if vendors[i].reqCPU != vendors[j].reqCPU {
+			return vendors[i].reqCPU > vendors[j].reqCPU
+		}
+		if vendors[i].registry != vendors[j].registry {
+			return vendors[i].registry < vendors[j].registry
+		}
+		return vendors[i].organization < vendors[j].organization
+	})
+	return vendors, clusterCPU, clusterMem
+}
+
+// createVendorSheet lists requests per image registry and organization with their
+// share of cluster requests
+func createVendorSheet(f *excelize.File, vendors []vendorTotal, clusterCPU, clusterMem int64, sheetName string) error {
+	_, err := f.NewSheet(sheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create image vendors sheet: %w", err)
+	}
+
+	headers := []string{
+		"Registry", "Organization", "Images", "Containers",
+		"Request CPU (cores)", "Request Memory (Gi)", "CPU % of Cluster", "Memory % of Cluster", "Repositories",
+	}
+	if err := f.SetSheetRow(sheetName, "A1", &headers); err != nil {
+		return fmt.Errorf("failed to set headers: %w", err)
+	}
+
+	row := 2
+	for _, v := range vendors {
+		images := make([]string, 0, len(v.images))
+		for image := range v.images {
+			images = append(images, image)
+		}
+		sort.Strings(images)
+
+		data := []interface{}{
+			v.registry,
+			v.organization,
+			len(images),
+			v.containers,
+			milliToCores(v.reqCPU),
+			bytesToGi(v.reqMem),
+			ratio(v.reqCPU, clusterCPU),
+			ratio(v.reqMem, clusterMem),
+			strings.Join(images, ", "),
+		}
+		if err := export.SetRow(f, sheetName, row, data, fmt.Sprintf("registry '%s/%s'", v.registry, v.organization)); err != nil {
+			return err
+		}
+		row++
+	}
+
+	if row > 2 {
+		last := row - 1
+		f.SetCellStyle(sheetName, "E2", fmt.Sprintf("F%d", last), export.DecimalStyle(f, false))
+		f.SetCellStyle(sheetName, "G2", fmt.Sprintf("H%d", last), export.PercentStyle(f, "0.0%"))
+	}
+
+	f.SetColWidth(sheetName, "A", "B", 24)
+	f.SetColWidth(sheetName, "C", "D", 12)
+	f.SetColWidth(sheetName, "E", "H", 20)
+	f.SetColWidth(sheetName, "I", "I", 80)
+
+	return nil
+}
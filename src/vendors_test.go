@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestImageVendor(t *testing.T) {
+	tests := []struct {
+		image, registry, organization string
+	}{
+		{"nginx:1.25", "docker.io", "library"},
+		{"bitnami/redis:7", "docker.io", "bitnami"},
+		{"docker.io/library/nginx", "docker.io", "library"},
+		{"index.docker.io/grafana/grafana", "docker.io", "grafana"},
+		{"quay.io/bigvendor/operator:v2", "quay.io", "bigvendor"},
+		{"registry.k8s.io/coredns/coredns:v1.11.1", "registry.k8s.io", "coredns"},
+		{"registry.k8s.io/pause:3.9", "registry.k8s.io", ""},
+		{"localhost/team/app", "localhost", "team"},
+		{"myregistry:5000/app@sha256:abc", "myregistry:5000", ""},
+	}
+	for _, tt := range tests {
+		registry, organization := imageVendor(tt.image)
+		if registry != tt.registry || organization != tt.organization {
+			t.Errorf("imageVendor(%q) = %q, %q, want %q, %q", tt.image, registry, organization, tt.registry, tt.organization)
+		}
+	}
+}
+
+func TestVendorTotals(t *testing.T) {
+	container := func(image, cpu string) corev1.Container {
+		c := testSidecarContainer("c", cpu, "128Mi")
+		c.Image = image
+		return c
+	}
+	pod := func(containers ...corev1.Container) corev1.Pod {
+		return corev1.Pod{Spec: corev1.PodSpec{Containers: containers}, Status: corev1.PodStatus{Phase: corev1.PodRunning}}
+	}
+	pods := []corev1.Pod{
+		pod(container("quay.io/bigvendor/operator:v1", "1"), container("quay.io/bigvendor/agent:v1", "500m")),
+		pod(container("quay.io/bigvendor/agent:v2", "500m")),
+		pod(container("nginx", "250m")),
+		{Spec: corev1.PodSpec{Containers: []corev1.Container{container("quay.io/bigvendor/job", "4")}}, Status: corev1.PodStatus{Phase: corev1.PodSucceeded}},
+	}
+
+	vendors, clusterCPU, _ := vendorTotals(pods)
+	if clusterCPU != 2250 {
+		t.Errorf("clusterCPU = %dm, want 2250m", clusterCPU)
+	}
+	if len(vendors) != 2 {
+		t.Fatalf("vendorTotals() returned %d vendors, want 2", len(vendors))
+	}
+	v := vendors[0]
+	if v.registry != "quay.io" || v.organization != "bigvendor" || v.containers != 3 || len(v.images) != 2 || v.reqCPU != 2000 {
+		t.Errorf("vendors[0] = %+v, want quay.io/bigvendor with 3 containers of 2 images", v)
+	}
+}
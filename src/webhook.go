@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Annotations stamped on pods by the webhook subcommand
+const (
+	TShirtSizeAnnotation       = "resource-report/tshirt-size"
+	CPUEfficiencyAnnotation    = "resource-report/cpu-efficiency"
+	MemoryEfficiencyAnnotation = "resource-report/memory-efficiency"
+)
+
+// Efficiency classes of the webhook annotations, by request/limit ratio and the
+// rating thresholds of the scoring model, as on the Insights sheet
+const (
+	EfficiencyUnderProvisioned = "under-provisioned"         // From underProvisioned
+	EfficiencyBalanced         = "well-balanced"             // From wellBalanced
+	EfficiencyOverProvisioned  = "over-provisioned"          // From overProvisioned
+	EfficiencySeverelyOver     = "severely-over-provisioned" // Below overProvisioned
+	EfficiencyNoLimit          = "no-limit"                  // A container without a limit
+)
+
+// Webhook server settings
+const (
+	DefaultWebhookAddr = ":8443"
+	PathMutate         = "/mutate"
+	MaxAdmissionReview = 3 << 20 // Bytes, the API server's request size limit
+)
+
+// webhookArgs are the flags of the webhook subcommand
+type webhookArgs struct {
+	listen, tlsCert, tlsKey *string
+	configPath              *string
+	verbose, quiet          *bool
+}
+
+// webhookFlags defines the flags of the webhook subcommand
+func webhookFlags() (*flag.FlagSet, *webhookArgs) {
+	fs := flag.NewFlagSet("webhook", flag.ExitOnError)
+	return fs, &webhookArgs{
+		listen:     fs.String("listen", DefaultWebhookAddr, "HTTPS listen address of the admission webhook"),
+		tlsCert:    fs.String("tls-cert", "", "Path of the serving certificate (PEM), trusted by the webhook configuration's caBundle"),
+		tlsKey:     fs.String("tls-key", "", "Path of the serving certificate's private key (PEM)"),
+		configPath: fs.String("config", "", "Path to config file (YAML/JSON) with the T-shirt sizes and scoring model"),
+		verbose:    fs.Bool("verbose", false, "Enable verbose logging"),
+		quiet:      fs.Bool("quiet", false, "Only log errors (logs always go to stderr)"),
+	}
+}
+
+// efficiencyClass classifies a request/limit ratio with the scoring model, like
+// the ratings of the Insights sheet; limit 0 means unbounded
+func efficiencyClass(request, limit int64, model scoringModel) string {
+	if limit == 0 {
+		return EfficiencyNoLimit
+	}
+	return model.efficiencyClass(float64(request) / float64(limit) * 100)
+}
+
+// podLimits sums the container limits of a pod; a container without a limit
+// makes the pod's limit 0 (unbounded)
+func podLimits(pod *corev1.Pod) (cpu, mem int64) {
+	unboundedCPU, unboundedMem := false, false
+	for _, c := range podContainers(pod) {
+		limCPU, limMem := quantityMilli(c.Resources.Limits.Cpu()), quantityBytes(c.Resources.Limits.Memory())
+		unboundedCPU = unboundedCPU || limCPU == 0
+		unboundedMem = unboundedMem || limMem == 0
+		cpu += limCPU
+		mem += limMem
+	}
+	if unboundedCPU {
+		cpu = 0
+	}
+	if unboundedMem {
+		mem = 0
+	}
+	return cpu, mem
+}
+
+// podClassAnnotations returns the size and efficiency annotations of a pod,
+// computed from its summed requests and limits
+func podClassAnnotations(pod *corev1.Pod, sizes []tshirtSize, model scoringModel) map[string]string {
+	reqCPU, reqMem := podRequests(pod)
+	limCPU, limMem := podLimits(pod)
+	return map[string]string{
+		TShirtSizeAnnotation:       classifyTShirt(sizes, reqCPU, reqMem),
+		CPUEfficiencyAnnotation:    efficiencyClass(reqCPU, limCPU, model),
+		MemoryEfficiencyAnnotation: efficiencyClass(reqMem, limMem, model),
+	}
+}
+
+// patchOperation is one JSON patch (RFC 6902) operation
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// annotationPatch returns the JSON patch setting annotations on a pod; values
+// already present are left out
+func annotationPatch(pod *corev1.Pod, annotations map[string]string) []patchOperation {
+	if len(pod.Annotations) == 0 {
+		return []patchOperation{{Op: "add", Path: "/metadata/annotations", Value: annotations}}
+	}
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var patch []patchOperation
+	for _, key := range keys {
+		if value, ok := pod.Annotations[key]; ok && value == annotations[key] {
+			continue
+		}
+		// JSON pointer escaping (RFC 6901): ~ first, then /
+		escaped := strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+		patch = append(patch, patchOperation{Op: "add", Path: "/metadata/annotations/" + escaped, Value: annotations[key]})
+	}
+	return patch
+}
+
+// admitPod answers an admission request: pods are annotated, anything else is
+// let through unchanged. Pods are never rejected, so an undecodable pod is
+// admitted without annotations.
+func admitPod(req *admissionv1.AdmissionRequest, sizes []tshirtSize, model scoringModel) *admissionv1.AdmissionResponse {
+	resp := &admissionv1.AdmissionResponse{UID: req.UID, Allowed: true}
+	if req.Kind.Group != "" || req.Kind.Kind != "Pod" {
+		return resp
+	}
+	var pod corev1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		logrus.Warnf("Admitted pod %s/%s without annotations: %v", req.Namespace, req.Name, err)
+		return resp
+	}
+	patch := annotationPatch(&pod, podClassAnnotations(&pod, sizes, model))
+	if len(patch) == 0 {
+		return resp
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		logrus.Warnf("Admitted pod %s/%s without annotations: %v", req.Namespace, req.Name, err)
+		return resp
+	}
+	patchType := admissionv1.PatchTypeJSONPatch
+	resp.Patch, resp.PatchType = data, &patchType
+	return resp
+}
+
+// webhookHandler returns the HTTP routes of the webhook subcommand
+func webhookHandler(sizes []tshirtSize, model scoringModel) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PathHealth, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc(PathMutate, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var review admissionv1.AdmissionReview
+		if err := json.NewDecoder(io.LimitReader(r.Body, MaxAdmissionReview)).Decode(&review); err != nil || review.Request == nil {
+			http.Error(w, "invalid admission review", http.StatusBadRequest)
+			return
+		}
+		review.Response = admitPod(review.Request, sizes, model)
+		review.Request = nil
+		writeJSON(w, http.StatusOK, review)
+	})
+	return mux
+}
+
+// runWebhook implements the webhook subcommand: a mutating admission webhook
+// stamping new pods with their T-shirt size and efficiency classes
+func runWebhook(args []string) error {
+	fs, a := webhookFlags()
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := configureLogging(*a.verbose, *a.quiet); err != nil {
+		return err
+	}
+	if *a.tlsCert == "" || *a.tlsKey == "" {
+		return fmt.Errorf("-tls-cert and -tls-key are required, the API server only calls webhooks over HTTPS")
+	}
+	for _, path := range []string{*a.tlsCert, *a.tlsKey} {
+		if err := validatePath(path); err != nil {
+			return fmt.Errorf("invalid TLS path: %w", err)
+		}
+	}
+	cfg, err := loadConfig(*a.configPath)
+	if err != nil {
+		return err
+	}
+	sizes, err := parseTShirtSizes(cfg.TShirtSizes)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	model, err := parseScoring(cfg.Scoring)
+	if err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+
+	server := &http.Server{Addr: *a.listen, Handler: webhookHandler(sizes, model), ReadHeaderTimeout: DefaultAPITimeout}
+	logrus.Infof("Serving admission webhook on %s%s", *a.listen, PathMutate)
+	if err := server.ListenAndServeTLS(*a.tlsCert, *a.tlsKey); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestEfficiencyClass(t *testing.T) {
+	defaultModel, _ := parseScoring(nil)
+	strict, _ := parseScoring(&scoringSpec{Model: "strict"})
+	custom, _ := parseScoring(&scoringSpec{WellBalanced: 30, OverProvisioned: 10})
+	tests := []struct {
+		model          scoringModel
+		request, limit int64
+		want           string
+	}{
+		{defaultModel, 900, 1000, EfficiencyUnderProvisioned},
+		{defaultModel, 600, 1000, EfficiencyBalanced},
+		{defaultModel, 400, 1000, EfficiencyOverProvisioned},
+		{defaultModel, 100, 1000, EfficiencySeverelyOver},
+		{defaultModel, 100, 0, EfficiencyNoLimit},
+		{strict, 700, 1000, EfficiencyOverProvisioned},
+		{strict, 500, 1000, EfficiencySeverelyOver},
+		{custom, 400, 1000, EfficiencyBalanced},
+		{custom, 100, 1000, EfficiencyOverProvisioned},
+	}
+	for _, tt := range tests {
+		if got := efficiencyClass(tt.request, tt.limit, tt.model); got != tt.want {
+			t.Errorf("efficiencyClass(%d, %d, %+v) = %s, want %s", tt.request, tt.limit, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestPodClassAnnotations(t *testing.T) {
+	sizes, _ := parseTShirtSizes(defaultTShirtSizes)
+	container := func(reqCPU, limCPU, reqMem, limMem string) corev1.Container {
+		c := corev1.Container{Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(reqCPU), corev1.ResourceMemory: resource.MustParse(reqMem)},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(limMem)},
+		}}
+		if limCPU != "" {
+			c.Resources.Limits[corev1.ResourceCPU] = resource.MustParse(limCPU)
+		}
+		return c
+	}
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{
+		container("500m", "1", "1Gi", "1Gi"),
+		container("250m", "", "256Mi", "1Gi"), // No CPU limit
+	}}}
+	want := map[string]string{
+		TShirtSizeAnnotation:       "M",
+		CPUEfficiencyAnnotation:    EfficiencyNoLimit,
+		MemoryEfficiencyAnnotation: EfficiencyBalanced, // 1.25Gi of 2Gi
+	}
+	model, _ := parseScoring(nil)
+	if got := podClassAnnotations(pod, sizes, model); !reflect.DeepEqual(got, want) {
+		t.Errorf("podClassAnnotations() = %v, want %v", got, want)
+	}
+}
+
+func TestAnnotationPatch(t *testing.T) {
+	annotations := map[string]string{TShirtSizeAnnotation: "S", CPUEfficiencyAnnotation: EfficiencyBalanced}
+	patch := annotationPatch(&corev1.Pod{}, annotations)
+	if len(patch) != 1 || patch[0].Path != "/metadata/annotations" {
+		t.Errorf("annotationPatch() without annotations = %+v, want one map", patch)
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{TShirtSizeAnnotation: "S", "team": "shop"}}}
+	patch = annotationPatch(pod, annotations)
+	want := []patchOperation{{Op: "add", Path: "/metadata/annotations/resource-report~1cpu-efficiency", Value: EfficiencyBalanced}}
+	if !reflect.DeepEqual(patch, want) {
+		t.Errorf("annotationPatch() = %+v, want %+v", patch, want)
+	}
+}
+
+func TestWebhookHandler(t *testing.T) {
+	sizes, _ := parseTShirtSizes(defaultTShirtSizes)
+	model, _ := parseScoring(nil)
+	raw, _ := json.Marshal(corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web"}})
+	review := admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &admissionv1.AdmissionRequest{
+			UID:    "42",
+			Kind:   metav1.GroupVersionKind{Version: "v1", Kind: "Pod"},
+			Object: runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, _ := json.Marshal(review)
+	rec := httptest.NewRecorder()
+	webhookHandler(sizes, model).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, PathMutate, bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST %s = %d", PathMutate, rec.Code)
+	}
+	var got admissionv1.AdmissionReview
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if r := got.Response; r == nil || r.UID != "42" || !r.Allowed || r.PatchType == nil || !bytes.Contains(r.Patch, []byte(`"resource-report/tshirt-size":"S"`)) {
+		t.Errorf("response = %+v, want an allowed patch", got.Response)
+	}
+
+	review.Request.Kind.Kind = "Service"
+	body, _ = json.Marshal(review)
+	rec = httptest.NewRecorder()
+	webhookHandler(sizes, model).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, PathMutate, bytes.NewReader(body)))
+	var service admissionv1.AdmissionReview
+	if err := json.NewDecoder(rec.Body).Decode(&service); err != nil {
+		t.Fatal(err)
+	}
+	if r := service.Response; r == nil || !r.Allowed || r.Patch != nil {
+		t.Errorf("response for a Service = %+v, want allowed without patch", r)
+	}
+
+	rec = httptest.NewRecorder()
+	webhookHandler(sizes, model).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, PathMutate, bytes.NewReader([]byte("{}"))))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("POST without a request = %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/ohauer/PodResourceCalculator/pkg/report"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TightLimitRatio is the limit/request ratio up to which a limit counts as tight
+const TightLimitRatio = 1.5
+
+// Limit classes used to separate workloads in the request vs limit charts
+const (
+	LimitClassTight    = "Tight"
+	LimitClassGenerous = "Generous"
+	LimitClassMissing  = "Missing limit"
+)
+
+// workloadKey identifies the controller owning a pod
+type workloadKey struct {
+	namespace, kind, name string
+}
+
+func (k workloadKey) String() string {
+	return k.namespace + "/" + k.kind + "/" + k.name
+}
+
+// workloadTotal aggregates container resources of all pods of a workload
+type workloadTotal struct {
+	pods                             int
+	reqCPU, limCPU                   int64
+	reqMem, limMem                   int64
+	missingCPULimit, missingMemLimit bool // At least one container without limit
+}
+
+// workloadOf resolves the top-level controller of a pod without extra API calls.
+// ReplicaSets created by Deployments are mapped back using the pod-template-hash label.
+func workloadOf(pod *corev1.Pod) workloadKey {
+	key := workloadKey{namespace: pod.Namespace}
+	key.kind, key.name = report.Workload(pod)
+	return key
+}
+
+// limitClass classifies a limit relative to its request
+func limitClass(req, lim int64, missing bool) string {
+	if missing || lim == 0 {
+		return LimitClassMissing
+	}
+	if float64(lim) <= float64(req)*TightLimitRatio {
+		return LimitClassTight
+	}
+	return LimitClassGenerous
+}
+
+// sortedWorkloads returns workload keys ordered by namespace, kind and name
+func sortedWorkloads(workloads map[workloadKey]workloadTotal) []workloadKey {
+	keys := make([]workloadKey, 0, len(workloads))
+	for key := range workloads {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWorkloadOf(t *testing.T) {
+	isController := true
+	notController := false
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		owners []metav1.OwnerReference
+		want   workloadKey
+	}{
+		{"bare pod", nil, nil, workloadKey{"shop", "Pod", "web-1"}},
+		{"deployment via replicaset", map[string]string{"pod-template-hash": "5d8f"},
+			[]metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-5d8f", Controller: &isController}},
+			workloadKey{"shop", "Deployment", "web"}},
+		{"standalone replicaset", nil,
+			[]metav1.OwnerReference{{Kind: "ReplicaSet", Name: "web-rs", Controller: &isController}},
+			workloadKey{"shop", "ReplicaSet", "web-rs"}},
+		{"statefulset", nil,
+			[]metav1.OwnerReference{{Kind: "StatefulSet", Name: "db", Controller: &isController}},
+			workloadKey{"shop", "StatefulSet", "db"}},
+		{"non-controller owner skipped", nil,
+			[]metav1.OwnerReference{{Kind: "ConfigMap", Name: "cm", Controller: &notController}, {Kind: "DaemonSet", Name: "agent"}},
+			workloadKey{"shop", "DaemonSet", "agent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Name: "web-1", Namespace: "shop", Labels: tt.labels, OwnerReferences: tt.owners,
+			}}
+			if got := workloadOf(pod); got != tt.want {
+				t.Errorf("workloadOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimitClass(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     int64
+		lim     int64
+		missing bool
+		want    string
+	}{
+		{"equal", 100, 100, false, LimitClassTight},
+		{"at tight ratio", 100, 150, false, LimitClassTight},
+		{"generous", 100, 400, false, LimitClassGenerous},
+		{"no request", 0, 100, false, LimitClassGenerous},
+		{"zero limit", 100, 0, false, LimitClassMissing},
+		{"partially missing", 100, 100, true, LimitClassMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitClass(tt.req, tt.lim, tt.missing); got != tt.want {
+				t.Errorf("limitClass() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}